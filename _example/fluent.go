@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	fluentConfig := &go_logger.FluentConfig{
+		Address:    "127.0.0.1:24224",
+		Tag:        "app.%level_string%",
+		RequireAck: true,
+	}
+	logger.Attach("fluent", go_logger.LOGGER_LEVEL_DEBUG, fluentConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Alert("this is a alert log!")
+
+	logger.Flush()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+	"os"
+	"time"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	hostname, _ := os.Hostname()
+	lokiConfig := &go_logger.LokiConfig{
+		Url:           "http://127.0.0.1:3100",
+		Labels:        map[string]string{"job": "example", "host": hostname},
+		LevelLabel:    true,
+		JsonFormat:    true,
+		BatchSize:     100,
+		FlushInterval: 2 * time.Second,
+		MaxRetries:    5,
+	}
+	logger.Attach("loki", go_logger.LOGGER_LEVEL_DEBUG, lokiConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Alert("this is a alert log!")
+
+	logger.Flush()
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	mqttConfig := &go_logger.MqttConfig{
+		Address:  "127.0.0.1:1883",
+		Topic:    "devices/edge-1/logs/%level_string%",
+		QoS:      go_logger.MQTT_QOS_AT_LEAST_ONCE,
+		ClientId: "edge-1",
+	}
+	logger.Attach("mqtt", go_logger.LOGGER_LEVEL_DEBUG, mqttConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Alert("this is a alert log!")
+
+	logger.Flush()
+}
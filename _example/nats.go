@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	natsConfig := &go_logger.NatsConfig{
+		Address: "127.0.0.1:4222",
+		Subject: "logs.edge-1.%level_string%",
+		Token:   "secret",
+	}
+	logger.Attach("nats", go_logger.LOGGER_LEVEL_DEBUG, natsConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Alert("this is a alert log!")
+
+	logger.Flush()
+}
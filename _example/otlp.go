@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	otlpConfig := &go_logger.OtlpConfig{
+		Endpoint:           "http://127.0.0.1:4317",
+		Protocol:           go_logger.OTLP_PROTOCOL_GRPC,
+		ServiceName:        "checkout",
+		ResourceAttributes: map[string]string{"service.version": "1.2.0", "deployment.environment": "prod"},
+	}
+	logger.Attach("otlp", go_logger.LOGGER_LEVEL_DEBUG, otlpConfig)
+	logger.SetAsync()
+
+	// fields are attributes, "trace_id" and "span_id" are ids of the record
+	logger.WriterFields(go_logger.LOGGER_LEVEL_ERROR, "payment failed", map[string]interface{}{
+		"order_id": 42,
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":  "00f067aa0ba902b7",
+	})
+	logger.Info("this is a info log!")
+
+	logger.Flush()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	// full logs are written to the file, the terminal shows the summary line only
+	logger.Detach("console")
+	logger.Attach("file", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.FileConfig{Filename: "./test.log"})
+	logger.Attach("progress", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.ProgressConfig{Label: "import"})
+
+	for i := 0; i < 100000; i++ {
+		if i%1000 == 0 {
+			logger.Errorf("row %d is broken", i)
+			continue
+		}
+		logger.Infof("row %d is imported", i)
+	}
+
+	// the final summary is drawn by Close
+	logger.Close(context.Background())
+}
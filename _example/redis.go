@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	redisConfig := &go_logger.RedisConfig{
+		Address:  "127.0.0.1:6379",
+		Password: "secret",
+		Mode:     go_logger.REDIS_MODE_LIST, // logstash redis input data_type => "list"
+		Key:      "logstash",
+	}
+	logger.Attach("redis", go_logger.LOGGER_LEVEL_DEBUG, redisConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Alert("this is a alert log!")
+
+	logger.Flush()
+}
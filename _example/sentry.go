@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	// error, critical, alert and emergency messages are sent to sentry
+	sentryConfig := &go_logger.SentryConfig{
+		Dsn:                "https://public@o1.ingest.sentry.io/42",
+		Environment:        "production",
+		Release:            "example@1.0.0",
+		MaxEventsPerMinute: 60,
+	}
+	logger.Attach("sentry", go_logger.LOGGER_LEVEL_ERROR, sentryConfig)
+	logger.SetAsync()
+
+	logger.Emergency("this is a emergency log!")
+	logger.Error("this is a error log!")
+
+	logger.Flush()
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	// critical and more severe messages are posted to the ops channel, max 10 every minute
+	slackConfig := &go_logger.SlackConfig{
+		Url:                  "https://hooks.slack.com/services/T000/B000/XXXX",
+		Channel:              "#ops",
+		Format:               ":rotating_light: *[%level_string%]* %body% (%file%:%line%)",
+		MaxMessagesPerMinute: 10,
+	}
+	logger.Attach("slack", go_logger.LOGGER_LEVEL_CRITICAL, slackConfig)
+	logger.SetAsync()
+
+	logger.Critical("this is a critical log!")
+	logger.Error("this is a error log, not posted!")
+
+	logger.Flush()
+}
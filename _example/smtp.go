@@ -0,0 +1,31 @@
+package main
+
+import (
+	"github.com/phachon/go-logger"
+	"time"
+)
+
+func main() {
+
+	logger := go_logger.NewLogger()
+
+	// error and more severe messages of every 10 minutes are sent in one mail, max 6 mails every hour
+	smtpConfig := &go_logger.SmtpConfig{
+		Host:             "smtp.example.com:587",
+		Username:         "logger@example.com",
+		Password:         "password",
+		From:             "logger@example.com",
+		To:               []string{"ops@example.com"},
+		Subject:          "[app] [%level_string%] %body%",
+		Digest:           10 * time.Minute,
+		MaxMails:         6,
+		MaxMailsInterval: time.Hour,
+	}
+	logger.Attach("smtp", go_logger.LOGGER_LEVEL_ERROR, smtpConfig)
+	logger.SetAsync()
+
+	logger.Critical("this is a critical log!")
+	logger.Error("this is a error log!")
+
+	logger.Flush()
+}
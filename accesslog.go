@@ -0,0 +1,258 @@
+package go_logger
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// category of access records, see SetAdapterCategories()
+const ACCESS_LOG_CATEGORY = "access"
+
+// fields of access records
+const (
+	ACCESS_FIELD_METHOD     = "method"
+	ACCESS_FIELD_PATH       = "path"
+	ACCESS_FIELD_QUERY      = "query"
+	ACCESS_FIELD_STATUS     = "status"
+	ACCESS_FIELD_LATENCY    = "latency_ms"
+	ACCESS_FIELD_BYTES      = "bytes"
+	ACCESS_FIELD_REMOTE_IP  = "remote_ip"
+	ACCESS_FIELD_REQUEST_ID = "request_id"
+	ACCESS_FIELD_USER_AGENT = "user_agent"
+	ACCESS_FIELD_REFERER    = "referer"
+	ACCESS_FIELD_HOST       = "host"
+	ACCESS_FIELD_PROTO      = "proto"
+)
+
+// default fields of access records
+var accessLogDefaultFields = []string{
+	ACCESS_FIELD_METHOD, ACCESS_FIELD_PATH, ACCESS_FIELD_STATUS, ACCESS_FIELD_LATENCY,
+	ACCESS_FIELD_BYTES, ACCESS_FIELD_REMOTE_IP, ACCESS_FIELD_REQUEST_ID, ACCESS_FIELD_USER_AGENT,
+}
+
+// access log config
+type AccessLogConfig struct {
+
+	// fields of records, ACCESS_FIELD_* names, default method, path, status, latency_ms, bytes, remote_ip, request_id and user_agent
+	Fields []string
+
+	// more fields of the request, eg: the user of the session
+	ExtraFields func(r *http.Request) map[string]interface{}
+
+	// field "category" of records, default "access"
+	Category string
+
+	// write 1 of every SampleSuccess 2xx responses, default 1 writes all
+	// 1xx and 3xx responses, 4xx and 5xx are always written
+	SampleSuccess int
+
+	// remote ip is the first address of X-Forwarded-For or X-Real-IP, only behind trusted proxies
+	TrustProxy bool
+}
+
+// access log of http requests, records are "GET /orders 200" with fields of the request
+// 5xx responses are Error, 4xx are Warning, others are Info
+type AccessLog struct {
+	successes uint64 // first for 64-bit atomic alignment on 32-bit platforms
+	logger    *Logger
+	config    AccessLogConfig
+}
+
+// new access log of the logger, nil config is the default config
+//
+// example:
+//	accessLog := logger.AccessLog(&go_logger.AccessLogConfig{SampleSuccess: 10})
+//	accessLog.Log(r, http.StatusOK, 512, time.Since(start))
+func (logger *Logger) AccessLog(config *AccessLogConfig) *AccessLog {
+	accessLog := &AccessLog{logger: logger}
+	if config != nil {
+		accessLog.config = *config
+	}
+	if accessLog.config.Fields == nil {
+		accessLog.config.Fields = accessLogDefaultFields
+	}
+	for _, field := range accessLog.config.Fields {
+		if !accessLogField(field) {
+			fmt.Fprintf(os.Stderr, "logger: access log field %s is unknown\n", field)
+		}
+	}
+	if accessLog.config.Category == "" {
+		accessLog.config.Category = ACCESS_LOG_CATEGORY
+	}
+	if accessLog.config.SampleSuccess <= 0 {
+		accessLog.config.SampleSuccess = 1
+	}
+	return accessLog
+}
+
+// http handler writing a record of every request, the status of panics is 500
+//
+// example:
+//	http.ListenAndServe(":8080", logger.RequestIdHandler(logger.AccessLogHandler(mux, nil)))
+func (logger *Logger) AccessLogHandler(next http.Handler, config *AccessLogConfig) http.Handler {
+	return logger.AccessLog(config).Handler(next)
+}
+
+// http handler writing a record of every request
+func (accessLog *AccessLog) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		aw := &accessResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		startTime := time.Now()
+
+		defer func() {
+			e := recover()
+			status := aw.status
+			if e != nil {
+				status = http.StatusInternalServerError
+			}
+			// request id of the response header is set by a RequestIdHandler inside
+			accessLog.log(r, status, aw.bytes, time.Since(startTime), w.Header().Get(REQUEST_ID_HEADER))
+			if e != nil {
+				panic(e)
+			}
+		}()
+
+		next.ServeHTTP(aw, r)
+	})
+}
+
+// write the record of a served request, bytes is the size of the response body
+func (accessLog *AccessLog) Log(r *http.Request, status int, bytes int64, latency time.Duration) {
+	accessLog.log(r, status, bytes, latency, "")
+}
+
+// write the record, requestId is the request id if the request has none
+func (accessLog *AccessLog) log(r *http.Request, status int, bytes int64, latency time.Duration, requestId string) {
+	level := LOGGER_LEVEL_INFO
+	switch {
+	case status >= 500:
+		level = LOGGER_LEVEL_ERROR
+	case status >= 400:
+		level = LOGGER_LEVEL_WARNING
+	case status >= 200 && status < 300 && accessLog.config.SampleSuccess > 1:
+		if (atomic.AddUint64(&accessLog.successes, 1)-1)%uint64(accessLog.config.SampleSuccess) != 0 {
+			return
+		}
+	}
+	if !accessLog.logger.enabled(level) {
+		return
+	}
+
+	fields := map[string]interface{}{LOGGER_FIELD_CATEGORY: accessLog.config.Category}
+	if accessLog.config.ExtraFields != nil {
+		for key, value := range accessLog.config.ExtraFields(r) {
+			fields[key] = value
+		}
+	}
+	for _, field := range accessLog.config.Fields {
+		value, ok := accessLog.fieldValue(field, r, status, bytes, latency, requestId)
+		if ok {
+			fields[field] = value
+		}
+	}
+	accessLog.logger.WriterFields(level, fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, status), fields)
+}
+
+// value of the record field, empty strings are not written
+func (accessLog *AccessLog) fieldValue(field string, r *http.Request, status int, bytes int64, latency time.Duration, requestId string) (interface{}, bool) {
+	var value string
+	switch field {
+	case ACCESS_FIELD_STATUS:
+		return status, true
+	case ACCESS_FIELD_LATENCY:
+		return float64(latency.Microseconds()) / 1000, true
+	case ACCESS_FIELD_BYTES:
+		return bytes, true
+	case ACCESS_FIELD_METHOD:
+		value = r.Method
+	case ACCESS_FIELD_PATH:
+		value = r.URL.Path
+	case ACCESS_FIELD_QUERY:
+		value = r.URL.RawQuery
+	case ACCESS_FIELD_REMOTE_IP:
+		value = accessLog.remoteIp(r)
+	case ACCESS_FIELD_REQUEST_ID:
+		value = RequestIdFromContext(r.Context())
+		if value == "" && validRequestId(r.Header.Get(REQUEST_ID_HEADER)) {
+			value = r.Header.Get(REQUEST_ID_HEADER)
+		}
+		if value == "" {
+			value = requestId
+		}
+	case ACCESS_FIELD_USER_AGENT:
+		value = r.UserAgent()
+	case ACCESS_FIELD_REFERER:
+		value = r.Referer()
+	case ACCESS_FIELD_HOST:
+		value = r.Host
+	case ACCESS_FIELD_PROTO:
+		value = r.Proto
+	}
+	return value, value != ""
+}
+
+// client address of the request, proxy headers are used if TrustProxy
+func (accessLog *AccessLog) remoteIp(r *http.Request) string {
+	if accessLog.config.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIp := r.Header.Get("X-Real-IP"); realIp != "" {
+			return strings.TrimSpace(realIp)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func accessLogField(field string) bool {
+	switch field {
+	case ACCESS_FIELD_METHOD, ACCESS_FIELD_PATH, ACCESS_FIELD_QUERY, ACCESS_FIELD_STATUS, ACCESS_FIELD_LATENCY, ACCESS_FIELD_BYTES,
+		ACCESS_FIELD_REMOTE_IP, ACCESS_FIELD_REQUEST_ID, ACCESS_FIELD_USER_AGENT, ACCESS_FIELD_REFERER, ACCESS_FIELD_HOST, ACCESS_FIELD_PROTO:
+		return true
+	}
+	return false
+}
+
+// response writer records status code and body size
+type accessResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (aw *accessResponseWriter) WriteHeader(status int) {
+	if !aw.wroteHeader {
+		aw.status = status
+		aw.wroteHeader = true
+	}
+	aw.ResponseWriter.WriteHeader(status)
+}
+
+func (aw *accessResponseWriter) Write(b []byte) (int, error) {
+	aw.wroteHeader = true
+	n, err := aw.ResponseWriter.Write(b)
+	aw.bytes += int64(n)
+	return n, err
+}
+
+func (aw *accessResponseWriter) Flush() {
+	flusher, ok := aw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// response writer of http.ResponseController
+func (aw *accessResponseWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
@@ -0,0 +1,95 @@
+package go_logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLogger_AccessLogHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("orders"))
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("broken")
+	})
+	handler := logger.RequestIdHandler(logger.AccessLogHandler(mux, &AccessLogConfig{
+		Fields:      append(accessLogDefaultFields, ACCESS_FIELD_QUERY),
+		ExtraFields: func(r *http.Request) map[string]interface{} { return map[string]interface{}{"user": "alice"} },
+		TrustProxy:  true,
+	}))
+
+	r := httptest.NewRequest("GET", "/orders?page=2", nil)
+	r.Header.Set(REQUEST_ID_HEADER, "req-1")
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	r.Header.Set("User-Agent", "curl/8.0")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	entry := recorded.LastEntry()
+	if entry == nil || entry.Body != "GET /orders 200" || entry.Level != LOGGER_LEVEL_INFO {
+		t.Fatalf("access log record error: %v", entry)
+	}
+	expected := map[string]interface{}{
+		"category": "access", "method": "GET", "path": "/orders", "query": "page=2", "status": 200, "bytes": int64(6),
+		"remote_ip": "203.0.113.7", "request_id": "req-1", "user_agent": "curl/8.0", "user": "alice",
+	}
+	for name, value := range expected {
+		if entry.Fields[name] != value {
+			t.Errorf("access log field %s error: %v", name, entry.Fields[name])
+		}
+	}
+	if _, ok := entry.Fields[ACCESS_FIELD_LATENCY].(float64); !ok {
+		t.Errorf("access log latency error: %v", entry.Fields[ACCESS_FIELD_LATENCY])
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/missing", nil))
+	entry = recorded.LastEntry()
+	if entry.Body != "POST /missing 404" || entry.Level != LOGGER_LEVEL_WARNING || entry.Fields["remote_ip"] != "192.0.2.1" || entry.Fields["request_id"] == "" {
+		t.Errorf("access log 404 error: %v", entry)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("access log panic is not raised")
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+	}()
+	entry = recorded.LastEntry()
+	if entry.Body != "GET /panic 500" || entry.Level != LOGGER_LEVEL_ERROR {
+		t.Errorf("access log panic error: %v", entry)
+	}
+}
+
+func TestAccessLog_SampleSuccess(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+
+	accessLog := logger.AccessLog(&AccessLogConfig{SampleSuccess: 3, Fields: []string{ACCESS_FIELD_STATUS}, Category: "api"})
+	r := httptest.NewRequest("GET", "/health", nil)
+	for i := 0; i < 7; i++ {
+		accessLog.Log(r, http.StatusOK, 0, time.Millisecond)
+	}
+	accessLog.Log(r, http.StatusBadGateway, 0, time.Millisecond)
+	accessLog.Log(r, http.StatusFound, 0, time.Millisecond)
+
+	entries := recorded.Entries()
+	if len(entries) != 5 || entries[3].Body != "GET /health 502" || entries[4].Body != "GET /health 302" {
+		t.Fatalf("access log sampling error: %d records", len(entries))
+	}
+	if len(entries[0].Fields) != 2 || entries[0].Fields["category"] != "api" {
+		t.Errorf("access log fields error: %v", entries[0].Fields)
+	}
+}
@@ -0,0 +1,260 @@
+package go_logger
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const AGGREGATE_ADAPTER_NAME = "aggregate"
+
+const (
+	AGGREGATE_DEFAULT_INTERVAL   = time.Minute
+	AGGREGATE_DEFAULT_TOP_ERRORS = 5
+
+	// distinct error bodies and latency samples kept in a window
+	AGGREGATE_MAX_ERRORS  = 1000
+	AGGREGATE_MAX_SAMPLES = 10000
+)
+
+// fields of aggregate summaries
+const (
+	LOGGER_FIELD_AGGREGATE_START       = "aggregate.start"
+	LOGGER_FIELD_AGGREGATE_END         = "aggregate.end"
+	LOGGER_FIELD_AGGREGATE_COUNT       = "aggregate.count"
+	LOGGER_FIELD_AGGREGATE_LEVELS      = "aggregate.levels"
+	LOGGER_FIELD_AGGREGATE_TOP_ERRORS  = "aggregate.top_errors"
+	LOGGER_FIELD_AGGREGATE_LATENCY_P95 = "aggregate.latency_p95"
+)
+
+// adapter aggregate, messages are counted in time windows and a summary record is written to the sink every Interval
+// summaries are info messages with fields "aggregate.count", "aggregate.levels" (count of every level),
+// "aggregate.top_errors" (most frequent bodies of messages at or above error level) and "aggregate.latency_p95" of LatencyField
+type AdapterAggregate struct {
+	lock    sync.Mutex
+	config  *AggregateConfig
+	start   time.Time
+	count   int64
+	levels  map[int]int64
+	errors  map[string]int64
+	samples []float64
+	seen    int64 // latency values of the window, samples are a reservoir of them
+	ticker  *time.Ticker
+	quit    chan struct{}
+}
+
+// aggregate config
+type AggregateConfig struct {
+
+	// adapter of summaries, must be initialized, eg: a file adapter
+	Sink LoggerAbstract
+
+	// length of windows, default 1m
+	Interval time.Duration
+
+	// number field of the latency, eg: "latency_ms", time.Duration values are milliseconds
+	// empty is no latency
+	LatencyField string
+
+	// most frequent error bodies of summaries, default 5
+	TopErrors int
+}
+
+func (ac *AggregateConfig) Name() string {
+	return AGGREGATE_ADAPTER_NAME
+}
+
+func NewAdapterAggregate() LoggerAbstract {
+	return &AdapterAggregate{}
+}
+
+func (adapterAggregate *AdapterAggregate) Init(aggregateConfig Config) error {
+	ac, ok := aggregateConfig.(*AggregateConfig)
+	if !ok || ac == nil {
+		return configTypeError(AGGREGATE_ADAPTER_NAME, "AggregateConfig")
+	}
+	adapterAggregate.config = ac
+
+	if ac.Sink == nil {
+		return configError(AGGREGATE_ADAPTER_NAME, "Sink", "cannot be empty!")
+	}
+	if ac.Interval <= 0 {
+		ac.Interval = AGGREGATE_DEFAULT_INTERVAL
+	}
+	if ac.TopErrors <= 0 {
+		ac.TopErrors = AGGREGATE_DEFAULT_TOP_ERRORS
+	}
+	adapterAggregate.reset(time.Now())
+
+	adapterAggregate.ticker = time.NewTicker(ac.Interval)
+	adapterAggregate.quit = make(chan struct{})
+	go adapterAggregate.startEmit(adapterAggregate.ticker, adapterAggregate.quit)
+	return nil
+}
+
+// count the message in the current window
+func (adapterAggregate *AdapterAggregate) Write(loggerMsg *loggerMessage) error {
+	adapterAggregate.lock.Lock()
+	defer adapterAggregate.lock.Unlock()
+
+	adapterAggregate.count++
+	adapterAggregate.levels[loggerMsg.Level]++
+	if loggerMsg.Level <= LOGGER_LEVEL_ERROR {
+		_, ok := adapterAggregate.errors[loggerMsg.Body]
+		if ok || len(adapterAggregate.errors) < AGGREGATE_MAX_ERRORS {
+			adapterAggregate.errors[loggerMsg.Body]++
+		}
+	}
+	if adapterAggregate.config.LatencyField != "" {
+		if latency, ok := aggregateNumber(loggerMsg.Fields[adapterAggregate.config.LatencyField]); ok {
+			adapterAggregate.sample(latency)
+		}
+	}
+	return nil
+}
+
+// flush the sink, windows are written every Interval
+func (adapterAggregate *AdapterAggregate) Flush() {
+	adapterAggregate.config.Sink.Flush()
+}
+
+// stop windows, write the summary of the current window and close the sink
+func (adapterAggregate *AdapterAggregate) Close() error {
+	adapterAggregate.lock.Lock()
+	if adapterAggregate.ticker != nil {
+		adapterAggregate.ticker.Stop()
+		close(adapterAggregate.quit)
+		adapterAggregate.ticker = nil
+	}
+	adapterAggregate.lock.Unlock()
+
+	adapterAggregate.emit(time.Now())
+	adapterAggregate.config.Sink.Flush()
+	if closer, ok := adapterAggregate.config.Sink.(LoggerCloser); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (adapterAggregate *AdapterAggregate) Name() string {
+	return AGGREGATE_ADAPTER_NAME
+}
+
+// write summaries every Interval
+func (adapterAggregate *AdapterAggregate) startEmit(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case now := <-ticker.C:
+			adapterAggregate.emit(now)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// write the summary of the window ended at now and start a new window, empty windows are skipped
+func (adapterAggregate *AdapterAggregate) emit(now time.Time) {
+	adapterAggregate.lock.Lock()
+	if adapterAggregate.count == 0 {
+		adapterAggregate.start = now
+		adapterAggregate.lock.Unlock()
+		return
+	}
+	loggerMsg := adapterAggregate.summary(now)
+	adapterAggregate.reset(now)
+	adapterAggregate.lock.Unlock()
+
+	err := adapterAggregate.config.Sink.Write(loggerMsg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: aggregate adapter write failed, error: %v\n", err)
+	}
+}
+
+// summary message of the window, call it after lock
+func (adapterAggregate *AdapterAggregate) summary(now time.Time) *loggerMessage {
+	levels := make(map[string]int64, len(adapterAggregate.levels))
+	errorCount := int64(0)
+	for level, count := range adapterAggregate.levels {
+		levels[levelNames()[level]] = count
+		if level <= LOGGER_LEVEL_ERROR {
+			errorCount += count
+		}
+	}
+
+	bodies := make([]string, 0, len(adapterAggregate.errors))
+	for body := range adapterAggregate.errors {
+		bodies = append(bodies, body)
+	}
+	sort.Slice(bodies, func(i, j int) bool {
+		ci, cj := adapterAggregate.errors[bodies[i]], adapterAggregate.errors[bodies[j]]
+		return ci > cj || ci == cj && bodies[i] < bodies[j]
+	})
+	if len(bodies) > adapterAggregate.config.TopErrors {
+		bodies = bodies[:adapterAggregate.config.TopErrors]
+	}
+	topErrors := make([]map[string]interface{}, len(bodies))
+	for i, body := range bodies {
+		topErrors[i] = map[string]interface{}{"body": body, "count": adapterAggregate.errors[body]}
+	}
+
+	fields := map[string]interface{}{
+		LOGGER_FIELD_AGGREGATE_START:      adapterAggregate.start.Format(time.RFC3339),
+		LOGGER_FIELD_AGGREGATE_END:        now.Format(time.RFC3339),
+		LOGGER_FIELD_AGGREGATE_COUNT:      adapterAggregate.count,
+		LOGGER_FIELD_AGGREGATE_LEVELS:     levels,
+		LOGGER_FIELD_AGGREGATE_TOP_ERRORS: topErrors,
+	}
+	if len(adapterAggregate.samples) > 0 {
+		sort.Float64s(adapterAggregate.samples)
+		fields[LOGGER_FIELD_AGGREGATE_LATENCY_P95] = adapterAggregate.samples[(len(adapterAggregate.samples)-1)*95/100]
+	}
+	msg := "aggregate: " + strconv.FormatInt(adapterAggregate.count, 10) + " messages, " +
+		strconv.FormatInt(errorCount, 10) + " errors in " + now.Sub(adapterAggregate.start).Round(time.Second).String()
+	return newLoggerMessage(now, LOGGER_LEVEL_INFO, msg, fields)
+}
+
+// start a new window, call it after lock
+func (adapterAggregate *AdapterAggregate) reset(now time.Time) {
+	adapterAggregate.start = now
+	adapterAggregate.count = 0
+	adapterAggregate.levels = map[int]int64{}
+	adapterAggregate.errors = map[string]int64{}
+	adapterAggregate.samples = nil
+	adapterAggregate.seen = 0
+}
+
+// add the latency to the reservoir of the window, call it after lock
+func (adapterAggregate *AdapterAggregate) sample(latency float64) {
+	adapterAggregate.seen++
+	if len(adapterAggregate.samples) < AGGREGATE_MAX_SAMPLES {
+		adapterAggregate.samples = append(adapterAggregate.samples, latency)
+		return
+	}
+	if i := rand.Int63n(adapterAggregate.seen); i < AGGREGATE_MAX_SAMPLES {
+		adapterAggregate.samples[i] = latency
+	}
+}
+
+// number of the field value, time.Duration is milliseconds
+func aggregateNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case time.Duration:
+		return float64(number) / float64(time.Millisecond), true
+	case int32:
+		return float64(number), true
+	case float32:
+		return float64(number), true
+	}
+	return configNumber(value)
+}
+
+func init() {
+	Register(AGGREGATE_ADAPTER_NAME, NewAdapterAggregate)
+	RegisterConfig(AGGREGATE_ADAPTER_NAME, func() Config {
+		return &AggregateConfig{}
+	})
+}
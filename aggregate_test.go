@@ -0,0 +1,107 @@
+package go_logger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestAggregate(t *testing.T, config *AggregateConfig) (*AdapterAggregate, *AdapterMemory) {
+	sink := NewAdapterMemory().(*AdapterMemory)
+	sink.Init(&MemoryConfig{})
+	config.Sink = sink
+	adapterAggregate := NewAdapterAggregate().(*AdapterAggregate)
+	if err := adapterAggregate.Init(config); err != nil {
+		t.Fatalf("aggregate init error: %s", err)
+	}
+	return adapterAggregate, sink
+}
+
+func TestAdapterAggregate_Init(t *testing.T) {
+	err := NewAdapterAggregate().Init(&AggregateConfig{})
+	if err == nil || err.Error() != "config Sink cannot be empty!" {
+		t.Errorf("aggregate init error: %v", err)
+	}
+}
+
+func TestAdapterAggregate_Summary(t *testing.T) {
+
+	adapterAggregate, sink := newTestAggregate(t, &AggregateConfig{
+		Interval:     time.Hour,
+		LatencyField: "latency",
+		TopErrors:    2,
+	})
+	defer adapterAggregate.Close()
+
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_INFO, "request", map[string]interface{}{"latency": i + 1}))
+	}
+	for i := 0; i < 3; i++ {
+		adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_ERROR, "db timeout", nil))
+	}
+	adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_CRITICAL, "disk full", nil))
+	adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_ERROR, "cache miss", map[string]interface{}{"latency": 2 * time.Second}))
+	adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_WARNING, "slow", nil))
+
+	adapterAggregate.emit(adapterAggregate.start.Add(time.Minute))
+	messages := sink.messages()
+	if len(messages) != 1 {
+		t.Fatalf("aggregate summaries %d, expect 1", len(messages))
+	}
+	summary := messages[0]
+	if summary.Level != LOGGER_LEVEL_INFO || summary.Body != "aggregate: 106 messages, 5 errors in 1m0s" {
+		t.Errorf("aggregate summary error: %s %q", summary.LevelString, summary.Body)
+	}
+	if summary.Fields[LOGGER_FIELD_AGGREGATE_COUNT] != int64(106) {
+		t.Errorf("aggregate count error: %v", summary.Fields[LOGGER_FIELD_AGGREGATE_COUNT])
+	}
+	levels := map[string]int64{"Info": 100, "Error": 4, "Critical": 1, "Warning": 1}
+	if !reflect.DeepEqual(summary.Fields[LOGGER_FIELD_AGGREGATE_LEVELS], levels) {
+		t.Errorf("aggregate levels error: %v", summary.Fields[LOGGER_FIELD_AGGREGATE_LEVELS])
+	}
+	topErrors := []map[string]interface{}{
+		{"body": "db timeout", "count": int64(3)},
+		{"body": "cache miss", "count": int64(1)},
+	}
+	if !reflect.DeepEqual(summary.Fields[LOGGER_FIELD_AGGREGATE_TOP_ERRORS], topErrors) {
+		t.Errorf("aggregate top errors error: %v", summary.Fields[LOGGER_FIELD_AGGREGATE_TOP_ERRORS])
+	}
+	// 101 samples: 1 - 100 and 2000ms
+	if summary.Fields[LOGGER_FIELD_AGGREGATE_LATENCY_P95] != float64(96) {
+		t.Errorf("aggregate latency p95 error: %v", summary.Fields[LOGGER_FIELD_AGGREGATE_LATENCY_P95])
+	}
+
+	// the window is reset, empty windows are skipped
+	adapterAggregate.emit(time.Now())
+	adapterAggregate.Write(newLoggerMessage(now, LOGGER_LEVEL_DEBUG, "debug", nil))
+	adapterAggregate.emit(time.Now())
+	messages = sink.messages()
+	if len(messages) != 2 || !strings.HasPrefix(messages[1].Body, "aggregate: 1 messages, 0 errors") {
+		t.Fatalf("aggregate next window error: %d", len(messages))
+	}
+	if _, ok := messages[1].Fields[LOGGER_FIELD_AGGREGATE_LATENCY_P95]; ok {
+		t.Errorf("aggregate latency of window without samples")
+	}
+}
+
+func TestAdapterAggregate_Interval(t *testing.T) {
+
+	adapterAggregate, sink := newTestAggregate(t, &AggregateConfig{Interval: 20 * time.Millisecond})
+	adapterAggregate.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "tick", nil))
+
+	deadline := time.Now().Add(time.Second)
+	for len(sink.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(sink.messages()) != 1 {
+		t.Fatalf("aggregate summary of interval is not written")
+	}
+
+	adapterAggregate.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "close", nil))
+	adapterAggregate.Close()
+	if len(sink.messages()) != 2 {
+		t.Errorf("aggregate summary of close is not written")
+	}
+}
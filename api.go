@@ -1,18 +1,50 @@
 package go_logger
 
 import (
-	"errors"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
-	"github.com/phachon/go-logger/utils"
-	"reflect"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const API_ADAPTER_NAME = "api"
 
+// body formats of batches
+const (
+	API_BATCH_FORMAT_NDJSON = "ndjson"
+	API_BATCH_FORMAT_JSON   = "json"
+)
+
+const (
+	API_DEFAULT_FLUSH_INTERVAL = 5 * time.Second
+	API_DEFAULT_TIMEOUT        = 10 * time.Second
+	API_DEFAULT_RETRY_BACKOFF  = 500 * time.Millisecond
+	API_MAX_RETRY_BACKOFF      = 30 * time.Second
+)
+
 // adapter api
 type AdapterApi struct {
+	// counters are first for 64-bit atomic alignment on 32-bit platforms
+	encodingErrors int64 // json fallbacks of marshal errors
+	retries        int64 // retried requests
+
+	lock   sync.Mutex
 	config *ApiConfig
+	client *http.Client
+	buffer []*loggerMessage
+	ticker *time.Ticker
+	quit   chan struct{}
 }
 
 // api config
@@ -33,6 +65,37 @@ type ApiConfig struct {
 
 	// verify response http code
 	VerifyCode int
+
+	// strip ansi escapes and control characters (except "\t" and "\n") from body and fields
+	StripControl bool
+
+	// max messages of one request, default 1 is a form request of every message
+	// batches are POST requests of json records, BatchFormat API_BATCH_FORMAT_NDJSON (default) or API_BATCH_FORMAT_JSON (array)
+	BatchSize   int
+	BatchFormat string
+
+	// buffered messages are sent every FlushInterval, default 5s
+	FlushInterval time.Duration
+
+	// gzip the body of batches, "Content-Encoding: gzip"
+	Gzip bool
+
+	// retries of network errors and 5xx responses, the backoff doubles from RetryBackoff (default 500ms) up to 30s
+	MaxRetries   int
+	RetryBackoff time.Duration
+
+	// header "Authorization: Bearer <token>"
+	BearerToken string
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// client certificate and key files (PEM) of https Url, added to TLSConfig
+	CertFile string
+	KeyFile  string
+
+	// tls config of https Url
+	TLSConfig *tls.Config
 }
 
 func (ac *ApiConfig) Name() string {
@@ -45,33 +108,90 @@ func NewAdapterApi() LoggerAbstract {
 
 func (adapterApi *AdapterApi) Init(apiConfig Config) error {
 
-	if apiConfig.Name() != API_ADAPTER_NAME {
-		return errors.New("logger api adapter init error, config must ApiConfig")
+	ac, ok := apiConfig.(*ApiConfig)
+	if !ok || ac == nil {
+		return configTypeError(API_ADAPTER_NAME, "ApiConfig")
 	}
-
-	vc := reflect.ValueOf(apiConfig)
-	ac := vc.Interface().(*ApiConfig)
 	adapterApi.config = ac
 
 	if adapterApi.config.Url == "" {
-		return errors.New("config Url cannot be empty!")
+		return configError(API_ADAPTER_NAME, "Url", "cannot be empty!")
 	}
 	if adapterApi.config.Method != "GET" && adapterApi.config.Method != "POST" {
-		return errors.New("config Method must one of the 'GET', 'POST'!")
+		return configError(API_ADAPTER_NAME, "Method", "must one of the 'GET', 'POST'!")
 	}
 	if adapterApi.config.IsVerify && (adapterApi.config.VerifyCode == 0) {
-		return errors.New("config if IsVerify is true, VerifyCode cannot be 0!")
+		return configError(API_ADAPTER_NAME, "VerifyCode", "cannot be 0 if IsVerify is true!")
+	}
+	if ac.BatchSize <= 0 {
+		ac.BatchSize = 1
+	}
+	if ac.BatchSize > 1 && ac.Method != "POST" {
+		return configError(API_ADAPTER_NAME, "Method", "must be 'POST' if BatchSize > 1!")
+	}
+	if ac.BatchFormat == "" {
+		ac.BatchFormat = API_BATCH_FORMAT_NDJSON
+	}
+	if ac.BatchFormat != API_BATCH_FORMAT_NDJSON && ac.BatchFormat != API_BATCH_FORMAT_JSON {
+		return configError(API_ADAPTER_NAME, "BatchFormat", "must one of the 'ndjson', 'json'!")
+	}
+	if ac.FlushInterval <= 0 {
+		ac.FlushInterval = API_DEFAULT_FLUSH_INTERVAL
+	}
+	if ac.RetryBackoff <= 0 {
+		ac.RetryBackoff = API_DEFAULT_RETRY_BACKOFF
+	}
+	if ac.Timeout <= 0 {
+		ac.Timeout = API_DEFAULT_TIMEOUT
+	}
+
+	tlsConfig := ac.TLSConfig
+	if ac.CertFile != "" || ac.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(ac.CertFile, ac.KeyFile)
+		if err != nil {
+			return err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	adapterApi.client = &http.Client{
+		Timeout: ac.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	if ac.BatchSize > 1 {
+		adapterApi.ticker = time.NewTicker(ac.FlushInterval)
+		adapterApi.quit = make(chan struct{})
+		go adapterApi.startFlush(adapterApi.ticker, adapterApi.quit)
 	}
 	return nil
 }
 
 func (adapterApi *AdapterApi) Write(loggerMsg *loggerMessage) error {
 
-	url := adapterApi.config.Url
-	method := adapterApi.config.Method
-	isVerify := adapterApi.config.IsVerify
-	verifyCode := adapterApi.config.VerifyCode
-	headers := adapterApi.config.Headers
+	if adapterApi.config.StripControl {
+		loggerMsg = sanitizeLoggerMessage(loggerMsg)
+	}
+	if adapterApi.config.BatchSize > 1 {
+		adapterApi.lock.Lock()
+		adapterApi.buffer = append(adapterApi.buffer, loggerMsg)
+		if len(adapterApi.buffer) < adapterApi.config.BatchSize {
+			adapterApi.lock.Unlock()
+			return nil
+		}
+		buffer := adapterApi.buffer
+		adapterApi.buffer = []*loggerMessage{}
+		adapterApi.lock.Unlock()
+
+		return adapterApi.batch(buffer)
+	}
 
 	loggerMap := map[string]string{
 		"timestamp":          strconv.FormatInt(loggerMsg.Timestamp, 10),
@@ -85,32 +205,201 @@ func (adapterApi *AdapterApi) Write(loggerMsg *loggerMessage) error {
 		"line":               strconv.Itoa(loggerMsg.Line),
 		"function":           loggerMsg.Function,
 	}
-
-	var err error
-	var code int
-	if method == "GET" {
-		_, code, err = utils.NewMisc().HttpGet(url, loggerMap, headers, 0)
-	} else {
-		_, code, err = utils.NewMisc().HttpPost(url, loggerMap, headers, 0)
+	if len(loggerMsg.Fields) > 0 {
+		fieldsByte, _ := json.Marshal(loggerMsg.Fields)
+		loggerMap["fields"] = string(fieldsByte)
 	}
-	if err != nil {
-		return err
+
+	queryValues := []string{}
+	for queryKey, queryValue := range loggerMap {
+		queryValues = append(queryValues, queryKey+"="+url.QueryEscape(queryValue))
 	}
-	if isVerify && (code != verifyCode) {
-		return fmt.Errorf("%s", "request "+url+" faild, code="+strconv.Itoa(code))
+	queryString := strings.Join(queryValues, "&")
+	queryUrl := adapterApi.config.Url
+	if !strings.Contains(queryUrl, "?") {
+		queryUrl += "?"
 	}
+	queryUrl += queryString
 
-	return nil
+	return adapterApi.request(func() (*http.Request, error) {
+		if adapterApi.config.Method == "GET" {
+			return http.NewRequest("GET", queryUrl, nil)
+		}
+		req, err := http.NewRequest("POST", queryUrl, strings.NewReader(queryString))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return req, err
+	})
 }
 
+// send buffered messages
 func (adapterApi *AdapterApi) Flush() {
+	if adapterApi.config.BatchSize <= 1 {
+		return
+	}
+	adapterApi.lock.Lock()
+	buffer := adapterApi.buffer
+	adapterApi.buffer = []*loggerMessage{}
+	adapterApi.lock.Unlock()
+
+	err := adapterApi.batch(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: api adapter flush failed, error: %v\n", err)
+	}
+}
 
+// stop flush ticker and send buffered messages
+func (adapterApi *AdapterApi) Close() error {
+	if adapterApi.ticker != nil {
+		adapterApi.ticker.Stop()
+		close(adapterApi.quit)
+		adapterApi.ticker = nil
+	}
+	adapterApi.Flush()
+	return nil
 }
 
 func (adapterApi *AdapterApi) Name() string {
 	return API_ADAPTER_NAME
 }
 
+func (adapterApi *AdapterApi) Capabilities() Capabilities {
+	batching := adapterApi.config != nil && adapterApi.config.BatchSize > 1
+	return Capabilities{Batching: batching, NeedsFlush: batching, Remote: true}
+}
+
+// HEAD request of Url with headers
+func (adapterApi *AdapterApi) HealthCheck() error {
+	return healthCheckHttp(adapterApi.client, "HEAD", adapterApi.config.Url, func(req *http.Request) {
+		for key, value := range adapterApi.config.Headers {
+			req.Header.Set(key, value)
+		}
+		if adapterApi.config.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+adapterApi.config.BearerToken)
+		}
+	})
+}
+
+// Counters of json fallbacks and retried requests
+func (adapterApi *AdapterApi) Counters() map[string]int64 {
+	return map[string]int64{
+		COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterApi.encodingErrors),
+		"retries":               atomic.LoadInt64(&adapterApi.retries),
+	}
+}
+
+// flush buffer every FlushInterval
+func (adapterApi *AdapterApi) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterApi.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// POST messages as json records of BatchFormat
+func (adapterApi *AdapterApi) batch(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+	body := &bytes.Buffer{}
+	var writer io.Writer = body
+	var gzipWriter *gzip.Writer
+	if adapterApi.config.Gzip {
+		gzipWriter = gzip.NewWriter(body)
+		writer = gzipWriter
+	}
+	contentType := "application/x-ndjson"
+	if adapterApi.config.BatchFormat == API_BATCH_FORMAT_JSON {
+		contentType = "application/json"
+		writer.Write([]byte("["))
+	}
+	for i, loggerMsg := range loggerMsgs {
+		if i > 0 && adapterApi.config.BatchFormat == API_BATCH_FORMAT_JSON {
+			writer.Write([]byte(","))
+		}
+		writer.Write(marshalLoggerMessage(loggerMsg, &adapterApi.encodingErrors))
+		if adapterApi.config.BatchFormat == API_BATCH_FORMAT_NDJSON {
+			writer.Write([]byte("\n"))
+		}
+	}
+	if adapterApi.config.BatchFormat == API_BATCH_FORMAT_JSON {
+		writer.Write([]byte("]"))
+	}
+	if gzipWriter != nil {
+		err := gzipWriter.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	payload := body.Bytes()
+	return adapterApi.request(func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", adapterApi.config.Url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if adapterApi.config.Gzip {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		return req, nil
+	})
+}
+
+// send the request with headers, network errors and 5xx responses are retried with backoff
+// response code is checked by VerifyCode if IsVerify is true, batches must be 2xx otherwise
+func (adapterApi *AdapterApi) request(newRequest func() (*http.Request, error)) error {
+	config := adapterApi.config
+	backoff := config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+		for key, value := range config.Headers {
+			req.Header.Set(key, value)
+		}
+		if config.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+config.BearerToken)
+		}
+
+		code := 0
+		resp, err := adapterApi.client.Do(req)
+		if err == nil {
+			code = resp.StatusCode
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if (err != nil || code >= 500) && attempt < config.MaxRetries {
+			atomic.AddInt64(&adapterApi.retries, 1)
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > API_MAX_RETRY_BACKOFF {
+				backoff = API_MAX_RETRY_BACKOFF
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if config.IsVerify && (code != config.VerifyCode) {
+			return fmt.Errorf("%s", "request "+config.Url+" faild, code="+strconv.Itoa(code))
+		}
+		if !config.IsVerify && config.BatchSize > 1 && code/100 != 2 {
+			return fmt.Errorf("%s", "request "+config.Url+" faild, code="+strconv.Itoa(code))
+		}
+		return nil
+	}
+}
+
 func init() {
 	Register(API_ADAPTER_NAME, NewAdapterApi)
+	RegisterConfig(API_ADAPTER_NAME, func() Config {
+		return &ApiConfig{}
+	})
 }
@@ -0,0 +1,160 @@
+package go_logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdapterApi_Init(t *testing.T) {
+
+	for _, config := range []*ApiConfig{
+		{Url: "http://127.0.0.1", Method: "GET", BatchSize: 10},
+		{Url: "http://127.0.0.1", Method: "POST", BatchFormat: "xml"},
+		{Url: "http://127.0.0.1", Method: "POST", CertFile: "./not_exist.pem", KeyFile: "./not_exist.key"},
+	} {
+		if err := NewAdapterApi().Init(config); err == nil {
+			t.Errorf("api init %+v must be error", config)
+		}
+	}
+}
+
+func TestAdapterApi_Write(t *testing.T) {
+
+	lock := sync.Mutex{}
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		lock.Lock()
+		requests = append(requests, r)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	for _, method := range []string{"GET", "POST"} {
+		requests = nil
+		adapterApi := NewAdapterApi()
+		err := adapterApi.Init(&ApiConfig{
+			Url:         server.URL + "/logs",
+			Method:      method,
+			Headers:     map[string]string{"X-App": "shop"},
+			BearerToken: "secret",
+		})
+		if err != nil {
+			t.Fatalf("api init error: %s", err)
+		}
+		err = adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "order failed", map[string]interface{}{"order": 7}))
+		if err != nil {
+			t.Fatalf("api write error: %s", err)
+		}
+		if len(requests) != 1 {
+			t.Fatalf("api %s requests %d, expect 1", method, len(requests))
+		}
+		r := requests[0]
+		if r.Method != method || r.URL.Path != "/logs" || r.Form.Get("body") != "order failed" || r.Form.Get("level_string") != "Error" || r.Form.Get("fields") != `{"order":7}` {
+			t.Errorf("api %s request error: %s %s %v", method, r.Method, r.URL.Path, r.Form)
+		}
+		if r.Header.Get("X-App") != "shop" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("api %s request headers error: %v", method, r.Header)
+		}
+	}
+}
+
+func TestAdapterApi_Batch(t *testing.T) {
+
+	lock := sync.Mutex{}
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader := r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, _ = gzip.NewReader(r.Body)
+		}
+		body, _ := ioutil.ReadAll(reader)
+		lock.Lock()
+		bodies = append(bodies, r.Header.Get("Content-Type")+" "+string(body))
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	adapterApi := NewAdapterApi().(*AdapterApi)
+	err := adapterApi.Init(&ApiConfig{Url: server.URL, Method: "POST", BatchSize: 2, Gzip: true, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("api init error: %s", err)
+	}
+	if !adapterApi.Capabilities().Batching {
+		t.Errorf("api batch capabilities error")
+	}
+	for _, body := range []string{"a", "b", "c"} {
+		adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, body, nil))
+	}
+	if len(bodies) != 1 || strings.Count(bodies[0], "\n") != 2 || !strings.HasPrefix(bodies[0], "application/x-ndjson {") {
+		t.Fatalf("api ndjson batch error: %q", bodies)
+	}
+	adapterApi.Close()
+	if len(bodies) != 2 || !strings.Contains(bodies[1], `"body":"c"`) {
+		t.Fatalf("api close must send buffered messages: %q", bodies)
+	}
+
+	bodies = nil
+	adapterApi = NewAdapterApi().(*AdapterApi)
+	adapterApi.Init(&ApiConfig{Url: server.URL, Method: "POST", BatchSize: 10, BatchFormat: API_BATCH_FORMAT_JSON, FlushInterval: 10 * time.Millisecond})
+	defer adapterApi.Close()
+	adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "a", nil))
+	adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "b", nil))
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		lock.Lock()
+		n := len(bodies)
+		lock.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(bodies) != 1 || !strings.HasPrefix(bodies[0], "application/json [") {
+		t.Fatalf("api json batch of flush interval error: %q", bodies)
+	}
+	records := []map[string]interface{}{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(bodies[0], "application/json ")), &records); err != nil || len(records) != 2 {
+		t.Errorf("api json batch must be an array: %s %q", err, bodies[0])
+	}
+}
+
+func TestAdapterApi_Retry(t *testing.T) {
+
+	lock := sync.Mutex{}
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		attempts++
+		n := attempts
+		lock.Unlock()
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	adapterApi := NewAdapterApi().(*AdapterApi)
+	adapterApi.Init(&ApiConfig{Url: server.URL, Method: "POST", IsVerify: true, VerifyCode: 200, MaxRetries: 3, RetryBackoff: time.Millisecond})
+	if err := adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "retry", nil)); err != nil {
+		t.Errorf("api retry error: %s", err)
+	}
+	if attempts != 3 || adapterApi.Counters()["retries"] != 2 {
+		t.Errorf("api attempts %d retries %d, expect 3 and 2", attempts, adapterApi.Counters()["retries"])
+	}
+
+	attempts = -10
+	adapterApi.config.MaxRetries = 1
+	if err := adapterApi.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "failed", nil)); err == nil || !strings.Contains(err.Error(), "code=503") {
+		t.Errorf("api retries exhausted must be error: %v", err)
+	}
+}
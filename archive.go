@@ -0,0 +1,298 @@
+package go_logger
+
+import (
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ARCHIVE_DEFAULT_TIMEOUT = 5 * time.Minute
+
+// s3 uploader of bak files, FileConfig.Uploader
+// requests are signed by aws signature v4, s3 compatible storages (minio, ceph, r2, gcs interoperability) are supported by Endpoint
+//
+// example:
+//	&go_logger.FileConfig{
+//		Filename: "./app.log",
+//		MaxBak:   7,
+//		Uploader: &go_logger.S3Uploader{Region: "eu-west-1", Bucket: "logs", Prefix: "app/", AccessKey: "...", SecretKey: "..."},
+//	}
+type S3Uploader struct {
+
+	// endpoint, default "https://s3.<Region>.amazonaws.com", eg: "https://storage.googleapis.com", "http://127.0.0.1:9000"
+	Endpoint string
+
+	// signing region, default "us-east-1"
+	Region string
+
+	Bucket string
+
+	// key prefix, the key is Prefix + name of the bak file, eg: "app/" is "app/app_20240102.log"
+	Prefix string
+
+	// credentials, SessionToken of temporary credentials
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// bucket in the path, "<Endpoint>/<Bucket>/<key>", otherwise "<Bucket>.<Endpoint host>/<key>"
+	PathStyle bool
+
+	// storage class, eg: "STANDARD_IA", "GLACIER"
+	StorageClass string
+
+	// gzip the file before upload, the key is suffixed with ".gz", ".gz" files are uploaded as is
+	Gzip bool
+
+	// http client, default timeout 5m
+	Client *http.Client
+}
+
+// upload the file, return "s3://<Bucket>/<key>"
+func (uploader *S3Uploader) Upload(path string) (string, error) {
+	if uploader.Bucket == "" {
+		return "", configError("", "Bucket", "cannot be empty!")
+	}
+	region := uploader.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := uploader.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	file, key, cleanup, err := archiveFile(path, uploader.Prefix, uploader.Gzip)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	// payload hash of the signature
+	payloadHash := sha256.New()
+	size, err := io.Copy(payloadHash, file)
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	objectUrl := *endpointUrl
+	if uploader.PathStyle {
+		objectUrl.Path = strings.TrimSuffix(objectUrl.Path, "/") + "/" + uploader.Bucket + "/" + key
+	} else {
+		objectUrl.Host = uploader.Bucket + "." + objectUrl.Host
+		objectUrl.Path = strings.TrimSuffix(objectUrl.Path, "/") + "/" + key
+	}
+	req, err := http.NewRequest(http.MethodPut, objectUrl.String(), ioutil.NopCloser(file))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "text/plain")
+	if strings.HasSuffix(key, ".gz") {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	if uploader.StorageClass != "" {
+		req.Header.Set("X-Amz-Storage-Class", uploader.StorageClass)
+	}
+	if uploader.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", uploader.SessionToken)
+	}
+	signV4(req, hex.EncodeToString(payloadHash.Sum(nil)), time.Now(), region, "s3", uploader.AccessKey, uploader.SecretKey)
+
+	err = archivePut(uploader.Client, req)
+	if err != nil {
+		return "", err
+	}
+	return "s3://" + uploader.Bucket + "/" + key, nil
+}
+
+// gcs uploader of bak files by the json api, FileConfig.Uploader
+// S3Uploader with Endpoint "https://storage.googleapis.com" and hmac keys is supported too
+type GcsUploader struct {
+
+	// endpoint, default "https://storage.googleapis.com"
+	Endpoint string
+
+	Bucket string
+
+	// object prefix, the object is Prefix + name of the bak file
+	Prefix string
+
+	// oauth2 access token of every upload, eg: golang.org/x/oauth2 TokenSource
+	Token func() (string, error)
+
+	// gzip the file before upload, the object is suffixed with ".gz", ".gz" files are uploaded as is
+	Gzip bool
+
+	// http client, default timeout 5m
+	Client *http.Client
+}
+
+// upload the file, return "gs://<Bucket>/<object>"
+func (uploader *GcsUploader) Upload(path string) (string, error) {
+	if uploader.Bucket == "" {
+		return "", configError("", "Bucket", "cannot be empty!")
+	}
+	if uploader.Token == nil {
+		return "", configError("", "Token", "cannot be empty!")
+	}
+	endpoint := uploader.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+
+	file, object, cleanup, err := archiveFile(path, uploader.Prefix, uploader.Gzip)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	token, err := uploader.Token()
+	if err != nil {
+		return "", err
+	}
+
+	uploadUrl := strings.TrimSuffix(endpoint, "/") + "/upload/storage/v1/b/" + url.PathEscape(uploader.Bucket) +
+		"/o?uploadType=media&name=" + url.QueryEscape(object)
+	req, err := http.NewRequest(http.MethodPost, uploadUrl, ioutil.NopCloser(file))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = fileInfo.Size()
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "text/plain")
+	if strings.HasSuffix(object, ".gz") {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+
+	err = archivePut(uploader.Client, req)
+	if err != nil {
+		return "", err
+	}
+	return "gs://" + uploader.Bucket + "/" + object, nil
+}
+
+// open the file to upload and its key, the file is gzipped to a temp file if gzipped is true
+func archiveFile(path string, prefix string, gzipped bool) (*os.File, string, func(), error) {
+	key := prefix + filepath.Base(path)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if !gzipped || strings.HasSuffix(path, ".gz") {
+		return file, key, func() { file.Close() }, nil
+	}
+	defer file.Close()
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.gz")
+	if err != nil {
+		return nil, "", nil, err
+	}
+	cleanup := func() {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+	}
+	gzipWriter := gzip.NewWriter(tmpFile)
+	_, err = io.Copy(gzipWriter, file)
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if err == nil {
+		_, err = tmpFile.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, "", nil, err
+	}
+	return tmpFile, key + ".gz", cleanup, nil
+}
+
+// send the upload request, non 2xx responses are errors
+func archivePut(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = &http.Client{Timeout: ARCHIVE_DEFAULT_TIMEOUT}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("archive upload request failed, code=%d, body=%s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// sign the request by aws signature v4, host and all headers are signed
+func signV4(req *http.Request, payloadHash string, t time.Time, region string, service string, accessKey string, secretKey string) {
+	amzDate := t.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := &strings.Builder{}
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// the query of uploads is empty
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := hmacSha256([]byte("AWS4"+secretKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, service)
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
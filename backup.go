@@ -0,0 +1,238 @@
+package go_logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// default BackupName of files sliced by date, by lines or size and by date with lines or size
+const (
+	FILE_BACKUP_NAME_DATE      = "{{.Name}}_{{.Time}}{{.Ext}}"
+	FILE_BACKUP_NAME_SIZE      = "{{.Name}}.{{.Time}}{{.Ext}}"
+	FILE_BACKUP_NAME_DATE_SIZE = "{{.Name}}_{{.Time}}.{{.Seq}}{{.Ext}}"
+)
+
+// time layout of files sliced by lines or size
+const FILE_BACKUP_TIME_FORMAT = "2006-01-02-15.04.05.9999"
+
+// time layouts of files sliced by date
+var fileSliceTimeFormats = map[string]string{
+	FILE_SLICE_DATE_YEAR:  "2006",
+	FILE_SLICE_DATE_MONTH: "200601",
+	FILE_SLICE_DATE_DAY:   "20060102",
+	FILE_SLICE_DATE_HOUR:  "2006010215",
+	FILE_SLICE_DATE_WEEK:  "20060102",
+}
+
+// regexp of default time layouts
+var fileBackupTimeExprs = map[string]string{
+	FILE_BACKUP_TIME_FORMAT: "[0-9]{4}-[0-9]{2}-[0-9]{2}-[0-9]{2}.[0-9]{2}.[0-9]{2}.[0-9]{0,4}",
+	"2006":                  "[0-9]{4}",
+	"200601":                "[0-9]{6}",
+	"20060102":              "[0-9]{8}",
+	"2006010215":            "[0-9]{10}",
+}
+
+// fields of BackupName template, eg: "{{.Name}}.{{.Time}}.{{.Seq}}{{.Ext}}" is "app.20240102.1.log"
+type BackupNameData struct {
+
+	// file name without extension and extension, eg: "app" and ".log"
+	Name string
+	Ext  string
+
+	// rotation time formatted by BackupTimeFormat
+	Time string
+
+	// 1, increased while the backup file exists
+	Seq int
+}
+
+// Seq of the template rendering a backup pattern
+const backupPatternSeq = 987654321
+
+// parsed BackupName templates
+var backupNameTemplates sync.Map
+
+func backupNameTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := backupNameTemplates.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+	tmpl, err := template.New("backup").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, configErrorCause(FILE_ADAPTER_NAME, "BackupName", "is illegal", err)
+	}
+	name := &bytes.Buffer{}
+	err = tmpl.Execute(name, BackupNameData{Name: "app", Ext: ".log", Time: "20060102", Seq: 1})
+	if err != nil {
+		return nil, configErrorCause(FILE_ADAPTER_NAME, "BackupName", "is illegal", err)
+	}
+	if name.Len() == 0 || strings.ContainsAny(name.String(), `/\`) {
+		return nil, configError(FILE_ADAPTER_NAME, "BackupName", "must be a file name!")
+	}
+	backupNameTemplates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// backup names of the file in a time layout
+type backupNaming struct {
+	fs         FileSystem
+	dir        string
+	tmpl       *template.Template
+	timeFormat string
+	name       string
+	ext        string
+}
+
+// backup naming by BackupName, BackupTimeFormat and BackupDir
+// timeFormat is the default time layout of the slice
+func (fw *FileWriter) backupNaming(config *FileConfig, timeFormat string) (*backupNaming, error) {
+	text := config.BackupName
+	if text == "" {
+		text = FILE_BACKUP_NAME_DATE
+		if timeFormat == FILE_BACKUP_TIME_FORMAT {
+			text = FILE_BACKUP_NAME_SIZE
+		} else if config.MaxSize > 0 || config.MaxLine > 0 {
+			text = FILE_BACKUP_NAME_DATE_SIZE
+		}
+	}
+	tmpl, err := backupNameTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	if config.BackupTimeFormat != "" {
+		timeFormat = config.BackupTimeFormat
+	}
+	name, ext := splitFilenameExt(filepath.Base(fw.filename))
+	return &backupNaming{
+		fs:         fw.fs,
+		dir:        fw.backupDir(config),
+		tmpl:       tmpl,
+		timeFormat: timeFormat,
+		name:       name,
+		ext:        ext,
+	}, nil
+}
+
+// directory of backup files
+func (fw *FileWriter) backupDir(config *FileConfig) string {
+	dir := filepath.Dir(fw.filename)
+	if config.BackupDir == "" {
+		return dir
+	}
+	return joinFileDir(dir, config.BackupDir)
+}
+
+// directory relative to dir, absolute directories are returned as is
+// directories rooted without a drive letter on windows, eg: "\logs", are on the drive of dir
+func joinFileDir(dir string, relative string) string {
+	if filepath.IsAbs(relative) {
+		return relative
+	}
+	if relative != "" && os.IsPathSeparator(relative[0]) {
+		return filepath.Clean(filepath.VolumeName(dir) + relative)
+	}
+	return filepath.Join(dir, relative)
+}
+
+// name without extension and extension of the file name, eg: "app" and ".log" of "app.log"
+// dot files and names without a dot have no extension, eg: ".app" and "app"
+func splitFilenameExt(filename string) (string, string) {
+	ext := filepath.Ext(filename)
+	if ext == filename || ext == "." {
+		return filename, ""
+	}
+	return strings.TrimSuffix(filename, ext), ext
+}
+
+func (naming *backupNaming) render(data BackupNameData) string {
+	name := &bytes.Buffer{}
+	naming.tmpl.Execute(name, data)
+	return name.String()
+}
+
+// path of the backup rotated at t, Seq is increased while the path exists
+func (naming *backupNaming) path(t time.Time) string {
+	data := BackupNameData{Name: naming.name, Ext: naming.ext, Time: t.Format(naming.timeFormat), Seq: 1}
+	backupPath := filepath.Join(naming.dir, naming.render(data))
+	for {
+		if _, err := naming.fs.Stat(backupPath); os.IsNotExist(err) {
+			return backupPath
+		}
+		data.Seq++
+		nextPath := filepath.Join(naming.dir, naming.render(data))
+		// the template has no Seq, the backup is replaced
+		if nextPath == backupPath {
+			return backupPath
+		}
+		backupPath = nextPath
+	}
+}
+
+// patterns of backup names of any slice and the backup directory, backups may be left by an old config
+func (fw *FileWriter) backupPatterns(config *FileConfig) ([]*regexp.Regexp, string, error) {
+	timeFormats := []string{FILE_BACKUP_TIME_FORMAT}
+	for _, timeFormat := range fileSliceTimeFormats {
+		timeFormats = append(timeFormats, timeFormat)
+	}
+	patterns := []*regexp.Regexp{}
+	dirPath := ""
+	for _, timeFormat := range timeFormats {
+		naming, err := fw.backupNaming(config, timeFormat)
+		if err != nil {
+			return nil, "", err
+		}
+		r, err := naming.pattern()
+		if err != nil {
+			return nil, "", err
+		}
+		patterns = append(patterns, r)
+		dirPath = naming.dir
+	}
+	return patterns, dirPath, nil
+}
+
+// regexp of backup names, submatch "time" and "seq" if they are in the template, gzip backups match
+func (naming *backupNaming) pattern() (*regexp.Regexp, error) {
+	name := naming.render(BackupNameData{Name: "\x00N", Ext: "\x00E", Time: "\x00T", Seq: backupPatternSeq})
+	expr := regexp.QuoteMeta(name)
+	expr = strings.Replace(expr, "\x00N", regexp.QuoteMeta(naming.name), -1)
+	expr = strings.Replace(expr, "\x00E", regexp.QuoteMeta(naming.ext), -1)
+	expr = strings.Replace(expr, "\x00T", "(?P<time>"+backupTimeExpr(naming.timeFormat)+")", 1)
+	expr = strings.Replace(expr, "\x00T", backupTimeExpr(naming.timeFormat), -1)
+	expr = strings.Replace(expr, strconv.Itoa(backupPatternSeq), "(?P<seq>[0-9]+)", 1)
+	return regexp.Compile("^" + expr + `(\.gz)?$`)
+}
+
+// backup time and seq of the file name, ok is false if it isn't a backup name
+func (naming *backupNaming) parse(r *regexp.Regexp, filename string) (t time.Time, seq int, ok bool) {
+	match := r.FindStringSubmatch(filename)
+	if match == nil {
+		return t, 0, false
+	}
+	for i, group := range r.SubexpNames() {
+		switch group {
+		case "time":
+			t, _ = time.ParseInLocation(naming.timeFormat, match[i], time.Local)
+		case "seq":
+			seq, _ = strconv.Atoi(match[i])
+		}
+	}
+	return t, seq, true
+}
+
+// regexp of the time layout, digits of custom layouts are [0-9]
+func backupTimeExpr(layout string) string {
+	if expr, ok := fileBackupTimeExprs[layout]; ok {
+		return expr
+	}
+	sample := time.Date(2006, 1, 2, 15, 4, 5, 123456789, time.UTC).Format(layout)
+	expr := regexp.QuoteMeta(sample)
+	return regexp.MustCompile(`[0-9]+`).ReplaceAllString(expr, "[0-9]+")
+}
@@ -0,0 +1,134 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWriter_BackupName(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	fw := NewFileWrite(filename)
+	err = fw.initFile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fw.closeFile()
+
+	config := &FileConfig{BackupName: "{{.Name}}.{{.Time}}.{{.Seq}}{{.Ext}}", BackupDir: "backup", DirMode: FILE_DEFAULT_DIR_MODE}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		err = fw.rotate(config, "20060102", day)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	for _, name := range []string{"test.20240102.1.log", "test.20240102.2.log", "test.20240102.3.log"} {
+		if _, err := os.Stat(filepath.Join(dir, "backup", name)); err != nil {
+			t.Errorf("backup file %s is not rotated", name)
+		}
+	}
+
+	naming, _ := fw.backupNaming(config, "20060102")
+	r, err := naming.pattern()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	bakTime, seq, ok := naming.parse(r, "test.20240102.12.log.gz")
+	if !ok || !bakTime.Equal(day) || seq != 12 {
+		t.Errorf("backup name parse error: %v %d %v", bakTime, seq, ok)
+	}
+	if _, _, ok := naming.parse(r, "other.20240102.1.log"); ok {
+		t.Error("backup name of other file must not match")
+	}
+
+	// the highest seq of the same time is the newest, the file being sliced is the other bak
+	err = fw.cleanUpBackupFiles(&FileConfig{BackupName: config.BackupName, BackupDir: config.BackupDir, MaxBak: 2}, "20060102")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	files, _ := ioutil.ReadDir(filepath.Join(dir, "backup"))
+	if len(files) != 1 || files[0].Name() != "test.20240102.3.log" {
+		t.Errorf("backup dir clean up error: %v", files)
+	}
+}
+
+func TestAdapterFile_InitBackupName(t *testing.T) {
+
+	fileAdapter := NewAdapterFile()
+	err := fileAdapter.Init(&FileConfig{Filename: filepath.Join(os.TempDir(), "test.log"), BackupName: "{{.Name"})
+	if err == nil {
+		t.Error("illegal BackupName must error")
+	}
+	err = fileAdapter.Init(&FileConfig{Filename: filepath.Join(os.TempDir(), "test.log"), BackupName: "old/{{.Name}}{{.Ext}}"})
+	if err == nil {
+		t.Error("BackupName with directory must error")
+	}
+}
+
+func TestSplitFilenameExt(t *testing.T) {
+
+	cases := map[string][2]string{
+		"app.log":    {"app", ".log"},
+		"app.log.gz": {"app.log", ".gz"},
+		"app":        {"app", ""},
+		".app":       {".app", ""},
+		"app.":       {"app.", ""},
+	}
+	for filename, expected := range cases {
+		name, ext := splitFilenameExt(filename)
+		if name != expected[0] || ext != expected[1] {
+			t.Errorf("split %s error: %q %q", filename, name, ext)
+		}
+	}
+
+	dir := filepath.Join("logs.d", "app")
+	if suffixed := suffixFilename(dir, "canary"); suffixed != filepath.Join("logs.d", "app.canary") {
+		t.Errorf("suffix of file without extension error: %s", suffixed)
+	}
+	if suffixed := suffixFilename(filepath.Join("logs.d", "app.log"), "canary"); suffixed != filepath.Join("logs.d", "app.canary.log") {
+		t.Errorf("suffix of file error: %s", suffixed)
+	}
+	if joined := joinFileDir(filepath.Join("var", "log"), "old"); joined != filepath.Join("var", "log", "old") {
+		t.Errorf("join relative dir error: %s", joined)
+	}
+}
+
+func TestFileWriter_CleanUpWithoutExt(t *testing.T) {
+
+	// a directory with a dot outside of the working directory, the file has no extension
+	dir := filepath.Join(t.TempDir(), "logs.d")
+	filename := filepath.Join(dir, "app")
+	fw := NewFileWrite(filename)
+	fw.createDirs = true
+	err := fw.initFile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fw.closeFile()
+
+	config := &FileConfig{MaxBak: 2}
+	for day := 1; day <= 4; day++ {
+		err = fw.rotate(config, "20060102", time.Date(2024, 1, day, 0, 0, 0, 0, time.Local))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	files, _ := ioutil.ReadDir(dir)
+	names := []string{}
+	for _, fi := range files {
+		names = append(names, fi.Name())
+	}
+	if len(names) != 3 || names[0] != "app" || names[1] != "app_20240103" || names[2] != "app_20240104" {
+		t.Errorf("clean up of files without extension error: %v", names)
+	}
+}
@@ -0,0 +1,220 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// the batch is committed or discarded
+var ErrBatchDone = errors.New("logger: batch is committed or discarded")
+
+// messages appended to a batch are written by Commit contiguously, messages of other goroutines are not interleaved
+//
+// example:
+//	batch := logger.Begin()
+//	for _, account := range accounts {
+//		batch.Infof("account %s balance %d", account.Id, account.Balance)
+//	}
+//	err := batch.Commit()
+type LoggerBatch struct {
+	logger   *Logger
+	lock     sync.Mutex
+	messages []*loggerMessage
+	done     bool
+}
+
+// begin a batch of messages, nothing is written until Commit
+func (logger *Logger) Begin() *LoggerBatch {
+	return &LoggerBatch{logger: logger}
+}
+
+// number of messages appended and not written
+func (batch *LoggerBatch) Len() int {
+	batch.lock.Lock()
+	defer batch.lock.Unlock()
+	return len(batch.messages)
+}
+
+// write the messages contiguously and flush adapters once, messages of other goroutines wait until the batch is written
+// in async mode the batch is pushed to queues together and Commit returns after the queues are drained
+// error handlers and hooks must not log to the logger in Commit
+func (batch *LoggerBatch) Commit() error {
+	batch.lock.Lock()
+	if batch.done {
+		batch.lock.Unlock()
+		return ErrBatchDone
+	}
+	batch.done = true
+	messages := batch.messages
+	batch.messages = nil
+	batch.lock.Unlock()
+
+	logger := batch.logger
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		return ErrLoggerClosed
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	// sampling, hooks, redaction and budgets run outside of the lock
+	processed := make([]*loggerMessage, 0, len(messages))
+	for _, loggerMsg := range messages {
+		loggerMsg.batched = &processed
+		logger.dispatch(loggerMsg, nil)
+	}
+
+	// queues are drained before messages of other goroutines are pushed
+	logger.dispatchLock.Lock()
+	for _, loggerMsg := range processed {
+		logger.deliverMessage(loggerMsg, nil)
+	}
+	logger.flushOutputs(logger.outputs, logger.synchronous)
+	logger.dispatchLock.Unlock()
+
+	return logger.strict.err()
+}
+
+// drop the messages, return ErrBatchDone if the batch is committed or discarded
+func (batch *LoggerBatch) Discard() error {
+	batch.lock.Lock()
+	defer batch.lock.Unlock()
+	if batch.done {
+		return ErrBatchDone
+	}
+	batch.done = true
+	batch.messages = nil
+	return nil
+}
+
+// append the message, time and caller are of the append
+func (batch *LoggerBatch) write(callDepth int, level int, msg string, fields map[string]interface{}) error {
+	if !batch.logger.enabled(level) {
+		return nil
+	}
+	loggerMsg := batch.logger.callerMessage(callDepth+1, level, msg, fields)
+
+	batch.lock.Lock()
+	defer batch.lock.Unlock()
+	if batch.done {
+		return ErrBatchDone
+	}
+	batch.messages = append(batch.messages, loggerMsg)
+	return nil
+}
+
+// append log message
+func (batch *LoggerBatch) Writer(level int, msg string) error {
+	return batch.write(2, level, msg, nil)
+}
+
+// append log message with fields
+func (batch *LoggerBatch) WriterFields(level int, msg string, fields map[string]interface{}) error {
+	return batch.write(2, level, msg, fields)
+}
+
+// log emergency level
+func (batch *LoggerBatch) Emergency(msg string) {
+	batch.write(2, LOGGER_LEVEL_EMERGENCY, msg, nil)
+}
+
+// log emergency format
+func (batch *LoggerBatch) Emergencyf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_EMERGENCY, fmt.Sprintf(format, a...), nil)
+}
+
+// log alert level
+func (batch *LoggerBatch) Alert(msg string) {
+	batch.write(2, LOGGER_LEVEL_ALERT, msg, nil)
+}
+
+// log alert format
+func (batch *LoggerBatch) Alertf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_ALERT) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_ALERT, fmt.Sprintf(format, a...), nil)
+}
+
+// log critical level
+func (batch *LoggerBatch) Critical(msg string) {
+	batch.write(2, LOGGER_LEVEL_CRITICAL, msg, nil)
+}
+
+// log critical format
+func (batch *LoggerBatch) Criticalf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_CRITICAL) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_CRITICAL, fmt.Sprintf(format, a...), nil)
+}
+
+// log error level
+func (batch *LoggerBatch) Error(msg string) {
+	batch.write(2, LOGGER_LEVEL_ERROR, msg, nil)
+}
+
+// log error format
+func (batch *LoggerBatch) Errorf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_ERROR, fmt.Sprintf(format, a...), nil)
+}
+
+// log warning level
+func (batch *LoggerBatch) Warning(msg string) {
+	batch.write(2, LOGGER_LEVEL_WARNING, msg, nil)
+}
+
+// log warning format
+func (batch *LoggerBatch) Warningf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_WARNING) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_WARNING, fmt.Sprintf(format, a...), nil)
+}
+
+// log notice level
+func (batch *LoggerBatch) Notice(msg string) {
+	batch.write(2, LOGGER_LEVEL_NOTICE, msg, nil)
+}
+
+// log notice format
+func (batch *LoggerBatch) Noticef(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_NOTICE) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_NOTICE, fmt.Sprintf(format, a...), nil)
+}
+
+// log info level
+func (batch *LoggerBatch) Info(msg string) {
+	batch.write(2, LOGGER_LEVEL_INFO, msg, nil)
+}
+
+// log info format
+func (batch *LoggerBatch) Infof(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_INFO) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_INFO, fmt.Sprintf(format, a...), nil)
+}
+
+// log debug level
+func (batch *LoggerBatch) Debug(msg string) {
+	batch.write(2, LOGGER_LEVEL_DEBUG, msg, nil)
+}
+
+// log debug format
+func (batch *LoggerBatch) Debugf(format string, a ...interface{}) {
+	if !batch.logger.enabled(LOGGER_LEVEL_DEBUG) {
+		return
+	}
+	batch.write(2, LOGGER_LEVEL_DEBUG, fmt.Sprintf(format, a...), nil)
+}
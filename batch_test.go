@@ -0,0 +1,113 @@
+package go_logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestBatchLogger(t *testing.T) (*Logger, *AdapterTest) {
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger, logger.Adapter("test").(*AdapterTest)
+}
+
+func TestLoggerBatch_Commit(t *testing.T) {
+	logger, adapter := newTestBatchLogger(t)
+	logger.SetLevel(LOGGER_LEVEL_INFO)
+
+	batch := logger.Begin()
+	batch.Info("report start")
+	batch.Debug("not written")
+	batch.WriterFields(LOGGER_LEVEL_WARNING, "account", map[string]interface{}{"id": 1})
+	batch.Infof("report %s", "end")
+	if batch.Len() != 3 || len(adapter.Entries()) != 0 {
+		t.Fatalf("batch is written before commit: %d %d", batch.Len(), len(adapter.Entries()))
+	}
+
+	err := batch.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := adapter.Entries()
+	if len(entries) != 3 || entries[0].Body != "report start" || entries[1].Fields["id"] != 1 || entries[2].Body != "report end" {
+		t.Errorf("batch commit error: %v", entries)
+	}
+	if !strings.HasSuffix(entries[0].File, "batch_test.go") {
+		t.Errorf("batch caller error: %s", entries[0].File)
+	}
+
+	if batch.Commit() != ErrBatchDone || batch.Discard() != ErrBatchDone || batch.Writer(LOGGER_LEVEL_INFO, "late") != ErrBatchDone {
+		t.Error("committed batch is not done")
+	}
+}
+
+func TestLoggerBatch_Discard(t *testing.T) {
+	logger, adapter := newTestBatchLogger(t)
+
+	batch := logger.Begin()
+	batch.Error("discarded")
+	err := batch.Discard()
+	if err != nil || batch.Len() != 0 || batch.Commit() != ErrBatchDone || len(adapter.Entries()) != 0 {
+		t.Errorf("batch discard error: %v %d", err, len(adapter.Entries()))
+	}
+}
+
+func TestLoggerBatch_Contiguous(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		logger, adapter := newTestBatchLogger(t)
+		if async {
+			logger.SetAsync(10)
+		}
+
+		stop := make(chan struct{})
+		wait := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						logger.Info("concurrent")
+					}
+				}
+			}()
+		}
+
+		for n := 0; n < 20; n++ {
+			batch := logger.Begin()
+			for i := 0; i < 10; i++ {
+				batch.Info("batch " + strconv.Itoa(n) + " line " + strconv.Itoa(i))
+			}
+			err := batch.Commit()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		close(stop)
+		wait.Wait()
+		logger.Flush()
+
+		entries := adapter.Entries()
+		for i, entry := range entries {
+			if !strings.HasSuffix(entry.Body, " line 0") {
+				continue
+			}
+			prefix := strings.TrimSuffix(entry.Body, "0")
+			for j := 1; j < 10; j++ {
+				if i+j >= len(entries) || entries[i+j].Body != prefix+strconv.Itoa(j) {
+					t.Fatalf("batch is interleaved, async %v: %v", async, entries[i:i+j+1])
+				}
+			}
+		}
+		logger.Detach("test")
+	}
+}
@@ -2,6 +2,7 @@ package go_logger
 
 import (
 	"testing"
+	"time"
 )
 
 // go test -run=benchmark -cpu=1,2,4 -benchmem -benchtime=3s -bench="ConsoleText"
@@ -95,3 +96,46 @@ func BenchmarkLoggerFileJson(b *testing.B) {
 		}
 	})
 }
+
+// go test -run=benchmark -cpu=1,2,4 -benchmem -benchtime=3s -bench="MessageFormat"
+func BenchmarkLoggerMessageFormat(b *testing.B) {
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "benchmark logger message", nil)
+	format := "%millisecond_format% [%level_string%] [%file%:%line%] %body%"
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			loggerMessageFormat(format, loggerMsg)
+		}
+	})
+}
+
+// go test -run=benchmark -cpu=1,2,4 -benchmem -benchtime=3s -bench="LevelGate"
+// debug messages are dropped by the gate before formatted, no adapter writes debug
+func BenchmarkLoggerLevelGate(b *testing.B) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("console", LOGGER_LEVEL_INFO, &ConsoleConfig{})
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Debugf("benchmark logger message %d", 1)
+		}
+	})
+}
+
+// go test -run=benchmark -cpu=1,2,4 -benchmem -benchtime=3s -bench="FileMaxSize"
+func BenchmarkLoggerFileMaxSize(b *testing.B) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+		Filename: "./test.log",
+		MaxSize:  1024 * 1024,
+		MaxLine:  100000000,
+	})
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info("benchmark logger message")
+		}
+	})
+}
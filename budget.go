@@ -0,0 +1,160 @@
+package go_logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// field name of the category, budgets are set by category
+const LOGGER_FIELD_CATEGORY = "category"
+
+// default interval of budgets
+const BUDGET_DEFAULT_INTERVAL = time.Minute
+
+// log budget of a category, messages over budget of the interval are suppressed
+//
+// example, "db" may log at most 10MB every hour:
+//	logger.SetBudget("db", &go_logger.Budget{Bytes: 10 * 1024 * 1024, Interval: time.Hour})
+type Budget struct {
+
+	// max messages of every interval, 0 is unlimited
+	Messages int64
+
+	// max bytes of body and fields of every interval, 0 is unlimited
+	Bytes int64
+
+	// interval of budget, default 1m
+	Interval time.Duration
+}
+
+// suppressed messages of a category
+type BudgetStats struct {
+	Suppressed      int64
+	SuppressedBytes int64
+}
+
+// budgets of categories
+type loggerBudgets struct {
+	lock       sync.Mutex
+	categories map[string]*budgetCounter
+}
+
+type budgetCounter struct {
+	budget   Budget
+	start    time.Time
+	messages int64
+	bytes    int64
+
+	// suppressed of the interval, reported by a warning when the next interval starts
+	suppressed      int64
+	suppressedBytes int64
+
+	stats BudgetStats
+}
+
+// set budget of the category, nil removes the budget
+// category of a message is field "category", messages without it have category ""
+func (logger *Logger) SetBudget(category string, budget *Budget) {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	budgets, _ := logger.budgets.Load().(*loggerBudgets)
+	if budgets == nil {
+		budgets = &loggerBudgets{categories: map[string]*budgetCounter{}}
+		logger.budgets.Store(budgets)
+	}
+
+	budgets.lock.Lock()
+	defer budgets.lock.Unlock()
+	if budget == nil {
+		delete(budgets.categories, category)
+		return
+	}
+	rule := *budget
+	if rule.Interval <= 0 {
+		rule.Interval = BUDGET_DEFAULT_INTERVAL
+	}
+	counter, ok := budgets.categories[category]
+	if !ok {
+		counter = &budgetCounter{}
+		budgets.categories[category] = counter
+	}
+	counter.budget = rule
+}
+
+// suppressed messages of categories with budget
+func (logger *Logger) BudgetStats() map[string]BudgetStats {
+	stats := map[string]BudgetStats{}
+	budgets, _ := logger.budgets.Load().(*loggerBudgets)
+	if budgets == nil {
+		return stats
+	}
+	budgets.lock.Lock()
+	defer budgets.lock.Unlock()
+	for category, counter := range budgets.categories {
+		stats[category] = counter.stats
+	}
+	return stats
+}
+
+// message is in budget of its category
+// the first message of an interval after suppression is preceded by a warning of suppressed messages
+func (logger *Logger) inBudget(loggerMsg *loggerMessage) bool {
+	budgets, _ := logger.budgets.Load().(*loggerBudgets)
+	if budgets == nil || loggerMsg.verbose {
+		return true
+	}
+	category := loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_CATEGORY)
+
+	now := time.Now()
+	budgets.lock.Lock()
+	counter, ok := budgets.categories[category]
+	if !ok {
+		budgets.lock.Unlock()
+		return true
+	}
+	suppressed, suppressedBytes := int64(0), int64(0)
+	if now.Sub(counter.start) >= counter.budget.Interval {
+		suppressed, suppressedBytes = counter.suppressed, counter.suppressedBytes
+		counter.start = now
+		counter.messages = 0
+		counter.bytes = 0
+		counter.suppressed = 0
+		counter.suppressedBytes = 0
+	}
+	size := int64(0)
+	if counter.budget.Bytes > 0 {
+		size = loggerMessageSize(loggerMsg)
+	}
+	allow := (counter.budget.Messages <= 0 || counter.messages < counter.budget.Messages) &&
+		(counter.budget.Bytes <= 0 || counter.bytes+size <= counter.budget.Bytes)
+	if allow {
+		counter.messages++
+		counter.bytes += size
+	} else {
+		counter.suppressed++
+		counter.suppressedBytes += size
+		counter.stats.Suppressed++
+		counter.stats.SuppressedBytes += size
+	}
+	budgets.lock.Unlock()
+
+	if suppressed > 0 {
+		logger.dispatch(newLoggerMessage(logger.now(), LOGGER_LEVEL_WARNING, fmt.Sprintf("logger: category %q exceeded its budget, %d messages (%d bytes) suppressed", category, suppressed, suppressedBytes), map[string]interface{}{
+			LOGGER_FIELD_CATEGORY: category,
+			"suppressed":          suppressed,
+			"suppressed_bytes":    suppressedBytes,
+		}), nil)
+	}
+	return allow
+}
+
+// bytes of body and fields
+func loggerMessageSize(loggerMsg *loggerMessage) int64 {
+	size := len(loggerMsg.Body)
+	for key := range loggerMsg.Fields {
+		size += len(key) + len(loggerMessageField(loggerMsg.Fields, key))
+	}
+	return int64(size)
+}
@@ -0,0 +1,54 @@
+package go_logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetBudget(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	logger.SetBudget("db", &Budget{Messages: 2, Interval: 50 * time.Millisecond})
+	logger.SetBudget("cache", &Budget{Bytes: 30, Interval: time.Hour})
+	db := logger.With(map[string]interface{}{LOGGER_FIELD_CATEGORY: "db"})
+	cache := logger.With(map[string]interface{}{LOGGER_FIELD_CATEGORY: "cache"})
+	for i := 0; i < 5; i++ {
+		db.Info("query")
+		logger.Info("request")
+	}
+	// "miss" + "category" + "cache" is 17 bytes
+	cache.Info("miss")
+	cache.Info("miss")
+	cache.Info("miss")
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 8 {
+		t.Fatalf("logger budget error: %d messages", len(entries))
+	}
+	stats := logger.BudgetStats()
+	if stats["db"].Suppressed != 3 || stats["cache"].Suppressed != 2 || stats["cache"].SuppressedBytes != 34 {
+		t.Errorf("logger budget stats error: %v", stats)
+	}
+
+	// suppressed messages are reported by the next interval
+	time.Sleep(60 * time.Millisecond)
+	db.Info("query")
+	entries = memoryAdapter.Entries()
+	warning := entries[len(entries)-2]
+	if warning.Level != LOGGER_LEVEL_WARNING || !strings.Contains(warning.Body, "3 messages") || warning.Fields["suppressed"] != int64(3) {
+		t.Errorf("logger budget warning error: %+v", warning)
+	}
+	if entries[len(entries)-1].Body != "query" {
+		t.Errorf("logger budget next interval error: %+v", entries[len(entries)-1])
+	}
+
+	logger.SetBudget("db", nil)
+	if _, ok := logger.BudgetStats()["db"]; ok {
+		t.Error("logger budget is not removed")
+	}
+}
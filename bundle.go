@@ -0,0 +1,331 @@
+package go_logger
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// files of support bundles
+const (
+	BUNDLE_FILE_CONFIG      = "config.json"
+	BUNDLE_FILE_DIAGNOSTICS = "diagnostics.json"
+
+	// "memory/<adapter>.log", json records of memory adapters, oldest first
+	BUNDLE_DIR_MEMORY = "memory/"
+
+	// "files/<adapter>/<backup>", rotated backups of file adapters and Files of options
+	BUNDLE_DIR_FILES = "files/"
+)
+
+const BUNDLE_DEFAULT_BACKUPS = 3
+
+// support bundle options
+type BundleOptions struct {
+
+	// newest rotated backups of every file of file adapters, default 3, -1 is none
+	Backups int
+
+	// newest entries of every memory adapter, 0 is all kept entries
+	MemoryEntries int
+
+	// more files of "files/", eg: the config file of LoadConfig
+	Files []string
+}
+
+// config of the bundle, values of adapter configs are flattened by dotted paths
+type bundleConfig struct {
+	Level         string
+	Async         bool
+	QueueCapacity int
+	Adapters      []bundleAdapter
+}
+
+type bundleAdapter struct {
+	Name         string
+	Level        string
+	MaxLevel     string
+	Capabilities Capabilities
+	Config       map[string]interface{}
+}
+
+// runtime and stats of the bundle
+type bundleDiagnostics struct {
+	Time       string
+	GoVersion  string
+	Os         string
+	Arch       string
+	Hostname   string
+	Pid        int
+	Goroutines int
+	Stats      LoggerStats
+}
+
+// collect a support bundle zip in dir, return the path of the zip, eg: "dir/logger-bundle-20240102-150405.zip"
+// the bundle has the config of attached adapters (secrets are masked), diagnostics (runtime and Stats()),
+// entries of memory adapters and the newest rotated backups of file adapters
+//
+// example:
+//	bundle, err := logger.CollectBundle("/tmp", &go_logger.BundleOptions{Backups: 5})
+func (logger *Logger) CollectBundle(dir string, options *BundleOptions) (string, error) {
+	if options == nil {
+		options = &BundleOptions{}
+	}
+	backups := options.Backups
+	if backups == 0 {
+		backups = BUNDLE_DEFAULT_BACKUPS
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	bundlePath := filepath.Join(dir, "logger-bundle-"+time.Now().Format("20060102-150405")+".zip")
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		return "", err
+	}
+
+	err = logger.writeBundle(zip.NewWriter(file), backups, options)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(bundlePath)
+		return "", err
+	}
+	return bundlePath, nil
+}
+
+// write files of the bundle and close the zip writer
+func (logger *Logger) writeBundle(zipWriter *zip.Writer, backups int, options *BundleOptions) error {
+	logger.lock.Lock()
+	outputs := append([]*outputLogger{}, logger.outputs...)
+	config := bundleConfig{
+		Level:         levelNames()[int(atomic.LoadInt32(&logger.level))],
+		Async:         !logger.synchronous,
+		QueueCapacity: logger.queueCapacity,
+	}
+	logger.lock.Unlock()
+
+	redactor := diffRedactor
+	if loggerRedactor, ok := logger.redactor.Load().(**Redactor); ok && *loggerRedactor != nil {
+		redactor = *loggerRedactor
+	}
+	for _, output := range outputs {
+		maxLevel := LOGGER_LEVEL_EMERGENCY
+		if levels, ok := output.levels.Load().(*levelRange); ok {
+			maxLevel = levels.max
+		}
+		config.Adapters = append(config.Adapters, bundleAdapter{
+			Name:         output.Name,
+			Level:        levelNames()[output.minLevel()],
+			MaxLevel:     levelNames()[maxLevel],
+			Capabilities: output.capabilities(),
+			Config:       bundleConfigValues(output.config, redactor),
+		})
+	}
+	hostname, _ := os.Hostname()
+	diagnostics := bundleDiagnostics{
+		Time:       time.Now().Format(time.RFC3339),
+		GoVersion:  runtime.Version(),
+		Os:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Hostname:   hostname,
+		Pid:        os.Getpid(),
+		Goroutines: runtime.NumGoroutine(),
+		Stats:      logger.Stats(),
+	}
+
+	err := writeBundleJson(zipWriter, BUNDLE_FILE_CONFIG, config)
+	if err == nil {
+		err = writeBundleJson(zipWriter, BUNDLE_FILE_DIAGNOSTICS, diagnostics)
+	}
+	for _, output := range outputs {
+		if err != nil {
+			break
+		}
+		switch adapter := output.LoggerAbstract.(type) {
+		case *AdapterMemory:
+			err = writeBundleMemory(zipWriter, output.Name, adapter, options.MemoryEntries)
+		case *AdapterFile:
+			if backups > 0 {
+				err = writeBundleBackups(zipWriter, output.Name, adapter, backups)
+			}
+		}
+	}
+	for _, filename := range options.Files {
+		if err != nil {
+			break
+		}
+		err = writeBundleFile(zipWriter, BUNDLE_DIR_FILES+filepath.Base(filename), OsFileSystem, filename)
+	}
+
+	closeErr := zipWriter.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// values of the adapter config, secret fields are masked, funcs, adapters and byte keys are their types
+func bundleConfigValues(config Config, redactor *Redactor) map[string]interface{} {
+	values := map[string]interface{}{}
+	if config == nil {
+		return values
+	}
+	flattenDiffValue("", reflect.ValueOf(config), values)
+
+	for path, value := range values {
+		if value == nil {
+			continue
+		}
+		name := strings.ToLower(path[strings.LastIndex(path, ".")+1:])
+		if bundleSecret(redactor, name) && !reflect.ValueOf(value).IsZero() {
+			values[path] = redactor.mask
+			continue
+		}
+		if stringer, ok := value.(fmt.Stringer); ok {
+			values[path] = stringer.String()
+			continue
+		}
+		v := reflect.ValueOf(value)
+		kind := v.Kind()
+		if kind == reflect.Slice || kind == reflect.Array {
+			kind = v.Type().Elem().Kind()
+			if kind != reflect.String {
+				kind = reflect.Invalid
+			}
+		}
+		switch kind {
+		case reflect.String:
+			if s, ok := value.(string); ok {
+				values[path] = redactor.Redact(s)
+			}
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		default:
+			values[path] = fmt.Sprintf("%T", value)
+		}
+	}
+	return values
+}
+
+// name of the config field is a secret, eg: "password", "bearertoken" or "secretkey"
+func bundleSecret(redactor *Redactor, name string) bool {
+	for field := range redactor.fields {
+		if strings.Contains(name, strings.Replace(field, "_", "", -1)) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeBundleJson(zipWriter *zip.Writer, name string, v interface{}) error {
+	jsonByte, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(jsonByte)
+	return err
+}
+
+// json records of the newest entries of the memory adapter
+func writeBundleMemory(zipWriter *zip.Writer, name string, adapterMemory *AdapterMemory, entries int) error {
+	loggerMsgs := adapterMemory.messages()
+	if entries > 0 && len(loggerMsgs) > entries {
+		loggerMsgs = loggerMsgs[len(loggerMsgs)-entries:]
+	}
+	writer, err := zipWriter.Create(BUNDLE_DIR_MEMORY + name + ".log")
+	if err != nil {
+		return err
+	}
+	for _, loggerMsg := range loggerMsgs {
+		_, err = writer.Write(append(marshalLoggerMessage(loggerMsg, nil), '\n'))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// the newest backups of every file of the file adapter and its tenant files
+func writeBundleBackups(zipWriter *zip.Writer, name string, adapterFile *AdapterFile, backups int) error {
+	adapterFiles := append([]*AdapterFile{adapterFile}, adapterFile.tenantAdapters()...)
+	for _, file := range adapterFiles {
+		for _, fileWrite := range file.write {
+			paths, err := fileWrite.backupFiles(file.config)
+			if err != nil {
+				return err
+			}
+			if len(paths) > backups {
+				paths = paths[:backups]
+			}
+			for _, path := range paths {
+				err = writeBundleFile(zipWriter, BUNDLE_DIR_FILES+name+"/"+filepath.Base(path), fileWrite.fs, path)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// paths of backup files of the file, newest first
+func (fw *FileWriter) backupFiles(config *FileConfig) ([]string, error) {
+	patterns, dirPath, err := fw.backupPatterns(config)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := fw.fs.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	backups := []os.FileInfo{}
+	for _, fi := range dir {
+		if !fi.IsDir() && matchAny(patterns, fi.Name()) {
+			backups = append(backups, fi)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ModTime().After(backups[j].ModTime())
+	})
+	paths := make([]string, len(backups))
+	for i, fi := range backups {
+		paths[i] = filepath.Join(dirPath, fi.Name())
+	}
+	return paths, nil
+}
+
+func writeBundleFile(zipWriter *zip.Writer, name string, fs FileSystem, path string) error {
+	file, err := fs.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
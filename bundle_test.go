@@ -0,0 +1,89 @@
+package go_logger
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_CollectBundle(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{Size: 10})
+	logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{Filename: path.Join(dir, "app.log"), MaxLine: 1})
+	logger.Attach("api", LOGGER_LEVEL_ERROR, &ApiConfig{Url: "http://127.0.0.1:1/logs", Method: "POST", BearerToken: "abc", Timeout: 2 * time.Second})
+	defer logger.Detach("api")
+
+	for _, body := range []string{"one", "two", "three", "four", "five"} {
+		logger.Info(body)
+	}
+	configFile := path.Join(dir, "logger.yaml")
+	ioutil.WriteFile(configFile, []byte("level: info\n"), 0644)
+
+	bundle, err := logger.CollectBundle(path.Join(dir, "bundles"), &BundleOptions{Backups: 2, MemoryEntries: 3, Files: []string{configFile}})
+	if err != nil {
+		t.Fatalf("logger collect bundle error: %s", err)
+	}
+	if !strings.HasPrefix(path.Base(bundle), "logger-bundle-") || path.Ext(bundle) != ".zip" {
+		t.Errorf("logger bundle path error: %s", bundle)
+	}
+
+	reader, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	contents := map[string]string{}
+	for _, file := range reader.File {
+		rc, _ := file.Open()
+		content, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		contents[file.Name] = string(content)
+	}
+
+	config := bundleConfig{}
+	if err := json.Unmarshal([]byte(contents[BUNDLE_FILE_CONFIG]), &config); err != nil {
+		t.Fatalf("bundle config error: %s, %q", err, contents[BUNDLE_FILE_CONFIG])
+	}
+	if len(config.Adapters) != 3 || config.Adapters[2].Name != "api" || config.Adapters[2].Level != "Error" ||
+		!config.Adapters[2].Capabilities.Remote {
+		t.Errorf("bundle config adapters error: %+v", config.Adapters)
+	}
+	api := config.Adapters[2].Config
+	if api["BearerToken"] != REDACT_DEFAULT_MASK || api["Timeout"] != "2s" || api["Url"] != "http://127.0.0.1:1/logs" {
+		t.Errorf("bundle api config error: %v", api)
+	}
+	if !strings.Contains(contents[BUNDLE_FILE_DIAGNOSTICS], `"GoVersion"`) || !strings.Contains(contents[BUNDLE_FILE_DIAGNOSTICS], `"Info": 5`) {
+		t.Errorf("bundle diagnostics error: %s", contents[BUNDLE_FILE_DIAGNOSTICS])
+	}
+
+	memory := strings.Split(strings.TrimSpace(contents[BUNDLE_DIR_MEMORY+"memory.log"]), "\n")
+	if len(memory) != 3 || !strings.Contains(memory[0], `"body":"three"`) || !strings.Contains(memory[2], `"body":"five"`) {
+		t.Errorf("bundle memory error: %q", memory)
+	}
+
+	backups := 0
+	for name := range contents {
+		if strings.HasPrefix(name, BUNDLE_DIR_FILES+"file/app.") {
+			backups++
+		}
+	}
+	if backups != 2 {
+		t.Errorf("bundle backups error: %v", contents)
+	}
+	if contents[BUNDLE_DIR_FILES+"logger.yaml"] != "level: info\n" {
+		t.Errorf("bundle files error: %v", contents)
+	}
+}
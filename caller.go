@@ -0,0 +1,154 @@
+package go_logger
+
+import (
+	"path"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// max stack depth searched for the caller
+const CALLER_MAX_DEPTH = 32
+
+// frame of the caller, file is the base name
+type callerFrame struct {
+	file     string
+	line     int
+	function string
+}
+
+var unknownCallerFrame = &callerFrame{file: "null", line: 0, function: "null"}
+
+// frames of program counters, inlined calls of a program counter are more frames
+var callerFrames sync.Map
+
+// functions marked by Helper()
+var (
+	helperFunctions sync.Map
+	helpers         int32
+)
+
+// Helper marks the calling function as a logging helper, like testing.T.Helper
+// file, line and function of messages are of the caller of the helper
+//
+// example:
+//	func logRequest(logger *go_logger.Logger, req *http.Request) {
+//		logger.Helper()
+//		logger.Infof("request %s", req.URL)
+//	}
+func (logger *Logger) Helper() {
+	markHelper()
+}
+
+// Helper marks the calling function as a logging helper, see Logger.Helper()
+func (child *ChildLogger) Helper() {
+	markHelper()
+}
+
+func markHelper() {
+	var pcs [1]uintptr
+	// skip runtime.Callers, markHelper and Helper
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if _, loaded := helperFunctions.LoadOrStore(frame.Function, true); !loaded {
+		atomic.AddInt32(&helpers, 1)
+	}
+}
+
+// skip more frames to the caller for wrappers of the logger, eg: 1 reports the caller of the wrapper
+// Helper() marks wrappers without counting frames
+//
+// example:
+//	func Info(msg string) {
+//		defaultLogger.Info(msg)
+//	}
+//	defaultLogger.SetCallerSkip(1)
+func (logger *Logger) SetCallerSkip(skip int) {
+	if skip < 0 {
+		skip = 0
+	}
+	atomic.StoreInt32(&logger.callerSkip, int32(skip))
+}
+
+// capture file, line and function of the caller, default true
+// they are empty if it's disabled, which saves the stack walk of every message
+func (logger *Logger) SetCaller(enabled bool) {
+	value := int32(1)
+	if enabled {
+		value = 0
+	}
+	atomic.StoreInt32(&logger.noCaller, value)
+}
+
+// child logger of the wrapper which skips more frames to the caller, added to SetCallerSkip
+func (logger *Logger) WithCallerSkip(skip int) *ChildLogger {
+	return (&ChildLogger{logger: logger}).WithCallerSkip(skip)
+}
+
+// child logger which skips more frames to the caller
+func (child *ChildLogger) WithCallerSkip(skip int) *ChildLogger {
+	callerSkip := child.callerSkip + skip
+	if callerSkip < 0 {
+		callerSkip = 0
+	}
+	return &ChildLogger{logger: child.logger, fields: child.fields, prefix: child.prefix, callerSkip: callerSkip}
+}
+
+// new message of the caller at callDepth of the caller of callerMessage, frames of SetCallerSkip are skipped too
+func (logger *Logger) callerMessage(callDepth int, level int, msg string, fields map[string]interface{}) *loggerMessage {
+	if levelNames()[level] == "" {
+		printError("logger: level " + strconv.Itoa(level) + " is illegal!")
+	}
+	callDepth += int(atomic.LoadInt32(&logger.callerSkip))
+	fields = logger.withStacktrace(callDepth, level, fields)
+	if atomic.LoadInt32(&logger.noCaller) == 1 {
+		return newLoggerMessage(logger.now(), level, msg, fields)
+	}
+	return newCallerMessage(callDepth+1, logger.now(), level, msg, fields)
+}
+
+// frame of the caller at callDepth of the caller of callerOf, like runtime.Caller, helpers are skipped
+func callerOf(callDepth int) *callerFrame {
+	var pcs [CALLER_MAX_DEPTH]uintptr
+	// frames of runtime.Callers and callerOf are counted by skip
+	n := runtime.Callers(0, pcs[:])
+	skip := callDepth + 2
+	checkHelpers := atomic.LoadInt32(&helpers) > 0
+	for _, pc := range pcs[:n] {
+		for _, frame := range framesOf(pc) {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if checkHelpers {
+				if _, ok := helperFunctions.Load(frame.function); ok {
+					continue
+				}
+			}
+			return frame
+		}
+	}
+	return unknownCallerFrame
+}
+
+// frames of the program counter, cached
+func framesOf(pc uintptr) []*callerFrame {
+	if frames, ok := callerFrames.Load(pc); ok {
+		return frames.([]*callerFrame)
+	}
+	frames := []*callerFrame{}
+	runtimeFrames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		frame, more := runtimeFrames.Next()
+		_, file := path.Split(frame.File)
+		frames = append(frames, &callerFrame{file: file, line: frame.Line, function: frame.Function})
+		if !more {
+			break
+		}
+	}
+	callerFrames.Store(pc, frames)
+	return frames
+}
@@ -0,0 +1,121 @@
+package go_logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testLogHelper(logger *Logger, msg string) {
+	logger.Helper()
+	logger.Info(msg)
+}
+
+func TestLogger_Helper(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "%body% %file%:%line% %function%",
+	})
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.Info("direct")
+	testLogHelper(logger, "helper")
+	child := logger.With(map[string]interface{}{"k": "v"})
+	child.Info("child")
+	logger.Writer(LOGGER_LEVEL_INFO, "writer")
+	logger.Infof("%s", "infof")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	expects := []string{
+		"direct caller_test.go:" + strconv.Itoa(line+1) + " github.com/phachon/go-logger.TestLogger_Helper",
+		"helper caller_test.go:" + strconv.Itoa(line+2) + " github.com/phachon/go-logger.TestLogger_Helper",
+		"child caller_test.go:" + strconv.Itoa(line+4) + " github.com/phachon/go-logger.TestLogger_Helper",
+		"writer caller_test.go:" + strconv.Itoa(line+5) + " github.com/phachon/go-logger.TestLogger_Helper",
+		"infof caller_test.go:" + strconv.Itoa(line+6) + " github.com/phachon/go-logger.TestLogger_Helper",
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("logger helper lines error: %q", buffer.String())
+	}
+	for i, expect := range expects {
+		if lines[i] != expect {
+			t.Errorf("logger helper caller error: %q, expect %q", lines[i], expect)
+		}
+	}
+}
+
+func testLogWrapper(logger *Logger, msg string) {
+	logger.Info(msg)
+}
+
+func testChildWrapper(child *ChildLogger, msg string) {
+	child.Info(msg)
+}
+
+func TestLogger_SetCallerSkip(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "%body% %file%:%line% %function%",
+	})
+
+	_, _, line, _ := runtime.Caller(0)
+	logger.SetCallerSkip(1)
+	testLogWrapper(logger, "wrapper")
+	logger.SetCallerSkip(0)
+	testChildWrapper(logger.WithCallerSkip(1).With(map[string]interface{}{"k": "v"}), "child wrapper")
+	logger.SetCaller(false)
+	logger.Info("no caller")
+	logger.SetCaller(true)
+	logger.WithCallerSkip(-1).Info("negative")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	expects := []string{
+		"wrapper caller_test.go:" + strconv.Itoa(line+2) + " github.com/phachon/go-logger.TestLogger_SetCallerSkip",
+		"child wrapper caller_test.go:" + strconv.Itoa(line+4) + " github.com/phachon/go-logger.TestLogger_SetCallerSkip",
+		"no caller :0",
+		"negative caller_test.go:" + strconv.Itoa(line+8) + " github.com/phachon/go-logger.TestLogger_SetCallerSkip",
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("logger caller skip lines error: %q", buffer.String())
+	}
+	for i, expect := range expects {
+		if strings.TrimSpace(lines[i]) != expect {
+			t.Errorf("logger caller skip error: %q, expect %q", lines[i], expect)
+		}
+	}
+}
+
+func TestCallerOf(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		frame := callerOf(0)
+		_, file, line, _ := runtime.Caller(0)
+		if !strings.HasSuffix(file, "/"+frame.file) || frame.line != line-1 || frame.function != "github.com/phachon/go-logger.TestCallerOf" {
+			t.Errorf("caller of error: %+v, expect %s:%d", frame, file, line-1)
+		}
+	}
+	if callerOf(CALLER_MAX_DEPTH) != unknownCallerFrame {
+		t.Error("caller of unknown frame error")
+	}
+}
+
+func BenchmarkCallerOf(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		callerOf(1)
+	}
+}
+
+func BenchmarkRuntimeCaller(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pc, _, _, _ := runtime.Caller(1)
+		runtime.FuncForPC(pc).Name()
+	}
+}
@@ -0,0 +1,57 @@
+package go_logger
+
+// async queue capacity of remote and batching adapters, unless capacity is set by SetAsync()
+const ASYNC_QUEUE_REMOTE_CAPACITY = 1000
+
+// what an adapter supports and needs, the logger chooses queue capacity and flush strategy by it
+type Capabilities struct {
+
+	// messages are buffered and written in batches
+	Batching bool
+
+	// records are binary, not text lines
+	Binary bool
+
+	// buffered messages are written by Flush(), Flush() is called in sync mode too
+	NeedsFlush bool
+
+	// messages are written over the network, writes are slow and may fail
+	Remote bool
+}
+
+// adapter reports its capabilities after Init, optional
+type LoggerCapabilities interface {
+	Capabilities() Capabilities
+}
+
+// capabilities of attached adapter, zero Capabilities if the adapter doesn't report them
+func (logger *Logger) AdapterCapabilities(adapterName string) Capabilities {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			return output.capabilities()
+		}
+	}
+	return Capabilities{}
+}
+
+func (output *outputLogger) capabilities() Capabilities {
+	if reporter, ok := output.LoggerAbstract.(LoggerCapabilities); ok {
+		return reporter.Capabilities()
+	}
+	return Capabilities{}
+}
+
+// async queue capacity of output after lock, remote and batching adapters have bigger queues by default
+func (logger *Logger) queueCapacityOf(output *outputLogger) int {
+	if logger.queueCapacity > 0 {
+		return logger.queueCapacity
+	}
+	capabilities := output.capabilities()
+	if capabilities.Remote || capabilities.Batching {
+		return ASYNC_QUEUE_REMOTE_CAPACITY
+	}
+	return ASYNC_QUEUE_DEFAULT_CAPACITY
+}
@@ -0,0 +1,45 @@
+package go_logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogger_AdapterCapabilities(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("elasticsearch", LOGGER_LEVEL_DEBUG, &ElasticsearchConfig{Url: "http://127.0.0.1:9200", Index: "logs"})
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	defer logger.Detach("elasticsearch")
+
+	if !logger.AdapterCapabilities("elasticsearch").Remote || logger.AdapterCapabilities("memory") != (Capabilities{}) {
+		t.Errorf("adapter capabilities error")
+	}
+
+	// remote adapters have bigger queues by default
+	logger.SetAsync()
+	for _, output := range logger.outputs {
+		capacity := cap(output.queue.msgChan)
+		if output.Name == "elasticsearch" && capacity != ASYNC_QUEUE_REMOTE_CAPACITY || output.Name == "memory" && capacity != ASYNC_QUEUE_DEFAULT_CAPACITY {
+			t.Errorf("adapter %s queue capacity error: %d", output.Name, capacity)
+		}
+	}
+	logger.SetAsync(10)
+	for _, output := range logger.outputs {
+		if cap(output.queue.msgChan) != 10 {
+			t.Errorf("queue capacity of SetAsync must be used")
+		}
+	}
+}
+
+func TestLogger_FlushNeedsFlush(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%", BufferSize: 4096})
+	logger.Info("buffered")
+	// sync mode flushes buffered adapters
+	logger.Flush()
+	if content := readLog(); !strings.Contains(content, "buffered") {
+		t.Errorf("buffered file must be flushed in sync mode: %q", content)
+	}
+}
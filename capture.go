@@ -0,0 +1,190 @@
+package go_logger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	CAPTURE_DEFAULT_MAX_MESSAGES = 1000
+	CAPTURE_DEFAULT_ERROR_STATUS = 500
+)
+
+// capture config of http handler
+type CaptureConfig struct {
+
+	// messages of this level and lower are captured, eg: LOGGER_LEVEL_INFO captures Info and Debug
+	// messages of higher level are written immediately
+	Level int
+
+	// captured messages are written if the request takes longer, 0 is disabled
+	SlowThreshold time.Duration
+
+	// captured messages are written if the response status >= ErrorStatus, default 500
+	ErrorStatus int
+
+	// max captured messages of one request, the oldest are dropped, default 1000
+	MaxMessages int
+}
+
+// logger capture, buffer messages of a request until it's known whether they're needed
+type LoggerCapture struct {
+	logger      *Logger
+	level       int
+	maxMessages int
+
+	lock     sync.Mutex
+	messages []*loggerMessage
+	failed   bool
+	flushed  bool
+	dropped  bool
+}
+
+type loggerCaptureKey struct{}
+
+func captureFromContext(ctx context.Context) *LoggerCapture {
+	if ctx == nil {
+		return nil
+	}
+	capture, _ := ctx.Value(loggerCaptureKey{}).(*LoggerCapture)
+	return capture
+}
+
+// start capture, XxxCtx messages of the returned context with level >= level are buffered
+// call Flush() to write them or Discard() to drop them
+func (logger *Logger) StartCapture(ctx context.Context, level int) (context.Context, *LoggerCapture) {
+	capture := &LoggerCapture{
+		logger:      logger,
+		level:       level,
+		maxMessages: CAPTURE_DEFAULT_MAX_MESSAGES,
+	}
+	return context.WithValue(ctx, loggerCaptureKey{}, capture), capture
+}
+
+// mark the capture of the context failed, CaptureHandler writes the captured messages
+func CaptureMarkError(ctx context.Context) {
+	capture := captureFromContext(ctx)
+	if capture != nil {
+		capture.MarkError()
+	}
+}
+
+func (capture *LoggerCapture) add(loggerMsg *loggerMessage) {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+
+	if capture.dropped {
+		return
+	}
+	if capture.flushed {
+		capture.logger.dispatch(loggerMsg, nil)
+		return
+	}
+	if capture.maxMessages > 0 && len(capture.messages) >= capture.maxMessages {
+		capture.messages = capture.messages[1:]
+	}
+	capture.messages = append(capture.messages, loggerMsg)
+}
+
+// mark failed
+func (capture *LoggerCapture) MarkError() {
+	capture.lock.Lock()
+	capture.failed = true
+	capture.lock.Unlock()
+}
+
+// is marked failed
+func (capture *LoggerCapture) Failed() bool {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+	return capture.failed
+}
+
+// write captured messages with their original time, later messages are written immediately
+func (capture *LoggerCapture) Flush() {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+
+	for _, loggerMsg := range capture.messages {
+		capture.logger.dispatch(loggerMsg, nil)
+	}
+	capture.messages = nil
+	capture.flushed = true
+}
+
+// drop captured messages, later messages are dropped too
+func (capture *LoggerCapture) Discard() {
+	capture.lock.Lock()
+	defer capture.lock.Unlock()
+
+	capture.messages = nil
+	capture.dropped = true
+}
+
+// http handler, Debug/Info messages of a request are only written if the request fails or is slow
+// handlers log by XxxCtx(r.Context(), ...)
+//
+// example:
+//	http.ListenAndServe(":8080", logger.CaptureHandler(mux, &go_logger.CaptureConfig{SlowThreshold: time.Second}))
+func (logger *Logger) CaptureHandler(next http.Handler, config *CaptureConfig) http.Handler {
+	if config == nil {
+		config = &CaptureConfig{Level: LOGGER_LEVEL_INFO}
+	}
+	if config.ErrorStatus <= 0 {
+		config.ErrorStatus = CAPTURE_DEFAULT_ERROR_STATUS
+	}
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = CAPTURE_DEFAULT_MAX_MESSAGES
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, capture := logger.StartCapture(r.Context(), config.Level)
+		capture.maxMessages = config.MaxMessages
+		cw := &captureResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		startTime := time.Now()
+
+		defer func() {
+			e := recover()
+			if e != nil || cw.status >= config.ErrorStatus || capture.Failed() ||
+				(config.SlowThreshold > 0 && time.Since(startTime) > config.SlowThreshold) {
+				capture.Flush()
+			} else {
+				capture.Discard()
+			}
+			if e != nil {
+				panic(e)
+			}
+		}()
+
+		next.ServeHTTP(cw, r.WithContext(ctx))
+	})
+}
+
+// response writer records status code
+type captureResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (cw *captureResponseWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.status = status
+		cw.wroteHeader = true
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *captureResponseWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *captureResponseWriter) Flush() {
+	flusher, ok := cw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
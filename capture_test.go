@@ -0,0 +1,49 @@
+package go_logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLogger_CaptureHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	handler := logger.CaptureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugCtx(r.Context(), "debug "+r.URL.Path)
+		logger.InfoCtx(r.Context(), "info "+r.URL.Path)
+		logger.WarningCtx(r.Context(), "warning "+r.URL.Path)
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/mark":
+			CaptureMarkError(r.Context())
+		}
+	}), nil)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	entries := memoryAdapter.Entries()
+	if len(entries) != 1 || entries[0].Body != "warning /ok" {
+		t.Fatalf("capture success request error: %v", entries)
+	}
+
+	memoryAdapter.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	entries = memoryAdapter.Entries()
+	if len(entries) != 3 || entries[0].Body != "warning /fail" || entries[1].Body != "debug /fail" || entries[2].Body != "info /fail" {
+		t.Fatalf("capture failed request error: %v", entries)
+	}
+	if entries[1].File != "capture_test.go" {
+		t.Errorf("capture message file error: %s", entries[1].File)
+	}
+
+	memoryAdapter.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/mark", nil))
+	if len(memoryAdapter.Entries()) != 3 {
+		t.Errorf("capture marked request error: %v", memoryAdapter.Entries())
+	}
+}
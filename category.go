@@ -0,0 +1,75 @@
+package go_logger
+
+import "errors"
+
+// child logger of the category, messages get field "category"
+// categories of SetAdapterCategories are written only to their adapters
+//
+// example:
+//	logger.SetAdapterCategories("access_file", "access")
+//	logger.Category("access").Info("GET /orders 200")
+func (logger *Logger) Category(category string) *ChildLogger {
+	return logger.With(map[string]interface{}{LOGGER_FIELD_CATEGORY: category})
+}
+
+// set categories written by the adapter, the adapter writes messages of the categories only, empty removes them
+// messages of a category claimed by adapters are not written to other adapters,
+// adapters without categories write messages without category and of categories no adapter claims
+//
+// example:
+//	logger.SetAdapterCategories("access_file", "access")
+//	logger.SetAdapterCategories("audit_file", "audit", "security")
+func (logger *Logger) SetAdapterCategories(adapterName string, categories ...string) error {
+	for _, category := range categories {
+		if category == "" {
+			return errors.New("logger: adapter category cannot be empty!")
+		}
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.setCategories(categories)
+			logger.updateCategoryClaims()
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// store categories as a set
+func (output *outputLogger) setCategories(categories []string) {
+	set := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		set[category] = true
+	}
+	output.categories.Store(set)
+}
+
+// categories of attached outputs, call it after lock
+func (logger *Logger) updateCategoryClaims() {
+	claims := map[string]bool{}
+	for _, output := range logger.outputs {
+		categories, _ := output.categories.Load().(map[string]bool)
+		for category := range categories {
+			claims[category] = true
+		}
+	}
+	logger.categories.Store(claims)
+}
+
+// output writes the category of the message
+func (output *outputLogger) categoryAccept(loggerMsg *loggerMessage) bool {
+	category := loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_CATEGORY)
+	categories, _ := output.categories.Load().(map[string]bool)
+	if len(categories) > 0 {
+		return categories[category]
+	}
+	if category == "" || output.categoryClaims == nil {
+		return true
+	}
+	claims, _ := output.categoryClaims.Load().(map[string]bool)
+	return !claims[category]
+}
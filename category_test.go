@@ -0,0 +1,76 @@
+package go_logger
+
+import (
+	"testing"
+)
+
+func testCategoryBodies(logger *Logger, name string) []string {
+	bodies := []string{}
+	for _, entry := range logger.Adapter(name).(*AdapterTest).Entries() {
+		bodies = append(bodies, entry.Body)
+	}
+	return bodies
+}
+
+func TestLogger_Category(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAs("app", "test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	logger.AttachAs("access", "test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	logger.AttachAs("audit", "test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	logger.SetAdapterCategories("access", "access")
+	logger.SetAdapterCategories("audit", "audit", "security")
+
+	logger.Info("started")
+	logger.Category("access").Info("GET /orders 200")
+	logger.Category("security").Warning("login failed")
+	logger.Category("billing").Info("invoice sent")
+
+	if bodies := testCategoryBodies(logger, "app"); len(bodies) != 2 || bodies[0] != "started" || bodies[1] != "invoice sent" {
+		t.Errorf("category default adapter error: %v", bodies)
+	}
+	if bodies := testCategoryBodies(logger, "access"); len(bodies) != 1 || bodies[0] != "GET /orders 200" {
+		t.Errorf("category access adapter error: %v", bodies)
+	}
+	if bodies := testCategoryBodies(logger, "audit"); len(bodies) != 1 || bodies[0] != "login failed" {
+		t.Errorf("category audit adapter error: %v", bodies)
+	}
+	if entry := logger.Adapter("access").(*AdapterTest).LastEntry(); entry.Fields[LOGGER_FIELD_CATEGORY] != "access" {
+		t.Errorf("category field error: %v", entry.Fields)
+	}
+
+	// the category is written to every adapter when it's not claimed
+	logger.SetAdapterCategories("access")
+	logger.Category("access").Info("GET /health 200")
+	if len(testCategoryBodies(logger, "app")) != 3 || len(testCategoryBodies(logger, "access")) != 2 || len(testCategoryBodies(logger, "audit")) != 1 {
+		t.Error("category removed error")
+	}
+	// claims are updated when adapters are detached
+	logger.Detach("audit")
+	logger.Category("security").Info("password changed")
+	if bodies := testCategoryBodies(logger, "app"); len(bodies) != 4 {
+		t.Errorf("category detached adapter error: %v", bodies)
+	}
+
+	if logger.SetAdapterCategories("missing", "access") == nil || logger.SetAdapterCategories("app", "") == nil {
+		t.Error("set adapter categories error")
+	}
+}
+
+func TestLogger_LoadConfigBytesCategories(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "test"}, {"name": "test", "alias": "access", "categories": ["access"]}]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("started")
+	logger.Category("access").Info("GET /orders 200")
+	if bodies := testCategoryBodies(logger, "test"); len(bodies) != 1 || bodies[0] != "started" {
+		t.Errorf("config categories error: %v", bodies)
+	}
+	if bodies := testCategoryBodies(logger, "access"); len(bodies) != 1 || bodies[0] != "GET /orders 200" {
+		t.Errorf("config categories error: %v", bodies)
+	}
+}
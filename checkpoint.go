@@ -0,0 +1,146 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpoint of the file is Filename + ".checkpoint"
+const FILE_CHECKPOINT_SUFFIX = ".checkpoint"
+
+// max rotations of the checkpoint
+const FILE_CHECKPOINT_MAX_ROTATIONS = 64
+
+// checkpoint of the file for tailing agents (vector, fluent bit, filebeat)
+// agents match the inode of a rotated file to the current file they were reading and continue from their offset
+// instead of reading the rotated file from the beginning
+type FileCheckpoint struct {
+
+	// current file, its inode and device, 0 if they are not supported (windows)
+	File   string    `json:"file"`
+	Inode  uint64    `json:"inode"`
+	Device uint64    `json:"device"`
+	Opened time.Time `json:"opened"`
+
+	// rotated files still on disk, oldest first
+	Rotations []FileCheckpointRotation `json:"rotations"`
+}
+
+// rotated file of the checkpoint, Size is final, the file is not written anymore
+type FileCheckpointRotation struct {
+	File    string    `json:"file"`
+	Inode   uint64    `json:"inode"`
+	Device  uint64    `json:"device"`
+	Size    int64     `json:"size"`
+	Rotated time.Time `json:"rotated"`
+}
+
+// ReadFileCheckpoint return the checkpoint of the log file, nil if it isn't written
+func ReadFileCheckpoint(filename string) (*FileCheckpoint, error) {
+	return readFileCheckpoint(OsFileSystem, filename)
+}
+
+// checkpoint of the log file of the file system
+func readFileCheckpoint(fs FileSystem, filename string) (*FileCheckpoint, error) {
+	content, err := readFileOf(fs, filename+FILE_CHECKPOINT_SUFFIX)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	checkpoint := &FileCheckpoint{}
+	err = json.Unmarshal(content, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// record the rotated file of the checkpoint
+func (fw *FileWriter) checkpointRotated(rotated string) {
+	if !fw.checkpoint {
+		return
+	}
+	fw.loadCheckpoint()
+	fileInfo, err := fw.fs.Stat(rotated)
+	if err != nil {
+		return
+	}
+	rotation := FileCheckpointRotation{
+		File:    absFilename(rotated),
+		Size:    fileInfo.Size(),
+		Rotated: fw.clock.Now(),
+	}
+	rotation.Inode, rotation.Device = fileInode(fileInfo)
+	fw.rotationHistory = append(fw.rotationHistory, rotation)
+}
+
+// write the checkpoint of the current file, the checkpoint is replaced by rename
+func (fw *FileWriter) writeCheckpoint() {
+	if !fw.checkpoint {
+		return
+	}
+	fw.loadCheckpoint()
+	fileInfo, err := fw.fs.Stat(fw.filename)
+	if err != nil {
+		return
+	}
+
+	// rotated files removed by clean up or compressed are not tailed anymore
+	rotations := []FileCheckpointRotation{}
+	for _, rotation := range fw.rotationHistory {
+		if _, err := fw.fs.Stat(rotation.File); err == nil {
+			rotations = append(rotations, rotation)
+		}
+	}
+	if len(rotations) > FILE_CHECKPOINT_MAX_ROTATIONS {
+		rotations = rotations[len(rotations)-FILE_CHECKPOINT_MAX_ROTATIONS:]
+	}
+	fw.rotationHistory = rotations
+
+	checkpoint := &FileCheckpoint{
+		File:      absFilename(fw.filename),
+		Opened:    fw.clock.Now(),
+		Rotations: rotations,
+	}
+	checkpoint.Inode, checkpoint.Device = fileInode(fileInfo)
+	content, _ := json.MarshalIndent(checkpoint, "", "  ")
+
+	filename := fw.filename + FILE_CHECKPOINT_SUFFIX
+	tmpFilename := filename + ".tmp"
+	err = writeFileOf(fw.fs, tmpFilename, content, fw.fileMode)
+	if err == nil {
+		err = fw.fs.Rename(tmpFilename, filename)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: unable write checkpoint of %s, error: %v\n", fw.filename, err)
+	}
+}
+
+// rotations of the checkpoint written before restart
+func (fw *FileWriter) loadCheckpoint() {
+	if fw.rotationHistory != nil {
+		return
+	}
+	fw.rotationHistory = []FileCheckpointRotation{}
+	checkpoint, err := readFileCheckpoint(fw.fs, fw.filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: unable read checkpoint of %s, error: %v\n", fw.filename, err)
+		return
+	}
+	if checkpoint != nil {
+		fw.rotationHistory = checkpoint.Rotations
+	}
+}
+
+func absFilename(filename string) string {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	return absPath
+}
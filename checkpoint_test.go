@@ -0,0 +1,65 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestFileWriter_Checkpoint(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "test.log")
+	config := &FileConfig{Checkpoint: true, FileMode: FILE_DEFAULT_MODE, DirMode: FILE_DEFAULT_DIR_MODE}
+	fw := newFileWriteByConfig(filename, config)
+	err = fw.initFile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	fw.writeString("rotated\n")
+	err = fw.rotate(config, "20060102", time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	fw.closeFile()
+
+	checkpoint, err := ReadFileCheckpoint(filename)
+	if err != nil || checkpoint == nil {
+		t.Fatalf("read checkpoint error: %v", err)
+	}
+	fileInfo, _ := os.Stat(filename)
+	inode, device := fileInode(fileInfo)
+	if checkpoint.File != filename || checkpoint.Inode != inode || checkpoint.Device != device {
+		t.Errorf("checkpoint current file error: %+v", checkpoint)
+	}
+	bakFilename := path.Join(dir, "test_20240102.log")
+	bakInfo, _ := os.Stat(bakFilename)
+	bakInode, _ := fileInode(bakInfo)
+	if len(checkpoint.Rotations) != 1 || checkpoint.Rotations[0].File != bakFilename ||
+		checkpoint.Rotations[0].Inode != bakInode || checkpoint.Rotations[0].Size != 8 {
+		t.Fatalf("checkpoint rotations error: %+v", checkpoint.Rotations)
+	}
+
+	// rotations are kept by restart, removed rotated files are dropped
+	fw = newFileWriteByConfig(filename, config)
+	fw.initFile()
+	checkpoint, _ = ReadFileCheckpoint(filename)
+	if len(checkpoint.Rotations) != 1 {
+		t.Errorf("checkpoint rotations are not kept by restart: %+v", checkpoint.Rotations)
+	}
+	fw.closeFile()
+	os.Remove(bakFilename)
+	fw.initFile()
+	fw.closeFile()
+	checkpoint, _ = ReadFileCheckpoint(filename)
+	if len(checkpoint.Rotations) != 0 {
+		t.Errorf("removed rotated file is not dropped: %+v", checkpoint.Rotations)
+	}
+}
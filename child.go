@@ -0,0 +1,185 @@
+package go_logger
+
+import (
+	"fmt"
+)
+
+// field name of the component of child loggers, see Logger.New()
+const LOGGER_FIELD_COMPONENT = "component"
+
+// child logger, shares the adapters of the logger and adds bound fields to every message
+type ChildLogger struct {
+	logger     *Logger
+	fields     map[string]interface{}
+	prefix     string
+	callerSkip int // more frames skipped to the caller, WithCallerSkip()
+}
+
+// child logger with bound fields
+func (logger *Logger) With(fields map[string]interface{}) *ChildLogger {
+	return (&ChildLogger{logger: logger}).With(fields)
+}
+
+// child logger of the component, messages get field "component", format placeholder "%component%"
+//
+// example:
+//
+//	db := logger.New("db")
+//	db.New("pool").Info("connected") // component "db.pool"
+func (logger *Logger) New(name string) *ChildLogger {
+	return (&ChildLogger{logger: logger}).New(name)
+}
+
+// child logger of the sub component, the component is joined by ".", eg: "db.pool"
+func (child *ChildLogger) New(name string) *ChildLogger {
+	if parent, ok := child.fields[LOGGER_FIELD_COMPONENT].(string); ok && parent != "" {
+		name = parent + "." + name
+	}
+	return child.With(map[string]interface{}{LOGGER_FIELD_COMPONENT: name})
+}
+
+// child logger with the prefix of message bodies, prefixes of parents are kept, eg: "[payment] "
+func (child *ChildLogger) WithPrefix(prefix string) *ChildLogger {
+	return &ChildLogger{logger: child.logger, fields: child.fields, prefix: child.prefix + prefix, callerSkip: child.callerSkip}
+}
+
+// child logger with more bound fields, fields take precedence
+func (child *ChildLogger) With(fields map[string]interface{}) *ChildLogger {
+	bound := make(map[string]interface{}, len(child.fields)+len(fields))
+	for key, value := range child.fields {
+		bound[key] = value
+	}
+	for key, value := range fields {
+		bound[key] = value
+	}
+	return &ChildLogger{logger: child.logger, fields: bound, prefix: child.prefix, callerSkip: child.callerSkip}
+}
+
+// fields of the message, message fields take precedence over bound fields
+func (child *ChildLogger) messageFields(fields map[string]interface{}) map[string]interface{} {
+	if len(child.fields) == 0 {
+		return fields
+	}
+	merged := make(map[string]interface{}, len(child.fields)+len(fields))
+	for key, value := range child.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return merged
+}
+
+// write log message
+func (child *ChildLogger) Writer(level int, msg string) error {
+	return child.logger.write(2+child.callerSkip, level, child.prefix+msg, child.messageFields(nil))
+}
+
+// write log message with fields
+func (child *ChildLogger) WriterFields(level int, msg string, fields map[string]interface{}) error {
+	return child.logger.write(2+child.callerSkip, level, child.prefix+msg, child.messageFields(fields))
+}
+
+// log emergency level
+func (child *ChildLogger) Emergency(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_EMERGENCY, child.prefix+msg, child.messageFields(nil))
+}
+
+// log emergency format
+func (child *ChildLogger) Emergencyf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_EMERGENCY, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log alert level
+func (child *ChildLogger) Alert(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ALERT, child.prefix+msg, child.messageFields(nil))
+}
+
+// log alert format
+func (child *ChildLogger) Alertf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_ALERT) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ALERT, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log critical level
+func (child *ChildLogger) Critical(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+msg, child.messageFields(nil))
+}
+
+// log critical format
+func (child *ChildLogger) Criticalf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_CRITICAL) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log error level
+func (child *ChildLogger) Error(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ERROR, child.prefix+msg, child.messageFields(nil))
+}
+
+// log error format
+func (child *ChildLogger) Errorf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ERROR, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log warning level
+func (child *ChildLogger) Warning(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_WARNING, child.prefix+msg, child.messageFields(nil))
+}
+
+// log warning format
+func (child *ChildLogger) Warningf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_WARNING) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_WARNING, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log notice level
+func (child *ChildLogger) Notice(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_NOTICE, child.prefix+msg, child.messageFields(nil))
+}
+
+// log notice format
+func (child *ChildLogger) Noticef(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_NOTICE) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_NOTICE, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log info level
+func (child *ChildLogger) Info(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_INFO, child.prefix+msg, child.messageFields(nil))
+}
+
+// log info format
+func (child *ChildLogger) Infof(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_INFO) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_INFO, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
+
+// log debug level
+func (child *ChildLogger) Debug(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_DEBUG, child.prefix+msg, child.messageFields(nil))
+}
+
+// log debug format
+func (child *ChildLogger) Debugf(format string, a ...interface{}) {
+	if !child.logger.enabled(LOGGER_LEVEL_DEBUG) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_DEBUG, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+}
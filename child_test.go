@@ -0,0 +1,55 @@
+package go_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLogger_New(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "[%component%] %body% %fields%",
+	})
+
+	db := logger.New("db")
+	db.Info("open")
+	db.New("pool").With(map[string]interface{}{"size": 4}).Warningf("pool %s", "full")
+	db.WithPrefix("query: ").New("tx").Error("rollback")
+	logger.Info("root")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	expects := []string{
+		"[db] open component=db",
+		"[db.pool] pool full component=db.pool size=4",
+		"[db.tx] query: rollback component=db.tx",
+		"[] root",
+	}
+	if len(lines) != len(expects) {
+		t.Fatalf("child logger lines error: %q", buffer.String())
+	}
+	for i, expect := range expects {
+		if strings.TrimSpace(lines[i]) != expect {
+			t.Errorf("child logger message error: %q, expect %q", lines[i], expect)
+		}
+	}
+
+	logger.Detach("writer")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, JsonFormat: true})
+	buffer.Reset()
+	logger.New("api").WithPrefix("[api] ").Notice("started")
+	loggerMsg := map[string]interface{}{}
+	err := json.Unmarshal(buffer.Bytes(), &loggerMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := loggerMsg["fields"].(map[string]interface{})
+	if loggerMsg["body"] != "[api] started" || fields[LOGGER_FIELD_COMPONENT] != "api" {
+		t.Errorf("child logger json error: %s", buffer.String())
+	}
+}
@@ -0,0 +1,164 @@
+package go_logger
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// clock of date slices, rotation times and MaxAge of file adapters, see FileConfig.Clock
+// Now of a clock is a message time provider too, eg: Providers{Now: clock.Now}
+type Clock interface {
+	Now() time.Time
+}
+
+// file operations of file adapters, see FileConfig.FileSystem
+// wrap the default to fake errors or times in tests, eg: a full disk when files are rotated
+// every file of the adapter is opened, stat, renamed, linked and removed by it: log files, bak files,
+// trash, symlinks, checkpoints, upload manifests and lock files of MultiProcess
+type FileSystem interface {
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	Stat(name string) (os.FileInfo, error)
+	Lstat(name string) (os.FileInfo, error)
+	Rename(oldPath string, newPath string) error
+	Remove(name string) error
+	MkdirAll(path string, perm os.FileMode) error
+	ReadDir(dirname string) ([]os.FileInfo, error)
+	Chown(name string, uid int, gid int) error
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+	Symlink(oldname string, newname string) error
+	Readlink(name string) (string, error)
+}
+
+// file of a FileSystem, *os.File of the default
+// files of MultiProcess are locked by Fd() if the file has it, files without it are not locked against other processes
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+	Sync() error
+	Stat() (os.FileInfo, error)
+	Name() string
+}
+
+// clock of time.Now
+var SystemClock Clock = systemClock{}
+
+// file system of the os package
+var OsFileSystem FileSystem = osFileSystem{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type osFileSystem struct{}
+
+func (osFileSystem) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		// a nil *os.File is not a nil File
+		return nil, err
+	}
+	return file, nil
+}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) Lstat(name string) (os.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (osFileSystem) Rename(oldPath string, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) ReadDir(dirname string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(dirname)
+}
+
+func (osFileSystem) Chown(name string, uid int, gid int) error {
+	return os.Chown(name, uid, gid)
+}
+
+func (osFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+func (osFileSystem) Symlink(oldname string, newname string) error {
+	return os.Symlink(oldname, newname)
+}
+
+func (osFileSystem) Readlink(name string) (string, error) {
+	return os.Readlink(name)
+}
+
+// read the whole file of the file system
+func readFileOf(fs FileSystem, filename string) ([]byte, error) {
+	file, err := fs.OpenFile(filename, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ioutil.ReadAll(file)
+}
+
+// write the file of the file system, it's created or truncated
+func writeFileOf(fs FileSystem, filename string, data []byte, perm os.FileMode) error {
+	file, err := fs.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// clock stopped at a time, it's moved by Set and Add only
+//
+// example:
+//	clock := go_logger.NewFrozenClock(time.Date(2024, 1, 1, 23, 59, 0, 0, time.Local))
+//	logger.Attach("file", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.FileConfig{Filename: "./app.log", DateSlice: "d", Clock: clock})
+//	clock.Add(time.Minute) // the next write rotates "app.log" to "app_20240101.log"
+type FrozenClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func NewFrozenClock(t time.Time) *FrozenClock {
+	return &FrozenClock{now: t}
+}
+
+func (clock *FrozenClock) Now() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return clock.now
+}
+
+func (clock *FrozenClock) Set(t time.Time) {
+	clock.lock.Lock()
+	clock.now = t
+	clock.lock.Unlock()
+}
+
+func (clock *FrozenClock) Add(d time.Duration) {
+	clock.lock.Lock()
+	clock.now = clock.now.Add(d)
+	clock.lock.Unlock()
+}
@@ -0,0 +1,222 @@
+package go_logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// file system failing renames while fail is set
+type renameFailFileSystem struct {
+	FileSystem
+	fail bool
+}
+
+func (fs *renameFailFileSystem) Rename(oldPath string, newPath string) error {
+	if fs.fail {
+		return errors.New("rename " + oldPath + ": no space left on device")
+	}
+	return fs.FileSystem.Rename(oldPath, newPath)
+}
+
+// file system recording the operations of the file adapter
+type recordFileSystem struct {
+	FileSystem
+	lock sync.Mutex
+	ops  map[string]int
+}
+
+func (fs *recordFileSystem) record(op string) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	fs.ops[op]++
+}
+
+func (fs *recordFileSystem) count(op string) int {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	return fs.ops[op]
+}
+
+func (fs *recordFileSystem) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	fs.record("OpenFile")
+	return fs.FileSystem.OpenFile(name, flag, perm)
+}
+
+func (fs *recordFileSystem) Chown(name string, uid int, gid int) error {
+	fs.record("Chown")
+	return nil
+}
+
+func (fs *recordFileSystem) Lstat(name string) (os.FileInfo, error) {
+	fs.record("Lstat")
+	return fs.FileSystem.Lstat(name)
+}
+
+func (fs *recordFileSystem) Symlink(oldname string, newname string) error {
+	fs.record("Symlink")
+	return fs.FileSystem.Symlink(oldname, newname)
+}
+
+func (fs *recordFileSystem) Stat(name string) (os.FileInfo, error) {
+	fs.record("Stat")
+	return fs.FileSystem.Stat(name)
+}
+
+func newTestClockAdapter(t *testing.T, config *FileConfig) (*AdapterFile, string) {
+	dir := t.TempDir()
+	config.Filename = filepath.Join(dir, "test.log")
+	adapter := NewAdapterFile().(*AdapterFile)
+	err := adapter.Init(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(adapter.Flush)
+	return adapter, dir
+}
+
+func writeTestClockMessage(t *testing.T, adapter *AdapterFile, body string) {
+	err := adapter.Write(&loggerMessage{Level: LOGGER_LEVEL_INFO, LevelString: "info", Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testClockFiles(dir string) []string {
+	files, _ := ioutil.ReadDir(dir)
+	names := []string{}
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFrozenClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFrozenClock(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("frozen clock now error: %v", clock.Now())
+	}
+	clock.Add(time.Hour)
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Errorf("frozen clock add error: %v", clock.Now())
+	}
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("frozen clock set error: %v", clock.Now())
+	}
+}
+
+func TestAdapterFile_ClockSliceByHour(t *testing.T) {
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 10, 59, 0, 0, time.Local))
+	adapter, dir := newTestClockAdapter(t, &FileConfig{DateSlice: FILE_SLICE_DATE_HOUR, Format: "%body%", Clock: clock})
+
+	writeTestClockMessage(t, adapter, "first")
+	clock.Add(30 * time.Second)
+	writeTestClockMessage(t, adapter, "second")
+	if names := testClockFiles(dir); strings.Join(names, ",") != "test.log" {
+		t.Fatalf("slice by hour within the hour error: %v", names)
+	}
+
+	// the hour ends
+	clock.Add(time.Minute)
+	writeTestClockMessage(t, adapter, "third")
+	adapter.Flush()
+	if names := testClockFiles(dir); strings.Join(names, ",") != "test.log,test_2024010110.log" {
+		t.Fatalf("slice by hour at the boundary error: %v", names)
+	}
+	bak, _ := ioutil.ReadFile(filepath.Join(dir, "test_2024010110.log"))
+	content, _ := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if !strings.Contains(string(bak), "second") || strings.Contains(string(bak), "third") || !strings.Contains(string(content), "third") {
+		t.Errorf("slice by hour content error: %q %q", bak, content)
+	}
+}
+
+func TestAdapterFile_ClockSliceByDayMaxBak(t *testing.T) {
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 23, 0, 0, 0, time.Local))
+	adapter, dir := newTestClockAdapter(t, &FileConfig{DateSlice: FILE_SLICE_DATE_DAY, MaxBak: 2, Clock: clock})
+
+	for i := 0; i < 4; i++ {
+		writeTestClockMessage(t, adapter, "day")
+		clock.Add(24 * time.Hour)
+	}
+	writeTestClockMessage(t, adapter, "day")
+
+	// 4 days are sliced, the 2 newest bak files are kept
+	names := testClockFiles(dir)
+	if strings.Join(names, ",") != "test.log,test_20240103.log,test_20240104.log" {
+		t.Errorf("slice by day max bak error: %v", names)
+	}
+}
+
+func TestAdapterFile_FileSystemRenameError(t *testing.T) {
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 23, 59, 0, 0, time.Local))
+	fs := &renameFailFileSystem{FileSystem: OsFileSystem}
+	adapter, dir := newTestClockAdapter(t, &FileConfig{DateSlice: FILE_SLICE_DATE_DAY, Clock: clock, FileSystem: fs})
+
+	writeTestClockMessage(t, adapter, "first")
+	clock.Add(time.Hour)
+	fs.fail = true
+	err := adapter.Write(&loggerMessage{Level: LOGGER_LEVEL_INFO, LevelString: "info", Body: "second"})
+	if err == nil || !strings.Contains(err.Error(), "no space left on device") {
+		t.Errorf("rename error of the file system is not returned: %v", err)
+	}
+
+	fs.fail = false
+	writeTestClockMessage(t, adapter, "third")
+	if _, err := os.Stat(filepath.Join(dir, "test_20240101.log")); err != nil {
+		t.Errorf("slice after the rename error: %v", testClockFiles(dir))
+	}
+}
+
+func TestAdapterFile_FileSystemOperations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local))
+	fs := &recordFileSystem{FileSystem: OsFileSystem, ops: map[string]int{}}
+	adapter, _ := newTestClockAdapter(t, &FileConfig{DateSlice: FILE_SLICE_DATE_DAY, SymlinkLatest: true, MaxLine: 100,
+		Chown: true, Uid: os.Getuid(), Gid: os.Getgid(), Clock: clock, FileSystem: fs})
+
+	writeTestClockMessage(t, adapter, "first")
+	clock.Add(24 * time.Hour)
+	writeTestClockMessage(t, adapter, "second")
+	for _, op := range []string{"OpenFile", "Chown", "Lstat", "Symlink", "Stat"} {
+		if fs.count(op) == 0 {
+			t.Errorf("%s is not called on the file system: %v", op, fs.ops)
+		}
+	}
+}
+
+func TestAdapterFile_ClockReopenInterval(t *testing.T) {
+	clock := NewFrozenClock(time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local))
+	adapter, dir := newTestClockAdapter(t, &FileConfig{ReopenInterval: time.Minute, Clock: clock})
+	filename := filepath.Join(dir, "test.log")
+
+	writeTestClockMessage(t, adapter, "first")
+	adapter.Flush()
+	os.Remove(filename)
+
+	// the interval of the clock is not ended
+	writeTestClockMessage(t, adapter, "second")
+	adapter.Flush()
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Fatalf("file is reopened before the interval of the clock: %v", err)
+	}
+
+	clock.Add(time.Minute)
+	writeTestClockMessage(t, adapter, "third")
+	adapter.Flush()
+	content, _ := ioutil.ReadFile(filename)
+	if strings.TrimSpace(string(content)) == "" || !strings.Contains(string(content), "third") {
+		t.Errorf("file is not reopened after the interval of the clock: %q", content)
+	}
+}
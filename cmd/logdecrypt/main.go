@@ -0,0 +1,103 @@
+// logdecrypt decrypts log files written with FileConfig Encryption,
+// or encrypted field values of FieldEncryptor with -fields
+//
+//	logdecrypt -key <hex aes key or x25519 private key> app.log app_20240102.log.gz
+//	logdecrypt -key-file ./log.key < app.log
+//	logdecrypt -fields -key-file ./auditor.key app.log
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/phachon/go-logger"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	keyText := flag.String("key", "", "aes-gcm key or x25519 private key, hex or base64")
+	keyFile := flag.String("key-file", "", "file of the key")
+	fields := flag.Bool("fields", false, "decrypt encrypted field values of records, the key is a x25519 private key")
+	flag.Parse()
+
+	if *keyFile != "" {
+		content, err := ioutil.ReadFile(*keyFile)
+		if err != nil {
+			exit(err)
+		}
+		*keyText = string(content)
+	}
+	key, err := parseKey(strings.TrimSpace(*keyText))
+	if err != nil {
+		exit(err)
+	}
+
+	decrypt := go_logger.DecryptLog
+	if *fields {
+		decrypt = go_logger.DecryptFields
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	defer output.Flush()
+	if flag.NArg() == 0 {
+		err = decrypt(bufio.NewReader(os.Stdin), output, key)
+		if err != nil {
+			exit(err)
+		}
+		return
+	}
+	for _, filename := range flag.Args() {
+		err = decryptFile(filename, output, key, decrypt)
+		if err != nil {
+			output.Flush()
+			exit(fmt.Errorf("%s: %v", filename, err))
+		}
+	}
+}
+
+// decrypt the file, gzip files are decompressed
+func decryptFile(filename string, w io.Writer, key []byte, decrypt func(io.Reader, io.Writer, []byte) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(filename, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	}
+	return decrypt(r, w, key)
+}
+
+func parseKey(text string) ([]byte, error) {
+	if text == "" {
+		return nil, errors.New("-key or -key-file is required")
+	}
+	key, err := hex.DecodeString(text)
+	if err == nil {
+		return key, nil
+	}
+	key, err = base64.StdEncoding.DecodeString(text)
+	if err == nil {
+		return key, nil
+	}
+	return nil, errors.New("key must be hex or base64")
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "logdecrypt:", err)
+	os.Exit(1)
+}
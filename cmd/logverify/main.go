@@ -0,0 +1,112 @@
+// logverify verifies hash chains of log files written with FileConfig HashChain,
+// it exits 1 if a record is changed, removed or inserted, or a rotated file is not sealed
+//
+//	logverify -key <hex or base64 hmac key> app.log app_20240102.log.gz
+//	logverify -key-file ./audit.key -sealed app_20240102.log
+//	logverify < app.log
+package main
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/phachon/go-logger"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func main() {
+	keyText := flag.String("key", "", "hmac key of the hash chain, hex or base64, empty is sha256")
+	keyFile := flag.String("key-file", "", "file of the key")
+	sealed := flag.Bool("sealed", false, "files must be sealed, eg: rotated files")
+	flag.Parse()
+
+	if *keyFile != "" {
+		content, err := ioutil.ReadFile(*keyFile)
+		if err != nil {
+			exit(err)
+		}
+		*keyText = string(content)
+	}
+	key, err := parseKey(strings.TrimSpace(*keyText))
+	if err != nil {
+		exit(err)
+	}
+
+	if flag.NArg() == 0 {
+		err = verify("stdin", os.Stdin, key, *sealed)
+		if err != nil {
+			exit(err)
+		}
+		return
+	}
+	failed := false
+	for _, filename := range flag.Args() {
+		err = verifyFile(filename, key, *sealed)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "logverify:", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// verify the file, gzip files are decompressed
+func verifyFile(filename string, key []byte, sealed bool) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(filename, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("%s: %v", filename, err)
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	}
+	return verify(filename, r, key, sealed)
+}
+
+func verify(name string, r io.Reader, key []byte, sealed bool) error {
+	report, err := go_logger.VerifyHashChain(r, key)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if sealed && !report.Sealed {
+		return fmt.Errorf("%s: file is not sealed, records after %s are truncated", name, report.LastHash)
+	}
+	fmt.Printf("%s: ok, %d records, last hash %s, sealed %t\n", name, report.Records, report.LastHash, report.Sealed)
+	return nil
+}
+
+// empty key is sha256
+func parseKey(text string) ([]byte, error) {
+	if text == "" {
+		return nil, nil
+	}
+	key, err := hex.DecodeString(text)
+	if err == nil {
+		return key, nil
+	}
+	key, err = base64.StdEncoding.DecodeString(text)
+	if err == nil {
+		return key, nil
+	}
+	return nil, errors.New("key must be hex or base64")
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "logverify:", err)
+	os.Exit(1)
+}
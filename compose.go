@@ -0,0 +1,109 @@
+package go_logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	TEE_ADAPTER_NAME      = "tee"
+	FILTERED_ADAPTER_NAME = "filtered"
+)
+
+// tee adapter, write every message to all adapters
+type AdapterTee struct {
+	adapters []LoggerAbstract
+}
+
+// filtered adapter, only write messages matched pred
+type AdapterFiltered struct {
+	adapter LoggerAbstract
+	pred    func(entry LogEntry) bool
+}
+
+// compose adapters, messages are written to all of them
+// adapters must be initialized, attach it by logger.AttachAdapter()
+//
+// example:
+//	jsonFile := go_logger.NewAdapterFile()
+//	jsonFile.Init(&go_logger.FileConfig{Filename: "./app.json", JsonFormat: true})
+//	textFile := go_logger.NewAdapterFile()
+//	textFile.Init(&go_logger.FileConfig{Filename: "./app.log"})
+//	logger.AttachAdapter("files", go_logger.LOGGER_LEVEL_DEBUG, go_logger.Tee(jsonFile, textFile))
+func Tee(adapters ...LoggerAbstract) LoggerAbstract {
+	return &AdapterTee{adapters: adapters}
+}
+
+// compose adapter, only messages pred returns true are written to it
+func Filtered(adapter LoggerAbstract, pred func(entry LogEntry) bool) LoggerAbstract {
+	return &AdapterFiltered{adapter: adapter, pred: pred}
+}
+
+// adapters are initialized before composed
+func (adapterTee *AdapterTee) Init(config Config) error {
+	return nil
+}
+
+func (adapterTee *AdapterTee) Write(loggerMsg *loggerMessage) error {
+	errs := []string{}
+	for _, adapter := range adapterTee.adapters {
+		err := adapter.Write(loggerMsg)
+		if err != nil {
+			errs = append(errs, adapter.Name()+": "+err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("tee write failed, %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (adapterTee *AdapterTee) Flush() {
+	for _, adapter := range adapterTee.adapters {
+		adapter.Flush()
+	}
+}
+
+func (adapterTee *AdapterTee) Close() error {
+	var closeErr error
+	for _, adapter := range adapterTee.adapters {
+		if closer, ok := adapter.(LoggerCloser); ok {
+			err := closer.Close()
+			if err != nil && closeErr == nil {
+				closeErr = err
+			}
+		}
+	}
+	return closeErr
+}
+
+func (adapterTee *AdapterTee) Name() string {
+	return TEE_ADAPTER_NAME
+}
+
+// adapter is initialized before composed
+func (adapterFiltered *AdapterFiltered) Init(config Config) error {
+	return nil
+}
+
+func (adapterFiltered *AdapterFiltered) Write(loggerMsg *loggerMessage) error {
+	if !adapterFiltered.pred(loggerMsg.Entry()) {
+		return nil
+	}
+	return adapterFiltered.adapter.Write(loggerMsg)
+}
+
+func (adapterFiltered *AdapterFiltered) Flush() {
+	adapterFiltered.adapter.Flush()
+}
+
+func (adapterFiltered *AdapterFiltered) Close() error {
+	if closer, ok := adapterFiltered.adapter.(LoggerCloser); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (adapterFiltered *AdapterFiltered) Name() string {
+	return FILTERED_ADAPTER_NAME
+}
@@ -0,0 +1,46 @@
+package go_logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+
+	dir, _ := ioutil.TempDir("", "go-logger")
+	defer os.RemoveAll(dir)
+
+	jsonFile := NewAdapterFile()
+	jsonFile.Init(&FileConfig{Filename: path.Join(dir, "app.json"), JsonFormat: true})
+	textFile := NewAdapterFile()
+	textFile.Init(&FileConfig{Filename: path.Join(dir, "app.log")})
+	errorFile := NewAdapterFile()
+	errorFile.Init(&FileConfig{Filename: path.Join(dir, "error.log")})
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAdapter("files", LOGGER_LEVEL_DEBUG, Tee(jsonFile, textFile, Filtered(errorFile, func(entry LogEntry) bool {
+		return entry.Level <= LOGGER_LEVEL_ERROR
+	})))
+
+	logger.Info("tee info")
+	logger.Error("tee error")
+	logger.Close(context.Background())
+
+	jsonContent, _ := ioutil.ReadFile(path.Join(dir, "app.json"))
+	textContent, _ := ioutil.ReadFile(path.Join(dir, "app.log"))
+	errorContent, _ := ioutil.ReadFile(path.Join(dir, "error.log"))
+	if strings.Count(string(jsonContent), `"body":"tee`) != 2 {
+		t.Error("tee json file error: " + string(jsonContent))
+	}
+	if !strings.Contains(string(textContent), "[Info] tee info") || !strings.Contains(string(textContent), "[Error] tee error") {
+		t.Error("tee text file error: " + string(textContent))
+	}
+	if strings.Contains(string(errorContent), "tee info") || !strings.Contains(string(errorContent), "tee error") {
+		t.Error("filtered error file error: " + string(errorContent))
+	}
+}
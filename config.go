@@ -1,6 +1,664 @@
 package go_logger
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // logger config interface
 type Config interface {
 	Name() string
 }
+
+// config file formats
+const (
+	CONFIG_FORMAT_JSON = "json"
+	CONFIG_FORMAT_YAML = "yaml"
+	CONFIG_FORMAT_TOML = "toml"
+)
+
+type adapterConfigFunc func() Config
+
+var adapterConfigs = make(map[string]adapterConfigFunc)
+
+// Register config of logger adapter, adapters with config can be loaded from config files
+func RegisterConfig(adapterName string, newConfig adapterConfigFunc) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if adapterConfigs[adapterName] != nil {
+		panic("logger: logger adapter config " + adapterName + " already registered!")
+	}
+	if newConfig == nil {
+		panic("logger: logger adapter config " + adapterName + " is nil!")
+	}
+
+	adapterConfigs[adapterName] = newConfig
+}
+
+// new config func of registered adapter config
+func registeredConfig(adapterName string) (adapterConfigFunc, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	newConfig, ok := adapterConfigs[adapterName]
+	return newConfig, ok
+}
+
+// config file of logger
+type loggerConfigFile struct {
+
+	// logger level name, default "debug"
+	Level string
+
+	// async queue capacity of each adapter, 0 keeps the logger mode
+	Async int
+
+	// "block", "drop_oldest" or "drop_newest"
+	AsyncPolicy string
+
+	// routing table, see SetRoutes()
+	Routes string
+
+	Adapters []loggerConfigAdapter
+}
+
+// adapter of config file
+type loggerConfigAdapter struct {
+
+	// registered adapter name
+	Name string
+
+	// attach the adapter as alias, the same adapter can be attached many times by different aliases
+	Alias string
+
+	// least severe level written, default "debug"
+	Level string
+
+	// most severe level written, default "emergency"
+	MaxLevel string
+
+	// write timeout, eg: "2s"
+	Timeout time.Duration
+
+	// ttl of messages, see SetAdapterTTL(), eg: "1m"
+	TTL time.Duration
+
+	// tags of adapter metrics, see SetAdapterTags()
+	Tags map[string]string
+
+	// filter rules of the adapter, see SetAdapterFilter(), body rules are regexp strings
+	Filter *FilterRules
+
+	// adapters closed after the adapter, see SetAdapterDependsOn()
+	DependsOn []string
+
+	// categories written by the adapter only, see SetAdapterCategories()
+	Categories []string
+
+	// time format of the adapter, see SetAdapterTimeFormat(), eg: {location: UTC, millisecond_layout: RFC3339Nano}
+	Time *TimeFormat
+
+	// fields of the adapter config, eg: filename, max_size, date_slice of file adapter
+	Config map[string]interface{}
+}
+
+var (
+	configDurationType = reflect.TypeOf(time.Duration(0))
+	configFileModeType = reflect.TypeOf(os.FileMode(0))
+	configRegexpType   = reflect.TypeOf((*regexp.Regexp)(nil))
+	configLocationType = reflect.TypeOf((*time.Location)(nil))
+)
+
+// load config file, format by extension: ".json", ".yaml", ".yml" or ".toml"
+// attached adapters are replaced with the adapters of the config
+//
+// example config.yaml:
+//	level: info
+//	adapters:
+//	  - name: console
+//	    config: {color: true}
+//	  - name: file
+//	    level: warning
+//	    config:
+//	      filename: ./app.log
+//	      max_size: 102400
+//	      date_slice: d
+//	      level_file_name: {error: ./error.log}
+//	      flush_interval: 2s
+func (logger *Logger) LoadConfig(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	if format == "yml" {
+		format = CONFIG_FORMAT_YAML
+	}
+	return logger.LoadConfigBytes(data, format)
+}
+
+// load config of format, CONFIG_FORMAT_JSON | CONFIG_FORMAT_YAML | CONFIG_FORMAT_TOML
+// all adapters are initialized before attached, the logger is unchanged if any of them fails
+// adapters with the same config as the last load are kept, only their levels and timeout are updated
+func (logger *Logger) LoadConfigBytes(data []byte, format string) error {
+	document, err := parseConfigDocument(data, format)
+	if err != nil {
+		return err
+	}
+	configFile := &loggerConfigFile{}
+	err = decodeConfigValue(document, reflect.ValueOf(configFile), "config")
+	if err != nil {
+		return err
+	}
+
+	level := LOGGER_LEVEL_DEBUG
+	if configFile.Level != "" {
+		level, err = configLevel(configFile.Level, "config level")
+		if err != nil {
+			return err
+		}
+	}
+	policy, err := configAsyncPolicy(configFile.AsyncPolicy)
+	if err != nil {
+		return err
+	}
+	var router *Router
+	if configFile.Routes != "" {
+		router, err = ParseRoutes(configFile.Routes)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.reloadLock.Lock()
+	defer logger.reloadLock.Unlock()
+
+	logger.lock.Lock()
+	attached := logger.outputs
+	logger.lock.Unlock()
+
+	err = checkConfigDependencies(configFile.Adapters)
+	if err != nil {
+		return err
+	}
+	outputs, created, err := newConfigOutputs(configFile.Adapters, attached)
+	if err != nil {
+		return err
+	}
+
+	// swap outputs at once, removed outputs are drained and closed after swapped
+	logger.lock.Lock()
+	for _, output := range created {
+		logger.initOutput(output)
+	}
+	removed := []*outputLogger{}
+	for _, output := range logger.outputs {
+		if !containsOutput(outputs, output) {
+			removed = append(removed, output)
+		}
+	}
+	logger.setOutputs(outputs)
+	for _, output := range removed {
+		logger.removeFallbacks(output)
+	}
+	logger.updateStandby()
+	logger.updateGate()
+	logger.updateCategoryClaims()
+	if len(outputs) > 0 {
+		logger.flushEarly(outputs[0])
+	}
+	logger.lock.Unlock()
+
+	for _, output := range removed {
+		closeOutput(output)
+	}
+
+	if configFile.Async > 0 {
+		logger.SetAsyncPolicy(policy)
+		logger.SetAsync(configFile.Async)
+	}
+	logger.SetRouter(router)
+	logger.SetLevel(level)
+	return nil
+}
+
+// outputs of config adapters, attached outputs loaded with the same adapter config are kept
+// return all outputs and created ones, created ones are closed if any of them fails
+func newConfigOutputs(configAdapters []loggerConfigAdapter, attached []*outputLogger) ([]*outputLogger, []*outputLogger, error) {
+	outputs := []*outputLogger{}
+	created := []*outputLogger{}
+	for i, configAdapter := range configAdapters {
+		path := "config adapters[" + strconv.Itoa(i) + "]"
+		var output *outputLogger
+		var err error
+		for _, o := range outputs {
+			if o.Name == configAdapter.outputName() {
+				err = errors.New("logger: " + path + " adapter " + configAdapter.outputName() + " already attached!")
+			}
+		}
+		if err == nil {
+			output, err = reusedConfigOutput(configAdapter, attached, path)
+		}
+		if err == nil && output == nil {
+			output, err = newConfigOutput(configAdapter, path)
+			if err == nil {
+				created = append(created, output)
+			}
+		}
+		if err != nil {
+			for _, output := range created {
+				if closer, ok := output.LoggerAbstract.(LoggerCloser); ok {
+					closer.Close()
+				}
+			}
+			return nil, nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, created, nil
+}
+
+// attached output loaded with the same adapter config, levels and timeout are updated
+// nil if adapter config is changed
+func reusedConfigOutput(configAdapter loggerConfigAdapter, attached []*outputLogger, path string) (*outputLogger, error) {
+	for _, output := range attached {
+		if output.Name != configAdapter.outputName() || output.configSource == nil ||
+			output.configSource.Name != configAdapter.Name ||
+			!reflect.DeepEqual(output.configSource.Config, configAdapter.Config) {
+			continue
+		}
+		levels, err := configOutputLevels(configAdapter, path)
+		if err != nil {
+			return nil, err
+		}
+		err = checkAdapterTags(configAdapter.Tags)
+		if err != nil {
+			return nil, errors.New("logger: " + path + " " + err.Error())
+		}
+		timeFormat, err := configAdapter.Time.resolve()
+		if err != nil {
+			return nil, errors.New("logger: " + path + ".time " + err.Error())
+		}
+		output.levels.Store(levels)
+		output.timeFormat.Store(&timeFormat)
+		output.timeout.Store(configAdapter.Timeout)
+		output.ttl.Store(configAdapter.TTL)
+		output.setTags(configAdapter.Tags)
+		output.filter.Store(&configAdapter.Filter)
+		output.dependsOn.Store(configAdapter.DependsOn)
+		output.setCategories(configAdapter.Categories)
+		output.configSource = &configAdapter
+		return output, nil
+	}
+	return nil, nil
+}
+
+// initialized output of config adapter
+func newConfigOutput(configAdapter loggerConfigAdapter, path string) (*outputLogger, error) {
+	newConfig, ok := registeredConfig(configAdapter.Name)
+	if !ok {
+		return nil, errors.New("logger: " + path + " adapter " + configAdapter.Name + " config is not registered!")
+	}
+	newLog, ok := registeredAdapter(configAdapter.Name)
+	if !ok {
+		return nil, errors.New("logger: " + path + " adapter " + configAdapter.Name + " is nil!")
+	}
+	levels, err := configOutputLevels(configAdapter, path)
+	if err != nil {
+		return nil, err
+	}
+	err = checkAdapterTags(configAdapter.Tags)
+	if err != nil {
+		return nil, errors.New("logger: " + path + " " + err.Error())
+	}
+	timeFormat, err := configAdapter.Time.resolve()
+	if err != nil {
+		return nil, errors.New("logger: " + path + ".time " + err.Error())
+	}
+
+	config := newConfig()
+	err = decodeConfigValue(configAdapter.Config, reflect.ValueOf(config), path+".config")
+	if err != nil {
+		return nil, err
+	}
+	adapterLog := newLog()
+	err = adapterLog.Init(config)
+	if err != nil {
+		return nil, fmt.Errorf("logger: %s adapter %s init failed, error: %w", path, configAdapter.Name, configErrorOf(configAdapter.Name, err))
+	}
+
+	output := &outputLogger{
+		Name:           configAdapter.outputName(),
+		Level:          levels.min,
+		LoggerAbstract: adapterLog,
+		config:         config,
+		configSource:   &configAdapter,
+	}
+	output.levels.Store(levels)
+	output.timeout.Store(configAdapter.Timeout)
+	output.ttl.Store(configAdapter.TTL)
+	output.setTags(configAdapter.Tags)
+	output.filter.Store(&configAdapter.Filter)
+	output.dependsOn.Store(configAdapter.DependsOn)
+	output.setCategories(configAdapter.Categories)
+	output.timeFormat.Store(&timeFormat)
+	return output, nil
+}
+
+// output name of config adapter, alias or adapter name
+func (configAdapter *loggerConfigAdapter) outputName() string {
+	if configAdapter.Alias != "" {
+		return configAdapter.Alias
+	}
+	return configAdapter.Name
+}
+
+// level range of config adapter
+func configOutputLevels(configAdapter loggerConfigAdapter, path string) (*levelRange, error) {
+	levels := &levelRange{min: LOGGER_LEVEL_DEBUG, max: LOGGER_LEVEL_EMERGENCY}
+	var err error
+	if configAdapter.Level != "" {
+		levels.min, err = configLevel(configAdapter.Level, path+".level")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if configAdapter.MaxLevel != "" {
+		levels.max, err = configLevel(configAdapter.MaxLevel, path+".max_level")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return levels, nil
+}
+
+// output is one of outputs
+func containsOutput(outputs []*outputLogger, output *outputLogger) bool {
+	for _, o := range outputs {
+		if o == output {
+			return true
+		}
+	}
+	return false
+}
+
+// level of config value, name or number
+func configLevel(levelStr string, path string) (int, error) {
+	level, ok := levelFromString(levelStr)
+	if !ok {
+		return 0, errors.New("logger: " + path + " " + levelStr + " is illegal!")
+	}
+	return level, nil
+}
+
+// async policy of config value
+func configAsyncPolicy(policy string) (int, error) {
+	switch strings.ToLower(policy) {
+	case "", "block":
+		return ASYNC_POLICY_BLOCK, nil
+	case "drop_oldest":
+		return ASYNC_POLICY_DROP_OLDEST, nil
+	case "drop_newest":
+		return ASYNC_POLICY_DROP_NEWEST, nil
+	}
+	return 0, errors.New("logger: config async_policy must be one of 'block', 'drop_oldest', 'drop_newest'!")
+}
+
+// parse config document to generic values
+func parseConfigDocument(data []byte, format string) (interface{}, error) {
+	var document interface{}
+	var err error
+	switch format {
+	case CONFIG_FORMAT_JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&document)
+	case CONFIG_FORMAT_YAML:
+		err = yaml.Unmarshal(data, &document)
+	case CONFIG_FORMAT_TOML:
+		tomlDocument := map[string]interface{}{}
+		err = toml.Unmarshal(data, &tomlDocument)
+		document = tomlDocument
+	default:
+		return nil, errors.New("logger: config format " + format + " is not supported!")
+	}
+	if err != nil {
+		return nil, errors.New("logger: config parse failed, error: " + err.Error())
+	}
+	return document, nil
+}
+
+// field name of config key, "max_size", "max-size" and "MaxSize" are the same
+func configFieldName(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+// decode generic config value into dst
+// durations are strings ("1s") or nanoseconds, file modes are octal strings ("0640") or numbers, regexps are strings
+// level fields and map[int] keys accept level names
+func decodeConfigValue(value interface{}, dst reflect.Value, path string) error {
+	if value == nil {
+		return nil
+	}
+	if dst.Type() == configRegexpType {
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("logger: " + path + " must be a regexp string!")
+		}
+		pattern, err := regexp.Compile(text)
+		if err != nil {
+			return errors.New("logger: " + path + " must be a regexp, error: " + err.Error())
+		}
+		dst.Set(reflect.ValueOf(pattern))
+		return nil
+	}
+	if dst.Type() == configLocationType {
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("logger: " + path + " must be a time zone name!")
+		}
+		location, err := time.LoadLocation(text)
+		if err != nil {
+			return errors.New("logger: " + path + " must be a time zone, error: " + err.Error())
+		}
+		dst.Set(reflect.ValueOf(location))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return decodeConfigValue(value, dst.Elem(), path)
+	}
+
+	switch dst.Type() {
+	case configDurationType:
+		if text, ok := value.(string); ok {
+			duration, err := time.ParseDuration(text)
+			if err != nil {
+				return errors.New("logger: " + path + " must be a duration!")
+			}
+			dst.SetInt(int64(duration))
+			return nil
+		}
+	case configFileModeType:
+		if text, ok := value.(string); ok {
+			mode, err := strconv.ParseUint(text, 8, 32)
+			if err != nil {
+				return errors.New("logger: " + path + " must be an octal file mode!")
+			}
+			dst.SetUint(mode)
+			return nil
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return errors.New("logger: " + path + " is not supported in config!")
+		}
+		dst.Set(reflect.ValueOf(value))
+	case reflect.String:
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("logger: " + path + " must be a string!")
+		}
+		dst.SetString(text)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return errors.New("logger: " + path + " must be a bool!")
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if text, ok := value.(string); ok && strings.HasSuffix(strings.ToLower(path), "level") {
+			level, err := configLevel(text, path)
+			if err != nil {
+				return err
+			}
+			dst.SetInt(int64(level))
+			return nil
+		}
+		number, ok := configNumber(value)
+		if !ok || number != float64(int64(number)) {
+			return errors.New("logger: " + path + " must be an integer!")
+		}
+		dst.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := configNumber(value)
+		if !ok || number < 0 || number != float64(uint64(number)) {
+			return errors.New("logger: " + path + " must be a positive integer!")
+		}
+		dst.SetUint(uint64(number))
+	case reflect.Float32, reflect.Float64:
+		number, ok := configNumber(value)
+		if !ok {
+			return errors.New("logger: " + path + " must be a number!")
+		}
+		dst.SetFloat(number)
+	case reflect.Slice:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			return errors.New("logger: " + path + " must be a list!")
+		}
+		slice := reflect.MakeSlice(dst.Type(), items.Len(), items.Len())
+		for i := 0; i < items.Len(); i++ {
+			err := decodeConfigValue(items.Index(i).Interface(), slice.Index(i), path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case reflect.Map:
+		entries, ok := configMap(value)
+		if !ok {
+			return errors.New("logger: " + path + " must be a map!")
+		}
+		m := reflect.MakeMap(dst.Type())
+		for key, entry := range entries {
+			mapKey, err := decodeConfigKey(key, dst.Type().Key(), path)
+			if err != nil {
+				return err
+			}
+			mapValue := reflect.New(dst.Type().Elem()).Elem()
+			err = decodeConfigValue(entry, mapValue, path+"."+key)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(mapKey, mapValue)
+		}
+		dst.Set(m)
+	case reflect.Struct:
+		entries, ok := configMap(value)
+		if !ok {
+			return errors.New("logger: " + path + " must be a map!")
+		}
+		fields := map[string]int{}
+		for i := 0; i < dst.NumField(); i++ {
+			if dst.Type().Field(i).PkgPath == "" {
+				fields[configFieldName(dst.Type().Field(i).Name)] = i
+			}
+		}
+		for key, entry := range entries {
+			i, ok := fields[configFieldName(key)]
+			if !ok {
+				return errors.New("logger: " + path + " unknown field " + key + "!")
+			}
+			err := decodeConfigValue(entry, dst.Field(i), path+"."+key)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New("logger: " + path + " is not supported in config!")
+	}
+	return nil
+}
+
+// map key of config key, int keys are levels (LevelFileName) and accept level names
+func decodeConfigKey(key string, keyType reflect.Type, path string) (reflect.Value, error) {
+	mapKey := reflect.New(keyType).Elem()
+	switch keyType.Kind() {
+	case reflect.String:
+		mapKey.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		level, err := configLevel(key, path+" key")
+		if err != nil {
+			return mapKey, err
+		}
+		mapKey.SetInt(int64(level))
+	default:
+		return mapKey, errors.New("logger: " + path + " is not supported in config!")
+	}
+	return mapKey, nil
+}
+
+// number of json, yaml and toml values
+func configNumber(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case json.Number:
+		f, err := number.Float64()
+		return f, err == nil
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	case float64:
+		return number, true
+	case string:
+		f, err := strconv.ParseFloat(number, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// string keyed map of json, yaml and toml values
+func configMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		entries := make(map[string]interface{}, len(m))
+		for key, entry := range m {
+			entries[fmt.Sprint(key)] = entry
+		}
+		return entries, true
+	}
+	return nil, false
+}
@@ -0,0 +1,180 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_LoadConfigBytes(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "app.log")
+
+	configs := map[string]string{
+		CONFIG_FORMAT_JSON: `{
+	"level": "info",
+	"adapters": [
+		{"name": "memory", "config": {"size": 10}},
+		{"name": "file", "level": "warning", "max_level": "error", "timeout": "2s",
+		 "config": {"filename": "` + filename + `", "max_size": 1024, "flush_interval": "2s", "file_mode": "0640", "level_file_name": {"error": "` + filename + `.error"}, "format": "%body%"}}
+	]
+}`,
+		CONFIG_FORMAT_YAML: `
+level: info
+adapters:
+  - name: memory
+    config: {size: 10}
+  - name: file
+    level: warning
+    max_level: error
+    timeout: 2s
+    config:
+      filename: ` + filename + `
+      max_size: 1024
+      flush_interval: 2s
+      file_mode: "0640"
+      level_file_name: {error: ` + filename + `.error}
+      format: "%body%"
+`,
+		CONFIG_FORMAT_TOML: `
+level = "info"
+
+[[adapters]]
+name = "memory"
+config = {size = 10}
+
+[[adapters]]
+name = "file"
+level = "warning"
+max_level = "error"
+timeout = "2s"
+[adapters.config]
+filename = "` + filename + `"
+max_size = 1024
+flush_interval = "2s"
+file_mode = "0640"
+level_file_name = {error = "` + filename + `.error"}
+format = "%body%"
+`,
+	}
+
+	for format, config := range configs {
+		logger := NewLogger()
+		err := logger.LoadConfigBytes([]byte(config), format)
+		if err != nil {
+			t.Fatalf("%s config load error: %s", format, err.Error())
+		}
+		if logger.Adapter("console") != nil {
+			t.Errorf("%s config must replace attached adapters", format)
+		}
+		if logger.Level() != LOGGER_LEVEL_INFO {
+			t.Errorf("%s config level error", format)
+		}
+
+		fileAdapter := logger.Adapter("file").(*AdapterFile)
+		fc := fileAdapter.config
+		if fc.MaxSize != 1024 || fc.FlushInterval != 2*time.Second || fc.FileMode != 0640 || fc.LevelFileName[LOGGER_LEVEL_ERROR] != filename+".error" {
+			t.Errorf("%s file config error: %+v", format, fc)
+		}
+		if logger.Adapter("memory").(*AdapterMemory).config.Size != 10 {
+			t.Errorf("%s memory config error", format)
+		}
+
+		logger.Critical("critical")
+		logger.Error("error")
+		logger.Debug("debug")
+		logger.Detach("file")
+		content, _ := ioutil.ReadFile(filename)
+		if string(content) != "error\n" {
+			t.Errorf("%s adapter levels error: %q", format, content)
+		}
+		os.Remove(filename)
+		os.Remove(filename + ".error")
+	}
+}
+
+func TestLogger_LoadConfigBytesError(t *testing.T) {
+
+	tests := map[string]string{
+		`{"adapters": [{"name": "unknown"}]}`:                           "config is not registered",
+		`{"adapters": [{"name": "memory", "config": {"sise": 10}}]}`:    "unknown field sise",
+		`{"adapters": [{"name": "memory", "config": {"size": "ten"}}]}`: "must be an integer",
+		`{"adapters": [{"name": "file", "config": {"filename": ""}}]}`:  "init failed",
+		`{"level": "verbose"}`: "is illegal",
+		`{"adapters": [{"name": "memory"}, {"name": "memory"}]}`:                          "already attached",
+		`{"adapters": [{"name": "memory", "config": {"size": 10}}], "async_policy": "x"}`: "async_policy",
+	}
+	for config, expected := range tests {
+		logger := NewLogger()
+		err := logger.LoadConfigBytes([]byte(config), CONFIG_FORMAT_JSON)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("config %s error must contain %q: %v", config, expected, err)
+		}
+		if logger.Adapter("console") == nil {
+			t.Error("logger must be unchanged if config fails")
+		}
+	}
+}
+
+func TestLogger_LoadConfigBytesAlias(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory"}, {"name": "memory", "alias": "errors", "level": "error"}]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger.Info("info")
+	if len(logger.Adapter("memory").(*AdapterMemory).Entries()) != 1 || len(logger.Adapter("errors").(*AdapterMemory).Entries()) != 0 {
+		t.Error("adapter alias config error")
+	}
+}
+
+func TestLogger_LoadConfigBytesTags(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory", "tags": {"team": "core"}}]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tags := logger.Stats().Adapters["memory"].Tags; tags["team"] != "core" {
+		t.Errorf("adapter tags config error: %v", tags)
+	}
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory", "tags": {"team": "payments"}}]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if tags := logger.Stats().Adapters["memory"].Tags; tags["team"] != "payments" {
+		t.Errorf("adapter tags reload error: %v", tags)
+	}
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory", "tags": {"__team": "core"}}]}`), CONFIG_FORMAT_JSON)
+	if err == nil || !strings.Contains(err.Error(), "adapters[0] adapter tag __team") {
+		t.Errorf("adapter tags config must be error: %v", err)
+	}
+}
+
+func TestLogger_LoadConfig(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "logger.yml")
+	ioutil.WriteFile(filename, []byte("adapters:\n  - name: memory\n"), 0644)
+	logger := NewLogger()
+	if logger.LoadConfig(filename) != nil || logger.Adapter("memory") == nil {
+		t.Error("yml config load error")
+	}
+	if logger.LoadConfig(path.Join(dir, "logger.ini")) == nil {
+		t.Error("missing config file must be error")
+	}
+}
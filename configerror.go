@@ -0,0 +1,139 @@
+package go_logger
+
+import (
+	"errors"
+	"strings"
+)
+
+// errors.Is(err, ErrConfig) is true for config errors of adapters, see ConfigError
+var ErrConfig = errors.New("logger: config error")
+
+// the config passed to Init is not the config of the adapter, or it's nil
+var ErrConfigType = errors.New("logger: config type error")
+
+// config error returned by Init of adapters and Attach
+//
+// example:
+//	var configErr *go_logger.ConfigError
+//	if errors.As(err, &configErr) && configErr.Field == "Filename" {
+//		...
+//	}
+type ConfigError struct {
+
+	// registered name of the adapter, eg: "file", empty if the config is not of an adapter
+	Adapter string
+
+	// field of the config, eg: "MaxSize", empty if the config itself is illegal
+	Field string
+
+	// why the field is illegal, eg: "must not be negative!"
+	Reason string
+
+	// cause of the error, eg: errors of opening the file, ErrConfigType
+	Err error
+}
+
+func (err *ConfigError) Error() string {
+	if err.Field == "" {
+		return "logger " + err.Adapter + " adapter init error, " + err.Reason
+	}
+	message := "config " + err.Field + " " + err.Reason
+	if err.Err != nil {
+		message += ", error: " + err.Err.Error()
+	}
+	return message
+}
+
+func (err *ConfigError) Unwrap() error {
+	return err.Err
+}
+
+// all config errors are ErrConfig
+func (err *ConfigError) Is(target error) bool {
+	return target == ErrConfig
+}
+
+func configError(adapter string, field string, reason string) error {
+	return &ConfigError{Adapter: adapter, Field: field, Reason: reason}
+}
+
+// config error caused by err
+func configErrorCause(adapter string, field string, reason string, err error) error {
+	return &ConfigError{Adapter: adapter, Field: field, Reason: reason, Err: err}
+}
+
+// the config is not the config of the adapter, eg: "config must FileConfig"
+func configTypeError(adapter string, config string) error {
+	return &ConfigError{Adapter: adapter, Reason: "config must " + config, Err: ErrConfigType}
+}
+
+// set the adapter of config errors returned by shared validations, eg: checkLineEnding()
+func configErrorOf(adapter string, err error) error {
+	var configErr *ConfigError
+	if errors.As(err, &configErr) && configErr.Adapter == "" {
+		configErr.Adapter = adapter
+	}
+	return err
+}
+
+// first placeholder like "%name%" or "%name(...)%" of the format which isn't known, empty if there's none
+// text between percent signs which isn't a name, eg: "100% done %body%", is literal text
+func unknownFormatPlaceholder(format string) string {
+	for i := 0; i < len(format); {
+		if strings.HasPrefix(format[i:], "%{") {
+			end := formatGroupEnd(format[i+2:])
+			if end >= 0 {
+				if unknown := unknownFormatPlaceholder(format[i+2 : i+2+end]); unknown != "" {
+					return unknown
+				}
+				i += end + 4
+				continue
+			}
+		}
+		if format[i] == '%' {
+			end := strings.IndexByte(format[i+1:], '%')
+			if end >= 0 {
+				expr := format[i+1 : i+1+end]
+				if _, ok := compileFormatExpr(expr); ok {
+					i += end + 2
+					continue
+				}
+				if formatPlaceholderName(expr) {
+					return "%" + expr + "%"
+				}
+			}
+		}
+		i++
+	}
+	return ""
+}
+
+// the expression is a name, eg: "levl_string", or a call of a name, eg: "uper(body)"
+func formatPlaceholderName(expr string) bool {
+	if open := strings.IndexByte(expr, '('); open > 0 && strings.HasSuffix(expr, ")") {
+		expr = expr[:open]
+	}
+	if expr == "" {
+		return false
+	}
+	for i, c := range expr {
+		letter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// placeholders of Format and LevelFormat must be known
+func checkFormatPlaceholders(format string, levelFormats map[int]string) error {
+	if unknown := unknownFormatPlaceholder(format); unknown != "" {
+		return configError("", "Format", "placeholder "+unknown+" is unknown!")
+	}
+	for _, levelFormat := range levelFormats {
+		if unknown := unknownFormatPlaceholder(levelFormat); unknown != "" {
+			return configError("", "LevelFormat", "placeholder "+unknown+" is unknown!")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package go_logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigError(t *testing.T) {
+
+	tests := map[string]*FileConfig{
+		"config Filename can't be empty!":                         {},
+		"config MaxLine must not be negative!":                    {Filename: "test.log", MaxLine: -1},
+		"config MaxRecordSize must not be larger than MaxSize!":   {Filename: "test.log", MaxSize: 100, MaxRecordSize: 200 * 1024},
+		"config Format placeholder %levl_string% is unknown!":     {Filename: "test.log", Format: "%levl_string% %body%"},
+		"config LevelFormat placeholder %uper(body)% is unknown!": {Filename: "test.log", LevelFormat: map[int]string{LOGGER_LEVEL_ERROR: "%uper(body)%"}},
+	}
+	for expected, config := range tests {
+		err := NewAdapterFile().Init(config)
+		var configErr *ConfigError
+		if err == nil || err.Error() != expected || !errors.Is(err, ErrConfig) || !errors.As(err, &configErr) || configErr.Field == "" {
+			t.Errorf("file config error must be %q: %v", expected, err)
+		}
+	}
+
+	err := NewAdapterFile().Init(&FileConfig{Filename: "test.log", Format: "100% of %body% %{[%trace_id%]}%"})
+	if err != nil {
+		t.Errorf("file config literal percent error: %v", err)
+	}
+	os.Remove("test.log")
+}
+
+func TestConfigError_Type(t *testing.T) {
+
+	var config *FileConfig
+	err := NewAdapterFile().Init(config)
+	if err == nil || !errors.Is(err, ErrConfigType) || err.Error() != "logger file adapter init error, config must FileConfig" {
+		t.Errorf("file config type error: %v", err)
+	}
+	err = NewAdapterConsole().Init(&FileConfig{})
+	if !errors.Is(err, ErrConfigType) || !errors.Is(err, ErrConfig) {
+		t.Errorf("console config type error: %v", err)
+	}
+}
+
+func TestLogger_AttachConfigError(t *testing.T) {
+
+	dir := t.TempDir()
+	os.Chmod(dir, 0500)
+	defer os.Chmod(dir, 0700)
+
+	logger := NewLogger()
+	err := logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{Filename: filepath.Join(dir, "app.log")})
+	var configErr *ConfigError
+	if os.Getuid() != 0 && (!errors.As(err, &configErr) || configErr.Adapter != "file" || configErr.Field != "Filename" || !os.IsPermission(errors.Unwrap(configErr))) {
+		t.Errorf("attach file not writable error: %v", err)
+	}
+
+	err = logger.AttachAs("stderr", "console", LOGGER_LEVEL_DEBUG, &ConsoleConfig{LineEnding: "\t"})
+	if !errors.As(err, &configErr) || configErr.Adapter != "console" || configErr.Field != "LineEnding" {
+		t.Errorf("attach console line ending error: %v", err)
+	}
+}
@@ -1,12 +1,12 @@
 package go_logger
 
 import (
-	"errors"
 	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
 	"io"
 	"os"
-	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 const CONSOLE_ADAPTER_NAME = "console"
@@ -24,14 +24,17 @@ var levelColors = map[int]color.Attribute{
 
 // adapter console
 type AdapterConsole struct {
+	encodingErrors int64 // json fallbacks of marshal errors
+
 	write  *ConsoleWriter
 	config *ConsoleConfig
 }
 
 // console writer
 type ConsoleWriter struct {
-	lock   sync.Mutex
-	writer io.Writer
+	lock      sync.Mutex
+	writer    io.Writer
+	errWriter io.Writer
 }
 
 // console config
@@ -39,6 +42,21 @@ type ConsoleConfig struct {
 	// console text is show color
 	Color bool
 
+	// color of level, levels not in it use the default colors
+	// example: map[int]color.Attribute{LOGGER_LEVEL_ERROR: color.FgHiRed}
+	LevelColors map[int]color.Attribute
+
+	// is write messages at or above StderrLevel to stderr, others to stdout
+	Stderr bool
+
+	// messages at or above the level are written to stderr if Stderr is true
+	// example: LOGGER_LEVEL_WARNING writes warning, error, critical, alert and emergency to stderr
+	StderrLevel int
+
+	// formatter of messages, JsonFormat and Format are ignored if it's set
+	// example: &go_logger.LogfmtFormatter{}
+	Formatter Formatter
+
 	// is json format
 	JsonFormat bool
 
@@ -55,9 +73,29 @@ type ConsoleConfig struct {
 	//	File string "%file%"
 	//	Line int "%line%"
 	//	Function "%function%"
+	//	Fields "%fields%"
+	//	TraceId "%trace_id%"
+	//	SpanId "%span_id%"
+	//	Deploy "%deploy%"
+	//	Component "%component%"
+	//	Stacktrace "%stacktrace%"
 	//
 	// example: format = "%millisecond_format% [%level_string%] %body%"
 	Format string
+
+	// format of levels, overrides Format and JsonFormat of the level
+	// example: LevelFormat = map[int]string{go_logger.LOGGER_LEVEL_ERROR: "%millisecond_format% [%level_string%] [%file%:%line% %function%] %body%\n%stacktrace%"}
+	LevelFormat map[int]string
+
+	// json format of levels, overrides JsonFormat and LevelFormat of the level
+	// example: LevelJsonFormat = map[int]bool{go_logger.LOGGER_LEVEL_DEBUG: true}
+	LevelJsonFormat map[int]bool
+
+	// line ending of messages, LINE_ENDING_LF or LINE_ENDING_CRLF, default "\r\n" on windows, otherwise "\n"
+	LineEnding string
+
+	// encode messages before they are written, eg: simplifiedchinese.GBK.NewEncoder(), default utf-8
+	Encoder TextEncoder
 }
 
 func (cc *ConsoleConfig) Name() string {
@@ -66,7 +104,8 @@ func (cc *ConsoleConfig) Name() string {
 
 func NewAdapterConsole() LoggerAbstract {
 	consoleWrite := &ConsoleWriter{
-		writer: os.Stdout,
+		writer:    os.Stdout,
+		errWriter: os.Stderr,
 	}
 	config := &ConsoleConfig{}
 	return &AdapterConsole{
@@ -76,17 +115,29 @@ func NewAdapterConsole() LoggerAbstract {
 }
 
 func (adapterConsole *AdapterConsole) Init(consoleConfig Config) error {
-	if consoleConfig.Name() != CONSOLE_ADAPTER_NAME {
-		return errors.New("logger console adapter init error, config must ConsoleConfig")
+	cc, ok := consoleConfig.(*ConsoleConfig)
+	if !ok || cc == nil {
+		return configTypeError(CONSOLE_ADAPTER_NAME, "ConsoleConfig")
 	}
-
-	vc := reflect.ValueOf(consoleConfig)
-	cc := vc.Interface().(*ConsoleConfig)
 	adapterConsole.config = cc
 
 	if cc.JsonFormat == false && cc.Format == "" {
 		cc.Format = defaultLoggerMessageFormat
 	}
+	if err := checkLineEnding(&cc.LineEnding); err != nil {
+		return err
+	}
+	if err := checkLevelFormat(cc.LevelFormat, cc.LevelJsonFormat); err != nil {
+		return err
+	}
+	if err := checkFormatPlaceholders(cc.Format, cc.LevelFormat); err != nil {
+		return err
+	}
+	if cc.Color {
+		// colorable writers translate ansi colors on windows
+		adapterConsole.write.writer = colorable.NewColorableStdout()
+		adapterConsole.write.errWriter = colorable.NewColorableStderr()
+	}
 
 	return nil
 }
@@ -94,40 +145,60 @@ func (adapterConsole *AdapterConsole) Init(consoleConfig Config) error {
 func (adapterConsole *AdapterConsole) Write(loggerMsg *loggerMessage) error {
 
 	msg := ""
-	if adapterConsole.config.JsonFormat == true {
+	config := adapterConsole.config
+	format, jsonFormat := levelFormat(loggerMsg.Level, config.Format, config.JsonFormat, config.LevelFormat, config.LevelJsonFormat)
+	if config.Formatter != nil {
+		msg = formatterFormat(config.Formatter, loggerMsg)
+	} else if jsonFormat == true {
 		//jsonByte, _ := json.Marshal(loggerMsg)
-		jsonByte, _ := loggerMsg.MarshalJSON()
+		jsonByte := marshalLoggerMessage(loggerMsg, &adapterConsole.encodingErrors)
 		msg = string(jsonByte)
 	} else {
-		msg = loggerMessageFormat(adapterConsole.config.Format, loggerMsg)
+		msg = loggerMessageFormat(format, loggerMsg)
 	}
 	consoleWriter := adapterConsole.write
+	writer := consoleWriter.writer
+	if adapterConsole.config.Stderr && loggerMsg.Level <= adapterConsole.config.StderrLevel {
+		writer = consoleWriter.errWriter
+	}
+
+	colorAttr := adapterConsole.getColorByLevel(loggerMsg.Level, msg)
+	consoleWriter.lock.Lock()
+	defer consoleWriter.lock.Unlock()
+	msg, err := encodeText(adapterConsole.config.Encoder, msg+adapterConsole.config.LineEnding)
+	if err != nil {
+		return err
+	}
 
 	if adapterConsole.config.Color {
-		colorAttr := adapterConsole.getColorByLevel(loggerMsg.Level, msg)
-		consoleWriter.lock.Lock()
-		color.New(colorAttr).Println(msg)
-		consoleWriter.lock.Unlock()
-		return nil
+		_, err = color.New(colorAttr).Fprint(writer, msg)
+		return err
 	}
 
-	consoleWriter.lock.Lock()
-	consoleWriter.writer.Write([]byte(msg + "\n"))
-	consoleWriter.lock.Unlock()
+	_, err = writer.Write([]byte(msg))
 
-	return nil
+	return err
 }
 
 func (adapterConsole *AdapterConsole) Name() string {
 	return CONSOLE_ADAPTER_NAME
 }
 
+// Counters of json fallbacks
+func (adapterConsole *AdapterConsole) Counters() map[string]int64 {
+	return map[string]int64{COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterConsole.encodingErrors)}
+}
+
 func (adapterConsole *AdapterConsole) Flush() {
 
 }
 
 func (adapterConsole *AdapterConsole) getColorByLevel(level int, content string) color.Attribute {
-	lc, ok := levelColors[level]
+	lc, ok := adapterConsole.config.LevelColors[level]
+	if ok {
+		return lc
+	}
+	lc, ok = levelColorOf(level)
 	if !ok {
 		lc = color.FgWhite
 	}
@@ -136,4 +207,7 @@ func (adapterConsole *AdapterConsole) getColorByLevel(level int, content string)
 
 func init() {
 	Register(CONSOLE_ADAPTER_NAME, NewAdapterConsole)
+	RegisterConfig(CONSOLE_ADAPTER_NAME, func() Config {
+		return &ConsoleConfig{}
+	})
 }
@@ -1,6 +1,10 @@
 package go_logger
 
 import (
+	"bytes"
+	"errors"
+	"github.com/fatih/color"
+	"strings"
 	"testing"
 	"time"
 )
@@ -79,3 +83,92 @@ func TestAdapterConsole_WriteJsonFormat(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestAdapterConsole_WriteStderr(t *testing.T) {
+
+	consoleAdapter := NewAdapterConsole()
+	consoleAdapter.Init(&ConsoleConfig{
+		Color:       true,
+		LevelColors: map[int]color.Attribute{LOGGER_LEVEL_WARNING: color.FgHiRed},
+		Stderr:      true,
+		StderrLevel: LOGGER_LEVEL_WARNING,
+		Format:      "%level_string% %body%",
+	})
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	consoleAdapter.(*AdapterConsole).write.writer = stdout
+	consoleAdapter.(*AdapterConsole).write.errWriter = stderr
+
+	for level := LOGGER_LEVEL_EMERGENCY; level <= LOGGER_LEVEL_DEBUG; level++ {
+		consoleAdapter.Write(&loggerMessage{
+			Level:       level,
+			LevelString: levelNames()[level],
+			Body:        "stderr test",
+		})
+	}
+
+	if strings.Count(stderr.String(), "stderr test") != 5 || !strings.Contains(stderr.String(), "Warning") {
+		t.Error("console stderr write error: " + stderr.String())
+	}
+	if strings.Count(stdout.String(), "stderr test") != 3 || !strings.Contains(stdout.String(), "Notice") {
+		t.Error("console stdout write error: " + stdout.String())
+	}
+	if consoleAdapter.(*AdapterConsole).getColorByLevel(LOGGER_LEVEL_WARNING, "") != color.FgHiRed {
+		t.Error("console level color error")
+	}
+	if consoleAdapter.(*AdapterConsole).getColorByLevel(LOGGER_LEVEL_ERROR, "") != color.FgRed {
+		t.Error("console default level color error")
+	}
+}
+
+func TestAdapterConsole_LineEnding(t *testing.T) {
+
+	// latin-1 encoder of legacy tools
+	latin1 := TextEncoderFunc(func(text []byte) ([]byte, error) {
+		encoded := []byte{}
+		for _, r := range string(text) {
+			if r > 0xff {
+				return nil, errors.New("rune is not latin-1")
+			}
+			encoded = append(encoded, byte(r))
+		}
+		return encoded, nil
+	})
+	consoleAdapter := NewAdapterConsole()
+	err := consoleAdapter.Init(&ConsoleConfig{Format: "%body%", LineEnding: LINE_ENDING_CRLF, Encoder: latin1})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	stdout := &bytes.Buffer{}
+	consoleAdapter.(*AdapterConsole).write.writer = stdout
+
+	consoleAdapter.Write(&loggerMessage{Level: LOGGER_LEVEL_INFO, Body: "café"})
+	if err := consoleAdapter.Write(&loggerMessage{Level: LOGGER_LEVEL_INFO, Body: "日本"}); err == nil {
+		t.Error("message the encoder can't encode must return the error")
+	}
+	if stdout.String() != "caf\xe9\r\n" {
+		t.Errorf("console line ending and encoding error: %q", stdout.String())
+	}
+
+	if NewAdapterConsole().Init(&ConsoleConfig{LineEnding: "\r"}) == nil {
+		t.Error("illegal line ending must return error")
+	}
+}
+
+func TestAdapterConsole_LevelFormat(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	consoleAdapter := NewAdapterConsole().(*AdapterConsole)
+	consoleAdapter.Init(&ConsoleConfig{
+		JsonFormat:  true,
+		LevelFormat: map[int]string{LOGGER_LEVEL_ERROR: "[%level_string%] %body%"},
+	})
+	consoleAdapter.write.writer = buf
+
+	consoleAdapter.Write(&loggerMessage{Level: LOGGER_LEVEL_ERROR, LevelString: "Error", Body: "failed"})
+	consoleAdapter.Write(&loggerMessage{Level: LOGGER_LEVEL_INFO, LevelString: "Info", Body: "compact"})
+	lines := strings.Split(buf.String(), consoleAdapter.config.LineEnding)
+	if lines[0] != "[Error] failed" || !strings.HasPrefix(lines[1], "{") {
+		t.Errorf("console level format error: %q", buf.String())
+	}
+}
@@ -0,0 +1,259 @@
+package go_logger
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// field names of the default context extractor
+const (
+	LOGGER_FIELD_TRACE_ID   = "trace_id"
+	LOGGER_FIELD_SPAN_ID    = "span_id"
+	LOGGER_FIELD_REQUEST_ID = "request_id"
+)
+
+// extract fields (trace id, request id ...) from context
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+type loggerTraceKey struct{}
+
+type loggerTrace struct {
+	traceId string
+	spanId  string
+}
+
+// context with trace id and span id, read by DefaultContextExtractor
+func ContextWithTrace(ctx context.Context, traceId string, spanId string) context.Context {
+	return context.WithValue(ctx, loggerTraceKey{}, loggerTrace{traceId: traceId, spanId: spanId})
+}
+
+// trace id and span id of the context
+func TraceFromContext(ctx context.Context) (traceId string, spanId string) {
+	if ctx == nil {
+		return "", ""
+	}
+	trace, _ := ctx.Value(loggerTraceKey{}).(loggerTrace)
+	return trace.traceId, trace.spanId
+}
+
+// default context extractor, fields "trace_id" and "span_id" of ContextWithTrace, "request_id" of ContextWithRequestId
+func DefaultContextExtractor(ctx context.Context) map[string]interface{} {
+	traceId, spanId := TraceFromContext(ctx)
+	requestId := RequestIdFromContext(ctx)
+	if traceId == "" && spanId == "" && requestId == "" {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	if traceId != "" {
+		fields[LOGGER_FIELD_TRACE_ID] = traceId
+	}
+	if spanId != "" {
+		fields[LOGGER_FIELD_SPAN_ID] = spanId
+	}
+	if requestId != "" {
+		fields[LOGGER_FIELD_REQUEST_ID] = requestId
+	}
+	return fields
+}
+
+// set context extractor, nil restores DefaultContextExtractor
+//
+// example, read the trace of opentelemetry:
+//	logger.SetContextExtractor(func(ctx context.Context) map[string]interface{} {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return map[string]interface{}{"trace_id": sc.TraceID().String(), "span_id": sc.SpanID().String()}
+//	})
+func (logger *Logger) SetContextExtractor(extractor ContextExtractor) {
+	if extractor == nil {
+		extractor = DefaultContextExtractor
+	}
+	logger.extractor.Store(extractor)
+}
+
+// fields of the context
+func (logger *Logger) contextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+	extractor, ok := logger.extractor.Load().(ContextExtractor)
+	if !ok {
+		extractor = DefaultContextExtractor
+	}
+	return extractor(ctx)
+}
+
+// write log message with context fields
+// params : ctx context.Context, level int, msg string
+// return : error
+func (logger *Logger) WriterCtx(ctx context.Context, level int, msg string) error {
+	return logger.writeCtx(3, ctx, level, msg, nil)
+}
+
+// write log message with context fields and fields, fields take precedence
+func (logger *Logger) WriterFieldsCtx(ctx context.Context, level int, msg string, fields map[string]interface{}) error {
+	return logger.writeCtx(3, ctx, level, msg, fields)
+}
+
+func (logger *Logger) writeCtx(callDepth int, ctx context.Context, level int, msg string, fields map[string]interface{}) error {
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		return ErrLoggerClosed
+	}
+
+	// captured, verbose and boosted messages are kept regardless of logger level
+	verbose := minLevelAccept(ctx, level)
+	boosted := verboseFromContext(ctx) && level <= int(atomic.LoadInt32(&logger.boostGate))
+	capture := captureFromContext(ctx)
+	captured := capture != nil && capture.logger == logger && level >= capture.level
+	if !captured && !verbose && !boosted && !logger.enabled(level) {
+		return nil
+	}
+
+	ctxFields := logger.contextFields(ctx)
+	if len(ctxFields) > 0 {
+		merged := make(map[string]interface{}, len(ctxFields)+len(fields))
+		for key, value := range ctxFields {
+			merged[key] = value
+		}
+		for key, value := range fields {
+			merged[key] = value
+		}
+		fields = merged
+	}
+	loggerMsg := logger.callerMessage(callDepth, level, msg, fields)
+	loggerMsg.verbose = verbose
+	loggerMsg.boosted = boosted
+	if captured {
+		capture.add(loggerMsg)
+		return nil
+	}
+	logger.dispatch(loggerMsg, nil)
+	return nil
+}
+
+// the message of the context is written, checked before it's formatted like Xxxf
+func (logger *Logger) ctxEnabled(ctx context.Context, level int) bool {
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		return false
+	}
+	if logger.enabled(level) || minLevelAccept(ctx, level) {
+		return true
+	}
+	if verboseFromContext(ctx) && level <= int(atomic.LoadInt32(&logger.boostGate)) {
+		return true
+	}
+	capture := captureFromContext(ctx)
+	return capture != nil && capture.logger == logger && level >= capture.level
+}
+
+// log emergency level with context
+func (logger *Logger) EmergencyCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_EMERGENCY, msg, nil)
+}
+
+// log emergency format with context
+func (logger *Logger) EmergencyCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_EMERGENCY, msg, nil)
+}
+
+// log alert level with context
+func (logger *Logger) AlertCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_ALERT, msg, nil)
+}
+
+// log alert format with context
+func (logger *Logger) AlertCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_ALERT) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_ALERT, msg, nil)
+}
+
+// log critical level with context
+func (logger *Logger) CriticalCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_CRITICAL, msg, nil)
+}
+
+// log critical format with context
+func (logger *Logger) CriticalCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_CRITICAL) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_CRITICAL, msg, nil)
+}
+
+// log error level with context
+func (logger *Logger) ErrorCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_ERROR, msg, nil)
+}
+
+// log error format with context
+func (logger *Logger) ErrorCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_ERROR) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_ERROR, msg, nil)
+}
+
+// log warning level with context
+func (logger *Logger) WarningCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_WARNING, msg, nil)
+}
+
+// log warning format with context
+func (logger *Logger) WarningCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_WARNING) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_WARNING, msg, nil)
+}
+
+// log notice level with context
+func (logger *Logger) NoticeCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_NOTICE, msg, nil)
+}
+
+// log notice format with context
+func (logger *Logger) NoticeCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_NOTICE) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_NOTICE, msg, nil)
+}
+
+// log info level with context
+func (logger *Logger) InfoCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_INFO, msg, nil)
+}
+
+// log info format with context
+func (logger *Logger) InfoCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_INFO) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_INFO, msg, nil)
+}
+
+// log debug level with context
+func (logger *Logger) DebugCtx(ctx context.Context, msg string) {
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_DEBUG, msg, nil)
+}
+
+// log debug format with context
+func (logger *Logger) DebugCtxf(ctx context.Context, format string, a ...interface{}) {
+	if !logger.ctxEnabled(ctx, LOGGER_LEVEL_DEBUG) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	logger.writeCtx(3, ctx, LOGGER_LEVEL_DEBUG, msg, nil)
+}
@@ -0,0 +1,70 @@
+package go_logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type testRequestIdKey struct{}
+
+func TestLogger_InfoCtx(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "[%level_string%] %body% trace=%trace_id% span=%span_id% %file%",
+	})
+
+	ctx := ContextWithTrace(context.Background(), "abc", "def")
+	logger.InfoCtx(ctx, "ctx info")
+	if strings.TrimSpace(buffer.String()) != "[Info] ctx info trace=abc span=def context_test.go" {
+		t.Errorf("logger ctx text error: %q", buffer.String())
+	}
+
+	buffer.Reset()
+	logger.SetContextExtractor(func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{"request_id": ctx.Value(testRequestIdKey{})}
+	})
+	logger.WriterFieldsCtx(context.WithValue(ctx, testRequestIdKey{}, "r1"), LOGGER_LEVEL_ERROR, "ctx error", map[string]interface{}{"code": 1})
+	if strings.TrimSpace(buffer.String()) != "[Error] ctx error trace= span= context_test.go" {
+		t.Errorf("logger ctx extractor error: %q", buffer.String())
+	}
+
+	logger.Detach("writer")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, JsonFormat: true})
+	buffer.Reset()
+	logger.ErrorCtxf(context.WithValue(ctx, testRequestIdKey{}, "r2"), "ctx %s", "json")
+	if !strings.Contains(buffer.String(), `"fields":{"request_id":"r2"}`) {
+		t.Errorf("logger ctx json error: %s", buffer.String())
+	}
+}
+
+func TestLogger_CtxLevelGate(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_WARNING, &MemoryConfig{})
+	extracted := 0
+	logger.SetContextExtractor(func(ctx context.Context) map[string]interface{} {
+		extracted++
+		return nil
+	})
+
+	formatted := 0
+	logger.InfoCtxf(context.Background(), "%s", levelGateStringer{&formatted})
+	logger.InfoCtx(context.Background(), "info")
+	if formatted != 0 || extracted != 0 {
+		t.Errorf("ctx messages no adapter writes must not be formatted or extracted: %d %d", formatted, extracted)
+	}
+
+	logger.InfoCtxf(ContextWithMinLevel(context.Background(), LOGGER_LEVEL_INFO), "%s", levelGateStringer{&formatted})
+	logger.Close(context.Background())
+	logger.ErrorCtxf(context.Background(), "%s", levelGateStringer{&formatted})
+	if formatted != 1 || extracted != 1 {
+		t.Errorf("ctx level gate error: %d %d", formatted, extracted)
+	}
+}
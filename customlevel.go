@@ -0,0 +1,121 @@
+package go_logger
+
+import (
+	"github.com/fatih/color"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// register a custom level with its name and console color
+// the level is its severity like built-in levels, more severe is smaller:
+// eg: 8 is below debug, -1 is above emergency so it's written by every adapter
+// custom levels are used by SetLevel, Attach, LevelFileName, LevelColors and Writer, registering is safe while messages are logged
+//
+// example:
+//	const LEVEL_TRACE = 8
+//	const LEVEL_AUDIT = -1
+//
+//	func init() {
+//		go_logger.RegisterLevel(LEVEL_TRACE, "Trace", color.FgHiBlack)
+//		go_logger.RegisterLevel(LEVEL_AUDIT, "Audit", color.FgHiMagenta)
+//	}
+//
+//	logger.Writer(LEVEL_AUDIT, "user 42 deleted order 7")
+func RegisterLevel(level int, name string, levelColor color.Attribute) {
+	if name == "" {
+		panic("logger: level " + strconv.Itoa(level) + " name is empty!")
+	}
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	if levelNames()[level] != "" {
+		panic("logger: level " + strconv.Itoa(level) + " already registered!")
+	}
+	if _, ok := levelFromString(name); ok {
+		panic("logger: level name " + name + " already registered!")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		panic("logger: level name " + name + " is a number!")
+	}
+
+	// messages read the maps without lock, they're copied and replaced
+	names := map[int]string{level: name}
+	for registered, registeredName := range levelNames() {
+		names[registered] = registeredName
+	}
+	colors := map[int]color.Attribute{level: levelColor}
+	for registered, registeredColor := range registeredColors.Load().(map[int]color.Attribute) {
+		colors[registered] = registeredColor
+	}
+	registeredNames.Store(names)
+	registeredColors.Store(colors)
+}
+
+// lock of RegisterLevel, names and colors are read without lock
+var levelLock sync.Mutex
+
+// names and colors of built-in and registered levels, the maps are never changed after they're stored
+var (
+	registeredNames  = newLevelValue(levelStringMapping)
+	registeredColors = newLevelValue(levelColors)
+)
+
+func newLevelValue(levels interface{}) *atomic.Value {
+	value := &atomic.Value{}
+	value.Store(levels)
+	return value
+}
+
+// names of registered levels, read only
+func levelNames() map[int]string {
+	return registeredNames.Load().(map[int]string)
+}
+
+// console color of the registered level
+func levelColorOf(level int) (color.Attribute, bool) {
+	levelColor, ok := registeredColors.Load().(map[int]color.Attribute)[level]
+	return levelColor, ok
+}
+
+// registered levels from the most severe
+func registeredLevels() []int {
+	names := levelNames()
+	levels := make([]int, 0, len(names))
+	for level := range names {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	return levels
+}
+
+// built-in level of the custom level for syslog severities, levels out of them are clamped
+func builtinLevel(level int) int {
+	if level < LOGGER_LEVEL_EMERGENCY {
+		return LOGGER_LEVEL_EMERGENCY
+	}
+	if level > LOGGER_LEVEL_DEBUG {
+		return LOGGER_LEVEL_DEBUG
+	}
+	return level
+}
+
+// counters of custom levels
+type customLevelCounters struct {
+	counters sync.Map // level int: *int64
+}
+
+func (custom *customLevelCounters) add(level int) {
+	counter, ok := custom.counters.Load(level)
+	if !ok {
+		counter, _ = custom.counters.LoadOrStore(level, new(int64))
+	}
+	atomic.AddInt64(counter.(*int64), 1)
+}
+
+func (custom *customLevelCounters) each(fn func(level int, count int64)) {
+	custom.counters.Range(func(level, counter interface{}) bool {
+		fn(level.(int), atomic.LoadInt64(counter.(*int64)))
+		return true
+	})
+}
@@ -0,0 +1,100 @@
+package go_logger
+
+import (
+	"github.com/fatih/color"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testLevelTrace = 8
+	testLevelAudit = -1
+)
+
+func init() {
+	RegisterLevel(testLevelTrace, "Trace", color.FgHiBlack)
+	RegisterLevel(testLevelAudit, "Audit", color.FgHiMagenta)
+}
+
+func TestRegisterLevel(t *testing.T) {
+
+	buffer := &switchWriter{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "[%level_string%] %body%"})
+
+	logger.Writer(testLevelTrace, "trace")
+	logger.Writer(testLevelAudit, "audit")
+	err := logger.SetLevel(testLevelTrace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Detach("writer")
+	logger.Attach("writer", testLevelTrace, &WriterConfig{Writer: buffer, Format: "[%level_string%] %body%"})
+	logger.Writer(testLevelTrace, "trace")
+	if buffer.String() != "[Audit] audit\n[Trace] trace\n" {
+		t.Errorf("custom level messages error: %q", buffer.String())
+	}
+
+	if logger.LoggerLevel("trace") != testLevelTrace || logger.LoggerLevel("AUDIT") != testLevelAudit {
+		t.Error("custom level by name error")
+	}
+	stats := logger.Stats()
+	if stats.Levels["Trace"] != 1 || stats.Levels["Audit"] != 1 {
+		t.Errorf("custom level stats error: %v", stats.Levels)
+	}
+	metrics := string(stats.prometheusText())
+	if !strings.Contains(metrics, `go_logger_messages_total{level="audit"} 1`) {
+		t.Errorf("custom level metrics error: %s", metrics)
+	}
+	if builtinLevel(testLevelAudit) != LOGGER_LEVEL_EMERGENCY || builtinLevel(testLevelTrace) != LOGGER_LEVEL_DEBUG {
+		t.Error("custom level builtin level error")
+	}
+
+	for _, register := range []func(){
+		func() { RegisterLevel(LOGGER_LEVEL_INFO, "Verbose", color.FgWhite) },
+		func() { RegisterLevel(20, "debug", color.FgWhite) },
+		func() { RegisterLevel(20, "", color.FgWhite) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("register illegal level error")
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+// runs of TestRegisterLevel_Concurrent, levels of every run are new
+var registerLevelRuns int
+
+func TestRegisterLevel_Concurrent(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", testLevelTrace, &MemoryConfig{})
+
+	registerLevelRuns++
+	first := 100 + registerLevelRuns*10
+	wait := sync.WaitGroup{}
+	wait.Add(1)
+	go func() {
+		defer wait.Done()
+		for i := 0; i < 5; i++ {
+			RegisterLevel(first+i, "Concurrent"+strconv.Itoa(first+i), color.FgWhite)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		logger.Writer(testLevelTrace, "trace")
+		logger.LoggerLevel("concurrent" + strconv.Itoa(first))
+	}
+	wait.Wait()
+
+	if logger.LoggerLevel("Concurrent"+strconv.Itoa(first+4)) != first+4 {
+		t.Error("concurrent registered level error")
+	}
+}
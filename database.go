@@ -0,0 +1,435 @@
+package go_logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DATABASE_ADAPTER_NAME = "database"
+
+// sql dialects of database adapter, placeholders and types of the table
+const (
+	DATABASE_DIALECT_POSTGRES = "postgres"
+	DATABASE_DIALECT_MYSQL    = "mysql"
+	DATABASE_DIALECT_SQLITE   = "sqlite"
+)
+
+const (
+	DATABASE_DEFAULT_TABLE              = "logs"
+	DATABASE_DEFAULT_BATCH_SIZE         = 100
+	DATABASE_DEFAULT_FLUSH_INTERVAL     = time.Second
+	DATABASE_DEFAULT_TIMEOUT            = 5 * time.Second
+	DATABASE_DEFAULT_MAX_RETRIES        = 2
+	DATABASE_DEFAULT_RETENTION_INTERVAL = time.Hour
+)
+
+// rows of one INSERT statement, sqlite limits variables of a statement to 999
+const databaseRowsPerInsert = 100
+
+// columns of the table, names of the json record
+var databaseColumns = []string{"millisecond", "millisecond_format", "level", "level_string", "body", "file", "line", "function", "fields"}
+
+var databaseTableRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// adapter database, records are inserted to a sql table by database/sql
+type AdapterDatabase struct {
+	encodingErrors int64 // first for 64-bit atomic alignment on 32-bit platforms
+	lock           sync.Mutex
+	migrateLock    sync.Mutex
+	config         *DatabaseConfig
+	db             *sql.DB
+	opened         bool // db is opened by the adapter
+	migrated       bool
+	buffer         []*loggerMessage
+	ticker         *time.Ticker
+	sweeper        *time.Ticker
+	quit           chan struct{}
+}
+
+// database config
+type DatabaseConfig struct {
+
+	// database opened by the application, or the adapter opens Driver with DSN and closes it on Close()
+	// drivers must be imported by the application, eg: _ "github.com/lib/pq"
+	DB     *sql.DB
+	Driver string
+	DSN    string
+
+	// DATABASE_DIALECT_POSTGRES, DATABASE_DIALECT_MYSQL or DATABASE_DIALECT_SQLITE
+	// default by Driver name, eg: "pgx" is postgres, "sqlite3" is sqlite
+	Dialect string
+
+	// table of records, default "logs", it's created with an index of millisecond if it's missing
+	Table string
+
+	// don't create the table, eg: the table is managed by migrations of the application
+	SkipMigration bool
+
+	// buffered messages are inserted by one transaction when BatchSize is reached or every FlushInterval
+	// default 100 and 1s
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// timeout of a transaction, default 5s
+	Timeout time.Duration
+
+	// insert is retried, default 2, -1 is no retry
+	MaxRetries int
+
+	// records older than Retention are deleted every RetentionInterval (default 1h), 0 keeps records
+	Retention         time.Duration
+	RetentionInterval time.Duration
+}
+
+func (dc *DatabaseConfig) Name() string {
+	return DATABASE_ADAPTER_NAME
+}
+
+func NewAdapterDatabase() LoggerAbstract {
+	return &AdapterDatabase{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterDatabase *AdapterDatabase) Init(databaseConfig Config) error {
+	dc, ok := databaseConfig.(*DatabaseConfig)
+	if !ok || dc == nil {
+		return configTypeError(DATABASE_ADAPTER_NAME, "DatabaseConfig")
+	}
+	adapterDatabase.config = dc
+
+	if dc.DB == nil && dc.Driver == "" {
+		return configError(DATABASE_ADAPTER_NAME, "Driver", "cannot be empty if DB is nil!")
+	}
+	if dc.Dialect == "" {
+		dc.Dialect = databaseDialectOf(dc.Driver)
+	}
+	if dc.Dialect != DATABASE_DIALECT_POSTGRES && dc.Dialect != DATABASE_DIALECT_MYSQL && dc.Dialect != DATABASE_DIALECT_SQLITE {
+		return configError(DATABASE_ADAPTER_NAME, "Dialect", "must be postgres, mysql or sqlite!")
+	}
+	if dc.Table == "" {
+		dc.Table = DATABASE_DEFAULT_TABLE
+	}
+	if !databaseTableRegexp.MatchString(dc.Table) {
+		return configError(DATABASE_ADAPTER_NAME, "Table", "must be a table name!")
+	}
+	if dc.BatchSize <= 0 {
+		dc.BatchSize = DATABASE_DEFAULT_BATCH_SIZE
+	}
+	if dc.FlushInterval <= 0 {
+		dc.FlushInterval = DATABASE_DEFAULT_FLUSH_INTERVAL
+	}
+	if dc.Timeout <= 0 {
+		dc.Timeout = DATABASE_DEFAULT_TIMEOUT
+	}
+	if dc.MaxRetries == 0 {
+		dc.MaxRetries = DATABASE_DEFAULT_MAX_RETRIES
+	}
+	if dc.RetentionInterval <= 0 {
+		dc.RetentionInterval = DATABASE_DEFAULT_RETENTION_INTERVAL
+	}
+
+	adapterDatabase.db = dc.DB
+	if adapterDatabase.db == nil {
+		// sql.Open doesn't connect, the table is created by the first insert
+		db, err := sql.Open(dc.Driver, dc.DSN)
+		if err != nil {
+			return err
+		}
+		adapterDatabase.db = db
+		adapterDatabase.opened = true
+	}
+
+	adapterDatabase.ticker = time.NewTicker(dc.FlushInterval)
+	adapterDatabase.quit = make(chan struct{})
+	var sweep <-chan time.Time
+	if dc.Retention > 0 {
+		adapterDatabase.sweeper = time.NewTicker(dc.RetentionInterval)
+		sweep = adapterDatabase.sweeper.C
+	}
+	go adapterDatabase.startFlush(adapterDatabase.ticker, sweep, adapterDatabase.quit)
+
+	return nil
+}
+
+func (adapterDatabase *AdapterDatabase) Write(loggerMsg *loggerMessage) error {
+	adapterDatabase.lock.Lock()
+	adapterDatabase.buffer = append(adapterDatabase.buffer, loggerMsg)
+	if len(adapterDatabase.buffer) < adapterDatabase.config.BatchSize {
+		adapterDatabase.lock.Unlock()
+		return nil
+	}
+	buffer := adapterDatabase.buffer
+	adapterDatabase.buffer = []*loggerMessage{}
+	adapterDatabase.lock.Unlock()
+
+	return adapterDatabase.send(buffer)
+}
+
+func (adapterDatabase *AdapterDatabase) Flush() {
+	adapterDatabase.lock.Lock()
+	buffer := adapterDatabase.buffer
+	adapterDatabase.buffer = []*loggerMessage{}
+	adapterDatabase.lock.Unlock()
+
+	err := adapterDatabase.send(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: database adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop tickers, insert buffered messages and close the database opened by the adapter
+func (adapterDatabase *AdapterDatabase) Close() error {
+	if adapterDatabase.ticker != nil {
+		adapterDatabase.ticker.Stop()
+		if adapterDatabase.sweeper != nil {
+			adapterDatabase.sweeper.Stop()
+		}
+		close(adapterDatabase.quit)
+		adapterDatabase.ticker = nil
+	}
+	adapterDatabase.Flush()
+
+	if adapterDatabase.opened {
+		return adapterDatabase.db.Close()
+	}
+	return nil
+}
+
+func (adapterDatabase *AdapterDatabase) Name() string {
+	return DATABASE_ADAPTER_NAME
+}
+
+func (adapterDatabase *AdapterDatabase) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// ping the database
+func (adapterDatabase *AdapterDatabase) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), adapterDatabase.config.Timeout)
+	defer cancel()
+	return adapterDatabase.db.PingContext(ctx)
+}
+
+func (adapterDatabase *AdapterDatabase) Counters() map[string]int64 {
+	return map[string]int64{
+		COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterDatabase.encodingErrors),
+	}
+}
+
+// delete records older than Retention, return the number of deleted records
+func (adapterDatabase *AdapterDatabase) Sweep() (int64, error) {
+	config := adapterDatabase.config
+	if config.Retention <= 0 {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	before := time.Now().Add(-config.Retention).UnixNano() / 1e6
+	query := "DELETE FROM " + config.Table + " WHERE millisecond < " + databasePlaceholder(config.Dialect, 1)
+	result, err := adapterDatabase.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// flush buffer every FlushInterval and sweep records every RetentionInterval
+func (adapterDatabase *AdapterDatabase) startFlush(ticker *time.Ticker, sweep <-chan time.Time, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterDatabase.Flush()
+		case <-sweep:
+			_, err := adapterDatabase.Sweep()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logger: database adapter sweep failed, error: %v\n", err)
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// insert messages by one transaction
+func (adapterDatabase *AdapterDatabase) send(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	queries := []string{}
+	args := [][]interface{}{}
+	for start := 0; start < len(loggerMsgs); start += databaseRowsPerInsert {
+		end := start + databaseRowsPerInsert
+		if end > len(loggerMsgs) {
+			end = len(loggerMsgs)
+		}
+		query, queryArgs := adapterDatabase.insertQuery(loggerMsgs[start:end])
+		queries = append(queries, query)
+		args = append(args, queryArgs)
+	}
+
+	err := adapterDatabase.insert(queries, args)
+	for retry := 0; err != nil && retry < adapterDatabase.config.MaxRetries; retry++ {
+		err = adapterDatabase.insert(queries, args)
+	}
+	return err
+}
+
+// exec INSERT statements by one transaction, the table is created first
+func (adapterDatabase *AdapterDatabase) insert(queries []string, args [][]interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), adapterDatabase.config.Timeout)
+	defer cancel()
+
+	err := adapterDatabase.migrate(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := adapterDatabase.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for i, query := range queries {
+		_, err = tx.ExecContext(ctx, query, args[i]...)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// multiple rows INSERT statement of messages
+func (adapterDatabase *AdapterDatabase) insertQuery(loggerMsgs []*loggerMessage) (string, []interface{}) {
+	config := adapterDatabase.config
+	query := &strings.Builder{}
+	query.WriteString("INSERT INTO " + config.Table + " (" + strings.Join(databaseColumns, ", ") + ") VALUES ")
+
+	args := make([]interface{}, 0, len(loggerMsgs)*len(databaseColumns))
+	for i, loggerMsg := range loggerMsgs {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for column := range databaseColumns {
+			if column > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString(databasePlaceholder(config.Dialect, len(args)+column+1))
+		}
+		query.WriteString(")")
+
+		var fields interface{}
+		if len(loggerMsg.Fields) > 0 {
+			fields = marshalDatabaseFields(loggerMsg.Fields, &adapterDatabase.encodingErrors)
+		}
+		args = append(args, loggerMsg.Millisecond, loggerMsg.MillisecondFormat, loggerMsg.Level, loggerMsg.LevelString,
+			loggerMsg.Body, loggerMsg.File, loggerMsg.Line, loggerMsg.Function, fields)
+	}
+	return query.String(), args
+}
+
+// create the table and the index of millisecond if they're missing, once
+func (adapterDatabase *AdapterDatabase) migrate(ctx context.Context) error {
+	adapterDatabase.migrateLock.Lock()
+	defer adapterDatabase.migrateLock.Unlock()
+
+	if adapterDatabase.migrated || adapterDatabase.config.SkipMigration {
+		return nil
+	}
+	for _, statement := range databaseSchema(adapterDatabase.config.Dialect, adapterDatabase.config.Table) {
+		_, err := adapterDatabase.db.ExecContext(ctx, statement)
+		if err != nil {
+			return errors.New("create table " + adapterDatabase.config.Table + " error: " + err.Error())
+		}
+	}
+	adapterDatabase.migrated = true
+	return nil
+}
+
+// statements creating the table of the dialect
+func databaseSchema(dialect string, table string) []string {
+	index := strings.Replace(table, ".", "_", -1) + "_millisecond"
+	switch dialect {
+	case DATABASE_DIALECT_POSTGRES:
+		return []string{
+			"CREATE TABLE IF NOT EXISTS " + table + " (id BIGSERIAL PRIMARY KEY, millisecond BIGINT NOT NULL, millisecond_format VARCHAR(32) NOT NULL, " +
+				"level SMALLINT NOT NULL, level_string VARCHAR(32) NOT NULL, body TEXT NOT NULL, file TEXT NOT NULL, line INTEGER NOT NULL, function TEXT NOT NULL, fields TEXT)",
+			"CREATE INDEX IF NOT EXISTS " + index + " ON " + table + " (millisecond)",
+		}
+	case DATABASE_DIALECT_MYSQL:
+		// mysql has no CREATE INDEX IF NOT EXISTS, the index is created with the table
+		return []string{
+			"CREATE TABLE IF NOT EXISTS " + table + " (id BIGINT AUTO_INCREMENT PRIMARY KEY, millisecond BIGINT NOT NULL, millisecond_format VARCHAR(32) NOT NULL, " +
+				"level SMALLINT NOT NULL, level_string VARCHAR(32) NOT NULL, body TEXT NOT NULL, file VARCHAR(1024) NOT NULL, line INT NOT NULL, `function` VARCHAR(1024) NOT NULL, fields TEXT, " +
+				"INDEX " + index + " (millisecond))",
+		}
+	default:
+		return []string{
+			"CREATE TABLE IF NOT EXISTS " + table + " (id INTEGER PRIMARY KEY AUTOINCREMENT, millisecond INTEGER NOT NULL, millisecond_format TEXT NOT NULL, " +
+				"level INTEGER NOT NULL, level_string TEXT NOT NULL, body TEXT NOT NULL, file TEXT NOT NULL, line INTEGER NOT NULL, function TEXT NOT NULL, fields TEXT)",
+			"CREATE INDEX IF NOT EXISTS " + index + " ON " + table + " (millisecond)",
+		}
+	}
+}
+
+// placeholder of the nth argument, "$n" of postgres and "?" of others
+func databasePlaceholder(dialect string, n int) string {
+	if dialect == DATABASE_DIALECT_POSTGRES {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// dialect of the driver name, eg: "postgres", "pgx", "mysql", "sqlite3"
+func databaseDialectOf(driver string) string {
+	driver = strings.ToLower(driver)
+	switch {
+	case strings.Contains(driver, "postgres") || strings.HasPrefix(driver, "pgx") || driver == "pq":
+		return DATABASE_DIALECT_POSTGRES
+	case strings.Contains(driver, "mysql"):
+		return DATABASE_DIALECT_MYSQL
+	case strings.Contains(driver, "sqlite"):
+		return DATABASE_DIALECT_SQLITE
+	}
+	return ""
+}
+
+// json object of fields, fields which cannot be marshaled are replaced like marshalLoggerMessage()
+func marshalDatabaseFields(fields map[string]interface{}, fallbacks *int64) string {
+	jsonByte, err := json.Marshal(fields)
+	if err == nil {
+		return string(jsonByte)
+	}
+	atomic.AddInt64(fallbacks, 1)
+
+	safeFields := make(map[string]interface{}, len(fields)+1)
+	for key, value := range fields {
+		if _, fieldErr := json.Marshal(value); fieldErr != nil {
+			value = fmt.Sprintf("%+v", value)
+		}
+		safeFields[key] = value
+	}
+	safeFields[LOGGER_FIELD_ENCODING_ERROR] = err.Error()
+	jsonByte, _ = json.Marshal(safeFields)
+	return string(jsonByte)
+}
+
+func init() {
+	Register(DATABASE_ADAPTER_NAME, NewAdapterDatabase)
+	RegisterConfig(DATABASE_ADAPTER_NAME, func() Config {
+		return &DatabaseConfig{}
+	})
+}
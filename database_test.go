@@ -0,0 +1,181 @@
+package go_logger
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fake driver recording statements of committed transactions
+type fakeDatabaseDriver struct {
+	lock       sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	failures   int // inserts fail
+}
+
+type fakeDatabaseConn struct {
+	database *fakeDatabaseDriver
+	tx       []string
+	txArgs   [][]driver.Value
+	inTx     bool
+}
+
+type fakeDatabaseStmt struct {
+	conn  *fakeDatabaseConn
+	query string
+}
+
+func (fd *fakeDatabaseDriver) Open(name string) (driver.Conn, error) {
+	return &fakeDatabaseConn{database: fd}, nil
+}
+
+func (fc *fakeDatabaseConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeDatabaseStmt{conn: fc, query: query}, nil
+}
+
+func (fc *fakeDatabaseConn) Close() error {
+	return nil
+}
+
+func (fc *fakeDatabaseConn) Begin() (driver.Tx, error) {
+	fc.inTx = true
+	return fc, nil
+}
+
+func (fc *fakeDatabaseConn) Commit() error {
+	fc.database.lock.Lock()
+	defer fc.database.lock.Unlock()
+	fc.database.statements = append(fc.database.statements, fc.tx...)
+	fc.database.args = append(fc.database.args, fc.txArgs...)
+	fc.tx, fc.txArgs, fc.inTx = nil, nil, false
+	return nil
+}
+
+func (fc *fakeDatabaseConn) Rollback() error {
+	fc.tx, fc.txArgs, fc.inTx = nil, nil, false
+	return nil
+}
+
+func (fs *fakeDatabaseStmt) Close() error {
+	return nil
+}
+
+func (fs *fakeDatabaseStmt) NumInput() int {
+	return -1
+}
+
+func (fs *fakeDatabaseStmt) Exec(args []driver.Value) (driver.Result, error) {
+	database := fs.conn.database
+	database.lock.Lock()
+	defer database.lock.Unlock()
+	if strings.HasPrefix(fs.query, "INSERT") && database.failures > 0 {
+		database.failures--
+		return nil, errors.New("connection reset")
+	}
+	if !fs.conn.inTx {
+		database.statements = append(database.statements, fs.query)
+		database.args = append(database.args, args)
+		return driver.RowsAffected(3), nil
+	}
+	fs.conn.tx = append(fs.conn.tx, fs.query)
+	fs.conn.txArgs = append(fs.conn.txArgs, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (fs *fakeDatabaseStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+var fakeDatabase = &fakeDriverSwitch{}
+
+func init() {
+	sql.Register("fake-database", fakeDatabase)
+}
+
+func TestAdapterDatabase_Write(t *testing.T) {
+
+	fakeDriver := &fakeDatabaseDriver{failures: 1}
+	fakeDatabase.driver = fakeDriver
+
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("database", LOGGER_LEVEL_DEBUG, &DatabaseConfig{
+		Driver:        "fake-database",
+		Dialect:       DATABASE_DIALECT_POSTGRES,
+		Table:         "app_logs",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Retention:     24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WriterFields(LOGGER_LEVEL_INFO, "one", map[string]interface{}{"user": "go", "bad": func() {}})
+	logger.Error("two")
+	logger.Warning("three")
+	logger.Flush()
+
+	fakeDriver.lock.Lock()
+	statements := fakeDriver.statements
+	args := fakeDriver.args
+	fakeDriver.lock.Unlock()
+	if len(statements) != 4 || !strings.HasPrefix(statements[0], "CREATE TABLE IF NOT EXISTS app_logs (id BIGSERIAL") ||
+		statements[1] != "CREATE INDEX IF NOT EXISTS app_logs_millisecond ON app_logs (millisecond)" {
+		t.Fatalf("database statements error: %q", statements)
+	}
+	insert := "INSERT INTO app_logs (millisecond, millisecond_format, level, level_string, body, file, line, function, fields) VALUES " +
+		"($1, $2, $3, $4, $5, $6, $7, $8, $9), ($10, $11, $12, $13, $14, $15, $16, $17, $18)"
+	if statements[2] != insert || !strings.HasSuffix(statements[3], "($1, $2, $3, $4, $5, $6, $7, $8, $9)") {
+		t.Errorf("database insert error: %q", statements[2:])
+	}
+	if args[2][4] != "one" || args[2][13] != "two" || args[3][4] != "three" || args[2][3] != "Info" || args[2][17] != nil {
+		t.Errorf("database insert args error: %v", args[2:])
+	}
+	if fields, _ := args[2][8].(string); !strings.Contains(fields, `"user":"go"`) || !strings.Contains(fields, `"encoding_error"`) {
+		t.Errorf("database fields error: %v", args[2][8])
+	}
+
+	adapter := logger.Adapter("database").(*AdapterDatabase)
+	if adapter.Counters()[COUNTER_ENCODING_ERRORS] != 1 {
+		t.Errorf("database encoding errors error: %v", adapter.Counters())
+	}
+	deleted, err := adapter.Sweep()
+	if err != nil || deleted != 3 {
+		t.Errorf("database sweep error: %d, %v", deleted, err)
+	}
+	fakeDriver.lock.Lock()
+	sweep := fakeDriver.statements[len(fakeDriver.statements)-1]
+	before := fakeDriver.args[len(fakeDriver.args)-1][0].(int64)
+	fakeDriver.lock.Unlock()
+	if sweep != "DELETE FROM app_logs WHERE millisecond < $1" || before > time.Now().Add(-23*time.Hour).UnixNano()/1e6 {
+		t.Errorf("database sweep statement error: %s, %d", sweep, before)
+	}
+	logger.Detach("database")
+}
+
+func TestAdapterDatabase_Init(t *testing.T) {
+
+	configs := map[string]*DatabaseConfig{
+		"empty":   {},
+		"dialect": {Driver: "unknown"},
+		"table":   {Driver: "sqlite3", Table: "logs; DROP TABLE users"},
+	}
+	for name, config := range configs {
+		if NewAdapterDatabase().Init(config) == nil {
+			t.Errorf("database config %s must be error", name)
+		}
+	}
+	if databaseDialectOf("pgx") != DATABASE_DIALECT_POSTGRES || databaseDialectOf("mysql") != DATABASE_DIALECT_MYSQL ||
+		databaseDialectOf("sqlite3") != DATABASE_DIALECT_SQLITE {
+		t.Error("database dialect of driver error")
+	}
+	if strings.Contains(databaseSchema(DATABASE_DIALECT_MYSQL, "logs")[0], "IF NOT EXISTS logs_millisecond") ||
+		databasePlaceholder(DATABASE_DIALECT_MYSQL, 3) != "?" {
+		t.Error("database mysql dialect error")
+	}
+}
@@ -0,0 +1,199 @@
+package go_logger
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// field of repeat summaries, the number of collapsed messages
+const LOGGER_FIELD_REPEATED = "repeated"
+
+const DEDUP_DEFAULT_WINDOW = 30 * time.Second
+
+// dedup config of an adapter
+type DedupConfig struct {
+
+	// identical consecutive messages are collapsed until Window after the first repeat, default 30s
+	// then "last message repeated N times" is written and the next identical message is written again
+	Window time.Duration
+
+	// messages are identical by level and body, fields are not compared, eg: fields of request ids
+	IgnoreFields bool
+}
+
+// collapse repeats of the last written message of an output
+type deduper struct {
+	config DedupConfig
+	emit   func(summary *loggerMessage)
+
+	lock       sync.Mutex
+	last       *loggerMessage // last written message
+	latest     *loggerMessage // last collapsed repeat
+	repeated   int
+	timer      *time.Timer
+	generation int // run of repeats, expired timers of old runs are ignored
+}
+
+// set dedup of the adapter, identical consecutive messages are written once and
+// followed by a summary "last message repeated N times" with field "repeated", nil config disables dedup
+// dedup applies after filters and adapter sampler, summaries are written when a different message arrives,
+// the window ends or the logger is flushed
+//
+// example:
+//	logger.SetAdapterDedup("file", &go_logger.DedupConfig{Window: 10 * time.Second})
+func (logger *Logger) SetAdapterDedup(adapterName string, config *DedupConfig) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name != adapterName {
+			continue
+		}
+		output.writeDedup(output.flushDedup())
+		if config == nil {
+			output.dedup.Store((*deduper)(nil))
+			return nil
+		}
+		dedup := &deduper{config: *config}
+		if dedup.config.Window <= 0 {
+			dedup.config.Window = DEDUP_DEFAULT_WINDOW
+		}
+		dedup.emit = func(summary *loggerMessage) {
+			logger.writeDedupSummary(output, summary)
+		}
+		output.dedup.Store(dedup)
+		return nil
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// write the summary of an expired window, skipped if the logger is closed
+func (logger *Logger) writeDedupSummary(output *outputLogger, summary *loggerMessage) {
+	logger.lock.Lock()
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		logger.lock.Unlock()
+		return
+	}
+	queue := output.queue
+	logger.lock.Unlock()
+
+	if queue != nil {
+		queue.push(summary)
+		return
+	}
+	output.writeDedup(summary)
+}
+
+// write the summary by the queue of async loggers or the adapter, nil summary is ignored
+func (output *outputLogger) writeDedup(summary *loggerMessage) {
+	if summary == nil {
+		return
+	}
+	if output.queue != nil {
+		output.queue.push(summary)
+		return
+	}
+	err := output.send(summary)
+	if err != nil {
+		output.writeError(summary, err)
+	}
+}
+
+// output dedup of the message, return whether the message is written and the summary of collapsed repeats written before it
+func (output *outputLogger) deduplicate(loggerMsg *loggerMessage) (bool, *loggerMessage) {
+	dedup, ok := output.dedup.Load().(*deduper)
+	if !ok || dedup == nil {
+		return true, nil
+	}
+	return dedup.check(loggerMsg)
+}
+
+// pending summary of output dedup, the logger is flushed or the output is closed
+func (output *outputLogger) flushDedup() *loggerMessage {
+	dedup, ok := output.dedup.Load().(*deduper)
+	if !ok || dedup == nil {
+		return nil
+	}
+	dedup.lock.Lock()
+	defer dedup.lock.Unlock()
+	return dedup.summary()
+}
+
+func (dedup *deduper) check(loggerMsg *loggerMessage) (bool, *loggerMessage) {
+	dedup.lock.Lock()
+	defer dedup.lock.Unlock()
+
+	if dedup.last != nil && dedup.identical(dedup.last, loggerMsg) {
+		if dedup.repeated == 0 {
+			generation := dedup.generation
+			dedup.timer = time.AfterFunc(dedup.config.Window, func() {
+				dedup.expire(generation)
+			})
+		}
+		dedup.repeated++
+		dedup.latest = loggerMsg
+		return false, nil
+	}
+	summary := dedup.summary()
+	dedup.last = loggerMsg
+	return true, summary
+}
+
+// window of the run ends, the next identical message is written again
+func (dedup *deduper) expire(generation int) {
+	dedup.lock.Lock()
+	if generation != dedup.generation {
+		dedup.lock.Unlock()
+		return
+	}
+	summary := dedup.summary()
+	dedup.last = nil
+	dedup.lock.Unlock()
+
+	if summary != nil {
+		dedup.emit(summary)
+	}
+}
+
+// summary of collapsed repeats and reset the run, nil if there is no repeat
+// the summary is the last repeat with body "last message repeated N times"
+func (dedup *deduper) summary() *loggerMessage {
+	if dedup.repeated == 0 {
+		return nil
+	}
+	if dedup.timer != nil {
+		dedup.timer.Stop()
+		dedup.timer = nil
+	}
+	summary := *dedup.latest
+	summary.wal = nil
+	summary.Fields = make(map[string]interface{}, len(dedup.latest.Fields)+1)
+	for key, value := range dedup.latest.Fields {
+		summary.Fields[key] = value
+	}
+	summary.Fields[LOGGER_FIELD_REPEATED] = dedup.repeated
+	summary.Body = "last message repeated " + strconv.Itoa(dedup.repeated) + " times"
+	if dedup.repeated == 1 {
+		summary.Body = "last message repeated 1 time"
+	}
+
+	dedup.repeated = 0
+	dedup.latest = nil
+	dedup.generation++
+	return &summary
+}
+
+// messages are identical by level, body and fields
+func (dedup *deduper) identical(last *loggerMessage, loggerMsg *loggerMessage) bool {
+	if last.Level != loggerMsg.Level || last.Body != loggerMsg.Body {
+		return false
+	}
+	if dedup.config.IgnoreFields || (len(last.Fields) == 0 && len(loggerMsg.Fields) == 0) {
+		return true
+	}
+	return reflect.DeepEqual(last.Fields, loggerMsg.Fields)
+}
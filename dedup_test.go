@@ -0,0 +1,109 @@
+package go_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogger_SetAdapterDedup(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.AttachAs("raw", "memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	err := logger.SetAdapterDedup("memory", &DedupConfig{Window: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.SetAdapterDedup("file", &DedupConfig{}); err == nil {
+		t.Error("dedup of not attached adapter must be error")
+	}
+
+	for i := 0; i < 5; i++ {
+		logger.Error("connect failed")
+	}
+	logger.Error("connect succeeded")
+	logger.Error("connect succeeded")
+	logger.Info("connect succeeded")
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	bodies := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		bodies = append(bodies, entry.Body)
+	}
+	if len(bodies) != 5 || bodies[0] != "connect failed" || bodies[1] != "last message repeated 4 times" ||
+		bodies[2] != "connect succeeded" || bodies[3] != "last message repeated 1 time" || bodies[4] != "connect succeeded" {
+		t.Fatalf("dedup bodies error: %v", bodies)
+	}
+	if entries[1].Fields[LOGGER_FIELD_REPEATED] != 4 || entries[1].Level != LOGGER_LEVEL_ERROR {
+		t.Errorf("dedup summary error: %+v", entries[1])
+	}
+	if raw := logger.Adapter("raw").(*AdapterMemory).Entries(); len(raw) != 8 {
+		t.Errorf("adapter without dedup error: %d", len(raw))
+	}
+
+	// pending repeats are written by flush
+	logger.Info("connect succeeded")
+	logger.Flush()
+	entries = logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 6 || entries[5].Body != "last message repeated 1 time" || entries[5].Level != LOGGER_LEVEL_INFO {
+		t.Errorf("dedup flush error: %v", entries[len(entries)-1].Body)
+	}
+}
+
+func TestLogger_SetAdapterDedupFields(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetAdapterDedup("memory", &DedupConfig{})
+
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "retry", map[string]interface{}{"attempt": 1})
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "retry", map[string]interface{}{"attempt": 2})
+	if entries := logger.Adapter("memory").(*AdapterMemory).Entries(); len(entries) != 2 {
+		t.Errorf("dedup must compare fields: %d", len(entries))
+	}
+
+	logger.SetAdapterDedup("memory", &DedupConfig{IgnoreFields: true})
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "retry", map[string]interface{}{"attempt": 3})
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "retry", map[string]interface{}{"attempt": 4})
+	logger.SetAdapterDedup("memory", nil)
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "retry", map[string]interface{}{"attempt": 5})
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 5 || entries[3].Body != "last message repeated 1 time" || entries[3].Fields["attempt"] != 4 {
+		t.Fatalf("dedup ignore fields error: %+v", entries)
+	}
+	if entries[4].Fields["attempt"] != 5 {
+		t.Errorf("disabled dedup error: %+v", entries[4])
+	}
+}
+
+func TestLogger_SetAdapterDedupWindow(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetAsync(10)
+	logger.SetAdapterDedup("memory", &DedupConfig{Window: 20 * time.Millisecond})
+
+	logger.Error("disk full")
+	logger.Error("disk full")
+	logger.Error("disk full")
+	memory := logger.Adapter("memory").(*AdapterMemory)
+	deadline := time.Now().Add(time.Second)
+	for len(memory.Entries()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.Error("disk full")
+	logger.Flush()
+
+	entries := memory.Entries()
+	bodies := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		bodies = append(bodies, entry.Body)
+	}
+	if len(bodies) != 3 || bodies[1] != "last message repeated 2 times" || bodies[2] != "disk full" {
+		t.Errorf("dedup window error: %v", bodies)
+	}
+}
@@ -0,0 +1,62 @@
+package go_logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// env of the deploy tag, eg: LOGGER_DEPLOY_TAG=canary
+const DEPLOY_TAG_ENV = "LOGGER_DEPLOY_TAG"
+
+// field name of the deploy tag
+const LOGGER_FIELD_DEPLOY = "deploy"
+
+var deployTag atomic.Value
+
+func init() {
+	deployTag.Store(os.Getenv(DEPLOY_TAG_ENV))
+}
+
+// set deploy tag (blue, green, canary ...), default is env LOGGER_DEPLOY_TAG
+// messages get field "deploy", set it before attach adapters with DeploySuffix
+func SetDeployTag(tag string) {
+	deployTag.Store(tag)
+}
+
+// deploy tag
+func DeployTag() string {
+	return deployTag.Load().(string)
+}
+
+// add deploy tag field, the message fields are copied
+func withDeployTag(loggerMsg *loggerMessage) {
+	tag := DeployTag()
+	if tag == "" {
+		return
+	}
+	if _, ok := loggerMsg.Fields[LOGGER_FIELD_DEPLOY]; ok {
+		return
+	}
+	fields := make(map[string]interface{}, len(loggerMsg.Fields)+1)
+	for key, value := range loggerMsg.Fields {
+		fields[key] = value
+	}
+	fields[LOGGER_FIELD_DEPLOY] = tag
+	loggerMsg.Fields = fields
+}
+
+// filename suffixed with deploy tag, eg: "app.log" is "app.canary.log"
+func deploySuffixFilename(filename string) string {
+	return suffixFilename(filename, DeployTag())
+}
+
+// filename with suffix before the extension
+func suffixFilename(filename string, suffix string) string {
+	if suffix == "" {
+		return filename
+	}
+	dir, name := filepath.Split(filename)
+	name, ext := splitFilenameExt(name)
+	return dir + name + "." + suffix + ext
+}
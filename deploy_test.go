@@ -0,0 +1,53 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestSetDeployTag(t *testing.T) {
+
+	SetDeployTag("canary")
+	defer SetDeployTag("")
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	err = logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+		Filename:     path.Join(dir, "app.log"),
+		DeploySuffix: true,
+		Format:       "%body% %deploy%",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	fields := map[string]interface{}{"user": "go"}
+	logger.WriterFields(LOGGER_LEVEL_INFO, "deploy", fields)
+	logger.Flush()
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 1 || entries[0].Fields[LOGGER_FIELD_DEPLOY] != "canary" || entries[0].Fields["user"] != "go" {
+		t.Errorf("deploy tag field error: %v", entries)
+	}
+	if _, ok := fields[LOGGER_FIELD_DEPLOY]; ok {
+		t.Error("deploy tag must not modify the fields of the caller")
+	}
+
+	content, err := ioutil.ReadFile(path.Join(dir, "app.canary.log"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.TrimSpace(string(content)) != "deploy canary" {
+		t.Errorf("deploy tag file content error: %q", content)
+	}
+}
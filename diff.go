@@ -0,0 +1,152 @@
+package go_logger
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// field of diff messages, changed paths with "before" and "after" values
+const LOGGER_FIELD_DIFF = "diff"
+
+// field of diff messages, name of the entity
+const LOGGER_FIELD_ENTITY = "entity"
+
+// redactor of diffs if the logger has no redactor
+var diffRedactor = NewRedactor(nil)
+
+// log the field level diff of before and after at info level, eg: "user changed: email, role"
+// structs (exported fields, by json tag) and maps are compared by dotted paths, eg: "address.city"
+// values of secret fields are masked by the redactor of the logger, or DefaultRedactFields
+// nothing is logged if before and after are equal
+func (logger *Logger) Diff(name string, before, after interface{}) {
+	msg, fields := logger.diffMessage(name, before, after)
+	if fields == nil {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_INFO, msg, fields)
+}
+
+// log the field level diff of before and after at info level with bound fields
+func (child *ChildLogger) Diff(name string, before, after interface{}) {
+	msg, fields := child.logger.diffMessage(name, before, after)
+	if fields == nil {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_INFO, child.prefix+msg, child.messageFields(fields))
+}
+
+// message and fields of the diff, nil fields if there are no changes
+func (logger *Logger) diffMessage(name string, before, after interface{}) (string, map[string]interface{}) {
+	beforeValues := map[string]interface{}{}
+	afterValues := map[string]interface{}{}
+	flattenDiffValue("", reflect.ValueOf(before), beforeValues)
+	flattenDiffValue("", reflect.ValueOf(after), afterValues)
+
+	redactor := diffRedactor
+	if loggerRedactor, ok := logger.redactor.Load().(**Redactor); ok && *loggerRedactor != nil {
+		redactor = *loggerRedactor
+	}
+
+	paths := []string{}
+	for path := range beforeValues {
+		paths = append(paths, path)
+	}
+	for path := range afterValues {
+		if _, ok := beforeValues[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	diff := map[string]interface{}{}
+	changed := []string{}
+	for _, path := range paths {
+		beforeValue, afterValue := beforeValues[path], afterValues[path]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		changed = append(changed, path)
+		// secret fields are masked by the last name of the path
+		if redactor.fields[strings.ToLower(path[strings.LastIndex(path, ".")+1:])] {
+			beforeValue, afterValue = redactor.mask, redactor.mask
+		}
+		diff[path] = map[string]interface{}{"before": beforeValue, "after": afterValue}
+	}
+	if len(changed) == 0 {
+		return "", nil
+	}
+
+	msg := name + " changed: " + strings.Join(changed, ", ")
+	return msg, map[string]interface{}{
+		LOGGER_FIELD_ENTITY: name,
+		LOGGER_FIELD_DIFF:   diff,
+	}
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// values of v by dotted paths, structs and maps are flattened, others are values
+func flattenDiffValue(path string, v reflect.Value, values map[string]interface{}) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			break
+		}
+		// time.Time and other text values are compared as values
+		if v.Type().Implements(textMarshalerType) || v.Type().Implements(stringerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		if path != "" {
+			values[path] = nil
+		}
+		return
+	}
+	if v.Type().Implements(textMarshalerType) || v.Type().Implements(stringerType) {
+		if path != "" {
+			values[path] = v.Interface()
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := field.Name
+			if tag := field.Tag.Get("json"); tag != "" {
+				tagName := strings.Split(tag, ",")[0]
+				if tagName == "-" {
+					continue
+				}
+				if tagName != "" {
+					name = tagName
+				}
+			}
+			flattenDiffValue(diffPath(path, name), v.Field(i), values)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			flattenDiffValue(diffPath(path, fmt.Sprint(key.Interface())), v.MapIndex(key), values)
+		}
+	default:
+		if path != "" {
+			values[path] = v.Interface()
+		}
+	}
+}
+
+func diffPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
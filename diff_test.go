@@ -0,0 +1,55 @@
+package go_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogger_Diff(t *testing.T) {
+
+	type address struct {
+		City string `json:"city"`
+	}
+	type user struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+		Address  *address
+		Created  time.Time `json:"created"`
+		Tags     []string  `json:"tags"`
+		internal int
+	}
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	before := user{Name: "alice", Password: "old", Address: &address{City: "Paris"}, Created: created, Tags: []string{"a"}, internal: 1}
+	after := user{Name: "alice", Password: "new", Address: &address{City: "Berlin"}, Created: created, Tags: []string{"a", "b"}, internal: 2}
+	logger.Diff("user", before, after)
+	logger.Diff("user", before, before)
+	logger.With(map[string]interface{}{"request_id": "r1"}).Diff("settings", map[string]interface{}{"theme": "dark"}, map[string]interface{}{"theme": "light", "lang": "en"})
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("equal values must not be logged: %d messages", len(entries))
+	}
+	if entries[0].Body != "user changed: Address.city, password, tags" || entries[0].Fields[LOGGER_FIELD_ENTITY] != "user" {
+		t.Errorf("diff message error: %+v", entries[0])
+	}
+	diff := entries[0].Fields[LOGGER_FIELD_DIFF].(map[string]interface{})
+	if city := diff["Address.city"].(map[string]interface{}); city["before"] != "Paris" || city["after"] != "Berlin" {
+		t.Errorf("diff of nested field error: %v", city)
+	}
+	if password := diff["password"].(map[string]interface{}); password["before"] != REDACT_DEFAULT_MASK || password["after"] != REDACT_DEFAULT_MASK {
+		t.Errorf("diff of secret field must be masked: %v", password)
+	}
+
+	if entries[1].Body != "settings changed: lang, theme" || entries[1].Fields["request_id"] != "r1" {
+		t.Errorf("diff of maps error: %+v", entries[1])
+	}
+	if lang := entries[1].Fields[LOGGER_FIELD_DIFF].(map[string]interface{})["lang"].(map[string]interface{}); lang["before"] != nil || lang["after"] != "en" {
+		t.Errorf("diff of added key error: %v", lang)
+	}
+}
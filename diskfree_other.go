@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package go_logger
+
+import "errors"
+
+// free space is not supported
+func diskFree(dirPath string) (int64, error) {
+	return 0, errors.New("disk free space is not supported")
+}
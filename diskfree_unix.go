@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package go_logger
+
+import "syscall"
+
+// free space (byte) of the volume of the path, available to unprivileged users
+func diskFree(dirPath string) (int64, error) {
+	stat := &syscall.Statfs_t{}
+	err := syscall.Statfs(dirPath, stat)
+	if err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
@@ -0,0 +1,427 @@
+package go_logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const DISK_WATCH_DEFAULT_INTERVAL = 10 * time.Second
+
+// disk states of file adapters, from normal to stop
+const (
+	DISK_STATE_NORMAL = iota
+	DISK_STATE_COMPRESS
+	DISK_STATE_DROP
+	DISK_STATE_STOP
+)
+
+var diskStateStringMapping = map[int]string{
+	DISK_STATE_NORMAL:   "normal",
+	DISK_STATE_COMPRESS: "compress",
+	DISK_STATE_DROP:     "drop",
+	DISK_STATE_STOP:     "stop",
+}
+
+// free space (byte) of the volume of the path, replaced by tests
+var diskFreeSpace = diskFree
+
+// writer of messages diverted from stopped file adapters, replaced by tests
+var diskDivertWriter io.Writer = os.Stderr
+
+// disk watch config, thresholds are free space of the log volume (KB, same unit as FileConfig.MaxSize)
+// 0 threshold is disabled
+type DiskWatchConfig struct {
+
+	// check free space every interval, default 10s
+	Interval time.Duration
+
+	// gzip backup files below it
+	CompressBelow int64
+
+	// drop Info and Debug messages of file adapters below it
+	DropBelow int64
+
+	// stop writing file adapters below it, other adapters (console, remote) are still written
+	StopBelow int64
+
+	// rotate the files and remove backups below it, oldest first, until free space is above it
+	// backups are removed even if they're not uploaded or TrashDir is set
+	PurgeBelow int64
+
+	// messages less severe than DropLevel are dropped below DropBelow, default LOGGER_LEVEL_NOTICE drops Info and Debug
+	DropLevel int
+
+	// messages of stopped file adapters are written to stderr by their Format
+	Divert bool
+
+	// called on every state transition and purge of a file adapter
+	OnEvent func(event DiskEvent)
+}
+
+// disk event of a file adapter
+type DiskEvent struct {
+
+	// attached name of the file adapter
+	Adapter string
+
+	// DISK_STATE_* before and after the event, equal if backups are purged
+	OldState int
+	State    int
+
+	// free space (KB) of the volume
+	Free int64
+
+	// backup files removed by the purge
+	Purged []string
+}
+
+// disk state of the free space
+func (config *DiskWatchConfig) state(free int64) int {
+	if config.StopBelow > 0 && free < config.StopBelow {
+		return DISK_STATE_STOP
+	}
+	if config.DropBelow > 0 && free < config.DropBelow {
+		return DISK_STATE_DROP
+	}
+	if config.CompressBelow > 0 && free < config.CompressBelow {
+		return DISK_STATE_COMPRESS
+	}
+	return DISK_STATE_NORMAL
+}
+
+// watch free space of file adapters volumes, degrade them below thresholds of config
+// every state transition is logged as a warning (notice when recovered) with fields "adapter", "disk_state" and "disk_free"
+// below PurgeBelow the files are rotated and backups are removed, stopped adapters write to stderr if Divert
+// return func stops the watchdog
+//
+// example:
+//	stop := logger.WatchDisk(&go_logger.DiskWatchConfig{
+//		CompressBelow: 10 * 1024 * 1024,
+//		DropBelow:     1024 * 1024,
+//		StopBelow:     100 * 1024,
+//	})
+//	defer stop()
+func (logger *Logger) WatchDisk(config *DiskWatchConfig) (stop func()) {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = DISK_WATCH_DEFAULT_INTERVAL
+	}
+	quit := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		logger.checkDisk(config)
+		for {
+			select {
+			case <-ticker.C:
+				logger.checkDisk(config)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+	}
+}
+
+// check free space of file adapters and apply disk states
+func (logger *Logger) checkDisk(config *DiskWatchConfig) {
+	logger.lock.Lock()
+	outputs := map[string]*AdapterFile{}
+	for _, output := range logger.outputs {
+		adapterFile, ok := output.LoggerAbstract.(*AdapterFile)
+		if ok {
+			outputs[output.Name] = adapterFile
+		}
+	}
+	logger.lock.Unlock()
+
+	for name, adapterFile := range outputs {
+		dirPath := adapterFile.dirPath()
+		if dirPath == "" {
+			continue
+		}
+		free, err := diskFreeSpace(dirPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: disk watch %s failed, error: %v\n", dirPath, err)
+			continue
+		}
+		free = free / 1024
+
+		state := config.state(free)
+		adapterFile.diskWatch.Store(config)
+		oldState := adapterFile.setDiskState(state)
+		if state >= DISK_STATE_COMPRESS {
+			err = adapterFile.compressBackups()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logger: disk watch compress backups failed, error: %v\n", err)
+			}
+		}
+		if config.PurgeBelow > 0 && free < config.PurgeBelow {
+			logger.purgeDisk(config, name, adapterFile, dirPath, state)
+		} else {
+			atomic.StoreInt32(&adapterFile.diskPurge, 0)
+		}
+		if state == oldState {
+			continue
+		}
+		level := LOGGER_LEVEL_WARNING
+		if state < oldState {
+			level = LOGGER_LEVEL_NOTICE
+		}
+		logger.WriterFields(level, "logger: disk state of adapter "+name+" is "+diskStateStringMapping[state], map[string]interface{}{
+			"adapter":    name,
+			"disk_state": diskStateStringMapping[state],
+			"disk_free":  free,
+		})
+		if config.OnEvent != nil {
+			config.OnEvent(DiskEvent{Adapter: name, OldState: oldState, State: state, Free: free})
+		}
+	}
+}
+
+// rotate the files when the free space falls below PurgeBelow, then remove backups oldest first until it's above
+// the purge is logged as a warning with fields "adapter", "disk_free" and "disk_purged"
+func (logger *Logger) purgeDisk(config *DiskWatchConfig, name string, adapterFile *AdapterFile, dirPath string, state int) {
+	if atomic.SwapInt32(&adapterFile.diskPurge, 1) == 0 {
+		err := adapterFile.RotateNow()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: disk watch rotate failed, error: %v\n", err)
+		}
+	}
+	backups, err := adapterFile.backupFilesOldest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: disk watch purge backups failed, error: %v\n", err)
+	}
+
+	fs := OsFileSystem
+	if adapterFile.config.FileSystem != nil {
+		fs = adapterFile.config.FileSystem
+	}
+	purged := []string{}
+	free := int64(0)
+	for _, backup := range backups {
+		free, err = diskFreeSpace(dirPath)
+		if err != nil || free/1024 >= config.PurgeBelow {
+			break
+		}
+		err = fs.Remove(backup)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "logger: disk watch purge backups failed, error: %v\n", err)
+			continue
+		}
+		purged = append(purged, backup)
+	}
+	if len(purged) == 0 {
+		return
+	}
+	free, _ = diskFreeSpace(dirPath)
+	logger.WriterFields(LOGGER_LEVEL_WARNING, "logger: disk space of adapter "+name+" is low, backups are purged", map[string]interface{}{
+		"adapter":     name,
+		"disk_free":   free / 1024,
+		"disk_purged": len(purged),
+	})
+	if config.OnEvent != nil {
+		config.OnEvent(DiskEvent{Adapter: name, OldState: state, State: state, Free: free / 1024, Purged: purged})
+	}
+}
+
+// disk state of the file adapter
+func (adapterFile *AdapterFile) DiskState() int {
+	return int(atomic.LoadInt32(&adapterFile.diskState))
+}
+
+// set disk state, return the old state
+func (adapterFile *AdapterFile) setDiskState(state int) int {
+	return int(atomic.SwapInt32(&adapterFile.diskState, int32(state)))
+}
+
+// file adapter drops the message by disk state
+func (adapterFile *AdapterFile) diskDropped(loggerMsg *loggerMessage) bool {
+	switch adapterFile.DiskState() {
+	case DISK_STATE_STOP:
+		return true
+	case DISK_STATE_DROP:
+		return loggerMsg.Level > adapterFile.diskDropLevel()
+	}
+	return false
+}
+
+// min level of the drop state
+func (adapterFile *AdapterFile) diskDropLevel() int {
+	if config, ok := adapterFile.diskWatch.Load().(*DiskWatchConfig); ok && config.DropLevel > 0 {
+		return config.DropLevel
+	}
+	return LOGGER_LEVEL_NOTICE
+}
+
+// write the dropped message to stderr if the adapter is stopped and diverted
+func (adapterFile *AdapterFile) diskDivert(loggerMsg *loggerMessage) error {
+	config, ok := adapterFile.diskWatch.Load().(*DiskWatchConfig)
+	if !ok || !config.Divert || adapterFile.DiskState() != DISK_STATE_STOP {
+		return nil
+	}
+	// stderr is text, json files are diverted in the default format
+	fc := adapterFile.config
+	format, jsonFormat := levelFormat(loggerMsg.Level, fc.Format, fc.JsonFormat, fc.LevelFormat, fc.LevelJsonFormat)
+	if jsonFormat {
+		format = defaultLoggerMessageFormat
+	}
+	_, err := io.WriteString(diskDivertWriter, loggerMessageFormat(format, loggerMsg)+fc.LineEnding)
+	return err
+}
+
+// backup files of all file writers and tenants, oldest first
+func (adapterFile *AdapterFile) backupFilesOldest() ([]string, error) {
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	backups := []backup{}
+	var backupErr error
+	files := []*AdapterFile{adapterFile}
+	files = append(files, adapterFile.tenantAdapters()...)
+	for _, file := range files {
+		for _, fileWrite := range file.write {
+			paths, err := fileWrite.backupFiles(file.config)
+			if err != nil && backupErr == nil {
+				backupErr = err
+			}
+			for _, path := range paths {
+				if fi, err := fileWrite.fs.Stat(path); err == nil {
+					backups = append(backups, backup{path: path, modTime: fi.ModTime()})
+				}
+			}
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.Before(backups[j].modTime)
+	})
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths, backupErr
+}
+
+// directory of the files of the adapter
+func (adapterFile *AdapterFile) dirPath() string {
+	if adapterFile.config.Filename != "" {
+		return filepath.Dir(adapterFile.config.Filename)
+	}
+	for _, filename := range adapterFile.config.LevelFileName {
+		return filepath.Dir(filename)
+	}
+	return ""
+}
+
+// gzip backup files of all file writers
+func (adapterFile *AdapterFile) compressBackups() error {
+	var compressErr error
+	for _, fileWrite := range adapterFile.write {
+		if fileWrite.gzip {
+			continue
+		}
+		err := fileWrite.compressBackups(adapterFile.config)
+		if err != nil && compressErr == nil {
+			compressErr = err
+		}
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		err := tenantFile.compressBackups()
+		if err != nil && compressErr == nil {
+			compressErr = err
+		}
+	}
+	return compressErr
+}
+
+// gzip backup files of the file, "app_20240102.log" is "app_20240102.log.gz"
+// gzip backups still match MaxBak, MaxAge and MaxTotalSize clean up
+func (fw *FileWriter) compressBackups(config *FileConfig) error {
+	patterns, dirPath, err := fw.backupPatterns(config)
+	if err != nil {
+		return err
+	}
+
+	dir, err := fw.fs.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, fi := range dir {
+		if fi.IsDir() || strings.HasSuffix(fi.Name(), ".gz") || !matchAny(patterns, fi.Name()) {
+			continue
+		}
+		// files are compressed after they are uploaded, uploads read them
+		if fw.uploads != nil && !fw.uploads.isUploaded(fi.Name()) {
+			continue
+		}
+		err = compressFile(fw.fs, filepath.Join(dirPath, fi.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// name matches one of the patterns
+func matchAny(patterns []*regexp.Regexp, name string) bool {
+	for _, r := range patterns {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzip the file of the file system to filename.gz and remove it
+func compressFile(fs FileSystem, filename string) error {
+	src, err := fs.OpenFile(filename, os.O_RDONLY, 0)
+	if err != nil {
+		// removed by clean up
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := fs.OpenFile(filename+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, srcInfo.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	gzipWriter := gzip.NewWriter(dst)
+	_, err = io.Copy(gzipWriter, src)
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	closeErr := dst.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fs.Remove(filename + ".gz")
+		return err
+	}
+	fs.Chtimes(filename+".gz", srcInfo.ModTime(), srcInfo.ModTime())
+	src.Close()
+	return fs.Remove(filename)
+}
@@ -0,0 +1,169 @@
+package go_logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDiskWatchConfig_State(t *testing.T) {
+
+	config := &DiskWatchConfig{CompressBelow: 300, DropBelow: 200, StopBelow: 100}
+	tests := map[int64]int{
+		400: DISK_STATE_NORMAL,
+		250: DISK_STATE_COMPRESS,
+		150: DISK_STATE_DROP,
+		50:  DISK_STATE_STOP,
+	}
+	for free, state := range tests {
+		if config.state(free) != state {
+			t.Errorf("disk state of free %d must be %d", free, config.state(free))
+		}
+	}
+}
+
+func TestLogger_WatchDisk(t *testing.T) {
+
+	var free int64 = 1000 * 1024
+	diskFreeSpace = func(dirPath string) (int64, error) {
+		return atomic.LoadInt64(&free), nil
+	}
+	defer func() {
+		diskFreeSpace = diskFree
+	}()
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	fileAdapter := logger.Adapter("file").(*AdapterFile)
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	backup := path.Join(path.Dir(fileAdapter.config.Filename), "test_20240102.log")
+	ioutil.WriteFile(backup, []byte("backup"), 0644)
+
+	stop := logger.WatchDisk(&DiskWatchConfig{
+		Interval:      10 * time.Millisecond,
+		CompressBelow: 500,
+		DropBelow:     200,
+		StopBelow:     100,
+	})
+	defer stop()
+
+	atomic.StoreInt64(&free, 150*1024)
+	time.Sleep(50 * time.Millisecond)
+	if fileAdapter.DiskState() != DISK_STATE_DROP {
+		t.Fatalf("disk state must be drop: %d", fileAdapter.DiskState())
+	}
+	if _, err := os.Stat(backup + ".gz"); err != nil {
+		t.Error("backup files must be compressed")
+	}
+	logger.Info("dropped")
+	logger.Error("written")
+
+	atomic.StoreInt64(&free, 50*1024)
+	time.Sleep(50 * time.Millisecond)
+	logger.Error("stopped")
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 5 || entries[0].Fields["disk_state"] != "drop" || entries[3].Fields["disk_state"] != "stop" {
+		t.Errorf("disk state transitions must be logged: %v", entries)
+	}
+	if entries[4].Body != "stopped" {
+		t.Error("other adapters must be written when file adapter is stopped")
+	}
+
+	logger.Detach("file")
+	if readLog() != "logger: disk state of adapter file is drop\nwritten\n" {
+		t.Error("file adapter must drop messages by disk state")
+	}
+}
+
+func TestLogger_WatchDiskPurge(t *testing.T) {
+
+	logger, _ := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+	fileAdapter := logger.Adapter("file").(*AdapterFile)
+	dir := path.Dir(fileAdapter.config.Filename)
+
+	backups := []string{}
+	for i, name := range []string{"test_20240103.log", "test_20240101.log", "test_20240102.log"} {
+		backup := path.Join(dir, name)
+		ioutil.WriteFile(backup, []byte("backup"), 0644)
+		modTime := time.Date(2024, 1, 1+i, 0, 0, 0, 0, time.Local)
+		os.Chtimes(backup, modTime, modTime)
+		backups = append(backups, backup)
+	}
+	// every removed backup frees 100KB
+	var base int64 = 50
+	diskFreeSpace = func(dirPath string) (int64, error) {
+		free := atomic.LoadInt64(&base)
+		for _, backup := range backups {
+			if _, err := os.Stat(backup); err != nil {
+				free += 100
+			}
+		}
+		return free * 1024, nil
+	}
+	defer func() {
+		diskFreeSpace = diskFree
+	}()
+
+	logger.Info("current")
+	events := []DiskEvent{}
+	config := &DiskWatchConfig{PurgeBelow: 200, OnEvent: func(event DiskEvent) {
+		events = append(events, event)
+	}}
+	logger.checkDisk(config)
+
+	if len(events) != 1 || len(events[0].Purged) != 2 || events[0].Purged[0] != backups[0] || events[0].Purged[1] != backups[1] || events[0].Free != 250 {
+		t.Fatalf("disk purge event error: %v", events)
+	}
+	if _, err := os.Stat(backups[2]); err != nil {
+		t.Error("disk purge must stop above PurgeBelow")
+	}
+	rotated, _ := fileAdapter.backupFilesOldest()
+	if len(rotated) != 2 || rotated[0] != backups[2] {
+		t.Errorf("disk purge must rotate the file: %v", rotated)
+	}
+
+	// the file is not rotated again while free space is below PurgeBelow
+	atomic.StoreInt64(&base, -100)
+	logger.checkDisk(config)
+	if rotated, _ = fileAdapter.backupFilesOldest(); len(rotated) != 1 || len(events) != 2 {
+		t.Errorf("disk purge error: %v, %d events", rotated, len(events))
+	}
+}
+
+func TestLogger_WatchDiskDivert(t *testing.T) {
+
+	var free int64 = 150 * 1024
+	diskFreeSpace = func(dirPath string) (int64, error) {
+		return atomic.LoadInt64(&free), nil
+	}
+	diverted := &bytes.Buffer{}
+	diskDivertWriter = diverted
+	defer func() {
+		diskFreeSpace = diskFree
+		diskDivertWriter = os.Stderr
+	}()
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+	config := &DiskWatchConfig{DropBelow: 200, StopBelow: 100, DropLevel: LOGGER_LEVEL_ERROR, Divert: true}
+	logger.checkDisk(config)
+	logger.Warning("dropped")
+	logger.Error("written")
+
+	atomic.StoreInt64(&free, 50*1024)
+	logger.checkDisk(config)
+	logger.Critical("diverted")
+
+	logger.Detach("file")
+	if readLog() != "written\n" {
+		t.Errorf("disk drop level error: %q", readLog())
+	}
+	if diverted.String() != "logger: disk state of adapter file is stop\ndiverted\n" {
+		t.Errorf("disk divert error: %q", diverted.String())
+	}
+}
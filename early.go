@@ -0,0 +1,100 @@
+package go_logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// default max messages buffered before any adapter is attached
+const EARLY_BUFFER_DEFAULT_SIZE = 1000
+
+// messages logged while no adapter is attached, eg: from init() before main() attaches adapters
+// they are written to the first attached adapter
+type earlyBuffer struct {
+	lock     sync.Mutex
+	size     int
+	messages []*loggerMessage
+	dropped  int64
+}
+
+// set max messages buffered while no adapter is attached, the oldest are dropped over it, 0 is disabled
+func (logger *Logger) SetEarlyBuffer(size int) {
+	logger.early.lock.Lock()
+	defer logger.early.lock.Unlock()
+
+	if size < 0 {
+		size = 0
+	}
+	logger.early.size = size
+	if len(logger.early.messages) > size {
+		logger.early.dropped += int64(len(logger.early.messages) - size)
+		logger.early.messages = logger.early.messages[len(logger.early.messages)-size:]
+	}
+}
+
+// buffer the message if no adapter is attached, return false if it's not buffered
+func (logger *Logger) bufferEarly(loggerMsg *loggerMessage) bool {
+	if len(logger.outputs) > 0 {
+		return false
+	}
+
+	logger.early.lock.Lock()
+	defer logger.early.lock.Unlock()
+
+	// adapter is attached before lock
+	if len(logger.outputs) > 0 || logger.early.size == 0 {
+		return false
+	}
+	if len(logger.early.messages) >= logger.early.size {
+		logger.early.messages[0].releaseWAL(false)
+		logger.early.messages = logger.early.messages[1:]
+		logger.early.dropped++
+		defer logger.strict.fail(STRICT_FAILURE_DROP, "", ErrEarlyBufferFull)
+	}
+	loggerMsg.retainWAL()
+	logger.early.messages = append(logger.early.messages, loggerMsg)
+	return true
+}
+
+// write buffered messages to the first attached output, call it after lock
+func (logger *Logger) flushEarly(output *outputLogger) {
+	logger.early.lock.Lock()
+	loggerMsgs := logger.early.messages
+	dropped := logger.early.dropped
+	logger.early.messages = nil
+	logger.early.dropped = 0
+	logger.early.lock.Unlock()
+
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "logger: %d messages before adapters are attached are dropped\n", dropped)
+	}
+	for _, loggerMsg := range loggerMsgs {
+		targets, routed := logger.routeTargets(loggerMsg)
+		if !output.accept(loggerMsg, targets, routed) || !output.sample(loggerMsg) {
+			loggerMsg.releaseWAL(true)
+			continue
+		}
+		if output.queue != nil {
+			output.queue.push(loggerMsg)
+			continue
+		}
+		err := output.send(loggerMsg)
+		if err != nil {
+			output.writeError(loggerMsg, err)
+		}
+		loggerMsg.releaseWAL(err == nil)
+	}
+}
+
+// write buffered messages to stderr when logger is closed without adapters
+func (logger *Logger) closeEarly() {
+	logger.early.lock.Lock()
+	defer logger.early.lock.Unlock()
+
+	for _, loggerMsg := range logger.early.messages {
+		fmt.Fprintln(os.Stderr, loggerMessageFormat(defaultLoggerMessageFormat, loggerMsg))
+		loggerMsg.releaseWAL(true)
+	}
+	logger.early.messages = nil
+}
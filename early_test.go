@@ -0,0 +1,36 @@
+package go_logger
+
+import (
+	"testing"
+)
+
+func TestLogger_EarlyBuffer(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.SetEarlyBuffer(2)
+	logger.Info("dropped")
+	logger.Info("init")
+	logger.Debug("config loaded")
+
+	logger.Attach("memory", LOGGER_LEVEL_INFO, &MemoryConfig{})
+	logger.Info("main")
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 2 || entries[0].Body != "init" || entries[1].Body != "main" {
+		t.Errorf("messages before adapters are attached must be written to the first adapter: %v", entries)
+	}
+}
+
+func TestLogger_EarlyBufferDisabled(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.SetEarlyBuffer(0)
+	logger.Info("discarded")
+
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	if len(logger.Adapter("memory").(*AdapterMemory).Entries()) != 0 {
+		t.Error("disabled early buffer must not keep messages")
+	}
+}
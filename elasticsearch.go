@@ -0,0 +1,361 @@
+package go_logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const ELASTICSEARCH_ADAPTER_NAME = "elasticsearch"
+
+const (
+	ELASTICSEARCH_DEFAULT_BATCH_SIZE     = 500
+	ELASTICSEARCH_DEFAULT_FLUSH_INTERVAL = 5 * time.Second
+	ELASTICSEARCH_DEFAULT_TIMEOUT        = 10 * time.Second
+	ELASTICSEARCH_DEFAULT_MAX_RETRIES    = 2
+)
+
+// time layout of index names, "2006" followed by month, day and hour, eg: "2006.01.02"
+var elasticsearchIndexTimeRegexp = regexp.MustCompile(`2006(?:[._-]?(?:01|02|15))*`)
+
+// adapter elasticsearch
+type AdapterElasticsearch struct {
+	encodingErrors int64 // json fallbacks of marshal errors
+
+	lock   sync.Mutex
+	config *ElasticsearchConfig
+	client *http.Client
+	buffer []*loggerMessage // wal records of buffered messages are retained until their request is sent
+	err    error            // first bulk error since the last writeBuffered
+	report func(loggerMsg *loggerMessage, err error)
+	shards *tenantShards
+	ticker *time.Ticker
+	quit   chan struct{}
+}
+
+// elasticsearch config
+type ElasticsearchConfig struct {
+
+	// elasticsearch address, eg: "http://127.0.0.1:9200"
+	Url string
+
+	// index name, time layout of year "2006", month "01", day "02" and hour "15" is replaced by message time
+	// example: "app-logs-2006.01.02"
+	Index string
+
+	// suffix index name with "-" and deploy tag, eg: "app-logs-2024.01.02-canary"
+	DeploySuffix bool
+
+	// write messages of every tenant to separate indices, index name is suffixed with "-" and tenant
+	TenantShard bool
+
+	// max tenant indices, messages of new tenants over it are written to the default index, default 100
+	MaxTenants int
+
+	// max messages of one _bulk request, default 500
+	BatchSize int
+
+	// buffered messages are sent every FlushInterval, default 5s
+	FlushInterval time.Duration
+
+	// failed _bulk requests (network errors, non 2xx status) are retried, default 2, -1 is no retry
+	// the error of a batch failed again is reported with every message, errors of documents aren't retried
+	MaxRetries int
+
+	// max document size (byte), bigger documents are split into parts by body, 0 is unlimited
+	MaxRecordSize int
+
+	// keys of documents, eg: keys of the index mapping, nil is the keys of JsonFormat
+	// example: &go_logger.JsonFormatter{Rename: map[string]string{"body": "message"}, FlattenFields: true}
+	Json *JsonFormatter
+
+	// strip ansi escapes and control characters (except "\t" and "\n") from body and fields
+	StripControl bool
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// basic auth
+	Username string
+	Password string
+
+	// tls config of https Url
+	TLSConfig *tls.Config
+}
+
+func (ec *ElasticsearchConfig) Name() string {
+	return ELASTICSEARCH_ADAPTER_NAME
+}
+
+func NewAdapterElasticsearch() LoggerAbstract {
+	return &AdapterElasticsearch{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterEs *AdapterElasticsearch) Init(esConfig Config) error {
+	ec, ok := esConfig.(*ElasticsearchConfig)
+	if !ok || ec == nil {
+		return configTypeError(ELASTICSEARCH_ADAPTER_NAME, "ElasticsearchConfig")
+	}
+	// defaults are set to a copy, the config of the caller isn't changed
+	esConfigCopy := *ec
+	ec = &esConfigCopy
+	adapterEs.config = ec
+
+	if ec.Url == "" {
+		return configError(ELASTICSEARCH_ADAPTER_NAME, "Url", "cannot be empty!")
+	}
+	if ec.Index == "" {
+		return configError(ELASTICSEARCH_ADAPTER_NAME, "Index", "cannot be empty!")
+	}
+	if ec.BatchSize <= 0 {
+		ec.BatchSize = ELASTICSEARCH_DEFAULT_BATCH_SIZE
+	}
+	if ec.FlushInterval <= 0 {
+		ec.FlushInterval = ELASTICSEARCH_DEFAULT_FLUSH_INTERVAL
+	}
+	if ec.Timeout <= 0 {
+		ec.Timeout = ELASTICSEARCH_DEFAULT_TIMEOUT
+	}
+	if ec.MaxRetries == 0 {
+		ec.MaxRetries = ELASTICSEARCH_DEFAULT_MAX_RETRIES
+	}
+	ec.Url = strings.TrimRight(ec.Url, "/")
+	if ec.TenantShard {
+		adapterEs.shards = newTenantShards(ec.MaxTenants)
+	}
+
+	adapterEs.client = &http.Client{
+		Timeout: ec.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: ec.TLSConfig,
+		},
+	}
+
+	adapterEs.ticker = time.NewTicker(ec.FlushInterval)
+	adapterEs.quit = make(chan struct{})
+	go adapterEs.startFlush(adapterEs.ticker, adapterEs.quit)
+
+	return nil
+}
+
+// buffer the message, the error of its _bulk request is reported later
+func (adapterEs *AdapterElasticsearch) Write(loggerMsg *loggerMessage) error {
+	if adapterEs.config.StripControl {
+		loggerMsg = sanitizeLoggerMessage(loggerMsg)
+	}
+	loggerMsg.retainWAL()
+	adapterEs.lock.Lock()
+	adapterEs.buffer = append(adapterEs.buffer, loggerMsg)
+	if len(adapterEs.buffer) < adapterEs.config.BatchSize {
+		adapterEs.lock.Unlock()
+		return nil
+	}
+	buffer := adapterEs.buffer
+	adapterEs.buffer = []*loggerMessage{}
+	adapterEs.lock.Unlock()
+
+	adapterEs.send(buffer)
+	return nil
+}
+
+func (adapterEs *AdapterElasticsearch) Flush() {
+	adapterEs.lock.Lock()
+	buffer := adapterEs.buffer
+	adapterEs.buffer = []*loggerMessage{}
+	adapterEs.lock.Unlock()
+
+	adapterEs.send(buffer)
+}
+
+func (adapterEs *AdapterElasticsearch) setErrorReport(report func(loggerMsg *loggerMessage, err error)) {
+	adapterEs.lock.Lock()
+	defer adapterEs.lock.Unlock()
+	adapterEs.report = report
+}
+
+func (adapterEs *AdapterElasticsearch) writeBuffered() error {
+	adapterEs.Flush()
+
+	adapterEs.lock.Lock()
+	defer adapterEs.lock.Unlock()
+	err := adapterEs.err
+	adapterEs.err = nil
+	return err
+}
+
+// send messages by _bulk api with retries, the error is reported with every message
+func (adapterEs *AdapterElasticsearch) send(loggerMsgs []*loggerMessage) {
+	if len(loggerMsgs) == 0 {
+		return
+	}
+	body := adapterEs.bulkBody(loggerMsgs)
+	retry, err := adapterEs.bulk(body)
+	for i := 0; retry && i < adapterEs.config.MaxRetries; i++ {
+		retry, err = adapterEs.bulk(body)
+	}
+
+	adapterEs.lock.Lock()
+	if err != nil && adapterEs.err == nil {
+		adapterEs.err = err
+	}
+	report := adapterEs.report
+	adapterEs.lock.Unlock()
+
+	bufferDone(ELASTICSEARCH_ADAPTER_NAME, report, loggerMsgs, err)
+}
+
+// stop flush ticker and send buffered messages
+func (adapterEs *AdapterElasticsearch) Close() error {
+	if adapterEs.ticker != nil {
+		adapterEs.ticker.Stop()
+		close(adapterEs.quit)
+		adapterEs.ticker = nil
+	}
+	adapterEs.Flush()
+	return nil
+}
+
+func (adapterEs *AdapterElasticsearch) Name() string {
+	return ELASTICSEARCH_ADAPTER_NAME
+}
+
+// Counters of json fallbacks
+func (adapterEs *AdapterElasticsearch) Counters() map[string]int64 {
+	return map[string]int64{COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterEs.encodingErrors)}
+}
+
+func (adapterEs *AdapterElasticsearch) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// GET request of the cluster root
+func (adapterEs *AdapterElasticsearch) HealthCheck() error {
+	return healthCheckHttp(adapterEs.client, "GET", adapterEs.config.Url+"/", func(req *http.Request) {
+		if adapterEs.config.Username != "" {
+			req.SetBasicAuth(adapterEs.config.Username, adapterEs.config.Password)
+		}
+	})
+}
+
+// flush buffer every FlushInterval
+func (adapterEs *AdapterElasticsearch) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterEs.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// ndjson body of the _bulk request
+func (adapterEs *AdapterElasticsearch) bulkBody(loggerMsgs []*loggerMessage) []byte {
+	body := &bytes.Buffer{}
+	for _, loggerMsg := range loggerMsgs {
+		action, _ := json.Marshal(map[string]map[string]string{
+			"index": {"_index": adapterEs.indexName(loggerMsg)},
+		})
+		for _, partMsg := range splitLoggerMessage(loggerMsg, adapterEs.config.MaxRecordSize) {
+			var doc []byte
+			if adapterEs.config.Json != nil {
+				doc = adapterEs.config.Json.formatMessage(partMsg)
+			} else {
+				doc = marshalLoggerMessage(partMsg, &adapterEs.encodingErrors)
+			}
+			body.Write(action)
+			body.WriteByte('\n')
+			body.Write(doc)
+			body.WriteByte('\n')
+		}
+	}
+	return body.Bytes()
+}
+
+// send the _bulk request, retry is true if the request failed and documents may be indexed by a retry
+func (adapterEs *AdapterElasticsearch) bulk(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest("POST", adapterEs.config.Url+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if adapterEs.config.Username != "" {
+		req.SetBasicAuth(adapterEs.config.Username, adapterEs.config.Password)
+	}
+
+	resp, err := adapterEs.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return true, fmt.Errorf("elasticsearch bulk request failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	bulkResp := &elasticsearchBulkResponse{}
+	err = json.Unmarshal(respBody, bulkResp)
+	if err != nil {
+		return false, err
+	}
+	if bulkResp.Errors {
+		for _, item := range bulkResp.Items {
+			for _, result := range item {
+				if result.Error != nil {
+					return false, fmt.Errorf("elasticsearch bulk index failed, index=%s, status=%d, error=%s", result.Index, result.Status, result.Error)
+				}
+			}
+		}
+		return false, errors.New("elasticsearch bulk index failed")
+	}
+	return false, nil
+}
+
+// index name by message time
+func (adapterEs *AdapterElasticsearch) indexName(loggerMsg *loggerMessage) string {
+	msgTime := time.Unix(0, loggerMsg.Millisecond*int64(time.Millisecond))
+	index := elasticsearchIndexTimeRegexp.ReplaceAllStringFunc(adapterEs.config.Index, func(layout string) string {
+		return msgTime.Format(layout)
+	})
+	if adapterEs.shards != nil {
+		tenant := messageTenant(loggerMsg)
+		if adapterEs.shards.allow(tenant) {
+			index += "-" + strings.ToLower(tenant)
+		}
+	}
+	if adapterEs.config.DeploySuffix && DeployTag() != "" {
+		index += "-" + DeployTag()
+	}
+	return index
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Index  string          `json:"_index"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+func init() {
+	Register(ELASTICSEARCH_ADAPTER_NAME, NewAdapterElasticsearch)
+	RegisterConfig(ELASTICSEARCH_ADAPTER_NAME, func() Config {
+		return &ElasticsearchConfig{}
+	})
+}
@@ -0,0 +1,124 @@
+package go_logger
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdapterElasticsearch_Write(t *testing.T) {
+
+	requests := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if r.URL.Path != "/_bulk" || username != "elastic" || password != "changeme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- string(body)
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	esAdapter := NewAdapterElasticsearch()
+	err := esAdapter.Init(&ElasticsearchConfig{
+		Url:           server.URL,
+		Index:         "app-logs-2006.01.02",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Username:      "elastic",
+		Password:      "changeme",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	loggerMsg := &loggerMessage{
+		Millisecond: time.Date(2024, 5, 10, 8, 0, 0, 0, time.Local).UnixNano() / 1e6,
+		Level:       LOGGER_LEVEL_ERROR,
+		LevelString: "Error",
+		Body:        "logger elasticsearch adapter test",
+	}
+	esAdapter.Write(loggerMsg)
+	if len(requests) != 0 {
+		t.Fatal("elasticsearch adapter sent before batch is full")
+	}
+	err = esAdapter.Write(loggerMsg)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	body := <-requests
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("elasticsearch bulk body error: %s", body)
+	}
+	if lines[0] != `{"index":{"_index":"app-logs-2024.05.10"}}` {
+		t.Error("elasticsearch bulk action error: " + lines[0])
+	}
+	if !strings.Contains(lines[1], `"body":"logger elasticsearch adapter test"`) {
+		t.Error("elasticsearch bulk doc error: " + lines[1])
+	}
+
+	esAdapter.Write(loggerMsg)
+	esAdapter.(LoggerCloser).Close()
+	if len(requests) != 1 {
+		t.Error("elasticsearch adapter close doesn't flush")
+	}
+}
+
+func TestAdapterElasticsearch_Retry(t *testing.T) {
+
+	var lock sync.Mutex
+	failures := 3
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	config := &ElasticsearchConfig{Url: server.URL + "/", Index: "app-logs-2006.01.02", FlushInterval: time.Hour}
+	failed := []string{}
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("elasticsearch", LOGGER_LEVEL_DEBUG, config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if config.BatchSize != 0 || config.MaxRetries != 0 || config.Url != server.URL+"/" {
+		t.Errorf("elasticsearch adapter changed the config: %+v", config)
+	}
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+		failed = append(failed, loggerMsg.Body)
+	})
+
+	// the request failed again after 2 retries is reported with every message
+	logger.Info("a")
+	logger.Info("b")
+	logger.Flush()
+	if requests != 3 || strings.Join(failed, ",") != "a,b" {
+		t.Errorf("elasticsearch failed bulk error: requests=%d, failed=%v", requests, failed)
+	}
+
+	// a retry is sent
+	failures = 4
+	logger.Info("c")
+	logger.Flush()
+	if requests != 5 || len(failed) != 2 {
+		t.Errorf("elasticsearch bulk retry error: requests=%d, failed=%v", requests, failed)
+	}
+	logger.Close(context.Background())
+}
@@ -0,0 +1,176 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// header line of files encrypted for a recipient, followed by the ephemeral public key
+const FILE_ENCRYPTION_X25519_HEADER = "#go-logger-x25519 "
+
+// encryption of file records at rest, records are "base64(nonce + aes-gcm ciphertext)" lines
+// set Key or Recipient, see DecryptLog() to decrypt
+type FileEncryption struct {
+
+	// aes-gcm key of 16, 24 or 32 bytes
+	Key []byte
+
+	// x25519 public key of the recipient (32 bytes), see GenerateEncryptionKey()
+	// a key of every opened file is agreed with an ephemeral key, only the private key of the recipient decrypts
+	Recipient []byte
+}
+
+// GenerateEncryptionKey return a x25519 key pair of FileEncryption Recipient and DecryptLog
+func GenerateEncryptionKey() (publicKey []byte, privateKey []byte, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.PublicKey().Bytes(), key.Bytes(), nil
+}
+
+func (encryption *FileEncryption) validate() error {
+	if (len(encryption.Key) == 0) == (len(encryption.Recipient) == 0) {
+		return configError("", "Encryption", "must set one of the Key, Recipient!")
+	}
+	if len(encryption.Key) > 0 {
+		_, err := newRecordCipher(encryption.Key)
+		if err != nil {
+			return configError("", "Encryption.Key", "must be 16, 24 or 32 bytes!")
+		}
+		return nil
+	}
+	_, err := ecdh.X25519().NewPublicKey(encryption.Recipient)
+	if err != nil {
+		return configError("", "Encryption.Recipient", "must be a x25519 public key!")
+	}
+	return nil
+}
+
+// cipher of the opened file and the header written first
+func (encryption *FileEncryption) fileCipher() (cipher.AEAD, string, error) {
+	if len(encryption.Key) > 0 {
+		aead, err := newRecordCipher(encryption.Key)
+		return aead, "", err
+	}
+	recipient, err := ecdh.X25519().NewPublicKey(encryption.Recipient)
+	if err != nil {
+		return nil, "", err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, "", err
+	}
+	aead, err := newRecordCipher(recipientKey(shared, ephemeral.PublicKey().Bytes(), encryption.Recipient))
+	if err != nil {
+		return nil, "", err
+	}
+	header := FILE_ENCRYPTION_X25519_HEADER + base64.StdEncoding.EncodeToString(ephemeral.PublicKey().Bytes()) + "\r\n"
+	return aead, header, nil
+}
+
+// aes-256 key of the shared secret, bound to both public keys
+func recipientKey(shared []byte, ephemeral []byte, recipient []byte) []byte {
+	mac := hmac.New(sha256.New, shared)
+	mac.Write([]byte(FILE_ENCRYPTION_X25519_HEADER))
+	mac.Write(ephemeral)
+	mac.Write(recipient)
+	return mac.Sum(nil)
+}
+
+func newRecordCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt the record to a line
+func encryptRecord(aead cipher.AEAD, record string) (string, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(record)+aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(record), nil)
+	return base64.StdEncoding.EncodeToString(sealed) + "\r\n", nil
+}
+
+// DecryptLog decrypt records of the encrypted file to w
+// key is the aes-gcm Key, or the x25519 private key of the Recipient
+func DecryptLog(r io.Reader, w io.Writer, key []byte) error {
+	// nil if key is not an aes key, records follow a x25519 header
+	aead, _ := newRecordCipher(key)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		// every opened file starts with a header of its ephemeral key
+		if strings.HasPrefix(line, FILE_ENCRYPTION_X25519_HEADER) {
+			var err error
+			aead, err = headerCipher(key, strings.TrimPrefix(line, FILE_ENCRYPTION_X25519_HEADER))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if aead == nil {
+			return errors.New("logger: key is not an aes key and the record has no x25519 header")
+		}
+		sealed, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return errors.New("logger: illegal encrypted record of line " + strconv.Itoa(lineNum))
+		}
+		record, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+		if err != nil {
+			return errors.New("logger: unable decrypt record of line " + strconv.Itoa(lineNum) + ", error: " + err.Error())
+		}
+		_, err = w.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// cipher of the header, privateKey is the x25519 private key of the recipient
+func headerCipher(privateKey []byte, header string) (cipher.AEAD, error) {
+	identity, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralBytes, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := identity.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return newRecordCipher(recipientKey(shared, ephemeralBytes, identity.PublicKey().Bytes()))
+}
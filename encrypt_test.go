@@ -0,0 +1,56 @@
+package go_logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileConfig_Encryption(t *testing.T) {
+
+	publicKey, privateKey, err := GenerateEncryptionKey()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	aesKey := bytes.Repeat([]byte{7}, 32)
+
+	for name, encryption := range map[string]*FileEncryption{"key": {Key: aesKey}, "recipient": {Recipient: publicKey}} {
+		config := &FileConfig{Format: "%body%", Encryption: encryption, LineEnding: LINE_ENDING_LF}
+		logger, readLog := newTestFileLogger(t, config)
+		logger.Info("secret audit record")
+		logger.Adapter("file").(LoggerCloser).Close()
+
+		// a new header is written by reopen
+		fileWrite := logger.Adapter("file").(*AdapterFile).write[FILE_ACCESS_LEVEL]
+		fileWrite.initFile()
+		fileWrite.writeByConfig(config, newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "second record", nil))
+		fileWrite.closeFile()
+
+		content := readLog()
+		if strings.Contains(content, "audit") {
+			t.Fatalf("%s: file is not encrypted: %s", name, content)
+		}
+		decryptKey := aesKey
+		if name == "recipient" {
+			decryptKey = privateKey
+		}
+		decrypted := &bytes.Buffer{}
+		err = DecryptLog(strings.NewReader(content), decrypted, decryptKey)
+		if err != nil {
+			t.Fatalf("%s: decrypt error: %v", name, err)
+		}
+		if decrypted.String() != "secret audit record\nsecond record\n" {
+			t.Errorf("%s: decrypted log error: %q", name, decrypted.String())
+		}
+		if DecryptLog(strings.NewReader(content), decrypted, bytes.Repeat([]byte{8}, 32)) == nil {
+			t.Errorf("%s: decrypt by other key must error", name)
+		}
+	}
+
+	fileAdapter := NewAdapterFile()
+	if fileAdapter.Init(&FileConfig{Filename: os.TempDir() + "/test.log", Encryption: &FileEncryption{Key: []byte("short")}}) == nil {
+		t.Error("illegal encryption key must error")
+	}
+}
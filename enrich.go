@@ -0,0 +1,440 @@
+package go_logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ENRICHER_DEFAULT_TTL          = time.Minute
+	ENRICHER_DEFAULT_NEGATIVE_TTL = 10 * time.Second
+	ENRICHER_DEFAULT_MAX_ENTRIES  = 10000
+	ENRICH_SOURCE_DEFAULT_TIMEOUT = time.Second
+)
+
+// source of enriched fields by key, nil fields and nil error if the key is not found
+type EnrichSource interface {
+	Lookup(key string) (map[string]interface{}, error)
+}
+
+// EnrichSource of func
+type EnrichSourceFunc func(key string) (map[string]interface{}, error)
+
+func (fn EnrichSourceFunc) Lookup(key string) (map[string]interface{}, error) {
+	return fn(key)
+}
+
+// enricher config
+type EnricherConfig struct {
+
+	// field of the lookup key, eg: "tenant_id", messages without it are not enriched
+	Field string
+
+	// source of fields, eg: RedisEnrichSource, EtcdEnrichSource
+	Source EnrichSource
+
+	// cache ttl of found keys, default 1m
+	// expired fields are still added while they're refreshed in background
+	TTL time.Duration
+
+	// cache ttl of not found keys and lookup errors, default 10s
+	NegativeTTL time.Duration
+
+	// max cached keys, default 10000
+	MaxEntries int
+}
+
+// enricher adds the fields of a key to messages by a hook, fields are cached locally
+// fields of the message take precedence over enriched fields
+//
+// example, map tenant id to plan tier:
+//	enricher, _ := go_logger.NewEnricher(&go_logger.EnricherConfig{
+//		Field:  "tenant_id",
+//		Source: &go_logger.RedisEnrichSource{Addr: "127.0.0.1:6379", KeyPrefix: "tenant:"},
+//	})
+//	logger.AddHook(enricher.Hook())
+type Enricher struct {
+	lock    sync.Mutex
+	config  *EnricherConfig
+	entries map[string]*enrichEntry
+}
+
+// cached fields of a key
+type enrichEntry struct {
+	fields     map[string]interface{}
+	expires    time.Time
+	loading    chan struct{} // closed after the first lookup
+	refreshing bool
+}
+
+func NewEnricher(config *EnricherConfig) (*Enricher, error) {
+	if config.Field == "" {
+		return nil, configError("", "Field", "cannot be empty!")
+	}
+	if config.Source == nil {
+		return nil, configError("", "Source", "cannot be empty!")
+	}
+	enricherConfig := *config
+	if enricherConfig.TTL <= 0 {
+		enricherConfig.TTL = ENRICHER_DEFAULT_TTL
+	}
+	if enricherConfig.NegativeTTL <= 0 {
+		enricherConfig.NegativeTTL = ENRICHER_DEFAULT_NEGATIVE_TTL
+	}
+	if enricherConfig.MaxEntries <= 0 {
+		enricherConfig.MaxEntries = ENRICHER_DEFAULT_MAX_ENTRIES
+	}
+	return &Enricher{config: &enricherConfig, entries: map[string]*enrichEntry{}}, nil
+}
+
+// hook of the logger, see Logger.AddHook()
+func (enricher *Enricher) Hook() Hook {
+	return func(entry *LogEntry) error {
+		key := loggerMessageField(entry.Fields, enricher.config.Field)
+		if key == "" {
+			return nil
+		}
+		for name, value := range enricher.Fields(key) {
+			if _, ok := entry.Fields[name]; !ok {
+				entry.Fields[name] = value
+			}
+		}
+		return nil
+	}
+}
+
+// fields of the key, looked up once by concurrent callers of a missing key
+func (enricher *Enricher) Fields(key string) map[string]interface{} {
+	enricher.lock.Lock()
+	entry, ok := enricher.entries[key]
+	if ok {
+		loading := entry.loading
+		if loading != nil {
+			enricher.lock.Unlock()
+			<-loading
+			enricher.lock.Lock()
+		} else if !entry.refreshing && time.Now().After(entry.expires) {
+			entry.refreshing = true
+			go enricher.refresh(key, entry)
+		}
+		fields := entry.fields
+		enricher.lock.Unlock()
+		return fields
+	}
+
+	if len(enricher.entries) >= enricher.config.MaxEntries {
+		enricher.evict()
+	}
+	entry = &enrichEntry{loading: make(chan struct{})}
+	enricher.entries[key] = entry
+	enricher.lock.Unlock()
+
+	fields, ttl := enricher.lookup(key)
+
+	enricher.lock.Lock()
+	defer enricher.lock.Unlock()
+	entry.fields = fields
+	entry.expires = time.Now().Add(ttl)
+	close(entry.loading)
+	entry.loading = nil
+	return fields
+}
+
+// remove the cached fields of the key
+func (enricher *Enricher) Invalidate(key string) {
+	enricher.lock.Lock()
+	defer enricher.lock.Unlock()
+
+	entry, ok := enricher.entries[key]
+	if ok && entry.loading == nil {
+		delete(enricher.entries, key)
+	}
+}
+
+// fields of the source and their ttl
+func (enricher *Enricher) lookup(key string) (map[string]interface{}, time.Duration) {
+	fields, err := enricher.config.Source.Lookup(key)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: enricher lookup %s failed, error: %v\n", key, err)
+		return nil, enricher.config.NegativeTTL
+	}
+	if len(fields) == 0 {
+		return nil, enricher.config.NegativeTTL
+	}
+	return fields, enricher.config.TTL
+}
+
+// refresh the expired entry, fields are kept if the lookup fails
+func (enricher *Enricher) refresh(key string, entry *enrichEntry) {
+	fields, err := enricher.config.Source.Lookup(key)
+
+	enricher.lock.Lock()
+	defer enricher.lock.Unlock()
+	entry.refreshing = false
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: enricher lookup %s failed, error: %v\n", key, err)
+		entry.expires = time.Now().Add(enricher.config.NegativeTTL)
+		return
+	}
+	entry.fields = fields
+	if len(fields) == 0 {
+		entry.fields = nil
+		entry.expires = time.Now().Add(enricher.config.NegativeTTL)
+		return
+	}
+	entry.expires = time.Now().Add(enricher.config.TTL)
+}
+
+// remove expired entries, or a random entry if none is expired, call it after lock
+func (enricher *Enricher) evict() {
+	now := time.Now()
+	for key, entry := range enricher.entries {
+		if entry.loading == nil && now.After(entry.expires) {
+			delete(enricher.entries, key)
+		}
+	}
+	if len(enricher.entries) < enricher.config.MaxEntries {
+		return
+	}
+	for key, entry := range enricher.entries {
+		if entry.loading == nil {
+			delete(enricher.entries, key)
+			return
+		}
+	}
+}
+
+// redis source, the value of KeyPrefix + key is a json object (GET), or a hash (HGETALL) if Hash is true
+type RedisEnrichSource struct {
+
+	// address, eg: "127.0.0.1:6379"
+	Addr string
+
+	Password string
+	DB       int
+
+	// key prefix, eg: "tenant:"
+	KeyPrefix string
+
+	// values are hashes
+	Hash bool
+
+	// dial and command timeout, default 1s
+	Timeout time.Duration
+
+	lock   sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (source *RedisEnrichSource) Lookup(key string) (map[string]interface{}, error) {
+	source.lock.Lock()
+	defer source.lock.Unlock()
+
+	command := "GET"
+	if source.Hash {
+		command = "HGETALL"
+	}
+	reply, err := source.do(command, source.KeyPrefix+key)
+	if err != nil {
+		// the connection is broken, redial by the next lookup
+		if source.conn != nil {
+			source.conn.Close()
+			source.conn = nil
+		}
+		return nil, err
+	}
+
+	switch value := reply.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		fields := map[string]interface{}{}
+		err = json.Unmarshal([]byte(value), &fields)
+		if err != nil {
+			return nil, fmt.Errorf("redis value of %s is not a json object, error: %v", source.KeyPrefix+key, err)
+		}
+		return fields, nil
+	case []interface{}:
+		fields := map[string]interface{}{}
+		for i := 0; i+1 < len(value); i += 2 {
+			name, _ := value[i].(string)
+			fields[name] = value[i+1]
+		}
+		return fields, nil
+	default:
+		return nil, fmt.Errorf("redis reply of %s is illegal: %v", source.KeyPrefix+key, reply)
+	}
+}
+
+// run the command, dial if not connected, call it after lock
+func (source *RedisEnrichSource) do(args ...string) (interface{}, error) {
+	timeout := source.Timeout
+	if timeout <= 0 {
+		timeout = ENRICH_SOURCE_DEFAULT_TIMEOUT
+	}
+	if source.conn == nil {
+		conn, err := net.DialTimeout("tcp", source.Addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+		source.conn = conn
+		source.reader = bufio.NewReader(conn)
+		if source.Password != "" {
+			_, err = source.command(timeout, "AUTH", source.Password)
+		}
+		if err == nil && source.DB != 0 {
+			_, err = source.command(timeout, "SELECT", strconv.Itoa(source.DB))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return source.command(timeout, args...)
+}
+
+func (source *RedisEnrichSource) command(timeout time.Duration, args ...string) (interface{}, error) {
+	source.conn.SetDeadline(time.Now().Add(timeout))
+	_, err := source.conn.Write(appendRedisCommand(nil, args...))
+	if err != nil {
+		return nil, err
+	}
+	return readRedisReply(source.reader)
+}
+
+// append the resp array of the command
+func appendRedisCommand(buf []byte, args ...string) []byte {
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	return buf
+}
+
+// error reply of redis, the connection is still usable
+type redisError string
+
+func (err redisError) Error() string {
+	return "redis error: " + string(err)
+}
+
+// read a resp reply, bulk strings are strings, nil bulk strings and arrays are nil
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis reply is empty")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		value := make([]byte, size+2)
+		_, err = io.ReadFull(reader, value)
+		if err != nil {
+			return nil, err
+		}
+		return string(value[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, size)
+		for i := range values {
+			values[i], err = readRedisReply(reader)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, errors.New("redis reply is illegal: " + line)
+	}
+}
+
+// etcd source by the v3 json gateway, the value of KeyPrefix + key is a json object
+type EtcdEnrichSource struct {
+
+	// endpoint, eg: "http://127.0.0.1:2379"
+	Endpoint string
+
+	// key prefix, eg: "/tenants/"
+	KeyPrefix string
+
+	// http client, default timeout 1s
+	Client *http.Client
+}
+
+func (source *EtcdEnrichSource) Lookup(key string) (map[string]interface{}, error) {
+	client := source.Client
+	if client == nil {
+		client = &http.Client{Timeout: ENRICH_SOURCE_DEFAULT_TIMEOUT}
+	}
+	body, _ := json.Marshal(map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(source.KeyPrefix + key))})
+	resp, err := client.Post(strings.TrimSuffix(source.Endpoint, "/")+"/v3/kv/range", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("etcd range request failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	rangeResp := struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}{}
+	err = json.Unmarshal(respBody, &rangeResp)
+	if err != nil {
+		return nil, err
+	}
+	if len(rangeResp.Kvs) == 0 {
+		return nil, nil
+	}
+	value, err := base64.StdEncoding.DecodeString(rangeResp.Kvs[0].Value)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(value, &fields)
+	if err != nil {
+		return nil, fmt.Errorf("etcd value of %s is not a json object, error: %v", source.KeyPrefix+key, err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,189 @@
+package go_logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnricher(t *testing.T) {
+
+	lookups := int32(0)
+	source := EnrichSourceFunc(func(key string) (map[string]interface{}, error) {
+		atomic.AddInt32(&lookups, 1)
+		switch key {
+		case "acme":
+			return map[string]interface{}{"plan": "gold", "region": "eu"}, nil
+		case "down":
+			return nil, errors.New("connection refused")
+		}
+		return nil, nil
+	})
+	enricher, err := NewEnricher(&EnricherConfig{Field: "tenant_id", Source: source, TTL: 50 * time.Millisecond, NegativeTTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "%body% %fields%"})
+	logger.AddHook(enricher.Hook())
+
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order", map[string]interface{}{"tenant_id": "acme", "region": "us"})
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order", map[string]interface{}{"tenant_id": "acme"})
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order", map[string]interface{}{"tenant_id": "globex"})
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order", map[string]interface{}{"tenant_id": "globex"})
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order", map[string]interface{}{"tenant_id": "down"})
+	logger.Info("no tenant")
+	expect := "order plan=gold region=us tenant_id=acme\n" +
+		"order plan=gold region=eu tenant_id=acme\n" +
+		"order tenant_id=globex\n" +
+		"order tenant_id=globex\n" +
+		"order tenant_id=down\n" +
+		"no tenant \n"
+	if buffer.String() != expect {
+		t.Errorf("enricher messages error: %q", buffer.String())
+	}
+	if atomic.LoadInt32(&lookups) != 3 {
+		t.Errorf("enricher negative cache error: %d lookups", lookups)
+	}
+
+	// expired fields are refreshed in background
+	time.Sleep(60 * time.Millisecond)
+	enricher.Fields("acme")
+	for i := 0; i < 100 && atomic.LoadInt32(&lookups) != 4; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&lookups) != 4 {
+		t.Errorf("enricher refresh error: %d lookups", lookups)
+	}
+	enricher.Invalidate("globex")
+	enricher.Fields("globex")
+	if atomic.LoadInt32(&lookups) != 5 {
+		t.Errorf("enricher invalidate error: %d lookups", lookups)
+	}
+
+	// concurrent misses of a key are looked up once
+	slow, _ := NewEnricher(&EnricherConfig{Field: "tenant_id", Source: EnrichSourceFunc(func(key string) (map[string]interface{}, error) {
+		atomic.AddInt32(&lookups, 1)
+		time.Sleep(20 * time.Millisecond)
+		return map[string]interface{}{"plan": "free"}, nil
+	}), MaxEntries: 1})
+	atomic.StoreInt32(&lookups, 0)
+	wait := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if slow.Fields("initech")["plan"] != "free" {
+				t.Error("enricher concurrent fields error")
+			}
+		}()
+	}
+	wait.Wait()
+	slow.Fields("hooli")
+	if atomic.LoadInt32(&lookups) != 2 || len(slow.entries) != 1 {
+		t.Errorf("enricher single lookup error: %d lookups, %d entries", lookups, len(slow.entries))
+	}
+
+	if _, err := NewEnricher(&EnricherConfig{Field: "tenant_id"}); err == nil {
+		t.Error("enricher empty source error")
+	}
+}
+
+func TestRedisEnrichSource(t *testing.T) {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	commands := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			reply, err := readRedisReply(reader)
+			if err != nil {
+				return
+			}
+			args := []string{}
+			for _, arg := range reply.([]interface{}) {
+				args = append(args, arg.(string))
+			}
+			commands <- strings.Join(args, " ")
+			switch args[0] {
+			case "AUTH", "SELECT":
+				conn.Write([]byte("+OK\r\n"))
+			case "GET":
+				if args[1] == "tenant:acme" {
+					conn.Write([]byte("$15\r\n{\"plan\":\"gold\"}\r\n"))
+				} else {
+					conn.Write([]byte("$-1\r\n"))
+				}
+			case "HGETALL":
+				conn.Write([]byte("*2\r\n$4\r\nplan\r\n$6\r\nsilver\r\n"))
+			}
+		}
+	}()
+
+	source := &RedisEnrichSource{Addr: listener.Addr().String(), Password: "secret", DB: 2, KeyPrefix: "tenant:"}
+	fields, err := source.Lookup("acme")
+	if err != nil || fields["plan"] != "gold" {
+		t.Errorf("redis get error: %v %v", fields, err)
+	}
+	fields, err = source.Lookup("globex")
+	if err != nil || fields != nil {
+		t.Errorf("redis not found error: %v %v", fields, err)
+	}
+	source.Hash = true
+	fields, err = source.Lookup("acme")
+	if err != nil || fields["plan"] != "silver" {
+		t.Errorf("redis hgetall error: %v %v", fields, err)
+	}
+	for _, expect := range []string{"AUTH secret", "SELECT 2", "GET tenant:acme", "GET tenant:globex", "HGETALL tenant:acme"} {
+		if command := <-commands; command != expect {
+			t.Errorf("redis command error: %q, expect %q", command, expect)
+		}
+	}
+}
+
+func TestEtcdEnrichSource(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&request)
+		key, _ := base64.StdEncoding.DecodeString(request["key"])
+		if r.URL.Path != "/v3/kv/range" || string(key) != "/tenants/acme" {
+			w.Write([]byte(`{"header":{}}`))
+			return
+		}
+		w.Write([]byte(`{"kvs":[{"value":"` + base64.StdEncoding.EncodeToString([]byte(`{"plan":"gold"}`)) + `"}]}`))
+	}))
+	defer server.Close()
+
+	source := &EtcdEnrichSource{Endpoint: server.URL, KeyPrefix: "/tenants/"}
+	fields, err := source.Lookup("acme")
+	if err != nil || fields["plan"] != "gold" {
+		t.Errorf("etcd range error: %v %v", fields, err)
+	}
+	fields, err = source.Lookup("globex")
+	if err != nil || fields != nil {
+		t.Errorf("etcd not found error: %v %v", fields, err)
+	}
+}
@@ -0,0 +1,36 @@
+package go_logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// called when an adapter failed to write a message, in the goroutine of the write (async queue goroutine in async mode)
+// adapter is the attached name of the adapter, do not log to the same adapter in handler
+type ErrorHandler func(adapter string, err error, loggerMsg *loggerMessage)
+
+// set handler of adapter write errors, errors are printed to stderr when handler is nil
+func (logger *Logger) SetErrorHandler(handler ErrorHandler) {
+	logger.errorHandler.Store(handler)
+}
+
+// report write error to the error handler of logger and strict mode
+func (output *outputLogger) writeError(loggerMsg *loggerMessage, err error) {
+	output.handleError(loggerMsg, err)
+	output.strict.fail(STRICT_FAILURE_WRITE, output.Name, err)
+}
+
+func (output *outputLogger) handleError(loggerMsg *loggerMessage, err error) {
+	handler, _ := output.errorHandler.Load().(ErrorHandler)
+	if handler == nil {
+		fmt.Fprintf(os.Stderr, "logger: unable write loggerMessage to adapter:%v, error: %v\n", output.Name, err)
+		return
+	}
+	defer func() {
+		e := recover()
+		if e != nil {
+			fmt.Fprintf(os.Stderr, "logger: error handler of adapter %s panic: %v, error: %v\n", output.Name, e, err)
+		}
+	}()
+	handler(output.Name, err, loggerMsg)
+}
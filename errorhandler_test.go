@@ -0,0 +1,57 @@
+package go_logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	return 0, fw.err
+}
+
+func TestLogger_SetErrorHandler(t *testing.T) {
+
+	diskFull := errors.New("disk full")
+	for _, async := range []bool{false, true} {
+		logger := NewLogger()
+		logger.Detach("console")
+		logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: &failingWriter{err: diskFull}})
+		if async {
+			logger.SetAsync()
+		}
+
+		lock := sync.Mutex{}
+		bodies := []string{}
+		logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+			if adapter != "writer" || !errors.Is(err, diskFull) {
+				t.Errorf("error handler args error: %s %v", adapter, err)
+			}
+			lock.Lock()
+			bodies = append(bodies, loggerMsg.Body)
+			lock.Unlock()
+		})
+		logger.Info("lost")
+		logger.Error("lost too")
+		logger.Flush()
+
+		lock.Lock()
+		if len(bodies) != 2 || bodies[0] != "lost" || bodies[1] != "lost too" {
+			t.Errorf("async=%v, error handler bodies error: %v", async, bodies)
+		}
+		lock.Unlock()
+
+		// panics of handler are recovered
+		logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+			panic("handler failed")
+		})
+		logger.Info("lost")
+		logger.Flush()
+		logger.Close(context.Background())
+	}
+}
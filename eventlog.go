@@ -0,0 +1,125 @@
+package go_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const EVENTLOG_ADAPTER_NAME = "eventlog"
+
+const EVENTLOG_DEFAULT_EVENT_ID = 1
+
+// event types of windows event log
+const (
+	EVENTLOG_ERROR_TYPE       = 0x0001
+	EVENTLOG_WARNING_TYPE     = 0x0002
+	EVENTLOG_INFORMATION_TYPE = 0x0004
+)
+
+// adapter eventlog, messages are reported to windows event log, windows only
+type AdapterEventlog struct {
+	lock   sync.Mutex
+	config *EventlogConfig
+	writer eventlogWriter
+}
+
+// eventlog config
+type EventlogConfig struct {
+
+	// event source, default name of the executable without extension
+	// register the source (eg: New-EventLog -LogName Application -Source app) to show messages without a warning of missing description
+	Source string
+
+	// event id of messages, default 1
+	EventId uint32
+
+	// message format, default "%millisecond_format% [%level_string%] %body%"
+	Format string
+}
+
+func (ec *EventlogConfig) Name() string {
+	return EVENTLOG_ADAPTER_NAME
+}
+
+// event log of the source
+type eventlogWriter interface {
+	report(eventType uint16, eventId uint32, msg string) error
+	close() error
+}
+
+func NewAdapterEventlog() LoggerAbstract {
+	return &AdapterEventlog{}
+}
+
+func (adapterEventlog *AdapterEventlog) Init(eventlogConfig Config) error {
+	ec, ok := eventlogConfig.(*EventlogConfig)
+	if !ok || ec == nil {
+		return configTypeError(EVENTLOG_ADAPTER_NAME, "EventlogConfig")
+	}
+	adapterEventlog.config = ec
+
+	if ec.Source == "" {
+		ec.Source = strings.TrimSuffix(filepath.Base(os.Args[0]), filepath.Ext(os.Args[0]))
+	}
+	if ec.EventId == 0 {
+		ec.EventId = EVENTLOG_DEFAULT_EVENT_ID
+	}
+	if ec.Format == "" {
+		ec.Format = defaultLoggerMessageFormat
+	}
+	if err := checkFormatPlaceholders(ec.Format, nil); err != nil {
+		return err
+	}
+
+	writer, err := openEventlog(ec.Source)
+	if err != nil {
+		return err
+	}
+	adapterEventlog.writer = writer
+	return nil
+}
+
+// report the message, error and more severe messages are errors, warning is warning, others are information
+func (adapterEventlog *AdapterEventlog) Write(loggerMsg *loggerMessage) error {
+	adapterEventlog.lock.Lock()
+	defer adapterEventlog.lock.Unlock()
+
+	msg := loggerMessageFormat(adapterEventlog.config.Format, loggerMsg)
+	return adapterEventlog.writer.report(eventlogType(loggerMsg.Level), adapterEventlog.config.EventId, msg)
+}
+
+func (adapterEventlog *AdapterEventlog) Flush() {
+
+}
+
+func (adapterEventlog *AdapterEventlog) Close() error {
+	adapterEventlog.lock.Lock()
+	defer adapterEventlog.lock.Unlock()
+
+	return adapterEventlog.writer.close()
+}
+
+func (adapterEventlog *AdapterEventlog) Name() string {
+	return EVENTLOG_ADAPTER_NAME
+}
+
+// event type of the level
+func eventlogType(level int) uint16 {
+	switch {
+	case level <= LOGGER_LEVEL_ERROR:
+		return EVENTLOG_ERROR_TYPE
+	case level == LOGGER_LEVEL_WARNING:
+		return EVENTLOG_WARNING_TYPE
+	default:
+		return EVENTLOG_INFORMATION_TYPE
+	}
+}
+
+func init() {
+	Register(EVENTLOG_ADAPTER_NAME, NewAdapterEventlog)
+	RegisterConfig(EVENTLOG_ADAPTER_NAME, func() Config {
+		return &EventlogConfig{}
+	})
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+// +build !windows
+
+package go_logger
+
+import "errors"
+
+// event log is not supported
+func openEventlog(source string) (eventlogWriter, error) {
+	return nil, errors.New("logger eventlog adapter is only supported on windows")
+}
@@ -0,0 +1,32 @@
+package go_logger
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestEventlogType(t *testing.T) {
+
+	types := map[int]uint16{
+		LOGGER_LEVEL_EMERGENCY: EVENTLOG_ERROR_TYPE,
+		LOGGER_LEVEL_ERROR:     EVENTLOG_ERROR_TYPE,
+		LOGGER_LEVEL_WARNING:   EVENTLOG_WARNING_TYPE,
+		LOGGER_LEVEL_NOTICE:    EVENTLOG_INFORMATION_TYPE,
+		LOGGER_LEVEL_DEBUG:     EVENTLOG_INFORMATION_TYPE,
+	}
+	for level, eventType := range types {
+		if eventlogType(level) != eventType {
+			t.Errorf("eventlog type of level %d error: %d", level, eventlogType(level))
+		}
+	}
+}
+
+func TestAdapterEventlog_Init(t *testing.T) {
+
+	if runtime.GOOS == "windows" {
+		t.Skip("eventlog is supported")
+	}
+	if NewAdapterEventlog().Init(&EventlogConfig{Source: "app"}) == nil {
+		t.Error("eventlog must error on unsupported platform")
+	}
+}
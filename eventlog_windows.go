@@ -0,0 +1,55 @@
+//go:build windows
+// +build windows
+
+package go_logger
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32              = syscall.NewLazyDLL("advapi32.dll")
+	registerEventSource   = advapi32.NewProc("RegisterEventSourceW")
+	reportEvent           = advapi32.NewProc("ReportEventW")
+	deregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+)
+
+// event log handle of the source
+type eventlogHandle struct {
+	handle uintptr
+}
+
+func openEventlog(source string) (eventlogWriter, error) {
+	sourcePtr, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	handle, _, err := registerEventSource.Call(0, uintptr(unsafe.Pointer(sourcePtr)))
+	if handle == 0 {
+		return nil, err
+	}
+	return &eventlogHandle{handle: handle}, nil
+}
+
+// report the message as the only insertion string of the event
+func (eventlog *eventlogHandle) report(eventType uint16, eventId uint32, msg string) error {
+	msgPtr, err := syscall.UTF16PtrFromString(msg)
+	if err != nil {
+		return err
+	}
+	insertions := []*uint16{msgPtr}
+	ret, _, err := reportEvent.Call(eventlog.handle, uintptr(eventType), 0, uintptr(eventId), 0, 1, 0, uintptr(unsafe.Pointer(&insertions[0])), 0)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func (eventlog *eventlogHandle) close() error {
+	ret, _, err := deregisterEventSource.Call(eventlog.handle)
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
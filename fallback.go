@@ -0,0 +1,204 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	FALLBACK_DEFAULT_MAX_FAILURES   = 3
+	FALLBACK_DEFAULT_RETRY_INTERVAL = 30 * time.Second
+	FALLBACK_DEFAULT_MAX_REPLAY     = 10000
+)
+
+// fallback of an adapter, failed messages are written to the fallback adapter
+type FallbackConfig struct {
+
+	// attached name of the fallback adapter, eg: "file"
+	Adapter string
+
+	// consecutive failures (errors and timeouts) before the adapter is down, default 3
+	// messages are written to the fallback adapter only while the adapter is down
+	MaxFailures int
+
+	// the down adapter is retried every RetryInterval, default 30s
+	RetryInterval time.Duration
+
+	// replay messages written to the fallback adapter when the adapter is recovered
+	Replay bool
+
+	// max messages kept for replay, the oldest are dropped, default 10000
+	MaxReplay int
+
+	// the fallback adapter writes fallback messages only, not its own messages
+	Standby bool
+}
+
+// fallback state of an output
+type adapterFallback struct {
+	lock      sync.Mutex
+	config    *FallbackConfig
+	secondary *outputLogger
+	failures  int
+	down      bool
+	nextRetry time.Time
+	replay    []*loggerMessage
+}
+
+// set fallback of attached adapter, nil config removes the fallback
+// example, write to a local file when the api is down and replay on recovery:
+//	logger.SetAdapterFallback("api", &go_logger.FallbackConfig{Adapter: "file", Replay: true})
+//params : adapterName string, config *FallbackConfig
+//return : error
+func (logger *Logger) SetAdapterFallback(adapterName string, config *FallbackConfig) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	var primary, secondary *outputLogger
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			primary = output
+		}
+		if config != nil && output.Name == config.Adapter {
+			secondary = output
+		}
+	}
+	if primary == nil {
+		return errors.New("logger: adapter " + adapterName + " is not attached!")
+	}
+	if config == nil {
+		primary.fallback.Store((*adapterFallback)(nil))
+		logger.updateStandby()
+		return nil
+	}
+	if secondary == nil {
+		return errors.New("logger: fallback adapter " + config.Adapter + " is not attached!")
+	}
+	if secondary == primary {
+		return errors.New("logger: adapter " + adapterName + " cannot fallback to itself!")
+	}
+
+	fallbackConfig := *config
+	if fallbackConfig.MaxFailures <= 0 {
+		fallbackConfig.MaxFailures = FALLBACK_DEFAULT_MAX_FAILURES
+	}
+	if fallbackConfig.RetryInterval <= 0 {
+		fallbackConfig.RetryInterval = FALLBACK_DEFAULT_RETRY_INTERVAL
+	}
+	if fallbackConfig.MaxReplay <= 0 {
+		fallbackConfig.MaxReplay = FALLBACK_DEFAULT_MAX_REPLAY
+	}
+	primary.fallback.Store(&adapterFallback{
+		config:    &fallbackConfig,
+		secondary: secondary,
+	})
+	logger.updateStandby()
+	return nil
+}
+
+// remove fallbacks to the detached output after lock
+func (logger *Logger) removeFallbacks(detached *outputLogger) {
+	for _, output := range logger.outputs {
+		fallback, _ := output.fallback.Load().(*adapterFallback)
+		if fallback != nil && fallback.secondary == detached {
+			output.fallback.Store((*adapterFallback)(nil))
+		}
+	}
+}
+
+// outputs are standby while they are Standby fallbacks of other outputs, after lock
+func (logger *Logger) updateStandby() {
+	standby := map[*outputLogger]bool{}
+	for _, output := range logger.outputs {
+		fallback, _ := output.fallback.Load().(*adapterFallback)
+		if fallback != nil && fallback.config.Standby {
+			standby[fallback.secondary] = true
+		}
+	}
+	for _, output := range logger.outputs {
+		if standby[output] {
+			atomic.StoreInt32(&output.standby, 1)
+		} else {
+			atomic.StoreInt32(&output.standby, 0)
+		}
+	}
+}
+
+// write message to the spool and the adapter, or its fallback adapter if it failed or is down
+func (output *outputLogger) send(loggerMsg *loggerMessage) error {
+	if output.expired(loggerMsg) {
+		return ErrMessageExpired
+	}
+	if spool, _ := output.spool.Load().(*adapterSpool); spool != nil {
+		return spool.write(loggerMsg)
+	}
+	return output.transmit(loggerMsg)
+}
+
+// write message to the adapter, or its fallback adapter
+func (output *outputLogger) transmit(loggerMsg *loggerMessage) error {
+	fallback, _ := output.fallback.Load().(*adapterFallback)
+	if fallback == nil {
+		return output.write(loggerMsg)
+	}
+	return fallback.write(output, loggerMsg)
+}
+
+func (fallback *adapterFallback) write(primary *outputLogger, loggerMsg *loggerMessage) error {
+	fallback.lock.Lock()
+	defer fallback.lock.Unlock()
+
+	now := time.Now()
+	if fallback.down {
+		if now.Before(fallback.nextRetry) || !fallback.replayTo(primary) {
+			fallback.nextRetry = now.Add(fallback.config.RetryInterval)
+			return fallback.writeSecondary(loggerMsg)
+		}
+	}
+
+	err := primary.write(loggerMsg)
+	if err == nil {
+		if fallback.down {
+			fallback.down = false
+			fmt.Fprintf(os.Stderr, "logger: adapter %s is recovered from fallback %s\n", primary.Name, fallback.secondary.Name)
+		}
+		fallback.failures = 0
+		return nil
+	}
+
+	fallback.failures++
+	if !fallback.down && fallback.failures >= fallback.config.MaxFailures {
+		fallback.down = true
+		fmt.Fprintf(os.Stderr, "logger: adapter %s is down, error: %v, messages are written to fallback %s\n", primary.Name, err, fallback.secondary.Name)
+	}
+	fallback.nextRetry = now.Add(fallback.config.RetryInterval)
+	return fallback.writeSecondary(loggerMsg)
+}
+
+// write message to the fallback adapter and keep it for replay
+func (fallback *adapterFallback) writeSecondary(loggerMsg *loggerMessage) error {
+	if fallback.config.Replay {
+		if len(fallback.replay) >= fallback.config.MaxReplay {
+			fallback.replay = fallback.replay[1:]
+		}
+		fallback.replay = append(fallback.replay, loggerMsg)
+	}
+	return fallback.secondary.write(loggerMsg)
+}
+
+// replay kept messages to the adapter in order, false if it failed again, expired messages are skipped
+func (fallback *adapterFallback) replayTo(primary *outputLogger) bool {
+	for len(fallback.replay) > 0 {
+		if !primary.expired(fallback.replay[0]) && primary.write(fallback.replay[0]) != nil {
+			return false
+		}
+		fallback.replay[0] = nil
+		fallback.replay = fallback.replay[1:]
+	}
+	fallback.replay = nil
+	return true
+}
@@ -0,0 +1,103 @@
+package go_logger
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writer fails while down is set
+type switchWriter struct {
+	lock   sync.Mutex
+	down   bool
+	buffer bytes.Buffer
+}
+
+func (sw *switchWriter) Write(p []byte) (int, error) {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	if sw.down {
+		return 0, errors.New("connection refused")
+	}
+	return sw.buffer.Write(p)
+}
+
+func (sw *switchWriter) setDown(down bool) {
+	sw.lock.Lock()
+	sw.down = down
+	sw.lock.Unlock()
+}
+
+func (sw *switchWriter) String() string {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	return sw.buffer.String()
+}
+
+func TestLogger_SetAdapterFallback(t *testing.T) {
+
+	primary := &switchWriter{}
+	secondary := &switchWriter{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAs("remote", "writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: primary, Format: "%body%"})
+	logger.AttachAs("local", "writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: secondary, Format: "%body%"})
+
+	if logger.SetAdapterFallback("remote", &FallbackConfig{Adapter: "none"}) == nil {
+		t.Errorf("fallback adapter must be attached")
+	}
+	err := logger.SetAdapterFallback("remote", &FallbackConfig{
+		Adapter:       "local",
+		MaxFailures:   2,
+		RetryInterval: 50 * time.Millisecond,
+		Replay:        true,
+		Standby:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("1")
+	primary.setDown(true)
+	logger.Info("2")
+	logger.Info("3")
+	// remote is down, not retried before RetryInterval
+	primary.setDown(false)
+	logger.Info("4")
+	if primary.String() != "1\n" || secondary.String() != "2\n3\n4\n" {
+		t.Errorf("fallback error: %q %q", primary.String(), secondary.String())
+	}
+
+	// recovered, fallback messages are replayed in order
+	time.Sleep(60 * time.Millisecond)
+	logger.Info("5")
+	if primary.String() != "1\n2\n3\n4\n5\n" || secondary.String() != "2\n3\n4\n" {
+		t.Errorf("fallback replay error: %q %q", primary.String(), secondary.String())
+	}
+
+	logger.Detach("local")
+	primary.setDown(true)
+	logger.Info("6")
+	if secondary.String() != "2\n3\n4\n" {
+		t.Errorf("fallback must be removed with detached adapter")
+	}
+}
+
+func TestLogger_SetAdapterFallbackStandby(t *testing.T) {
+
+	secondary := &switchWriter{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAs("remote", "writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: &switchWriter{}, Format: "%body%"})
+	logger.AttachAs("local", "writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: secondary, Format: "%body%"})
+
+	logger.SetAdapterFallback("remote", &FallbackConfig{Adapter: "local", Standby: true})
+	logger.Info("standby")
+	logger.SetAdapterFallback("remote", nil)
+	logger.Info("own")
+	if secondary.String() != "own\n" {
+		t.Errorf("standby error: %q", secondary.String())
+	}
+}
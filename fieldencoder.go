@@ -0,0 +1,132 @@
+package go_logger
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// encoder of field values of a type, the result is written instead of the value
+type FieldEncoder func(value interface{}) interface{}
+
+// registered encoders, copied on register
+type fieldEncoderRegistry struct {
+	types      map[reflect.Type]FieldEncoder
+	interfaces []reflect.Type // interface types in register order
+}
+
+var fieldEncoders atomic.Value // *fieldEncoderRegistry
+
+// lock of RegisterFieldEncoder
+var fieldEncodersLock sync.Mutex
+
+// register the encoder of field values of the type, encoded values are written by every adapter and format
+// values of an interface type are values implementing it, encoders of concrete types are checked first
+// values of nested maps and slices of fields are encoded too, results are not encoded again
+//
+// example:
+//	go_logger.RegisterFieldEncoder(reflect.TypeOf(decimal.Decimal{}), func(value interface{}) interface{} {
+//		return value.(decimal.Decimal).String()
+//	})
+func RegisterFieldEncoder(valueType reflect.Type, encoder FieldEncoder) {
+	if valueType == nil {
+		panic("logger: field encoder type is nil!")
+	}
+	if encoder == nil {
+		panic("logger: field encoder of " + valueType.String() + " is nil!")
+	}
+
+	fieldEncodersLock.Lock()
+	defer fieldEncodersLock.Unlock()
+
+	registry := &fieldEncoderRegistry{types: map[reflect.Type]FieldEncoder{}}
+	if current, ok := fieldEncoders.Load().(*fieldEncoderRegistry); ok {
+		if current.types[valueType] != nil {
+			panic("logger: field encoder of " + valueType.String() + " already registered!")
+		}
+		for registered, registeredEncoder := range current.types {
+			registry.types[registered] = registeredEncoder
+		}
+		registry.interfaces = append(registry.interfaces, current.interfaces...)
+	}
+	registry.types[valueType] = encoder
+	if valueType.Kind() == reflect.Interface {
+		registry.interfaces = append(registry.interfaces, valueType)
+	}
+	fieldEncoders.Store(registry)
+}
+
+// encode fields of the message by registered encoders, fields are copied if a value is encoded
+func encodeFields(loggerMsg *loggerMessage) {
+	registry, ok := fieldEncoders.Load().(*fieldEncoderRegistry)
+	if !ok || len(loggerMsg.Fields) == 0 {
+		return
+	}
+	if fields, encoded := registry.encodeMap(loggerMsg.Fields); encoded {
+		loggerMsg.Fields = fields
+	}
+}
+
+// encoder of the value type, nil if there is no encoder
+func (registry *fieldEncoderRegistry) encoderOf(valueType reflect.Type) FieldEncoder {
+	if encoder, ok := registry.types[valueType]; ok {
+		return encoder
+	}
+	for _, interfaceType := range registry.interfaces {
+		if valueType.Implements(interfaceType) {
+			return registry.types[interfaceType]
+		}
+	}
+	return nil
+}
+
+func (registry *fieldEncoderRegistry) encode(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case map[string]interface{}:
+		return registry.encodeMap(v)
+	case []interface{}:
+		var encodedValues []interface{}
+		for i, item := range v {
+			encodedItem, encoded := registry.encode(item)
+			if encoded && encodedValues == nil {
+				encodedValues = make([]interface{}, len(v))
+				copy(encodedValues, v)
+			}
+			if encodedValues != nil {
+				encodedValues[i] = encodedItem
+			}
+		}
+		if encodedValues == nil {
+			return value, false
+		}
+		return encodedValues, true
+	}
+	if encoder := registry.encoderOf(reflect.TypeOf(value)); encoder != nil {
+		return encoder(value), true
+	}
+	return value, false
+}
+
+// map is copied if a value is encoded
+func (registry *fieldEncoderRegistry) encodeMap(values map[string]interface{}) (map[string]interface{}, bool) {
+	var encodedValues map[string]interface{}
+	for key, value := range values {
+		encodedValue, encoded := registry.encode(value)
+		if !encoded {
+			continue
+		}
+		if encodedValues == nil {
+			encodedValues = make(map[string]interface{}, len(values))
+			for k, v := range values {
+				encodedValues[k] = v
+			}
+		}
+		encodedValues[key] = encodedValue
+	}
+	if encodedValues == nil {
+		return values, false
+	}
+	return encodedValues, true
+}
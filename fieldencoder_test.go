@@ -0,0 +1,68 @@
+package go_logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testFieldMoney struct {
+	cents    int64
+	currency string
+}
+
+type testFieldId interface {
+	FieldId() string
+}
+
+type testFieldUserId int
+
+func (id testFieldUserId) FieldId() string {
+	return fmt.Sprintf("user-%d", id)
+}
+
+// encoders are registered once, registering a type twice panics
+func init() {
+	RegisterFieldEncoder(reflect.TypeOf(testFieldMoney{}), func(value interface{}) interface{} {
+		money := value.(testFieldMoney)
+		return fmt.Sprintf("%d.%02d %s", money.cents/100, money.cents%100, money.currency)
+	})
+	RegisterFieldEncoder(reflect.TypeOf((*testFieldId)(nil)).Elem(), func(value interface{}) interface{} {
+		return value.(testFieldId).FieldId()
+	})
+}
+
+func TestRegisterFieldEncoder(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{JsonFormat: true})
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+
+	fields := map[string]interface{}{
+		"amount": testFieldMoney{cents: 1250, currency: "EUR"},
+		"order":  map[string]interface{}{"buyer": testFieldUserId(7), "items": []interface{}{1, testFieldMoney{cents: 5, currency: "EUR"}}},
+		"count":  2,
+	}
+	logger.WriterFields(LOGGER_LEVEL_INFO, "paid", fields)
+
+	entry := logger.Adapter("memory").(*AdapterMemory).Entries()[0]
+	order := entry.Fields["order"].(map[string]interface{})
+	if entry.Fields["amount"] != "12.50 EUR" || order["buyer"] != "user-7" || order["items"].([]interface{})[1] != "0.05 EUR" ||
+		entry.Fields["count"] != 2 {
+		t.Errorf("field encoder error: %v", entry.Fields)
+	}
+	if _, ok := fields["amount"].(testFieldMoney); !ok {
+		t.Errorf("field encoder changed fields of caller: %v", fields)
+	}
+	log := readLog()
+	if !strings.Contains(log, `"amount":"12.50 EUR"`) || !strings.Contains(log, `"buyer":"user-7"`) {
+		t.Errorf("field encoder json error: %s", log)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("register field encoder twice must panic")
+		}
+	}()
+	RegisterFieldEncoder(reflect.TypeOf(testFieldMoney{}), func(value interface{}) interface{} { return value })
+}
@@ -0,0 +1,189 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// prefix of encrypted field values, "enc:x25519:" + base64(ephemeral public key) + ":" + base64(nonce + aes-gcm ciphertext)
+const FIELD_ENCRYPTION_PREFIX = "enc:x25519:"
+
+var fieldEncryptionRegexp = regexp.MustCompile(`("?)` + FIELD_ENCRYPTION_PREFIX + `([A-Za-z0-9+/=]+):([A-Za-z0-9+/=]+)("?)`)
+
+// field encryptor encrypts values of fields for a recipient before adapters write
+// values are json encoded and encrypted with the x25519 public key of the recipient (see GenerateEncryptionKey()),
+// only the private key decrypts them, see DecryptFields() and "logdecrypt -fields"
+type FieldEncryptor struct {
+	fields    map[string]bool
+	aead      cipher.AEAD
+	ephemeral string // base64 ephemeral public key of values
+}
+
+// new field encryptor of the field names, name match is case insensitive, fields of nested maps match too
+//
+// example:
+//	encryptor, err := go_logger.NewFieldEncryptor(auditorPublicKey, "ssn", "card_last4_plus")
+//	if err != nil {
+//		return err
+//	}
+//	logger.SetFieldEncryptor(encryptor)
+func NewFieldEncryptor(recipient []byte, fields ...string) (*FieldEncryptor, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("logger: fields of field encryptor cannot be empty!")
+	}
+	recipientPublic, err := ecdh.X25519().NewPublicKey(recipient)
+	if err != nil {
+		return nil, errors.New("logger: recipient must be a x25519 public key!")
+	}
+	// one ephemeral key of the encryptor, values carry it and are decrypted alone
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(recipientPublic)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newRecordCipher(recipientKey(shared, ephemeral.PublicKey().Bytes(), recipient))
+	if err != nil {
+		return nil, err
+	}
+
+	encryptor := &FieldEncryptor{
+		fields:    map[string]bool{},
+		aead:      aead,
+		ephemeral: base64.StdEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+	}
+	for _, name := range fields {
+		encryptor.fields[strings.ToLower(name)] = true
+	}
+	return encryptor, nil
+}
+
+// set field encryptor, applied after redaction, nil disables encryption
+func (logger *Logger) SetFieldEncryptor(encryptor *FieldEncryptor) {
+	logger.encryptor.Store(&encryptor)
+}
+
+// encrypt fields of the message with logger field encryptor
+func (logger *Logger) encryptFields(loggerMsg *loggerMessage) {
+	encryptor, ok := logger.encryptor.Load().(**FieldEncryptor)
+	if !ok || *encryptor == nil || len(loggerMsg.Fields) == 0 {
+		return
+	}
+	loggerMsg.Fields = (*encryptor).encryptFields(loggerMsg.Fields)
+}
+
+// fields are copied, values of encrypted fields are replaced
+func (encryptor *FieldEncryptor) encryptFields(fields map[string]interface{}) map[string]interface{} {
+	encrypted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if encryptor.fields[strings.ToLower(key)] {
+			encrypted[key] = encryptor.Encrypt(value)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = encryptor.encryptFields(nested)
+		}
+		encrypted[key] = value
+	}
+	return encrypted
+}
+
+// encrypt the json of value, values which cannot be marshaled are their "%+v" strings
+// the value never leaves in plain text, it's REDACT_DEFAULT_MASK if it cannot be encrypted
+func (encryptor *FieldEncryptor) Encrypt(value interface{}) string {
+	plain, err := json.Marshal(value)
+	if err != nil {
+		plain, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+	nonce := make([]byte, encryptor.aead.NonceSize(), encryptor.aead.NonceSize()+len(plain)+encryptor.aead.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return REDACT_DEFAULT_MASK
+	}
+	sealed := encryptor.aead.Seal(nonce, nonce, plain, nil)
+	return FIELD_ENCRYPTION_PREFIX + encryptor.ephemeral + ":" + base64.StdEncoding.EncodeToString(sealed)
+}
+
+// DecryptFieldValue decrypt the encrypted field value to its json, privateKey is the x25519 private key of the recipient
+func DecryptFieldValue(value string, privateKey []byte) (json.RawMessage, error) {
+	match := fieldEncryptionRegexp.FindStringSubmatch(value)
+	if match == nil || match[0] != value || match[1] != "" || match[4] != "" {
+		return nil, errors.New("logger: value is not an encrypted field value")
+	}
+	return decryptFieldMatch(match, privateKey, map[string]cipher.AEAD{})
+}
+
+// DecryptFields decrypt encrypted field values of records (lines) of r to w, other text is unchanged
+// quoted values of json records are replaced by their json, values of text records by their text
+func DecryptFields(r io.Reader, w io.Writer, privateKey []byte) error {
+	ciphers := map[string]cipher.AEAD{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		var decryptErr error
+		line := fieldEncryptionRegexp.ReplaceAllStringFunc(scanner.Text(), func(token string) string {
+			match := fieldEncryptionRegexp.FindStringSubmatch(token)
+			plain, err := decryptFieldMatch(match, privateKey, ciphers)
+			if err != nil {
+				if decryptErr == nil {
+					decryptErr = err
+				}
+				return token
+			}
+			// json string value "enc:..." is replaced by the json value
+			if match[1] != "" && match[4] != "" {
+				return string(plain)
+			}
+			var text string
+			if json.Unmarshal(plain, &text) == nil {
+				return match[1] + text + match[4]
+			}
+			return match[1] + string(plain) + match[4]
+		})
+		if decryptErr != nil {
+			return errors.New("logger: unable decrypt field of line " + strconv.Itoa(lineNum) + ", error: " + decryptErr.Error())
+		}
+		_, err := io.WriteString(w, line+"\n")
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// decrypt the submatch of ephemeral key and sealed value, ciphers of ephemeral keys are cached
+func decryptFieldMatch(match []string, privateKey []byte, ciphers map[string]cipher.AEAD) (json.RawMessage, error) {
+	aead, ok := ciphers[match[2]]
+	if !ok {
+		var err error
+		aead, err = headerCipher(privateKey, match[2])
+		if err != nil {
+			return nil, err
+		}
+		ciphers[match[2]] = aead
+	}
+	sealed, err := base64.StdEncoding.DecodeString(match[3])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errors.New("illegal encrypted field value")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
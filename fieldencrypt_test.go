@@ -0,0 +1,74 @@
+package go_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SetFieldEncryptor(t *testing.T) {
+
+	publicKey, privateKey, err := GenerateEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptor, err := NewFieldEncryptor(publicKey, "ssn", "card_last4_plus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetFieldEncryptor(encryptor)
+
+	fields := map[string]interface{}{
+		"SSN":  "123-45-6789",
+		"user": map[string]interface{}{"name": "go", "card_last4_plus": 4242},
+	}
+	logger.WriterFields(LOGGER_LEVEL_INFO, "payment", fields)
+
+	entry := logger.Adapter("memory").(*AdapterMemory).Entries()[0]
+	ssn, _ := entry.Fields["SSN"].(string)
+	card, _ := entry.Fields["user"].(map[string]interface{})["card_last4_plus"].(string)
+	if !strings.HasPrefix(ssn, FIELD_ENCRYPTION_PREFIX) || !strings.HasPrefix(card, FIELD_ENCRYPTION_PREFIX) ||
+		entry.Fields["user"].(map[string]interface{})["name"] != "go" {
+		t.Fatalf("encrypt fields error: %v", entry.Fields)
+	}
+	if fields["SSN"] != "123-45-6789" {
+		t.Errorf("encrypt fields changed fields of caller: %v", fields)
+	}
+
+	value, err := DecryptFieldValue(ssn, privateKey)
+	if err != nil || string(value) != `"123-45-6789"` {
+		t.Errorf("decrypt field value error: %s, %s", value, err)
+	}
+
+	record, _ := json.Marshal(entry.Fields)
+	text := "payment ssn=" + ssn + " card=" + card
+	output := &bytes.Buffer{}
+	err = DecryptFields(strings.NewReader(string(record)+"\n"+text+"\n"), output, privateKey)
+	if err != nil {
+		t.Fatalf("decrypt fields error: %s", err)
+	}
+	expected := `{"SSN":"123-45-6789","user":{"card_last4_plus":4242,"name":"go"}}` + "\n" + "payment ssn=123-45-6789 card=4242\n"
+	if output.String() != expected {
+		t.Errorf("decrypt fields error: %q", output.String())
+	}
+
+	_, otherKey, _ := GenerateEncryptionKey()
+	if DecryptFields(strings.NewReader(text), &bytes.Buffer{}, otherKey) == nil {
+		t.Errorf("decrypt fields with other key must be error")
+	}
+}
+
+func TestNewFieldEncryptor(t *testing.T) {
+
+	publicKey, _, _ := GenerateEncryptionKey()
+	if _, err := NewFieldEncryptor(publicKey); err == nil {
+		t.Errorf("field encryptor without fields must be error")
+	}
+	if _, err := NewFieldEncryptor([]byte("short"), "ssn"); err == nil {
+		t.Errorf("field encryptor of illegal recipient must be error")
+	}
+}
@@ -0,0 +1,185 @@
+package go_logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// json types of field values
+const (
+	FIELD_TYPE_STRING = "string"
+	FIELD_TYPE_NUMBER = "number"
+	FIELD_TYPE_BOOL   = "bool"
+	FIELD_TYPE_OBJECT = "object"
+	FIELD_TYPE_ARRAY  = "array"
+	FIELD_TYPE_NULL   = "null"
+)
+
+// field type report, conflicting types of a field break index mappings (elasticsearch)
+type FieldTypeReport struct {
+
+	// types seen of every field, nested object fields are named "parent.child"
+	Fields map[string][]string
+
+	// fields logged with more than one type (null excluded), sorted
+	Conflicts []string
+}
+
+type fieldTypeTracker struct {
+	lock   sync.Mutex
+	fields map[string]map[string]bool
+}
+
+// track json types of message fields, a warning is printed to stderr when a field gets conflicting types
+func (logger *Logger) SetFieldTypeTracking(enabled bool) {
+	var tracker *fieldTypeTracker
+	if enabled {
+		tracker = &fieldTypeTracker{fields: map[string]map[string]bool{}}
+	}
+	logger.fieldTypes.Store(&tracker)
+}
+
+// field type report, empty if tracking is disabled
+func (logger *Logger) FieldTypeReport() FieldTypeReport {
+	report := FieldTypeReport{Fields: map[string][]string{}, Conflicts: []string{}}
+	tracker, ok := logger.fieldTypes.Load().(**fieldTypeTracker)
+	if !ok || *tracker == nil {
+		return report
+	}
+
+	(*tracker).lock.Lock()
+	defer (*tracker).lock.Unlock()
+	for name, types := range (*tracker).fields {
+		typeNames := make([]string, 0, len(types))
+		for typeName := range types {
+			typeNames = append(typeNames, typeName)
+		}
+		sort.Strings(typeNames)
+		report.Fields[name] = typeNames
+		if fieldTypesConflict(types) {
+			report.Conflicts = append(report.Conflicts, name)
+		}
+	}
+	sort.Strings(report.Conflicts)
+	return report
+}
+
+// track field types of the message
+func (logger *Logger) trackFieldTypes(loggerMsg *loggerMessage) {
+	if len(loggerMsg.Fields) == 0 {
+		return
+	}
+	tracker, ok := logger.fieldTypes.Load().(**fieldTypeTracker)
+	if !ok || *tracker == nil {
+		return
+	}
+	(*tracker).track("", loggerMsg.Fields)
+}
+
+func (tracker *fieldTypeTracker) track(prefix string, fields map[string]interface{}) {
+	for key, value := range fields {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		typeName := fieldType(value)
+
+		tracker.lock.Lock()
+		types := tracker.fields[name]
+		if types == nil {
+			types = map[string]bool{}
+			tracker.fields[name] = types
+		}
+		conflicted := fieldTypesConflict(types)
+		isNew := !types[typeName]
+		types[typeName] = true
+		if isNew && !conflicted && fieldTypesConflict(types) {
+			fmt.Fprintf(os.Stderr, "logger: field %s is logged with conflicting types %v\n", name, fieldTypeNames(types))
+		}
+		tracker.lock.Unlock()
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			tracker.track(name, nested)
+		}
+	}
+}
+
+func fieldTypesConflict(types map[string]bool) bool {
+	count := 0
+	for typeName := range types {
+		if typeName != FIELD_TYPE_NULL {
+			count++
+		}
+	}
+	return count > 1
+}
+
+func fieldTypeNames(types map[string]bool) []string {
+	typeNames := make([]string, 0, len(types))
+	for typeName := range types {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+	return typeNames
+}
+
+// json type of the first byte of json value
+func jsonType(first byte) string {
+	switch first {
+	case '"':
+		return FIELD_TYPE_STRING
+	case 't', 'f':
+		return FIELD_TYPE_BOOL
+	case 'n':
+		return FIELD_TYPE_NULL
+	case '{':
+		return FIELD_TYPE_OBJECT
+	case '[':
+		return FIELD_TYPE_ARRAY
+	}
+	return FIELD_TYPE_NUMBER
+}
+
+// json type of the value, same as encoding/json encodes it
+func fieldType(value interface{}) string {
+	if value == nil {
+		return FIELD_TYPE_NULL
+	}
+	if marshaler, ok := value.(json.Marshaler); ok {
+		data, err := marshaler.MarshalJSON()
+		if err == nil && len(data) > 0 {
+			return jsonType(data[0])
+		}
+	}
+	if _, ok := value.(encoding.TextMarshaler); ok {
+		return FIELD_TYPE_STRING
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return FIELD_TYPE_NULL
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return FIELD_TYPE_STRING
+	case reflect.Bool:
+		return FIELD_TYPE_BOOL
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return FIELD_TYPE_NUMBER
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return FIELD_TYPE_STRING
+		}
+		return FIELD_TYPE_ARRAY
+	}
+	return FIELD_TYPE_OBJECT
+}
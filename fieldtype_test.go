@@ -0,0 +1,39 @@
+package go_logger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLogger_FieldTypeReport(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+
+	logger.WriterFields(LOGGER_LEVEL_INFO, "untracked", map[string]interface{}{"id": "a"})
+	if len(logger.FieldTypeReport().Fields) != 0 {
+		t.Error("field type report must be empty if tracking is disabled")
+	}
+
+	logger.SetFieldTypeTracking(true)
+	logger.WriterFields(LOGGER_LEVEL_INFO, "first", map[string]interface{}{
+		"id": 1, "user": map[string]interface{}{"age": 10}, "cost": time.Second, "at": time.Now(), "ok": true, "tags": []string{"a"},
+	})
+	logger.WriterFields(LOGGER_LEVEL_INFO, "second", map[string]interface{}{
+		"id": "2", "user": map[string]interface{}{"age": nil}, "ok": false,
+	})
+
+	report := logger.FieldTypeReport()
+	if !reflect.DeepEqual(report.Conflicts, []string{"id"}) {
+		t.Errorf("field type conflicts error: %v", report.Conflicts)
+	}
+	if !reflect.DeepEqual(report.Fields["user.age"], []string{"null", "number"}) ||
+		!reflect.DeepEqual(report.Fields["cost"], []string{"number"}) ||
+		!reflect.DeepEqual(report.Fields["at"], []string{"string"}) ||
+		!reflect.DeepEqual(report.Fields["tags"], []string{"array"}) ||
+		!reflect.DeepEqual(report.Fields["user"], []string{"object"}) {
+		t.Errorf("field type report error: %v", report.Fields)
+	}
+}
@@ -1,16 +1,19 @@
 package go_logger
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/cipher"
 	"errors"
 	"github.com/phachon/go-logger/utils"
-	"io/ioutil"
+	"io"
 	"os"
-	"path"
-	"reflect"
-	"regexp"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -22,31 +25,125 @@ const (
 	FILE_SLICE_DATE_MONTH = "m"
 	FILE_SLICE_DATE_DAY   = "d"
 	FILE_SLICE_DATE_HOUR  = "h"
+	FILE_SLICE_DATE_WEEK  = "w"
 )
 
 const (
 	FILE_ACCESS_LEVEL = 1000
 )
 
+const FILE_REOPEN_DEFAULT_INTERVAL = time.Second
+
+const FILE_DEFAULT_FLUSH_INTERVAL = time.Second
+
+// file size of MaxSize is counted by writes and reconciled by stat every interval, eg: the file is truncated
+// compressed size of gzip files is known only by stat
+const FILE_SIZE_CHECK_INTERVAL = time.Second
+
+const (
+	FILE_DEFAULT_MODE     os.FileMode = 0666
+	FILE_DEFAULT_DIR_MODE os.FileMode = 0755
+)
+
 // adapter file
 type AdapterFile struct {
-	write  map[int]*FileWriter
-	config *FileConfig
+	write     map[int]*FileWriter
+	config    *FileConfig
+	quit      chan struct{}
+	schedule  *fileSchedule
+	diskState int32        // DISK_STATE_*, set by Logger.WatchDisk
+	diskWatch atomic.Value // *DiskWatchConfig of Logger.WatchDisk
+	diskPurge int32        // free space is below PurgeBelow
+
+	tenantLock sync.Mutex
+	tenants    map[string]*AdapterFile
+	shards     *tenantShards
 }
 
 // file writer
 type FileWriter struct {
+	encodingErrors int64 // json fallbacks of marshal errors
+
 	lock      sync.RWMutex
-	writer    *os.File
+	writer    File
 	startLine int64
 	startTime int64
 	filename  string
+	checkTime time.Time
+	checkSize int64
+	size      int64     // bytes of the file and buffer, counted by writes and reconciled by stat
+	sizeTime  time.Time // last stat of size
+	rotations int64     // sliced by date, lines or size
+	reopens   int64     // reopened after external rotation
+
+	buffer     *bufio.Writer
+	bufferSize int
+	gzip       bool
+	gzipWriter *gzip.Writer
+	fileMode   os.FileMode
+	dirMode    os.FileMode
+	createDirs bool
+	chown      bool
+	uid        int
+	gid        int
+
+	uploads *backupUploads // uploads of bak files, created if Uploader is set
+
+	checkpoint      bool
+	rotationHistory []FileCheckpointRotation // rotations of the checkpoint, loaded by first use
+
+	rotateEvents []RotateEvent // rotations fired after unlock
+
+	latest string // file of the date slice linked by Filename if SymlinkLatest is true
+
+	encryption *FileEncryption
+	aead       cipher.AEAD // cipher of records of the opened file
+
+	hashChain  *FileHashChain
+	chain      *hashChain // chain of the opened file, continued from its last record
+	lineEnding string
+
+	multiProcess bool
+	processLock  File  // lock file of MultiProcess, opened by first write
+	processSize  int64 // file size after the last write, lines are recounted if other processes wrote
+
+	clock Clock      // time of date slices and rotations
+	fs    FileSystem // operations of the file and its bak files
 }
 
 func NewFileWrite(fn string) *FileWriter {
 	return &FileWriter{
 		filename: fn,
+		fileMode: FILE_DEFAULT_MODE,
+		dirMode:  FILE_DEFAULT_DIR_MODE,
+		clock:    SystemClock,
+		fs:       OsFileSystem,
+	}
+}
+
+// new file writer with permissions of config
+func newFileWriteByConfig(fn string, config *FileConfig) *FileWriter {
+	fw := NewFileWrite(fn)
+	fw.fileMode = config.FileMode
+	fw.dirMode = config.DirMode
+	fw.createDirs = config.CreateDirs
+	fw.chown = config.Chown
+	fw.uid = config.Uid
+	fw.gid = config.Gid
+	fw.bufferSize = config.BufferSize
+	fw.gzip = config.Gzip
+	fw.checkpoint = config.Checkpoint
+	fw.encryption = config.Encryption
+	fw.multiProcess = config.MultiProcess
+	fw.hashChain = config.HashChain
+	fw.lineEnding = config.LineEnding
+	if config.Clock != nil {
+		fw.clock = config.Clock
+	}
+	if config.FileSystem != nil {
+		fw.fs = config.FileSystem
 	}
+	return fw
 }
 
 // file config
@@ -67,16 +164,151 @@ type FileConfig struct {
 	// max file bak
 	MaxBak int64
 
+	// max age of file bak, older bak files will be removed
+	// example: 30 * 24 * time.Hour keep 30 days
+	MaxAge time.Duration
+
+	// max total size of file bak, same unit as MaxSize
+	// the oldest bak files are removed until the total size is under it
+	MaxTotalSize int64
+
+	// move bak files removed by MaxBak, MaxAge and MaxTotalSize to TrashDir instead of removing them
+	// relative TrashDir is in the directory of Filename, eg: "trash"
+	// files in TrashDir are removed after TrashMaxAge, default 7 days
+	TrashDir    string
+	TrashMaxAge time.Duration
+
+	// gzip bak files moved to TrashDir
+	TrashCompress bool
+
+	// template of bak file names, fields of BackupNameData, eg: "{{.Name}}.{{.Time}}.{{.Seq}}{{.Ext}}"
+	// default "{{.Name}}_{{.Time}}{{.Ext}}" sliced by date, "{{.Name}}.{{.Time}}{{.Ext}}" sliced by lines or size
+	// and "{{.Name}}_{{.Time}}.{{.Seq}}{{.Ext}}" sliced by date with lines or size
+	BackupName string
+
+	// time layout of .Time, numeric layouts only
+	// default "2006", "200601", "20060102", "2006010215", "20060102" (week) sliced by date, "2006-01-02-15.04.05.9999" sliced by lines or size
+	BackupTimeFormat string
+
+	// move rotated files to BackupDir (same volume), relative BackupDir is in the directory of Filename, eg: "backup"
+	BackupDir string
+
+	// upload rotated bak files in background, bak files are removed by MaxBak, MaxAge and MaxTotalSize only after they are uploaded
+	// uploaded files are recorded in Filename + ".manifest", see ReadBackupManifest()
+	Uploader BackupUploader
+
+	// write the current file, its inode and rotated files to Filename + ".checkpoint" for tailing agents
+	// see ReadFileCheckpoint()
+	Checkpoint bool
+
+	// encrypt every record with an aes-gcm key or for a x25519 recipient, see DecryptLog()
+	Encryption *FileEncryption
+
+	// chain every record to the previous one by its hash for tamper-evident audit files, see VerifyHashChain()
+	HashChain *FileHashChain
+
+	// called after a slice completes, oldPath is moved to the bak file newPath and recreated
+	// called after the file is unlocked, see NotifyRotate() to receive rotations of all files
+	OnRotate func(oldPath, newPath string)
+
 	// file slice by date
 	// "y" Log files are cut through year
 	// "m" Log files are cut through mouth
 	// "d" Log files are cut through day
 	// "h" Log files are cut through hour
+	// "w" Log files are cut through week, bak files are named by the first day of the week
+	// with MaxSize or MaxLine, bak files of the date are numbered, eg: "app_20240101.1.log", "app_20240101.2.log"
 	DateSlice string
 
+	// first day of the week of DateSlice "w", default time.Sunday
+	WeekStart time.Weekday
+
+	// rotate at the start of every DateSlice (midnight of "d", top of the hour of "h") even if nothing is written,
+	// so the file of the previous period is always closed, DateSlice alone rotates on the next write
+	ScheduledRotate bool
+
+	// rotate at the times of the cron expression "minute hour day month weekday" even if nothing is written
+	// eg: "0 */6 * * *", "30 2 * * 1-5", "@daily"
+	RotateCron string
+
+	// write the file of the date slice and keep Filename a symlink to it, eg: "app.log" -> "app_20240510.log"
+	// files are not renamed by slices, DateSlice must be set
+	SymlinkLatest bool
+
+	// suffix filenames with deploy tag, eg: "app.log" is "app.canary.log"
+	DeploySuffix bool
+
+	// write messages of every tenant to separate files, eg: "app.log" is "app.acme.log"
+	TenantShard bool
+
+	// max tenant files, messages of new tenants over it are written to the default files, default 100
+	MaxTenants int
+
+	// max json record size (byte), bigger records are split into parts by body, 0 is unlimited
+	// parts have fields "part_id", "part" and "parts", ReassembleEntries() joins them
+	MaxRecordSize int
+
+	// buffer writes in memory (byte), buffered data is written every FlushInterval, 0 is unbuffered
+	BufferSize    int
+	FlushInterval time.Duration
+
+	// write files gzip compressed, eg: Filename "app.log.gz"
+	// compressed data is flushed every FlushInterval (default 1s), the file can be read by zcat at any time
+	Gzip bool
+
+	// fsync after every write, for durability-sensitive logs (audit)
+	SyncOnWrite bool
+
+	// fsync every interval, 0 is disabled
+	SyncInterval time.Duration
+
+	// strip ansi escapes and control characters (except "\t" and "\n") from body and fields
+	StripControl bool
+
+	// line ending of records, LINE_ENDING_LF or LINE_ENDING_CRLF, default "\r\n" on windows, otherwise "\n"
+	LineEnding string
+
+	// encode records before they are written, eg: simplifiedchinese.GBK.NewEncoder(), default utf-8
+	Encoder TextEncoder
+
+	// permission of created files, default 0666 (before umask)
+	FileMode os.FileMode
+
+	// create missing parent directories with DirMode, default 0755 (before umask)
+	CreateDirs bool
+	DirMode    os.FileMode
+
+	// change owner of created files and directories to Uid and Gid, not supported on windows
+	Chown bool
+	Uid   int
+	Gid   int
+
+	// re-stat files every interval, reopen it if renamed or removed by external rotation (logrotate)
+	// default 1s, negative is disabled
+	ReopenInterval time.Duration
+
+	// processes and logger instances sharing the file write and rotate it in turn by an advisory lock of Filename + ".lock"
+	// others reopen the file rotated by one of them, buffered data is written before unlocked, ReopenInterval is ignored
+	// cannot be used with Gzip, SymlinkLatest or Encryption for a Recipient
+	MultiProcess bool
+
+	// clock of date slices, bak file times and MaxAge, default SystemClock
+	// example: go_logger.NewFrozenClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	Clock Clock
+
+	// operations of the file and bak files, default OsFileSystem
+	FileSystem FileSystem
+
+	// formatter of messages, JsonFormat and Format are ignored if it's set
+	// example: &go_logger.LogfmtFormatter{}
+	Formatter Formatter
+
 	// is json format
 	JsonFormat bool
 
+	// is html format, every message is a <div> of Format text colored by level
+	HtmlFormat bool
+
 	// jsonFormat is false, please input format string
 	// if format is empty, default format "%millisecond_format% [%level_string%] %body%"
 	//
@@ -90,9 +322,23 @@ type FileConfig struct {
 	//	File string "%file%"
 	//	Line int "%line%"
 	//	Function "%function%"
+	//	Fields "%fields%"
+	//	TraceId "%trace_id%"
+	//	SpanId "%span_id%"
+	//	Deploy "%deploy%"
+	//	Component "%component%"
+	//	Stacktrace "%stacktrace%"
 	//
 	// example: format = "%millisecond_format% [%level_string%] %body%"
 	Format string
+
+	// format of levels, overrides Format and JsonFormat of the level
+	// example: LevelFormat = map[int]string{go_logger.LOGGER_LEVEL_ERROR: "%millisecond_format% [%level_string%] [%file%:%line% %function%] %body%\n%stacktrace%"}
+	LevelFormat map[int]string
+
+	// json format of levels, overrides JsonFormat and LevelFormat of the level
+	// example: LevelJsonFormat = map[int]bool{go_logger.LOGGER_LEVEL_DEBUG: true}
+	LevelJsonFormat map[int]bool
 }
 
 func (fc *FileConfig) Name() string {
@@ -104,23 +350,23 @@ var fileSliceDateMapping = map[string]int{
 	FILE_SLICE_DATE_MONTH: 1,
 	FILE_SLICE_DATE_DAY:   2,
 	FILE_SLICE_DATE_HOUR:  3,
+	FILE_SLICE_DATE_WEEK:  4,
 }
 
 func NewAdapterFile() LoggerAbstract {
 	return &AdapterFile{
-		write:  map[int]*FileWriter{},
-		config: &FileConfig{},
+		write:   map[int]*FileWriter{},
+		config:  &FileConfig{},
+		tenants: map[string]*AdapterFile{},
 	}
 }
 
 // init
 func (adapterFile *AdapterFile) Init(fileConfig Config) error {
-	if fileConfig.Name() != FILE_ADAPTER_NAME {
-		return errors.New("logger file adapter init error, config must FileConfig")
+	fc, ok := fileConfig.(*FileConfig)
+	if !ok || fc == nil {
+		return configTypeError(FILE_ADAPTER_NAME, "FileConfig")
 	}
-
-	vc := reflect.ValueOf(fileConfig)
-	fc := vc.Interface().(*FileConfig)
 	adapterFile.config = fc
 
 	if fc.JsonFormat == false && fc.Format == "" {
@@ -129,41 +375,208 @@ func (adapterFile *AdapterFile) Init(fileConfig Config) error {
 
 	if len(adapterFile.config.LevelFileName) == 0 {
 		if adapterFile.config.Filename == "" {
-			return errors.New("config Filename can't be empty!")
+			return configError(FILE_ADAPTER_NAME, "Filename", "can't be empty!")
+		}
+	}
+	if fc.MaxSize < 0 || fc.MaxLine < 0 || fc.MaxBak < 0 {
+		field := "MaxSize"
+		if fc.MaxLine < 0 {
+			field = "MaxLine"
+		} else if fc.MaxBak < 0 {
+			field = "MaxBak"
+		}
+		return configError(FILE_ADAPTER_NAME, field, "must not be negative!")
+	}
+	if fc.MaxSize > 0 && int64(fc.MaxRecordSize) > fc.MaxSize*1024 {
+		return configError(FILE_ADAPTER_NAME, "MaxRecordSize", "must not be larger than MaxSize!")
+	}
+	_, ok = fileSliceDateMapping[adapterFile.config.DateSlice]
+	if !ok && adapterFile.config.DateSlice != FILE_SLICE_DATE_NULL {
+		return configError(FILE_ADAPTER_NAME, "DateSlice", "must be one of the 'y', 'd', 'm','h','w'!")
+	}
+	if adapterFile.config.WeekStart < time.Sunday || adapterFile.config.WeekStart > time.Saturday {
+		return configError(FILE_ADAPTER_NAME, "WeekStart", "is illegal!")
+	}
+	if adapterFile.config.SymlinkLatest && adapterFile.config.DateSlice == FILE_SLICE_DATE_NULL {
+		return configError(FILE_ADAPTER_NAME, "SymlinkLatest", "must be used with DateSlice!")
+	}
+	if fc.ScheduledRotate && (fc.DateSlice == FILE_SLICE_DATE_NULL || fc.RotateCron != "") {
+		return configError(FILE_ADAPTER_NAME, "ScheduledRotate", "must be used with DateSlice and without RotateCron!")
+	}
+	schedule, err := newFileSchedule(fc)
+	if err != nil {
+		return err
+	}
+	adapterFile.schedule = schedule
+	if fc.FileMode == 0 {
+		fc.FileMode = FILE_DEFAULT_MODE
+	}
+	if fc.DirMode == 0 {
+		fc.DirMode = FILE_DEFAULT_DIR_MODE
+	}
+	if fc.BackupName != "" {
+		_, err := backupNameTemplate(fc.BackupName)
+		if err != nil {
+			return err
 		}
 	}
-	_, ok := fileSliceDateMapping[adapterFile.config.DateSlice]
-	if !ok {
-		return errors.New("config DateSlice must be one of the 'y', 'd', 'm','h'!")
+	if fc.Encryption != nil {
+		err := fc.Encryption.validate()
+		if err != nil {
+			return err
+		}
+	}
+	if err := checkLineEnding(&fc.LineEnding); err != nil {
+		return err
+	}
+	if err := checkLevelFormat(fc.LevelFormat, fc.LevelJsonFormat); err != nil {
+		return err
+	}
+	if err := checkFormatPlaceholders(fc.Format, fc.LevelFormat); err != nil {
+		return err
+	}
+	if fc.MultiProcess && (fc.Gzip || fc.SymlinkLatest || (fc.Encryption != nil && len(fc.Encryption.Recipient) > 0)) {
+		return configError(FILE_ADAPTER_NAME, "MultiProcess", "cannot be used with Gzip, SymlinkLatest or Encryption Recipient!")
+	}
+	if fc.HashChain != nil && (fc.Encryption != nil || fc.MultiProcess) {
+		return configError(FILE_ADAPTER_NAME, "HashChain", "cannot be used with Encryption or MultiProcess!")
 	}
 
 	// init FileWriter
 	if len(adapterFile.config.LevelFileName) > 0 {
 		fileWriters := map[int]*FileWriter{}
 		for level, filename := range adapterFile.config.LevelFileName {
-			_, ok := levelStringMapping[level]
+			_, ok := levelNames()[level]
 			if !ok {
-				return errors.New("config LevelFileName key level is illegal!")
+				return configError(FILE_ADAPTER_NAME, "LevelFileName", "key level is illegal!")
+			}
+			if fc.DeploySuffix {
+				filename = deploySuffixFilename(filename)
+			}
+			fw := newFileWriteByConfig(filename, fc)
+			if fc.SymlinkLatest {
+				err := fw.linkLatest(fc, fw.clock.Now(), true)
+				if err != nil {
+					return err
+				}
+			}
+			err := fw.initFile()
+			if err != nil {
+				return configErrorCause(FILE_ADAPTER_NAME, "LevelFileName", filename+" is not writable", err)
 			}
-			fw := NewFileWrite(filename)
-			fw.initFile()
 			fileWriters[level] = fw
 		}
 		adapterFile.write = fileWriters
 	}
 
 	if adapterFile.config.Filename != "" {
-		fw := NewFileWrite(adapterFile.config.Filename)
-		fw.initFile()
+		filename := adapterFile.config.Filename
+		if fc.DeploySuffix {
+			filename = deploySuffixFilename(filename)
+		}
+		fw := newFileWriteByConfig(filename, fc)
+		if fc.SymlinkLatest {
+			err := fw.linkLatest(fc, fw.clock.Now(), true)
+			if err != nil {
+				return err
+			}
+		}
+		err := fw.initFile()
+		if err != nil {
+			return configErrorCause(FILE_ADAPTER_NAME, "Filename", filename+" is not writable", err)
+		}
 		adapterFile.write[FILE_ACCESS_LEVEL] = fw
 	}
 
+	if fc.TenantShard {
+		adapterFile.shards = newTenantShards(fc.MaxTenants)
+	}
+	if fc.ReopenInterval == 0 {
+		fc.ReopenInterval = FILE_REOPEN_DEFAULT_INTERVAL
+	}
+	if (fc.BufferSize > 0 || fc.Gzip) && fc.FlushInterval <= 0 {
+		fc.FlushInterval = FILE_DEFAULT_FLUSH_INTERVAL
+	}
+	if fc.BufferSize > 0 || fc.Gzip || fc.SyncInterval > 0 {
+		adapterFile.quit = make(chan struct{})
+		go adapterFile.startFlush(adapterFile.quit)
+	}
+	if adapterFile.schedule != nil {
+		if adapterFile.quit == nil {
+			adapterFile.quit = make(chan struct{})
+		}
+		go adapterFile.startSchedule(adapterFile.schedule, adapterFile.quit)
+	}
+
 	return nil
 }
 
+// file adapter of the tenant, files are suffixed with tenant
+func (adapterFile *AdapterFile) tenantAdapter(tenant string) (*AdapterFile, error) {
+	adapterFile.tenantLock.Lock()
+	defer adapterFile.tenantLock.Unlock()
+
+	tenantFile, ok := adapterFile.tenants[tenant]
+	if ok {
+		return tenantFile, nil
+	}
+
+	tenantConfig := *adapterFile.config
+	tenantConfig.TenantShard = false
+	if tenantConfig.Filename != "" {
+		tenantConfig.Filename = suffixFilename(tenantConfig.Filename, tenant)
+	}
+	if len(tenantConfig.LevelFileName) > 0 {
+		tenantConfig.LevelFileName = map[int]string{}
+		for level, filename := range adapterFile.config.LevelFileName {
+			tenantConfig.LevelFileName[level] = suffixFilename(filename, tenant)
+		}
+	}
+
+	tenantFile = NewAdapterFile().(*AdapterFile)
+	err := tenantFile.Init(&tenantConfig)
+	if err != nil {
+		return nil, err
+	}
+	adapterFile.tenants[tenant] = tenantFile
+	return tenantFile, nil
+}
+
+// tenant file adapters
+func (adapterFile *AdapterFile) tenantAdapters() []*AdapterFile {
+	adapterFile.tenantLock.Lock()
+	defer adapterFile.tenantLock.Unlock()
+
+	tenantFiles := make([]*AdapterFile, 0, len(adapterFile.tenants))
+	for _, tenantFile := range adapterFile.tenants {
+		tenantFiles = append(tenantFiles, tenantFile)
+	}
+	return tenantFiles
+}
+
 // Write
 func (adapterFile *AdapterFile) Write(loggerMsg *loggerMessage) error {
 
+	if adapterFile.diskDropped(loggerMsg) {
+		return adapterFile.diskDivert(loggerMsg)
+	}
+
+	if adapterFile.config.StripControl {
+		loggerMsg = sanitizeLoggerMessage(loggerMsg)
+	}
+
+	// tenant file write
+	if adapterFile.shards != nil {
+		tenant := messageTenant(loggerMsg)
+		if adapterFile.shards.allow(tenant) {
+			tenantFile, err := adapterFile.tenantAdapter(tenant)
+			if err != nil {
+				return err
+			}
+			return tenantFile.Write(loggerMsg)
+		}
+	}
+
 	var accessChan = make(chan error, 1)
 	var levelChan = make(chan error, 1)
 
@@ -218,11 +631,115 @@ func (adapterFile *AdapterFile) Write(loggerMsg *loggerMessage) error {
 	return nil
 }
 
-// Flush
+// flush buffers every FlushInterval and fsync every SyncInterval
+func (adapterFile *AdapterFile) startFlush(quit chan struct{}) {
+	var flushChan, syncChan <-chan time.Time
+	if adapterFile.config.BufferSize > 0 || adapterFile.config.Gzip {
+		flushTicker := time.NewTicker(adapterFile.config.FlushInterval)
+		defer flushTicker.Stop()
+		flushChan = flushTicker.C
+	}
+	if adapterFile.config.SyncInterval > 0 {
+		syncTicker := time.NewTicker(adapterFile.config.SyncInterval)
+		defer syncTicker.Stop()
+		syncChan = syncTicker.C
+	}
+	for {
+		select {
+		case <-flushChan:
+			for _, fileWrite := range adapterFile.write {
+				fileWrite.lock.Lock()
+				fileWrite.flushBuffer()
+				fileWrite.lock.Unlock()
+			}
+		case <-syncChan:
+			for _, fileWrite := range adapterFile.write {
+				fileWrite.lock.Lock()
+				fileWrite.flushBuffer()
+				fileWrite.writer.Sync()
+				fileWrite.lock.Unlock()
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Flush, commit written data to disk
 func (adapterFile *AdapterFile) Flush() {
 	for _, fileWrite := range adapterFile.write {
-		fileWrite.writer.Close()
+		fileWrite.lock.Lock()
+		fileWrite.flushBuffer()
+		fileWrite.writer.Sync()
+		fileWrite.lock.Unlock()
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		tenantFile.Flush()
+	}
+}
+
+// Reopen files, call it after files are rotated by external tools
+func (adapterFile *AdapterFile) Reopen() error {
+	var reopenErr error
+	for _, fileWrite := range adapterFile.write {
+		fileWrite.lock.Lock()
+		err := fileWrite.reopen()
+		fileWrite.lock.Unlock()
+		if err != nil && reopenErr == nil {
+			reopenErr = err
+		}
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		err := tenantFile.Reopen()
+		if err != nil && reopenErr == nil {
+			reopenErr = err
+		}
 	}
+	return reopenErr
+}
+
+// Close file handles
+func (adapterFile *AdapterFile) Close() error {
+	if adapterFile.quit != nil {
+		close(adapterFile.quit)
+		adapterFile.quit = nil
+	}
+	var closeErr error
+	for _, fileWrite := range adapterFile.write {
+		fileWrite.lock.Lock()
+		err := fileWrite.closeFile()
+		fileWrite.closeProcessLock()
+		fileWrite.lock.Unlock()
+		fileWrite.waitUploads()
+		if err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		err := tenantFile.Close()
+		if err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
+
+// Counters of rotations and reopens of all files
+func (adapterFile *AdapterFile) Counters() map[string]int64 {
+	counters := map[string]int64{"rotations": 0, "reopens": 0, COUNTER_ENCODING_ERRORS: 0}
+	for _, fileWrite := range adapterFile.write {
+		fileWrite.lock.Lock()
+		counters["rotations"] += fileWrite.rotations
+		counters["reopens"] += fileWrite.reopens
+		fileWrite.lock.Unlock()
+		counters[COUNTER_ENCODING_ERRORS] += atomic.LoadInt64(&fileWrite.encodingErrors)
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		for name, count := range tenantFile.Counters() {
+			counters[name] += count
+		}
+	}
+	return counters
 }
 
 // Name
@@ -230,23 +747,51 @@ func (adapterFile *AdapterFile) Name() string {
 	return FILE_ADAPTER_NAME
 }
 
+// buffered and gzip files need flush
+func (adapterFile *AdapterFile) Capabilities() Capabilities {
+	return Capabilities{
+		Binary:     adapterFile.config.Gzip,
+		NeedsFlush: adapterFile.config.BufferSize > 0 || adapterFile.config.Gzip,
+	}
+}
+
+// the directory of files is writable and the disk is not stopped by WatchDisk
+func (adapterFile *AdapterFile) HealthCheck() error {
+	if adapterFile.DiskState() == DISK_STATE_STOP {
+		return errors.New("disk space is low, file writes are stopped")
+	}
+	dirPath := adapterFile.dirPath()
+	if dirPath == "" {
+		return nil
+	}
+	err := healthCheckWritable(dirPath)
+	if err != nil {
+		return err
+	}
+	free, err := diskFreeSpace(dirPath)
+	if err == nil && free == 0 {
+		return errors.New("disk of " + dirPath + " is full")
+	}
+	return nil
+}
+
 // init file
 func (fw *FileWriter) initFile() error {
 
 	//check file exits, otherwise create a file
-	ok, _ := utils.UtilFile.PathExists(fw.filename)
-	if ok == false {
-		err := utils.UtilFile.CreateFile(fw.filename)
+	_, err := fw.fs.Stat(fw.filename)
+	if os.IsNotExist(err) {
+		err := fw.createFile()
 		if err != nil {
 			return err
 		}
 	}
 
 	// get start time
-	fw.startTime = time.Now().Unix()
+	fw.startTime = fw.clock.Now().Unix()
 
 	// get file start lines
-	nowLines, err := utils.UtilFile.GetFileLines(fw.filename)
+	nowLines, err := fw.getFileLines()
 	if err != nil {
 		return err
 	}
@@ -258,279 +803,579 @@ func (fw *FileWriter) initFile() error {
 		return err
 	}
 	fw.writer = file
+
+	// a new gzip member is appended to the file, gzip readers read all members
+	var output io.Writer = file
+	fw.gzipWriter = nil
+	if fw.gzip {
+		fw.gzipWriter = gzip.NewWriter(file)
+		output = fw.gzipWriter
+	}
+	fw.buffer = nil
+	if fw.bufferSize > 0 {
+		fw.buffer = bufio.NewWriterSize(output, fw.bufferSize)
+	}
+	fw.reconcileSize()
+	if fw.hashChain != nil {
+		err = fw.continueChain()
+		if err != nil {
+			return err
+		}
+	}
+	if fw.encryption != nil {
+		aead, header, err := fw.encryption.fileCipher()
+		if err != nil {
+			return err
+		}
+		fw.aead = aead
+		if header != "" {
+			err = fw.writeString(header)
+			if err != nil {
+				return err
+			}
+			fw.countSize(header)
+			fw.startLine++
+		}
+	}
+	fw.writeCheckpoint()
 	return nil
 }
 
+//write data to buffer, gzip writer or file
+func (fw *FileWriter) writeString(msg string) error {
+	var err error
+	if fw.buffer != nil {
+		_, err = fw.buffer.WriteString(msg)
+	} else if fw.gzipWriter != nil {
+		_, err = fw.gzipWriter.Write([]byte(msg))
+	} else {
+		_, err = fw.writer.Write([]byte(msg))
+	}
+	return err
+}
+
+//write buffered data to file, compressed data is flushed to a point readers can decompress
+func (fw *FileWriter) flushBuffer() error {
+	if fw.buffer != nil {
+		err := fw.buffer.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	if fw.gzipWriter != nil {
+		return fw.gzipWriter.Flush()
+	}
+	return nil
+}
+
+//get file lines, lines of decompressed data if gzip
+func (fw *FileWriter) getFileLines() (int64, error) {
+	file, err := fw.fs.OpenFile(fw.filename, os.O_RDONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if !fw.gzip {
+		return utils.UtilFile.GetReaderLines(file)
+	}
+
+	lines := int64(1)
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		// empty file or not gzip data
+		return lines, nil
+	}
+	defer gzipReader.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := gzipReader.Read(buf)
+		lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+		if err != nil {
+			// a member without trailer is read as far as possible
+			return lines, nil
+		}
+	}
+}
+
+//flush buffer and close file, chained files are sealed
+func (fw *FileWriter) closeFile() error {
+	if fw.chain != nil {
+		fw.writeString(fw.chain.chain(FILE_HASH_CHAIN_SEAL) + fw.lineEnding)
+		fw.chain = nil
+	}
+	err := fw.flushBuffer()
+	if fw.gzipWriter != nil {
+		gzipErr := fw.gzipWriter.Close()
+		if err == nil {
+			err = gzipErr
+		}
+	}
+	closeErr := fw.writer.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
 // write by config
 func (fw *FileWriter) writeByConfig(config *FileConfig, loggerMsg *loggerMessage) error {
 
 	fw.lock.Lock()
-	defer fw.lock.Unlock()
+	defer func() {
+		events := fw.rotateEvents
+		fw.rotateEvents = nil
+		fw.lock.Unlock()
+		fireRotateEvents(config, events)
+	}()
+
+	if fw.multiProcess {
+		unlock, err := fw.lockProcesses()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		err = fw.syncProcesses()
+		if err != nil {
+			return err
+		}
+	} else if config.ReopenInterval > 0 && fw.clock.Now().Sub(fw.checkTime) >= config.ReopenInterval {
+		err := fw.checkRotated()
+		if err != nil {
+			return err
+		}
+	}
 
 	if config.DateSlice != "" {
 		// file slice by date
-		err := fw.sliceByDate(config.DateSlice, config.MaxBak)
+		err := fw.sliceByDate(config.DateSlice, config)
 		if err != nil {
 			return err
 		}
 	}
 	if config.MaxLine != 0 {
 		// file slice by line
-		err := fw.sliceByFileLines(config.MaxLine, config.MaxBak)
+		err := fw.sliceByFileLines(config.MaxLine, config)
 		if err != nil {
 			return err
 		}
 	}
 	if config.MaxSize != 0 {
 		// file slice by size
-		err := fw.sliceByFileSize(config.MaxSize, config.MaxBak)
+		err := fw.sliceByFileSize(config.MaxSize, config)
 		if err != nil {
 			return err
 		}
 	}
 
 	msg := ""
-	if config.JsonFormat == true {
+	format, jsonFormat := levelFormat(loggerMsg.Level, config.Format, config.JsonFormat, config.LevelFormat, config.LevelJsonFormat)
+	if config.Formatter != nil {
+		msg = formatterFormat(config.Formatter, loggerMsg) + config.LineEnding
+	} else if jsonFormat == true {
 		//jsonByte, _ := json.Marshal(loggerMsg)
-		jsonByte, _ := loggerMsg.MarshalJSON()
-		msg = string(jsonByte) + "\r\n"
+		for _, partMsg := range splitLoggerMessage(loggerMsg, config.MaxRecordSize) {
+			jsonByte := marshalLoggerMessage(partMsg, &fw.encodingErrors)
+			msg += string(jsonByte) + config.LineEnding
+		}
+	} else if config.HtmlFormat == true {
+		msg = loggerMessageHtml(format, loggerMsg) + config.LineEnding
 	} else {
-		msg = loggerMessageFormat(config.Format, loggerMsg) + "\r\n"
+		msg = loggerMessageFormat(format, loggerMsg) + config.LineEnding
+	}
+	lines := int64(strings.Count(msg, "\n"))
+	if jsonFormat == true {
+		lines = int64(strings.Count(msg, config.LineEnding))
 	}
 
-	fw.writer.Write([]byte(msg))
-	if config.MaxLine != 0 {
-		if config.JsonFormat == true {
-			fw.startLine += 1
-		} else {
-			fw.startLine += int64(strings.Count(msg, "\n"))
+	msg, err := encodeText(config.Encoder, msg)
+	if err != nil {
+		return err
+	}
+	if fw.chain != nil {
+		msg = fw.chain.chainRecords(msg, config.LineEnding, jsonFormat)
+	}
+	if fw.aead != nil {
+		encrypted, err := encryptRecord(fw.aead, msg)
+		if err != nil {
+			return err
 		}
+		msg = encrypted
+	}
+
+	err = fw.writeString(msg)
+	if err != nil {
+		return err
+	}
+	fw.countSize(msg)
+	if config.SyncOnWrite {
+		err = fw.flushBuffer()
+		if err != nil {
+			return err
+		}
+		err = fw.writer.Sync()
+		if err != nil {
+			return err
+		}
+	}
+	if config.MaxLine != 0 {
+		fw.startLine += lines
+	}
+	if fw.multiProcess {
+		return fw.releaseProcesses()
 	}
 	return nil
 }
 
 //slice file by date (y, m, d, h, i, s), rename file is file_time.log and recreate file
-func (fw *FileWriter) sliceByDate(dataSlice string, maxBak int64) error {
+func (fw *FileWriter) sliceByDate(dataSlice string, config *FileConfig) error {
 
-	filename := fw.filename
-	filenameSuffix := path.Ext(filename)
 	startTime := time.Unix(fw.startTime, 0)
-	nowTime := time.Now()
+	nowTime := fw.clock.Now()
 
-	oldFilename := ""
 	isHaveSlice := false
-	timeFormat := ""
 	if (dataSlice == FILE_SLICE_DATE_YEAR) &&
 		(startTime.Year() != nowTime.Year()) {
 		isHaveSlice = true
-		timeFormat = "2006"
-		oldFilename = strings.Replace(filename, filenameSuffix, "", 1) + "_" + startTime.Format(timeFormat) + filenameSuffix
 	}
 	if (dataSlice == FILE_SLICE_DATE_MONTH) &&
 		(startTime.Format("200601") != nowTime.Format("200601")) {
 		isHaveSlice = true
-		timeFormat = "200601"
-		oldFilename = strings.Replace(filename, filenameSuffix, "", 1) + "_" + startTime.Format(timeFormat) + filenameSuffix
 	}
 	if (dataSlice == FILE_SLICE_DATE_DAY) &&
 		(startTime.Format("20060102") != nowTime.Format("20060102")) {
 		isHaveSlice = true
-		timeFormat = "20060102"
-		oldFilename = strings.Replace(filename, filenameSuffix, "", 1) + "_" + startTime.Format(timeFormat) + filenameSuffix
 	}
 	if (dataSlice == FILE_SLICE_DATE_HOUR) &&
-		(startTime.Format("2006010215") != startTime.Format("2006010215")) {
+		(startTime.Format("2006010215") != nowTime.Format("2006010215")) {
+		isHaveSlice = true
+	}
+	if (dataSlice == FILE_SLICE_DATE_WEEK) &&
+		!weekStart(startTime, config.WeekStart).Equal(weekStart(nowTime, config.WeekStart)) {
 		isHaveSlice = true
-		timeFormat = "2006010215"
-		oldFilename = strings.Replace(filename, filenameSuffix, "", 1) + "_" + startTime.Format(timeFormat) + filenameSuffix
 	}
 
 	if isHaveSlice == true {
-
-		// check bak num
-		if maxBak > 0 {
-			err := fw.cleanUpBackupFiles(maxBak, timeFormat)
-			if err != nil {
-				return err
-			}
-		}
-
-		//close file handle
-		fw.writer.Close()
-		err := os.Rename(fw.filename, oldFilename)
-		if err != nil {
-			return err
-		}
-		err = fw.initFile()
-		if err != nil {
-			return err
-		}
+		return fw.rotate(config, fileSliceTimeFormats[dataSlice], fw.sliceStart(config))
 	}
 
 	return nil
 }
 
 //slice file by line, if maxLine < fileLine, rename file is file_line_maxLine_time.log and recreate file
-func (fw *FileWriter) sliceByFileLines(maxLine int64, maxBak int64) error {
+func (fw *FileWriter) sliceByFileLines(maxLine int64, config *FileConfig) error {
 
-	filename := fw.filename
-	filenameSuffix := path.Ext(filename)
-	startLine := fw.startLine
-	timeFormat := "2006-01-02-15.04.05.9999"
+	if fw.startLine >= maxLine {
+		return fw.rotateBySize(config)
+	}
 
-	if startLine >= maxLine {
+	return nil
+}
 
-		// check bak num
-		if maxBak > 0 {
-			err := fw.cleanUpBackupFiles(maxBak, timeFormat)
-			if err != nil {
-				return err
-			}
-		}
+//slice file by size, if maxSize < fileSize, rename file is file_size_maxSize_time.log and recreate file
+//size is counted by writes and reconciled by stat every FILE_SIZE_CHECK_INTERVAL
+func (fw *FileWriter) sliceByFileSize(maxSize int64, config *FileConfig) error {
 
-		//close file handle
-		fw.writer.Close()
-		timeFlag := time.Now().Format(timeFormat)
-		oldFilename := strings.Replace(filename, filenameSuffix, "", 1) + "." + timeFlag + filenameSuffix
-		err := os.Rename(filename, oldFilename)
-		if err != nil {
-			return err
-		}
-		err = fw.initFile()
-		if err != nil {
-			return err
-		}
+	if fw.clock.Now().Sub(fw.sizeTime) >= FILE_SIZE_CHECK_INTERVAL {
+		fw.reconcileSize()
+	}
+
+	if fw.size/1024 >= maxSize {
+		return fw.rotateBySize(config)
 	}
 
 	return nil
 }
 
-//slice file by size, if maxSize < fileSize, rename file is file_size_maxSize_time.log and recreate file
-func (fw *FileWriter) sliceByFileSize(maxSize int64, maxBak int64) error {
+// rotate by lines or size, bak files of DateSlice are numbered in the date, eg: "app_20240101.1.log"
+func (fw *FileWriter) rotateBySize(config *FileConfig) error {
+	if config.DateSlice != "" {
+		return fw.rotate(config, fileSliceTimeFormats[config.DateSlice], fw.sliceStart(config))
+	}
+	return fw.rotate(config, FILE_BACKUP_TIME_FORMAT, fw.clock.Now())
+}
 
-	filename := fw.filename
-	filenameSuffix := path.Ext(filename)
-	nowSize, _ := fw.getFileSize(filename)
-	timeFormat := "2006-01-02-15.04.05.9999"
+// start of the date slice of the file, first day of the week sliced by week
+func (fw *FileWriter) sliceStart(config *FileConfig) time.Time {
+	startTime := time.Unix(fw.startTime, 0)
+	if config.DateSlice == FILE_SLICE_DATE_WEEK {
+		return weekStart(startTime, config.WeekStart)
+	}
+	return startTime
+}
 
-	if nowSize >= maxSize {
-		// check bak num
-		if maxBak > 0 {
-			err := fw.cleanUpBackupFiles(maxBak, timeFormat)
-			if err != nil {
-				return err
-			}
-		}
+// first day of the week of t
+func weekStart(t time.Time, start time.Weekday) time.Time {
+	days := (int(t.Weekday()) - int(start) + 7) % 7
+	year, month, day := t.Date()
+	return time.Date(year, month, day-days, 0, 0, 0, 0, t.Location())
+}
 
-		//close file handle
-		fw.writer.Close()
-		timeFlag := time.Now().Format(timeFormat)
-		oldFilename := strings.Replace(filename, filenameSuffix, "", 1) + "." + timeFlag + filenameSuffix
-		err := os.Rename(filename, oldFilename)
+//rename the file to the backup of time and recreate file
+func (fw *FileWriter) rotate(config *FileConfig, timeFormat string, t time.Time) error {
+	if config.SymlinkLatest {
+		return fw.rotateLatest(config)
+	}
+
+	// check bak num, age and total size
+	if config.MaxBak > 0 || config.MaxAge > 0 || config.MaxTotalSize > 0 {
+		err := fw.cleanUpBackupFiles(config, timeFormat)
 		if err != nil {
 			return err
 		}
-		err = fw.initFile()
+	}
+
+	naming, err := fw.backupNaming(config, timeFormat)
+	if err != nil {
+		return err
+	}
+	if config.BackupDir != "" {
+		err = fw.fs.MkdirAll(naming.dir, config.DirMode)
 		if err != nil {
 			return err
 		}
 	}
 
+	//close file handle
+	fw.closeFile()
+	oldFilename := naming.path(t)
+	err = fw.fs.Rename(fw.filename, oldFilename)
+	if err != nil {
+		return err
+	}
+	fw.checkpointRotated(oldFilename)
+	fw.uploadBackup(config, oldFilename)
+	err = fw.initFile()
+	if err != nil {
+		return err
+	}
+	fw.rotations++
+	fw.rotated(RotateEvent{OldPath: fw.filename, NewPath: oldFilename, Time: fw.clock.Now()})
+
 	return nil
 }
 
-//clean up backup files
-//params : maxBak int64, timeFormat string
+//clean up backup files by MaxBak, MaxAge and MaxTotalSize
+//params : config *FileConfig, timeFormat string
 //return : error
-func (fw *FileWriter) cleanUpBackupFiles(maxBak int64, timeFormat string) error {
-	filename := fw.filename
-	filenameSuffix := path.Ext(filename)
-
-	dirPath, oldFilename := path.Split(filename)
-	oldFilename = strings.Replace(oldFilename, filenameSuffix, "", 1)
-
-	dir, err := ioutil.ReadDir(dirPath)
+func (fw *FileWriter) cleanUpBackupFiles(config *FileConfig, timeFormat string) error {
+	naming, err := fw.backupNaming(config, timeFormat)
 	if err != nil {
 		return err
 	}
-
-	p := ""
-	fileConnect := ""
-	switch timeFormat {
-	case "2006-01-02-15.04.05.9999":
-		p = "[0-9]{4}-[0-9]{2}-[0-9]{2}-[0-9]{2}.[0-9]{2}.[0-9]{2}.[0-9]{0,4}"
-		fileConnect = "."
-	case "2006":
-		p = "[0-9]{4}"
-		fileConnect = "_"
-	case "200601":
-		p = "[0-9]{6}"
-		fileConnect = "_"
-	case "20060102":
-		p = "[0-9]{8}"
-		fileConnect = "_"
-	case "2006010215":
-		p = "[0-9]{10}"
-		fileConnect = "_"
+	r, err := naming.pattern()
+	if err != nil {
+		return err
 	}
 
-	if p == "" {
-		return errors.New("time format can not switch expr")
+	dir, err := fw.fs.ReadDir(naming.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
 	}
 
-	r, _ := regexp.Compile(p)
-
-	bakFileMap := make(map[int]string)
-	bakTimeSlice := make([]int, 0, maxBak)
+	bakFiles := []backupFile{}
 	for _, fi := range dir {
-		if fi.IsDir() {
+		// Filename and the file linked by Filename are being written
+		bakPath := filepath.Join(naming.dir, fi.Name())
+		if fi.IsDir() || bakPath == fw.latest || bakPath == filepath.Clean(fw.filename) {
 			continue
 		}
-		match, err := regexp.MatchString(oldFilename+fileConnect+p+filenameSuffix, fi.Name())
-		if err != nil {
-			return err
-		}
-		if !match {
+		t, seq, ok := naming.parse(r, fi.Name())
+		if !ok {
 			continue
 		}
-		matchStr := r.FindString(fi.Name())
-		if matchStr == "" {
-			continue
+		if t.IsZero() {
+			t = fi.ModTime()
 		}
-		t, _ := time.Parse(timeFormat, matchStr)
-		bakFileMap[int(t.Unix())] = fi.Name()
-		bakTimeSlice = append(bakTimeSlice, int(t.Unix()))
+		bakFiles = append(bakFiles, backupFile{
+			path:    bakPath,
+			time:    t.Unix(),
+			seq:     seq,
+			modTime: fi.ModTime(),
+			size:    fi.Size() / 1024,
+		})
 	}
 
-	if int64(len(bakTimeSlice)) < maxBak {
-		return nil
+	// oldest first
+	sort.Slice(bakFiles, func(i, j int) bool {
+		if bakFiles[i].time != bakFiles[j].time {
+			return bakFiles[i].time < bakFiles[j].time
+		}
+		return bakFiles[i].seq < bakFiles[j].seq
+	})
+
+	removeNum := 0
+
+	// the file being sliced will become a new bak file
+	if config.MaxBak > 0 && int64(len(bakFiles)) >= config.MaxBak {
+		removeNum = len(bakFiles) - int(config.MaxBak) + 1
+	}
+
+	if config.MaxAge > 0 {
+		expireTime := fw.clock.Now().Add(-config.MaxAge)
+		for i := removeNum; i < len(bakFiles); i++ {
+			if bakFiles[i].modTime.After(expireTime) {
+				break
+			}
+			removeNum = i + 1
+		}
+	}
+
+	if config.MaxTotalSize > 0 {
+		totalSize, _ := fw.getFileSize(fw.filename)
+		for _, bakFile := range bakFiles[removeNum:] {
+			totalSize += bakFile.size
+		}
+		for removeNum < len(bakFiles) && totalSize > config.MaxTotalSize {
+			totalSize -= bakFiles[removeNum].size
+			removeNum++
+		}
+	}
+
+	removeFiles := bakFiles[:removeNum]
+	if config.Uploader != nil {
+		removeFiles = fw.backupUploads(config).removable(bakFiles, removeNum)
+	}
+
+	if config.TrashDir != "" {
+		return fw.trashBackupFiles(config, removeFiles)
+	}
+	for _, bakFile := range removeFiles {
+		err := fw.fs.Remove(bakFile.path)
+		if err != nil {
+			return err
+		}
 	}
 
-	sort.Ints(bakTimeSlice)
+	return nil
+}
+
+// backup file info
+type backupFile struct {
+	path    string
+	time    int64
+	seq     int
+	modTime time.Time
+	size    int64
+}
+
+//reopen the file if it's renamed or removed, recount lines if it's truncated (copytruncate)
+func (fw *FileWriter) checkRotated() error {
+	fw.checkTime = fw.clock.Now()
 
-	for _, bakTime := range bakTimeSlice[:int64(len(bakTimeSlice))-maxBak+1] {
-		err := os.Remove(bakFileMap[bakTime])
+	fileInfo, err := fw.fs.Stat(fw.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fw.reopen()
+		}
+		return err
+	}
+	openInfo, err := fw.writer.Stat()
+	if err != nil || !os.SameFile(fileInfo, openInfo) {
+		return fw.reopen()
+	}
+	if fileInfo.Size() < fw.checkSize {
+		lines, err := fw.getFileLines()
 		if err != nil {
 			return err
 		}
+		fw.startLine = lines
 	}
+	fw.checkSize = fileInfo.Size()
+	fw.setSize(fileInfo.Size())
+	return nil
+}
 
+//close and open the file again
+func (fw *FileWriter) reopen() error {
+	if fw.writer != nil {
+		fw.closeFile()
+	}
+	err := fw.initFile()
+	if err != nil {
+		return err
+	}
+	fw.checkTime = fw.clock.Now()
+	fw.checkSize = 0
+	fw.reopens++
+	return nil
+}
+
+//create file with mode, create parent directories if createDirs
+func (fw *FileWriter) createFile() error {
+	err := fw.createDir(filepath.Dir(fw.filename))
+	if err != nil {
+		return err
+	}
+
+	file, err := fw.fs.OpenFile(fw.filename, os.O_CREATE|os.O_WRONLY, fw.fileMode)
+	if err != nil {
+		return err
+	}
+	file.Close()
+	if fw.chown {
+		return fw.fs.Chown(fw.filename, fw.uid, fw.gid)
+	}
+	return nil
+}
+
+// create the directory if CreateDirs is true
+func (fw *FileWriter) createDir(dirPath string) error {
+	if !fw.createDirs {
+		return nil
+	}
+	if _, err := fw.fs.Stat(dirPath); err == nil {
+		return nil
+	}
+	err := fw.fs.MkdirAll(dirPath, fw.dirMode)
+	if err != nil {
+		return err
+	}
+	if fw.chown {
+		return fw.fs.Chown(dirPath, fw.uid, fw.gid)
+	}
 	return nil
 }
 
 //get file object
 //params : filename
-//return : *os.file, error
-func (fw *FileWriter) getFileObject(filename string) (file *os.File, err error) {
-	file, err = os.OpenFile(filename, os.O_RDWR|os.O_APPEND, 0766)
+//return : File, error
+func (fw *FileWriter) getFileObject(filename string) (file File, err error) {
+	file, err = fw.fs.OpenFile(filename, os.O_RDWR|os.O_APPEND, fw.fileMode)
 	return file, err
 }
 
+//count bytes written to the file, compressed bytes of gzip files are reconciled by stat
+func (fw *FileWriter) countSize(msg string) {
+	if !fw.gzip {
+		fw.size += int64(len(msg))
+	}
+}
+
+//stat the size of the opened file
+func (fw *FileWriter) reconcileSize() {
+	fileInfo, err := fw.writer.Stat()
+	if err == nil {
+		fw.setSize(fileInfo.Size())
+	}
+}
+
+//size of the file of stat and buffered data
+func (fw *FileWriter) setSize(fileSize int64) {
+	fw.size = fileSize
+	if fw.buffer != nil {
+		fw.size += int64(fw.buffer.Buffered())
+	}
+	fw.sizeTime = fw.clock.Now()
+}
+
 //get file size
 //params : filename
 //return : fileSize(byte int64), error
 func (fw *FileWriter) getFileSize(filename string) (fileSize int64, err error) {
-	fileInfo, err := os.Stat(filename)
+	fileInfo, err := fw.fs.Stat(filename)
 	if err != nil {
 		return fileSize, err
 	}
@@ -540,4 +1385,7 @@ func (fw *FileWriter) getFileSize(filename string) (fileSize int64, err error) {
 
 func init() {
 	Register(FILE_ADAPTER_NAME, NewAdapterFile)
+	RegisterConfig(FILE_ADAPTER_NAME, func() Config {
+		return &FileConfig{}
+	})
 }
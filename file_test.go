@@ -1,6 +1,15 @@
 package go_logger
 
 import (
+	"bytes"
+	"compress/gzip"
+	"github.com/phachon/go-logger/utils"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 )
@@ -91,3 +100,384 @@ func TestAdapterFile_WriteLevelFile(t *testing.T) {
 	loggerMsg.Level = LOGGER_LEVEL_ERROR
 	fileAdapter.Write(loggerMsg)
 }
+
+func TestFileWriter_CleanUpBackupFiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	ioutil.WriteFile(filename, []byte{}, 0766)
+
+	timeFormat := "20060102"
+	now := time.Now()
+	for i := 1; i <= 4; i++ {
+		day := now.AddDate(0, 0, -i*10)
+		bakFilename := filepath.Join(dir, "test_"+day.Format(timeFormat)+".log")
+		ioutil.WriteFile(bakFilename, make([]byte, 2048), 0766)
+		os.Chtimes(bakFilename, day, day)
+	}
+
+	fw := NewFileWrite(filename)
+
+	// keep 25 days, removes the 30 and 40 days old backups
+	err = fw.cleanUpBackupFiles(&FileConfig{MaxAge: 25 * 24 * time.Hour}, timeFormat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Errorf("max age clean up error, %d files left", len(files))
+	}
+
+	// backups are 2KB each, keep 2KB in total
+	err = fw.cleanUpBackupFiles(&FileConfig{MaxTotalSize: 2}, timeFormat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	files, _ = ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("max total size clean up error, %d files left", len(files))
+	}
+	ok, _ := utils.UtilFile.PathExists(filepath.Join(dir, "test_"+now.AddDate(0, 0, -10).Format(timeFormat)+".log"))
+	if !ok {
+		t.Error("max total size clean up removed the newest backup")
+	}
+}
+
+func TestFileWriter_TrashBackupFiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	ioutil.WriteFile(filename, []byte{}, 0766)
+
+	timeFormat := "20060102"
+	now := time.Now()
+	for i := 1; i <= 3; i++ {
+		day := now.AddDate(0, 0, -i*10)
+		bakFilename := filepath.Join(dir, "test_"+day.Format(timeFormat)+".log")
+		ioutil.WriteFile(bakFilename, make([]byte, 2048), 0766)
+		os.Chtimes(bakFilename, day, day)
+	}
+	// expired file of the trash, file of another log is kept
+	trashDir := filepath.Join(dir, "trash")
+	os.Mkdir(trashDir, 0755)
+	expired := now.AddDate(0, 0, -8)
+	for _, name := range []string{"test_20000101.log.gz", "other_20000101.log"} {
+		ioutil.WriteFile(filepath.Join(trashDir, name), []byte{}, 0766)
+		os.Chtimes(filepath.Join(trashDir, name), expired, expired)
+	}
+
+	fw := NewFileWrite(filename)
+	err = fw.cleanUpBackupFiles(&FileConfig{MaxBak: 2, TrashDir: "trash", TrashCompress: true}, timeFormat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Errorf("max bak clean up error, %d files left", len(files))
+	}
+	trashFiles, _ := ioutil.ReadDir(trashDir)
+	names := []string{}
+	for _, fi := range trashFiles {
+		names = append(names, fi.Name())
+	}
+	oldest := "test_" + now.AddDate(0, 0, -30).Format(timeFormat) + ".log.gz"
+	if len(names) != 3 || names[0] != "other_20000101.log" || names[1] != oldest {
+		t.Errorf("trash files error: %v", names)
+	}
+}
+
+func TestAdapterFile_CreateDirs(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "logs", "app", "app.log")
+
+	fileAdapter := NewAdapterFile()
+	err = fileAdapter.Init(&FileConfig{Filename: filename})
+	if err == nil {
+		t.Fatal("file adapter must fail if the directory doesn't exist")
+	}
+
+	fileAdapter = NewAdapterFile()
+	err = fileAdapter.Init(&FileConfig{
+		Filename:   filename,
+		CreateDirs: true,
+		FileMode:   0640,
+		DirMode:    0750,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fileAdapter.(*AdapterFile).Close()
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if fileInfo.Mode().Perm() != 0640 {
+		t.Errorf("file mode error: %v", fileInfo.Mode().Perm())
+	}
+	dirInfo, _ := os.Stat(filepath.Dir(filename))
+	if dirInfo.Mode().Perm() != 0750 {
+		t.Errorf("dir mode error: %v", dirInfo.Mode().Perm())
+	}
+}
+
+func TestAdapterFile_BufferSize(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:        "%body%",
+		BufferSize:    4096,
+		FlushInterval: time.Hour,
+	})
+	logger.Info("buffered")
+
+	fileAdapter := logger.Adapter("file").(*AdapterFile)
+	content, _ := ioutil.ReadFile(fileAdapter.write[FILE_ACCESS_LEVEL].filename)
+	if len(content) != 0 {
+		t.Errorf("buffered message must not be written before flush: %q", content)
+	}
+
+	logger.Detach("file")
+	if readLog() != "buffered\n" {
+		t.Error("buffered message must be written when adapter is detached")
+	}
+}
+
+func TestAdapterFile_FlushInterval(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:        "%body%",
+		BufferSize:    4096,
+		FlushInterval: 10 * time.Millisecond,
+		SyncInterval:  10 * time.Millisecond,
+	})
+	logger.Info("flushed")
+	time.Sleep(50 * time.Millisecond)
+
+	if readLog() != "flushed\n" {
+		t.Error("buffered message must be written every flush interval")
+	}
+	logger.Detach("file")
+}
+
+func TestAdapterFile_HtmlFormat(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:     "[%level_string%] %body%",
+		HtmlFormat: true,
+	})
+	logger.Error("<b>failed</b> & retried")
+	logger.Detach("file")
+
+	content := readLog()
+	expected := `<div class="log level-Error" style="font-family:monospace;white-space:pre-wrap;color:#d00">[Error] &lt;b&gt;failed&lt;/b&gt; &amp; retried</div>` + "\n"
+	if content != expected {
+		t.Errorf("file html format error: %s", content)
+	}
+}
+
+func TestAdapterFile_Gzip(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:        "%body%",
+		Gzip:          true,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	logger.Info("compressed")
+	time.Sleep(50 * time.Millisecond)
+
+	// flushed data can be read before the file is closed
+	fileAdapter := logger.Adapter("file").(*AdapterFile)
+	content, _ := ioutil.ReadFile(fileAdapter.write[FILE_ACCESS_LEVEL].filename)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	flushed := make([]byte, 64)
+	n, _ := io.ReadFull(gzipReader, flushed)
+	if string(flushed[:n]) != "compressed\n" {
+		t.Errorf("gzip message must be readable after flush: %q", flushed[:n])
+	}
+
+	logger.Detach("file")
+	gzipReader, err = gzip.NewReader(bytes.NewReader([]byte(readLog())))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	closed, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if string(closed) != "compressed\n" {
+		t.Errorf("gzip file content error: %q", closed)
+	}
+}
+
+func TestFileWriter_SliceByWeekAndSize(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	if start := weekStart(time.Date(2024, 1, 3, 15, 0, 0, 0, time.Local), time.Monday); !start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("week start error: %v", start)
+	}
+
+	filename := filepath.Join(dir, "test.log")
+	config := &FileConfig{DateSlice: FILE_SLICE_DATE_WEEK, WeekStart: time.Monday, MaxLine: 2}
+	fw := NewFileWrite(filename)
+	err = fw.initFile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fw.closeFile()
+
+	// the file of the week exceeds MaxLine twice, then the week ends
+	fw.startTime = time.Date(2024, 1, 3, 15, 0, 0, 0, time.Local).Unix()
+	for i := 0; i < 2; i++ {
+		fw.startLine = 2
+		err = fw.sliceByFileLines(config.MaxLine, config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		fw.startTime = time.Date(2024, 1, 3, 15, 0, 0, 0, time.Local).Unix()
+	}
+	err = fw.sliceByDate(config.DateSlice, config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, name := range []string{"test_20240101.1.log", "test_20240101.2.log", "test_20240101.3.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("bak file %s is not sliced", name)
+		}
+	}
+}
+
+func TestFileWriter_SliceBySizeCounter(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	err = ioutil.WriteFile(filename, []byte(strings.Repeat("x", 1000)+"\n"), 0666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	config := &FileConfig{Filename: filename, MaxSize: 2, Format: "%body%", LineEnding: LINE_ENDING_CRLF}
+	fw := newFileWriteByConfig(filename, config)
+	err = fw.initFile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fw.closeFile()
+	if fw.size != 1001 || fw.startLine != 2 {
+		t.Fatalf("file size %d lines %d of the existing file error", fw.size, fw.startLine)
+	}
+
+	// writes are counted without stat until the file reaches MaxSize
+	for i := 0; i < 11; i++ {
+		err = fw.writeByConfig(config, &loggerMessage{Body: strings.Repeat("y", 98)})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if fw.size != 2101 || fw.rotations != 0 {
+		t.Fatalf("file size %d rotations %d error", fw.size, fw.rotations)
+	}
+	err = fw.writeByConfig(config, &loggerMessage{Body: "z"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if fw.rotations != 1 || fw.size != 3 {
+		t.Errorf("file size %d rotations %d after slice error", fw.size, fw.rotations)
+	}
+}
+
+func TestFile_GetFileLines(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	content := "a\n" + strings.Repeat("b", 100*1024) + "\nc"
+	err = ioutil.WriteFile(filename, []byte(content), 0666)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	lines, err := utils.UtilFile.GetFileLines(filename)
+	if err != nil || lines != 3 {
+		t.Errorf("file lines %d error: %v", lines, err)
+	}
+}
+
+func TestAdapterFile_LineEnding(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:     "%body%",
+		MaxLine:    100,
+		LineEnding: LINE_ENDING_CRLF,
+		Encoder: TextEncoderFunc(func(text []byte) ([]byte, error) {
+			return bytes.ToUpper(text), nil
+		}),
+	})
+	logger.Info("first\nsecond")
+	logger.Info("third")
+	logger.Flush()
+	fw := logger.Adapter("file").(*AdapterFile).write[FILE_ACCESS_LEVEL]
+
+	if fw.startLine != 4 {
+		t.Errorf("file lines %d error", fw.startLine)
+	}
+	if content := readLog(); content != "FIRST\nSECOND\r\nTHIRD\r\n" {
+		t.Errorf("file line ending and encoding error: %q", content)
+	}
+}
+
+func TestAdapterFile_LevelFormat(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format:          "%level_string%: %body%",
+		LevelFormat:     map[int]string{LOGGER_LEVEL_ERROR: "%level_string%: %body% (%file%)"},
+		LevelJsonFormat: map[int]bool{LOGGER_LEVEL_DEBUG: true},
+	})
+	logger.Info("compact")
+	logger.Error("failed")
+	logger.Debug("detail")
+	logger.Flush()
+
+	lines := strings.Split(readLog(), "\n")
+	if len(lines) != 4 || lines[0] != "Info: compact" || lines[1] != "Error: failed (file_test.go)" || !strings.HasPrefix(lines[2], "{") {
+		t.Errorf("file level format error: %q", lines)
+	}
+
+	err := NewAdapterFile().Init(&FileConfig{Filename: "test.log", LevelFormat: map[int]string{100: "%body%"}})
+	if err == nil || err.Error() != "config LevelFormat key level is illegal!" {
+		t.Errorf("file level format level error: %v", err)
+	}
+}
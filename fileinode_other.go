@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package go_logger
+
+import "os"
+
+// inode is not supported
+func fileInode(fileInfo os.FileInfo) (uint64, uint64) {
+	return 0, 0
+}
@@ -0,0 +1,18 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package go_logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// inode and device of the file
+func fileInode(fileInfo os.FileInfo) (uint64, uint64) {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0
+	}
+	return uint64(stat.Ino), uint64(stat.Dev)
+}
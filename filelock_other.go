@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package go_logger
+
+import (
+	"errors"
+)
+
+// file lock is not supported
+func lockFile(file fileDescriptor) error {
+	return errors.New("file lock is not supported")
+}
+
+func unlockFile(file fileDescriptor) error {
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package go_logger
+
+import (
+	"syscall"
+)
+
+// advisory exclusive lock of the file, blocks until locked
+func lockFile(file fileDescriptor) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+func unlockFile(file fileDescriptor) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
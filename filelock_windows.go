@@ -0,0 +1,36 @@
+//go:build windows
+// +build windows
+
+package go_logger
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const lockfileExclusiveLock = 0x2
+
+var (
+	kernel32     = syscall.NewLazyDLL("kernel32.dll")
+	lockFileEx   = kernel32.NewProc("LockFileEx")
+	unlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// exclusive lock of the first byte of the file, blocks until locked
+func lockFile(file fileDescriptor) error {
+	overlapped := &syscall.Overlapped{}
+	ret, _, err := lockFileEx.Call(file.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func unlockFile(file fileDescriptor) error {
+	overlapped := &syscall.Overlapped{}
+	ret, _, err := unlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(overlapped)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package go_logger
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// filter rules of an adapter, messages pass if they match no exclude rule and every kind of include rules
+// rules apply to verbose messages too, fields are compared by their "%v" strings
+type FilterRules struct {
+
+	// messages pass only if their bodies match one of the regexps
+	IncludeBodies []*regexp.Regexp
+
+	// messages with bodies matching one of the regexps are dropped
+	ExcludeBodies []*regexp.Regexp
+
+	// messages pass only if all the fields equal the values, eg: {"tenant": "acme"}
+	IncludeFields map[string]string
+
+	// messages with one of the fields equal to the value are dropped, eg: {"health_check": "true"}
+	ExcludeFields map[string]string
+
+	// messages pass only if their component (child loggers, see Logger.New()) is one of the components or a sub component
+	IncludeComponents []string
+
+	// messages of the components or sub components are dropped, eg: "thirdparty" drops "thirdparty" and "thirdparty.http"
+	ExcludeComponents []string
+}
+
+// set filter rules of the adapter, nil removes them
+// filters apply after router and adapter level, before adapter sampler
+//
+// example:
+//	logger.SetAdapterFilter("error_file", &go_logger.FilterRules{
+//		ExcludeComponents: []string{"thirdparty"},
+//		ExcludeBodies:     []*regexp.Regexp{regexp.MustCompile(`connection reset by peer`)},
+//	})
+func (logger *Logger) SetAdapterFilter(adapterName string, rules *FilterRules) error {
+	err := rules.validate()
+	if err != nil {
+		return errors.New("logger: " + err.Error())
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.filter.Store(&rules)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+func (rules *FilterRules) validate() error {
+	if rules == nil {
+		return nil
+	}
+	for _, patterns := range [][]*regexp.Regexp{rules.IncludeBodies, rules.ExcludeBodies} {
+		for _, pattern := range patterns {
+			if pattern == nil {
+				return errors.New("filter body regexp cannot be nil!")
+			}
+		}
+	}
+	return nil
+}
+
+// output filter passes the message
+func (output *outputLogger) filtered(loggerMsg *loggerMessage) bool {
+	rules, ok := output.filter.Load().(**FilterRules)
+	if !ok || *rules == nil {
+		return true
+	}
+	return (*rules).pass(loggerMsg)
+}
+
+// message matches no exclude rule and every kind of include rules
+func (rules *FilterRules) pass(loggerMsg *loggerMessage) bool {
+	for _, pattern := range rules.ExcludeBodies {
+		if pattern.MatchString(loggerMsg.Body) {
+			return false
+		}
+	}
+	for name, value := range rules.ExcludeFields {
+		if _, ok := loggerMsg.Fields[name]; ok && loggerMessageField(loggerMsg.Fields, name) == value {
+			return false
+		}
+	}
+	component := loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_COMPONENT)
+	if component != "" && filterComponent(rules.ExcludeComponents, component) {
+		return false
+	}
+
+	if len(rules.IncludeBodies) > 0 {
+		matched := false
+		for _, pattern := range rules.IncludeBodies {
+			if pattern.MatchString(loggerMsg.Body) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for name, value := range rules.IncludeFields {
+		if _, ok := loggerMsg.Fields[name]; !ok || loggerMessageField(loggerMsg.Fields, name) != value {
+			return false
+		}
+	}
+	if len(rules.IncludeComponents) > 0 && (component == "" || !filterComponent(rules.IncludeComponents, component)) {
+		return false
+	}
+	return true
+}
+
+// component is one of the components or a sub component
+func filterComponent(components []string, component string) bool {
+	for _, name := range components {
+		if component == name || strings.HasPrefix(component, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// rules of the filter, eg: "exclude_bodies=[^health] exclude_components=[thirdparty]"
+func (rules *FilterRules) String() string {
+	parts := []string{}
+	add := func(name string, values []string) {
+		if len(values) > 0 {
+			parts = append(parts, name+"=["+strings.Join(values, ", ")+"]")
+		}
+	}
+	patterns := func(regexps []*regexp.Regexp) []string {
+		sources := make([]string, 0, len(regexps))
+		for _, pattern := range regexps {
+			sources = append(sources, pattern.String())
+		}
+		return sources
+	}
+	fields := func(values map[string]string) []string {
+		pairs := make([]string, 0, len(values))
+		for _, name := range sortedStringKeys(values) {
+			pairs = append(pairs, name+"="+values[name])
+		}
+		return pairs
+	}
+	add("include_bodies", patterns(rules.IncludeBodies))
+	add("exclude_bodies", patterns(rules.ExcludeBodies))
+	add("include_fields", fields(rules.IncludeFields))
+	add("exclude_fields", fields(rules.ExcludeFields))
+	add("include_components", rules.IncludeComponents)
+	add("exclude_components", rules.ExcludeComponents)
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,98 @@
+package go_logger
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestLogger_SetAdapterFilter(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.AttachAs("errors", "memory", LOGGER_LEVEL_ERROR, &MemoryConfig{})
+	err := logger.SetAdapterFilter("errors", &FilterRules{
+		ExcludeBodies:     []*regexp.Regexp{regexp.MustCompile(`connection reset`)},
+		ExcludeComponents: []string{"thirdparty"},
+		ExcludeFields:     map[string]string{"expected": "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Error("disk full")
+	logger.Error("read: connection reset by peer")
+	logger.New("thirdparty").New("http").Error("retry failed")
+	logger.New("thirdpartyx").Error("component prefix")
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "canceled", map[string]interface{}{"expected": true})
+
+	bodies := func(name string) []string {
+		entries := logger.Adapter(name).(*AdapterMemory).Entries()
+		result := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			result = append(result, entry.Body)
+		}
+		return result
+	}
+	if errors := bodies("errors"); len(errors) != 2 || errors[0] != "disk full" || errors[1] != "component prefix" {
+		t.Errorf("adapter filter exclude error: %v", errors)
+	}
+	if all := bodies("memory"); len(all) != 5 {
+		t.Errorf("adapter without filter error: %v", all)
+	}
+
+	logger.SetAdapterFilter("memory", &FilterRules{
+		IncludeBodies:     []*regexp.Regexp{regexp.MustCompile(`^order`)},
+		IncludeFields:     map[string]string{"tenant": "acme"},
+		IncludeComponents: []string{"billing"},
+	})
+	billing := logger.New("billing").With(map[string]interface{}{"tenant": "acme"})
+	billing.Info("order paid")
+	billing.Info("invoice sent")
+	billing.New("tax").Info("order taxed")
+	logger.New("billing").Info("order without tenant")
+	logger.WriterFields(LOGGER_LEVEL_INFO, "order without component", map[string]interface{}{"tenant": "acme"})
+	if all := bodies("memory"); len(all) != 7 || all[5] != "order paid" || all[6] != "order taxed" {
+		t.Errorf("adapter filter include error: %v", all)
+	}
+
+	logger.SetAdapterFilter("memory", nil)
+	logger.Info("no filter")
+	if all := bodies("memory"); all[len(all)-1] != "no filter" {
+		t.Errorf("adapter filter remove error: %v", all)
+	}
+
+	if logger.SetAdapterFilter("missing", &FilterRules{}) == nil {
+		t.Errorf("filter of adapter not attached must be error")
+	}
+	if logger.SetAdapterFilter("memory", &FilterRules{ExcludeBodies: []*regexp.Regexp{nil}}) == nil {
+		t.Errorf("filter nil regexp must be error")
+	}
+}
+
+func TestLogger_LoadConfigBytesFilter(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`
+adapters:
+  - name: memory
+    filter:
+      exclude_bodies: ["^health"]
+      exclude_components: [thirdparty]
+`), CONFIG_FORMAT_YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("health ok")
+	logger.New("thirdparty").Info("noise")
+	logger.Info("order paid")
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 1 || entries[0].Body != "order paid" {
+		t.Errorf("config filter error: %v", entries)
+	}
+
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory", "filter": {"exclude_bodies": ["("]}}]}`), CONFIG_FORMAT_JSON)
+	if err == nil {
+		t.Errorf("config filter regexp must be error")
+	}
+}
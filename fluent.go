@@ -0,0 +1,322 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const FLUENT_ADAPTER_NAME = "fluent"
+
+const (
+	FLUENT_DEFAULT_ADDRESS        = "127.0.0.1:24224"
+	FLUENT_DEFAULT_TAG            = "go-logger"
+	FLUENT_DEFAULT_BATCH_SIZE     = 100
+	FLUENT_DEFAULT_FLUSH_INTERVAL = time.Second
+	FLUENT_DEFAULT_TIMEOUT        = 3 * time.Second
+	FLUENT_DEFAULT_MAX_RETRIES    = 2
+)
+
+// adapter fluentd / fluent bit, forward protocol
+type AdapterFluent struct {
+	lock     sync.Mutex
+	connLock sync.Mutex
+	config   *FluentConfig
+	conn     net.Conn
+	reader   *bufio.Reader
+	buffer   []*loggerMessage
+	ticker   *time.Ticker
+	quit     chan struct{}
+}
+
+// fluent config
+type FluentConfig struct {
+
+	// network of forward input, "tcp" or "unix", default "tcp"
+	Network string
+
+	// address of forward input, default "127.0.0.1:24224", socket path of "unix"
+	Address string
+
+	// tag of messages, placeholders of Format are replaced, default "go-logger"
+	// example: "app.%level_string%"
+	Tag string
+
+	// wait for ack of every chunk, messages are resent when ack timed out (at least once)
+	RequireAck bool
+
+	// time is integer seconds instead of EventTime, for fluentd v0.12
+	SecondTime bool
+
+	// max messages of one forward message, default 100
+	BatchSize int
+
+	// buffered messages are sent every FlushInterval, default 1s
+	FlushInterval time.Duration
+
+	// dial, write and ack timeout, default 3s
+	Timeout time.Duration
+
+	// send is retried with a new connection, default 2, -1 is no retry
+	MaxRetries int
+}
+
+func (fc *FluentConfig) Name() string {
+	return FLUENT_ADAPTER_NAME
+}
+
+func NewAdapterFluent() LoggerAbstract {
+	return &AdapterFluent{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterFluent *AdapterFluent) Init(fluentConfig Config) error {
+	fc, ok := fluentConfig.(*FluentConfig)
+	if !ok || fc == nil {
+		return configTypeError(FLUENT_ADAPTER_NAME, "FluentConfig")
+	}
+	adapterFluent.config = fc
+
+	if fc.Network == "" {
+		fc.Network = "tcp"
+	}
+	if fc.Network != "tcp" && fc.Network != "unix" {
+		return configError(FLUENT_ADAPTER_NAME, "Network", "must be tcp or unix!")
+	}
+	if fc.Address == "" {
+		fc.Address = FLUENT_DEFAULT_ADDRESS
+	}
+	if fc.Tag == "" {
+		fc.Tag = FLUENT_DEFAULT_TAG
+	}
+	if fc.BatchSize <= 0 {
+		fc.BatchSize = FLUENT_DEFAULT_BATCH_SIZE
+	}
+	if fc.FlushInterval <= 0 {
+		fc.FlushInterval = FLUENT_DEFAULT_FLUSH_INTERVAL
+	}
+	if fc.Timeout <= 0 {
+		fc.Timeout = FLUENT_DEFAULT_TIMEOUT
+	}
+	if fc.MaxRetries == 0 {
+		fc.MaxRetries = FLUENT_DEFAULT_MAX_RETRIES
+	}
+
+	adapterFluent.ticker = time.NewTicker(fc.FlushInterval)
+	adapterFluent.quit = make(chan struct{})
+	go adapterFluent.startFlush(adapterFluent.ticker, adapterFluent.quit)
+
+	return nil
+}
+
+func (adapterFluent *AdapterFluent) Write(loggerMsg *loggerMessage) error {
+	adapterFluent.lock.Lock()
+	adapterFluent.buffer = append(adapterFluent.buffer, loggerMsg)
+	if len(adapterFluent.buffer) < adapterFluent.config.BatchSize {
+		adapterFluent.lock.Unlock()
+		return nil
+	}
+	buffer := adapterFluent.buffer
+	adapterFluent.buffer = []*loggerMessage{}
+	adapterFluent.lock.Unlock()
+
+	return adapterFluent.forward(buffer)
+}
+
+func (adapterFluent *AdapterFluent) Flush() {
+	adapterFluent.lock.Lock()
+	buffer := adapterFluent.buffer
+	adapterFluent.buffer = []*loggerMessage{}
+	adapterFluent.lock.Unlock()
+
+	err := adapterFluent.forward(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: fluent adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker, send buffered messages and close the connection
+func (adapterFluent *AdapterFluent) Close() error {
+	if adapterFluent.ticker != nil {
+		adapterFluent.ticker.Stop()
+		close(adapterFluent.quit)
+		adapterFluent.ticker = nil
+	}
+	adapterFluent.Flush()
+
+	adapterFluent.connLock.Lock()
+	defer adapterFluent.connLock.Unlock()
+	adapterFluent.disconnect()
+	return nil
+}
+
+func (adapterFluent *AdapterFluent) Name() string {
+	return FLUENT_ADAPTER_NAME
+}
+
+func (adapterFluent *AdapterFluent) Capabilities() Capabilities {
+	return Capabilities{Batching: true, Binary: true, NeedsFlush: true, Remote: true}
+}
+
+// connect to the forward input if it's not connected
+func (adapterFluent *AdapterFluent) HealthCheck() error {
+	adapterFluent.connLock.Lock()
+	defer adapterFluent.connLock.Unlock()
+
+	return adapterFluent.connect()
+}
+
+// flush buffer every FlushInterval
+func (adapterFluent *AdapterFluent) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterFluent.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// send messages by forward mode, one forward message of every tag
+func (adapterFluent *AdapterFluent) forward(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	tags := []string{}
+	entries := map[string][]byte{}
+	counts := map[string]int{}
+	for _, loggerMsg := range loggerMsgs {
+		tag := loggerMessageFormat(adapterFluent.config.Tag, loggerMsg)
+		if _, ok := entries[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		entries[tag] = adapterFluent.appendEntry(entries[tag], loggerMsg)
+		counts[tag]++
+	}
+
+	adapterFluent.connLock.Lock()
+	defer adapterFluent.connLock.Unlock()
+
+	for _, tag := range tags {
+		chunk := ""
+		if adapterFluent.config.RequireAck {
+			chunk = fluentChunkId()
+		}
+		buf := appendMsgpackArrayHeader(nil, 3)
+		buf = appendMsgpackString(buf, tag)
+		buf = appendMsgpackArrayHeader(buf, counts[tag])
+		buf = append(buf, entries[tag]...)
+		if chunk != "" {
+			buf = appendMsgpack(buf, map[string]interface{}{"size": counts[tag], "chunk": chunk})
+		} else {
+			buf = appendMsgpack(buf, map[string]interface{}{"size": counts[tag]})
+		}
+
+		err := adapterFluent.send(buf, chunk)
+		for retry := 0; err != nil && retry < adapterFluent.config.MaxRetries; retry++ {
+			err = adapterFluent.send(buf, chunk)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append [time, record] entry of the message
+func (adapterFluent *AdapterFluent) appendEntry(buf []byte, loggerMsg *loggerMessage) []byte {
+	msgTime := time.Unix(0, loggerMsg.Millisecond*int64(time.Millisecond))
+	buf = appendMsgpackArrayHeader(buf, 2)
+	if adapterFluent.config.SecondTime {
+		buf = appendMsgpackInt(buf, msgTime.Unix())
+	} else {
+		buf = appendMsgpackEventTime(buf, msgTime)
+	}
+
+	record := make(map[string]interface{}, len(loggerMsg.Fields)+6)
+	for key, value := range loggerMsg.Fields {
+		record[key] = value
+	}
+	record["level"] = loggerMsg.Level
+	record["level_string"] = loggerMsg.LevelString
+	record["body"] = loggerMsg.Body
+	record["file"] = loggerMsg.File
+	record["line"] = loggerMsg.Line
+	record["function"] = loggerMsg.Function
+	return appendMsgpack(buf, record)
+}
+
+// write forward message, wait for ack of chunk, the connection is closed on errors
+func (adapterFluent *AdapterFluent) send(buf []byte, chunk string) error {
+	err := adapterFluent.connect()
+	if err != nil {
+		return err
+	}
+	timeout := adapterFluent.config.Timeout
+	adapterFluent.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err = adapterFluent.conn.Write(buf)
+	if err != nil {
+		adapterFluent.disconnect()
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	adapterFluent.conn.SetReadDeadline(time.Now().Add(timeout))
+	resp, err := readMsgpack(adapterFluent.reader)
+	if err != nil {
+		adapterFluent.disconnect()
+		return err
+	}
+	ack, _ := resp.(map[string]interface{})
+	if ack["ack"] != chunk {
+		adapterFluent.disconnect()
+		return errors.New("fluent ack of chunk " + chunk + " mismatch")
+	}
+	return nil
+}
+
+func (adapterFluent *AdapterFluent) connect() error {
+	if adapterFluent.conn != nil {
+		return nil
+	}
+	conn, err := net.DialTimeout(adapterFluent.config.Network, adapterFluent.config.Address, adapterFluent.config.Timeout)
+	if err != nil {
+		return err
+	}
+	adapterFluent.conn = conn
+	adapterFluent.reader = bufio.NewReader(conn)
+	return nil
+}
+
+func (adapterFluent *AdapterFluent) disconnect() {
+	if adapterFluent.conn != nil {
+		adapterFluent.conn.Close()
+		adapterFluent.conn = nil
+		adapterFluent.reader = nil
+	}
+}
+
+// unique chunk id of ack
+func fluentChunkId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return base64.StdEncoding.EncodeToString(id)
+}
+
+func init() {
+	Register(FLUENT_ADAPTER_NAME, NewAdapterFluent)
+	RegisterConfig(FLUENT_ADAPTER_NAME, func() Config {
+		return &FluentConfig{}
+	})
+}
@@ -0,0 +1,129 @@
+package go_logger
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fluent forward input, forward messages are sent to channel, chunks are acked
+func newTestFluentServer(t *testing.T) (string, chan []interface{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	messages := make(chan []interface{}, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					value, err := readMsgpack(reader)
+					if err != nil {
+						return
+					}
+					message := value.([]interface{})
+					messages <- message
+					option := message[2].(map[string]interface{})
+					if chunk, ok := option["chunk"]; ok {
+						conn.Write(appendMsgpack(nil, map[string]interface{}{"ack": chunk}))
+					}
+				}
+			}(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return listener.Addr().String(), messages
+}
+
+func TestAdapterFluent_Write(t *testing.T) {
+
+	address, messages := newTestFluentServer(t)
+	fluentAdapter := NewAdapterFluent()
+	err := fluentAdapter.Init(&FluentConfig{
+		Address:       address,
+		Tag:           "app.%level_string%",
+		RequireAck:    true,
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	msgTime := time.Unix(1715328000, 5e6)
+	fluentAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_ERROR, "error 1", map[string]interface{}{"order": 1001}))
+	fluentAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_INFO, "info", nil))
+	err = fluentAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_ERROR, "error 2", nil))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	message := <-messages
+	entries := message[1].([]interface{})
+	if message[0] != "app.Error" || len(entries) != 2 {
+		t.Fatalf("fluent forward message error: %v", message)
+	}
+	entry := entries[0].([]interface{})
+	eventTime := entry[0].(msgpackExt)
+	if eventTime.Type != MSGPACK_EXT_EVENT_TIME || string(eventTime.Data) != string(appendMsgpackEventTime(nil, msgTime)[2:]) {
+		t.Errorf("fluent event time error: %v", eventTime)
+	}
+	record := entry[1].(map[string]interface{})
+	if record["body"] != "error 1" || record["order"] != int64(1001) || record["level"] != int64(LOGGER_LEVEL_ERROR) {
+		t.Errorf("fluent record error: %v", record)
+	}
+	message = <-messages
+	if message[0] != "app.Info" {
+		t.Errorf("fluent tag error: %v", message[0])
+	}
+	fluentAdapter.(LoggerCloser).Close()
+}
+
+func TestAdapterFluent_Reconnect(t *testing.T) {
+
+	address, messages := newTestFluentServer(t)
+	fluentAdapter := NewAdapterFluent()
+	fluentAdapter.Init(&FluentConfig{Address: address, BatchSize: 1, FlushInterval: time.Hour, SecondTime: true})
+
+	fluentAdapter.Write(newLoggerMessage(time.Unix(1715328000, 0), LOGGER_LEVEL_INFO, "1", nil))
+	<-messages
+	// broken connection is redialed
+	fluentAdapter.(*AdapterFluent).conn.Close()
+	err := fluentAdapter.Write(newLoggerMessage(time.Unix(1715328000, 0), LOGGER_LEVEL_INFO, "2", nil))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	message := <-messages
+	entry := message[1].([]interface{})[0].([]interface{})
+	if message[0] != FLUENT_DEFAULT_TAG || entry[0] != int64(1715328000) {
+		t.Errorf("fluent message error: %v", message)
+	}
+	fluentAdapter.(LoggerCloser).Close()
+}
+
+func TestMsgpack(t *testing.T) {
+
+	values := []interface{}{
+		nil, true, false, int64(0), int64(127), int64(128), int64(65536), int64(-1), int64(-33), int64(-40000), int64(1) << 40,
+		"", "short", string(make([]byte, 40)), string(make([]byte, 300)), 1.5,
+		[]interface{}{int64(1), "a"}, map[string]interface{}{"a": int64(1), "b": []interface{}{"c"}},
+	}
+	for _, value := range values {
+		buf := appendMsgpack(nil, value)
+		decoded, err := readMsgpack(bufio.NewReader(bytes.NewReader(buf)))
+		if err != nil || !reflect.DeepEqual(decoded, value) {
+			t.Errorf("msgpack %v decoded %v, error: %v", value, decoded, err)
+		}
+	}
+}
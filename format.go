@@ -0,0 +1,380 @@
+package go_logger
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// placeholder of compiled format
+const (
+	formatLiteral = iota
+	formatTimestamp
+	formatTimestampFormat
+	formatMillisecond
+	formatMillisecondFormat
+	formatLevel
+	formatLevelString
+	formatFile
+	formatLine
+	formatFunction
+	formatBody
+	formatFields
+	formatTraceId
+	formatSpanId
+	formatDeploy
+	formatSequence
+	formatHostname
+	formatPid
+	formatComponent
+	formatStacktrace
+	formatField // field of the literal name, %field(order_id)%
+	formatCall  // function of a value, %upper(level_string)%
+	formatGroup // conditional segment, %{ [%fields%]}% is omitted if its placeholders are empty
+)
+
+var formatPlaceholders = map[string]int{
+	"timestamp":          formatTimestamp,
+	"timestamp_format":   formatTimestampFormat,
+	"millisecond":        formatMillisecond,
+	"millisecond_format": formatMillisecondFormat,
+	"level":              formatLevel,
+	"level_string":       formatLevelString,
+	"file":               formatFile,
+	"line":               formatLine,
+	"function":           formatFunction,
+	"body":               formatBody,
+	"fields":             formatFields,
+	"trace_id":           formatTraceId,
+	"span_id":            formatSpanId,
+	"deploy":             formatDeploy,
+	"sequence":           formatSequence,
+	"hostname":           formatHostname,
+	"pid":                formatPid,
+	"component":          formatComponent,
+	"stacktrace":         formatStacktrace,
+}
+
+// segment of compiled format, a literal text or a placeholder
+type formatSegment struct {
+	placeholder int
+	literal     string
+	call        *formatFunctionCall
+	group       compiledFormat
+}
+
+// functions of formats, the first argument is the value, the others are options
+//	upper(value), lower(value)
+//	pad(value, width) pads the value with spaces on the right, lpad(value, width) on the left
+//	trunc(value, max) cuts the value to max characters
+//	default(value, text) is text if the value is empty
+//	field(name) is the value of the field
+var formatFunctions = map[string]int{
+	"upper":   0,
+	"lower":   0,
+	"pad":     1,
+	"lpad":    1,
+	"trunc":   1,
+	"default": 1,
+}
+
+// function call of a compiled format
+type formatFunctionCall struct {
+	name  string
+	value formatSegment
+	width int
+	text  string
+}
+
+// format string compiled into segments, placeholders are substituted without scanning the format again
+type compiledFormat []formatSegment
+
+// compiled formats by format string
+var compiledFormats sync.Map
+
+var formatBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// compiled format of the format string, cached
+func getCompiledFormat(format string) compiledFormat {
+	cached, ok := compiledFormats.Load(format)
+	if ok {
+		return cached.(compiledFormat)
+	}
+	compiled := compileFormat(format)
+	compiledFormats.Store(format, compiled)
+	return compiled
+}
+
+// compile format, unknown placeholders are kept as literal text
+func compileFormat(format string) compiledFormat {
+	compiled := compiledFormat{}
+	literal := strings.Builder{}
+	appendSegment := func(segment formatSegment) {
+		if literal.Len() > 0 {
+			compiled = append(compiled, formatSegment{placeholder: formatLiteral, literal: literal.String()})
+			literal.Reset()
+		}
+		compiled = append(compiled, segment)
+	}
+	for i := 0; i < len(format); {
+		if strings.HasPrefix(format[i:], "%{") {
+			end := formatGroupEnd(format[i+2:])
+			if end >= 0 {
+				appendSegment(formatSegment{placeholder: formatGroup, group: compileFormat(format[i+2 : i+2+end])})
+				i += end + 4
+				continue
+			}
+		}
+		if format[i] == '%' {
+			end := strings.IndexByte(format[i+1:], '%')
+			if end >= 0 {
+				segment, ok := compileFormatExpr(format[i+1 : i+1+end])
+				if ok {
+					appendSegment(segment)
+					i += end + 2
+					continue
+				}
+			}
+		}
+		literal.WriteByte(format[i])
+		i++
+	}
+	if literal.Len() > 0 {
+		compiled = append(compiled, formatSegment{placeholder: formatLiteral, literal: literal.String()})
+	}
+	return compiled
+}
+
+// index of "}%" closing the group, nested groups are skipped, -1 if it's not closed
+func formatGroupEnd(format string) int {
+	depth := 0
+	for i := 0; i+1 < len(format); i++ {
+		switch {
+		case format[i] == '%' && format[i+1] == '{':
+			depth++
+			i++
+		case format[i] == '}' && format[i+1] == '%':
+			if depth == 0 {
+				return i
+			}
+			depth--
+			i++
+		}
+	}
+	return -1
+}
+
+// compile a placeholder, a field or a function call, false if it's unknown
+func compileFormatExpr(expr string) (formatSegment, bool) {
+	expr = strings.TrimSpace(expr)
+	placeholder, ok := formatPlaceholders[expr]
+	if ok {
+		return formatSegment{placeholder: placeholder}, true
+	}
+	open := strings.IndexByte(expr, '(')
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return formatSegment{}, false
+	}
+	name := strings.TrimSpace(expr[:open])
+	args := splitFormatArgs(expr[open+1 : len(expr)-1])
+	if name == "field" {
+		if len(args) != 1 || args[0] == "" {
+			return formatSegment{}, false
+		}
+		return formatSegment{placeholder: formatField, literal: args[0]}, true
+	}
+	options, ok := formatFunctions[name]
+	if !ok || len(args) != options+1 {
+		return formatSegment{}, false
+	}
+	value, ok := compileFormatExpr(args[0])
+	if !ok {
+		return formatSegment{}, false
+	}
+	call := &formatFunctionCall{name: name, value: value}
+	switch name {
+	case "pad", "lpad", "trunc":
+		width, err := strconv.Atoi(args[1])
+		if err != nil || width < 0 {
+			return formatSegment{}, false
+		}
+		call.width = width
+	case "default":
+		call.text = args[1]
+	}
+	return formatSegment{placeholder: formatCall, call: call}, true
+}
+
+// arguments of a function call separated by commas out of nested calls, trimmed
+func splitFormatArgs(args string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(args[start:]))
+}
+
+// value of the function call
+func (call *formatFunctionCall) format(loggerMsg *loggerMessage) string {
+	value := string(compiledFormat{call.value}.appendMessage(nil, loggerMsg))
+	switch call.name {
+	case "upper":
+		return strings.ToUpper(value)
+	case "lower":
+		return strings.ToLower(value)
+	case "pad", "lpad":
+		padding := call.width - utf8.RuneCountInString(value)
+		if padding <= 0 {
+			return value
+		}
+		if call.name == "lpad" {
+			return strings.Repeat(" ", padding) + value
+		}
+		return value + strings.Repeat(" ", padding)
+	case "trunc":
+		if utf8.RuneCountInString(value) <= call.width {
+			return value
+		}
+		return string([]rune(value)[:call.width])
+	case "default":
+		if value == "" {
+			return call.text
+		}
+	}
+	return value
+}
+
+// append formatted message to buf
+func (compiled compiledFormat) appendMessage(buf []byte, loggerMsg *loggerMessage) []byte {
+	buf, _ = compiled.appendSegments(buf, loggerMsg)
+	return buf
+}
+
+// append segments to buf, filled is true if a placeholder is not empty
+func (compiled compiledFormat) appendSegments(buf []byte, loggerMsg *loggerMessage) ([]byte, bool) {
+	filled := false
+	for _, segment := range compiled {
+		start := len(buf)
+		switch segment.placeholder {
+		case formatLiteral:
+			buf = append(buf, segment.literal...)
+			continue
+		case formatField:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, segment.literal)...)
+		case formatCall:
+			buf = append(buf, segment.call.format(loggerMsg)...)
+		case formatGroup:
+			var groupFilled bool
+			buf, groupFilled = segment.group.appendSegments(buf, loggerMsg)
+			if !groupFilled {
+				buf = buf[:start]
+			}
+		case formatTimestamp:
+			buf = strconv.AppendInt(buf, loggerMsg.Timestamp, 10)
+		case formatTimestampFormat:
+			buf = append(buf, loggerMsg.TimestampFormat...)
+		case formatMillisecond:
+			buf = strconv.AppendInt(buf, loggerMsg.Millisecond, 10)
+		case formatMillisecondFormat:
+			buf = append(buf, loggerMsg.MillisecondFormat...)
+		case formatLevel:
+			buf = strconv.AppendInt(buf, int64(loggerMsg.Level), 10)
+		case formatLevelString:
+			buf = append(buf, loggerMsg.LevelString...)
+		case formatFile:
+			buf = append(buf, loggerMsg.File...)
+		case formatLine:
+			buf = strconv.AppendInt(buf, int64(loggerMsg.Line), 10)
+		case formatFunction:
+			buf = append(buf, loggerMsg.Function...)
+		case formatBody:
+			buf = append(buf, loggerMsg.Body...)
+		case formatFields:
+			buf = append(buf, loggerMessageFields(loggerMsg.Fields)...)
+		case formatTraceId:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_TRACE_ID)...)
+		case formatSpanId:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_SPAN_ID)...)
+		case formatDeploy:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_DEPLOY)...)
+		case formatSequence:
+			buf = strconv.AppendUint(buf, loggerMsg.sequence, 10)
+		case formatHostname:
+			if loggerMsg.host != nil {
+				buf = append(buf, loggerMsg.host.hostname...)
+			}
+		case formatPid:
+			if loggerMsg.host != nil {
+				buf = strconv.AppendInt(buf, int64(loggerMsg.host.pid), 10)
+			}
+		case formatComponent:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_COMPONENT)...)
+		case formatStacktrace:
+			buf = append(buf, loggerMessageField(loggerMsg.Fields, LOGGER_FIELD_STACKTRACE)...)
+		}
+		if len(buf) > start {
+			filled = true
+		}
+	}
+	return buf, filled
+}
+
+// format message by compiled format with a pooled buffer
+func (compiled compiledFormat) format(loggerMsg *loggerMessage) string {
+	bufPtr := formatBufferPool.Get().(*[]byte)
+	buf := compiled.appendMessage((*bufPtr)[:0], loggerMsg)
+	message := string(buf)
+	// don't keep huge buffers in the pool
+	if cap(buf) <= 64*1024 {
+		*bufPtr = buf
+		formatBufferPool.Put(bufPtr)
+	}
+	return message
+}
+
+// format and json format of the level, LevelFormat and LevelJsonFormat override Format and JsonFormat
+// a level of LevelFormat is a text format unless LevelJsonFormat of the level is true
+func levelFormat(level int, format string, jsonFormat bool, levelFormats map[int]string, levelJsonFormats map[int]bool) (string, bool) {
+	if f, ok := levelFormats[level]; ok {
+		format, jsonFormat = f, false
+	}
+	if j, ok := levelJsonFormats[level]; ok {
+		jsonFormat = j
+	}
+	if !jsonFormat && format == "" {
+		format = defaultLoggerMessageFormat
+	}
+	return format, jsonFormat
+}
+
+// levels of LevelFormat and LevelJsonFormat must be registered levels
+func checkLevelFormat(levelFormats map[int]string, levelJsonFormats map[int]bool) error {
+	for level := range levelFormats {
+		if _, ok := levelNames()[level]; !ok {
+			return configError("", "LevelFormat", "key level is illegal!")
+		}
+	}
+	for level := range levelJsonFormats {
+		if _, ok := levelNames()[level]; !ok {
+			return configError("", "LevelJsonFormat", "key level is illegal!")
+		}
+	}
+	return nil
+}
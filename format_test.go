@@ -0,0 +1,55 @@
+package go_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompileFormat(t *testing.T) {
+
+	loggerMsg := newLoggerMessage(time.Unix(1521791201, 0), LOGGER_LEVEL_ERROR, "100% %file% done", map[string]interface{}{
+		LOGGER_FIELD_TRACE_ID: "abc",
+	})
+	loggerMsg.File = "main.go"
+	loggerMsg.Line = 64
+
+	formats := map[string]string{
+		"[%level_string%] %file%:%line% %body%": "[Error] main.go:64 100% %file% done",
+		"%level% %unknown% 50%":                 "3 %unknown% 50%",
+		"%trace_id%/%span_id%":                  "abc/",
+		"%%body%%":                              "%100% %file% done%",
+		"%timestamp% %timestamp_format%":        "1521791201 " + time.Unix(1521791201, 0).Format("2006-01-02 15:04:05"),
+	}
+	for format, expected := range formats {
+		if loggerMessageFormat(format, loggerMsg) != expected {
+			t.Errorf("format %q error: %q", format, loggerMessageFormat(format, loggerMsg))
+		}
+	}
+}
+
+func TestCompileFormat_Functions(t *testing.T) {
+
+	loggerMsg := newLoggerMessage(time.Unix(1521791201, 0), LOGGER_LEVEL_WARNING, "payment declined", map[string]interface{}{
+		"order": 42,
+	})
+	empty := newLoggerMessage(time.Unix(1521791201, 0), LOGGER_LEVEL_INFO, "started", nil)
+
+	formats := map[string][2]string{
+		"[%upper(level_string)%] %body%":                    {"[WARNING] payment declined", "[INFO] started"},
+		"%pad(level_string, 8)%|%lpad(line, 3)%":            {"Warning |  0", "Info    |  0"},
+		"%upper(pad(level_string,8))%|":                     {"WARNING |", "INFO    |"},
+		"%trunc(body, 7)%":                                  {"payment", "started"},
+		"%body%%{ [%fields%]}%":                             {"payment declined [order=42]", "started"},
+		"%body%%{ order=%field(order)%%{ id=%trace_id%}%}%": {"payment declined order=42", "started"},
+		"%default(field(order), -)% %lower(body)%":          {"42 payment declined", "- started"},
+		"%upper(unknown)% %pad(body)% %{ open":              {"%upper(unknown)% %pad(body)% %{ open", "%upper(unknown)% %pad(body)% %{ open"},
+	}
+	for format, expected := range formats {
+		if loggerMessageFormat(format, loggerMsg) != expected[0] {
+			t.Errorf("format %q error: %q", format, loggerMessageFormat(format, loggerMsg))
+		}
+		if loggerMessageFormat(format, empty) != expected[1] {
+			t.Errorf("format %q of empty fields error: %q", format, loggerMessageFormat(format, empty))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package go_logger
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// message formatter of adapters, the adapter adds the line ending
+type Formatter interface {
+	Format(entry *LogEntry) []byte
+}
+
+// formatter func
+type FormatterFunc func(entry *LogEntry) []byte
+
+func (f FormatterFunc) Format(entry *LogEntry) []byte {
+	return f(entry)
+}
+
+// level string of the entry
+func (entry *LogEntry) LevelString() string {
+	return levelNames()[entry.Level]
+}
+
+// formatter of built-in formatters, messages of adapters keep their formatted times, see SetAdapterTimeFormat
+type loggerMessageFormatter interface {
+	formatMessage(loggerMsg *loggerMessage) []byte
+}
+
+// json formatter, same as JsonFormat without options
+// keys are "timestamp", "timestamp_format", "millisecond", "millisecond_format", "level", "level_string",
+// "body", "file", "line", "function" and "fields"
+//
+// example, keys of an elastic mapping:
+//	&go_logger.JsonFormatter{
+//		Rename: map[string]string{"body": "message", "level_string": "level", "millisecond_format": "@timestamp"},
+//		Omit:   []string{"timestamp", "timestamp_format", "millisecond", "level"},
+//	}
+type JsonFormatter struct {
+
+	// rename keys, eg: {"body": "message"}
+	Rename map[string]string
+
+	// omit keys, eg: []string{"timestamp", "timestamp_format"}, "fields" omits all fields
+	Omit []string
+
+	// write fields as top level keys instead of the "fields" object, in key order
+	// fields named as a written key are prefixed by "fields.", eg: "fields.level"
+	FlattenFields bool
+
+	// write numbers as strings: timestamp, millisecond, level, line and number fields (not nested ones)
+	NumbersAsStrings bool
+
+	// write "<", ">" and "&" as is, they are escaped as "\u003c", "\u003e" and "\u0026" by default
+	NoEscapeHTML bool
+}
+
+func (jf *JsonFormatter) Format(entry *LogEntry) []byte {
+	return jf.formatMessage(entry.loggerMessage())
+}
+
+func (jf *JsonFormatter) formatMessage(loggerMsg *loggerMessage) []byte {
+	if len(jf.Rename) == 0 && len(jf.Omit) == 0 && !jf.FlattenFields && !jf.NumbersAsStrings {
+		return marshalLoggerMessageHTML(loggerMsg, nil, !jf.NoEscapeHTML)
+	}
+	return jf.marshal(loggerMsg)
+}
+
+// text formatter, Pattern has the placeholders of Format, default "%millisecond_format% [%level_string%] %body%"
+type TextFormatter struct {
+	Pattern string
+}
+
+func (tf *TextFormatter) Format(entry *LogEntry) []byte {
+	return tf.formatMessage(entry.loggerMessage())
+}
+
+func (tf *TextFormatter) formatMessage(loggerMsg *loggerMessage) []byte {
+	format := tf.Pattern
+	if format == "" {
+		format = defaultLoggerMessageFormat
+	}
+	return getCompiledFormat(format).appendMessage(nil, loggerMsg)
+}
+
+// logfmt formatter
+// example: time=2018-03-23T14:55:07.003+08:00 level=error msg="pay failed" file=main.go line=64 order=1001
+type LogfmtFormatter struct {
+
+	// time layout, default time.RFC3339Nano
+	TimeLayout string
+
+	// add file, line and function keys
+	Caller bool
+}
+
+func (lf *LogfmtFormatter) Format(entry *LogEntry) []byte {
+	layout := lf.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+
+	buf := make([]byte, 0, 128)
+	buf = appendLogfmt(buf, "time", entry.Time.Format(layout))
+	buf = appendLogfmt(buf, "level", strings.ToLower(entry.LevelString()))
+	buf = appendLogfmt(buf, "msg", entry.Body)
+	if lf.Caller {
+		buf = appendLogfmt(buf, "file", entry.File)
+		buf = appendLogfmt(buf, "line", strconv.Itoa(entry.Line))
+		buf = appendLogfmt(buf, "func", entry.Function)
+	}
+
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		buf = appendLogfmt(buf, key, loggerMessageField(entry.Fields, key))
+	}
+	return buf
+}
+
+// append "key=value", value is quoted if needed
+func appendLogfmt(buf []byte, key string, value string) []byte {
+	if len(buf) > 0 {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, key)...)
+	buf = append(buf, '=')
+	if logfmtNeedsQuote(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || unicode.IsControl(r) || r == 0xfffd {
+			return true
+		}
+	}
+	return false
+}
+
+// format the message with formatter
+func formatterFormat(formatter Formatter, loggerMsg *loggerMessage) string {
+	defer formatRegion()()
+	if messageFormatter, ok := formatter.(loggerMessageFormatter); ok {
+		return string(messageFormatter.formatMessage(loggerMsg))
+	}
+	entry := loggerMsg.Entry()
+	return string(formatter.Format(&entry))
+}
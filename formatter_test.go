@@ -0,0 +1,83 @@
+package go_logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+
+	entry := &LogEntry{
+		Time:   time.Date(2018, 3, 23, 14, 55, 7, 3e6, time.UTC),
+		Level:  LOGGER_LEVEL_ERROR,
+		Body:   `pay "failed"`,
+		File:   "main.go",
+		Line:   64,
+		Fields: map[string]interface{}{"order": 1001, "user name": "", "path": "/a=b"},
+	}
+	line := string((&LogfmtFormatter{Caller: true}).Format(entry))
+	expected := `time=2018-03-23T14:55:07.003Z level=error msg="pay \"failed\"" file=main.go line=64 func="" order=1001 path="/a=b" user_name=""`
+	if line != expected {
+		t.Errorf("logfmt format error:\n%s\n%s", line, expected)
+	}
+}
+
+func TestAdapterWriter_Formatter(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer:    buffer,
+		Formatter: &TextFormatter{Pattern: "%level_string%|%body%"},
+	})
+	logger.Info("text")
+
+	logger.Detach("writer")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Formatter: FormatterFunc(func(entry *LogEntry) []byte {
+			return []byte(entry.LevelString() + ":" + strings.ToUpper(entry.Body))
+		}),
+	})
+	logger.Warning("func")
+
+	if buffer.String() != "Info|text\nWarning:FUNC\n" {
+		t.Errorf("writer formatter error: %q", buffer.String())
+	}
+}
+
+func TestJsonFormatter_Options(t *testing.T) {
+
+	entry := &LogEntry{
+		Time:   time.Date(2018, 3, 23, 14, 55, 7, 3e6, time.UTC),
+		Level:  LOGGER_LEVEL_ERROR,
+		Body:   "pay <failed>",
+		Line:   64,
+		Fields: map[string]interface{}{"order": 1001, "level": "gold", "amount": 9.5, "tags": []string{"a"}},
+	}
+	if string((&JsonFormatter{}).Format(entry)) != string(marshalLoggerMessage(entry.loggerMessage(), nil)) {
+		t.Error("json formatter without options must be JsonFormat")
+	}
+
+	formatter := &JsonFormatter{
+		Rename:           map[string]string{"body": "message", "level_string": "level", "millisecond": "@timestamp"},
+		Omit:             []string{"timestamp", "timestamp_format", "millisecond_format", "level", "file", "function"},
+		FlattenFields:    true,
+		NumbersAsStrings: true,
+		NoEscapeHTML:     true,
+	}
+	expected := `{"@timestamp":"1521816907003","level":"Error","message":"pay <failed>","line":"64",` +
+		`"amount":"9.5","fields.level":"gold","order":"1001","tags":["a"]}`
+	if line := string(formatter.Format(entry)); line != expected {
+		t.Errorf("json formatter options error:\n%s\n%s", line, expected)
+	}
+
+	formatter = &JsonFormatter{Rename: map[string]string{"fields": "labels"}, Omit: []string{"timestamp", "timestamp_format", "millisecond_format", "level_string", "file", "line", "function"}}
+	expected = `{"millisecond":1521816907003,"level":3,"body":"pay \u003cfailed\u003e","labels":{"amount":9.5,"level":"gold","order":1001,"tags":["a"]}}`
+	if line := string(formatter.Format(entry)); line != expected {
+		t.Errorf("json formatter nested fields error:\n%s\n%s", line, expected)
+	}
+}
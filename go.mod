@@ -3,8 +3,10 @@ module github.com/phachon/go-logger
 go 1.12
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/fatih/color v1.7.0
 	github.com/mailru/easyjson v0.7.0
-	github.com/mattn/go-colorable v0.1.4 // indirect
+	github.com/mattn/go-colorable v0.1.4
 	github.com/mattn/go-isatty v0.0.11 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,203 @@
+package go_logger
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// go test -run TestGolden -update, rewrite golden files after intentional format changes
+var updateGolden = flag.Bool("update", false, "update golden files of testdata/golden")
+
+// canonical messages rendered by every serializer
+func goldenMessages() []*loggerMessage {
+	msgTime := time.Date(2018, 3, 23, 15, 46, 41, 970*int(time.Millisecond), time.UTC)
+	messages := []*loggerMessage{
+		newLoggerMessage(msgTime, LOGGER_LEVEL_INFO, "server started", nil),
+		newLoggerMessage(msgTime, LOGGER_LEVEL_ERROR, "pay failed", map[string]interface{}{
+			"order":  1001,
+			"amount": 12.5,
+			"paid":   false,
+			"user":   "bob smith",
+			"tags":   []string{"vip", "new"},
+		}),
+		newLoggerMessage(msgTime.Add(time.Millisecond), LOGGER_LEVEL_DEBUG, "line 1\nline 2\t\"quoted\" <b>&</b> 100% %body% \x1b[31mred\x1b[0m 中文", nil),
+		newLoggerMessage(msgTime.Add(time.Second), LOGGER_LEVEL_WARNING, "slow request", map[string]interface{}{
+			LOGGER_FIELD_TRACE_ID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			LOGGER_FIELD_SPAN_ID:  "00f067aa0ba902b7",
+			LOGGER_FIELD_DEPLOY:   "canary",
+			"empty":               "",
+		}),
+		newLoggerMessage(msgTime.Add(time.Hour), LOGGER_LEVEL_EMERGENCY, "", nil),
+	}
+	for i, loggerMsg := range messages {
+		loggerMsg.File = "main.go"
+		loggerMsg.Line = 64 + i
+		loggerMsg.Function = "main.main"
+	}
+	return messages
+}
+
+// write canonical messages to the adapter
+func goldenWrite(t *testing.T, adapter LoggerAbstract, config Config) {
+	err := adapter.Init(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, loggerMsg := range goldenMessages() {
+		err = adapter.Write(loggerMsg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	adapter.Flush()
+	if closer, ok := adapter.(LoggerCloser); ok {
+		closer.Close()
+	}
+}
+
+// render canonical messages by the writer adapter
+func goldenWriter(t *testing.T, config *WriterConfig) string {
+	buffer := &bytes.Buffer{}
+	config.Writer = buffer
+	goldenWrite(t, NewAdapterWriter(), config)
+	return buffer.String()
+}
+
+// render canonical messages by the file adapter
+func goldenFile(t *testing.T, config *FileConfig) string {
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config.Filename = path.Join(dir, "golden.log")
+	goldenWrite(t, NewAdapterFile(), config)
+	content, err := ioutil.ReadFile(config.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// render canonical messages by an http adapter, request bodies are recorded
+func goldenHttp(t *testing.T, adapter LoggerAbstract, config func(url string) Config, record func(r *http.Request) string) string {
+	buffer := &bytes.Buffer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buffer.WriteString(r.Method + " " + r.URL.Path + "\n" + record(r) + "\n")
+		w.Write([]byte(`{"errors":false}`))
+	}))
+	defer server.Close()
+	goldenWrite(t, adapter, config(server.URL))
+	return buffer.String()
+}
+
+func TestGolden(t *testing.T) {
+
+	// formatters format time in local time zone
+	defer func(local *time.Location) {
+		time.Local = local
+	}(time.Local)
+	time.Local = time.UTC
+
+	renders := map[string]func(t *testing.T) string{
+		"writer_text": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{})
+		},
+		"writer_placeholders": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{
+				Format: "%timestamp% %timestamp_format% %millisecond% %millisecond_format% %level% %level_string% " +
+					"%file%:%line% %function% %trace_id% %span_id% %deploy% %fields% | %body%",
+			})
+		},
+		"writer_json": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{JsonFormat: true})
+		},
+		"formatter_json": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{Formatter: &JsonFormatter{}})
+		},
+		"formatter_text": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{Formatter: &TextFormatter{}})
+		},
+		"formatter_logfmt": func(t *testing.T) string {
+			return goldenWriter(t, &WriterConfig{Formatter: &LogfmtFormatter{Caller: true}})
+		},
+		"console": func(t *testing.T) string {
+			buffer := &bytes.Buffer{}
+			adapter := NewAdapterConsole()
+			err := adapter.Init(&ConsoleConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			adapter.(*AdapterConsole).write.writer = buffer
+			adapter.(*AdapterConsole).write.errWriter = buffer
+			for _, loggerMsg := range goldenMessages() {
+				adapter.Write(loggerMsg)
+			}
+			return buffer.String()
+		},
+		"file_text": func(t *testing.T) string {
+			return goldenFile(t, &FileConfig{})
+		},
+		"file_json": func(t *testing.T) string {
+			return goldenFile(t, &FileConfig{JsonFormat: true})
+		},
+		"file_html": func(t *testing.T) string {
+			return goldenFile(t, &FileConfig{HtmlFormat: true, Format: defaultLoggerMessageFormat})
+		},
+		"elasticsearch": func(t *testing.T) string {
+			return goldenHttp(t, NewAdapterElasticsearch(), func(url string) Config {
+				return &ElasticsearchConfig{Url: url, Index: "app-2006.01.02"}
+			}, func(r *http.Request) string {
+				body, _ := ioutil.ReadAll(r.Body)
+				return string(body)
+			})
+		},
+		"loki": func(t *testing.T) string {
+			return goldenHttp(t, NewAdapterLoki(), func(url string) Config {
+				return &LokiConfig{Url: url, Labels: map[string]string{"job": "golden"}, LevelLabel: true, JsonFormat: true}
+			}, func(r *http.Request) string {
+				body, _ := ioutil.ReadAll(r.Body)
+				return string(body)
+			})
+		},
+		"api": func(t *testing.T) string {
+			return goldenHttp(t, NewAdapterApi(), func(url string) Config {
+				return &ApiConfig{Url: url + "/logs", Method: "POST"}
+			}, func(r *http.Request) string {
+				r.ParseForm()
+				return r.PostForm.Encode()
+			})
+		},
+	}
+
+	for name, render := range renders {
+		t.Run(name, func(t *testing.T) {
+			result := render(t)
+			goldenPath := path.Join("testdata", "golden", name+".golden")
+			if *updateGolden {
+				err := os.MkdirAll(path.Dir(goldenPath), 0755)
+				if err == nil {
+					err = ioutil.WriteFile(goldenPath, []byte(result), 0644)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := ioutil.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%v, run go test -run TestGolden -update to create it", err)
+			}
+			if result != string(golden) {
+				t.Errorf("%s is changed, run go test -run TestGolden -update if it's intended\ngot:\n%s\nwant:\n%s", goldenPath, result, golden)
+			}
+		})
+	}
+}
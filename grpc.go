@@ -0,0 +1,358 @@
+package go_logger
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const GRPC_ADAPTER_NAME = "grpc"
+
+const (
+	GRPC_DEFAULT_ENDPOINT = "http://127.0.0.1:50051"
+
+	// client streaming call of proto/logger.proto
+	GRPC_STREAM_PATH = "/gologger.v1.LogCollector/Stream"
+
+	GRPC_DEFAULT_TIMEOUT           = 10 * time.Second
+	GRPC_DEFAULT_MAX_RETRIES       = 3
+	GRPC_DEFAULT_RETRY_INTERVAL    = 500 * time.Millisecond
+	GRPC_DEFAULT_MAX_RETRY_BACKOFF = 30 * time.Second
+)
+
+// stream is ended by the collector with status OK
+var errGrpcStreamClosed = errors.New("grpc stream is closed by the collector")
+
+// adapter grpc, every message is a LogRecord of proto/logger.proto streamed to a LogCollector
+// one stream is kept open, a broken stream is opened again by the next message with backoff
+//
+// example:
+//	logger.Attach("grpc", go_logger.LOGGER_LEVEL_INFO, &go_logger.GrpcConfig{
+//		Endpoint:  "https://collector.example.com:443",
+//		Labels:    map[string]string{"service": "checkout"},
+//		KeepAlive: 5 * time.Minute,
+//	})
+type AdapterGrpc struct {
+	lock     sync.Mutex
+	config   *GrpcConfig
+	client   *http.Client
+	url      string
+	labels   []otlpKeyValue
+	stream   *grpcStream
+	sequence uint64
+	backoff  time.Duration
+	retryAt  time.Time
+}
+
+// grpc config
+type GrpcConfig struct {
+
+	// collector address, "http://" is h2c and "https://" is tls, default "http://127.0.0.1:50051"
+	// h2c needs go1.24, before it only "https://" endpoints are supported
+	Endpoint string
+
+	// tls config of https Endpoint, eg: client certificates or RootCAs of the collector
+	TLSConfig *tls.Config
+
+	// request headers (grpc metadata) of streams, eg: {"Authorization": "Bearer token"}
+	Headers map[string]string
+
+	// labels of every record, "hostname" is added if it's not set
+	Labels map[string]string
+
+	// ping the collector if the connection is idle for KeepAlive, default 0 is no ping, it needs go1.24
+	// collectors close connections pinging too often, eg: grpc-go servers allow a ping every 5 minutes by default
+	KeepAlive time.Duration
+
+	// timeout of dial, tls handshake, ping and the status of the stream when it's closed, default 10s
+	Timeout time.Duration
+
+	// a message is retried on a new stream when the stream is broken, default 3, -1 is no retry
+	MaxRetries int
+
+	// backoff of the first retry, doubled every retry up to 30s, default 500ms
+	// messages fail without retry until the backoff of the last retry is over
+	RetryInterval time.Duration
+}
+
+func (gc *GrpcConfig) Name() string {
+	return GRPC_ADAPTER_NAME
+}
+
+// client stream of records, the call runs until the stream is closed or broken
+type grpcStream struct {
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	done   chan struct{}
+	err    error // status error of the call, set before done is closed
+}
+
+func NewAdapterGrpc() LoggerAbstract {
+	return &AdapterGrpc{}
+}
+
+func (adapterGrpc *AdapterGrpc) Init(grpcConfig Config) error {
+	gc, ok := grpcConfig.(*GrpcConfig)
+	if !ok || gc == nil {
+		return configTypeError(GRPC_ADAPTER_NAME, "GrpcConfig")
+	}
+	adapterGrpc.config = gc
+
+	if gc.Endpoint == "" {
+		gc.Endpoint = GRPC_DEFAULT_ENDPOINT
+	}
+	endpoint, err := url.Parse(gc.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return configError(GRPC_ADAPTER_NAME, "Endpoint", "must be a http or https url!")
+	}
+	if gc.KeepAlive < 0 {
+		return configError(GRPC_ADAPTER_NAME, "KeepAlive", "must not be negative!")
+	}
+	if gc.Timeout <= 0 {
+		gc.Timeout = GRPC_DEFAULT_TIMEOUT
+	}
+	if gc.MaxRetries == 0 {
+		gc.MaxRetries = GRPC_DEFAULT_MAX_RETRIES
+	}
+	if gc.RetryInterval <= 0 {
+		gc.RetryInterval = GRPC_DEFAULT_RETRY_INTERVAL
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: gc.Timeout}).DialContext,
+		TLSClientConfig:     gc.TLSConfig,
+		TLSHandshakeTimeout: gc.Timeout,
+	}
+	err = forceHttp2(transport, endpoint.Scheme, gc.KeepAlive, gc.Timeout)
+	if err != nil {
+		return configError(GRPC_ADAPTER_NAME, "Endpoint", err.Error())
+	}
+	// no client timeout, streams are long-lived
+	adapterGrpc.client = &http.Client{Transport: transport}
+	adapterGrpc.url = endpoint.Scheme + "://" + endpoint.Host + GRPC_STREAM_PATH
+
+	labels := map[string]interface{}{}
+	if hostname, err := os.Hostname(); err == nil {
+		labels["hostname"] = hostname
+	}
+	for key, value := range gc.Labels {
+		labels[key] = value
+	}
+	adapterGrpc.labels = otlpAttributes(labels)
+	return nil
+}
+
+func (adapterGrpc *AdapterGrpc) Write(loggerMsg *loggerMessage) error {
+	adapterGrpc.lock.Lock()
+	defer adapterGrpc.lock.Unlock()
+
+	if wait := time.Until(adapterGrpc.retryAt); wait > 0 {
+		return fmt.Errorf("grpc collector is unavailable, retry in %s", wait.Round(time.Millisecond))
+	}
+	adapterGrpc.sequence++
+	frame := grpcFrame(appendGrpcLogRecord(nil, loggerMsg, adapterGrpc.labels, adapterGrpc.sequence))
+
+	config := adapterGrpc.config
+	for retry := 0; ; retry++ {
+		err := adapterGrpc.send(frame)
+		if err == nil {
+			adapterGrpc.backoff = 0
+			return nil
+		}
+		if adapterGrpc.backoff == 0 {
+			adapterGrpc.backoff = config.RetryInterval
+		} else if adapterGrpc.backoff *= 2; adapterGrpc.backoff > GRPC_DEFAULT_MAX_RETRY_BACKOFF {
+			adapterGrpc.backoff = GRPC_DEFAULT_MAX_RETRY_BACKOFF
+		}
+		if retry >= config.MaxRetries {
+			adapterGrpc.retryAt = time.Now().Add(adapterGrpc.backoff)
+			return err
+		}
+		time.Sleep(adapterGrpc.backoff)
+	}
+}
+
+// records are written to the stream by Write
+func (adapterGrpc *AdapterGrpc) Flush() {
+
+}
+
+// end the stream and wait for its status
+func (adapterGrpc *AdapterGrpc) Close() error {
+	adapterGrpc.lock.Lock()
+	defer adapterGrpc.lock.Unlock()
+
+	stream := adapterGrpc.stream
+	if stream == nil {
+		return nil
+	}
+	adapterGrpc.stream = nil
+	stream.writer.Close()
+	select {
+	case <-stream.done:
+		return stream.err
+	case <-time.After(adapterGrpc.config.Timeout):
+		stream.reader.CloseWithError(errors.New("grpc stream is closed"))
+		return errors.New("grpc stream status timeout")
+	}
+}
+
+func (adapterGrpc *AdapterGrpc) Name() string {
+	return GRPC_ADAPTER_NAME
+}
+
+func (adapterGrpc *AdapterGrpc) Capabilities() Capabilities {
+	return Capabilities{Binary: true, Remote: true}
+}
+
+// the open stream is healthy, the collector is dialed if there is none, unhealthy during the backoff of failures
+func (adapterGrpc *AdapterGrpc) HealthCheck() error {
+	adapterGrpc.lock.Lock()
+	stream := adapterGrpc.stream
+	retryAt := adapterGrpc.retryAt
+	adapterGrpc.lock.Unlock()
+
+	if stream != nil {
+		select {
+		case <-stream.done:
+			if stream.err != nil {
+				return stream.err
+			}
+		default:
+			return nil
+		}
+	}
+	if time.Now().Before(retryAt) {
+		return errors.New("grpc collector is unavailable, retry at " + retryAt.Format(time.RFC3339))
+	}
+	return healthCheckDialUrl(adapterGrpc.url, adapterGrpc.config.Timeout)
+}
+
+// write the frame to the stream, a new stream is opened if there is none, a broken stream is dropped
+func (adapterGrpc *AdapterGrpc) send(frame []byte) error {
+	if adapterGrpc.stream == nil {
+		stream, err := adapterGrpc.open()
+		if err != nil {
+			return err
+		}
+		adapterGrpc.stream = stream
+	}
+	stream := adapterGrpc.stream
+	_, err := stream.writer.Write(frame)
+	if err == nil {
+		return nil
+	}
+	adapterGrpc.stream = nil
+	stream.writer.CloseWithError(err)
+	// status of the call is the cause of the broken pipe
+	select {
+	case <-stream.done:
+		if stream.err != nil {
+			return stream.err
+		}
+	case <-time.After(adapterGrpc.config.Timeout):
+	}
+	return err
+}
+
+// start the streaming call, records are written to the writer of the stream
+func (adapterGrpc *AdapterGrpc) open() (*grpcStream, error) {
+	reader, writer := io.Pipe()
+	req, err := http.NewRequest("POST", adapterGrpc.url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	for key, value := range adapterGrpc.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	stream := &grpcStream{reader: reader, writer: writer, done: make(chan struct{})}
+	go func() {
+		stream.err = adapterGrpc.call(req)
+		err := stream.err
+		if err == nil {
+			err = errGrpcStreamClosed
+		}
+		reader.CloseWithError(err)
+		close(stream.done)
+	}()
+	return stream, nil
+}
+
+// run the call until the response ends, return the grpc status error
+func (adapterGrpc *AdapterGrpc) call(req *http.Request) error {
+	resp, err := adapterGrpc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("grpc stream failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+	// status is in trailers, or in headers of trailers-only responses
+	status, message := resp.Trailer.Get("grpc-status"), resp.Trailer.Get("grpc-message")
+	if status == "" {
+		status, message = resp.Header.Get("grpc-status"), resp.Header.Get("grpc-message")
+	}
+	if status == "0" {
+		return nil
+	}
+	message, _ = url.PathUnescape(message)
+	return fmt.Errorf("grpc stream failed, grpc-status=%s, grpc-message=%s", status, message)
+}
+
+// length-prefixed message of grpc, not compressed
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// LogRecord message of proto/logger.proto, values of fields are AnyValue messages of otlp
+func appendGrpcLogRecord(buf []byte, loggerMsg *loggerMessage, labels []otlpKeyValue, sequence uint64) []byte {
+	timeUnixNano := uint64(loggerMsg.nanosecond)
+	if timeUnixNano == 0 {
+		timeUnixNano = uint64(loggerMsg.Millisecond) * uint64(time.Millisecond)
+	}
+
+	buf = appendProtoFixed64(buf, 1, timeUnixNano)
+	buf = appendProtoTag(buf, 2, protoWireVarint)
+	buf = appendProtoVarint(buf, uint64(int64(loggerMsg.Level)))
+	buf = appendProtoBytes(buf, 3, []byte(loggerMsg.LevelString))
+	buf = appendProtoBytes(buf, 4, []byte(loggerMsg.Body))
+	if loggerMsg.File != "" {
+		buf = appendProtoBytes(buf, 5, []byte(loggerMsg.File))
+		buf = appendProtoTag(buf, 6, protoWireVarint)
+		buf = appendProtoVarint(buf, uint64(int64(loggerMsg.Line)))
+		buf = appendProtoBytes(buf, 7, []byte(loggerMsg.Function))
+	}
+	for _, field := range otlpAttributes(loggerMsg.Fields) {
+		buf = appendProtoBytes(buf, 8, appendOtlpKeyValue(nil, field))
+	}
+	for _, label := range labels {
+		buf = appendProtoBytes(buf, 9, appendOtlpKeyValue(nil, label))
+	}
+	buf = appendProtoTag(buf, 10, protoWireVarint)
+	return appendProtoVarint(buf, sequence)
+}
+
+func init() {
+	Register(GRPC_ADAPTER_NAME, NewAdapterGrpc)
+	RegisterConfig(GRPC_ADAPTER_NAME, func() Config {
+		return &GrpcConfig{}
+	})
+}
@@ -0,0 +1,209 @@
+//go:build go1.24
+// +build go1.24
+
+package go_logger
+
+import (
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector of test streams, records of every stream, the status of stream i is statuses[i] or "0"
+type testGrpcCollector struct {
+	lock     sync.Mutex
+	streams  [][][]byte
+	headers  []http.Header
+	statuses []string
+}
+
+func (collector *testGrpcCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.URL.Path != GRPC_STREAM_PATH || r.Header.Get("Content-Type") != "application/grpc" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	collector.lock.Lock()
+	index := len(collector.streams)
+	collector.streams = append(collector.streams, nil)
+	collector.headers = append(collector.headers, r.Header)
+	status := "0"
+	if index < len(collector.statuses) {
+		status = collector.statuses[index]
+	}
+	collector.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "grpc-status, grpc-message")
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	for {
+		prefix := make([]byte, 5)
+		if _, err := io.ReadFull(r.Body, prefix); err != nil {
+			break
+		}
+		record := make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+		if _, err := io.ReadFull(r.Body, record); err != nil {
+			break
+		}
+		collector.lock.Lock()
+		collector.streams[index] = append(collector.streams[index], record)
+		collector.lock.Unlock()
+		if status != "0" {
+			// reject the stream after its first record
+			break
+		}
+	}
+	w.Header().Set("grpc-status", status)
+	w.Header().Set("grpc-message", "collector%20restarting")
+}
+
+func (collector *testGrpcCollector) received() [][][]byte {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	return collector.streams
+}
+
+func newTestGrpcServer(collector *testGrpcCollector) *httptest.Server {
+	server := httptest.NewUnstartedServer(collector)
+	server.Config.Protocols = &http.Protocols{}
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	return server
+}
+
+func TestAdapterGrpc_Stream(t *testing.T) {
+
+	collector := &testGrpcCollector{}
+	server := newTestGrpcServer(collector)
+	defer server.Close()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("grpc", LOGGER_LEVEL_DEBUG, &GrpcConfig{
+		Endpoint: server.URL,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Labels:   map[string]string{"service": "checkout", "hostname": "web-1"},
+	})
+	logger.WriterFields(LOGGER_LEVEL_WARNING, "payment slow", map[string]interface{}{"order": 42})
+	logger.Info("payment done")
+	err := logger.Adapter("grpc").(*AdapterGrpc).Close()
+	if err != nil {
+		t.Fatalf("grpc close error: %s", err)
+	}
+
+	streams := collector.received()
+	if len(streams) != 1 || len(streams[0]) != 2 || collector.headers[0].Get("Authorization") != "Bearer token" {
+		t.Fatalf("grpc stream error: %d streams", len(streams))
+	}
+	record := streams[0][0]
+	if testProtoFieldOf(t, record, 2).value != LOGGER_LEVEL_WARNING || string(testProtoFieldOf(t, record, 3).bytes) != "Warning" ||
+		string(testProtoFieldOf(t, record, 4).bytes) != "payment slow" || testProtoFieldOf(t, record, 10).value != 1 {
+		t.Errorf("grpc record error: %v", testProtoFields(t, record))
+	}
+	if testProtoFieldOf(t, record, 1).value/uint64(time.Second) == 0 || !strings.HasSuffix(string(testProtoFieldOf(t, record, 5).bytes), "grpc_test.go") {
+		t.Errorf("grpc record time and caller error: %v", testProtoFields(t, record))
+	}
+	field := testProtoFieldOf(t, record, 8).bytes
+	if string(testProtoFieldOf(t, field, 1).bytes) != "order" || testProtoFieldOf(t, testProtoFieldOf(t, field, 2).bytes, 3).value != 42 {
+		t.Errorf("grpc record field error: %v", testProtoFields(t, field))
+	}
+	labels := []string{}
+	for _, f := range testProtoFields(t, record) {
+		if f.number == 9 {
+			labels = append(labels, string(testProtoFieldOf(t, f.bytes, 1).bytes)+"="+string(testProtoFieldOf(t, testProtoFieldOf(t, f.bytes, 2).bytes, 1).bytes))
+		}
+	}
+	if strings.Join(labels, ",") != "hostname=web-1,service=checkout" {
+		t.Errorf("grpc record labels error: %v", labels)
+	}
+	if testProtoFieldOf(t, streams[0][1], 10).value != 2 {
+		t.Errorf("grpc record sequence error: %v", testProtoFields(t, streams[0][1]))
+	}
+}
+
+func TestAdapterGrpc_Reconnect(t *testing.T) {
+
+	collector := &testGrpcCollector{statuses: []string{"14"}}
+	server := newTestGrpcServer(collector)
+	defer server.Close()
+
+	adapter := NewAdapterGrpc().(*AdapterGrpc)
+	err := adapter.Init(&GrpcConfig{Endpoint: server.URL, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first stream is rejected after the first record
+	err = adapter.Write(&loggerMessage{Body: "first"})
+	if err != nil {
+		t.Fatalf("grpc write error: %s", err)
+	}
+	<-adapter.stream.done
+	if adapter.stream.err == nil || !strings.Contains(adapter.stream.err.Error(), "grpc-status=14, grpc-message=collector restarting") {
+		t.Fatalf("grpc stream status error: %v", adapter.stream.err)
+	}
+
+	// the message is retried on a new stream
+	err = adapter.Write(&loggerMessage{Body: "second"})
+	if err != nil {
+		t.Fatalf("grpc retry error: %s", err)
+	}
+	err = adapter.Close()
+	if err != nil {
+		t.Fatalf("grpc close error: %s", err)
+	}
+	streams := collector.received()
+	if len(streams) != 2 || len(streams[1]) != 1 || string(testProtoFieldOf(t, streams[1][0], 4).bytes) != "second" || testProtoFieldOf(t, streams[1][0], 10).value != 2 {
+		t.Errorf("grpc reconnect error: %d streams", len(streams))
+	}
+}
+
+func TestAdapterGrpc_Backoff(t *testing.T) {
+
+	server := newTestGrpcServer(&testGrpcCollector{})
+	endpoint := server.URL
+	server.Close()
+
+	adapter := NewAdapterGrpc().(*AdapterGrpc)
+	err := adapter.Init(&GrpcConfig{Endpoint: endpoint, MaxRetries: 1, RetryInterval: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Close()
+
+	err = adapter.Write(&loggerMessage{Body: "lost"})
+	if err == nil || adapter.backoff != 40*time.Millisecond {
+		t.Fatalf("grpc retries error: %v, backoff %s", err, adapter.backoff)
+	}
+	// messages fail without retry until the backoff is over
+	start := time.Now()
+	err = adapter.Write(&loggerMessage{Body: "lost"})
+	if err == nil || !strings.Contains(err.Error(), "grpc collector is unavailable") || time.Since(start) > 10*time.Millisecond {
+		t.Errorf("grpc backoff error: %v", err)
+	}
+}
+
+func TestAdapterGrpc_Init(t *testing.T) {
+
+	configs := map[string]*GrpcConfig{
+		"config Endpoint must be a http or https url!": {Endpoint: "tcp://127.0.0.1:50051"},
+		"config KeepAlive must not be negative!":       {KeepAlive: -time.Second},
+	}
+	for message, config := range configs {
+		err := NewAdapterGrpc().Init(config)
+		if err == nil || err.Error() != message {
+			t.Errorf("grpc init %v error: %v", config, err)
+		}
+	}
+
+	adapter := NewAdapterGrpc().(*AdapterGrpc)
+	err := adapter.Init(&GrpcConfig{})
+	if err != nil || adapter.url != "http://127.0.0.1:50051"+GRPC_STREAM_PATH || adapter.config.MaxRetries != GRPC_DEFAULT_MAX_RETRIES {
+		t.Errorf("grpc init defaults error: %v, %s", err, adapter.url)
+	}
+}
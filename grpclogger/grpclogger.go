@@ -0,0 +1,238 @@
+// grpc interceptors logging calls by go_logger, it's a module of its own so go_logger doesn't depend on grpc
+package grpclogger
+
+import (
+	"context"
+	"github.com/phachon/go-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+	"time"
+)
+
+// metadata of request ids, the X-Request-Id header of http
+const REQUEST_ID_METADATA = "x-request-id"
+
+// fields of call records
+const (
+	FIELD_SERVICE  = "grpc_service"
+	FIELD_METHOD   = "grpc_method"
+	FIELD_TYPE     = "grpc_type"
+	FIELD_CODE     = "grpc_code"
+	FIELD_DURATION = "duration_ms"
+	FIELD_PEER     = "peer"
+	FIELD_ERROR    = "error"
+	FIELD_SLOW     = "slow"
+)
+
+// codes of calls failed by the client, they're logged by LOGGER_LEVEL_WARNING, other failed calls by LOGGER_LEVEL_ERROR
+var clientCodes = map[codes.Code]bool{
+	codes.Canceled:           true,
+	codes.InvalidArgument:    true,
+	codes.NotFound:           true,
+	codes.AlreadyExists:      true,
+	codes.PermissionDenied:   true,
+	codes.FailedPrecondition: true,
+	codes.OutOfRange:         true,
+	codes.Unauthenticated:    true,
+}
+
+// interceptor config
+type Config struct {
+
+	// log level of OK calls, default LOGGER_LEVEL_INFO
+	Level int
+
+	// OK calls take longer than SlowThreshold are logged by LOGGER_LEVEL_WARNING, 0 is disabled
+	SlowThreshold time.Duration
+
+	// full methods not logged, eg: "/grpc.health.v1.Health/Check"
+	SkipMethods []string
+}
+
+// logger of calls, records are "grpc server /checkout.Orders/Create OK" with fields of the call
+type callLog struct {
+	logger *go_logger.Logger
+	config Config
+	skip   map[string]bool
+}
+
+func newCallLog(logger *go_logger.Logger, config *Config) *callLog {
+	cl := &callLog{logger: logger, skip: map[string]bool{}}
+	if config != nil {
+		cl.config = *config
+	}
+	if cl.config.Level == go_logger.LOGGER_LEVEL_EMERGENCY {
+		cl.config.Level = go_logger.LOGGER_LEVEL_INFO
+	}
+	for _, method := range cl.config.SkipMethods {
+		cl.skip[method] = true
+	}
+	return cl
+}
+
+// log a finished call, side is "server" or "client"
+func (cl *callLog) log(ctx context.Context, side string, callType string, fullMethod string, startTime time.Time, err error) {
+	if cl.skip[fullMethod] {
+		return
+	}
+	duration := time.Since(startTime)
+	code := status.Code(err)
+
+	service, method := splitMethod(fullMethod)
+	fields := map[string]interface{}{
+		FIELD_SERVICE:  service,
+		FIELD_METHOD:   method,
+		FIELD_TYPE:     callType,
+		FIELD_CODE:     code.String(),
+		FIELD_DURATION: float64(duration.Microseconds()) / 1000,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[FIELD_PEER] = p.Addr.String()
+	}
+
+	level := cl.config.Level
+	switch {
+	case err != nil && clientCodes[code]:
+		level = go_logger.LOGGER_LEVEL_WARNING
+		fields[FIELD_ERROR] = status.Convert(err).Message()
+	case err != nil:
+		level = go_logger.LOGGER_LEVEL_ERROR
+		fields[FIELD_ERROR] = status.Convert(err).Message()
+	case cl.config.SlowThreshold > 0 && duration >= cl.config.SlowThreshold:
+		level = go_logger.LOGGER_LEVEL_WARNING
+		fields[FIELD_SLOW] = true
+	}
+	cl.logger.WriterFieldsCtx(ctx, level, "grpc "+side+" "+fullMethod+" "+code.String(), fields)
+}
+
+// server context of the call, the request id of metadata or a new id, it's sent back as header metadata
+func (cl *callLog) serverContext(ctx context.Context) context.Context {
+	requestId := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(REQUEST_ID_METADATA); len(values) > 0 && values[0] != "" && len(values[0]) <= go_logger.REQUEST_ID_MAX_LENGTH {
+			requestId = values[0]
+		}
+	}
+	if requestId == "" {
+		requestId = cl.logger.NewID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(REQUEST_ID_METADATA, requestId))
+	return go_logger.ContextWithRequestId(ctx, requestId)
+}
+
+// client context of the call, the request id of ctx is sent as metadata
+func clientContext(ctx context.Context) context.Context {
+	requestId := go_logger.RequestIdFromContext(ctx)
+	if requestId == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, REQUEST_ID_METADATA, requestId)
+}
+
+// service and method of "/package.Service/Method"
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// server interceptor logging unary calls, handlers log by XxxCtx(ctx, ...) with field "request_id"
+//
+// example:
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(grpclogger.UnaryServerInterceptor(logger, nil)),
+//		grpc.ChainStreamInterceptor(grpclogger.StreamServerInterceptor(logger, nil)),
+//	)
+func UnaryServerInterceptor(logger *go_logger.Logger, config *Config) grpc.UnaryServerInterceptor {
+	cl := newCallLog(logger, config)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		startTime := time.Now()
+		ctx = cl.serverContext(ctx)
+		resp, err := handler(ctx, req)
+		cl.log(ctx, "server", "unary", info.FullMethod, startTime, err)
+		return resp, err
+	}
+}
+
+// server interceptor logging stream calls when they end
+func StreamServerInterceptor(logger *go_logger.Logger, config *Config) grpc.StreamServerInterceptor {
+	cl := newCallLog(logger, config)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		startTime := time.Now()
+		ctx := cl.serverContext(ss.Context())
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		cl.log(ctx, "server", "stream", info.FullMethod, startTime, err)
+		return err
+	}
+}
+
+// client interceptor logging unary calls, the request id of ctx is sent as metadata "x-request-id"
+//
+// example:
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithChainUnaryInterceptor(grpclogger.UnaryClientInterceptor(logger, nil)),
+//		grpc.WithChainStreamInterceptor(grpclogger.StreamClientInterceptor(logger, nil)),
+//	)
+func UnaryClientInterceptor(logger *go_logger.Logger, config *Config) grpc.UnaryClientInterceptor {
+	cl := newCallLog(logger, config)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		startTime := time.Now()
+		err := invoker(clientContext(ctx), method, req, reply, cc, opts...)
+		cl.log(ctx, "client", "unary", method, startTime, err)
+		return err
+	}
+}
+
+// client interceptor logging stream calls, streams which fail to start are logged at once,
+// others when they end by an error of RecvMsg (io.EOF is OK)
+func StreamClientInterceptor(logger *go_logger.Logger, config *Config) grpc.StreamClientInterceptor {
+	cl := newCallLog(logger, config)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		startTime := time.Now()
+		cs, err := streamer(clientContext(ctx), desc, cc, method, opts...)
+		if err != nil {
+			cl.log(ctx, "client", "stream", method, startTime, err)
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, log: func(err error) {
+			cl.log(ctx, "client", "stream", method, startTime, err)
+		}}, nil
+	}
+}
+
+// server stream with the context of the request id
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+// client stream logged once by the first error of RecvMsg
+type clientStream struct {
+	grpc.ClientStream
+	log    func(err error)
+	logged bool
+}
+
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if err != nil && !cs.logged {
+		cs.logged = true
+		if err == io.EOF {
+			cs.log(nil)
+		} else {
+			cs.log(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package grpclogger
+
+import (
+	"context"
+	"github.com/phachon/go-logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, logger *go_logger.Logger) grpc_health_v1.HealthClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(logger, &Config{SkipMethods: []string{"/grpc.health.v1.Health/List"}})),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(logger, nil)),
+	)
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(UnaryClientInterceptor(logger, nil)),
+		grpc.WithChainStreamInterceptor(StreamClientInterceptor(logger, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.TestConfig{})
+	recorded := logger.Adapter("test").(*go_logger.AdapterTest)
+	client := newTestClient(t, logger)
+
+	ctx := go_logger.ContextWithRequestId(context.Background(), "req-1")
+	header := metadata.MD{}
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "missing"})
+	if err == nil {
+		t.Fatal("check of missing service error")
+	}
+	client.List(ctx, &grpc_health_v1.HealthListRequest{})
+
+	entries := recorded.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("grpc records error: %v", entries)
+	}
+	server, client0 := entries[0], entries[1]
+	if server.Body != "grpc server /grpc.health.v1.Health/Check OK" || server.Level != go_logger.LOGGER_LEVEL_INFO ||
+		server.Fields[FIELD_SERVICE] != "grpc.health.v1.Health" || server.Fields[FIELD_METHOD] != "Check" || server.Fields[FIELD_CODE] != "OK" ||
+		server.Fields["request_id"] != "req-1" || server.Fields[FIELD_PEER] == nil {
+		t.Errorf("grpc server record error: %v", server)
+	}
+	if client0.Body != "grpc client /grpc.health.v1.Health/Check OK" || client0.Fields["request_id"] != "req-1" || len(header.Get(REQUEST_ID_METADATA)) != 1 {
+		t.Errorf("grpc client record error: %v, %v", client0, header)
+	}
+	if _, ok := client0.Fields[FIELD_DURATION].(float64); !ok {
+		t.Errorf("grpc duration error: %v", client0.Fields)
+	}
+	if entries[2].Level != go_logger.LOGGER_LEVEL_WARNING || entries[2].Fields[FIELD_CODE] != "NotFound" || entries[2].Fields[FIELD_ERROR] != "unknown service" {
+		t.Errorf("grpc failed call record error: %v", entries[2])
+	}
+	// List is skipped by the server, it's not implemented by the health server
+	if entries[4].Body != "grpc client /grpc.health.v1.Health/List Unimplemented" || entries[4].Level != go_logger.LOGGER_LEVEL_ERROR {
+		t.Errorf("grpc skipped method error: %v", entries[4])
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.TestConfig{})
+	recorded := logger.Adapter("test").(*go_logger.AdapterTest)
+	client := newTestClient(t, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	stream.Recv()
+	stream.Recv()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(recorded.Entries()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	matched := recorded.Match(go_logger.MatchField(FIELD_TYPE, "stream"), go_logger.MatchField(FIELD_CODE, "Canceled"))
+	if len(matched) != 2 || len(recorded.Match(go_logger.MatchBody("grpc client"))) != 1 {
+		t.Errorf("grpc stream records error: %v", recorded.Entries())
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+	service, method := splitMethod("/checkout.v1.Orders/Create")
+	if service != "checkout.v1.Orders" || method != "Create" {
+		t.Errorf("split method error: %s %s", service, method)
+	}
+}
@@ -0,0 +1,184 @@
+package go_logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hash of the record before the first record of a file
+const FILE_HASH_CHAIN_GENESIS = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// record written when a chained file is closed or rotated, files truncated after it are not sealed
+const FILE_HASH_CHAIN_SEAL = `{"chain_seal":true}`
+
+// tamper-evident records of audit files, every record ends with the hash of the previous hash and the record
+// json records get field "chain", other records end with " chain=<hash>", see VerifyHashChain()
+type FileHashChain struct {
+
+	// hmac-sha256 key of hashes, records can't be rewritten without the key, empty is sha256
+	Key []byte
+}
+
+// result of VerifyHashChain()
+type HashChainReport struct {
+
+	// chained records, seals included
+	Records int64
+
+	// hash of the last record, FILE_HASH_CHAIN_GENESIS if there is no record
+	// keep it elsewhere to detect truncation of files not sealed yet
+	LastHash string
+
+	// the last record is a seal, rotated files which are not sealed are truncated
+	Sealed bool
+}
+
+// record of a file breaks the hash chain
+type HashChainError struct {
+	Line   int64
+	Reason string
+}
+
+func (err *HashChainError) Error() string {
+	return "logger: hash chain is broken at line " + strconv.FormatInt(err.Line, 10) + ", " + err.Reason
+}
+
+// end of chained records, group 1 is the hash
+var (
+	hashChainJsonSuffix = regexp.MustCompile(`,"chain":"([0-9a-f]{64})"}$`)
+	hashChainTextSuffix = regexp.MustCompile(` chain=([0-9a-f]{64})$`)
+)
+
+// hash chain of the records of a file writer
+type hashChain struct {
+	key  []byte
+	last string
+}
+
+func newHashChain(config *FileHashChain) *hashChain {
+	return &hashChain{key: config.Key, last: FILE_HASH_CHAIN_GENESIS}
+}
+
+// hash of the record chained to the previous hash
+func (chain *hashChain) next(record string) string {
+	var h hash.Hash
+	if len(chain.key) > 0 {
+		h = hmac.New(sha256.New, chain.key)
+	} else {
+		h = sha256.New()
+	}
+	io.WriteString(h, chain.last)
+	io.WriteString(h, "\n")
+	io.WriteString(h, record)
+	chain.last = hex.EncodeToString(h.Sum(nil))
+	return chain.last
+}
+
+// record with its chained hash
+func (chain *hashChain) chain(record string) string {
+	hash := chain.next(record)
+	if len(record) > 2 && strings.HasPrefix(record, "{") && strings.HasSuffix(record, "}") {
+		return record[:len(record)-1] + `,"chain":"` + hash + `"}`
+	}
+	return record + " chain=" + hash
+}
+
+// chain the records of msg ending with lineEnding, json lines are records, a text message is one record
+func (chain *hashChain) chainRecords(msg string, lineEnding string, lines bool) string {
+	if !lines {
+		return chain.chain(strings.TrimSuffix(msg, lineEnding)) + lineEnding
+	}
+	chained := ""
+	for _, record := range strings.SplitAfter(msg, lineEnding) {
+		if record != "" {
+			chained += chain.chain(strings.TrimSuffix(record, lineEnding)) + lineEnding
+		}
+	}
+	return chained
+}
+
+// continue the chain of the opened file from its last record, a new file starts from FILE_HASH_CHAIN_GENESIS
+func (fw *FileWriter) continueChain() error {
+	file, err := fw.fs.OpenFile(fw.filename, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if fw.gzip {
+		gzipReader, err := gzip.NewReader(file)
+		if err == nil {
+			defer gzipReader.Close()
+			r = gzipReader
+		} else {
+			// empty file
+			r = strings.NewReader("")
+		}
+	}
+	// records of a broken chain are kept, the chain continues from the last valid record
+	report, _ := VerifyHashChain(r, fw.hashChain.Key)
+	fw.chain = newHashChain(fw.hashChain)
+	fw.chain.last = report.LastHash
+	return nil
+}
+
+// verify the hash chain of records of FileConfig HashChain, key is the hmac key or empty
+// errors of broken chains are *HashChainError, the report counts the records before it
+//
+// example:
+//	file, _ := os.Open("./audit.log")
+//	report, err := go_logger.VerifyHashChain(file, key)
+func VerifyHashChain(r io.Reader, key []byte) (*HashChainReport, error) {
+	chain := &hashChain{key: key, last: FILE_HASH_CHAIN_GENESIS}
+	report := &HashChainReport{LastHash: FILE_HASH_CHAIN_GENESIS}
+	reader := bufio.NewReader(r)
+
+	record := ""
+	recordLine := int64(1)
+	lineNumber := int64(0)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			lineNumber++
+			content := strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			match := hashChainJsonSuffix.FindStringSubmatchIndex(content)
+			if match != nil {
+				record += content[:match[0]] + "}"
+			} else if match = hashChainTextSuffix.FindStringSubmatchIndex(content); match != nil {
+				record += content[:match[0]]
+			} else {
+				// lines of a text record
+				record += line
+				if err == nil {
+					continue
+				}
+				return report, &HashChainError{Line: recordLine, Reason: "record has no chain hash"}
+			}
+			hash := content[match[2]:match[3]]
+			if expected := chain.next(record); expected != hash {
+				return report, &HashChainError{Line: recordLine, Reason: "hash of the record is " + hash + ", expected " + expected}
+			}
+			report.Records++
+			report.LastHash = hash
+			report.Sealed = record == FILE_HASH_CHAIN_SEAL
+			record = ""
+			recordLine = lineNumber + 1
+		}
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package go_logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestFileConfig_HashChain(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "audit.log")
+	key := []byte("audit key")
+
+	write := func(config *FileConfig, bodies ...string) {
+		config.Filename = filename
+		config.HashChain = &FileHashChain{Key: key}
+		logger := NewLogger()
+		logger.Detach("console")
+		err := logger.Attach("file", LOGGER_LEVEL_DEBUG, config)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, body := range bodies {
+			logger.Info(body)
+		}
+		logger.Close(context.Background())
+	}
+	verify := func(content string, key []byte) (*HashChainReport, error) {
+		return VerifyHashChain(strings.NewReader(content), key)
+	}
+
+	// text records, multiline bodies are one record, the json logger continues the chain of the reopened file
+	write(&FileConfig{Format: "%level_string% %body%"}, "user root logged in", "sudo\nrm -rf /tmp/cache")
+	write(&FileConfig{JsonFormat: true}, "password changed")
+	content, _ := ioutil.ReadFile(filename)
+	report, err := verify(string(content), key)
+	if err != nil || report.Records != 5 || !report.Sealed {
+		t.Fatalf("hash chain verify error: %v %+v\n%s", err, report, content)
+	}
+	lines := strings.Split(string(content), "\n")
+	if !strings.HasPrefix(lines[0], "Info user root logged in chain=") || !strings.Contains(lines[4], `"body":"password changed"`) || !strings.Contains(lines[4], `,"chain":"`) {
+		t.Errorf("chained records error:\n%s", content)
+	}
+
+	if _, err := verify(string(content), []byte("other key")); err == nil {
+		t.Error("records of another key must break the chain")
+	}
+	tampered := strings.Replace(string(content), "user root", "user evil", 1)
+	if _, err := verify(tampered, key); err == nil || err.(*HashChainError).Line != 1 {
+		t.Errorf("tampered record error: %v", err)
+	}
+	removed := strings.Join(append(lines[:1:1], lines[2:]...), "\n")
+	if _, err := verify(removed, key); err == nil || err.(*HashChainError).Line != 2 {
+		t.Errorf("removed record error: %v", err)
+	}
+	truncated := strings.Join(lines[:5], "\n") + "\n"
+	report, err = verify(truncated, key)
+	if err != nil || report.Sealed || report.Records != 4 {
+		t.Errorf("truncated file must not be sealed: %v %+v", err, report)
+	}
+
+	if NewAdapterFile().Init(&FileConfig{Filename: filename, HashChain: &FileHashChain{}, Encryption: &FileEncryption{Key: make([]byte, 16)}}) == nil {
+		t.Error("hash chain with encryption must return error")
+	}
+}
@@ -0,0 +1,200 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// timeout of one health check of Health()
+const HEALTH_DEFAULT_TIMEOUT = 5 * time.Second
+
+// adapter checks it can write, optional
+// network adapters reach their endpoint, the file adapter checks its directory is writable and has disk space
+type LoggerHealthChecker interface {
+	HealthCheck() error
+}
+
+// health of an attached adapter
+type AdapterHealth struct {
+	Name    string        `json:"name"`
+	Adapter string        `json:"adapter"`
+	Healthy bool          `json:"healthy"`
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency_ns"`
+
+	// adapter has a health check, adapters without it are healthy
+	Checked bool `json:"checked"`
+}
+
+// health of all attached adapters
+type HealthReport struct {
+	Healthy  bool            `json:"healthy"`
+	Adapters []AdapterHealth `json:"adapters"`
+}
+
+// check all attached adapters concurrently, a check is unhealthy if it's not done in HEALTH_DEFAULT_TIMEOUT
+// adapters written to their fallback are unhealthy
+//
+// example:
+//	if report := logger.Health(); !report.Healthy {
+//		fmt.Println(report.Error())
+//	}
+func (logger *Logger) Health() *HealthReport {
+	logger.lock.Lock()
+	outputs := make([]*outputLogger, len(logger.outputs))
+	copy(outputs, logger.outputs)
+	logger.lock.Unlock()
+
+	report := &HealthReport{Healthy: true, Adapters: make([]AdapterHealth, len(outputs))}
+	wait := sync.WaitGroup{}
+	for i, output := range outputs {
+		wait.Add(1)
+		go func(health *AdapterHealth, output *outputLogger) {
+			defer wait.Done()
+			*health = output.health(HEALTH_DEFAULT_TIMEOUT)
+		}(&report.Adapters[i], output)
+	}
+	wait.Wait()
+
+	for _, health := range report.Adapters {
+		if !health.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+// errors of unhealthy adapters, nil if all adapters are healthy
+func (report *HealthReport) Error() error {
+	message := ""
+	for _, health := range report.Adapters {
+		if !health.Healthy {
+			if message != "" {
+				message += "; "
+			}
+			message += "adapter " + health.Name + ": " + health.Error
+		}
+	}
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
+
+// http handler of readiness probes, 200 if all adapters are healthy, 503 otherwise, the body is the json report
+//
+// example:
+//	http.Handle("/ready", logger.HealthHandler())
+func (logger *Logger) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := logger.Health()
+		body, _ := json.Marshal(report)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !report.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write(body)
+	})
+}
+
+// health of the output, the check is abandoned after timeout
+func (output *outputLogger) health(timeout time.Duration) AdapterHealth {
+	health := AdapterHealth{Name: output.Name, Adapter: output.LoggerAbstract.Name(), Healthy: true}
+	if fallback, ok := output.fallback.Load().(*adapterFallback); ok && fallback != nil {
+		fallback.lock.Lock()
+		down := fallback.down
+		fallback.lock.Unlock()
+		if down {
+			health.Healthy = false
+			health.Error = "adapter is down, messages are written to fallback " + fallback.config.Adapter
+		}
+	}
+	checker, ok := output.LoggerAbstract.(LoggerHealthChecker)
+	if !ok {
+		return health
+	}
+	health.Checked = true
+
+	startTime := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- checker.HealthCheck()
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-time.After(timeout):
+		err = errors.New("health check timeout")
+	}
+	health.Latency = time.Since(startTime)
+	if err != nil {
+		health.Healthy = false
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// request the url, network errors, 5xx and auth failures (401, 403) are unhealthy
+// setHeader sets auth and headers of the adapter to the request
+func healthCheckHttp(client *http.Client, method string, rawUrl string, setHeader func(req *http.Request)) error {
+	req, err := http.NewRequest(method, rawUrl, nil)
+	if err != nil {
+		return err
+	}
+	setHeader(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return errors.New("health check " + rawUrl + " failed, code=" + strconv.Itoa(resp.StatusCode))
+	}
+	return nil
+}
+
+// dial the host of the url, default port by scheme
+func healthCheckDialUrl(rawUrl string, timeout time.Duration) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	address := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+	return healthCheckDial("tcp", address, timeout)
+}
+
+func healthCheckDial(network string, address string, timeout time.Duration) error {
+	conn, err := net.DialTimeout(network, address, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// create and remove a file in the directory
+func healthCheckWritable(dirPath string) error {
+	file, err := ioutil.TempFile(dirPath, ".health-")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -0,0 +1,113 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLogger_Health(t *testing.T) {
+
+	var status int32 = http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer server.Close()
+
+	logger, _ := newTestFileLogger(t, nil)
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	err := logger.Attach("api", LOGGER_LEVEL_DEBUG, &ApiConfig{Url: server.URL, Method: "POST", BearerToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := logger.Health()
+	if !report.Healthy || len(report.Adapters) != 3 || report.Error() != nil {
+		t.Fatalf("health error: %v", report)
+	}
+	for _, health := range report.Adapters {
+		if health.Checked != (health.Name != "test") {
+			t.Errorf("health check of adapter %s error: %v", health.Name, health)
+		}
+	}
+
+	atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+	report = logger.Health()
+	if report.Healthy || report.Adapters[2].Healthy || !strings.Contains(report.Error().Error(), "adapter api: health check "+server.URL+" failed, code=503") {
+		t.Errorf("health unhealthy api error: %v", report)
+	}
+
+	// the api is down, messages are written to the file
+	atomic.StoreInt32(&status, http.StatusOK)
+	logger.SetAdapterFallback("api", &FallbackConfig{Adapter: "file"})
+	fallback := logger.outputs[2].fallback.Load().(*adapterFallback)
+	fallback.lock.Lock()
+	fallback.down = true
+	fallback.lock.Unlock()
+	report = logger.Health()
+	if report.Healthy || report.Adapters[2].Error != "adapter is down, messages are written to fallback file" {
+		t.Errorf("health fallback error: %v", report)
+	}
+}
+
+func TestLogger_HealthHandler(t *testing.T) {
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	address := server.URL
+	server.Close()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("slack", LOGGER_LEVEL_ERROR, &SlackConfig{Url: address + "/hooks", Timeout: time.Second})
+
+	w := httptest.NewRecorder()
+	logger.HealthHandler().ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	report := &HealthReport{}
+	err := json.Unmarshal(w.Body.Bytes(), report)
+	if err != nil || w.Code != http.StatusServiceUnavailable || report.Healthy || len(report.Adapters) != 1 || report.Adapters[0].Error == "" {
+		t.Errorf("health handler error: %d %s", w.Code, w.Body.String())
+	}
+
+	logger.Detach("slack")
+	w = httptest.NewRecorder()
+	logger.HealthHandler().ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"healthy":true,"adapters":[]}` {
+		t.Errorf("health handler error: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdapterFile_HealthCheck(t *testing.T) {
+
+	dir := t.TempDir()
+	adapter := NewAdapterFile().(*AdapterFile)
+	err := adapter.Init(&FileConfig{Filename: filepath.Join(dir, "app.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Flush()
+
+	if err := adapter.HealthCheck(); err != nil {
+		t.Errorf("file health check error: %s", err)
+	}
+	adapter.setDiskState(DISK_STATE_STOP)
+	if err := adapter.HealthCheck(); err == nil {
+		t.Error("file health check of stopped disk error")
+	}
+	adapter.setDiskState(DISK_STATE_NORMAL)
+
+	os.Chmod(dir, 0500)
+	defer os.Chmod(dir, 0700)
+	if os.Getuid() != 0 && adapter.HealthCheck() == nil {
+		t.Error("file health check of read only directory error")
+	}
+}
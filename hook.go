@@ -0,0 +1,86 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// hook returns ErrHookDrop to drop the message silently
+var ErrHookDrop = errors.New("logger: message dropped by hook")
+
+// logger hook, runs before adapters write, the entry can be modified
+// return error to drop the message, errors except ErrHookDrop are printed to stderr
+type Hook func(entry *LogEntry) error
+
+// add hook, hooks run in added order
+//
+// example:
+//	hostname, _ := os.Hostname()
+//	logger.AddHook(func(entry *go_logger.LogEntry) error {
+//		entry.Fields["hostname"] = hostname
+//		return nil
+//	})
+func (logger *Logger) AddHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	hooks, _ := logger.hooks.Load().([]Hook)
+	newHooks := make([]Hook, 0, len(hooks)+1)
+	newHooks = append(newHooks, hooks...)
+	logger.hooks.Store(append(newHooks, hook))
+}
+
+// remove all hooks
+func (logger *Logger) ClearHooks() {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	logger.hooks.Store([]Hook{})
+}
+
+// run hooks, return the modified message or false if dropped
+func (logger *Logger) runHooks(loggerMsg *loggerMessage) (*loggerMessage, bool) {
+	hooks, _ := logger.hooks.Load().([]Hook)
+	if len(hooks) == 0 {
+		return loggerMsg, true
+	}
+
+	entry := loggerMsg.Entry()
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for key, value := range entry.Fields {
+		fields[key] = value
+	}
+	entry.Fields = fields
+
+	for _, hook := range hooks {
+		err := hook(&entry)
+		if err == ErrHookDrop {
+			return nil, false
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: hook dropped message, error: %v\n", err)
+			return nil, false
+		}
+	}
+	if levelNames()[entry.Level] == "" {
+		fmt.Fprintf(os.Stderr, "logger: hook dropped message, level %s is illegal\n", strconv.Itoa(entry.Level))
+		return nil, false
+	}
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+
+	// sequence, hostname and pid are stamped before hooks
+	hookMsg := entry.loggerMessage()
+	hookMsg.sequence = loggerMsg.sequence
+	hookMsg.host = loggerMsg.host
+	hookMsg.verbose = loggerMsg.verbose
+	hookMsg.boosted = loggerMsg.boosted
+	hookMsg.batched = loggerMsg.batched
+	return hookMsg, true
+}
@@ -0,0 +1,88 @@
+package go_logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_AddHook(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	logger.AddHook(func(entry *LogEntry) error {
+		entry.Fields["hostname"] = "host-1"
+		return nil
+	})
+	logger.AddHook(func(entry *LogEntry) error {
+		if strings.Contains(entry.Body, "noise") {
+			return ErrHookDrop
+		}
+		if strings.Contains(entry.Body, "broken") {
+			return errors.New("broken hook")
+		}
+		entry.Body = strings.Replace(entry.Body, "secret", "******", -1)
+		return nil
+	})
+
+	fields := map[string]interface{}{"user": "go"}
+	logger.WriterFields(LOGGER_LEVEL_INFO, "token secret", fields)
+	logger.Info("noise")
+	logger.Info("broken")
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("logger hook drop error: %v", entries)
+	}
+	if entries[0].Body != "token ******" || entries[0].Fields["hostname"] != "host-1" || entries[0].Fields["user"] != "go" {
+		t.Errorf("logger hook modify error: %v", entries[0])
+	}
+	if entries[0].File != "hook_test.go" {
+		t.Errorf("logger hook file error: %s", entries[0].File)
+	}
+	if _, ok := fields["hostname"]; ok {
+		t.Error("logger hook must not modify the fields of the caller")
+	}
+
+	logger.ClearHooks()
+	logger.Info("noise")
+	if len(memoryAdapter.Entries()) != 2 {
+		t.Error("logger clear hooks error")
+	}
+}
+
+func TestLogger_AddHookStamp(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "#%sequence% %hostname%[%pid%] %body%"})
+	logger.SetProviders(Providers{
+		Now: func() time.Time {
+			return time.Unix(1521791201, 123456789)
+		},
+		Hostname: func() string {
+			return "web-1"
+		},
+		Pid: func() int {
+			return 7
+		},
+	})
+	logger.AddHook(func(entry *LogEntry) error {
+		if entry.Time.Nanosecond() != 123456789 {
+			t.Errorf("hook entry time error: %v", entry.Time)
+		}
+		entry.Body = "hooked"
+		return nil
+	})
+	logger.Info("message")
+	logger.Info("message")
+	if buffer.String() != "#1 web-1[7] hooked\n#2 web-1[7] hooked\n" {
+		t.Errorf("hook stamp output error: %q", buffer.String())
+	}
+}
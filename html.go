@@ -0,0 +1,24 @@
+package go_logger
+
+import (
+	"html"
+)
+
+// inline styles of levels, same colors as the memory adapter page
+var htmlLevelStyles = map[int]string{
+	LOGGER_LEVEL_EMERGENCY: "color:#b00;font-weight:bold",
+	LOGGER_LEVEL_ALERT:     "color:#b00;font-weight:bold",
+	LOGGER_LEVEL_CRITICAL:  "color:#b00;font-weight:bold",
+	LOGGER_LEVEL_ERROR:     "color:#d00",
+	LOGGER_LEVEL_WARNING:   "color:#c80",
+	LOGGER_LEVEL_NOTICE:    "color:#080",
+	LOGGER_LEVEL_INFO:      "color:#06c",
+	LOGGER_LEVEL_DEBUG:     "color:#888",
+}
+
+// html row of the message, the text of format is escaped and colored by level with inline style
+// inline style is kept by mail clients, rows can be emailed or served as is
+func loggerMessageHtml(format string, loggerMsg *loggerMessage) string {
+	return `<div class="log level-` + loggerMsg.LevelString + `" style="font-family:monospace;white-space:pre-wrap;` +
+		htmlLevelStyles[loggerMsg.Level] + `">` + html.EscapeString(loggerMessageFormat(format, loggerMsg)) + `</div>`
+}
@@ -0,0 +1,25 @@
+//go:build go1.24
+// +build go1.24
+
+package go_logger
+
+import (
+	"net/http"
+	"time"
+)
+
+// http/2 only transport of grpc, prior knowledge (h2c) of "http://" endpoints
+// pings are sent every keepAlive if it's positive, the connection is closed if a ping isn't answered in timeout
+func forceHttp2(transport *http.Transport, scheme string, keepAlive time.Duration, timeout time.Duration) error {
+	protocols := &http.Protocols{}
+	if scheme == "http" {
+		protocols.SetUnencryptedHTTP2(true)
+	} else {
+		protocols.SetHTTP2(true)
+	}
+	transport.Protocols = protocols
+	if keepAlive > 0 {
+		transport.HTTP2 = &http.HTTP2Config{SendPingTimeout: keepAlive, PingTimeout: timeout}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !go1.24
+// +build !go1.24
+
+package go_logger
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// http/2 of grpc is negotiated by tls before go1.24, "http://" endpoints need go1.24 for prior knowledge (h2c)
+// pings are not sent, keepAlive is ignored
+func forceHttp2(transport *http.Transport, scheme string, keepAlive time.Duration, timeout time.Duration) error {
+	if scheme == "http" {
+		return errors.New("http:// endpoints need go1.24, use https://")
+	}
+	transport.ForceAttemptHTTP2 = true
+	return nil
+}
@@ -0,0 +1,179 @@
+package go_logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// default max dumped body size (byte)
+const HTTP_DUMP_DEFAULT_MAX_BODY_SIZE = 4096
+
+// default dumped headers, others are omitted
+var DefaultHttpDumpHeaders = []string{
+	"Accept", "Content-Encoding", "Content-Length", "Content-Type", "Location",
+	"Retry-After", "User-Agent", "X-Request-Id",
+}
+
+// http dump config
+type HttpDumpConfig struct {
+
+	// allowlist of dumped headers, case insensitive, default DefaultHttpDumpHeaders
+	// secret headers in it (eg: "Authorization") are masked by the redactor of the logger
+	Headers []string
+
+	// max dumped body size (byte), default 4096, -1 is no body
+	MaxBodySize int
+
+	// sanitize body before dump, eg: mask password
+	BodySanitizer func(body []byte) []byte
+}
+
+// log the dump of the request as field "request"
+// the body is read up to MaxBodySize and req.Body still reads everything
+//
+// example:
+//	logger.DumpRequest(go_logger.LOGGER_LEVEL_DEBUG, "payment api request", req, nil)
+func (logger *Logger) DumpRequest(level int, msg string, req *http.Request, config *HttpDumpConfig) {
+	logger.write(2, level, msg, map[string]interface{}{"request": HttpRequestFields(req, config)})
+}
+
+// log the dump of the response as field "response"
+// the body is read up to MaxBodySize and resp.Body still reads everything
+func (logger *Logger) DumpResponse(level int, msg string, resp *http.Response, config *HttpDumpConfig) {
+	logger.write(2, level, msg, map[string]interface{}{"response": HttpResponseFields(resp, config)})
+}
+
+// dump fields of the request: method, url, proto, headers, body, body_size, body_truncated
+func HttpRequestFields(req *http.Request, config *HttpDumpConfig) map[string]interface{} {
+	config = httpDumpConfig(config)
+
+	fields := map[string]interface{}{
+		"method":  req.Method,
+		"url":     httpDumpUrl(req.URL),
+		"proto":   req.Proto,
+		"headers": httpDumpHeaders(req.Header, config),
+	}
+	if req.Host != "" && req.Host != req.URL.Host {
+		fields["host"] = req.Host
+	}
+	if config.MaxBodySize > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Body = httpDumpBody(req.Body, req.Header, config, fields)
+	}
+	return fields
+}
+
+// dump fields of the response: status, status_code, proto, headers, body, body_size, body_truncated
+// method and url of the request too
+func HttpResponseFields(resp *http.Response, config *HttpDumpConfig) map[string]interface{} {
+	config = httpDumpConfig(config)
+
+	fields := map[string]interface{}{
+		"status":      resp.Status,
+		"status_code": resp.StatusCode,
+		"proto":       resp.Proto,
+		"headers":     httpDumpHeaders(resp.Header, config),
+	}
+	if resp.Request != nil {
+		fields["method"] = resp.Request.Method
+		fields["url"] = httpDumpUrl(resp.Request.URL)
+	}
+	if config.MaxBodySize > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = httpDumpBody(resp.Body, resp.Header, config, fields)
+	}
+	return fields
+}
+
+// url without password
+func httpDumpUrl(u *url.URL) string {
+	dumpUrl := *u
+	if dumpUrl.User != nil {
+		dumpUrl.User = url.User(dumpUrl.User.Username())
+	}
+	return dumpUrl.String()
+}
+
+func httpDumpConfig(config *HttpDumpConfig) *HttpDumpConfig {
+	if config == nil {
+		config = &HttpDumpConfig{}
+	}
+	dumpConfig := *config
+	if dumpConfig.Headers == nil {
+		dumpConfig.Headers = DefaultHttpDumpHeaders
+	}
+	if dumpConfig.MaxBodySize == 0 {
+		dumpConfig.MaxBodySize = HTTP_DUMP_DEFAULT_MAX_BODY_SIZE
+	}
+	return &dumpConfig
+}
+
+// allowed headers, values of a header are joined by ", "
+func httpDumpHeaders(header http.Header, config *HttpDumpConfig) map[string]interface{} {
+	headers := map[string]interface{}{}
+	for _, name := range config.Headers {
+		values := header[http.CanonicalHeaderKey(name)]
+		if len(values) > 0 {
+			headers[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+// dump the body to fields, return a body which still reads everything
+// binary bodies are dumped as "binary, 1024 bytes"
+func httpDumpBody(body io.ReadCloser, header http.Header, config *HttpDumpConfig, fields map[string]interface{}) io.ReadCloser {
+	buf, _ := ioutil.ReadAll(io.LimitReader(body, int64(config.MaxBodySize)+1))
+	readBody := &peekedBody{
+		Reader: io.MultiReader(bytes.NewReader(buf), body),
+		Closer: body,
+	}
+
+	truncated := len(buf) > config.MaxBodySize
+	dump := buf
+	if truncated {
+		dump = trimPartialRune(buf[:config.MaxBodySize])
+	}
+	fields["body_size"] = len(dump)
+	fields["body_truncated"] = truncated
+	if isBinaryBody(header, dump) {
+		fields["body"] = fmt.Sprintf("binary, %d bytes", len(dump))
+		return readBody
+	}
+	if config.BodySanitizer != nil {
+		dump = config.BodySanitizer(dump)
+	}
+	fields["body"] = string(dump)
+	return readBody
+}
+
+// body is binary by content type, content encoding or content
+func isBinaryBody(header http.Header, body []byte) bool {
+	if encoding := header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return true
+	}
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && !isTextMediaType(mediaType) {
+			return true
+		}
+	}
+	return !utf8.Valid(body) || bytes.IndexByte(body, 0) >= 0
+}
+
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "javascript", "x-www-form-urlencoded", "yaml", "graphql"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
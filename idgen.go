@@ -0,0 +1,235 @@
+package go_logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// request id header of RequestIdHandler
+const REQUEST_ID_HEADER = "X-Request-Id"
+
+// max length of request ids of the request header, longer ids are replaced
+const REQUEST_ID_MAX_LENGTH = 128
+
+// start time of snowflake ids, default 2010-11-04 01:42:54.657 UTC
+var SNOWFLAKE_DEFAULT_EPOCH = time.Unix(0, 1288834974657*int64(time.Millisecond))
+
+// generator of correlation ids, eg: request ids
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGenerator of func
+type IDGeneratorFunc func() string
+
+func (fn IDGeneratorFunc) NewID() string {
+	return fn()
+}
+
+// random uuid version 4, eg: "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+type UUIDv4Generator struct{}
+
+func (generator UUIDv4Generator) NewID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return formatUUID(id)
+}
+
+// time ordered uuid version 7 of unix milliseconds, eg: "018f3c5e-7b2a-7c4d-9e1f-2a3b4c5d6e7f"
+type UUIDv7Generator struct{}
+
+func (generator UUIDv7Generator) NewID() string {
+	var id [16]byte
+	rand.Read(id[6:])
+	putMillisecond(id[:6], time.Now())
+	id[6] = id[6]&0x0f | 0x70
+	id[8] = id[8]&0x3f | 0x80
+	return formatUUID(id)
+}
+
+// time ordered ulid of unix milliseconds in crockford base32, eg: "01HZX3J6Y8Q9K2M4N5P6R7S8T9"
+type ULIDGenerator struct{}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (generator ULIDGenerator) NewID() string {
+	var id [16]byte
+	rand.Read(id[6:])
+	putMillisecond(id[:6], time.Now())
+
+	// 26 chars of 130 bits, the leading 2 bits are zero
+	ulid := make([]byte, 26)
+	for i := range ulid {
+		var value byte
+		for bit := i*5 - 2; bit < i*5+3; bit++ {
+			value <<= 1
+			if bit >= 0 && id[bit/8]&(0x80>>uint(bit%8)) != 0 {
+				value |= 1
+			}
+		}
+		ulid[i] = crockfordBase32[value]
+	}
+	return string(ulid)
+}
+
+// snowflake ids of 41 bits milliseconds since Epoch, 10 bits node and 12 bits sequence, in decimal
+// ids are unique across nodes with different Node ids, see NewSnowflakeGenerator()
+type SnowflakeGenerator struct {
+	lock      sync.Mutex
+	epoch     int64 // epoch in milliseconds
+	node      int64
+	last      int64 // millisecond of the last id
+	sequence  int64
+	nowMillis func() int64
+}
+
+// snowflake generator of the node id 0 - 1023, epoch default SNOWFLAKE_DEFAULT_EPOCH if it's zero
+func NewSnowflakeGenerator(node int64, epoch time.Time) (*SnowflakeGenerator, error) {
+	if node < 0 || node > 1023 {
+		return nil, errors.New("config node must be 0 - 1023!")
+	}
+	if epoch.IsZero() {
+		epoch = SNOWFLAKE_DEFAULT_EPOCH
+	}
+	return &SnowflakeGenerator{
+		epoch: epoch.UnixNano() / int64(time.Millisecond),
+		node:  node,
+		nowMillis: func() int64 {
+			return time.Now().UnixNano() / int64(time.Millisecond)
+		},
+	}, nil
+}
+
+// next id, it waits for the next millisecond if 4096 ids of the millisecond are generated
+// the clock moving backwards keeps the last millisecond so ids still increase
+func (generator *SnowflakeGenerator) NewID() string {
+	return strconv.FormatInt(generator.Next(), 10)
+}
+
+// next id
+func (generator *SnowflakeGenerator) Next() int64 {
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+
+	now := generator.nowMillis()
+	if now < generator.last {
+		now = generator.last
+	}
+	if now == generator.last {
+		generator.sequence = (generator.sequence + 1) & 0xfff
+		if generator.sequence == 0 {
+			for now <= generator.last {
+				time.Sleep(100 * time.Microsecond)
+				now = generator.nowMillis()
+			}
+		}
+	} else {
+		generator.sequence = 0
+	}
+	generator.last = now
+	return (now-generator.epoch)<<22 | generator.node<<12 | generator.sequence
+}
+
+func formatUUID(id [16]byte) string {
+	uuid := make([]byte, 36)
+	hex.Encode(uuid[0:8], id[0:4])
+	uuid[8] = '-'
+	hex.Encode(uuid[9:13], id[4:6])
+	uuid[13] = '-'
+	hex.Encode(uuid[14:18], id[6:8])
+	uuid[18] = '-'
+	hex.Encode(uuid[19:23], id[8:10])
+	uuid[23] = '-'
+	hex.Encode(uuid[24:], id[10:])
+	return string(uuid)
+}
+
+// 48 bits big endian unix milliseconds
+func putMillisecond(b []byte, t time.Time) {
+	var millisecond [8]byte
+	binary.BigEndian.PutUint64(millisecond[:], uint64(t.UnixNano()/int64(time.Millisecond)))
+	copy(b, millisecond[2:])
+}
+
+// set id generator of request ids, nil restores UUIDv4Generator
+//
+// example:
+//	snowflake, _ := go_logger.NewSnowflakeGenerator(7, time.Time{})
+//	logger.SetIDGenerator(snowflake)
+func (logger *Logger) SetIDGenerator(generator IDGenerator) {
+	if generator == nil {
+		generator = UUIDv4Generator{}
+	}
+	logger.idGenerator.Store(&generator)
+}
+
+// new id of the id generator
+func (logger *Logger) NewID() string {
+	generator, ok := logger.idGenerator.Load().(*IDGenerator)
+	if !ok {
+		return UUIDv4Generator{}.NewID()
+	}
+	return (*generator).NewID()
+}
+
+type loggerRequestIdKey struct{}
+
+// context with request id, field "request_id" of DefaultContextExtractor
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, loggerRequestIdKey{}, requestId)
+}
+
+// request id of the context
+func RequestIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(loggerRequestIdKey{}).(string)
+	return requestId
+}
+
+// context with a new request id of the id generator, the request id of ctx is kept
+func (logger *Logger) ContextWithNewRequestId(ctx context.Context) context.Context {
+	if RequestIdFromContext(ctx) != "" {
+		return ctx
+	}
+	return ContextWithRequestId(ctx, logger.NewID())
+}
+
+// http handler, the request id of header X-Request-Id or a new id of the id generator is added to the request context
+// and the response header, XxxCtx messages of the request get field "request_id"
+//
+// example:
+//	http.ListenAndServe(":8080", logger.RequestIdHandler(logger.TraceparentHandler(mux)))
+func (logger *Logger) RequestIdHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(REQUEST_ID_HEADER)
+		if !validRequestId(requestId) {
+			requestId = logger.NewID()
+		}
+		w.Header().Set(REQUEST_ID_HEADER, requestId)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestId(r.Context(), requestId)))
+	})
+}
+
+// request id of the header is printable ascii and not too long
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > REQUEST_ID_MAX_LENGTH {
+		return false
+	}
+	for i := 0; i < len(requestId); i++ {
+		if requestId[i] < 0x21 || requestId[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package go_logger
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDGenerators(t *testing.T) {
+
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	uuidV7 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulid := regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	for i := 0; i < 100; i++ {
+		if id := (UUIDv4Generator{}).NewID(); !uuidV4.MatchString(id) {
+			t.Errorf("uuid v4 error: %s", id)
+		}
+		if id := (UUIDv7Generator{}).NewID(); !uuidV7.MatchString(id) {
+			t.Errorf("uuid v7 error: %s", id)
+		}
+		if id := (ULIDGenerator{}).NewID(); !ulid.MatchString(id) {
+			t.Errorf("ulid error: %s", id)
+		}
+	}
+
+	// time prefix of uuid v7 and ulid
+	var id [16]byte
+	putMillisecond(id[:6], time.Unix(0, 0x0123456789ab*int64(time.Millisecond)))
+	if formatUUID(id)[:13] != "01234567-89ab" {
+		t.Errorf("uuid millisecond error: %s", formatUUID(id))
+	}
+
+	// ids of a millisecond increase by sequence, the clock moving backwards keeps the last millisecond
+	snowflake, err := NewSnowflakeGenerator(5, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	millisecond := int64(1288834974657 + 1000)
+	snowflake.nowMillis = func() int64 { return millisecond }
+	first := snowflake.Next()
+	if first != 1000<<22|5<<12 {
+		t.Errorf("snowflake id error: %d", first)
+	}
+	millisecond -= 10
+	if snowflake.Next() != first+1 || snowflake.NewID() != strconv.FormatInt(first+2, 10) {
+		t.Error("snowflake sequence error")
+	}
+	if _, err := NewSnowflakeGenerator(1024, time.Time{}); err == nil {
+		t.Error("snowflake node error")
+	}
+}
+
+func TestLogger_RequestIdHandler(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "%body% %fields%"})
+	logger.SetIDGenerator(IDGeneratorFunc(func() string { return "generated" }))
+
+	handler := logger.RequestIdHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoCtx(r.Context(), "request")
+	}))
+	for _, header := range []string{"", "r-42", strings.Repeat("x", REQUEST_ID_MAX_LENGTH+1), "bad id"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(REQUEST_ID_HEADER, header)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		expect := "generated"
+		if header == "r-42" {
+			expect = header
+		}
+		if recorder.Header().Get(REQUEST_ID_HEADER) != expect || strings.TrimSpace(buffer.String()) != "request request_id="+expect {
+			t.Errorf("request id %q error: %q %q", header, recorder.Header().Get(REQUEST_ID_HEADER), buffer.String())
+		}
+		buffer.Reset()
+	}
+
+	ctx := logger.ContextWithNewRequestId(context.Background())
+	if RequestIdFromContext(ctx) != "generated" || RequestIdFromContext(logger.ContextWithNewRequestId(ContextWithRequestId(ctx, "kept"))) != "kept" {
+		t.Error("context with new request id error")
+	}
+	logger.SetIDGenerator(nil)
+	if len(logger.NewID()) != 36 {
+		t.Error("default id generator error")
+	}
+}
@@ -0,0 +1,7 @@
+// integration tests of network adapters against real services in docker: delivery, retry and reconnect
+// it's a module of its own so go_logger doesn't depend on docker, tests are skipped if docker is not available
+// containers are removed after tests:
+//	cd integration && go test -run Integration -v .
+// network adapters add their targets here
+// kafka has no target, there is no kafka adapter in go_logger: kafka adapters are written out of the tree by the adapter sdk
+package integration
@@ -0,0 +1,338 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/phachon/go-logger"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// docker pool, skip the test if docker is not available
+func integrationPool(t *testing.T) *dockertest.Pool {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		t.Skipf("docker is not available: %v", err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		t.Skipf("docker is not available: %v", err)
+	}
+	pool.MaxWait = 3 * time.Minute
+	return pool
+}
+
+// run the container, removed after the test
+func integrationRun(t *testing.T, pool *dockertest.Pool, opts *dockertest.RunOptions) *dockertest.Resource {
+	resource, err := pool.RunWithOptions(opts, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		t.Fatalf("run %s error: %v", opts.Repository, err)
+	}
+	t.Cleanup(func() { pool.Purge(resource) })
+	resource.Expire(600)
+	return resource
+}
+
+// wait until url responds 200
+func integrationWaitHttp(t *testing.T, pool *dockertest.Pool, url string) {
+	err := pool.Retry(func() error {
+		resp, err := http.Get(url)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s is not ready, code=%d", url, resp.StatusCode)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
+
+// get json of url
+func integrationGetJson(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed, code=%d, body=%s", url, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, v)
+}
+
+// logger with only the adapter
+func integrationLogger(t *testing.T, adapterName string, config go_logger.Config) *go_logger.Logger {
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	err := logger.Attach(adapterName, go_logger.LOGGER_LEVEL_DEBUG, config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return logger
+}
+
+func TestIntegration_Elasticsearch(t *testing.T) {
+
+	pool := integrationPool(t)
+	resource := integrationRun(t, pool, &dockertest.RunOptions{
+		Repository: "docker.elastic.co/elasticsearch/elasticsearch",
+		Tag:        "8.11.3",
+		Env:        []string{"discovery.type=single-node", "xpack.security.enabled=false", "ES_JAVA_OPTS=-Xms512m -Xmx512m"},
+	})
+	esUrl := "http://" + resource.GetHostPort("9200/tcp")
+	integrationWaitHttp(t, pool, esUrl+"/_cluster/health?wait_for_status=yellow")
+
+	logger := integrationLogger(t, "elasticsearch", &go_logger.ElasticsearchConfig{Url: esUrl, Index: "integration", BatchSize: 10, Timeout: 2 * time.Second})
+	esAdapter := logger.Adapter("elasticsearch")
+
+	// delivery
+	for i := 0; i < 25; i++ {
+		logger.Infof("delivered %d", i)
+	}
+	esAdapter.Flush()
+
+	// connection is recovered after elasticsearch is unreachable
+	pool.Client.PauseContainer(resource.Container.ID)
+	logger.Error("lost")
+	esAdapter.Flush()
+	pool.Client.UnpauseContainer(resource.Container.ID)
+	logger.Error("recovered")
+	esAdapter.Flush()
+
+	http.Post(esUrl+"/integration/_refresh", "application/json", nil)
+	count := struct {
+		Count int `json:"count"`
+	}{}
+	err := integrationGetJson(esUrl+"/integration/_count", &count)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if count.Count != 26 {
+		t.Errorf("elasticsearch documents error: %d", count.Count)
+	}
+}
+
+func TestIntegration_Loki(t *testing.T) {
+
+	pool := integrationPool(t)
+	resource := integrationRun(t, pool, &dockertest.RunOptions{
+		Repository: "grafana/loki",
+		Tag:        "2.9.3",
+	})
+	lokiUrl := "http://" + resource.GetHostPort("3100/tcp")
+	integrationWaitHttp(t, pool, lokiUrl+"/ready")
+
+	logger := integrationLogger(t, "loki", &go_logger.LokiConfig{
+		Url:           lokiUrl,
+		Labels:        map[string]string{"job": "integration"},
+		LevelLabel:    true,
+		BatchSize:     1000,
+		MaxRetries:    8,
+		RetryInterval: time.Second,
+		Timeout:       2 * time.Second,
+	})
+	lokiAdapter := logger.Adapter("loki")
+
+	// delivery
+	for i := 0; i < 10; i++ {
+		logger.Infof("delivered %d", i)
+	}
+	lokiAdapter.Flush()
+
+	// push is retried while loki is unreachable
+	pool.Client.PauseContainer(resource.Container.ID)
+	go func() {
+		time.Sleep(3 * time.Second)
+		pool.Client.UnpauseContainer(resource.Container.ID)
+	}()
+	logger.Error("retried")
+	lokiAdapter.Flush()
+
+	query := url.Values{"query": {`{job="integration"}`}, "limit": {"100"}, "start": {fmt.Sprint(time.Now().Add(-time.Hour).UnixNano())}}
+	lines := []string{}
+	err := pool.Retry(func() error {
+		result := struct {
+			Data struct {
+				Result []struct {
+					Values [][]string `json:"values"`
+				} `json:"result"`
+			} `json:"data"`
+		}{}
+		err := integrationGetJson(lokiUrl+"/loki/api/v1/query_range?"+query.Encode(), &result)
+		if err != nil {
+			return err
+		}
+		lines = lines[:0]
+		for _, stream := range result.Data.Result {
+			for _, value := range stream.Values {
+				lines = append(lines, value[1])
+			}
+		}
+		if len(lines) != 11 {
+			return fmt.Errorf("loki lines: %d", len(lines))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("loki delivery error: %v %v", err, lines)
+	}
+}
+
+func TestIntegration_Fluent(t *testing.T) {
+
+	// fixed host port, random ports are allocated again by restart
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	_, port, _ := net.SplitHostPort(address)
+
+	pool := integrationPool(t)
+	resource := integrationRun(t, pool, &dockertest.RunOptions{
+		Repository:   "fluent/fluent-bit",
+		Tag:          "2.2",
+		Cmd:          []string{"/fluent-bit/bin/fluent-bit", "-i", "forward", "-o", "stdout", "-m", "*", "-f", "1"},
+		PortBindings: map[docker.Port][]docker.PortBinding{"24224/tcp": {{HostIP: "127.0.0.1", HostPort: port}}},
+	})
+	// write errors are returned by Writer
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	logger.SetStrict(go_logger.STRICT_MODE_ERROR)
+	err = pool.Retry(func() error {
+		return logger.Attach("fluent", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.FluentConfig{Address: address, Tag: "integration", RequireAck: true, BatchSize: 1, Timeout: 2 * time.Second, MaxRetries: 3})
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer logger.Close(context.Background())
+
+	// delivery with ack
+	err = logger.Writer(go_logger.LOGGER_LEVEL_INFO, "delivered")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// connection is dialed again after fluent bit is restarted
+	err = pool.Client.RestartContainer(resource.Container.ID, 5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = pool.Retry(func() error {
+		return logger.Writer(go_logger.LOGGER_LEVEL_INFO, "reconnected")
+	})
+	if err != nil {
+		t.Fatalf("fluent reconnect error: %v", err)
+	}
+
+	err = pool.Retry(func() error {
+		output := &bytes.Buffer{}
+		pool.Client.Logs(docker.LogsOptions{Container: resource.Container.ID, OutputStream: output, ErrorStream: output, Stdout: true, Stderr: true})
+		if !strings.Contains(output.String(), "delivered") || !strings.Contains(output.String(), "reconnected") {
+			return fmt.Errorf("fluent bit output: %s", output.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestIntegration_Redis(t *testing.T) {
+
+	// fixed host port, random ports are allocated again by restart
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	_, port, _ := net.SplitHostPort(address)
+
+	pool := integrationPool(t)
+	resource := integrationRun(t, pool, &dockertest.RunOptions{
+		Repository:   "redis",
+		Tag:          "7.2",
+		Cmd:          []string{"redis-server", "--save", "", "--appendonly", "no"},
+		PortBindings: map[docker.Port][]docker.PortBinding{"6379/tcp": {{HostIP: "127.0.0.1", HostPort: port}}},
+	})
+	llen := func() (string, error) {
+		output := &bytes.Buffer{}
+		_, err := resource.Exec([]string{"redis-cli", "LLEN", "integration"}, dockertest.ExecOptions{StdOut: output})
+		return strings.TrimSpace(output.String()), err
+	}
+	err = pool.Retry(func() error {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return err
+		}
+		conn.Close()
+		_, err = llen()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logger := integrationLogger(t, "redis", &go_logger.RedisConfig{Address: address, Key: "integration", BatchSize: 10, Timeout: 2 * time.Second, MaxRetries: 3})
+	redisAdapter := logger.Adapter("redis")
+
+	// delivery
+	for i := 0; i < 25; i++ {
+		logger.Infof("delivered %d", i)
+	}
+	redisAdapter.Flush()
+	if length, err := llen(); err != nil || length != "25" {
+		t.Fatalf("redis list length error: %s %v", length, err)
+	}
+
+	// pooled connections are broken by the restart, the batch is sent again by a new connection
+	err = pool.Client.RestartContainer(resource.Container.ID, 5)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	err = pool.Retry(func() error {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger.Error("reconnected")
+	redisAdapter.Flush()
+
+	// persistence is disabled, the list has the message of the new connection only
+	err = pool.Retry(func() error {
+		length, err := llen()
+		if err != nil {
+			return err
+		}
+		if length != "1" {
+			return fmt.Errorf("redis list length: %s", length)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("redis reconnect error: %v", err)
+	}
+}
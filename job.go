@@ -0,0 +1,117 @@
+package go_logger
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// logger job, log start, finish and panic of every run
+// a panic of the job is logged and panics again, unless SetRecover(true)
+type LoggerJob struct {
+	logger   *Logger
+	name     string
+	fn       func() error
+	interval time.Duration
+
+	lock      sync.Mutex
+	lastRun   time.Time
+	timer     *time.Timer
+	recovered bool // panics are logged and not raised again
+}
+
+// wrap a periodic job, the returned func can be given to any scheduler
+// a panic of fn is logged and raised again, jobs of schedulers which must not crash recover by SetRecover(true)
+//
+// example:
+//	c.AddFunc("@hourly", logger.WrapJob("backup", backup))
+//	c.AddFunc("@daily", logger.NewJob("report", 0, report).SetRecover(true).Run)
+func (logger *Logger) WrapJob(name string, fn func() error) func() {
+	return logger.NewJob(name, 0, fn).Run
+}
+
+// new job, a warning is logged if the job doesn't run within interval, 0 is disabled
+// call Stop() when the job is no longer scheduled
+func (logger *Logger) NewJob(name string, interval time.Duration, fn func() error) *LoggerJob {
+	job := &LoggerJob{
+		logger:   logger,
+		name:     name,
+		fn:       fn,
+		interval: interval,
+		lastRun:  time.Now(),
+	}
+	if interval > 0 {
+		job.timer = time.AfterFunc(interval, job.missed)
+	}
+	return job
+}
+
+// panics of the job are logged and swallowed if recovered is true, the run looks like it ended
+// the default is false, the panic is raised again after it's logged so the scheduler sees the crash
+func (job *LoggerJob) SetRecover(recovered bool) *LoggerJob {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+	job.recovered = recovered
+	return job
+}
+
+// run the job
+func (job *LoggerJob) Run() {
+	runId := job.logger.NewID()
+	startTime := time.Now()
+
+	job.lock.Lock()
+	job.lastRun = startTime
+	if job.timer != nil {
+		job.timer.Reset(job.interval)
+	}
+	recovered := job.recovered
+	job.lock.Unlock()
+
+	job.logger.write(2, LOGGER_LEVEL_INFO, fmt.Sprintf("job %s run=%s started", job.name, runId), nil)
+
+	defer func() {
+		e := recover()
+		if e != nil {
+			job.logger.WriterFields(LOGGER_LEVEL_CRITICAL, fmt.Sprintf("job %s run=%s panic duration=%s panic=%v",
+				job.name, runId, time.Since(startTime), e), PanicFields(e, debug.Stack()))
+			if !recovered {
+				panic(e)
+			}
+		}
+	}()
+
+	err := job.fn()
+	if err != nil {
+		job.logger.write(2, LOGGER_LEVEL_ERROR, fmt.Sprintf("job %s run=%s failed duration=%s error=%s",
+			job.name, runId, time.Since(startTime), err.Error()), nil)
+		return
+	}
+	job.logger.write(2, LOGGER_LEVEL_INFO, fmt.Sprintf("job %s run=%s finished duration=%s",
+		job.name, runId, time.Since(startTime)), nil)
+}
+
+// stop missed run detection
+func (job *LoggerJob) Stop() {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	if job.timer != nil {
+		job.timer.Stop()
+		job.timer = nil
+	}
+}
+
+// the job doesn't run within interval
+func (job *LoggerJob) missed() {
+	job.lock.Lock()
+	defer job.lock.Unlock()
+
+	if job.timer == nil {
+		return
+	}
+	job.logger.write(2, LOGGER_LEVEL_WARNING, fmt.Sprintf("job %s missed run, interval=%s last_run=%s",
+		job.name, job.interval, job.lastRun.Format("2006-01-02 15:04:05")), nil)
+	job.timer.Reset(job.interval)
+}
@@ -0,0 +1,56 @@
+package go_logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_WrapJob(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, nil)
+
+	logger.WrapJob("ok", func() error { return nil })()
+	logger.WrapJob("fail", func() error { return errors.New("disk full") })()
+	func() {
+		defer func() {
+			if recover() != "nil map" {
+				t.Error("job panic must be raised again")
+			}
+		}()
+		logger.WrapJob("panic", func() error { panic("nil map") })()
+	}()
+	logger.NewJob("recovered", 0, func() error { panic("index out of range") }).SetRecover(true).Run()
+
+	content := readLog()
+	for _, expect := range []string{
+		"[Info] job ok run=",
+		"finished duration=",
+		"[Error] job fail run=",
+		"error=disk full",
+		"[Critical] job panic run=",
+		"panic=nil map",
+		"[Critical] job recovered run=",
+		"panic=index out of range",
+	} {
+		if !strings.Contains(content, expect) {
+			t.Errorf("job log missing %s", expect)
+		}
+	}
+}
+
+func TestLoggerJob_Missed(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, nil)
+
+	job := logger.NewJob("report", 20*time.Millisecond, func() error { return nil })
+	job.Run()
+	time.Sleep(30 * time.Millisecond)
+	job.Stop()
+
+	content := readLog()
+	if strings.Count(content, "[Warning] job report missed run") != 1 {
+		t.Error("job missed run log error: " + content)
+	}
+}
@@ -0,0 +1,158 @@
+package go_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const JOURNALD_ADAPTER_NAME = "journald"
+
+const JOURNALD_DEFAULT_SOCKET = "/run/systemd/journal/socket"
+
+// adapter journald, messages are sent by the native journal protocol, linux only
+type AdapterJournald struct {
+	lock   sync.Mutex
+	config *JournaldConfig
+	sender journaldSender
+}
+
+// journald config
+type JournaldConfig struct {
+
+	// journal socket, default "/run/systemd/journal/socket"
+	Socket string
+
+	// SYSLOG_IDENTIFIER of messages, default name of the executable
+	Identifier string
+
+	// static fields of messages, eg: {"SERVICE_VERSION": "1.4.2"}
+	Fields map[string]string
+}
+
+func (jc *JournaldConfig) Name() string {
+	return JOURNALD_ADAPTER_NAME
+}
+
+// datagram sender of the journal socket
+type journaldSender interface {
+	send(data []byte) error
+	close() error
+}
+
+func NewAdapterJournald() LoggerAbstract {
+	return &AdapterJournald{}
+}
+
+func (adapterJournald *AdapterJournald) Init(journaldConfig Config) error {
+	jc, ok := journaldConfig.(*JournaldConfig)
+	if !ok || jc == nil {
+		return configTypeError(JOURNALD_ADAPTER_NAME, "JournaldConfig")
+	}
+	adapterJournald.config = jc
+
+	if jc.Socket == "" {
+		jc.Socket = JOURNALD_DEFAULT_SOCKET
+	}
+	if jc.Identifier == "" {
+		jc.Identifier = filepath.Base(os.Args[0])
+	}
+
+	sender, err := dialJournald(jc.Socket)
+	if err != nil {
+		return err
+	}
+	adapterJournald.sender = sender
+	return nil
+}
+
+// send the message as one datagram, level is the PRIORITY
+func (adapterJournald *AdapterJournald) Write(loggerMsg *loggerMessage) error {
+	adapterJournald.lock.Lock()
+	defer adapterJournald.lock.Unlock()
+
+	return adapterJournald.sender.send(adapterJournald.entry(loggerMsg))
+}
+
+func (adapterJournald *AdapterJournald) Flush() {
+
+}
+
+func (adapterJournald *AdapterJournald) Close() error {
+	adapterJournald.lock.Lock()
+	defer adapterJournald.lock.Unlock()
+
+	return adapterJournald.sender.close()
+}
+
+func (adapterJournald *AdapterJournald) Name() string {
+	return JOURNALD_ADAPTER_NAME
+}
+
+// journal entry of the message, fields are upper case journal field names
+func (adapterJournald *AdapterJournald) entry(loggerMsg *loggerMessage) []byte {
+	entry := make([]byte, 0, 256)
+	entry = appendJournaldField(entry, "MESSAGE", loggerMsg.Body)
+	entry = appendJournaldField(entry, "PRIORITY", strconv.Itoa(builtinLevel(loggerMsg.Level)))
+	entry = appendJournaldField(entry, "SYSLOG_IDENTIFIER", adapterJournald.config.Identifier)
+	if loggerMsg.File != "" {
+		entry = appendJournaldField(entry, "CODE_FILE", loggerMsg.File)
+		entry = appendJournaldField(entry, "CODE_LINE", strconv.Itoa(loggerMsg.Line))
+		entry = appendJournaldField(entry, "CODE_FUNC", loggerMsg.Function)
+	}
+	for key, value := range adapterJournald.config.Fields {
+		if name := journaldFieldName(key); name != "" {
+			entry = appendJournaldField(entry, name, value)
+		}
+	}
+	for _, key := range sortedFieldKeys(loggerMsg.Fields) {
+		if name := journaldFieldName(key); name != "" {
+			entry = appendJournaldField(entry, name, loggerMessageField(loggerMsg.Fields, key))
+		}
+	}
+	return entry
+}
+
+// "NAME=value\n", or binary safe "NAME\n" + little endian uint64 size + value + "\n" if value has new lines
+func appendJournaldField(entry []byte, name string, value string) []byte {
+	entry = append(entry, name...)
+	if !strings.ContainsRune(value, '\n') {
+		entry = append(entry, '=')
+		entry = append(entry, value...)
+		return append(entry, '\n')
+	}
+	entry = append(entry, '\n')
+	size := uint64(len(value))
+	for i := uint(0); i < 64; i += 8 {
+		entry = append(entry, byte(size>>i))
+	}
+	entry = append(entry, value...)
+	return append(entry, '\n')
+}
+
+// journal field name of the field: upper case letters, digits and "_", not starting with "_" (trusted fields) or a digit
+func journaldFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	key = strings.TrimLeft(string(name), "_")
+	if key == "" {
+		return ""
+	}
+	if key[0] >= '0' && key[0] <= '9' {
+		key = "FIELD_" + key
+	}
+	return key
+}
+
+func init() {
+	Register(JOURNALD_ADAPTER_NAME, NewAdapterJournald)
+	RegisterConfig(JOURNALD_ADAPTER_NAME, func() Config {
+		return &JournaldConfig{}
+	})
+}
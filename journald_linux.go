@@ -0,0 +1,67 @@
+//go:build linux
+// +build linux
+
+package go_logger
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"syscall"
+)
+
+// datagram socket sending to journal
+type journaldSocket struct {
+	addr *net.UnixAddr
+	conn *net.UnixConn
+}
+
+// unbound local socket, entries are sent to the journal socket path
+func dialJournald(socket string) (journaldSender, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &journaldSocket{addr: &net.UnixAddr{Name: socket, Net: "unixgram"}, conn: conn}, nil
+}
+
+// send the entry, entries over the datagram size are sent by the file descriptor of an unlinked temp file
+func (journald *journaldSocket) send(data []byte) error {
+	_, _, err := journald.conn.WriteMsgUnix(data, nil, journald.addr)
+	if err == nil || !isSyscallError(err, syscall.EMSGSIZE, syscall.ENOBUFS) {
+		return err
+	}
+
+	file, err := ioutil.TempFile("/dev/shm", "go-logger-journal-")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+	_, _, err = journald.conn.WriteMsgUnix([]byte{}, syscall.UnixRights(int(file.Fd())), journald.addr)
+	return err
+}
+
+func (journald *journaldSocket) close() error {
+	return journald.conn.Close()
+}
+
+// error is one of the errnos
+func isSyscallError(err error, errnos ...syscall.Errno) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+	}
+	if sysErr, ok := err.(*os.SyscallError); ok {
+		err = sysErr.Err
+	}
+	for _, errno := range errnos {
+		if err == errno {
+			return true
+		}
+	}
+	return false
+}
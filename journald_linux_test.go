@@ -0,0 +1,62 @@
+package go_logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAdapterJournald_Write(t *testing.T) {
+
+	socket := filepath.Join(t.TempDir(), "journal.socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	err = logger.Attach("journald", LOGGER_LEVEL_DEBUG, &JournaldConfig{Socket: socket, Identifier: "app"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger.Error("db is down")
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	entry := string(buf[:n])
+	if !strings.HasPrefix(entry, "MESSAGE=db is down\nPRIORITY=3\nSYSLOG_IDENTIFIER=app\n") || !strings.Contains(entry, "CODE_FILE=") {
+		t.Errorf("journald datagram error: %q", entry)
+	}
+
+	// entry over datagram size is passed by file descriptor
+	logger.Error(strings.Repeat("x", 1024*1024))
+	oob := make([]byte, 64)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	messages, _ := syscall.ParseSocketControlMessage(oob[:oobn])
+	if n != 0 || len(messages) != 1 {
+		t.Fatalf("journald large entry error: %d bytes, %d control messages", n, len(messages))
+	}
+	fds, _ := syscall.ParseUnixRights(&messages[0])
+	file := os.NewFile(uintptr(fds[0]), "journal")
+	defer file.Close()
+	file.Seek(0, 0)
+	data, _ := ioutil.ReadAll(file)
+	if !bytes.HasPrefix(data, []byte("MESSAGE=xxx")) || len(data) < 1024*1024 {
+		t.Errorf("journald large entry file error: %d bytes", len(data))
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package go_logger
+
+import "errors"
+
+// journald is not supported
+func dialJournald(socket string) (journaldSender, error) {
+	return nil, errors.New("logger journald adapter is only supported on linux")
+}
@@ -0,0 +1,37 @@
+package go_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJournaldFieldName(t *testing.T) {
+
+	names := map[string]string{
+		"trace_id": "TRACE_ID",
+		"user.id":  "USER_ID",
+		"_cursor":  "CURSOR",
+		"2fa":      "FIELD_2FA",
+		"__":       "",
+	}
+	for key, name := range names {
+		if journaldFieldName(key) != name {
+			t.Errorf("journald field name of %s error: %s", key, journaldFieldName(key))
+		}
+	}
+}
+
+func TestAdapterJournald_Entry(t *testing.T) {
+
+	journaldAdapter := &AdapterJournald{config: &JournaldConfig{Identifier: "app"}}
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_WARNING, "disk\nfull", map[string]interface{}{"user_id": 42})
+	loggerMsg.File = "main.go"
+	loggerMsg.Line = 12
+	loggerMsg.Function = "main.main"
+
+	expected := "MESSAGE\n\x09\x00\x00\x00\x00\x00\x00\x00disk\nfull\n" +
+		"PRIORITY=4\nSYSLOG_IDENTIFIER=app\nCODE_FILE=main.go\nCODE_LINE=12\nCODE_FUNC=main.main\nUSER_ID=42\n"
+	if entry := string(journaldAdapter.entry(loggerMsg)); entry != expected {
+		t.Errorf("journald entry error: %q", entry)
+	}
+}
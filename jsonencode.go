@@ -0,0 +1,138 @@
+package go_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/mailru/easyjson"
+	"github.com/mailru/easyjson/jlexer"
+	"github.com/mailru/easyjson/jwriter"
+)
+
+// fields of messages, the generated marshaller of loggerMessage (logger_easyjson.go) calls their easyjson methods
+// keys are sorted, values are written by writeJsonFieldValue
+type loggerFields map[string]interface{}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (fields loggerFields) MarshalEasyJSON(out *jwriter.Writer) {
+	if fields == nil {
+		out.RawString("null")
+		return
+	}
+	out.RawByte('{')
+	for i, key := range sortedFieldKeys(fields) {
+		if i > 0 {
+			out.RawByte(',')
+		}
+		out.String(key)
+		out.RawByte(':')
+		writeJsonFieldValue(out, fields[key])
+	}
+	out.RawByte('}')
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface, empty fields are nil
+func (fields *loggerFields) UnmarshalEasyJSON(in *jlexer.Lexer) {
+	if in.IsNull() {
+		in.Skip()
+		*fields = nil
+		return
+	}
+	in.Delim('{')
+	decoded := loggerFields(nil)
+	for !in.IsDelim('}') {
+		if decoded == nil {
+			decoded = loggerFields{}
+		}
+		key := string(in.String())
+		in.WantColon()
+		decoded[key] = in.Interface()
+		in.WantComma()
+	}
+	in.Delim('}')
+	*fields = decoded
+}
+
+// json of the message, html characters are escaped as \u003c, \u003e and \u0026 if escapeHTML
+func encodeLoggerMessage(loggerMsg *loggerMessage, escapeHTML bool) ([]byte, error) {
+	w := jwriter.Writer{NoEscapeHTML: !escapeHTML}
+	loggerMsg.MarshalEasyJSON(&w)
+	return w.BuildBytes()
+}
+
+// json of the field value, values failed to marshal are their "%+v" strings
+func encodeFieldValue(value interface{}, escapeHTML bool) []byte {
+	w := jwriter.Writer{NoEscapeHTML: !escapeHTML}
+	writeJsonFieldValue(&w, value)
+	data, err := w.BuildBytes()
+	if err != nil {
+		w = jwriter.Writer{NoEscapeHTML: !escapeHTML}
+		w.String(fmt.Sprintf("%+v", value))
+		data, _ = w.BuildBytes()
+	}
+	return data
+}
+
+// write json of the field value, common types are written directly, others by encoding/json
+// output of easyjson.Marshaler and json.Marshaler values is validated, invalid output is an error of the writer
+// like encoding/json, NaN and infinite floats are errors, invalid utf-8 is written as U+FFFD
+func writeJsonFieldValue(out *jwriter.Writer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		out.RawString("null")
+	case string:
+		out.String(v)
+	case bool:
+		out.Bool(v)
+	case int:
+		out.Int(v)
+	case int8:
+		out.Int8(v)
+	case int16:
+		out.Int16(v)
+	case int32:
+		out.Int32(v)
+	case int64:
+		out.Int64(v)
+	case uint:
+		out.Uint(v)
+	case uint8:
+		out.Uint8(v)
+	case uint16:
+		out.Uint16(v)
+	case uint32:
+		out.Uint32(v)
+	case uint64:
+		out.Uint64(v)
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			out.Raw(json.Marshal(v))
+			return
+		}
+		out.Float32(v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			out.Raw(json.Marshal(v))
+			return
+		}
+		out.Float64(v)
+	case easyjson.Marshaler:
+		w := jwriter.Writer{NoEscapeHTML: out.NoEscapeHTML}
+		v.MarshalEasyJSON(&w)
+		data, err := w.BuildBytes()
+		if err == nil && !json.Valid(data) {
+			err = errors.New("json: invalid output of MarshalEasyJSON")
+		}
+		out.Raw(data, err)
+	default:
+		// json.Marshaler output is validated and compacted by encoding/json
+		buffer := &bytes.Buffer{}
+		encoder := json.NewEncoder(buffer)
+		encoder.SetEscapeHTML(!out.NoEscapeHTML)
+		err := encoder.Encode(value)
+		out.Raw(bytes.TrimSuffix(buffer.Bytes(), []byte{'\n'}), err)
+	}
+}
@@ -0,0 +1,65 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testInvalidMarshaler struct{}
+
+func (marshaler testInvalidMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"unterminated`), nil
+}
+
+func TestMarshalLoggerMessage_Escape(t *testing.T) {
+
+	body := "quote \" backslash \\ newline \n tab \t nul \x00 esc \x1b invalid \xff\xfe sep   html <a>&"
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, body, map[string]interface{}{
+		"key \"\n\xff": "value \r\n\xc3",
+		"int":          int8(-3),
+		"uint":         uint64(1) << 63,
+		"float":        float32(0.5),
+		"nested":       map[string]interface{}{"html": "<b>"},
+	})
+	jsonByte := marshalLoggerMessage(loggerMsg, nil)
+	if !json.Valid(jsonByte) {
+		t.Fatalf("json record is invalid: %s", jsonByte)
+	}
+	record := map[string]interface{}{}
+	json.Unmarshal(jsonByte, &record)
+	if !strings.HasPrefix(record["body"].(string), "quote \" backslash \\ newline \n tab \t nul \x00 esc \x1b invalid ��") {
+		t.Errorf("json body error: %q", record["body"])
+	}
+	if !strings.Contains(string(jsonByte), `\u003ca\u003e\u0026`) || !strings.Contains(string(jsonByte), `\u003cb\u003e`) {
+		t.Errorf("html must be escaped by default: %s", jsonByte)
+	}
+	fields := record["fields"].(map[string]interface{})
+	if fields["key \"\n�"] != "value \r\n�" || fields["int"] != float64(-3) || fields["uint"] != float64(uint64(1)<<63) || fields["float"] != 0.5 {
+		t.Errorf("json fields error: %s", jsonByte)
+	}
+
+	jsonByte = marshalLoggerMessageHTML(loggerMsg, nil, false)
+	if !json.Valid(jsonByte) || !strings.Contains(string(jsonByte), `html <a>&`) || !strings.Contains(string(jsonByte), `"<b>"`) {
+		t.Errorf("html must not be escaped: %s", jsonByte)
+	}
+
+	// invalid output of MarshalJSON falls back to the "%+v" string
+	fallbacks := int64(0)
+	loggerMsg.Fields = map[string]interface{}{"custom": testInvalidMarshaler{}}
+	jsonByte = marshalLoggerMessage(loggerMsg, &fallbacks)
+	if !json.Valid(jsonByte) || fallbacks != 1 || !strings.Contains(string(jsonByte), LOGGER_FIELD_ENCODING_ERROR) {
+		t.Errorf("invalid marshaler output error: %s", jsonByte)
+	}
+}
+
+func BenchmarkMarshalLoggerMessage(b *testing.B) {
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "request \"done\"\n", map[string]interface{}{
+		"status": 200, "path": "/api/users", "latency": 0.0123, "ok": true,
+	})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		marshalLoggerMessage(loggerMsg, nil)
+	}
+}
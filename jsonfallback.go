@@ -0,0 +1,51 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// field of records written by the json fallback, the marshal error of the message
+const LOGGER_FIELD_ENCODING_ERROR = "encoding_error"
+
+// counter of json fallbacks in adapter Counters()
+const COUNTER_ENCODING_ERRORS = "encoding_errors"
+
+// json record of the message, the record is never dropped:
+// if marshal fails (eg: NaN, channels, funcs, MarshalJSON errors) fields which cannot be marshaled are
+// replaced by their "%+v" strings, field "encoding_error" is the error, fallbacks is increased if it's not nil
+// fallbacks is increased atomically, counters of adapters are their first field so they're 64-bit aligned on 32-bit platforms
+func marshalLoggerMessage(loggerMsg *loggerMessage, fallbacks *int64) []byte {
+	return marshalLoggerMessageHTML(loggerMsg, fallbacks, true)
+}
+
+// json record of the message, "<", ">" and "&" are written as is if escapeHTML is false
+func marshalLoggerMessageHTML(loggerMsg *loggerMessage, fallbacks *int64, escapeHTML bool) []byte {
+	jsonByte, err := encodeLoggerMessage(loggerMsg, escapeHTML)
+	if err == nil {
+		return jsonByte
+	}
+	if fallbacks != nil {
+		atomic.AddInt64(fallbacks, 1)
+	}
+
+	safeMsg := *loggerMsg
+	safeMsg.Fields = make(map[string]interface{}, len(loggerMsg.Fields)+1)
+	for key, value := range loggerMsg.Fields {
+		if _, fieldErr := json.Marshal(value); fieldErr != nil {
+			value = fmt.Sprintf("%+v", value)
+		}
+		safeMsg.Fields[key] = value
+	}
+	safeMsg.Fields[LOGGER_FIELD_ENCODING_ERROR] = err.Error()
+	jsonByte, err = encodeLoggerMessage(&safeMsg, escapeHTML)
+	if err == nil {
+		return jsonByte
+	}
+
+	// fields marshaled by json.Marshal but not by easyjson, eg: easyjson.Marshaler errors, are dropped
+	safeMsg.Fields = map[string]interface{}{LOGGER_FIELD_ENCODING_ERROR: err.Error()}
+	jsonByte, _ = encodeLoggerMessage(&safeMsg, escapeHTML)
+	return jsonByte
+}
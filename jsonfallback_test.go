@@ -0,0 +1,71 @@
+package go_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testFailedMarshaler struct{}
+
+func (marshaler testFailedMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("marshal failed")
+}
+
+func TestMarshalLoggerMessage(t *testing.T) {
+
+	fallbacks := int64(0)
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "ok", map[string]interface{}{"k": "v"})
+	jsonByte := marshalLoggerMessage(loggerMsg, &fallbacks)
+	if fallbacks != 0 || strings.Contains(string(jsonByte), LOGGER_FIELD_ENCODING_ERROR) {
+		t.Errorf("marshal logger message error: %s", jsonByte)
+	}
+
+	loggerMsg.Fields = map[string]interface{}{
+		"k":      "v",
+		"ratio":  math.NaN(),
+		"ch":     make(chan int),
+		"custom": testFailedMarshaler{},
+	}
+	jsonByte = marshalLoggerMessage(loggerMsg, &fallbacks)
+	if fallbacks != 1 {
+		t.Errorf("marshal logger message fallbacks %d, expect 1", fallbacks)
+	}
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(jsonByte, &record); err != nil {
+		t.Fatalf("fallback record must be json: %s, %s", err, jsonByte)
+	}
+	fields := record["fields"].(map[string]interface{})
+	if record["body"] != "ok" || fields["k"] != "v" || fields["ratio"] != "NaN" || fields["custom"] != "{}" {
+		t.Errorf("fallback record fields error: %s", jsonByte)
+	}
+	if !strings.HasPrefix(fields["ch"].(string), "0x") || fields[LOGGER_FIELD_ENCODING_ERROR] == "" {
+		t.Errorf("fallback record fields error: %s", jsonByte)
+	}
+	if len(loggerMsg.Fields) != 4 {
+		t.Errorf("fallback must not change the message fields")
+	}
+}
+
+func TestAdapterWriter_EncodingFallback(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, JsonFormat: true})
+
+	logger.WriterFields(LOGGER_LEVEL_INFO, "latency", map[string]interface{}{"p99": math.Inf(1)})
+	logger.Info("next")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"p99":"+Inf"`) || !strings.Contains(lines[0], `"encoding_error":"json: unsupported value: +Inf"`) {
+		t.Errorf("writer encoding fallback error: %q", buffer.String())
+	}
+	if count := logger.Stats().Adapters["writer"].Counters[COUNTER_ENCODING_ERRORS]; count != 1 {
+		t.Errorf("writer encoding errors %d, expect 1", count)
+	}
+}
@@ -0,0 +1,113 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// keys of json messages in written order
+var jsonFormatterKeys = []string{
+	"timestamp", "timestamp_format", "millisecond", "millisecond_format", "level", "level_string",
+	"body", "file", "line", "function", "fields",
+}
+
+// marshal the message by options of the formatter
+func (jf *JsonFormatter) marshal(loggerMsg *loggerMessage) []byte {
+	omitted := make(map[string]bool, len(jf.Omit))
+	for _, key := range jf.Omit {
+		omitted[key] = true
+	}
+	number := func(value int64) interface{} {
+		if jf.NumbersAsStrings {
+			return strconv.FormatInt(value, 10)
+		}
+		return value
+	}
+	values := map[string]interface{}{
+		"timestamp":          number(loggerMsg.Timestamp),
+		"timestamp_format":   loggerMsg.TimestampFormat,
+		"millisecond":        number(loggerMsg.Millisecond),
+		"millisecond_format": loggerMsg.MillisecondFormat,
+		"level":              number(int64(loggerMsg.Level)),
+		"level_string":       loggerMsg.LevelString,
+		"body":               loggerMsg.Body,
+		"file":               loggerMsg.File,
+		"line":               number(int64(loggerMsg.Line)),
+		"function":           loggerMsg.Function,
+	}
+
+	escapeHTML := !jf.NoEscapeHTML
+	buf := make([]byte, 0, 256)
+	buf = append(buf, '{')
+	written := make(map[string]bool, len(jsonFormatterKeys)+len(loggerMsg.Fields))
+	add := func(key string, value []byte) {
+		if len(buf) > 1 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, encodeFieldValue(key, escapeHTML)...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+		written[key] = true
+	}
+	fields := loggerMsg.Fields
+	if omitted["fields"] {
+		fields = nil
+	}
+	for _, key := range jsonFormatterKeys {
+		if omitted[key] {
+			continue
+		}
+		name := key
+		if renamed, ok := jf.Rename[key]; ok {
+			name = renamed
+		}
+		if key != "fields" {
+			add(name, encodeFieldValue(values[key], escapeHTML))
+			continue
+		}
+		if len(fields) == 0 || jf.FlattenFields {
+			continue
+		}
+		object := []byte{'{'}
+		for _, field := range sortedFieldKeys(fields) {
+			if len(object) > 1 {
+				object = append(object, ',')
+			}
+			object = append(object, encodeFieldValue(field, escapeHTML)...)
+			object = append(object, ':')
+			object = append(object, encodeFieldValue(jf.fieldValue(fields[field]), escapeHTML)...)
+		}
+		add(name, append(object, '}'))
+	}
+	if jf.FlattenFields {
+		for _, field := range sortedFieldKeys(fields) {
+			name := field
+			if written[name] {
+				name = "fields." + field
+			}
+			add(name, encodeFieldValue(jf.fieldValue(fields[field]), escapeHTML))
+		}
+	}
+	return append(buf, '}')
+}
+
+// value of a field, numbers are strings if NumbersAsStrings
+func (jf *JsonFormatter) fieldValue(value interface{}) interface{} {
+	if !jf.NumbersAsStrings || value == nil {
+		return value
+	}
+	if number, ok := value.(json.Number); ok {
+		return number.String()
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return value
+}
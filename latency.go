@@ -0,0 +1,227 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latency samples of every adapter, p99 is computed from the latest samples
+const LATENCY_WINDOW_SIZE = 1000
+
+// p99 is checked against slow threshold every interval writes
+const LATENCY_CHECK_INTERVAL = 100
+
+var ErrAdapterTimeout = errors.New("logger: adapter write timeout")
+
+// write latency of an adapter
+type LatencyStats struct {
+
+	// latency of the latest writes, timed out writes are the timeout
+	P50 time.Duration
+	P99 time.Duration
+	Max time.Duration
+
+	// total writes, failed writes and timed out writes (also failed)
+	Writes   int64
+	Errors   int64
+	Timeouts int64
+
+	// p99 exceeds the slow threshold
+	Slow bool
+}
+
+// latency tracker of an output
+type latencyTracker struct {
+	lock     sync.Mutex
+	samples  []time.Duration
+	next     int
+	full     bool
+	writes   int64
+	errors   int64
+	timeouts int64
+	slow     bool
+	pending  int32 // a timed out write is still running
+}
+
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{
+		samples: make([]time.Duration, LATENCY_WINDOW_SIZE),
+	}
+}
+
+// add a sample of write result, return p99 every LATENCY_CHECK_INTERVAL writes, otherwise -1
+func (tracker *latencyTracker) add(latency time.Duration, err error) time.Duration {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.samples[tracker.next] = latency
+	tracker.next++
+	if tracker.next == len(tracker.samples) {
+		tracker.next = 0
+		tracker.full = true
+	}
+	tracker.writes++
+	if err != nil {
+		tracker.errors++
+	}
+	if err == ErrAdapterTimeout {
+		tracker.timeouts++
+	}
+	if tracker.writes%LATENCY_CHECK_INTERVAL != 0 {
+		return -1
+	}
+	return tracker.percentile(sortedDurations(tracker.window()), 0.99)
+}
+
+// samples of the window
+func (tracker *latencyTracker) window() []time.Duration {
+	if tracker.full {
+		return tracker.samples
+	}
+	return tracker.samples[:tracker.next]
+}
+
+// set slow, return true if it's changed
+func (tracker *latencyTracker) setSlow(slow bool) bool {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	changed := tracker.slow != slow
+	tracker.slow = slow
+	return changed
+}
+
+func (tracker *latencyTracker) stats() LatencyStats {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	samples := sortedDurations(tracker.window())
+	stats := LatencyStats{
+		P50:      tracker.percentile(samples, 0.5),
+		P99:      tracker.percentile(samples, 0.99),
+		Writes:   tracker.writes,
+		Errors:   tracker.errors,
+		Timeouts: tracker.timeouts,
+		Slow:     tracker.slow,
+	}
+	if len(samples) > 0 {
+		stats.Max = samples[len(samples)-1]
+	}
+	return stats
+}
+
+// percentile of sorted samples
+func (tracker *latencyTracker) percentile(samples []time.Duration, p float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	return samples[int(float64(len(samples)-1)*p)]
+}
+
+// sorted copy of samples
+func sortedDurations(samples []time.Duration) []time.Duration {
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+// write message to the adapter with timeout, track write latency and detect slow adapter
+func (output *outputLogger) write(loggerMsg *loggerMessage) error {
+	loggerMsg = output.formatTime(loggerMsg)
+	start := time.Now()
+	timeout, _ := output.timeout.Load().(time.Duration)
+
+	var err error
+	if timeout <= 0 {
+		err = output.adapterWrite(loggerMsg)
+	} else {
+		err = output.writeTimeout(loggerMsg, timeout)
+	}
+
+	p99 := output.latency.add(time.Since(start), err)
+	threshold, _ := output.slowThreshold.Load().(time.Duration)
+	if p99 >= 0 && threshold > 0 {
+		slow := p99 > threshold
+		if output.latency.setSlow(slow) {
+			if slow {
+				fmt.Fprintf(os.Stderr, "logger: adapter %s is slow, p99 write latency %v exceeds %v\n", output.Name, p99, threshold)
+			} else {
+				fmt.Fprintf(os.Stderr, "logger: adapter %s is recovered, p99 write latency %v\n", output.Name, p99)
+			}
+		}
+	}
+	return err
+}
+
+// write in a goroutine, give up waiting after timeout
+// messages are not written while a timed out write is still running, the adapter is not piled up
+func (output *outputLogger) writeTimeout(loggerMsg *loggerMessage, timeout time.Duration) error {
+	if atomic.LoadInt32(&output.latency.pending) == 1 {
+		return ErrAdapterTimeout
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				done <- adapterPanicError(output.Name, e)
+			}
+		}()
+		done <- output.adapterWrite(loggerMsg)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	if atomic.CompareAndSwapInt32(&output.latency.pending, 0, 1) {
+		go func() {
+			<-done
+			atomic.StoreInt32(&output.latency.pending, 0)
+		}()
+	}
+	return ErrAdapterTimeout
+}
+
+// set write timeout of attached adapter, timed out writes return ErrAdapterTimeout, 0 is no timeout
+func (logger *Logger) SetAdapterTimeout(adapterName string, timeout time.Duration) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.timeout.Store(timeout)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// report adapters to stderr when their p99 write latency exceeds threshold, 0 is disabled
+func (logger *Logger) SetSlowThreshold(threshold time.Duration) {
+	logger.slowThreshold.Store(threshold)
+}
+
+// write latency of attached adapter
+func (logger *Logger) AdapterLatency(adapterName string) LatencyStats {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			return output.latency.stats()
+		}
+	}
+	return LatencyStats{}
+}
@@ -0,0 +1,84 @@
+package go_logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetAdapterTimeout(t *testing.T) {
+
+	blockingConfig := &blockingConfig{release: make(chan struct{})}
+	defer close(blockingConfig.release)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+	if logger.SetAdapterTimeout("blocking", 10*time.Millisecond) != nil {
+		t.Fatal("set adapter timeout error")
+	}
+
+	start := time.Now()
+	logger.Info("timeout")
+	// the timed out write is still running, the next one is not waited
+	logger.Info("skipped")
+	if time.Since(start) > time.Second {
+		t.Error("adapter write must be timed out")
+	}
+
+	stats := logger.AdapterLatency("blocking")
+	if stats.Writes != 2 || stats.Timeouts != 2 {
+		t.Errorf("adapter latency stats error: %+v", stats)
+	}
+	if logger.SetAdapterTimeout("file", time.Second) == nil {
+		t.Error("not attached adapter must be error")
+	}
+}
+
+func TestLogger_SetSlowThreshold(t *testing.T) {
+
+	blockingConfig := &blockingConfig{release: make(chan struct{})}
+	quit := make(chan struct{})
+	defer close(quit)
+	go func() {
+		for {
+			select {
+			case blockingConfig.release <- struct{}{}:
+				time.Sleep(time.Millisecond)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+	logger.SetSlowThreshold(100 * time.Microsecond)
+	for i := 0; i < LATENCY_CHECK_INTERVAL; i++ {
+		logger.Info("slow")
+	}
+
+	stats := logger.AdapterLatency("blocking")
+	if !stats.Slow || stats.P99 < 100*time.Microsecond || stats.Max < stats.P50 {
+		t.Errorf("slow adapter must be detected: %+v", stats)
+	}
+}
+
+func TestLogger_SetAdapterTimeoutPanic(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: panicWriter{}})
+	logger.SetAdapterTimeout("writer", time.Second)
+
+	errs := []error{}
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+		errs = append(errs, err)
+	})
+	// the panic of the write goroutine is returned as the write error
+	logger.Info("panic")
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "adapter writer panic: broken writer") {
+		t.Errorf("adapter panic of timed write error: %v", errs)
+	}
+}
@@ -0,0 +1,153 @@
+package go_logger
+
+// lazy messages, the func is called only if the level is enabled
+// so expensive messages cost nothing when the level is filtered
+//
+// example:
+//	logger.DebugFn(func() string {
+//		return "cache: " + cache.Dump()
+//	})
+
+// write lazy message of the level
+func (logger *Logger) WriterFn(level int, fn func() string) error {
+	if !logger.enabled(level) {
+		return nil
+	}
+	return logger.write(2, level, fn(), nil)
+}
+
+// log emergency lazy message
+func (logger *Logger) EmergencyFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_EMERGENCY, fn(), nil)
+}
+
+// log alert lazy message
+func (logger *Logger) AlertFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_ALERT) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_ALERT, fn(), nil)
+}
+
+// log critical lazy message
+func (logger *Logger) CriticalFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_CRITICAL) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_CRITICAL, fn(), nil)
+}
+
+// log error lazy message
+func (logger *Logger) ErrorFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_ERROR, fn(), nil)
+}
+
+// log warning lazy message
+func (logger *Logger) WarningFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_WARNING) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_WARNING, fn(), nil)
+}
+
+// log notice lazy message
+func (logger *Logger) NoticeFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_NOTICE) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_NOTICE, fn(), nil)
+}
+
+// log info lazy message
+func (logger *Logger) InfoFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_INFO) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_INFO, fn(), nil)
+}
+
+// log debug lazy message
+func (logger *Logger) DebugFn(fn func() string) {
+	if !logger.enabled(LOGGER_LEVEL_DEBUG) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_DEBUG, fn(), nil)
+}
+
+// write lazy message of the level with bound fields
+func (child *ChildLogger) WriterFn(level int, fn func() string) error {
+	if !child.logger.enabled(level) {
+		return nil
+	}
+	return child.logger.write(2+child.callerSkip, level, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log emergency lazy message
+func (child *ChildLogger) EmergencyFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_EMERGENCY, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log alert lazy message
+func (child *ChildLogger) AlertFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_ALERT) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ALERT, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log critical lazy message
+func (child *ChildLogger) CriticalFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_CRITICAL) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log error lazy message
+func (child *ChildLogger) ErrorFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ERROR, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log warning lazy message
+func (child *ChildLogger) WarningFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_WARNING) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_WARNING, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log notice lazy message
+func (child *ChildLogger) NoticeFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_NOTICE) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_NOTICE, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log info lazy message
+func (child *ChildLogger) InfoFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_INFO) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_INFO, child.prefix+fn(), child.messageFields(nil))
+}
+
+// log debug lazy message
+func (child *ChildLogger) DebugFn(fn func() string) {
+	if !child.logger.enabled(LOGGER_LEVEL_DEBUG) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_DEBUG, child.prefix+fn(), child.messageFields(nil))
+}
@@ -0,0 +1,50 @@
+package go_logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestLogger_Fn(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_INFO, &WriterConfig{
+		Writer: buffer,
+		Format: "%level_string% %body% %file%:%line%",
+	})
+
+	calls := 0
+	message := func() string {
+		calls++
+		return "lazy"
+	}
+	_, _, line, _ := runtime.Caller(0)
+	logger.InfoFn(message)
+	logger.DebugFn(message)
+	logger.With(map[string]interface{}{"k": "v"}).WithPrefix("child: ").ErrorFn(message)
+	logger.WriterFn(LOGGER_LEVEL_DEBUG, message)
+
+	if calls != 2 {
+		t.Errorf("logger lazy message calls %d, expect 2", calls)
+	}
+	expect := "Info lazy lazy_test.go:" + strconv.Itoa(line+1) + "\n" +
+		"Error child: lazy lazy_test.go:" + strconv.Itoa(line+3) + "\n"
+	if buffer.String() != expect {
+		t.Errorf("logger lazy message error: %q, expect %q", buffer.String(), expect)
+	}
+}
+
+func BenchmarkLogger_DebugFnFiltered(b *testing.B) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.SetLevel(LOGGER_LEVEL_INFO)
+	for i := 0; i < b.N; i++ {
+		logger.DebugFn(func() string {
+			return strconv.Itoa(i)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// response of the level handler
+type levelResponse struct {
+	Level     int    `json:"level"`
+	LevelName string `json:"level_string"`
+}
+
+// http handler of logger level
+//	GET returns current level: {"level":7,"level_string":"Debug"}
+//	PUT sets level by body {"level":"debug"}, {"level":7} or plain text "debug"
+//
+// example:
+//	http.Handle("/debug/level", logger.LevelHandler())
+//	curl -X PUT -d debug http://127.0.0.1:8080/debug/level
+func (logger *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 1024))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level, ok := levelFromRequestBody(body)
+			if !ok {
+				http.Error(w, "level must be one of emergency, alert, critical, error, warning, notice, info, debug", http.StatusBadRequest)
+				return
+			}
+			logger.SetLevel(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		level := logger.Level()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&levelResponse{Level: level, LevelName: levelNames()[level]})
+	})
+}
+
+// level of json {"level": name or number} or plain text body
+func levelFromRequestBody(body []byte) (int, bool) {
+	levelStr := strings.TrimSpace(string(body))
+	if strings.HasPrefix(levelStr, "{") {
+		request := map[string]interface{}{}
+		err := json.Unmarshal(body, &request)
+		if err != nil {
+			return 0, false
+		}
+		switch value := request["level"].(type) {
+		case string:
+			levelStr = value
+		case float64:
+			levelStr = strconv.Itoa(int(value))
+		default:
+			return 0, false
+		}
+	}
+	return levelFromString(levelStr)
+}
+
+// level by name or number, false if it's illegal
+func levelFromString(levelStr string) (int, bool) {
+	level, err := strconv.Atoi(levelStr)
+	if err == nil {
+		return level, levelNames()[level] != ""
+	}
+	for level, name := range levelNames() {
+		if strings.EqualFold(name, levelStr) {
+			return level, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,89 @@
+package go_logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SetLevel(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+	if logger.SetLevel(LOGGER_LEVEL_WARNING) != nil || logger.Level() != LOGGER_LEVEL_WARNING {
+		t.Fatal("set level error")
+	}
+	logger.Info("info")
+	logger.Warning("warning")
+	logger.SetLevel(LOGGER_LEVEL_DEBUG)
+	logger.Debug("debug")
+	logger.Detach("file")
+
+	if readLog() != "warning\ndebug\n" {
+		t.Error("messages less severe than logger level must not be written")
+	}
+	if logger.SetLevel(100) == nil {
+		t.Error("illegal level must be error")
+	}
+}
+
+func TestLogger_LevelHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	handler := logger.LevelHandler()
+
+	tests := []struct {
+		method string
+		body   string
+		code   int
+		level  string
+	}{
+		{"GET", "", http.StatusOK, `"level_string":"Debug"`},
+		{"PUT", "warning", http.StatusOK, `"level_string":"Warning"`},
+		{"PUT", `{"level":"Error"}`, http.StatusOK, `"level_string":"Error"`},
+		{"PUT", `{"level":6}`, http.StatusOK, `"level_string":"Info"`},
+		{"PUT", "verbose", http.StatusBadRequest, ""},
+		{"DELETE", "", http.StatusMethodNotAllowed, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(test.method, "/level", strings.NewReader(test.body)))
+		if w.Code != test.code || !strings.Contains(w.Body.String(), test.level) {
+			t.Errorf("%s %q error: %d %s", test.method, test.body, w.Code, w.Body.String())
+		}
+	}
+	if logger.Level() != LOGGER_LEVEL_INFO {
+		t.Error("level handler must set logger level")
+	}
+}
+
+type levelGateStringer struct {
+	formatted *int
+}
+
+func (s levelGateStringer) String() string {
+	*s.formatted++
+	return "formatted"
+}
+
+func TestLogger_LevelGate(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_WARNING, &MemoryConfig{})
+
+	formatted := 0
+	logger.Infof("%s", levelGateStringer{&formatted})
+	if formatted != 0 {
+		t.Error("messages no adapter writes must not be formatted")
+	}
+
+	logger.SetAdapterLevelRange("memory", LOGGER_LEVEL_DEBUG, LOGGER_LEVEL_EMERGENCY)
+	logger.Infof("%s", levelGateStringer{&formatted})
+	logger.SetLevel(LOGGER_LEVEL_ERROR)
+	logger.Infof("%s", levelGateStringer{&formatted})
+	if formatted != 1 {
+		t.Error("gate must be the least severe of logger level and adapter levels")
+	}
+}
@@ -0,0 +1,61 @@
+package go_logger
+
+import (
+	"runtime"
+)
+
+// line endings of text adapters
+const (
+	LINE_ENDING_LF   = "\n"
+	LINE_ENDING_CRLF = "\r\n"
+)
+
+// encoder of output text, eg: simplifiedchinese.GBK.NewEncoder() of golang.org/x/text for legacy windows tools
+// Bytes is called by one write at a time
+type TextEncoder interface {
+	Bytes(text []byte) ([]byte, error)
+}
+
+// func as a TextEncoder
+//
+// example:
+//	go_logger.TextEncoderFunc(func(text []byte) ([]byte, error) {
+//		return bytes.ToUpper(text), nil
+//	})
+type TextEncoderFunc func(text []byte) ([]byte, error)
+
+func (f TextEncoderFunc) Bytes(text []byte) ([]byte, error) {
+	return f(text)
+}
+
+// default line ending of the system, "\r\n" on windows, otherwise "\n"
+func defaultLineEnding() string {
+	if runtime.GOOS == "windows" {
+		return LINE_ENDING_CRLF
+	}
+	return LINE_ENDING_LF
+}
+
+// line ending of config, empty is the default line ending of the system
+func checkLineEnding(lineEnding *string) error {
+	switch *lineEnding {
+	case "":
+		*lineEnding = defaultLineEnding()
+	case LINE_ENDING_LF, LINE_ENDING_CRLF:
+	default:
+		return configError("", "LineEnding", "must be \"\\n\" or \"\\r\\n\"!")
+	}
+	return nil
+}
+
+// text encoded by encoder, text is not changed if encoder is nil
+func encodeText(encoder TextEncoder, text string) (string, error) {
+	if encoder == nil {
+		return text, nil
+	}
+	encoded, err := encoder.Bytes([]byte(text))
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
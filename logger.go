@@ -1,13 +1,15 @@
 package go_logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"path"
-	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,8 +35,33 @@ type LoggerAbstract interface {
 	Flush()
 }
 
+// adapter releases resources (file handles, connections) when logger is closed, optional
+type LoggerCloser interface {
+	Close() error
+}
+
+// adapter reopens its outputs (files rotated by external tools), optional
+type LoggerReopener interface {
+	Reopen() error
+}
+
+// adapter rotates its outputs on demand (RotateNow), optional
+type LoggerRotator interface {
+	RotateNow(levels ...int) error
+}
+
+// adapter counters reported by Stats(), eg: rotations of file adapter, optional
+type LoggerCounter interface {
+	Counters() map[string]int64
+}
+
+var ErrLoggerClosed = errors.New("logger: logger is closed")
+
 var adapters = make(map[string]adapterLoggerFunc)
 
+// lock of adapters and adapterConfigs, adapters can be registered while loggers attach
+var registryLock sync.RWMutex
+
 var levelStringMapping = map[int]string{
 	LOGGER_LEVEL_EMERGENCY: "Emergency",
 	LOGGER_LEVEL_ALERT:     "Alert",
@@ -48,8 +75,12 @@ var levelStringMapping = map[int]string{
 
 var defaultLoggerMessageFormat = "%millisecond_format% [%level_string%] %body%"
 
-//Register logger adapter
+//Register logger adapter, safe for concurrent use
+//newLog is called by every Attach, registering must not allocate resources of the adapter
 func Register(adapterName string, newLog adapterLoggerFunc) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
 	if adapters[adapterName] != nil {
 		panic("logger: logger adapter " + adapterName + " already registered!")
 	}
@@ -60,32 +91,134 @@ func Register(adapterName string, newLog adapterLoggerFunc) {
 	adapters[adapterName] = newLog
 }
 
+//names of registered adapters, sorted
+func RegisteredAdapters() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//adapter is registered
+func IsRegistered(adapterName string) bool {
+	_, ok := registeredAdapter(adapterName)
+	return ok
+}
+
+//new adapter func of registered adapter
+func registeredAdapter(adapterName string) (adapterLoggerFunc, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	newLog, ok := adapters[adapterName]
+	return newLog, ok
+}
+
 type Logger struct {
-	lock        sync.Mutex          //sync lock
-	outputs     []*outputLogger     // outputs loggers
-	msgChan     chan *loggerMessage // message channel
-	synchronous bool                // is sync
-	wait        sync.WaitGroup      // process wait
-	signalChan  chan string
+	lock          sync.Mutex      //sync lock
+	outputs       []*outputLogger // outputs loggers, replaced by setOutputs() and never changed in place
+	synchronous   bool            // is sync
+	queueCapacity int             // async queue capacity of each adapter, 0 is by adapter capabilities
+	queuePolicy   int             // async queue policy when queue is full
+	closed        int32           // is closed, no more messages are accepted
+	level         int32           // messages less severe are not written, SetLevel() at runtime
+	gate          int32           // least severe level of logger level and adapter levels, checked before messages are built
+	boostGate     int32           // least severe level of adapter levels, messages of sampled traces pass the logger level up to it
+	router        atomic.Value    // *Router, route messages to adapters
+	extractor     atomic.Value    // ContextExtractor, fields of the context
+	sampler       atomic.Value    // *Sampler, sample messages of all adapters
+	hooks         atomic.Value    // []Hook, run before adapters write
+	redactor      atomic.Value    // *Redactor, mask secrets after hooks
+	encryptor     atomic.Value    // *FieldEncryptor, encrypt fields after redaction
+	fieldTypes    atomic.Value    // *fieldTypeTracker, json types of fields
+	slowThreshold atomic.Value    // time.Duration, p99 write latency of slow adapters
+	errorHandler  atomic.Value    // ErrorHandler, adapter write errors
+	early         earlyBuffer     // messages before adapters are attached
+	reloadLock    sync.Mutex      // serialize LoadConfig
+	stats         *loggerStats    // counters of Stats()
+	providers     atomic.Value    // *loggerProviders, message time, sequence and host
+	budgets       atomic.Value    // *loggerBudgets, budgets of categories
+	profiling     int32           // pprof labels and trace regions, SetProfiling()
+	wal           atomic.Value    // *writeAheadLog, SetWAL()
+	idGenerator   atomic.Value    // *IDGenerator, request ids
+	callerSkip    int32           // more frames skipped to the caller, SetCallerSkip()
+	noCaller      int32           // file, line and function are not captured, SetCaller()
+	stackLevel    int32           // stack traces of messages at or above it, SetStacktraceLevel()
+	strict        loggerStrict    // strict mode of internal failures, SetStrict()
+	tees          atomic.Value    // []*Logger, loggers of Tee() writing every message
+	recorder      atomic.Value    // *flightRecorder, SetFlightRecorder()
+	categories    atomic.Value    // map[string]bool, categories claimed by SetAdapterCategories
+	dispatchLock  sync.RWMutex    // read while messages are delivered, written when outputs are swapped and batches are committed
 }
 
 type outputLogger struct {
+	expiredCount int64 // messages dropped by ttl, first for 64-bit atomic alignment on 32-bit platforms
+
 	Name  string
 	Level int
 	LoggerAbstract
+	queue   *asyncQueue
+	sampler atomic.Value // *Sampler, sample messages of the adapter
+	levels  atomic.Value // *levelRange, set by SetAdapterLevelRange
+	tags    atomic.Value // map[string]string, set by SetAdapterTags
+	filter  atomic.Value // *FilterRules, set by SetAdapterFilter
+	dedup   atomic.Value // *deduper, set by SetAdapterDedup
+
+	categories     atomic.Value  // map[string]bool, set by SetAdapterCategories
+	categoryClaims *atomic.Value // Logger.categories
+
+	dependsOn  atomic.Value // []string, adapters closed after it, set by SetAdapterDependsOn
+	timeFormat atomic.Value // *TimeFormat, set by SetAdapterTimeFormat
+
+	timeout       atomic.Value // time.Duration, write timeout
+	ttl           atomic.Value // time.Duration, messages older than it are not written, set by SetAdapterTTL
+	spool         atomic.Value // *adapterSpool, messages are spooled to disk before they're written, set by SetAdapterSpool
+	latency       *latencyTracker
+	slowThreshold *atomic.Value // Logger.slowThreshold
+	errorHandler  *atomic.Value // Logger.errorHandler
+	profiling     *int32        // Logger.profiling
+	strict        *loggerStrict // Logger.strict
+
+	fallback atomic.Value // *adapterFallback, set by SetAdapterFallback
+	standby  int32        // writes fallback messages only
+
+	config       Config               // config of Attach(), nil if the adapter is attached by AttachAdapter
+	configSource *loggerConfigAdapter // adapter config of LoadConfig, unchanged adapters are kept on reload
+}
+
+// levels written by an output, min is the least severe and max is the most severe
+type levelRange struct {
+	min int
+	max int
 }
 
+// json record of the message, logger_easyjson.go is generated by easyjson v0.7.0:
+//	easyjson logger.go
+// easyjson:json
 type loggerMessage struct {
-	Timestamp         int64  `json:"timestamp"`
-	TimestampFormat   string `json:"timestamp_format"`
-	Millisecond       int64  `json:"millisecond"`
-	MillisecondFormat string `json:"millisecond_format"`
-	Level             int    `json:"level"`
-	LevelString       string `json:"level_string"`
-	Body              string `json:"body"`
-	File              string `json:"file"`
-	Line              int    `json:"line"`
-	Function          string `json:"function"`
+	Timestamp         int64             `json:"timestamp"`
+	TimestampFormat   string            `json:"timestamp_format"`
+	Millisecond       int64             `json:"millisecond"`
+	MillisecondFormat string            `json:"millisecond_format"`
+	Level             int               `json:"level"`
+	LevelString       string            `json:"level_string"`
+	Body              string            `json:"body"`
+	File              string            `json:"file"`
+	Line              int               `json:"line"`
+	Function          string            `json:"function"`
+	Fields            loggerFields      `json:"fields,omitempty"`
+	verbose           bool              // written regardless of adapter level and sampling
+	boosted           bool              // of a sampled trace, written regardless of logger level and sampler, adapters filter it
+	sequence          uint64            // sequence of the logger, %sequence%
+	nanosecond        int64             // unix nanoseconds, formatted by SetAdapterTimeFormat
+	host              *loggerHost       // hostname and pid, %hostname% and %pid%
+	wal               *walRecord        // record of the write-ahead log, SetWAL()
+	batched           *[]*loggerMessage // processed messages of a batch, delivered together by Commit()
 }
 
 //new logger
@@ -93,13 +226,17 @@ type loggerMessage struct {
 func NewLogger() *Logger {
 	logger := &Logger{
 		outputs:     []*outputLogger{},
-		msgChan:     make(chan *loggerMessage, 10),
 		synchronous: true,
-		wait:        sync.WaitGroup{},
-		signalChan:  make(chan string, 1),
+		queuePolicy: ASYNC_POLICY_BLOCK,
+		level:       LOGGER_LEVEL_DEBUG,
+		gate:        LOGGER_LEVEL_DEBUG,
+		early:       earlyBuffer{size: EARLY_BUFFER_DEFAULT_SIZE},
+		stats:       &loggerStats{},
+		stackLevel:  STACKTRACE_LEVEL_NONE,
 	}
+	logger.providers.Store(newLoggerProviders(Providers{}))
 	//default adapter console
-	logger.attach("console", LOGGER_LEVEL_DEBUG, &ConsoleConfig{})
+	logger.attach("console", "console", LOGGER_LEVEL_DEBUG, &ConsoleConfig{})
 
 	return logger
 }
@@ -111,39 +248,103 @@ func (logger *Logger) Attach(adapterName string, level int, config Config) error
 	logger.lock.Lock()
 	defer logger.lock.Unlock()
 
-	return logger.attach(adapterName, level, config)
+	return logger.attach(adapterName, adapterName, level, config)
 }
 
-//attach a logger adapter after lock
-//param : adapterName console | file | database | ...
+//attach a logger adapter as name, the same adapter can be attached many times by different names
+//name is used by Detach(), Adapter(), routes and other adapter settings
+//example:
+//	logger.AttachAs("access", "file", go_logger.LOGGER_LEVEL_INFO, &go_logger.FileConfig{Filename: "./access.log"})
+//	logger.AttachAs("errors", "file", go_logger.LOGGER_LEVEL_ERROR, &go_logger.FileConfig{Filename: "./error.log"})
+//param : name string, adapterName console | file | database | ...
+//return : error
+func (logger *Logger) AttachAs(name string, adapterName string, level int, config Config) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	return logger.attach(name, adapterName, level, config)
+}
+
+//attach a logger adapter as name after lock
+//param : name string, adapterName console | file | database | ...
 //return : error
-func (logger *Logger) attach(adapterName string, level int, config Config) error {
+func (logger *Logger) attach(name string, adapterName string, level int, config Config) error {
 	for _, output := range logger.outputs {
-		if output.Name == adapterName {
-			printError("logger: adapter " + adapterName + "already attached!")
+		if output.Name == name {
+			printError("logger: adapter " + name + "already attached!")
 		}
 	}
-	logFun, ok := adapters[adapterName]
+	logFun, ok := registeredAdapter(adapterName)
 	if !ok {
 		printError("logger: adapter " + adapterName + "is nil!")
 	}
 	adapterLog := logFun()
 	err := adapterLog.Init(config)
 	if err != nil {
-		printError("logger: adapter " + adapterName + " init failed, error: " + err.Error())
+		return fmt.Errorf("logger: adapter %s init failed, error: %w", adapterName, configErrorOf(adapterName, err))
+	}
+
+	return logger.attachAdapter(name, level, adapterLog, config)
+}
+
+//attach an initialized adapter, eg: composed by Tee() or Filtered()
+//param : name of the output, adapter LoggerAbstract
+//return : error
+func (logger *Logger) AttachAdapter(name string, level int, adapter LoggerAbstract) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == name {
+			printError("logger: adapter " + name + "already attached!")
+		}
 	}
+	return logger.attachAdapter(name, level, adapter, nil)
+}
 
+//attach an initialized adapter after lock
+func (logger *Logger) attachAdapter(adapterName string, level int, adapterLog LoggerAbstract, config Config) error {
 	output := &outputLogger{
 		Name:           adapterName,
 		Level:          level,
 		LoggerAbstract: adapterLog,
+		config:         config,
 	}
-
-	logger.outputs = append(logger.outputs, output)
+	logger.attachOutput(output)
 	return nil
 }
 
-//start attach a logger adapter
+//attach an output after lock
+func (logger *Logger) attachOutput(output *outputLogger) {
+	logger.initOutput(output)
+	outputs := make([]*outputLogger, 0, len(logger.outputs)+1)
+	outputs = append(outputs, logger.outputs...)
+	logger.setOutputs(append(outputs, output))
+	logger.updateGate()
+	logger.updateCategoryClaims()
+	if len(logger.outputs) == 1 {
+		logger.flushEarly(output)
+	}
+}
+
+//init latency tracker and queue of the output after lock
+func (logger *Logger) initOutput(output *outputLogger) {
+	output.latency = newLatencyTracker()
+	output.slowThreshold = &logger.slowThreshold
+	output.errorHandler = &logger.errorHandler
+	output.profiling = &logger.profiling
+	output.strict = &logger.strict
+	output.categoryClaims = &logger.categories
+	if buffer, ok := output.LoggerAbstract.(loggerBuffer); ok {
+		buffer.setErrorReport(output.writeError)
+	}
+	if !logger.synchronous {
+		output.queue = newAsyncQueue(output, logger.queueCapacityOf(output), logger.queuePolicy)
+	}
+}
+
+//detach a logger adapter, its queue is drained, then it's flushed and closed (LoggerCloser)
+//don't pass a detached adapter to AttachAdapter() again, attach a new one
 //param : adapterName console | file | database | ...
 //return : error
 func (logger *Logger) Detach(adapterName string) error {
@@ -158,149 +359,498 @@ func (logger *Logger) Detach(adapterName string) error {
 //return : error
 func (logger *Logger) detach(adapterName string) error {
 	outputs := []*outputLogger{}
+	removed := []*outputLogger{}
 	for _, output := range logger.outputs {
 		if output.Name == adapterName {
+			removed = append(removed, output)
 			continue
 		}
 		outputs = append(outputs, output)
 	}
+	logger.setOutputs(outputs)
+	for _, output := range removed {
+		logger.removeFallbacks(output)
+	}
+	logger.updateStandby()
+	logger.updateGate()
+	logger.updateCategoryClaims()
+
+	// messages delivered before the swap are written, no more messages are delivered to removed outputs
+	for _, output := range removed {
+		closeOutput(output)
+	}
+	return nil
+}
+
+//replace outputs after lock, it returns after messages being delivered to the old outputs are written or queued
+func (logger *Logger) setOutputs(outputs []*outputLogger) {
+	logger.dispatchLock.Lock()
 	logger.outputs = outputs
+	logger.dispatchLock.Unlock()
+}
+
+//drain queue, flush and close the detached output
+func closeOutput(output *outputLogger) {
+	output.writeDedup(output.flushDedup())
+	if output.queue != nil {
+		output.queue.stop()
+	}
+	output.closeSpool()
+	output.Flush()
+	if closer, ok := output.LoggerAbstract.(LoggerCloser); ok {
+		closer.Close()
+	}
+}
+
+//get attached adapter by name, nil if not attached
+func (logger *Logger) Adapter(adapterName string) LoggerAbstract {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			return output.LoggerAbstract
+		}
+	}
 	return nil
 }
 
-//set logger level
+//set logger level, safe to call at runtime, messages less severe are not written to any adapter
 //params : level int
-//func (logger *Logger) SetLevel(level int) {
-//	logger.level = level
-//}
+func (logger *Logger) SetLevel(level int) error {
+	if levelNames()[level] == "" {
+		return errors.New("logger: level " + strconv.Itoa(level) + " is illegal!")
+	}
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	atomic.StoreInt32(&logger.level, int32(level))
+	logger.updateGate()
+	return nil
+}
+
+//get logger level
+func (logger *Logger) Level() int {
+	return int(atomic.LoadInt32(&logger.level))
+}
+
+//messages of the level may be written, false if the logger level and adapter levels drop them
+//guard messages which are expensive to build, eg: if logger.Enabled(go_logger.LOGGER_LEVEL_DEBUG) {...}
+func (logger *Logger) Enabled(level int) bool {
+	return logger.enabled(level)
+}
+
+//level passes the gate, the first stage of level filter before messages are built
+//adapter levels are the second stage when messages are written
+func (logger *Logger) enabled(level int) bool {
+	return level <= int(atomic.LoadInt32(&logger.gate)) || logger.teeEnabled(level) || logger.recordEnabled(level)
+}
+
+//update gate to the least severe level written by logger level and any adapter, call it after lock
+//gate is logger level before adapters are attached, messages are buffered
+func (logger *Logger) updateGate() {
+	gate := int(atomic.LoadInt32(&logger.level))
+	boostGate := gate
+	if len(logger.outputs) > 0 {
+		adapterLevel := LOGGER_LEVEL_EMERGENCY
+		for _, output := range logger.outputs {
+			if level := output.minLevel(); level > adapterLevel {
+				adapterLevel = level
+			}
+		}
+		if adapterLevel < gate {
+			gate = adapterLevel
+		}
+		boostGate = adapterLevel
+	}
+	atomic.StoreInt32(&logger.gate, int32(gate))
+	atomic.StoreInt32(&logger.boostGate, int32(boostGate))
+}
 
 //set logger synchronous false
-//params : sync bool
+//every adapter writes by its own queue
+//params : queue capacity int, default 100
 func (logger *Logger) SetAsync(data ...int) {
 	logger.lock.Lock()
 	defer logger.lock.Unlock()
+	// queues are replaced while no message is delivered
+	logger.dispatchLock.Lock()
+	defer logger.dispatchLock.Unlock()
 	logger.synchronous = false
 
-	msgChanLen := 100
-	if len(data) > 0 {
-		msgChanLen = data[0]
+	logger.queueCapacity = 0
+	if len(data) > 0 && data[0] > 0 {
+		logger.queueCapacity = data[0]
 	}
 
-	logger.msgChan = make(chan *loggerMessage, msgChanLen)
-	logger.signalChan = make(chan string, 1)
+	for _, output := range logger.outputs {
+		if output.queue != nil {
+			output.queue.stop()
+		}
+		output.queue = newAsyncQueue(output, logger.queueCapacityOf(output), logger.queuePolicy)
+	}
+}
 
-	if !logger.synchronous {
-		go func() {
-			defer func() {
-				e := recover()
-				if e != nil {
-					fmt.Printf("%v", e)
-				}
-			}()
-			logger.startAsyncWrite()
-		}()
+//set async queue policy when queue is full, call it before SetAsync()
+//params : ASYNC_POLICY_BLOCK | ASYNC_POLICY_DROP_OLDEST | ASYNC_POLICY_DROP_NEWEST
+func (logger *Logger) SetAsyncPolicy(policy int) {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	logger.queuePolicy = policy
+}
+
+//set level range of attached adapter, messages from maxLevel (most severe) to minLevel (least severe) are written
+//example, an errors only file, critical and above are handled elsewhere:
+//	logger.SetAdapterLevelRange("file", go_logger.LOGGER_LEVEL_ERROR, go_logger.LOGGER_LEVEL_ERROR)
+//params : adapterName string, minLevel int, maxLevel int
+//return : error
+func (logger *Logger) SetAdapterLevelRange(adapterName string, minLevel int, maxLevel int) error {
+	if levelNames()[minLevel] == "" || levelNames()[maxLevel] == "" {
+		return errors.New("logger: adapter level is illegal!")
+	}
+	if maxLevel > minLevel {
+		return errors.New("logger: adapter maxLevel must be more severe than minLevel!")
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.levels.Store(&levelRange{min: minLevel, max: maxLevel})
+			logger.updateGate()
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+//dropped messages count of all adapter queues
+func (logger *Logger) Dropped() int64 {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	var dropped int64
+	for _, output := range logger.outputs {
+		if output.queue != nil {
+			dropped += output.queue.droppedCount()
+		}
+	}
+	return dropped
+}
+
+//dropped messages count of adapter queue
+func (logger *Logger) AdapterDropped(adapterName string) int64 {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName && output.queue != nil {
+			return output.queue.droppedCount()
+		}
 	}
+	return 0
 }
 
 //write log message
 //params : level int, msg string
 //return : error
 func (logger *Logger) Writer(level int, msg string) error {
-	funcName := "null"
-	pc, file, line, ok := runtime.Caller(2)
-	if !ok {
-		file = "null"
-		line = 0
-	} else {
-		funcName = runtime.FuncForPC(pc).Name()
-	}
-	_, filename := path.Split(file)
+	return logger.write(2, level, msg, nil)
+}
+
+//write log message with fields
+//params : level int, msg string, fields map[string]interface{}
+//return : error
+func (logger *Logger) WriterFields(level int, msg string, fields map[string]interface{}) error {
+	return logger.write(2, level, msg, fields)
+}
 
-	if levelStringMapping[level] == "" {
-		printError("logger: level " + strconv.Itoa(level) + " is illegal!")
+//write log message, callDepth is the stack depth of the caller to report
+func (logger *Logger) write(callDepth int, level int, msg string, fields map[string]interface{}) error {
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		return ErrLoggerClosed
 	}
+	if !logger.enabled(level) {
+		return nil
+	}
+
+	logger.dispatch(logger.callerMessage(callDepth+1, level, msg, fields), nil)
 
-	loggerMsg := &loggerMessage{
-		Timestamp:         time.Now().Unix(),
-		TimestampFormat:   time.Now().Format("2006-01-02 15:04:05"),
-		Millisecond:       time.Now().UnixNano() / 1e6,
-		MillisecondFormat: time.Now().Format("2006-01-02 15:04:05.999"),
+	return logger.strict.err()
+}
+
+//new logger message of the caller at callDepth
+func newCallerMessage(callDepth int, t time.Time, level int, msg string, fields map[string]interface{}) *loggerMessage {
+	// frames are cached by program counter
+	frame := callerOf(callDepth)
+
+	loggerMsg := newLoggerMessage(t, level, msg, fields)
+	loggerMsg.File = frame.file
+	loggerMsg.Line = frame.line
+	loggerMsg.Function = frame.function
+	return loggerMsg
+}
+
+//new logger message at time
+func newLoggerMessage(t time.Time, level int, msg string, fields map[string]interface{}) *loggerMessage {
+	// timestamp format is the prefix of millisecond format, format time once
+	millisecondFormat := t.Format("2006-01-02 15:04:05.999")
+	return &loggerMessage{
+		Timestamp:         t.Unix(),
+		TimestampFormat:   millisecondFormat[:19],
+		Millisecond:       t.UnixNano() / 1e6,
+		nanosecond:        t.UnixNano(),
+		MillisecondFormat: millisecondFormat,
 		Level:             level,
-		LevelString:       levelStringMapping[level],
+		LevelString:       levelNames()[level],
 		Body:              msg,
-		File:              filename,
-		Line:              line,
-		Function:          funcName,
+		Fields:            fields,
+	}
+}
+
+//write message to outputs or queues, only the adapters if adapters is not empty
+func (logger *Logger) dispatchMessage(loggerMsg *loggerMessage, adapters []string) {
+	if len(adapters) == 0 {
+		written := logger.tee(loggerMsg)
+		if logger.record(loggerMsg) || !written {
+			return
+		}
+	}
+	logger.process(loggerMsg, adapters)
+}
+
+//run the pipeline of the logger and write the message
+func (logger *Logger) process(loggerMsg *loggerMessage, adapters []string) {
+	if !loggerMsg.verbose && !loggerMsg.boosted && !logger.sample(loggerMsg) {
+		return
+	}
+	logger.stamp(loggerMsg)
+	withDeployTag(loggerMsg)
+	loggerMsg, ok := logger.runHooks(loggerMsg)
+	if !ok {
+		return
+	}
+	encodeFields(loggerMsg)
+	logger.redact(loggerMsg)
+	logger.encryptFields(loggerMsg)
+	logger.trackFieldTypes(loggerMsg)
+	logger.strictEncoding(loggerMsg)
+	if !logger.inBudget(loggerMsg) {
+		return
+	}
+	logger.stats.count(loggerMsg)
+	logger.writeAhead(loggerMsg)
+	logger.deliver(loggerMsg, adapters)
+}
+
+//write accepted message to outputs or queues, the wal record is released by the dispatcher after
+//messages of a batch are collected and delivered by Commit()
+func (logger *Logger) deliver(loggerMsg *loggerMessage, adapters []string) {
+	if loggerMsg.batched != nil {
+		*loggerMsg.batched = append(*loggerMsg.batched, loggerMsg)
+		loggerMsg.batched = nil
+		return
+	}
+	logger.dispatchLock.RLock()
+	defer logger.dispatchLock.RUnlock()
+	// the logger is closed after the message passed the closed check
+	if atomic.LoadInt32(&logger.closed) == 1 {
+		loggerMsg.releaseWAL(false)
+		return
 	}
+	logger.deliverMessage(loggerMsg, adapters)
+}
 
+func (logger *Logger) deliverMessage(loggerMsg *loggerMessage, adapters []string) {
+	defer loggerMsg.releaseWAL(true)
+	if len(adapters) == 0 && logger.bufferEarly(loggerMsg) {
+		return
+	}
 	if !logger.synchronous {
-		logger.wait.Add(1)
-		logger.msgChan <- loggerMsg
+		logger.writeToQueues(loggerMsg, adapters)
 	} else {
-		logger.writeToOutputs(loggerMsg)
+		logger.writeToOutputs(loggerMsg, adapters)
 	}
-
-	return nil
 }
 
 //sync write message to loggerOutputs
 //params : loggerMessage
-func (logger *Logger) writeToOutputs(loggerMsg *loggerMessage) {
+func (logger *Logger) writeToOutputs(loggerMsg *loggerMessage, adapters []string) {
+	targets, routed := logger.routeTargets(loggerMsg)
 	for _, loggerOutput := range logger.outputs {
-		// write level
-		if loggerOutput.Level >= loggerMsg.Level {
-			err := loggerOutput.Write(loggerMsg)
+		if loggerOutput.accept(loggerMsg, targets, routed) && loggerOutput.selected(adapters) && loggerOutput.sample(loggerMsg) {
+			write, summary := loggerOutput.deduplicate(loggerMsg)
+			loggerOutput.writeDedup(summary)
+			if !write {
+				continue
+			}
+			loggerMsg.retainWAL()
+			err := loggerOutput.send(loggerMsg)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "logger: unable write loggerMessage to adapter:%v, error: %v\n", loggerOutput.Name, err)
+				loggerOutput.writeError(loggerMsg, err)
 			}
+			loggerMsg.releaseWAL(err == nil)
 		}
 	}
 }
 
-//start async write by read logger.msgChan
-func (logger *Logger) startAsyncWrite() {
-	for {
-		select {
-		case loggerMsg := <-logger.msgChan:
-			logger.writeToOutputs(loggerMsg)
-			logger.wait.Done()
-		case signal := <-logger.signalChan:
-			if signal == "flush" {
-				logger.flush()
+//async push message to loggerOutputs queue
+//params : loggerMessage
+func (logger *Logger) writeToQueues(loggerMsg *loggerMessage, adapters []string) {
+	targets, routed := logger.routeTargets(loggerMsg)
+	for _, loggerOutput := range logger.outputs {
+		if loggerOutput.queue != nil && loggerOutput.accept(loggerMsg, targets, routed) && loggerOutput.selected(adapters) && loggerOutput.sample(loggerMsg) {
+			write, summary := loggerOutput.deduplicate(loggerMsg)
+			loggerOutput.writeDedup(summary)
+			if !write {
+				continue
 			}
+			loggerMsg.retainWAL()
+			loggerOutput.queue.push(loggerMsg)
+		}
+	}
+}
+
+//output accepts the message by level, filter rules, categories and router targets
+func (output *outputLogger) accept(loggerMsg *loggerMessage, targets []string, routed bool) bool {
+	if atomic.LoadInt32(&output.standby) == 1 {
+		return false
+	}
+	// write level
+	if !output.levelAccept(loggerMsg.Level) && !loggerMsg.verbose {
+		return false
+	}
+	if !output.filtered(loggerMsg) || !output.categoryAccept(loggerMsg) {
+		return false
+	}
+	if !routed {
+		return true
+	}
+	for _, target := range targets {
+		if target == output.Name {
+			return true
+		}
+	}
+	return false
+}
+
+//least severe level of output
+func (output *outputLogger) minLevel() int {
+	levels, ok := output.levels.Load().(*levelRange)
+	if !ok {
+		return output.Level
+	}
+	return levels.min
+}
+
+//level is in the level range of output, default range is output level to emergency
+func (output *outputLogger) levelAccept(level int) bool {
+	levels, ok := output.levels.Load().(*levelRange)
+	if !ok {
+		return level <= output.Level
+	}
+	return level <= levels.min && level >= levels.max
+}
+
+//output is one of the adapters, or adapters is empty
+func (output *outputLogger) selected(adapters []string) bool {
+	if len(adapters) == 0 {
+		return true
+	}
+	for _, adapterName := range adapters {
+		if adapterName == output.Name {
+			return true
 		}
 	}
+	return false
 }
 
-//flush msgChan data
+//flush queues data
 func (logger *Logger) flush() {
-	if !logger.synchronous {
-		for {
-			if len(logger.msgChan) > 0 {
-				loggerMsg := <-logger.msgChan
-				logger.writeToOutputs(loggerMsg)
-				logger.wait.Done()
-				continue
+	logger.dispatchLock.RLock()
+	defer logger.dispatchLock.RUnlock()
+	logger.flushOutputs(logger.outputs, logger.synchronous)
+}
+
+//flush queues data of the outputs
+func (logger *Logger) flushOutputs(outputs []*outputLogger, synchronous bool) {
+	if !synchronous {
+		for _, loggerOutput := range outputs {
+			loggerOutput.writeDedup(loggerOutput.flushDedup())
+			if loggerOutput.queue != nil {
+				loggerOutput.queue.flush()
 			}
-			break
+			loggerOutput.Flush()
 		}
-		for _, loggerOutput := range logger.outputs {
+		return
+	}
+	// adapters buffering messages are flushed in sync mode too
+	for _, loggerOutput := range outputs {
+		loggerOutput.writeDedup(loggerOutput.flushDedup())
+		if loggerOutput.capabilities().NeedsFlush {
 			loggerOutput.Flush()
 		}
 	}
 }
 
-//if SetAsync() or logger.synchronous is false, must call Flush() to flush msgChan data
+//if SetAsync() or logger.synchronous is false, must call Flush() to flush queues data
 func (logger *Logger) Flush() {
-	if !logger.synchronous {
-		logger.signalChan <- "flush"
-		logger.wait.Wait()
-		return
-	}
 	logger.flush()
 }
 
+//stop accepting new messages, drain async queues, flush and close all adapters
+//adapters are closed before their dependencies and fallback adapters, the write-ahead log is closed last
+//return ctx.Err() if ctx expires before done
+func (logger *Logger) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&logger.closed, 0, 1) {
+		return ErrLoggerClosed
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		logger.lock.Lock()
+		defer logger.lock.Unlock()
+
+		// wait for messages being delivered, messages are not delivered after closed
+		logger.dispatchLock.Lock()
+		logger.dispatchLock.Unlock()
+
+		var closeErr error
+		for _, loggerOutput := range closeOrder(logger.outputs) {
+			loggerOutput.writeDedup(loggerOutput.flushDedup())
+			if loggerOutput.queue != nil {
+				loggerOutput.queue.stop()
+			}
+			loggerOutput.closeSpool()
+			loggerOutput.Flush()
+			if closer, ok := loggerOutput.LoggerAbstract.(LoggerCloser); ok {
+				err := closer.Close()
+				if err != nil && closeErr == nil {
+					closeErr = fmt.Errorf("logger: adapter %s close failed, error: %v", loggerOutput.Name, err)
+				}
+			}
+		}
+		logger.closeEarly()
+		logger.closeWAL()
+		done <- closeErr
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (logger *Logger) LoggerLevel(levelStr string) int {
+	return levelByName(levelStr)
+}
+
+//level by name, default LOGGER_LEVEL_DEBUG
+func levelByName(levelStr string) int {
 	levelStr = strings.ToUpper(levelStr)
 	switch levelStr {
 	case "EMERGENCY":
@@ -320,111 +870,165 @@ func (logger *Logger) LoggerLevel(levelStr string) int {
 	case "DEBUG":
 		return LOGGER_LEVEL_DEBUG
 	default:
+		// custom levels of RegisterLevel
+		level, ok := levelFromString(levelStr)
+		if ok {
+			return level
+		}
 		return LOGGER_LEVEL_DEBUG
 	}
 }
 
+//format message, the format is compiled once and cached
 func loggerMessageFormat(format string, loggerMsg *loggerMessage) string {
-	message := strings.Replace(format, "%timestamp%", strconv.FormatInt(loggerMsg.Timestamp, 10), 1)
-	message = strings.Replace(message, "%timestamp_format%", loggerMsg.TimestampFormat, 1)
-	message = strings.Replace(message, "%millisecond%", strconv.FormatInt(loggerMsg.Millisecond, 10), 1)
-	message = strings.Replace(message, "%millisecond_format%", loggerMsg.MillisecondFormat, 1)
-	message = strings.Replace(message, "%level%", strconv.Itoa(loggerMsg.Level), 1)
-	message = strings.Replace(message, "%level_string%", loggerMsg.LevelString, 1)
-	message = strings.Replace(message, "%file%", loggerMsg.File, 1)
-	message = strings.Replace(message, "%line%", strconv.Itoa(loggerMsg.Line), 1)
-	message = strings.Replace(message, "%function%", loggerMsg.Function, 1)
-	message = strings.Replace(message, "%body%", loggerMsg.Body, 1)
+	defer formatRegion()()
+	return getCompiledFormat(format).format(loggerMsg)
+}
+
+//field value string, empty if not exists
+func loggerMessageField(fields map[string]interface{}, key string) string {
+	value, ok := fields[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+//format fields to "key=value key=value" sorted by key
+func loggerMessageFields(fields map[string]interface{}) string {
+	keys := sortedFieldKeys(fields)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprint(fields[key])
+		if value == "" || strings.ContainsAny(value, " \"=") {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
 
-	return message
+//field keys sorted, fields of text and json formats are in the same order
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 //log emergency level
 func (logger *Logger) Emergency(msg string) {
-	logger.Writer(LOGGER_LEVEL_EMERGENCY, msg)
+	logger.write(2, LOGGER_LEVEL_EMERGENCY, msg, nil)
 }
 
 //log emergency format
 func (logger *Logger) Emergencyf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_EMERGENCY) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_EMERGENCY, msg)
+	logger.write(2, LOGGER_LEVEL_EMERGENCY, msg, nil)
 }
 
 //log alert level
 func (logger *Logger) Alert(msg string) {
-	logger.Writer(LOGGER_LEVEL_ALERT, msg)
+	logger.write(2, LOGGER_LEVEL_ALERT, msg, nil)
 }
 
 //log alert format
 func (logger *Logger) Alertf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_ALERT) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_ALERT, msg)
+	logger.write(2, LOGGER_LEVEL_ALERT, msg, nil)
 }
 
 //log critical level
 func (logger *Logger) Critical(msg string) {
-	logger.Writer(LOGGER_LEVEL_CRITICAL, msg)
+	logger.write(2, LOGGER_LEVEL_CRITICAL, msg, nil)
 }
 
 //log critical format
 func (logger *Logger) Criticalf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_CRITICAL) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_CRITICAL, msg)
+	logger.write(2, LOGGER_LEVEL_CRITICAL, msg, nil)
 }
 
 //log error level
 func (logger *Logger) Error(msg string) {
-	logger.Writer(LOGGER_LEVEL_ERROR, msg)
+	logger.write(2, LOGGER_LEVEL_ERROR, msg, nil)
 }
 
 //log error format
 func (logger *Logger) Errorf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_ERROR, msg)
+	logger.write(2, LOGGER_LEVEL_ERROR, msg, nil)
 }
 
 //log warning level
 func (logger *Logger) Warning(msg string) {
-	logger.Writer(LOGGER_LEVEL_WARNING, msg)
+	logger.write(2, LOGGER_LEVEL_WARNING, msg, nil)
 }
 
 //log warning format
 func (logger *Logger) Warningf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_WARNING) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_WARNING, msg)
+	logger.write(2, LOGGER_LEVEL_WARNING, msg, nil)
 }
 
 //log notice level
 func (logger *Logger) Notice(msg string) {
-	logger.Writer(LOGGER_LEVEL_NOTICE, msg)
+	logger.write(2, LOGGER_LEVEL_NOTICE, msg, nil)
 }
 
 //log notice format
 func (logger *Logger) Noticef(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_NOTICE) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_NOTICE, msg)
+	logger.write(2, LOGGER_LEVEL_NOTICE, msg, nil)
 }
 
 //log info level
 func (logger *Logger) Info(msg string) {
-	logger.Writer(LOGGER_LEVEL_INFO, msg)
+	logger.write(2, LOGGER_LEVEL_INFO, msg, nil)
 }
 
 //log info format
 func (logger *Logger) Infof(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_INFO) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_INFO, msg)
+	logger.write(2, LOGGER_LEVEL_INFO, msg, nil)
 }
 
 //log debug level
 func (logger *Logger) Debug(msg string) {
-	logger.Writer(LOGGER_LEVEL_DEBUG, msg)
+	logger.write(2, LOGGER_LEVEL_DEBUG, msg, nil)
 }
 
 //log debug format
 func (logger *Logger) Debugf(format string, a ...interface{}) {
+	if !logger.enabled(LOGGER_LEVEL_DEBUG) {
+		return
+	}
 	msg := fmt.Sprintf(format, a...)
-	logger.Writer(LOGGER_LEVEL_DEBUG, msg)
+	logger.write(2, LOGGER_LEVEL_DEBUG, msg, nil)
 }
 
 func printError(message string) {
@@ -56,6 +56,8 @@ func easyjson22b64118DecodeGithubComPhachonGoLogger(in *jlexer.Lexer, out *logge
 			out.Line = int(in.Int())
 		case "function":
 			out.Function = string(in.String())
+		case "fields":
+			(out.Fields).UnmarshalEasyJSON(in)
 		default:
 			in.SkipRecursive()
 		}
@@ -72,104 +74,59 @@ func easyjson22b64118EncodeGithubComPhachonGoLogger(out *jwriter.Writer, in logg
 	_ = first
 	{
 		const prefix string = ",\"timestamp\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix[1:])
 		out.Int64(int64(in.Timestamp))
 	}
 	{
 		const prefix string = ",\"timestamp_format\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.TimestampFormat))
 	}
 	{
 		const prefix string = ",\"millisecond\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.Int64(int64(in.Millisecond))
 	}
 	{
 		const prefix string = ",\"millisecond_format\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.MillisecondFormat))
 	}
 	{
 		const prefix string = ",\"level\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.Int(int(in.Level))
 	}
 	{
 		const prefix string = ",\"level_string\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.LevelString))
 	}
 	{
 		const prefix string = ",\"body\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.Body))
 	}
 	{
 		const prefix string = ",\"file\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.File))
 	}
 	{
 		const prefix string = ",\"line\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.Int(int(in.Line))
 	}
 	{
 		const prefix string = ",\"function\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
+		out.RawString(prefix)
 		out.String(string(in.Function))
 	}
+	if len(in.Fields) != 0 {
+		const prefix string = ",\"fields\":"
+		out.RawString(prefix)
+		(in.Fields).MarshalEasyJSON(out)
+	}
 	out.RawByte('}')
 }
 
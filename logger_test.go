@@ -1,7 +1,14 @@
 package go_logger
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -13,6 +20,7 @@ func TestNewLogger(t *testing.T) {
 func TestLogger_Attach(t *testing.T) {
 
 	logger := NewLogger()
+	logger.Detach("console")
 	fileConfig := &FileConfig{
 		Filename: "./test.log",
 	}
@@ -83,3 +91,151 @@ func TestLogger_loggerMessageFormat(t *testing.T) {
 
 	fmt.Println(str)
 }
+
+// new logger writes to a temp file, returns the logger and a func reads the file content
+func newTestFileLogger(t *testing.T, fileConfig *FileConfig) (*Logger, func() string) {
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if fileConfig == nil {
+		fileConfig = &FileConfig{}
+	}
+	fileConfig.Filename = path.Join(dir, "test.log")
+
+	logger := NewLogger()
+	logger.Detach("console")
+	err = logger.Attach("file", LOGGER_LEVEL_DEBUG, fileConfig)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return logger, func() string {
+		content, _ := ioutil.ReadFile(fileConfig.Filename)
+		os.RemoveAll(dir)
+		return string(content)
+	}
+}
+
+func TestLogger_Close(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, nil)
+	logger.SetAsync()
+
+	for i := 0; i < 100; i++ {
+		logger.Infof("close test %d", i)
+	}
+	err := logger.Close(context.Background())
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if logger.Writer(LOGGER_LEVEL_INFO, "after close") != ErrLoggerClosed {
+		t.Error("logger accepts messages after close")
+	}
+
+	content := readLog()
+	if strings.Count(content, "close test") != 100 || strings.Contains(content, "after close") {
+		t.Error("logger close lost messages")
+	}
+}
+
+func TestLogger_CloseWhileWriting(t *testing.T) {
+
+	for _, async := range []bool{false, true} {
+		logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+		if async {
+			logger.SetAsync(10)
+		}
+
+		wait := sync.WaitGroup{}
+		written := int64(0)
+		for i := 0; i < 4; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for logger.Writer(LOGGER_LEVEL_INFO, "writing") == nil {
+					atomic.AddInt64(&written, 1)
+				}
+			}()
+		}
+		for atomic.LoadInt64(&written) < 100 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// messages written before close are delivered, messages racing with close are written or dropped
+		before := atomic.LoadInt64(&written)
+		err := logger.Close(context.Background())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		wait.Wait()
+		lines := int64(strings.Count(readLog(), "writing"))
+		if lines < before || lines > atomic.LoadInt64(&written) {
+			t.Errorf("async %v close while writing error: %d lines, %d before close, %d written", async, lines, before, written)
+		}
+	}
+}
+
+func TestLogger_CloseTimeout(t *testing.T) {
+
+	blockingConfig := &blockingConfig{release: make(chan struct{})}
+	defer close(blockingConfig.release)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+	logger.SetAsync()
+	logger.Info("blocked")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if logger.Close(ctx) != context.DeadlineExceeded {
+		t.Error("logger close timeout error")
+	}
+}
+
+func TestLogger_SetAdapterLevelRange(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%"})
+	err := logger.SetAdapterLevelRange("file", LOGGER_LEVEL_ERROR, LOGGER_LEVEL_ERROR)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger.Critical("critical")
+	logger.Error("error")
+	logger.Warning("warning")
+	logger.Detach("file")
+
+	if readLog() != "error\n" {
+		t.Error("adapter must only write messages of the level range")
+	}
+	if logger.SetAdapterLevelRange("console", LOGGER_LEVEL_ERROR, LOGGER_LEVEL_DEBUG) == nil {
+		t.Error("maxLevel less severe than minLevel must be error")
+	}
+	if logger.SetAdapterLevelRange("file", LOGGER_LEVEL_DEBUG, LOGGER_LEVEL_ERROR) == nil {
+		t.Error("detached adapter must be error")
+	}
+}
+
+func TestLogger_AttachAs(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAs("access", "file", LOGGER_LEVEL_DEBUG, &FileConfig{Filename: path.Join(dir, "access.log"), Format: "%body%"})
+	logger.AttachAs("errors", "file", LOGGER_LEVEL_ERROR, &FileConfig{Filename: path.Join(dir, "error.log"), Format: "%body%"})
+	logger.Info("request")
+	logger.Error("failed")
+	logger.Detach("access")
+	logger.Detach("errors")
+
+	access, _ := ioutil.ReadFile(path.Join(dir, "access.log"))
+	errorLog, _ := ioutil.ReadFile(path.Join(dir, "error.log"))
+	if string(access) != "request\nfailed\n" || string(errorLog) != "failed\n" {
+		t.Errorf("adapter attached as names error: %q %q", access, errorLog)
+	}
+}
@@ -0,0 +1,143 @@
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"github.com/phachon/go-logger"
+	"sync"
+	"testing"
+	"time"
+)
+
+// run the conformance checks of the adapter SDK against an out-of-tree adapter
+// every check inits a new adapter of newAdapter with a new config of newConfig
+//
+// example:
+//	func TestKafkaAdapter(t *testing.T) {
+//		loggertest.AdapterConformance(t, NewKafkaAdapter, func() go_logger.Config {
+//			return &KafkaConfig{Brokers: []string{broker}}
+//		})
+//	}
+func AdapterConformance(t *testing.T, newAdapter func() go_logger.AdapterV1, newConfig func() go_logger.Config) {
+	t.Helper()
+
+	t.Run("Name", func(t *testing.T) {
+		adapter, config := newAdapter(), newConfig()
+		if adapter.Name() == "" || adapter.Name() != config.Name() {
+			t.Errorf("loggertest: adapter name %q must be the name of its config %q", adapter.Name(), config.Name())
+		}
+	})
+
+	t.Run("InitNilConfig", func(t *testing.T) {
+		err := conformanceCall("Init", func() error {
+			return newAdapter().Init(nil)
+		})
+		if err == nil {
+			t.Error("loggertest: Init of nil config must return an error")
+		}
+	})
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		adapter := initConformanceAdapter(t, newAdapter, newConfig)
+		for _, entry := range conformanceEntries() {
+			if err := conformanceCall("Write", func() error { return adapter.Write(entry) }); err != nil {
+				t.Errorf("loggertest: Write of entry %q failed, error: %v", entry.Body, err)
+			}
+		}
+		if err := conformanceCall("Flush", adapter.Flush); err != nil {
+			t.Errorf("loggertest: Flush failed, error: %v", err)
+		}
+		if err := conformanceCall("Close", adapter.Close); err != nil {
+			t.Errorf("loggertest: Close failed, error: %v", err)
+		}
+	})
+
+	t.Run("WriteBatch", func(t *testing.T) {
+		adapter := initConformanceAdapter(t, newAdapter, newConfig)
+		defer adapter.Close()
+		batcher, ok := adapter.(go_logger.AdapterV1Batcher)
+		if !ok {
+			t.Skip("adapter doesn't write batches")
+		}
+		if batcher.BatchSize() < 0 {
+			t.Errorf("loggertest: BatchSize must not be negative")
+		}
+		if err := conformanceCall("WriteBatch", func() error { return batcher.WriteBatch(nil) }); err != nil {
+			t.Errorf("loggertest: WriteBatch of no entries failed, error: %v", err)
+		}
+		if err := conformanceCall("WriteBatch", func() error { return batcher.WriteBatch(conformanceEntries()) }); err != nil {
+			t.Errorf("loggertest: WriteBatch failed, error: %v", err)
+		}
+	})
+
+	t.Run("Logger", func(t *testing.T) {
+		for _, async := range []bool{false, true} {
+			adapter := initConformanceAdapter(t, newAdapter, newConfig)
+			logger := go_logger.NewLogger()
+			logger.Detach("console")
+			logger.AttachAdapter(adapter.Name(), go_logger.LOGGER_LEVEL_DEBUG, go_logger.WrapAdapter(adapter))
+			if async {
+				logger.SetAsync()
+			}
+			wait := sync.WaitGroup{}
+			for i := 0; i < 4; i++ {
+				wait.Add(1)
+				go func(i int) {
+					defer wait.Done()
+					for n := 0; n < 25; n++ {
+						logger.WriterFields(go_logger.LOGGER_LEVEL_INFO, "conformance", map[string]interface{}{"goroutine": i, "n": n})
+					}
+				}(i)
+			}
+			wait.Wait()
+			logger.Flush()
+
+			ctx, cancel := context.WithTimeout(context.Background(), CloseTimeout)
+			err := logger.Close(ctx)
+			cancel()
+			if err != nil {
+				t.Errorf("loggertest: close logger of adapter failed, async %v, error: %v", async, err)
+			}
+		}
+	})
+}
+
+// new adapter initialized by a new config, the test fails if Init fails
+func initConformanceAdapter(t *testing.T, newAdapter func() go_logger.AdapterV1, newConfig func() go_logger.Config) go_logger.AdapterV1 {
+	t.Helper()
+	adapter := newAdapter()
+	err := conformanceCall("Init", func() error {
+		return adapter.Init(newConfig())
+	})
+	if err != nil {
+		t.Fatalf("loggertest: Init failed, error: %v", err)
+	}
+	return adapter
+}
+
+// entries of every level, without and with fields, empty and multi-line bodies
+func conformanceEntries() []*go_logger.LogEntry {
+	now := time.Now()
+	entries := []*go_logger.LogEntry{}
+	for level := go_logger.LOGGER_LEVEL_EMERGENCY; level <= go_logger.LOGGER_LEVEL_DEBUG; level++ {
+		entries = append(entries, &go_logger.LogEntry{Time: now, Level: level, Body: "conformance", File: "conformance.go", Line: 1, Function: "AdapterConformance"})
+	}
+	entries = append(entries,
+		&go_logger.LogEntry{Time: now, Level: go_logger.LOGGER_LEVEL_INFO, Body: ""},
+		&go_logger.LogEntry{Time: now, Level: go_logger.LOGGER_LEVEL_INFO, Body: "line 1\nline 2 \"quoted\" ünïcode"},
+		&go_logger.LogEntry{Time: now, Level: go_logger.LOGGER_LEVEL_ERROR, Body: "fields", Fields: map[string]interface{}{
+			"string": "value", "int": 1, "float": 1.5, "bool": true, "nil": nil, "error": fmt.Errorf("failed"),
+		}},
+	)
+	return entries
+}
+
+// call fn, a panic is returned as an error
+func conformanceCall(method string, fn func() error) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("%s panic: %v", method, e)
+		}
+	}()
+	return fn()
+}
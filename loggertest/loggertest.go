@@ -0,0 +1,113 @@
+// helpers of tests logging by go_logger, async messages are flushed before assertions and test completion
+package loggertest
+
+import (
+	"context"
+	"github.com/phachon/go-logger"
+	"strings"
+	"testing"
+	"time"
+)
+
+// max wait of closing the logger of New() on test completion
+var CloseTimeout = 5 * time.Second
+
+// flush async messages of logger now and again on test completion
+// call it after the code under test and before assertions on log output:
+//	doWork(logger)
+//	loggertest.Sync(t, logger)
+//	// assert log file
+func Sync(t testing.TB, logger *go_logger.Logger) {
+	t.Helper()
+	logger.Flush()
+	t.Cleanup(logger.Flush)
+}
+
+// new async logger writing messages to t.Log, closed on test completion
+// more adapters can be attached to it
+func New(t testing.TB) *go_logger.Logger {
+	t.Helper()
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.WriterConfig{
+		Writer: &testWriter{t: t},
+	})
+	logger.SetAsync()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), CloseTimeout)
+		defer cancel()
+		err := logger.Close(ctx)
+		if err != nil && err != go_logger.ErrLoggerClosed {
+			t.Errorf("loggertest: close logger failed, error: %v", err)
+		}
+	})
+	return logger
+}
+
+// write lines to t.Log
+type testWriter struct {
+	t testing.TB
+}
+
+func (tw *testWriter) Write(p []byte) (int, error) {
+	tw.t.Log(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// attach a test adapter recording messages of every level to logger, detached on test completion
+// assert recorded messages by AssertLogged() and AssertNotLogged()
+//
+// example:
+//	recorded := loggertest.Record(t, logger)
+//	doWork(logger)
+//	loggertest.AssertLogged(t, logger, go_logger.MatchLevel(go_logger.LOGGER_LEVEL_WARNING), go_logger.MatchField("order", 1001))
+//	recorded.Reset()
+func Record(t testing.TB, logger *go_logger.Logger) *go_logger.AdapterTest {
+	t.Helper()
+	err := logger.Attach(go_logger.TEST_ADAPTER_NAME, go_logger.LOGGER_LEVEL_DEBUG, &go_logger.TestConfig{})
+	if err != nil {
+		t.Fatalf("loggertest: attach test adapter failed, error: %v", err)
+	}
+	t.Cleanup(func() {
+		logger.Detach(go_logger.TEST_ADAPTER_NAME)
+	})
+	return logger.Adapter(go_logger.TEST_ADAPTER_NAME).(*go_logger.AdapterTest)
+}
+
+// fail the test if no message recorded by Record() is matched by all matchers, async messages are flushed before
+func AssertLogged(t testing.TB, logger *go_logger.Logger, matchers ...go_logger.EntryMatcher) {
+	t.Helper()
+	if len(recorded(t, logger).Match(matchers...)) == 0 {
+		t.Errorf("loggertest: no message is logged with %s", describe(matchers))
+	}
+}
+
+// fail the test if a message recorded by Record() is matched by all matchers, async messages are flushed before
+func AssertNotLogged(t testing.TB, logger *go_logger.Logger, matchers ...go_logger.EntryMatcher) {
+	t.Helper()
+	if matched := recorded(t, logger).Match(matchers...); len(matched) > 0 {
+		t.Errorf("loggertest: %d messages are logged with %s, first: %q", len(matched), describe(matchers), matched[0].Body)
+	}
+}
+
+// flush logger and return its test adapter
+func recorded(t testing.TB, logger *go_logger.Logger) *go_logger.AdapterTest {
+	t.Helper()
+	logger.Flush()
+	adapterTest, ok := logger.Adapter(go_logger.TEST_ADAPTER_NAME).(*go_logger.AdapterTest)
+	if !ok {
+		t.Fatalf("loggertest: test adapter is not attached, call loggertest.Record() before")
+	}
+	return adapterTest
+}
+
+func describe(matchers []go_logger.EntryMatcher) string {
+	if len(matchers) == 0 {
+		return "any entry"
+	}
+	descriptions := make([]string, len(matchers))
+	for i, matcher := range matchers {
+		descriptions[i] = matcher.String()
+	}
+	return strings.Join(descriptions, ", ")
+}
@@ -0,0 +1,160 @@
+package loggertest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/phachon/go-logger"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type lockedBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.lock.Lock()
+	defer lb.lock.Unlock()
+	return lb.buffer.String()
+}
+
+func TestSync(t *testing.T) {
+
+	buffer := &lockedBuffer{}
+	t.Run("sync", func(t *testing.T) {
+		logger := go_logger.NewLogger()
+		logger.Detach("console")
+		logger.Attach("writer", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.WriterConfig{Writer: buffer, Format: "%body%"})
+		logger.SetAsync()
+
+		for i := 0; i < 100; i++ {
+			logger.Info("async")
+		}
+		Sync(t, logger)
+		if strings.Count(buffer.String(), "async\n") != 100 {
+			t.Errorf("messages must be flushed by Sync")
+		}
+		logger.Info("after assertions")
+	})
+	if !strings.Contains(buffer.String(), "after assertions\n") {
+		t.Errorf("messages must be flushed on test completion")
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	var logger *go_logger.Logger
+	t.Run("new", func(t *testing.T) {
+		logger = New(t)
+		logger.Info("written to t.Log")
+	})
+	if logger.Writer(go_logger.LOGGER_LEVEL_INFO, "closed") != go_logger.ErrLoggerClosed {
+		t.Errorf("logger of New must be closed on test completion")
+	}
+}
+
+// testing.TB recording failures of assertions
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (fr *failureRecorder) Errorf(format string, args ...interface{}) {
+	fr.failures = append(fr.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertLogged(t *testing.T) {
+
+	logger := New(t)
+	recorded := Record(t, logger)
+	logger.With(map[string]interface{}{"order": 1001}).Warning("payment retried")
+
+	AssertLogged(t, logger, go_logger.MatchLevel(go_logger.LOGGER_LEVEL_WARNING), go_logger.MatchField("order", 1001))
+	AssertNotLogged(t, logger, go_logger.MatchLevel(go_logger.LOGGER_LEVEL_ERROR))
+
+	failures := &failureRecorder{TB: t}
+	AssertLogged(failures, logger, go_logger.MatchBody("refunded"))
+	AssertNotLogged(failures, logger, go_logger.MatchField("order", nil))
+	if len(failures.failures) != 2 || failures.failures[0] != `loggertest: no message is logged with body containing "refunded"` {
+		t.Errorf("assertion failures error: %q", failures.failures)
+	}
+
+	recorded.Reset()
+	AssertNotLogged(t, logger)
+}
+
+type conformanceConfig struct{}
+
+func (config *conformanceConfig) Name() string {
+	return "conformance"
+}
+
+// adapter of the SDK writing text lines to a buffer
+type conformanceAdapter struct {
+	buffer *bytes.Buffer
+}
+
+func (adapter *conformanceAdapter) Name() string {
+	return "conformance"
+}
+
+func (adapter *conformanceAdapter) Init(config go_logger.Config) error {
+	if _, ok := config.(*conformanceConfig); !ok {
+		return fmt.Errorf("config must conformanceConfig")
+	}
+	adapter.buffer = &bytes.Buffer{}
+	return nil
+}
+
+func (adapter *conformanceAdapter) Write(entry *go_logger.LogEntry) error {
+	adapter.buffer.WriteString(entry.Text("[%level_string%] %body%") + "\n")
+	return nil
+}
+
+func (adapter *conformanceAdapter) Flush() error {
+	return nil
+}
+
+func (adapter *conformanceAdapter) Close() error {
+	return nil
+}
+
+type conformanceBatcher struct {
+	conformanceAdapter
+}
+
+func (adapter *conformanceBatcher) WriteBatch(entries []*go_logger.LogEntry) error {
+	for _, entry := range entries {
+		record, err := entry.Json()
+		if err != nil {
+			return err
+		}
+		adapter.buffer.Write(append(record, '\n'))
+	}
+	return nil
+}
+
+func (adapter *conformanceBatcher) BatchSize() int {
+	return 10
+}
+
+func TestAdapterConformance(t *testing.T) {
+
+	newConfig := func() go_logger.Config {
+		return &conformanceConfig{}
+	}
+	AdapterConformance(t, func() go_logger.AdapterV1 {
+		return &conformanceAdapter{}
+	}, newConfig)
+	AdapterConformance(t, func() go_logger.AdapterV1 {
+		return &conformanceBatcher{}
+	}, newConfig)
+}
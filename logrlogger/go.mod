@@ -0,0 +1,20 @@
+module github.com/phachon/go-logger/logrlogger
+
+go 1.18
+
+require (
+	github.com/go-logr/logr v1.4.2
+	github.com/phachon/go-logger v0.0.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.3.2 // indirect
+	github.com/fatih/color v1.7.0 // indirect
+	github.com/mailru/easyjson v0.7.0 // indirect
+	github.com/mattn/go-colorable v0.1.4 // indirect
+	github.com/mattn/go-isatty v0.0.11 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/phachon/go-logger => ../
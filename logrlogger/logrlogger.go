@@ -0,0 +1,113 @@
+// logr.LogSink writing to go_logger, it's a module of its own so go_logger doesn't depend on logr
+package logrlogger
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"github.com/phachon/go-logger"
+)
+
+// field of the error of Error()
+const FIELD_ERROR = "error"
+
+// field of keys and values without value
+const MISSING_VALUE = "(MISSING)"
+
+// sink config
+type Config struct {
+
+	// levels of logr verbosities, V(i) is Levels[i], higher verbosities are the last level
+	// default V(0) is LOGGER_LEVEL_INFO, others are LOGGER_LEVEL_DEBUG
+	Levels []int
+}
+
+// logr.LogSink of the logger, names are the component of child loggers ("controller.reconciler")
+// and values are fields
+type LogSink struct {
+	logger *go_logger.Logger
+	child  *go_logger.ChildLogger
+	levels []int
+}
+
+var _ logr.CallDepthLogSink = &LogSink{}
+
+// new logr.Logger writing to the logger
+//
+// example:
+//	ctrl.SetLogger(logrlogger.New(logger, nil))
+func New(logger *go_logger.Logger, config *Config) logr.Logger {
+	return logr.New(NewLogSink(logger, config))
+}
+
+// new logr.LogSink writing to the logger
+func NewLogSink(logger *go_logger.Logger, config *Config) *LogSink {
+	sink := &LogSink{logger: logger, child: logger.With(nil)}
+	if config != nil {
+		sink.levels = config.Levels
+	}
+	if len(sink.levels) == 0 {
+		sink.levels = []int{go_logger.LOGGER_LEVEL_INFO, go_logger.LOGGER_LEVEL_DEBUG}
+	}
+	return sink
+}
+
+// callers of logr are the callers of messages
+func (sink *LogSink) Init(info logr.RuntimeInfo) {
+	sink.child = sink.child.WithCallerSkip(info.CallDepth + 1)
+}
+
+func (sink *LogSink) Enabled(level int) bool {
+	return sink.logger.Enabled(sink.level(level))
+}
+
+func (sink *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	sink.child.WriterFields(sink.level(level), msg, fieldsOf(keysAndValues))
+}
+
+func (sink *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := fieldsOf(keysAndValues)
+	if err != nil {
+		fields[FIELD_ERROR] = err.Error()
+	}
+	sink.child.WriterFields(go_logger.LOGGER_LEVEL_ERROR, msg, fields)
+}
+
+func (sink *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &LogSink{logger: sink.logger, child: sink.child.With(fieldsOf(keysAndValues)), levels: sink.levels}
+}
+
+func (sink *LogSink) WithName(name string) logr.LogSink {
+	return &LogSink{logger: sink.logger, child: sink.child.New(name), levels: sink.levels}
+}
+
+func (sink *LogSink) WithCallDepth(depth int) logr.LogSink {
+	return &LogSink{logger: sink.logger, child: sink.child.WithCallerSkip(depth), levels: sink.levels}
+}
+
+// level of the verbosity
+func (sink *LogSink) level(verbosity int) int {
+	if verbosity < 0 {
+		verbosity = 0
+	}
+	if verbosity >= len(sink.levels) {
+		return sink.levels[len(sink.levels)-1]
+	}
+	return sink.levels[verbosity]
+}
+
+// fields of key value pairs, a key without value is MISSING_VALUE
+func fieldsOf(keysAndValues []interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i+1 < len(keysAndValues) {
+			fields[key] = keysAndValues[i+1]
+		} else {
+			fields[key] = MISSING_VALUE
+		}
+	}
+	return fields
+}
@@ -0,0 +1,55 @@
+package logrlogger
+
+import (
+	"errors"
+	"github.com/phachon/go-logger"
+	"strings"
+	"testing"
+)
+
+func TestLogSink(t *testing.T) {
+
+	logger := go_logger.NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.TestConfig{})
+	recorded := logger.Adapter("test").(*go_logger.AdapterTest)
+
+	log := New(logger, nil).WithName("controller").WithValues("namespace", "prod")
+	log.Info("reconciled", "name", "web", "replicas", 3)
+	log.V(1).Info("cache hit")
+	log.V(5).Info("trace")
+	log.WithName("reconciler").Error(errors.New("conflict"), "update failed", "retry")
+
+	entries := recorded.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("logr records error: %v", entries)
+	}
+	info := entries[0]
+	if info.Level != go_logger.LOGGER_LEVEL_INFO || info.Body != "reconciled" || info.Fields["component"] != "controller" ||
+		info.Fields["namespace"] != "prod" || info.Fields["name"] != "web" || info.Fields["replicas"] != 3 {
+		t.Errorf("logr info record error: %v", info)
+	}
+	if !strings.HasSuffix(info.File, "logrlogger_test.go") {
+		t.Errorf("logr caller error: %s", info.File)
+	}
+	if entries[1].Level != go_logger.LOGGER_LEVEL_DEBUG || entries[2].Level != go_logger.LOGGER_LEVEL_DEBUG {
+		t.Errorf("logr verbosity error: %v", entries[1:3])
+	}
+	failed := entries[3]
+	if failed.Level != go_logger.LOGGER_LEVEL_ERROR || failed.Fields["error"] != "conflict" || failed.Fields["retry"] != MISSING_VALUE ||
+		failed.Fields["component"] != "controller.reconciler" {
+		t.Errorf("logr error record error: %v", failed)
+	}
+
+	logger.SetAdapterLevelRange("test", go_logger.LOGGER_LEVEL_INFO, go_logger.LOGGER_LEVEL_EMERGENCY)
+	if !log.Enabled() || log.V(1).Enabled() {
+		t.Error("logr enabled error")
+	}
+}
+
+func TestLogSink_Levels(t *testing.T) {
+	sink := NewLogSink(go_logger.NewLogger(), &Config{Levels: []int{go_logger.LOGGER_LEVEL_NOTICE, go_logger.LOGGER_LEVEL_INFO, go_logger.LOGGER_LEVEL_DEBUG}})
+	if sink.level(0) != go_logger.LOGGER_LEVEL_NOTICE || sink.level(1) != go_logger.LOGGER_LEVEL_INFO || sink.level(9) != go_logger.LOGGER_LEVEL_DEBUG {
+		t.Error("logr levels error")
+	}
+}
@@ -0,0 +1,199 @@
+package go_logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// default entries of a LogStore page
+const LOG_STORE_DEFAULT_LIMIT = 100
+
+// store of log entries paged by cursor, eg: AdapterMemory, FileLogStore
+type LogStore interface {
+	Query(query *LogQuery) (*LogPage, error)
+}
+
+// query of a LogStore page
+type LogQuery struct {
+
+	// entries at or above the level, eg: "error" or "3", empty is all levels
+	Level string
+
+	// entries of the time range, zero is unbounded
+	Since time.Time
+	Until time.Time
+
+	// NextCursor of the previous page, empty is the newest page
+	Cursor string
+
+	// max entries of the page, default 100
+	Limit int
+}
+
+// page of a LogStore query
+type LogPage struct {
+
+	// entries, newest first
+	Entries []LogEntry
+
+	// cursor of the next older page, empty if it's the last page
+	NextCursor string
+
+	// entries matched by the level and time range in the store
+	Total int
+}
+
+// entry of a store with its position, positions increase with writes
+type storedEntry struct {
+	position int64
+	entry    LogEntry
+}
+
+// page of stored entries (oldest first) by the query
+func queryStoredEntries(storedEntries []storedEntry, query *LogQuery) (*LogPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = LOG_STORE_DEFAULT_LIMIT
+	}
+	level, allLevels := 0, query.Level == ""
+	if !allLevels {
+		var ok bool
+		level, ok = levelFromString(query.Level)
+		if !ok {
+			return nil, errors.New("logger: query level " + query.Level + " is illegal!")
+		}
+	}
+	cursor := int64(-1)
+	if query.Cursor != "" {
+		var err error
+		cursor, err = strconv.ParseInt(query.Cursor, 10, 64)
+		if err != nil || cursor < 0 {
+			return nil, errors.New("logger: query cursor " + query.Cursor + " is illegal!")
+		}
+	}
+
+	page := &LogPage{Entries: []LogEntry{}}
+	lastPosition := int64(0)
+	for i := len(storedEntries) - 1; i >= 0; i-- {
+		stored := storedEntries[i]
+		if !allLevels && stored.entry.Level > level {
+			continue
+		}
+		if !query.Since.IsZero() && stored.entry.Time.Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && !stored.entry.Time.Before(query.Until) {
+			continue
+		}
+		page.Total++
+		if cursor >= 0 && stored.position >= cursor {
+			continue
+		}
+		if len(page.Entries) == limit {
+			// an older entry is matched, the page is not the last one
+			page.NextCursor = strconv.FormatInt(lastPosition, 10)
+			continue
+		}
+		page.Entries = append(page.Entries, stored.entry)
+		lastPosition = stored.position
+	}
+	return page, nil
+}
+
+// store of json records of the file adapter (JsonFormat), other lines are skipped
+// the file is read for every query, positions are offsets of records in the file
+type FileLogStore struct {
+	Filename string
+}
+
+func (store *FileLogStore) Query(query *LogQuery) (*LogPage, error) {
+	file, err := os.Open(store.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	storedEntries := []storedEntry{}
+	reader := bufio.NewReaderSize(file, 64*1024)
+	offset := int64(0)
+	for {
+		line, err := reader.ReadBytes('\n')
+		position := offset
+		offset += int64(len(line))
+		if len(line) > 0 {
+			entry, parseErr := ParseLogEntry(line)
+			if parseErr == nil {
+				storedEntries = append(storedEntries, storedEntry{position: position, entry: entry})
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return queryStoredEntries(storedEntries, query)
+}
+
+// http handler of the store, a json page {"entries": [...], "next_cursor": "", "total": 0}, entries newest first
+//
+// query params:
+//	level  entries at or above the level, eg: "error" or "3"
+//	since  entries since the time, RFC3339, eg: "2024-01-02T15:04:05Z"
+//	until  entries before the time, RFC3339
+//	cursor next_cursor of the previous page
+//	limit  max entries, default 100
+//
+// example:
+//	http.Handle("/admin/logs", go_logger.LogStoreHandler(&go_logger.FileLogStore{Filename: "./app.log"}))
+func LogStoreHandler(store LogStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		query := &LogQuery{Level: params.Get("level"), Cursor: params.Get("cursor")}
+		var err error
+		for name, t := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+			if value := params.Get(name); value != "" {
+				*t, err = time.Parse(time.RFC3339, value)
+				if err != nil {
+					http.Error(w, name+" must be a RFC3339 time", http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		if limitStr := params.Get("limit"); limitStr != "" {
+			query.Limit, err = strconv.Atoi(limitStr)
+			if err != nil || query.Limit <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if _, ok := levelFromString(query.Level); query.Level != "" && !ok {
+			http.Error(w, "level must be a level name or number", http.StatusBadRequest)
+			return
+		}
+		if cursor, err := strconv.ParseInt(query.Cursor, 10, 64); query.Cursor != "" && (err != nil || cursor < 0) {
+			http.Error(w, "cursor must be next_cursor of the previous page", http.StatusBadRequest)
+			return
+		}
+
+		page, err := store.Query(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entries := make([]json.RawMessage, len(page.Entries))
+		for i, entry := range page.Entries {
+			entries[i] = marshalLoggerMessage(entry.loggerMessage(), nil)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries":     entries,
+			"next_cursor": page.NextCursor,
+			"total":       page.Total,
+		})
+	})
+}
@@ -0,0 +1,97 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testLogStorePages(t *testing.T, name string, store LogStore) {
+	bodies := func(page *LogPage) []string {
+		result := []string{}
+		for _, entry := range page.Entries {
+			result = append(result, entry.Body)
+		}
+		return result
+	}
+
+	// 10 messages, error level every 3
+	page, err := store.Query(&LogQuery{Level: "error", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 4 || len(page.Entries) != 2 || page.Entries[0].Body != "m9" || page.Entries[1].Body != "m6" || page.NextCursor == "" {
+		t.Fatalf("%s first page error: %v %d %q", name, bodies(page), page.Total, page.NextCursor)
+	}
+	page, _ = store.Query(&LogQuery{Level: "error", Limit: 2, Cursor: page.NextCursor})
+	if page.Total != 4 || len(page.Entries) != 2 || page.Entries[0].Body != "m3" || page.Entries[1].Body != "m0" || page.NextCursor != "" {
+		t.Errorf("%s last page error: %v %d %q", name, bodies(page), page.Total, page.NextCursor)
+	}
+
+	page, _ = store.Query(&LogQuery{Since: TEST_PROVIDERS_START_TIME.Add(2 * time.Millisecond), Until: TEST_PROVIDERS_START_TIME.Add(5 * time.Millisecond)})
+	if page.Total != 3 || len(page.Entries) != 3 || page.Entries[0].Body != "m4" || page.Entries[2].Body != "m2" {
+		t.Errorf("%s time range error: %v %d", name, bodies(page), page.Total)
+	}
+	if _, err := store.Query(&LogQuery{Level: "verbose"}); err == nil {
+		t.Errorf("%s illegal level error", name)
+	}
+
+	recorder := httptest.NewRecorder()
+	LogStoreHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?level=error&limit=3", nil))
+	response := struct {
+		Entries    []map[string]interface{} `json:"entries"`
+		NextCursor string                   `json:"next_cursor"`
+		Total      int                      `json:"total"`
+	}{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	if err != nil || len(response.Entries) != 3 || response.Entries[0]["body"] != "m9" || response.Total != 4 || response.NextCursor == "" {
+		t.Errorf("%s handler error: %s", name, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	LogStoreHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?cursor=x", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("%s handler cursor error: %d", name, recorder.Code)
+	}
+}
+
+func TestLogStore(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.SetTestMode()
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{Size: 20})
+	logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{Filename: filename, JsonFormat: true})
+	for i := 0; i < 10; i++ {
+		level := LOGGER_LEVEL_INFO
+		if i%3 == 0 {
+			level = LOGGER_LEVEL_ERROR
+		}
+		logger.Writer(level, "m"+strconv.Itoa(i))
+	}
+	logger.Flush()
+
+	memory := logger.Adapter("memory").(*AdapterMemory)
+	testLogStorePages(t, "memory", memory)
+	testLogStorePages(t, "file", &FileLogStore{Filename: filename})
+
+	// cursors of the memory store are stable while messages are written
+	page, _ := memory.Query(&LogQuery{Limit: 5})
+	logger.Info("m10")
+	page, _ = memory.Query(&LogQuery{Limit: 5, Cursor: page.NextCursor})
+	if len(page.Entries) != 5 || page.Entries[0].Body != "m4" || page.Total != 11 {
+		t.Errorf("memory cursor error: %v", page.Entries)
+	}
+}
@@ -0,0 +1,360 @@
+package go_logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const LOKI_ADAPTER_NAME = "loki"
+
+const (
+	LOKI_PUSH_PATH                 = "/loki/api/v1/push"
+	LOKI_READY_PATH                = "/ready"
+	LOKI_DEFAULT_BATCH_SIZE        = 500
+	LOKI_DEFAULT_FLUSH_INTERVAL    = 5 * time.Second
+	LOKI_DEFAULT_TIMEOUT           = 10 * time.Second
+	LOKI_DEFAULT_MAX_RETRIES       = 3
+	LOKI_DEFAULT_RETRY_INTERVAL    = 500 * time.Millisecond
+	LOKI_DEFAULT_MAX_RETRY_BACKOFF = 30 * time.Second
+)
+
+// adapter loki
+type AdapterLoki struct {
+	encodingErrors int64 // json fallbacks of marshal errors
+
+	lock   sync.Mutex
+	config *LokiConfig
+	client *http.Client
+	buffer []*loggerMessage
+	ticker *time.Ticker
+	quit   chan struct{}
+}
+
+// loki config
+type LokiConfig struct {
+
+	// loki address, eg: "http://127.0.0.1:3100", messages are pushed to Url + "/loki/api/v1/push"
+	Url string
+
+	// static labels of streams, eg: {"job": "api", "host": "web-1"}
+	Labels map[string]string
+
+	// add label "level" of level string (lower case), eg: level="error"
+	LevelLabel bool
+
+	// fields added as labels, keep them low cardinality, eg: ["tenant"]
+	FieldLabels []string
+
+	// formatter of lines, JsonFormat and Format are ignored if it's set
+	Formatter Formatter
+
+	// is json format
+	JsonFormat bool
+
+	// jsonFormat is false, please input format string
+	// default "%millisecond_format% [%level_string%] %body%"
+	Format string
+
+	// loki tenant of multi-tenancy, header X-Scope-OrgID
+	TenantId string
+
+	// max messages of one push request, default 500
+	BatchSize int
+
+	// buffered messages are pushed every FlushInterval, default 5s
+	FlushInterval time.Duration
+
+	// push is retried on network errors, 429 and 5xx responses, default 3, -1 is no retry
+	MaxRetries int
+
+	// backoff of the first retry, doubled every retry up to 30s, default 500ms
+	RetryInterval time.Duration
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// basic auth
+	Username string
+	Password string
+
+	// request headers, eg: {"Authorization": "Bearer token"}
+	Headers map[string]string
+
+	// tls config of https Url
+	TLSConfig *tls.Config
+}
+
+func (lc *LokiConfig) Name() string {
+	return LOKI_ADAPTER_NAME
+}
+
+func NewAdapterLoki() LoggerAbstract {
+	return &AdapterLoki{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterLoki *AdapterLoki) Init(lokiConfig Config) error {
+	lc, ok := lokiConfig.(*LokiConfig)
+	if !ok || lc == nil {
+		return configTypeError(LOKI_ADAPTER_NAME, "LokiConfig")
+	}
+	adapterLoki.config = lc
+
+	if lc.Url == "" {
+		return configError(LOKI_ADAPTER_NAME, "Url", "cannot be empty!")
+	}
+	if len(lc.Labels) == 0 && !lc.LevelLabel && len(lc.FieldLabels) == 0 {
+		return configError(LOKI_ADAPTER_NAME, "Labels", "cannot be empty!")
+	}
+	if lc.JsonFormat == false && lc.Format == "" {
+		lc.Format = defaultLoggerMessageFormat
+	}
+	if err := checkFormatPlaceholders(lc.Format, nil); err != nil {
+		return err
+	}
+	if lc.BatchSize <= 0 {
+		lc.BatchSize = LOKI_DEFAULT_BATCH_SIZE
+	}
+	if lc.FlushInterval <= 0 {
+		lc.FlushInterval = LOKI_DEFAULT_FLUSH_INTERVAL
+	}
+	if lc.MaxRetries == 0 {
+		lc.MaxRetries = LOKI_DEFAULT_MAX_RETRIES
+	}
+	if lc.RetryInterval <= 0 {
+		lc.RetryInterval = LOKI_DEFAULT_RETRY_INTERVAL
+	}
+	if lc.Timeout <= 0 {
+		lc.Timeout = LOKI_DEFAULT_TIMEOUT
+	}
+	lc.Url = strings.TrimRight(lc.Url, "/")
+
+	adapterLoki.client = &http.Client{
+		Timeout: lc.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: lc.TLSConfig,
+		},
+	}
+
+	adapterLoki.ticker = time.NewTicker(lc.FlushInterval)
+	adapterLoki.quit = make(chan struct{})
+	go adapterLoki.startFlush(adapterLoki.ticker, adapterLoki.quit)
+
+	return nil
+}
+
+func (adapterLoki *AdapterLoki) Write(loggerMsg *loggerMessage) error {
+	adapterLoki.lock.Lock()
+	adapterLoki.buffer = append(adapterLoki.buffer, loggerMsg)
+	if len(adapterLoki.buffer) < adapterLoki.config.BatchSize {
+		adapterLoki.lock.Unlock()
+		return nil
+	}
+	buffer := adapterLoki.buffer
+	adapterLoki.buffer = []*loggerMessage{}
+	adapterLoki.lock.Unlock()
+
+	return adapterLoki.push(buffer)
+}
+
+func (adapterLoki *AdapterLoki) Flush() {
+	adapterLoki.lock.Lock()
+	buffer := adapterLoki.buffer
+	adapterLoki.buffer = []*loggerMessage{}
+	adapterLoki.lock.Unlock()
+
+	err := adapterLoki.push(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: loki adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker and push buffered messages
+func (adapterLoki *AdapterLoki) Close() error {
+	if adapterLoki.ticker != nil {
+		adapterLoki.ticker.Stop()
+		close(adapterLoki.quit)
+		adapterLoki.ticker = nil
+	}
+	adapterLoki.Flush()
+	return nil
+}
+
+func (adapterLoki *AdapterLoki) Name() string {
+	return LOKI_ADAPTER_NAME
+}
+
+// Counters of json fallbacks
+func (adapterLoki *AdapterLoki) Counters() map[string]int64 {
+	return map[string]int64{COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterLoki.encodingErrors)}
+}
+
+func (adapterLoki *AdapterLoki) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// GET request of Url + "/ready"
+func (adapterLoki *AdapterLoki) HealthCheck() error {
+	return healthCheckHttp(adapterLoki.client, "GET", adapterLoki.config.Url+LOKI_READY_PATH, func(req *http.Request) {
+		for key, value := range adapterLoki.config.Headers {
+			req.Header.Set(key, value)
+		}
+		if adapterLoki.config.TenantId != "" {
+			req.Header.Set("X-Scope-OrgID", adapterLoki.config.TenantId)
+		}
+	})
+}
+
+// flush buffer every FlushInterval
+func (adapterLoki *AdapterLoki) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterLoki.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// stream and lines of loki push api
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// push messages grouped into streams by labels, retry with backoff
+func (adapterLoki *AdapterLoki) push(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	streams := []*lokiStream{}
+	streamIndex := map[string]*lokiStream{}
+	for _, loggerMsg := range loggerMsgs {
+		labels := adapterLoki.labels(loggerMsg)
+		key := lokiStreamKey(labels)
+		stream, ok := streamIndex[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streamIndex[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(loggerMsg.Millisecond*int64(time.Millisecond), 10),
+			adapterLoki.line(loggerMsg),
+		})
+	}
+	body, err := json.Marshal(map[string][]*lokiStream{"streams": streams})
+	if err != nil {
+		return err
+	}
+
+	backoff := adapterLoki.config.RetryInterval
+	for retry := 0; ; retry++ {
+		var retryable bool
+		retryable, err = adapterLoki.send(body)
+		if err == nil || !retryable || retry >= adapterLoki.config.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > LOKI_DEFAULT_MAX_RETRY_BACKOFF {
+			backoff = LOKI_DEFAULT_MAX_RETRY_BACKOFF
+		}
+	}
+}
+
+// send push request, network errors, 429 and 5xx responses are retryable
+func (adapterLoki *AdapterLoki) send(body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", adapterLoki.config.Url+LOKI_PUSH_PATH, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range adapterLoki.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if adapterLoki.config.TenantId != "" {
+		req.Header.Set("X-Scope-OrgID", adapterLoki.config.TenantId)
+	}
+	if adapterLoki.config.Username != "" {
+		req.SetBasicAuth(adapterLoki.config.Username, adapterLoki.config.Password)
+	}
+
+	resp, err := adapterLoki.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5
+		return retryable, fmt.Errorf("loki push request failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+	return false, nil
+}
+
+// labels of the message stream
+func (adapterLoki *AdapterLoki) labels(loggerMsg *loggerMessage) map[string]string {
+	labels := make(map[string]string, len(adapterLoki.config.Labels)+len(adapterLoki.config.FieldLabels)+1)
+	for key, value := range adapterLoki.config.Labels {
+		labels[key] = value
+	}
+	if adapterLoki.config.LevelLabel {
+		labels["level"] = strings.ToLower(loggerMsg.LevelString)
+	}
+	for _, field := range adapterLoki.config.FieldLabels {
+		value := loggerMessageField(loggerMsg.Fields, field)
+		if value != "" {
+			labels[field] = value
+		}
+	}
+	return labels
+}
+
+// line of the message
+func (adapterLoki *AdapterLoki) line(loggerMsg *loggerMessage) string {
+	if adapterLoki.config.Formatter != nil {
+		return formatterFormat(adapterLoki.config.Formatter, loggerMsg)
+	}
+	if adapterLoki.config.JsonFormat == true {
+		jsonByte := marshalLoggerMessage(loggerMsg, &adapterLoki.encodingErrors)
+		return string(jsonByte)
+	}
+	return loggerMessageFormat(adapterLoki.config.Format, loggerMsg)
+}
+
+// labels sorted as key of the stream
+func lokiStreamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+strconv.Quote(labels[key]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func init() {
+	Register(LOKI_ADAPTER_NAME, NewAdapterLoki)
+	RegisterConfig(LOKI_ADAPTER_NAME, func() Config {
+		return &LokiConfig{}
+	})
+}
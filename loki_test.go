@@ -0,0 +1,101 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdapterLoki_Write(t *testing.T) {
+
+	requests := make(chan []byte, 10)
+	var failures int32 = 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != LOKI_PUSH_PATH || r.Header.Get("X-Scope-OrgID") != "team-a" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// first push fails, it's retried
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	lokiAdapter := NewAdapterLoki()
+	err := lokiAdapter.Init(&LokiConfig{
+		Url:           server.URL,
+		Labels:        map[string]string{"job": "api"},
+		LevelLabel:    true,
+		TenantId:      "team-a",
+		Format:        "%body%",
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		RetryInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	msgTime := time.Unix(1715328000, 0)
+	lokiAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_ERROR, "error 1", nil))
+	lokiAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_INFO, "info", nil))
+	if len(requests) != 0 {
+		t.Fatal("loki adapter pushed before batch is full")
+	}
+	err = lokiAdapter.Write(newLoggerMessage(msgTime, LOGGER_LEVEL_ERROR, "error 2", nil))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	push := struct {
+		Streams []lokiStream `json:"streams"`
+	}{}
+	err = json.Unmarshal(<-requests, &push)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(push.Streams) != 2 {
+		t.Fatalf("loki streams error: %+v", push.Streams)
+	}
+	errorStream := push.Streams[0]
+	if errorStream.Stream["job"] != "api" || errorStream.Stream["level"] != "error" || len(errorStream.Values) != 2 {
+		t.Errorf("loki stream error: %+v", errorStream)
+	}
+	if errorStream.Values[0] != [2]string{"1715328000000000000", "error 1"} || errorStream.Values[1][1] != "error 2" {
+		t.Errorf("loki values error: %v", errorStream.Values)
+	}
+	lokiAdapter.(LoggerCloser).Close()
+}
+
+func TestAdapterLoki_NoRetry(t *testing.T) {
+
+	var pushes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pushes, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	lokiAdapter := NewAdapterLoki()
+	lokiAdapter.Init(&LokiConfig{
+		Url:           server.URL,
+		Labels:        map[string]string{"job": "api"},
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		RetryInterval: time.Millisecond,
+	})
+	err := lokiAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "rejected", nil))
+	if err == nil || atomic.LoadInt32(&pushes) != 1 {
+		t.Errorf("4xx push must not be retried, pushes: %d", pushes)
+	}
+	lokiAdapter.(LoggerCloser).Close()
+}
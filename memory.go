@@ -0,0 +1,284 @@
+package go_logger
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const MEMORY_ADAPTER_NAME = "memory"
+
+const MEMORY_DEFAULT_SIZE = 1000
+
+// adapter memory, keep the last Size messages in a ring buffer
+type AdapterMemory struct {
+	lock    sync.RWMutex
+	config  *MemoryConfig
+	buffer  []*loggerMessage
+	next    int
+	full    bool
+	written int64 // written messages, positions of LogStore
+
+	followers map[*memoryFollower]bool // live tails of ServeHTTP
+}
+
+// memory config
+type MemoryConfig struct {
+
+	// max messages kept, default 1000
+	Size int
+}
+
+func (mc *MemoryConfig) Name() string {
+	return MEMORY_ADAPTER_NAME
+}
+
+func NewAdapterMemory() LoggerAbstract {
+	return &AdapterMemory{}
+}
+
+func (adapterMemory *AdapterMemory) Init(memoryConfig Config) error {
+	mc, ok := memoryConfig.(*MemoryConfig)
+	if !ok || mc == nil {
+		return configTypeError(MEMORY_ADAPTER_NAME, "MemoryConfig")
+	}
+	adapterMemory.config = mc
+
+	if mc.Size <= 0 {
+		mc.Size = MEMORY_DEFAULT_SIZE
+	}
+	adapterMemory.buffer = make([]*loggerMessage, mc.Size)
+	adapterMemory.next = 0
+	adapterMemory.full = false
+	adapterMemory.written = 0
+	return nil
+}
+
+func (adapterMemory *AdapterMemory) Write(loggerMsg *loggerMessage) error {
+	adapterMemory.lock.Lock()
+	defer adapterMemory.lock.Unlock()
+
+	adapterMemory.buffer[adapterMemory.next] = loggerMsg
+	for follower := range adapterMemory.followers {
+		follower.send(loggerMsg)
+	}
+	adapterMemory.written++
+	adapterMemory.next++
+	if adapterMemory.next == len(adapterMemory.buffer) {
+		adapterMemory.next = 0
+		adapterMemory.full = true
+	}
+	return nil
+}
+
+func (adapterMemory *AdapterMemory) Flush() {
+
+}
+
+func (adapterMemory *AdapterMemory) Name() string {
+	return MEMORY_ADAPTER_NAME
+}
+
+// kept messages, oldest first
+func (adapterMemory *AdapterMemory) messages() []*loggerMessage {
+	loggerMsgs, _ := adapterMemory.positionedMessages()
+	return loggerMsgs
+}
+
+// kept messages oldest first, and the position of the oldest
+func (adapterMemory *AdapterMemory) positionedMessages() ([]*loggerMessage, int64) {
+	adapterMemory.lock.RLock()
+	defer adapterMemory.lock.RUnlock()
+
+	return adapterMemory.keptMessages()
+}
+
+// kept messages oldest first, and the position of the oldest, call it after lock
+func (adapterMemory *AdapterMemory) keptMessages() ([]*loggerMessage, int64) {
+	if !adapterMemory.full {
+		return append([]*loggerMessage{}, adapterMemory.buffer[:adapterMemory.next]...), adapterMemory.written - int64(adapterMemory.next)
+	}
+	loggerMsgs := make([]*loggerMessage, 0, len(adapterMemory.buffer))
+	loggerMsgs = append(loggerMsgs, adapterMemory.buffer[adapterMemory.next:]...)
+	loggerMsgs = append(loggerMsgs, adapterMemory.buffer[:adapterMemory.next]...)
+	return loggerMsgs, adapterMemory.written - int64(len(loggerMsgs))
+}
+
+// page of kept entries, see LogStore
+// pages are stable while messages are written, entries removed from the buffer are skipped
+func (adapterMemory *AdapterMemory) Query(query *LogQuery) (*LogPage, error) {
+	loggerMsgs, position := adapterMemory.positionedMessages()
+	storedEntries := make([]storedEntry, len(loggerMsgs))
+	for i, loggerMsg := range loggerMsgs {
+		storedEntries[i] = storedEntry{position: position + int64(i), entry: loggerMsg.Entry()}
+	}
+	return queryStoredEntries(storedEntries, query)
+}
+
+// kept entries, oldest first
+func (adapterMemory *AdapterMemory) Entries() []LogEntry {
+	loggerMsgs := adapterMemory.messages()
+	entries := make([]LogEntry, len(loggerMsgs))
+	for i, loggerMsg := range loggerMsgs {
+		entries[i] = loggerMsg.Entry()
+	}
+	return entries
+}
+
+// remove all kept messages
+func (adapterMemory *AdapterMemory) Reset() {
+	adapterMemory.lock.Lock()
+	defer adapterMemory.lock.Unlock()
+
+	adapterMemory.buffer = make([]*loggerMessage, len(adapterMemory.buffer))
+	adapterMemory.next = 0
+	adapterMemory.full = false
+}
+
+// serve recent messages, newest first
+// live tail by server-sent events if follow is set or the request accepts text/event-stream, see serveEvents()
+//
+// query params:
+//
+//	level  only messages at or above the level, eg: "error" or "3"
+//	limit  max messages, default 100
+//	format "json" or "html", default html if the request accepts text/html
+//	follow "true" streams recent messages oldest first, then new messages
+//
+// example:
+//
+//	http.Handle("/debug/logs", logger.Adapter("memory").(*go_logger.AdapterMemory))
+func (adapterMemory *AdapterMemory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	level := LOGGER_LEVEL_DEBUG
+	if levelStr := query.Get("level"); levelStr != "" {
+		level = parseLevel(levelStr)
+	}
+	limit := 100
+	if limitStr := query.Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	if query.Get("follow") == "true" || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		adapterMemory.serveEvents(w, r, level, limit)
+		return
+	}
+
+	loggerMsgs := adapterMemory.messages()
+	matched := []*loggerMessage{}
+	for i := len(loggerMsgs) - 1; i >= 0 && len(matched) < limit; i-- {
+		if loggerMsgs[i].Level <= level {
+			matched = append(matched, loggerMsgs[i])
+		}
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+		if strings.Contains(r.Header.Get("Accept"), "text/html") {
+			format = "html"
+		}
+	}
+	if format == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		memoryHtmlPage().Execute(w, map[string]interface{}{
+			"Level":    level,
+			"Limit":    limit,
+			"Levels":   memoryHtmlLevels,
+			"Messages": matched,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("["))
+	for i, loggerMsg := range matched {
+		if i > 0 {
+			w.Write([]byte(","))
+		}
+		w.Write(marshalLoggerMessage(loggerMsg, nil))
+	}
+	w.Write([]byte("]"))
+}
+
+// level by name or number
+func parseLevel(levelStr string) int {
+	level, err := strconv.Atoi(levelStr)
+	if err == nil && levelNames()[level] != "" {
+		return level
+	}
+	return levelByName(levelStr)
+}
+
+type memoryHtmlLevel struct {
+	Level int
+	Name  string
+}
+
+var memoryHtmlLevels = []memoryHtmlLevel{
+	{LOGGER_LEVEL_EMERGENCY, "Emergency"},
+	{LOGGER_LEVEL_ALERT, "Alert"},
+	{LOGGER_LEVEL_CRITICAL, "Critical"},
+	{LOGGER_LEVEL_ERROR, "Error"},
+	{LOGGER_LEVEL_WARNING, "Warning"},
+	{LOGGER_LEVEL_NOTICE, "Notice"},
+	{LOGGER_LEVEL_INFO, "Info"},
+	{LOGGER_LEVEL_DEBUG, "Debug"},
+}
+
+// html page template, parsed on the first request
+var memoryHtmlTemplate = struct {
+	once     sync.Once
+	template *template.Template
+}{}
+
+func memoryHtmlPage() *template.Template {
+	memoryHtmlTemplate.once.Do(func() {
+		memoryHtmlTemplate.template = template.Must(template.New("memory").Parse(memoryHtmlSource))
+	})
+	return memoryHtmlTemplate.template
+}
+
+const memoryHtmlSource = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="2">
+<title>logs</title>
+<style>
+body { font-family: monospace; font-size: 13px; margin: 16px; }
+nav a { margin-right: 8px; }
+nav a.active { font-weight: bold; }
+table { border-collapse: collapse; width: 100%; margin-top: 12px; }
+td { border-bottom: 1px solid #eee; padding: 2px 6px; vertical-align: top; white-space: pre-wrap; }
+.level-0, .level-1, .level-2 { color: #b00; font-weight: bold; }
+.level-3 { color: #d00; }
+.level-4 { color: #c80; }
+.level-5 { color: #080; }
+.level-6 { color: #06c; }
+.level-7 { color: #888; }
+</style>
+</head>
+<body>
+<nav>{{range .Levels}}<a href="?format=html&level={{.Level}}&limit={{$.Limit}}"{{if eq .Level $.Level}} class="active"{{end}}>{{.Name}}</a>{{end}}</nav>
+<table>
+{{range .Messages}}<tr class="level-{{.Level}}"><td>{{.MillisecondFormat}}</td><td>{{.LevelString}}</td><td>{{.File}}:{{.Line}}</td><td>{{.Body}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+func init() {
+	Register(MEMORY_ADAPTER_NAME, NewAdapterMemory)
+	RegisterConfig(MEMORY_ADAPTER_NAME, func() Config {
+		return &MemoryConfig{}
+	})
+}
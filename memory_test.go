@@ -0,0 +1,136 @@
+package go_logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdapterMemory_Write(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{Size: 3})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	logger.Info("1")
+	logger.Info("2")
+	if entries := memoryAdapter.Entries(); len(entries) != 2 || entries[0].Body != "1" {
+		t.Fatal("memory adapter entries error")
+	}
+
+	logger.Error("3")
+	logger.Debug("4")
+	entries := memoryAdapter.Entries()
+	if len(entries) != 3 || entries[0].Body != "2" || entries[2].Body != "4" {
+		t.Errorf("memory adapter ring buffer error: %v", entries)
+	}
+
+	memoryAdapter.Reset()
+	if len(memoryAdapter.Entries()) != 0 {
+		t.Error("memory adapter reset error")
+	}
+}
+
+func TestAdapterMemory_ServeHTTP(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	logger.Info("memory info")
+	logger.Error("memory error <script>")
+	logger.Critical("memory critical")
+
+	w := httptest.NewRecorder()
+	memoryAdapter.ServeHTTP(w, httptest.NewRequest("GET", "/debug/logs?level=error&limit=1", nil))
+	messages := []map[string]interface{}{}
+	err := json.Unmarshal(w.Body.Bytes(), &messages)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(messages) != 1 || messages[0]["body"] != "memory critical" {
+		t.Errorf("memory handler json error: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/debug/logs?level=error", nil)
+	r.Header.Set("Accept", "text/html")
+	memoryAdapter.ServeHTTP(w, r)
+	html := w.Body.String()
+	if !strings.Contains(html, "memory error &lt;script&gt;") || strings.Contains(html, "memory info") {
+		t.Error("memory handler html error: " + html)
+	}
+
+	w = httptest.NewRecorder()
+	memoryAdapter.ServeHTTP(w, httptest.NewRequest("GET", "/debug/logs?limit=x", nil))
+	if w.Code != 400 {
+		t.Error("memory handler limit error")
+	}
+}
+
+func TestAdapterMemory_ServeEvents(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+	logger.Error("recent error 1")
+	logger.Error("recent error 2")
+	logger.Flush()
+
+	server := httptest.NewServer(memoryAdapter)
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, _ := http.NewRequest("GET", server.URL+"/debug/logs?level=error&limit=1&follow=true", nil)
+	resp, err := http.DefaultClient.Do(r.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("event stream content type error: %s", resp.Header.Get("Content-Type"))
+	}
+
+	events := bufio.NewReader(resp.Body)
+	readBody := func() string {
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			if strings.HasPrefix(line, "data: ") {
+				message := map[string]interface{}{}
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &message)
+				return message["body"].(string)
+			}
+		}
+	}
+	if body := readBody(); body != "recent error 2" {
+		t.Errorf("recent event error: %s", body)
+	}
+	logger.Info("live info")
+	logger.Critical("live critical")
+	if body := readBody(); body != "live critical" {
+		t.Errorf("live event error: %s", body)
+	}
+
+	cancel()
+	for i := 0; i < 100; i++ {
+		memoryAdapter.lock.RLock()
+		followers := len(memoryAdapter.followers)
+		memoryAdapter.lock.RUnlock()
+		if followers == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("disconnected client must unfollow")
+}
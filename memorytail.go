@@ -0,0 +1,109 @@
+package go_logger
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// queued messages of a live tail, messages are dropped while the client is slower
+const MEMORY_FOLLOW_QUEUE = 256
+
+// comment sent to idle live tails, proxies keep the connection open
+const MEMORY_FOLLOW_HEARTBEAT = 15 * time.Second
+
+// live tail of ServeHTTP
+type memoryFollower struct {
+	dropped    int64 // first for 64-bit atomic alignment on 32-bit platforms
+	loggerMsgs chan *loggerMessage
+}
+
+// queue the message, dropped if the queue is full
+func (follower *memoryFollower) send(loggerMsg *loggerMessage) {
+	select {
+	case follower.loggerMsgs <- loggerMsg:
+	default:
+		atomic.AddInt64(&follower.dropped, 1)
+	}
+}
+
+// follow written messages, kept messages before them are returned oldest first
+func (adapterMemory *AdapterMemory) follow() (*memoryFollower, []*loggerMessage) {
+	follower := &memoryFollower{loggerMsgs: make(chan *loggerMessage, MEMORY_FOLLOW_QUEUE)}
+	adapterMemory.lock.Lock()
+	if adapterMemory.followers == nil {
+		adapterMemory.followers = map[*memoryFollower]bool{}
+	}
+	adapterMemory.followers[follower] = true
+	loggerMsgs, _ := adapterMemory.keptMessages()
+	adapterMemory.lock.Unlock()
+	return follower, loggerMsgs
+}
+
+func (adapterMemory *AdapterMemory) unfollow(follower *memoryFollower) {
+	adapterMemory.lock.Lock()
+	defer adapterMemory.lock.Unlock()
+
+	delete(adapterMemory.followers, follower)
+}
+
+// stream messages at or above level as server-sent events until the client disconnects
+// the last limit recent messages are sent first, every event is a json record:
+//	data: {"timestamp":1521788107,...}
+// messages dropped for a slow client are counted by event "dropped":
+//	event: dropped
+//	data: 12
+//
+// example:
+//	curl -N "http://localhost:8080/debug/logs?level=error&follow=true"
+func (adapterMemory *AdapterMemory) serveEvents(w http.ResponseWriter, r *http.Request, level int, limit int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	follower, recent := adapterMemory.follow()
+	defer adapterMemory.unfollow(follower)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	matched := []*loggerMessage{}
+	for i := len(recent) - 1; i >= 0 && len(matched) < limit; i-- {
+		if recent[i].Level <= level {
+			matched = append(matched, recent[i])
+		}
+	}
+	for i := len(matched) - 1; i >= 0; i-- {
+		writeMemoryEvent(w, matched[i])
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(MEMORY_FOLLOW_HEARTBEAT)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			w.Write([]byte(": heartbeat\n\n"))
+		case loggerMsg := <-follower.loggerMsgs:
+			if dropped := atomic.SwapInt64(&follower.dropped, 0); dropped > 0 {
+				w.Write([]byte("event: dropped\ndata: " + strconv.FormatInt(dropped, 10) + "\n\n"))
+			}
+			if loggerMsg.Level <= level {
+				writeMemoryEvent(w, loggerMsg)
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeMemoryEvent(w http.ResponseWriter, loggerMsg *loggerMessage) {
+	w.Write([]byte("data: "))
+	w.Write(marshalLoggerMessage(loggerMsg, nil))
+	w.Write([]byte("\n\n"))
+}
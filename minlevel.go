@@ -0,0 +1,60 @@
+package go_logger
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// request header of the min level of the request, see MinLevelHandler()
+const MIN_LEVEL_HEADER = "X-Log-Level"
+
+type loggerMinLevelKey struct{}
+
+// context with the min level of XxxCtx messages, messages at or above it are written by every adapter
+// regardless of logger level, adapter level and sampling, eg: debug one request or tenant without global DEBUG
+//
+// example:
+//	ctx = go_logger.ContextWithMinLevel(ctx, go_logger.LOGGER_LEVEL_DEBUG)
+//	logger.DebugCtx(ctx, "cache miss") // written even if the logger level is info
+func ContextWithMinLevel(ctx context.Context, level int) context.Context {
+	return context.WithValue(ctx, loggerMinLevelKey{}, level)
+}
+
+// min level of the context, false if it's not set
+func MinLevelFromContext(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(loggerMinLevelKey{}).(int)
+	return level, ok
+}
+
+// the level is at or above the min level of the context
+func minLevelAccept(ctx context.Context, level int) bool {
+	minLevel, ok := MinLevelFromContext(ctx)
+	return ok && level <= minLevel
+}
+
+// http handler, requests with the header MIN_LEVEL_HEADER are logged at the level of it, eg: "X-Log-Level: debug"
+// allow checks the request may raise the level, eg: a token or an internal address, nil allows every request
+//
+// example:
+//	handler := logger.MinLevelHandler(mux, func(r *http.Request) bool {
+//		return r.Header.Get("X-Debug-Token") == debugToken
+//	})
+func (logger *Logger) MinLevelHandler(next http.Handler, allow func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get(MIN_LEVEL_HEADER))
+		if header == "" || (allow != nil && !allow(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		level, ok := levelFromString(header)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithMinLevel(r.Context(), level)))
+	})
+}
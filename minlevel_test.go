@@ -0,0 +1,62 @@
+package go_logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextWithMinLevel(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_WARNING, &TestConfig{})
+	logger.SetLevel(LOGGER_LEVEL_INFO)
+	recorded := logger.Adapter("test").(*AdapterTest)
+
+	ctx := ContextWithMinLevel(context.Background(), LOGGER_LEVEL_INFO)
+	logger.InfoCtx(ctx, "request info")
+	logger.DebugCtx(ctx, "request debug")
+	logger.InfoCtx(context.Background(), "other info")
+	logger.Info("global info")
+
+	entries := recorded.Entries()
+	if len(entries) != 1 || entries[0].Body != "request info" {
+		t.Errorf("context min level error: %v", entries)
+	}
+	if level, ok := MinLevelFromContext(ctx); !ok || level != LOGGER_LEVEL_INFO {
+		t.Errorf("min level of context error: %d", level)
+	}
+}
+
+func TestLogger_MinLevelHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_INFO, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+
+	handler := logger.MinLevelHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugCtx(r.Context(), "debug "+r.URL.Path)
+	}), func(r *http.Request) bool {
+		return r.Header.Get("X-Debug-Token") == "secret"
+	})
+
+	for path, header := range map[string][2]string{
+		"/allowed": {"debug", "secret"},
+		"/denied":  {"debug", "guess"},
+		"/illegal": {"verbose", "secret"},
+		"/none":    {"", "secret"},
+	} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set(MIN_LEVEL_HEADER, header[0])
+		r.Header.Set("X-Debug-Token", header[1])
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries := recorded.Entries()
+	if len(entries) != 1 || entries[0].Body != "debug /allowed" {
+		t.Errorf("min level handler error: %v", entries)
+	}
+}
@@ -0,0 +1,514 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const MQTT_ADAPTER_NAME = "mqtt"
+
+// qos of mqtt publish
+const (
+	MQTT_QOS_AT_MOST_ONCE  = 0
+	MQTT_QOS_AT_LEAST_ONCE = 1
+	MQTT_QOS_EXACTLY_ONCE  = 2
+)
+
+const (
+	MQTT_DEFAULT_ADDRESS        = "127.0.0.1:1883"
+	MQTT_DEFAULT_TOPIC          = "go-logger"
+	MQTT_DEFAULT_KEEP_ALIVE     = time.Minute
+	MQTT_DEFAULT_BATCH_SIZE     = 100
+	MQTT_DEFAULT_FLUSH_INTERVAL = time.Second
+	MQTT_DEFAULT_TIMEOUT        = 3 * time.Second
+	MQTT_DEFAULT_MAX_RETRIES    = 2
+)
+
+// mqtt 3.1.1 packet types
+const (
+	mqttConnect  = 1
+	mqttConnack  = 2
+	mqttPublish  = 3
+	mqttPuback   = 4
+	mqttPubrec   = 5
+	mqttPubrel   = 6
+	mqttPubcomp  = 7
+	mqttPingreq  = 12
+	mqttPingresp = 13
+)
+
+// adapter mqtt 3.1.1, json records are published to a topic with QoS 0, 1 or 2
+// publishes of QoS 1 and 2 are acknowledged by the broker before Write() or Flush() returns
+type AdapterMqtt struct {
+	encodingErrors int64
+	lock           sync.Mutex
+	connLock       sync.Mutex
+	config         *MqttConfig
+	conn           net.Conn
+	reader         *bufio.Reader
+	packetId       uint16
+	lastWrite      time.Time
+	buffer         []*loggerMessage
+	ticker         *time.Ticker
+	quit           chan struct{}
+}
+
+// mqtt config
+type MqttConfig struct {
+
+	// address of the broker, default "127.0.0.1:1883"
+	Address string
+
+	// topic of records, placeholders of Format are replaced, default "go-logger"
+	// example: "devices/edge-1/logs/%level_string%"
+	Topic string
+
+	// MQTT_QOS_AT_MOST_ONCE (default), MQTT_QOS_AT_LEAST_ONCE or MQTT_QOS_EXACTLY_ONCE
+	QoS int
+
+	// retained records, the broker keeps the last record of the topic
+	Retain bool
+
+	// client id, default "go-logger-" and random hex
+	ClientId string
+
+	Username string
+	Password string
+
+	// keep alive of the connection, idle connections are pinged, default 1m
+	KeepAlive time.Duration
+
+	// max records of one batch, default 100
+	BatchSize int
+
+	// buffered records are published every FlushInterval, default 1s
+	FlushInterval time.Duration
+
+	// dial, write and ack timeout, default 3s
+	Timeout time.Duration
+
+	// publish is retried with a new connection, default 2, -1 is no retry
+	// records of QoS 1 and 2 may be published twice if the connection is broken before they're acknowledged
+	MaxRetries int
+
+	// connect by tls if it's not nil
+	TLSConfig *tls.Config
+}
+
+func (mc *MqttConfig) Name() string {
+	return MQTT_ADAPTER_NAME
+}
+
+func NewAdapterMqtt() LoggerAbstract {
+	return &AdapterMqtt{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterMqtt *AdapterMqtt) Init(mqttConfig Config) error {
+	mc, ok := mqttConfig.(*MqttConfig)
+	if !ok || mc == nil {
+		return configTypeError(MQTT_ADAPTER_NAME, "MqttConfig")
+	}
+	adapterMqtt.config = mc
+
+	if mc.Address == "" {
+		mc.Address = MQTT_DEFAULT_ADDRESS
+	}
+	if mc.Topic == "" {
+		mc.Topic = MQTT_DEFAULT_TOPIC
+	}
+	if mc.QoS < MQTT_QOS_AT_MOST_ONCE || mc.QoS > MQTT_QOS_EXACTLY_ONCE {
+		return configError(MQTT_ADAPTER_NAME, "QoS", "must be 0, 1 or 2!")
+	}
+	if mc.ClientId == "" {
+		id := make([]byte, 8)
+		rand.Read(id)
+		mc.ClientId = "go-logger-" + hex.EncodeToString(id)
+	}
+	if mc.KeepAlive <= 0 {
+		mc.KeepAlive = MQTT_DEFAULT_KEEP_ALIVE
+	}
+	if mc.BatchSize <= 0 {
+		mc.BatchSize = MQTT_DEFAULT_BATCH_SIZE
+	}
+	if mc.FlushInterval <= 0 {
+		mc.FlushInterval = MQTT_DEFAULT_FLUSH_INTERVAL
+	}
+	if mc.Timeout <= 0 {
+		mc.Timeout = MQTT_DEFAULT_TIMEOUT
+	}
+	if mc.MaxRetries == 0 {
+		mc.MaxRetries = MQTT_DEFAULT_MAX_RETRIES
+	}
+
+	adapterMqtt.ticker = time.NewTicker(mc.FlushInterval)
+	adapterMqtt.quit = make(chan struct{})
+	go adapterMqtt.startFlush(adapterMqtt.ticker, adapterMqtt.quit)
+
+	return nil
+}
+
+func (adapterMqtt *AdapterMqtt) Write(loggerMsg *loggerMessage) error {
+	adapterMqtt.lock.Lock()
+	adapterMqtt.buffer = append(adapterMqtt.buffer, loggerMsg)
+	if len(adapterMqtt.buffer) < adapterMqtt.config.BatchSize {
+		adapterMqtt.lock.Unlock()
+		return nil
+	}
+	buffer := adapterMqtt.buffer
+	adapterMqtt.buffer = []*loggerMessage{}
+	adapterMqtt.lock.Unlock()
+
+	return adapterMqtt.publish(buffer)
+}
+
+// publish buffered records, idle connections are pinged
+func (adapterMqtt *AdapterMqtt) Flush() {
+	adapterMqtt.lock.Lock()
+	buffer := adapterMqtt.buffer
+	adapterMqtt.buffer = []*loggerMessage{}
+	adapterMqtt.lock.Unlock()
+
+	err := adapterMqtt.publish(buffer)
+	if err == nil && len(buffer) == 0 {
+		err = adapterMqtt.keepAlive()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: mqtt adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker, publish buffered records and disconnect
+func (adapterMqtt *AdapterMqtt) Close() error {
+	if adapterMqtt.ticker != nil {
+		adapterMqtt.ticker.Stop()
+		close(adapterMqtt.quit)
+		adapterMqtt.ticker = nil
+	}
+	adapterMqtt.Flush()
+
+	adapterMqtt.connLock.Lock()
+	defer adapterMqtt.connLock.Unlock()
+	if adapterMqtt.conn != nil {
+		// DISCONNECT, the broker doesn't publish the will
+		adapterMqtt.conn.SetWriteDeadline(time.Now().Add(adapterMqtt.config.Timeout))
+		adapterMqtt.conn.Write([]byte{0xe0, 0})
+	}
+	adapterMqtt.disconnect()
+	return nil
+}
+
+func (adapterMqtt *AdapterMqtt) Name() string {
+	return MQTT_ADAPTER_NAME
+}
+
+func (adapterMqtt *AdapterMqtt) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// connect to the broker if it's not connected
+func (adapterMqtt *AdapterMqtt) HealthCheck() error {
+	adapterMqtt.connLock.Lock()
+	defer adapterMqtt.connLock.Unlock()
+
+	return adapterMqtt.connect()
+}
+
+func (adapterMqtt *AdapterMqtt) Counters() map[string]int64 {
+	return map[string]int64{
+		COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterMqtt.encodingErrors),
+	}
+}
+
+// flush buffer every FlushInterval
+func (adapterMqtt *AdapterMqtt) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterMqtt.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (adapterMqtt *AdapterMqtt) publish(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	topics := make([]string, len(loggerMsgs))
+	records := make([][]byte, len(loggerMsgs))
+	for i, loggerMsg := range loggerMsgs {
+		topics[i] = loggerMessageFormat(adapterMqtt.config.Topic, loggerMsg)
+		records[i] = marshalLoggerMessage(loggerMsg, &adapterMqtt.encodingErrors)
+	}
+
+	adapterMqtt.connLock.Lock()
+	defer adapterMqtt.connLock.Unlock()
+
+	err := adapterMqtt.send(topics, records)
+	for retry := 0; err != nil && retry < adapterMqtt.config.MaxRetries; retry++ {
+		err = adapterMqtt.send(topics, records)
+	}
+	return err
+}
+
+// write PUBLISH of records, wait for acks of QoS 1 and 2, the connection is closed on errors
+func (adapterMqtt *AdapterMqtt) send(topics []string, records [][]byte) error {
+	err := adapterMqtt.connect()
+	if err != nil {
+		return err
+	}
+	config := adapterMqtt.config
+
+	flags := byte(config.QoS << 1)
+	if config.Retain {
+		flags |= 1
+	}
+	buf := []byte{}
+	ids := make([]uint16, 0, len(records))
+	for i, record := range records {
+		body := appendMqttString(nil, topics[i])
+		if config.QoS > MQTT_QOS_AT_MOST_ONCE {
+			id := adapterMqtt.nextPacketId()
+			ids = append(ids, id)
+			body = append(body, byte(id>>8), byte(id))
+		}
+		body = append(body, record...)
+		buf = appendMqttPacket(buf, mqttPublish<<4|flags, body)
+	}
+	err = adapterMqtt.writePackets(buf)
+	if err != nil {
+		return err
+	}
+
+	ackType := byte(mqttPuback)
+	if config.QoS == MQTT_QOS_EXACTLY_ONCE {
+		ackType = mqttPubrec
+	}
+	err = adapterMqtt.readAcks(ackType, ids)
+	if err != nil || config.QoS != MQTT_QOS_EXACTLY_ONCE {
+		return err
+	}
+
+	// PUBREL of every PUBREC, then PUBCOMP of every PUBREL
+	buf = buf[:0]
+	for _, id := range ids {
+		buf = appendMqttPacket(buf, mqttPubrel<<4|2, []byte{byte(id >> 8), byte(id)})
+	}
+	err = adapterMqtt.writePackets(buf)
+	if err != nil {
+		return err
+	}
+	return adapterMqtt.readAcks(mqttPubcomp, ids)
+}
+
+// read acks of the packet ids, PINGRESP is skipped
+func (adapterMqtt *AdapterMqtt) readAcks(ackType byte, ids []uint16) error {
+	pending := make(map[uint16]bool, len(ids))
+	for _, id := range ids {
+		pending[id] = true
+	}
+	adapterMqtt.conn.SetReadDeadline(time.Now().Add(adapterMqtt.config.Timeout))
+	for len(pending) > 0 {
+		header, body, err := readMqttPacket(adapterMqtt.reader)
+		if err != nil {
+			adapterMqtt.disconnect()
+			return err
+		}
+		if header>>4 == mqttPingresp {
+			continue
+		}
+		if header>>4 != ackType || len(body) < 2 {
+			adapterMqtt.disconnect()
+			return errors.New("mqtt ack packet type " + strconv.Itoa(int(header>>4)) + " is unexpected")
+		}
+		delete(pending, uint16(body[0])<<8|uint16(body[1]))
+	}
+	return nil
+}
+
+// ping the connection if it's idle for half of KeepAlive
+func (adapterMqtt *AdapterMqtt) keepAlive() error {
+	adapterMqtt.connLock.Lock()
+	defer adapterMqtt.connLock.Unlock()
+
+	if adapterMqtt.conn == nil || time.Since(adapterMqtt.lastWrite) < adapterMqtt.config.KeepAlive/2 {
+		return nil
+	}
+	err := adapterMqtt.writePackets([]byte{mqttPingreq << 4, 0})
+	if err != nil {
+		return err
+	}
+	adapterMqtt.conn.SetReadDeadline(time.Now().Add(adapterMqtt.config.Timeout))
+	header, _, err := readMqttPacket(adapterMqtt.reader)
+	if err == nil && header>>4 != mqttPingresp {
+		err = errors.New("mqtt PINGRESP packet type " + strconv.Itoa(int(header>>4)) + " is unexpected")
+	}
+	if err != nil {
+		adapterMqtt.disconnect()
+	}
+	return err
+}
+
+func (adapterMqtt *AdapterMqtt) writePackets(buf []byte) error {
+	adapterMqtt.conn.SetWriteDeadline(time.Now().Add(adapterMqtt.config.Timeout))
+	_, err := adapterMqtt.conn.Write(buf)
+	if err != nil {
+		adapterMqtt.disconnect()
+		return err
+	}
+	adapterMqtt.lastWrite = time.Now()
+	return nil
+}
+
+// packet id of QoS 1 and 2, 0 is not used
+func (adapterMqtt *AdapterMqtt) nextPacketId() uint16 {
+	adapterMqtt.packetId++
+	if adapterMqtt.packetId == 0 {
+		adapterMqtt.packetId = 1
+	}
+	return adapterMqtt.packetId
+}
+
+// dial, write CONNECT (clean session) and read CONNACK, call it after connLock
+func (adapterMqtt *AdapterMqtt) connect() error {
+	if adapterMqtt.conn != nil {
+		return nil
+	}
+	config := adapterMqtt.config
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	var conn net.Conn
+	var err error
+	if config.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", config.Address, config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", config.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	flags := byte(0x02)
+	body := appendMqttString(nil, "MQTT")
+	if config.Username != "" {
+		flags |= 0x80
+	}
+	if config.Password != "" {
+		flags |= 0x40
+	}
+	keepAlive := int(config.KeepAlive / time.Second)
+	if keepAlive > 0xffff {
+		keepAlive = 0xffff
+	}
+	body = append(body, 4, flags, byte(keepAlive>>8), byte(keepAlive))
+	body = appendMqttString(body, config.ClientId)
+	if config.Username != "" {
+		body = appendMqttString(body, config.Username)
+	}
+	if config.Password != "" {
+		body = appendMqttString(body, config.Password)
+	}
+
+	conn.SetDeadline(time.Now().Add(config.Timeout))
+	reader := bufio.NewReader(conn)
+	_, err = conn.Write(appendMqttPacket(nil, mqttConnect<<4, body))
+	if err == nil {
+		var header byte
+		header, body, err = readMqttPacket(reader)
+		if err == nil && (header>>4 != mqttConnack || len(body) < 2) {
+			err = errors.New("mqtt CONNACK is illegal")
+		}
+		if err == nil && body[1] != 0 {
+			err = errors.New("mqtt connection refused, return code " + strconv.Itoa(int(body[1])))
+		}
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	adapterMqtt.conn = conn
+	adapterMqtt.reader = reader
+	adapterMqtt.lastWrite = time.Now()
+	return nil
+}
+
+func (adapterMqtt *AdapterMqtt) disconnect() {
+	if adapterMqtt.conn != nil {
+		adapterMqtt.conn.Close()
+		adapterMqtt.conn = nil
+		adapterMqtt.reader = nil
+	}
+}
+
+// append the packet of fixed header, remaining length and body
+func appendMqttPacket(buf []byte, header byte, body []byte) []byte {
+	buf = append(buf, header)
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(buf, body...)
+}
+
+// append the length prefixed string
+func appendMqttString(buf []byte, s string) []byte {
+	buf = append(buf, byte(len(s)>>8), byte(len(s)))
+	return append(buf, s...)
+}
+
+// read fixed header and body of a packet
+func readMqttPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := 0
+	for multiplier := 1; ; multiplier *= 128 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		if multiplier > 128*128*128 {
+			return 0, nil, errors.New("mqtt remaining length is illegal")
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func init() {
+	Register(MQTT_ADAPTER_NAME, NewAdapterMqtt)
+	RegisterConfig(MQTT_ADAPTER_NAME, func() Config {
+		return &MqttConfig{}
+	})
+}
@@ -0,0 +1,130 @@
+package go_logger
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mqtt broker acking publishes by their QoS, packets are sent to the channel as "type body"
+func newTestMqttBroker(t *testing.T, returnCode byte) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	packets := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					header, body, err := readMqttPacket(reader)
+					if err != nil {
+						return
+					}
+					switch header >> 4 {
+					case mqttConnect:
+						packets <- "CONNECT " + string(body)
+						conn.Write([]byte{mqttConnack << 4, 2, 0, returnCode})
+					case mqttPublish:
+						qos := header >> 1 & 3
+						topicLen := int(body[0])<<8 | int(body[1])
+						topic := string(body[2 : 2+topicLen])
+						payload := body[2+topicLen:]
+						if qos > 0 {
+							id := payload[:2]
+							payload = payload[2:]
+							if qos == 1 {
+								conn.Write([]byte{mqttPuback << 4, 2, id[0], id[1]})
+							} else {
+								conn.Write([]byte{mqttPubrec << 4, 2, id[0], id[1]})
+							}
+						}
+						packets <- "PUBLISH " + topic + " " + string(rune('0'+qos)) + " " + string(payload)
+					case mqttPubrel:
+						packets <- "PUBREL"
+						conn.Write([]byte{mqttPubcomp << 4, 2, body[0], body[1]})
+					case mqttPingreq:
+						packets <- "PINGREQ"
+						conn.Write([]byte{mqttPingresp << 4, 0})
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), packets
+}
+
+func TestAdapterMqtt(t *testing.T) {
+
+	for _, qos := range []int{MQTT_QOS_AT_MOST_ONCE, MQTT_QOS_AT_LEAST_ONCE, MQTT_QOS_EXACTLY_ONCE} {
+		address, packets := newTestMqttBroker(t, 0)
+		adapterMqtt := NewAdapterMqtt()
+		err := adapterMqtt.Init(&MqttConfig{
+			Address:  address,
+			Topic:    "devices/edge-1/%level_string%",
+			QoS:      qos,
+			ClientId: "edge-1",
+			Username: "edge",
+			Password: "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		adapterMqtt.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "one", nil))
+		adapterMqtt.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "two", nil))
+		adapterMqtt.Flush()
+
+		if connect := <-packets; !strings.HasPrefix(connect, "CONNECT \x00\x04MQTT\x04\xc2") ||
+			!strings.HasSuffix(connect, "\x00\x06edge-1\x00\x04edge\x00\x06secret") {
+			t.Errorf("mqtt connect error: %q", connect)
+		}
+		for _, expect := range []string{"devices/edge-1/Info", "devices/edge-1/Error"} {
+			publish := <-packets
+			if !strings.HasPrefix(publish, "PUBLISH "+expect+" "+string(rune('0'+qos))+" {") {
+				t.Errorf("mqtt publish error: %q, expect %s qos %d", publish, expect, qos)
+			}
+		}
+		if qos == MQTT_QOS_EXACTLY_ONCE {
+			if pubrel := <-packets + <-packets; pubrel != "PUBRELPUBREL" {
+				t.Errorf("mqtt pubrel error: %q", pubrel)
+			}
+		}
+
+		// idle connections are pinged
+		adapterMqtt.(*AdapterMqtt).lastWrite = time.Now().Add(-time.Hour)
+		adapterMqtt.Flush()
+		if ping := <-packets; ping != "PINGREQ" {
+			t.Errorf("mqtt ping error: %q", ping)
+		}
+		adapterMqtt.(LoggerCloser).Close()
+	}
+}
+
+func TestAdapterMqtt_Refused(t *testing.T) {
+
+	address, _ := newTestMqttBroker(t, 5)
+	adapterMqtt := NewAdapterMqtt()
+	err := adapterMqtt.Init(&MqttConfig{Address: address, BatchSize: 1, MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapterMqtt.(LoggerCloser).Close()
+
+	err = adapterMqtt.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "one", nil))
+	if err == nil || err.Error() != "mqtt connection refused, return code 5" {
+		t.Errorf("mqtt refused error: %v", err)
+	}
+	if err := NewAdapterMqtt().Init(&MqttConfig{QoS: 3}); err == nil {
+		t.Error("mqtt qos must be error")
+	}
+}
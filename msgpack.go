@@ -0,0 +1,372 @@
+package go_logger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// msgpack ext type of fluentd EventTime
+const MSGPACK_EXT_EVENT_TIME = 0
+
+// append msgpack encoded value, unknown types are encoded by their json representation
+func appendMsgpack(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case string:
+		return appendMsgpackString(buf, v)
+	case []byte:
+		return appendMsgpackBinary(buf, v)
+	case int:
+		return appendMsgpackInt(buf, int64(v))
+	case int8:
+		return appendMsgpackInt(buf, int64(v))
+	case int16:
+		return appendMsgpackInt(buf, int64(v))
+	case int32:
+		return appendMsgpackInt(buf, int64(v))
+	case int64:
+		return appendMsgpackInt(buf, v)
+	case uint:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint8:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint16:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint32:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint64:
+		return appendMsgpackUint(buf, v)
+	case float32:
+		buf = append(buf, 0xca)
+		return appendUint32(buf, math.Float32bits(v))
+	case float64:
+		buf = append(buf, 0xcb)
+		return appendUint64(buf, math.Float64bits(v))
+	case time.Time:
+		return appendMsgpackString(buf, v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendMsgpackString(buf, v.String())
+	case error:
+		return appendMsgpackString(buf, v.Error())
+	case []interface{}:
+		buf = appendMsgpackArrayHeader(buf, len(v))
+		for _, item := range v {
+			buf = appendMsgpack(buf, item)
+		}
+		return buf
+	case []string:
+		buf = appendMsgpackArrayHeader(buf, len(v))
+		for _, item := range v {
+			buf = appendMsgpackString(buf, item)
+		}
+		return buf
+	case map[string]interface{}:
+		buf = appendMsgpackMapHeader(buf, len(v))
+		for _, key := range sortedFieldKeys(v) {
+			buf = appendMsgpackString(buf, key)
+			buf = appendMsgpack(buf, v[key])
+		}
+		return buf
+	case map[string]string:
+		buf = appendMsgpackMapHeader(buf, len(v))
+		for key, item := range v {
+			buf = appendMsgpackString(buf, key)
+			buf = appendMsgpackString(buf, item)
+		}
+		return buf
+	}
+
+	jsonByte, err := json.Marshal(value)
+	if err != nil {
+		return appendMsgpackString(buf, fmt.Sprint(value))
+	}
+	var jsonValue interface{}
+	json.Unmarshal(jsonByte, &jsonValue)
+	return appendMsgpack(buf, jsonValue)
+}
+
+func appendMsgpackString(buf []byte, s string) []byte {
+	switch {
+	case len(s) < 32:
+		buf = append(buf, 0xa0|byte(len(s)))
+	case len(s) <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(len(s)))
+	case len(s) <= math.MaxUint16:
+		buf = append(buf, 0xda)
+		buf = appendUint16(buf, uint16(len(s)))
+	default:
+		buf = append(buf, 0xdb)
+		buf = appendUint32(buf, uint32(len(s)))
+	}
+	return append(buf, s...)
+}
+
+func appendMsgpackBinary(buf []byte, b []byte) []byte {
+	switch {
+	case len(b) <= math.MaxUint8:
+		buf = append(buf, 0xc4, byte(len(b)))
+	case len(b) <= math.MaxUint16:
+		buf = append(buf, 0xc5)
+		buf = appendUint16(buf, uint16(len(b)))
+	default:
+		buf = append(buf, 0xc6)
+		buf = appendUint32(buf, uint32(len(b)))
+	}
+	return append(buf, b...)
+}
+
+func appendMsgpackInt(buf []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(buf, uint64(i))
+	case i >= -32:
+		return append(buf, byte(i))
+	case i >= math.MinInt8:
+		return append(buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		buf = append(buf, 0xd1)
+		return appendUint16(buf, uint16(i))
+	case i >= math.MinInt32:
+		buf = append(buf, 0xd2)
+		return appendUint32(buf, uint32(i))
+	}
+	buf = append(buf, 0xd3)
+	return appendUint64(buf, uint64(i))
+}
+
+func appendMsgpackUint(buf []byte, u uint64) []byte {
+	switch {
+	case u < 128:
+		return append(buf, byte(u))
+	case u <= math.MaxUint8:
+		return append(buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		buf = append(buf, 0xcd)
+		return appendUint16(buf, uint16(u))
+	case u <= math.MaxUint32:
+		buf = append(buf, 0xce)
+		return appendUint32(buf, uint32(u))
+	}
+	buf = append(buf, 0xcf)
+	return appendUint64(buf, u)
+}
+
+func appendMsgpackArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xdc)
+		return appendUint16(buf, uint16(n))
+	}
+	buf = append(buf, 0xdd)
+	return appendUint32(buf, uint32(n))
+}
+
+func appendMsgpackMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xde)
+		return appendUint16(buf, uint16(n))
+	}
+	buf = append(buf, 0xdf)
+	return appendUint32(buf, uint32(n))
+}
+
+// append fluentd EventTime, ext type 0 of seconds and nanoseconds
+func appendMsgpackEventTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xd7, MSGPACK_EXT_EVENT_TIME)
+	buf = appendUint32(buf, uint32(t.Unix()))
+	return appendUint32(buf, uint32(t.Nanosecond()))
+}
+
+// big endian integers
+func appendUint16(buf []byte, u uint16) []byte {
+	return append(buf, byte(u>>8), byte(u))
+}
+
+func appendUint32(buf []byte, u uint32) []byte {
+	return append(buf, byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+func appendUint64(buf []byte, u uint64) []byte {
+	return appendUint32(appendUint32(buf, uint32(u>>32)), uint32(u))
+}
+
+// msgpack ext value
+type msgpackExt struct {
+	Type int8
+	Data []byte
+}
+
+// read a msgpack value, maps are map[string]interface{}, integers are int64 (uint64 if bigger)
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	code, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case code < 0x80:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xf0 == 0x80:
+		return readMsgpackMap(r, int(code&0x0f))
+	case code&0xf0 == 0x90:
+		return readMsgpackArray(r, int(code&0x0f))
+	case code&0xe0 == 0xa0:
+		return readMsgpackString(r, int(code&0x1f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readMsgpackLength(r, code-0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackBytes(r, n)
+	case 0xca:
+		b, err := readMsgpackBytes(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := readMsgpackBytes(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := readMsgpackBytes(r, 1<<(code-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		u := uint64(0)
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		b, err := readMsgpackBytes(r, 1<<(code-0xd0))
+		if err != nil {
+			return nil, err
+		}
+		i := int64(int8(b[0]))
+		for _, c := range b[1:] {
+			i = i<<8 | int64(c)
+		}
+		return i, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgpackExt(r, 1<<(code-0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := readMsgpackLength(r, code-0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackExt(r, n)
+	case 0xd9, 0xda, 0xdb:
+		n, err := readMsgpackLength(r, code-0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackString(r, n)
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLength(r, code-0xdc+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLength(r, code-0xde+1)
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, n)
+	}
+	return nil, errors.New("logger: msgpack code " + fmt.Sprintf("0x%x", code) + " is illegal!")
+}
+
+// length of 1, 2 or 4 bytes by size 0, 1, 2
+func readMsgpackLength(r *bufio.Reader, size byte) (int, error) {
+	b, err := readMsgpackBytes(r, 1<<size)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, c := range b {
+		n = n<<8 | int(c)
+	}
+	return n, nil
+}
+
+func readMsgpackBytes(r *bufio.Reader, n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+func readMsgpackString(r *bufio.Reader, n int) (string, error) {
+	b, err := readMsgpackBytes(r, n)
+	return string(b), err
+}
+
+func readMsgpackExt(r *bufio.Reader, n int) (interface{}, error) {
+	extType, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := readMsgpackBytes(r, n)
+	return msgpackExt{Type: int8(extType), Data: data}, err
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	array := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+	return array, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(key)] = value
+	}
+	return m, nil
+}
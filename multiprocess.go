@@ -0,0 +1,91 @@
+package go_logger
+
+import (
+	"os"
+)
+
+// file of a descriptor, files of MultiProcess are locked by it
+type fileDescriptor interface {
+	Fd() uintptr
+}
+
+// suffix of the lock file of MultiProcess, eg: "app.log.lock"
+const FILE_LOCK_SUFFIX = ".lock"
+
+// lock the file against the other processes and logger instances writing it, return the unlock func
+// the lock file is never renamed, so it locks the file through rotations
+// files of file systems without descriptors are not locked, eg: in-memory file systems of one process
+func (fw *FileWriter) lockProcesses() (func(), error) {
+	if fw.processLock == nil {
+		file, err := fw.fs.OpenFile(fw.filename+FILE_LOCK_SUFFIX, os.O_CREATE|os.O_RDWR, fw.fileMode)
+		if err != nil {
+			return nil, err
+		}
+		fw.processLock = file
+	}
+	descriptor, ok := fw.processLock.(fileDescriptor)
+	if !ok {
+		return func() {}, nil
+	}
+	err := lockFile(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		unlockFile(descriptor)
+	}, nil
+}
+
+// after locked, reopen the file if another process rotated it, recount lines if another process wrote it
+func (fw *FileWriter) syncProcesses() error {
+	fileInfo, err := fw.fs.Stat(fw.filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		openInfo, openErr := fw.writer.Stat()
+		if openErr == nil && os.SameFile(fileInfo, openInfo) {
+			if fileInfo.Size() != fw.processSize {
+				lines, err := fw.getFileLines()
+				if err != nil {
+					return err
+				}
+				fw.startLine = lines
+			}
+			fw.setSize(fileInfo.Size())
+			return nil
+		}
+	}
+	err = fw.reopen()
+	if err != nil {
+		return err
+	}
+	openInfo, err := fw.writer.Stat()
+	if err != nil {
+		return err
+	}
+	fw.processSize = openInfo.Size()
+	return nil
+}
+
+// before unlocked, write buffered data for the other processes and record the file size
+func (fw *FileWriter) releaseProcesses() error {
+	err := fw.flushBuffer()
+	if err != nil {
+		return err
+	}
+	openInfo, err := fw.writer.Stat()
+	if err != nil {
+		return err
+	}
+	fw.processSize = openInfo.Size()
+	return nil
+}
+
+// close the lock file
+func (fw *FileWriter) closeProcessLock() {
+	if fw.processLock != nil {
+		fw.processLock.Close()
+		fw.processLock = nil
+	}
+}
@@ -0,0 +1,80 @@
+package go_logger
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAdapterFile_MultiProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// logger instances share the file as processes do, every instance rotates it by lines
+	filename := path.Join(dir, "app.log")
+	loggers := []*Logger{}
+	for i := 0; i < 2; i++ {
+		logger := NewLogger()
+		logger.Detach("console")
+		err = logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+			Filename:     filename,
+			Format:       "%body%",
+			MaxLine:      100,
+			BufferSize:   4096,
+			MultiProcess: true,
+		})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		loggers = append(loggers, logger)
+	}
+
+	wait := sync.WaitGroup{}
+	for i, logger := range loggers {
+		wait.Add(1)
+		go func(i int, logger *Logger) {
+			defer wait.Done()
+			for n := 0; n < 150; n++ {
+				logger.Info(fmt.Sprintf("logger %d message %d", i, n))
+			}
+		}(i, logger)
+	}
+	wait.Wait()
+	for _, logger := range loggers {
+		logger.Detach("file")
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	messages := map[string]bool{}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), FILE_LOCK_SUFFIX) {
+			continue
+		}
+		content, _ := ioutil.ReadFile(path.Join(dir, file.Name()))
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		if len(lines) > 100 {
+			t.Errorf("file %s is not rotated by MaxLine: %d lines", file.Name(), len(lines))
+		}
+		for _, line := range lines {
+			if !strings.HasPrefix(line, "logger ") || messages[line] {
+				t.Errorf("file %s line is corrupted or duplicated: %q", file.Name(), line)
+			}
+			messages[line] = true
+		}
+	}
+	if len(messages) != 300 {
+		t.Errorf("messages of shared file are lost: %d", len(messages))
+	}
+
+	err = NewAdapterFile().Init(&FileConfig{Filename: filename, MultiProcess: true, Gzip: true})
+	if err == nil {
+		t.Error("MultiProcess with Gzip must be error")
+	}
+}
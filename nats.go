@@ -0,0 +1,420 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const NATS_ADAPTER_NAME = "nats"
+
+const (
+	NATS_DEFAULT_ADDRESS        = "127.0.0.1:4222"
+	NATS_DEFAULT_SUBJECT        = "go-logger"
+	NATS_DEFAULT_CLIENT_NAME    = "go-logger"
+	NATS_DEFAULT_BATCH_SIZE     = 100
+	NATS_DEFAULT_FLUSH_INTERVAL = time.Second
+	NATS_DEFAULT_PING_INTERVAL  = time.Minute
+	NATS_DEFAULT_TIMEOUT        = 3 * time.Second
+	NATS_DEFAULT_MAX_RETRIES    = 2
+)
+
+// adapter nats, json records are published to a subject (core nats, at most once)
+// every batch is confirmed by PING / PONG, errors of the server (eg: permissions violation) are returned
+type AdapterNats struct {
+	encodingErrors int64
+	lock           sync.Mutex
+	connLock       sync.Mutex
+	config         *NatsConfig
+	conn           net.Conn
+	reader         *bufio.Reader
+	maxPayload     int
+	lastWrite      time.Time
+	buffer         []*loggerMessage
+	ticker         *time.Ticker
+	quit           chan struct{}
+}
+
+// nats config
+type NatsConfig struct {
+
+	// address of nats server, default "127.0.0.1:4222"
+	Address string
+
+	// subject of records, placeholders of Format are replaced, default "go-logger"
+	// example: "logs.%level_string%"
+	Subject string
+
+	// user and password, or token of the server authorization
+	Username string
+	Password string
+	Token    string
+
+	// client name shown by the server monitoring, default "go-logger"
+	ClientName string
+
+	// max records of one batch, default 100
+	BatchSize int
+
+	// buffered records are published every FlushInterval, default 1s
+	FlushInterval time.Duration
+
+	// idle connections are pinged every PingInterval, default 1m
+	PingInterval time.Duration
+
+	// dial, write and PONG timeout, default 3s
+	Timeout time.Duration
+
+	// publish is retried with a new connection, default 2, -1 is no retry
+	MaxRetries int
+
+	// tls config, tls is also used if the server requires it
+	TLSConfig *tls.Config
+}
+
+func (nc *NatsConfig) Name() string {
+	return NATS_ADAPTER_NAME
+}
+
+// INFO of nats server
+type natsInfo struct {
+	MaxPayload  int  `json:"max_payload"`
+	TLSRequired bool `json:"tls_required"`
+}
+
+// CONNECT of nats client
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+	TLS      bool   `json:"tls_required"`
+}
+
+func NewAdapterNats() LoggerAbstract {
+	return &AdapterNats{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterNats *AdapterNats) Init(natsConfig Config) error {
+	nc, ok := natsConfig.(*NatsConfig)
+	if !ok || nc == nil {
+		return configTypeError(NATS_ADAPTER_NAME, "NatsConfig")
+	}
+	adapterNats.config = nc
+
+	if nc.Address == "" {
+		nc.Address = NATS_DEFAULT_ADDRESS
+	}
+	if nc.Subject == "" {
+		nc.Subject = NATS_DEFAULT_SUBJECT
+	}
+	if strings.ContainsAny(nc.Subject, " \t\r\n") {
+		return configError(NATS_ADAPTER_NAME, "Subject", "cannot contain whitespace!")
+	}
+	if nc.ClientName == "" {
+		nc.ClientName = NATS_DEFAULT_CLIENT_NAME
+	}
+	if nc.BatchSize <= 0 {
+		nc.BatchSize = NATS_DEFAULT_BATCH_SIZE
+	}
+	if nc.FlushInterval <= 0 {
+		nc.FlushInterval = NATS_DEFAULT_FLUSH_INTERVAL
+	}
+	if nc.PingInterval <= 0 {
+		nc.PingInterval = NATS_DEFAULT_PING_INTERVAL
+	}
+	if nc.Timeout <= 0 {
+		nc.Timeout = NATS_DEFAULT_TIMEOUT
+	}
+	if nc.MaxRetries == 0 {
+		nc.MaxRetries = NATS_DEFAULT_MAX_RETRIES
+	}
+
+	adapterNats.ticker = time.NewTicker(nc.FlushInterval)
+	adapterNats.quit = make(chan struct{})
+	go adapterNats.startFlush(adapterNats.ticker, adapterNats.quit)
+
+	return nil
+}
+
+func (adapterNats *AdapterNats) Write(loggerMsg *loggerMessage) error {
+	adapterNats.lock.Lock()
+	adapterNats.buffer = append(adapterNats.buffer, loggerMsg)
+	if len(adapterNats.buffer) < adapterNats.config.BatchSize {
+		adapterNats.lock.Unlock()
+		return nil
+	}
+	buffer := adapterNats.buffer
+	adapterNats.buffer = []*loggerMessage{}
+	adapterNats.lock.Unlock()
+
+	return adapterNats.publish(buffer)
+}
+
+// publish buffered records, idle connections are pinged
+func (adapterNats *AdapterNats) Flush() {
+	adapterNats.lock.Lock()
+	buffer := adapterNats.buffer
+	adapterNats.buffer = []*loggerMessage{}
+	adapterNats.lock.Unlock()
+
+	err := adapterNats.publish(buffer)
+	if err == nil && len(buffer) == 0 {
+		err = adapterNats.keepAlive()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: nats adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker, publish buffered records and close the connection
+func (adapterNats *AdapterNats) Close() error {
+	if adapterNats.ticker != nil {
+		adapterNats.ticker.Stop()
+		close(adapterNats.quit)
+		adapterNats.ticker = nil
+	}
+	adapterNats.Flush()
+
+	adapterNats.connLock.Lock()
+	defer adapterNats.connLock.Unlock()
+	adapterNats.disconnect()
+	return nil
+}
+
+func (adapterNats *AdapterNats) Name() string {
+	return NATS_ADAPTER_NAME
+}
+
+func (adapterNats *AdapterNats) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// connect to the server if it's not connected, and PING
+func (adapterNats *AdapterNats) HealthCheck() error {
+	adapterNats.connLock.Lock()
+	defer adapterNats.connLock.Unlock()
+
+	err := adapterNats.connect()
+	if err != nil {
+		return err
+	}
+	return adapterNats.ping(nil)
+}
+
+func (adapterNats *AdapterNats) Counters() map[string]int64 {
+	return map[string]int64{
+		COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterNats.encodingErrors),
+	}
+}
+
+// flush buffer every FlushInterval
+func (adapterNats *AdapterNats) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterNats.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// publish records, records larger than max_payload of the server are not published
+func (adapterNats *AdapterNats) publish(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	subjects := make([]string, len(loggerMsgs))
+	records := make([][]byte, len(loggerMsgs))
+	for i, loggerMsg := range loggerMsgs {
+		subjects[i] = loggerMessageFormat(adapterNats.config.Subject, loggerMsg)
+		records[i] = marshalLoggerMessage(loggerMsg, &adapterNats.encodingErrors)
+	}
+
+	adapterNats.connLock.Lock()
+	defer adapterNats.connLock.Unlock()
+
+	err := adapterNats.send(subjects, records)
+	for retry := 0; err != nil && retry < adapterNats.config.MaxRetries; retry++ {
+		if adapterNats.conn != nil {
+			// errors of the server on a kept connection, eg: permissions violation, are not retried
+			break
+		}
+		err = adapterNats.send(subjects, records)
+	}
+	return err
+}
+
+// write PUB of records and PING, wait for PONG, the connection is closed on errors
+func (adapterNats *AdapterNats) send(subjects []string, records [][]byte) error {
+	err := adapterNats.connect()
+	if err != nil {
+		return err
+	}
+
+	buf := []byte{}
+	var sizeErr error
+	for i, record := range records {
+		if adapterNats.maxPayload > 0 && len(record) > adapterNats.maxPayload {
+			if sizeErr == nil {
+				sizeErr = errors.New("nats record of " + strconv.Itoa(len(record)) + " bytes exceeds max_payload " +
+					strconv.Itoa(adapterNats.maxPayload))
+			}
+			continue
+		}
+		buf = append(buf, "PUB "+subjects[i]+" "+strconv.Itoa(len(record))+"\r\n"...)
+		buf = append(buf, record...)
+		buf = append(buf, "\r\n"...)
+	}
+	err = adapterNats.ping(buf)
+	if err != nil {
+		return err
+	}
+	return sizeErr
+}
+
+// ping the connection if it's idle for PingInterval
+func (adapterNats *AdapterNats) keepAlive() error {
+	adapterNats.connLock.Lock()
+	defer adapterNats.connLock.Unlock()
+
+	if adapterNats.conn == nil || time.Since(adapterNats.lastWrite) < adapterNats.config.PingInterval {
+		return nil
+	}
+	return adapterNats.ping(nil)
+}
+
+// write buf and PING, read until PONG, PING of the server is replied, call it after connLock
+func (adapterNats *AdapterNats) ping(buf []byte) error {
+	timeout := adapterNats.config.Timeout
+	adapterNats.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := adapterNats.conn.Write(append(buf, "PING\r\n"...))
+	if err != nil {
+		adapterNats.disconnect()
+		return err
+	}
+	adapterNats.lastWrite = time.Now()
+
+	var serverErr error
+	adapterNats.conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		line, err := adapterNats.reader.ReadString('\n')
+		if err != nil {
+			adapterNats.disconnect()
+			// the server closes the connection after most errors, eg: authorization violation
+			if serverErr != nil {
+				return serverErr
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PONG":
+			return serverErr
+		case line == "PING":
+			adapterNats.conn.Write([]byte("PONG\r\n"))
+		case strings.HasPrefix(line, "-ERR"):
+			if serverErr == nil {
+				serverErr = errors.New("nats error: " + strings.TrimSpace(line[len("-ERR"):]))
+			}
+		}
+	}
+}
+
+// dial, read INFO, upgrade to tls and write CONNECT, call it after connLock
+func (adapterNats *AdapterNats) connect() error {
+	if adapterNats.conn != nil {
+		return nil
+	}
+	config := adapterNats.config
+	conn, err := net.DialTimeout("tcp", config.Address, config.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(config.Timeout))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return errors.New("nats INFO is illegal: " + strings.TrimSpace(line))
+	}
+	info := natsInfo{}
+	err = json.Unmarshal([]byte(line[len("INFO "):]), &info)
+	if err != nil {
+		conn.Close()
+		return errors.New("nats INFO is illegal, error: " + err.Error())
+	}
+
+	if config.TLSConfig != nil || info.TLSRequired {
+		tlsConfig := &tls.Config{}
+		if config.TLSConfig != nil {
+			tlsConfig = config.TLSConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(config.Address)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		err = tlsConn.Handshake()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+
+	connect, _ := json.Marshal(natsConnect{
+		Name:    config.ClientName,
+		Lang:    "go",
+		Version: "go-logger",
+		User:    config.Username,
+		Pass:    config.Password,
+		Token:   config.Token,
+		TLS:     config.TLSConfig != nil || info.TLSRequired,
+	})
+	adapterNats.conn = conn
+	adapterNats.reader = reader
+	adapterNats.maxPayload = info.MaxPayload
+	_, err = conn.Write([]byte("CONNECT " + string(connect) + "\r\n"))
+	if err != nil {
+		adapterNats.disconnect()
+		return err
+	}
+	return nil
+}
+
+func (adapterNats *AdapterNats) disconnect() {
+	if adapterNats.conn != nil {
+		adapterNats.conn.Close()
+		adapterNats.conn = nil
+		adapterNats.reader = nil
+	}
+}
+
+func init() {
+	Register(NATS_ADAPTER_NAME, NewAdapterNats)
+	RegisterConfig(NATS_ADAPTER_NAME, func() Config {
+		return &NatsConfig{}
+	})
+}
@@ -0,0 +1,127 @@
+package go_logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// nats server publishes are sent to the channel as "subject payload", PUB to "denied" is a permissions violation
+func newTestNatsServer(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	published := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte(`INFO {"server_id":"test","max_payload":1024}` + "\r\n"))
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					args := strings.Fields(line)
+					switch args[0] {
+					case "CONNECT":
+						published <- strings.TrimSpace(line)
+					case "PING":
+						conn.Write([]byte("PONG\r\n"))
+					case "PUB":
+						size, _ := strconv.Atoi(args[2])
+						payload := make([]byte, size+2)
+						io.ReadFull(reader, payload)
+						if args[1] == "denied" {
+							conn.Write([]byte("-ERR 'Permissions Violation for Publish to denied'\r\n"))
+							continue
+						}
+						published <- args[1] + " " + string(payload[:size])
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), published
+}
+
+func TestAdapterNats(t *testing.T) {
+
+	address, published := newTestNatsServer(t)
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("nats", LOGGER_LEVEL_DEBUG, &NatsConfig{
+		Address:  address,
+		Subject:  "logs.%level_string%",
+		Username: "edge",
+		Password: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Detach("nats")
+
+	logger.Info("one")
+	logger.Error("two")
+	logger.Flush()
+
+	if connect := <-published; !strings.Contains(connect, `"user":"edge","pass":"secret"`) || !strings.Contains(connect, `"verbose":false`) {
+		t.Errorf("nats connect error: %s", connect)
+	}
+	for _, expect := range []string{`logs.Info {`, `logs.Error {`} {
+		select {
+		case pub := <-published:
+			if !strings.HasPrefix(pub, expect) || !strings.Contains(pub, `"body":`) {
+				t.Errorf("nats publish error: %s, expect %s", pub, expect)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("nats publish timed out")
+		}
+	}
+}
+
+func TestAdapterNats_Errors(t *testing.T) {
+
+	address, published := newTestNatsServer(t)
+	adapterNats := NewAdapterNats()
+	err := adapterNats.Init(&NatsConfig{Address: address, Subject: "denied", BatchSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapterNats.(LoggerCloser).Close()
+
+	err = adapterNats.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "one", nil))
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("nats server error: %v", err)
+	}
+	<-published
+
+	adapterNats.(*AdapterNats).config.Subject = "logs"
+	err = adapterNats.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, strings.Repeat("x", 2048), nil))
+	if err == nil || !strings.Contains(err.Error(), "exceeds max_payload 1024") {
+		t.Errorf("nats max payload error: %v", err)
+	}
+	err = adapterNats.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "two", nil))
+	if err != nil {
+		t.Errorf("nats publish error: %v", err)
+	}
+	if pub := <-published; !strings.Contains(pub, `"body":"two"`) {
+		t.Errorf("nats publish error: %s", pub)
+	}
+
+	err = NewAdapterNats().Init(&NatsConfig{Subject: "logs app"})
+	if err == nil {
+		t.Error("nats subject with whitespace must be error")
+	}
+}
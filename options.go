@@ -0,0 +1,215 @@
+package go_logger
+
+import (
+	"context"
+	"errors"
+)
+
+// option of New()
+type Option func(logger *Logger) error
+
+// option of the adapter of WithFile(), WithConsole() and WithAdapter()
+type AdapterOption func(adapter *adapterOptions) error
+
+// adapter to attach
+type adapterOptions struct {
+	name   string
+	level  int
+	config Config
+}
+
+// new logger of the options, the console adapter is attached if none of the options attaches an adapter
+//
+// example:
+//	logger, err := go_logger.New(
+//		go_logger.WithFile("./app.log", go_logger.MaxSizeMB(100), go_logger.DailyRotate()),
+//		go_logger.WithConsole(go_logger.Color(), go_logger.AtLevel(go_logger.LOGGER_LEVEL_INFO)),
+//	)
+func New(options ...Option) (*Logger, error) {
+	logger := NewLogger()
+	logger.Detach("console")
+	for _, option := range options {
+		err := option(logger)
+		if err != nil {
+			logger.Close(context.Background())
+			return nil, err
+		}
+	}
+	if len(logger.outputs) == 0 {
+		logger.Attach("console", LOGGER_LEVEL_DEBUG, &ConsoleConfig{})
+	}
+	return logger, nil
+}
+
+// attach the file adapter named "file", messages of all levels are written
+func WithFile(filename string, options ...AdapterOption) Option {
+	return WithAdapter(FILE_ADAPTER_NAME, &FileConfig{Filename: filename}, options...)
+}
+
+// attach the console adapter named "console", messages of all levels are written
+func WithConsole(options ...AdapterOption) Option {
+	return WithAdapter(CONSOLE_ADAPTER_NAME, &ConsoleConfig{}, options...)
+}
+
+// attach the registered adapter of the config, named as the adapter
+//
+// example:
+//	go_logger.WithAdapter(go_logger.API_ADAPTER_NAME, &go_logger.ApiConfig{Url: url}, go_logger.AtLevel(go_logger.LOGGER_LEVEL_ERROR))
+func WithAdapter(adapterName string, config Config, options ...AdapterOption) Option {
+	return func(logger *Logger) error {
+		adapter := &adapterOptions{name: adapterName, level: LOGGER_LEVEL_DEBUG, config: config}
+		for _, option := range options {
+			err := option(adapter)
+			if err != nil {
+				return err
+			}
+		}
+		return logger.AttachAs(adapter.name, adapterName, adapter.level, adapter.config)
+	}
+}
+
+// logger level, see SetLevel()
+func WithLevel(level int) Option {
+	return func(logger *Logger) error {
+		return logger.SetLevel(level)
+	}
+}
+
+// async mode with the queue capacity of adapters, see SetAsync()
+func WithAsync(capacity int) Option {
+	return func(logger *Logger) error {
+		logger.SetAsync(capacity)
+		return nil
+	}
+}
+
+// level of the adapter, default LOGGER_LEVEL_DEBUG
+func AtLevel(level int) AdapterOption {
+	return func(adapter *adapterOptions) error {
+		if levelNames()[level] == "" {
+			return errors.New("logger: option AtLevel level is illegal!")
+		}
+		adapter.level = level
+		return nil
+	}
+}
+
+// attach the adapter as name, eg: two files named "access" and "errors"
+func Named(name string) AdapterOption {
+	return func(adapter *adapterOptions) error {
+		adapter.name = name
+		return nil
+	}
+}
+
+// format of console, file and writer adapters
+func Format(format string) AdapterOption {
+	return func(adapter *adapterOptions) error {
+		switch config := adapter.config.(type) {
+		case *ConsoleConfig:
+			config.Format = format
+		case *FileConfig:
+			config.Format = format
+		case *WriterConfig:
+			config.Format = format
+		default:
+			return adapter.illegal("Format")
+		}
+		return nil
+	}
+}
+
+// json format of console, file and writer adapters
+func Json() AdapterOption {
+	return func(adapter *adapterOptions) error {
+		switch config := adapter.config.(type) {
+		case *ConsoleConfig:
+			config.JsonFormat = true
+		case *FileConfig:
+			config.JsonFormat = true
+		case *WriterConfig:
+			config.JsonFormat = true
+		default:
+			return adapter.illegal("Json")
+		}
+		return nil
+	}
+}
+
+// colored messages of the console adapter
+func Color() AdapterOption {
+	return consoleOption("Color", func(config *ConsoleConfig) {
+		config.Color = true
+	})
+}
+
+// max file size in MB, see FileConfig.MaxSize
+func MaxSizeMB(size int64) AdapterOption {
+	return fileOption("MaxSizeMB", func(config *FileConfig) {
+		config.MaxSize = size * 1024
+	})
+}
+
+// max file lines, see FileConfig.MaxLine
+func MaxLines(lines int64) AdapterOption {
+	return fileOption("MaxLines", func(config *FileConfig) {
+		config.MaxLine = lines
+	})
+}
+
+// max bak files, see FileConfig.MaxBak
+func MaxBackups(backups int64) AdapterOption {
+	return fileOption("MaxBackups", func(config *FileConfig) {
+		config.MaxBak = backups
+	})
+}
+
+// rotate files every day at midnight
+func DailyRotate() AdapterOption {
+	return fileOption("DailyRotate", func(config *FileConfig) {
+		config.DateSlice = FILE_SLICE_DATE_DAY
+		config.ScheduledRotate = true
+	})
+}
+
+// rotate files every hour
+func HourlyRotate() AdapterOption {
+	return fileOption("HourlyRotate", func(config *FileConfig) {
+		config.DateSlice = FILE_SLICE_DATE_HOUR
+		config.ScheduledRotate = true
+	})
+}
+
+// write files gzip compressed, see FileConfig.Gzip
+func Compress() AdapterOption {
+	return fileOption("Compress", func(config *FileConfig) {
+		config.Gzip = true
+	})
+}
+
+func consoleOption(option string, set func(config *ConsoleConfig)) AdapterOption {
+	return func(adapter *adapterOptions) error {
+		config, ok := adapter.config.(*ConsoleConfig)
+		if !ok {
+			return adapter.illegal(option)
+		}
+		set(config)
+		return nil
+	}
+}
+
+func fileOption(option string, set func(config *FileConfig)) AdapterOption {
+	return func(adapter *adapterOptions) error {
+		config, ok := adapter.config.(*FileConfig)
+		if !ok {
+			return adapter.illegal(option)
+		}
+		set(config)
+		return nil
+	}
+}
+
+// the option is not an option of the adapter
+func (adapter *adapterOptions) illegal(option string) error {
+	return errors.New("logger: option " + option + " is not an option of adapter " + adapter.name + "!")
+}
@@ -0,0 +1,58 @@
+package go_logger
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+
+	filename := filepath.Join(t.TempDir(), "app.log")
+	logger, err := New(
+		WithLevel(LOGGER_LEVEL_INFO),
+		WithFile(filename, MaxSizeMB(100), MaxBackups(3), DailyRotate(), Format("%level_string% %body%")),
+		WithConsole(Color(), Named("stdout"), AtLevel(LOGGER_LEVEL_ERROR)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Level() != LOGGER_LEVEL_INFO || logger.Adapter("console") != nil || logger.Adapter("stdout") == nil {
+		t.Fatalf("new logger adapters error: %v", logger.outputs)
+	}
+	fileConfig := logger.Adapter("file").(*AdapterFile).config
+	if fileConfig.MaxSize != 100*1024 || fileConfig.MaxBak != 3 || fileConfig.DateSlice != FILE_SLICE_DATE_DAY || !fileConfig.ScheduledRotate {
+		t.Errorf("new logger file config error: %+v", fileConfig)
+	}
+	if !logger.Adapter("stdout").(*AdapterConsole).config.Color || logger.outputs[1].minLevel() != LOGGER_LEVEL_ERROR {
+		t.Error("new logger console config error")
+	}
+
+	logger.Debug("hidden")
+	logger.Info("started")
+	logger.Flush()
+	content, _ := ioutil.ReadFile(filename)
+	if strings.TrimSpace(string(content)) != "Info started" {
+		t.Errorf("new logger file content error: %q", content)
+	}
+	logger.Close(context.Background())
+
+	logger, err = New()
+	if err != nil || logger.Adapter("console") == nil || len(logger.outputs) != 1 {
+		t.Errorf("new logger default console error: %v", err)
+	}
+}
+
+func TestNew_Error(t *testing.T) {
+
+	_, err := New(WithConsole(MaxSizeMB(10)))
+	if err == nil || err.Error() != "logger: option MaxSizeMB is not an option of adapter console!" {
+		t.Errorf("new logger illegal option error: %v", err)
+	}
+	_, err = New(WithFile(""))
+	if err == nil || !strings.Contains(err.Error(), "config Filename can't be empty!") {
+		t.Errorf("new logger file config error: %v", err)
+	}
+}
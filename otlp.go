@@ -0,0 +1,718 @@
+package go_logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const OTLP_ADAPTER_NAME = "otlp"
+
+// protocols of otlp adapter
+const (
+	// protobuf records POSTed to Endpoint + "/v1/logs", default
+	OTLP_PROTOCOL_HTTP_PROTOBUF = "http/protobuf"
+
+	// json records POSTed to Endpoint + "/v1/logs"
+	OTLP_PROTOCOL_HTTP_JSON = "http/json"
+
+	// unary call LogsService/Export, Endpoint "http://" is h2c (go1.24+), "https://" is tls
+	OTLP_PROTOCOL_GRPC = "grpc"
+)
+
+const (
+	OTLP_DEFAULT_HTTP_ENDPOINT = "http://127.0.0.1:4318"
+	OTLP_DEFAULT_GRPC_ENDPOINT = "http://127.0.0.1:4317"
+	OTLP_HTTP_LOGS_PATH        = "/v1/logs"
+	OTLP_GRPC_EXPORT_PATH      = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+	OTLP_SCOPE_NAME            = "github.com/phachon/go-logger"
+
+	OTLP_DEFAULT_BATCH_SIZE        = 512
+	OTLP_DEFAULT_FLUSH_INTERVAL    = 5 * time.Second
+	OTLP_DEFAULT_TIMEOUT           = 10 * time.Second
+	OTLP_DEFAULT_MAX_RETRIES       = 3
+	OTLP_DEFAULT_RETRY_INTERVAL    = 500 * time.Millisecond
+	OTLP_DEFAULT_MAX_RETRY_BACKOFF = 30 * time.Second
+)
+
+// severity numbers of levels, the syslog mapping of the opentelemetry logs data model
+var otlpSeverityNumbers = map[int]int{
+	LOGGER_LEVEL_EMERGENCY: 21, // FATAL
+	LOGGER_LEVEL_ALERT:     19, // ERROR3
+	LOGGER_LEVEL_CRITICAL:  18, // ERROR2
+	LOGGER_LEVEL_ERROR:     17, // ERROR
+	LOGGER_LEVEL_WARNING:   13, // WARN
+	LOGGER_LEVEL_NOTICE:    10, // INFO2
+	LOGGER_LEVEL_INFO:      9,  // INFO
+	LOGGER_LEVEL_DEBUG:     5,  // DEBUG
+}
+
+// grpc status codes retried by otlp exporters
+var otlpRetryableGrpcCodes = map[string]bool{
+	"1":  true, // CANCELLED
+	"4":  true, // DEADLINE_EXCEEDED
+	"8":  true, // RESOURCE_EXHAUSTED
+	"10": true, // ABORTED
+	"11": true, // OUT_OF_RANGE
+	"14": true, // UNAVAILABLE
+	"15": true, // DATA_LOSS
+}
+
+// adapter otlp, records are exported to an opentelemetry collector by OTLP/HTTP or OTLP/gRPC
+type AdapterOtlp struct {
+	lock     sync.Mutex
+	config   *OtlpConfig
+	client   *http.Client
+	url      string
+	resource []otlpKeyValue
+	buffer   []*loggerMessage
+	ticker   *time.Ticker
+	quit     chan struct{}
+}
+
+// otlp config
+type OtlpConfig struct {
+
+	// collector address, default "http://127.0.0.1:4318" of http and "http://127.0.0.1:4317" of grpc
+	// "/v1/logs" is added to http endpoints without path, eg: "https://otlp.example.com" or "https://example.com/otlp/v1/logs"
+	Endpoint string
+
+	// OTLP_PROTOCOL_HTTP_PROTOBUF (default), OTLP_PROTOCOL_HTTP_JSON or OTLP_PROTOCOL_GRPC
+	Protocol string
+
+	// resource attribute "service.name", default env OTEL_SERVICE_NAME or "unknown_service:<executable>"
+	ServiceName string
+
+	// resource attributes, eg: {"service.version": "1.2.0", "deployment.environment": "prod"}
+	// "host.name" is the hostname if it's not set
+	ResourceAttributes map[string]string
+
+	// max records of one export request, default 512
+	BatchSize int
+
+	// buffered messages are exported every FlushInterval, default 5s
+	FlushInterval time.Duration
+
+	// gzip the request, "Content-Encoding: gzip" of http and "grpc-encoding: gzip" of grpc
+	Gzip bool
+
+	// export is retried on network errors, 429, 502, 503, 504 and retryable grpc codes, default 3, -1 is no retry
+	MaxRetries int
+
+	// backoff of the first retry, doubled every retry up to 30s, default 500ms
+	RetryInterval time.Duration
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// request headers (grpc metadata), eg: {"Authorization": "Bearer token"}
+	Headers map[string]string
+
+	// tls config of https Endpoint
+	TLSConfig *tls.Config
+}
+
+func (oc *OtlpConfig) Name() string {
+	return OTLP_ADAPTER_NAME
+}
+
+// attribute of otlp, values are otlpValueOf() values
+type otlpKeyValue struct {
+	key   string
+	value interface{}
+}
+
+func NewAdapterOtlp() LoggerAbstract {
+	return &AdapterOtlp{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterOtlp *AdapterOtlp) Init(otlpConfig Config) error {
+	oc, ok := otlpConfig.(*OtlpConfig)
+	if !ok || oc == nil {
+		return configTypeError(OTLP_ADAPTER_NAME, "OtlpConfig")
+	}
+	adapterOtlp.config = oc
+
+	if oc.Protocol == "" {
+		oc.Protocol = OTLP_PROTOCOL_HTTP_PROTOBUF
+	}
+	if oc.Protocol != OTLP_PROTOCOL_HTTP_PROTOBUF && oc.Protocol != OTLP_PROTOCOL_HTTP_JSON && oc.Protocol != OTLP_PROTOCOL_GRPC {
+		return configError(OTLP_ADAPTER_NAME, "Protocol", "must be http/protobuf, http/json or grpc!")
+	}
+	if oc.Endpoint == "" {
+		oc.Endpoint = OTLP_DEFAULT_HTTP_ENDPOINT
+		if oc.Protocol == OTLP_PROTOCOL_GRPC {
+			oc.Endpoint = OTLP_DEFAULT_GRPC_ENDPOINT
+		}
+	}
+	endpoint, err := url.Parse(oc.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return configError(OTLP_ADAPTER_NAME, "Endpoint", "must be a http or https url!")
+	}
+	if oc.ServiceName == "" {
+		oc.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	}
+	if oc.ServiceName == "" {
+		oc.ServiceName = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	if oc.BatchSize <= 0 {
+		oc.BatchSize = OTLP_DEFAULT_BATCH_SIZE
+	}
+	if oc.FlushInterval <= 0 {
+		oc.FlushInterval = OTLP_DEFAULT_FLUSH_INTERVAL
+	}
+	if oc.MaxRetries == 0 {
+		oc.MaxRetries = OTLP_DEFAULT_MAX_RETRIES
+	}
+	if oc.RetryInterval <= 0 {
+		oc.RetryInterval = OTLP_DEFAULT_RETRY_INTERVAL
+	}
+	if oc.Timeout <= 0 {
+		oc.Timeout = OTLP_DEFAULT_TIMEOUT
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: oc.TLSConfig,
+	}
+	if oc.Protocol == OTLP_PROTOCOL_GRPC {
+		err := forceHttp2(transport, endpoint.Scheme, 0, oc.Timeout)
+		if err != nil {
+			return configError(OTLP_ADAPTER_NAME, "Endpoint", err.Error())
+		}
+		adapterOtlp.url = endpoint.Scheme + "://" + endpoint.Host + OTLP_GRPC_EXPORT_PATH
+	} else if endpoint.Path == "" || endpoint.Path == "/" {
+		adapterOtlp.url = strings.TrimRight(oc.Endpoint, "/") + OTLP_HTTP_LOGS_PATH
+	} else {
+		adapterOtlp.url = oc.Endpoint
+	}
+	adapterOtlp.client = &http.Client{Timeout: oc.Timeout, Transport: transport}
+	adapterOtlp.resource = otlpResource(oc)
+
+	adapterOtlp.ticker = time.NewTicker(oc.FlushInterval)
+	adapterOtlp.quit = make(chan struct{})
+	go adapterOtlp.startFlush(adapterOtlp.ticker, adapterOtlp.quit)
+
+	return nil
+}
+
+func (adapterOtlp *AdapterOtlp) Write(loggerMsg *loggerMessage) error {
+	adapterOtlp.lock.Lock()
+	adapterOtlp.buffer = append(adapterOtlp.buffer, loggerMsg)
+	if len(adapterOtlp.buffer) < adapterOtlp.config.BatchSize {
+		adapterOtlp.lock.Unlock()
+		return nil
+	}
+	buffer := adapterOtlp.buffer
+	adapterOtlp.buffer = []*loggerMessage{}
+	adapterOtlp.lock.Unlock()
+
+	return adapterOtlp.export(buffer)
+}
+
+func (adapterOtlp *AdapterOtlp) Flush() {
+	adapterOtlp.lock.Lock()
+	buffer := adapterOtlp.buffer
+	adapterOtlp.buffer = []*loggerMessage{}
+	adapterOtlp.lock.Unlock()
+
+	err := adapterOtlp.export(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: otlp adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker and export buffered messages
+func (adapterOtlp *AdapterOtlp) Close() error {
+	if adapterOtlp.ticker != nil {
+		adapterOtlp.ticker.Stop()
+		close(adapterOtlp.quit)
+		adapterOtlp.ticker = nil
+	}
+	adapterOtlp.Flush()
+	return nil
+}
+
+func (adapterOtlp *AdapterOtlp) Name() string {
+	return OTLP_ADAPTER_NAME
+}
+
+func (adapterOtlp *AdapterOtlp) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// dial the collector
+func (adapterOtlp *AdapterOtlp) HealthCheck() error {
+	return healthCheckDialUrl(adapterOtlp.url, adapterOtlp.config.Timeout)
+}
+
+// flush buffer every FlushInterval
+func (adapterOtlp *AdapterOtlp) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterOtlp.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// export messages by one request, retry with backoff
+func (adapterOtlp *AdapterOtlp) export(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	var body []byte
+	if adapterOtlp.config.Protocol == OTLP_PROTOCOL_HTTP_JSON {
+		var err error
+		body, err = json.Marshal(otlpJsonRequest(adapterOtlp.resource, loggerMsgs))
+		if err != nil {
+			return err
+		}
+	} else {
+		body = appendOtlpRequest(nil, adapterOtlp.resource, loggerMsgs)
+	}
+	if adapterOtlp.config.Gzip {
+		compressed := &bytes.Buffer{}
+		gzipWriter := gzip.NewWriter(compressed)
+		gzipWriter.Write(body)
+		err := gzipWriter.Close()
+		if err != nil {
+			return err
+		}
+		body = compressed.Bytes()
+	}
+	if adapterOtlp.config.Protocol == OTLP_PROTOCOL_GRPC {
+		// length-prefixed message, the flag is 1 if it's compressed
+		frame := make([]byte, 5, 5+len(body))
+		if adapterOtlp.config.Gzip {
+			frame[0] = 1
+		}
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+		body = append(frame, body...)
+	}
+
+	backoff := adapterOtlp.config.RetryInterval
+	for retry := 0; ; retry++ {
+		retryable, err := adapterOtlp.send(body)
+		if err == nil || !retryable || retry >= adapterOtlp.config.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > OTLP_DEFAULT_MAX_RETRY_BACKOFF {
+			backoff = OTLP_DEFAULT_MAX_RETRY_BACKOFF
+		}
+	}
+}
+
+// send export request, return whether the error is retryable
+func (adapterOtlp *AdapterOtlp) send(body []byte) (bool, error) {
+	config := adapterOtlp.config
+	req, err := http.NewRequest("POST", adapterOtlp.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	switch config.Protocol {
+	case OTLP_PROTOCOL_GRPC:
+		req.Header.Set("Content-Type", "application/grpc")
+		req.Header.Set("TE", "trailers")
+		if config.Gzip {
+			req.Header.Set("grpc-encoding", "gzip")
+		}
+	case OTLP_PROTOCOL_HTTP_JSON:
+		req.Header.Set("Content-Type", "application/json")
+	default:
+		req.Header.Set("Content-Type", "application/x-protobuf")
+	}
+	if config.Gzip && config.Protocol != OTLP_PROTOCOL_GRPC {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := adapterOtlp.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+
+	if config.Protocol == OTLP_PROTOCOL_GRPC && resp.StatusCode == http.StatusOK {
+		// status is in trailers, or in headers of trailers-only responses
+		status, message := resp.Trailer.Get("grpc-status"), resp.Trailer.Get("grpc-message")
+		if status == "" {
+			status, message = resp.Header.Get("grpc-status"), resp.Header.Get("grpc-message")
+		}
+		if status == "0" {
+			return false, nil
+		}
+		message, _ = url.PathUnescape(message)
+		return otlpRetryableGrpcCodes[status], fmt.Errorf("otlp export failed, grpc-status=%s, grpc-message=%s", status, message)
+	}
+	if resp.StatusCode/100 != 2 {
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout
+		return retryable, fmt.Errorf("otlp export failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+	return false, nil
+}
+
+// resource attributes of the config, sorted by key
+func otlpResource(config *OtlpConfig) []otlpKeyValue {
+	attributes := map[string]interface{}{"service.name": config.ServiceName}
+	if hostname, err := os.Hostname(); err == nil {
+		attributes["host.name"] = hostname
+	}
+	for key, value := range config.ResourceAttributes {
+		attributes[key] = value
+	}
+	return otlpAttributes(attributes)
+}
+
+// attributes sorted by key
+func otlpAttributes(values map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attributes := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		attributes = append(attributes, otlpKeyValue{key: key, value: otlpValueOf(values[key])})
+	}
+	return attributes
+}
+
+// attributes of the message, fields and code attributes, valid "trace_id" and "span_id" are ids of the record
+func otlpRecordAttributes(loggerMsg *loggerMessage) ([]otlpKeyValue, []byte, []byte) {
+	fields := make(map[string]interface{}, len(loggerMsg.Fields)+3)
+	var traceId, spanId []byte
+	for key, value := range loggerMsg.Fields {
+		switch key {
+		case LOGGER_FIELD_TRACE_ID:
+			if id := otlpId(value, 16); id != nil {
+				traceId = id
+				continue
+			}
+		case LOGGER_FIELD_SPAN_ID:
+			if id := otlpId(value, 8); id != nil {
+				spanId = id
+				continue
+			}
+		}
+		fields[key] = value
+	}
+	if loggerMsg.File != "" {
+		fields["code.file.path"] = loggerMsg.File
+		fields["code.line.number"] = loggerMsg.Line
+		fields["code.function.name"] = loggerMsg.Function
+	}
+	return otlpAttributes(fields), traceId, spanId
+}
+
+// id of the hex string, nil if it's not a hex id of size bytes
+func otlpId(value interface{}, size int) []byte {
+	text, ok := value.(string)
+	if !ok || len(text) != size*2 {
+		return nil
+	}
+	id, err := hex.DecodeString(text)
+	if err != nil {
+		return nil
+	}
+	return id
+}
+
+// severity number of the level, INFO if it's a custom level
+func otlpSeverityNumber(level int) int {
+	if number, ok := otlpSeverityNumbers[level]; ok {
+		return number
+	}
+	return 9
+}
+
+// value of AnyValue: nil, string, bool, int64, float64, []byte, []interface{} or []otlpKeyValue
+func otlpValueOf(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int64, float64, []byte:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case map[string]interface{}:
+		return otlpAttributes(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return strconv.FormatUint(rv.Uint(), 10)
+		}
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		values := make([]interface{}, rv.Len())
+		for i := range values {
+			values[i] = otlpValueOf(rv.Index(i).Interface())
+		}
+		return values
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			values := make(map[string]interface{}, rv.Len())
+			for _, key := range rv.MapKeys() {
+				values[key.String()] = rv.MapIndex(key).Interface()
+			}
+			return otlpAttributes(values)
+		}
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+	// structs and others are their json, or "%+v" if they cannot be marshaled
+	jsonByte, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%+v", value)
+	}
+	return string(jsonByte)
+}
+
+//------------------------------------------------------------------------------------------------------------
+// protobuf encoding of ExportLogsServiceRequest (opentelemetry/proto/collector/logs/v1)
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+)
+
+func appendProtoVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+func appendProtoTag(buf []byte, field int, wire int) []byte {
+	return appendProtoVarint(buf, uint64(field<<3|wire))
+}
+
+func appendProtoBytes(buf []byte, field int, b []byte) []byte {
+	buf = appendProtoTag(buf, field, protoWireBytes)
+	buf = appendProtoVarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendProtoFixed64(buf []byte, field int, v uint64) []byte {
+	buf = appendProtoTag(buf, field, protoWireFixed64)
+	return binary.LittleEndian.AppendUint64(buf, v)
+}
+
+func appendOtlpRequest(buf []byte, resource []otlpKeyValue, loggerMsgs []*loggerMessage) []byte {
+	resourceMsg := []byte{}
+	for _, attribute := range resource {
+		resourceMsg = appendProtoBytes(resourceMsg, 1, appendOtlpKeyValue(nil, attribute))
+	}
+
+	scopeLogs := appendProtoBytes(nil, 1, appendProtoBytes(nil, 1, []byte(OTLP_SCOPE_NAME)))
+	for _, loggerMsg := range loggerMsgs {
+		scopeLogs = appendProtoBytes(scopeLogs, 2, appendOtlpLogRecord(nil, loggerMsg))
+	}
+
+	resourceLogs := appendProtoBytes(nil, 1, resourceMsg)
+	resourceLogs = appendProtoBytes(resourceLogs, 2, scopeLogs)
+	return appendProtoBytes(buf, 1, resourceLogs)
+}
+
+// LogRecord message
+func appendOtlpLogRecord(buf []byte, loggerMsg *loggerMessage) []byte {
+	timeUnixNano := uint64(loggerMsg.Millisecond) * uint64(time.Millisecond)
+	attributes, traceId, spanId := otlpRecordAttributes(loggerMsg)
+
+	buf = appendProtoFixed64(buf, 1, timeUnixNano)
+	buf = appendProtoTag(buf, 2, protoWireVarint)
+	buf = appendProtoVarint(buf, uint64(otlpSeverityNumber(loggerMsg.Level)))
+	buf = appendProtoBytes(buf, 3, []byte(loggerMsg.LevelString))
+	buf = appendProtoBytes(buf, 5, appendOtlpAnyValue(nil, loggerMsg.Body))
+	for _, attribute := range attributes {
+		buf = appendProtoBytes(buf, 6, appendOtlpKeyValue(nil, attribute))
+	}
+	if traceId != nil {
+		buf = appendProtoBytes(buf, 9, traceId)
+	}
+	if spanId != nil {
+		buf = appendProtoBytes(buf, 10, spanId)
+	}
+	return appendProtoFixed64(buf, 11, timeUnixNano)
+}
+
+// KeyValue message
+func appendOtlpKeyValue(buf []byte, attribute otlpKeyValue) []byte {
+	buf = appendProtoBytes(buf, 1, []byte(attribute.key))
+	return appendProtoBytes(buf, 2, appendOtlpAnyValue(nil, attribute.value))
+}
+
+// AnyValue message of otlpValueOf() value, nil is an empty AnyValue
+func appendOtlpAnyValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return appendProtoBytes(buf, 1, []byte(v))
+	case bool:
+		buf = appendProtoTag(buf, 2, protoWireVarint)
+		if v {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case int64:
+		buf = appendProtoTag(buf, 3, protoWireVarint)
+		return appendProtoVarint(buf, uint64(v))
+	case float64:
+		return appendProtoFixed64(buf, 4, math.Float64bits(v))
+	case []interface{}:
+		array := []byte{}
+		for _, item := range v {
+			array = appendProtoBytes(array, 1, appendOtlpAnyValue(nil, item))
+		}
+		return appendProtoBytes(buf, 5, array)
+	case []otlpKeyValue:
+		kvlist := []byte{}
+		for _, attribute := range v {
+			kvlist = appendProtoBytes(kvlist, 1, appendOtlpKeyValue(nil, attribute))
+		}
+		return appendProtoBytes(buf, 6, kvlist)
+	case []byte:
+		return appendProtoBytes(buf, 7, v)
+	}
+	return buf
+}
+
+//------------------------------------------------------------------------------------------------------------
+// OTLP/JSON encoding, ids are hex, 64 bit integers are strings
+
+func otlpJsonRequest(resource []otlpKeyValue, loggerMsgs []*loggerMessage) map[string]interface{} {
+	records := make([]map[string]interface{}, 0, len(loggerMsgs))
+	for _, loggerMsg := range loggerMsgs {
+		timeUnixNano := strconv.FormatUint(uint64(loggerMsg.Millisecond)*uint64(time.Millisecond), 10)
+		attributes, traceId, spanId := otlpRecordAttributes(loggerMsg)
+		record := map[string]interface{}{
+			"timeUnixNano":         timeUnixNano,
+			"observedTimeUnixNano": timeUnixNano,
+			"severityNumber":       otlpSeverityNumber(loggerMsg.Level),
+			"severityText":         loggerMsg.LevelString,
+			"body":                 otlpJsonAnyValue(loggerMsg.Body),
+			"attributes":           otlpJsonKeyValues(attributes),
+		}
+		if traceId != nil {
+			record["traceId"] = hex.EncodeToString(traceId)
+		}
+		if spanId != nil {
+			record["spanId"] = hex.EncodeToString(spanId)
+		}
+		records = append(records, record)
+	}
+
+	return map[string]interface{}{
+		"resourceLogs": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{"attributes": otlpJsonKeyValues(resource)},
+				"scopeLogs": []interface{}{
+					map[string]interface{}{
+						"scope":      map[string]interface{}{"name": OTLP_SCOPE_NAME},
+						"logRecords": records,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpJsonKeyValues(attributes []otlpKeyValue) []interface{} {
+	values := make([]interface{}, 0, len(attributes))
+	for _, attribute := range attributes {
+		values = append(values, map[string]interface{}{"key": attribute.key, "value": otlpJsonAnyValue(attribute.value)})
+	}
+	return values
+}
+
+// AnyValue of otlpValueOf() value, NaN and infinities are strings of protobuf json
+func otlpJsonAnyValue(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case string:
+		return map[string]interface{}{"stringValue": v}
+	case bool:
+		return map[string]interface{}{"boolValue": v}
+	case int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return map[string]interface{}{"doubleValue": "NaN"}
+		case math.IsInf(v, 1):
+			return map[string]interface{}{"doubleValue": "Infinity"}
+		case math.IsInf(v, -1):
+			return map[string]interface{}{"doubleValue": "-Infinity"}
+		}
+		return map[string]interface{}{"doubleValue": v}
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpJsonAnyValue(item))
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case []otlpKeyValue:
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": otlpJsonKeyValues(v)}}
+	case []byte:
+		return map[string]interface{}{"bytesValue": base64.StdEncoding.EncodeToString(v)}
+	}
+	return map[string]interface{}{}
+}
+
+func init() {
+	Register(OTLP_ADAPTER_NAME, NewAdapterOtlp)
+	RegisterConfig(OTLP_ADAPTER_NAME, func() Config {
+		return &OtlpConfig{}
+	})
+}
@@ -0,0 +1,78 @@
+//go:build go1.24
+// +build go1.24
+
+package go_logger
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdapterOtlp_Grpc(t *testing.T) {
+
+	lock := sync.Mutex{}
+	frames := [][]byte{}
+	status := "14"
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		if r.ProtoMajor != 2 || r.URL.Path != OTLP_GRPC_EXPORT_PATH || r.Header.Get("Content-Type") != "application/grpc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		frames = append(frames, body)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "grpc-status, grpc-message")
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("grpc-status", status)
+		w.Header().Set("grpc-message", "try%20later")
+		status = "0"
+	}))
+	server.Config.Protocols = &http.Protocols{}
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	adapter := NewAdapterOtlp().(*AdapterOtlp)
+	err := adapter.Init(&OtlpConfig{Endpoint: server.URL, Protocol: OTLP_PROTOCOL_GRPC, RetryInterval: time.Millisecond, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Close()
+	received := func() [][]byte {
+		lock.Lock()
+		defer lock.Unlock()
+		return frames
+	}
+
+	err = adapter.export([]*loggerMessage{{Millisecond: 1700000000123, Level: LOGGER_LEVEL_INFO, LevelString: "Info", Body: "hello"}})
+	if err != nil {
+		t.Fatalf("otlp grpc export error: %s", err)
+	}
+	if len(received()) != 2 {
+		t.Fatalf("otlp grpc retry error: %d requests", len(received()))
+	}
+	frame := received()[1]
+	if frame[0] != 0 || int(binary.BigEndian.Uint32(frame[1:5])) != len(frame)-5 {
+		t.Fatalf("otlp grpc frame error: %v", frame[:5])
+	}
+	record := testProtoFieldOf(t, testProtoFieldOf(t, testProtoFieldOf(t, frame[5:], 1).bytes, 2).bytes, 2).bytes
+	if testProtoFieldOf(t, record, 2).value != 9 {
+		t.Errorf("otlp grpc record error: %v", testProtoFields(t, record))
+	}
+
+	lock.Lock()
+	status = "3"
+	lock.Unlock()
+	err = adapter.export([]*loggerMessage{{Body: "bad"}})
+	if err == nil || !strings.Contains(err.Error(), "grpc-status=3, grpc-message=try later") || len(received()) != 3 {
+		t.Errorf("otlp grpc status error: %v, %d requests", err, len(received()))
+	}
+}
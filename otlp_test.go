@@ -0,0 +1,186 @@
+package go_logger
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// field of a protobuf message, value is the varint or fixed64 of numeric fields
+type testProtoField struct {
+	number int
+	value  uint64
+	bytes  []byte
+}
+
+// fields of a protobuf message
+func testProtoFields(t *testing.T, buf []byte) []testProtoField {
+	fields := []testProtoField{}
+	for len(buf) > 0 {
+		tag, n := binary.Uvarint(buf)
+		buf = buf[n:]
+		field := testProtoField{number: int(tag >> 3)}
+		switch tag & 7 {
+		case protoWireVarint:
+			field.value, n = binary.Uvarint(buf)
+			buf = buf[n:]
+		case protoWireFixed64:
+			field.value = binary.LittleEndian.Uint64(buf)
+			buf = buf[8:]
+		case protoWireBytes:
+			size, n := binary.Uvarint(buf)
+			field.bytes = buf[n : n+int(size)]
+			buf = buf[n+int(size):]
+		default:
+			t.Fatalf("protobuf wire type %d error", tag&7)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// the first field of the number
+func testProtoFieldOf(t *testing.T, buf []byte, number int) testProtoField {
+	for _, field := range testProtoFields(t, buf) {
+		if field.number == number {
+			return field
+		}
+	}
+	t.Fatalf("protobuf field %d is missing", number)
+	return testProtoField{}
+}
+
+func TestAdapterOtlp_HttpJson(t *testing.T) {
+
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("otlp", LOGGER_LEVEL_DEBUG, &OtlpConfig{
+		Endpoint:           server.URL,
+		Protocol:           OTLP_PROTOCOL_HTTP_JSON,
+		ServiceName:        "checkout",
+		ResourceAttributes: map[string]string{"host.name": "web-1", "service.version": "1.2.0"},
+		Headers:            map[string]string{"Authorization": "Bearer token"},
+		FlushInterval:      time.Hour,
+	})
+	logger.WriterFields(LOGGER_LEVEL_WARNING, "payment slow", map[string]interface{}{
+		"trace_id": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":  "00f067aa0ba902b7",
+		"order":    map[string]interface{}{"id": 42, "paid": true},
+		"tags":     []string{"a", "b"},
+	})
+	logger.Flush()
+
+	r := <-requests
+	if r.URL.Path != OTLP_HTTP_LOGS_PATH || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("otlp request error: %s %v", r.URL.Path, r.Header)
+	}
+	body := <-bodies
+	request := struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []map[string]interface{}
+			}
+			ScopeLogs []struct {
+				Scope      map[string]string
+				LogRecords []map[string]interface{}
+			}
+		}
+	}{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		t.Fatalf("otlp json error: %s, %s", err, body)
+	}
+	resource, _ := json.Marshal(request.ResourceLogs[0].Resource.Attributes)
+	if string(resource) != `[{"key":"host.name","value":{"stringValue":"web-1"}},{"key":"service.name","value":{"stringValue":"checkout"}},{"key":"service.version","value":{"stringValue":"1.2.0"}}]` {
+		t.Errorf("otlp resource error: %s", resource)
+	}
+	record := request.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record["severityNumber"] != float64(13) || record["severityText"] != "Warning" || record["traceId"] != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		record["spanId"] != "00f067aa0ba902b7" || !strings.HasSuffix(record["timeUnixNano"].(string), "000000") {
+		t.Errorf("otlp record error: %v", record)
+	}
+	attributes, _ := json.Marshal(record["attributes"])
+	for _, expected := range []string{
+		`{"key":"order","value":{"kvlistValue":{"values":[{"key":"id","value":{"intValue":"42"}},{"key":"paid","value":{"boolValue":true}}]}}}`,
+		`{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"a"},{"stringValue":"b"}]}}}`,
+		`{"key":"code.file.path","value":{"stringValue":"`,
+	} {
+		if !strings.Contains(string(attributes), expected) {
+			t.Errorf("otlp attributes error: %s", attributes)
+		}
+	}
+	if strings.Contains(string(attributes), "trace_id") {
+		t.Errorf("otlp attributes must not contain trace_id: %s", attributes)
+	}
+}
+
+func TestAdapterOtlp_HttpProtobuf(t *testing.T) {
+
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/x-protobuf" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+		bodies <- body
+	}))
+	defer server.Close()
+
+	adapter := NewAdapterOtlp().(*AdapterOtlp)
+	err := adapter.Init(&OtlpConfig{Endpoint: server.URL + "/otlp/v1/logs", ServiceName: "checkout", FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapter.Close()
+	adapter.Write(&loggerMessage{Millisecond: 1700000000123, Level: LOGGER_LEVEL_ERROR, LevelString: "Error", Body: "failed",
+		Fields: map[string]interface{}{"amount": 1.5}})
+	adapter.Flush()
+
+	body := <-bodies
+	resourceLogs := testProtoFieldOf(t, body, 1).bytes
+	scopeLogs := testProtoFieldOf(t, resourceLogs, 2).bytes
+	if string(testProtoFieldOf(t, testProtoFieldOf(t, scopeLogs, 1).bytes, 1).bytes) != OTLP_SCOPE_NAME {
+		t.Errorf("otlp scope error")
+	}
+	record := testProtoFieldOf(t, scopeLogs, 2).bytes
+	if testProtoFieldOf(t, record, 1).value != 1700000000123000000 || testProtoFieldOf(t, record, 2).value != 17 ||
+		string(testProtoFieldOf(t, record, 3).bytes) != "Error" || string(testProtoFieldOf(t, testProtoFieldOf(t, record, 5).bytes, 1).bytes) != "failed" {
+		t.Errorf("otlp protobuf record error: %v", testProtoFields(t, record))
+	}
+	attribute := testProtoFieldOf(t, record, 6).bytes
+	if string(testProtoFieldOf(t, attribute, 1).bytes) != "amount" || testProtoFieldOf(t, testProtoFieldOf(t, attribute, 2).bytes, 4).value != 0x3ff8000000000000 {
+		t.Errorf("otlp protobuf attribute error: %v", testProtoFields(t, attribute))
+	}
+}
+
+func TestAdapterOtlp_Init(t *testing.T) {
+
+	configs := map[string]*OtlpConfig{
+		"protocol": {Protocol: "thrift"},
+		"endpoint": {Endpoint: "127.0.0.1:4318"},
+	}
+	for name, config := range configs {
+		if NewAdapterOtlp().Init(config) == nil {
+			t.Errorf("otlp config %s must be error", name)
+		}
+	}
+	adapter := NewAdapterOtlp().(*AdapterOtlp)
+	adapter.Init(&OtlpConfig{Protocol: OTLP_PROTOCOL_GRPC})
+	defer adapter.Close()
+	if adapter.url != "http://127.0.0.1:4317"+OTLP_GRPC_EXPORT_PATH || !strings.HasPrefix(adapter.config.ServiceName, "unknown_service:") {
+		t.Errorf("otlp defaults error: %s, %s", adapter.url, adapter.config.ServiceName)
+	}
+}
@@ -0,0 +1,198 @@
+package go_logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// fields of recovered panics
+const (
+	LOGGER_FIELD_PANIC_VALUE = "panic.value"
+	LOGGER_FIELD_PANIC_TYPE  = "panic.type"
+	LOGGER_FIELD_PANIC_STACK = "panic.stack"
+)
+
+// max frames of trimmed panic stack
+const PANIC_STACK_MAX_FRAMES = 32
+
+// max time of Fatal() to drain queues and close adapters before exit
+const FATAL_CLOSE_TIMEOUT = 5 * time.Second
+
+// exit of Fatal(), replaced by tests
+var loggerExit = os.Exit
+
+var panicStackOffsetRegexp = regexp.MustCompile(` \+0x[0-9a-f]+$`)
+
+// fields of the recovered panic value and its stack (debug.Stack())
+// "panic.value" is the error message, the string, or the json object of structs and maps
+// "panic.type" is the go type, "panic.stack" is the stack from the panicking function
+//
+// example:
+//	defer func() {
+//		if e := recover(); e != nil {
+//			logger.WriterFields(go_logger.LOGGER_LEVEL_CRITICAL, "request panic", go_logger.PanicFields(e, debug.Stack()))
+//		}
+//	}()
+func PanicFields(e interface{}, stack []byte) map[string]interface{} {
+	fields := map[string]interface{}{
+		LOGGER_FIELD_PANIC_VALUE: panicValue(e),
+		LOGGER_FIELD_PANIC_TYPE:  fmt.Sprintf("%T", e),
+	}
+	if len(stack) > 0 {
+		fields[LOGGER_FIELD_PANIC_STACK] = trimPanicStack(string(stack))
+	}
+	return fields
+}
+
+// serialize panic value
+func panicValue(e interface{}) interface{} {
+	switch value := e.(type) {
+	case nil:
+		return nil
+	case error:
+		return value.Error()
+	case string:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return value
+	}
+
+	// structs and maps are json objects, keep fields queryable
+	data, err := json.Marshal(e)
+	if err == nil {
+		var object interface{}
+		if json.Unmarshal(data, &object) == nil {
+			return object
+		}
+	}
+	return fmt.Sprintf("%+v", e)
+}
+
+// trim goroutine header, recover handler frames before panic() and pc offsets
+func trimPanicStack(stack string) string {
+	lines := strings.Split(strings.TrimRight(stack, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "goroutine ") {
+		lines = lines[1:]
+	}
+
+	// frames are function and file lines
+	frames := []string{}
+	for i := 0; i+1 < len(lines); i += 2 {
+		file := panicStackOffsetRegexp.ReplaceAllString(strings.TrimSpace(lines[i+1]), "")
+		frames = append(frames, lines[i]+"\n\t"+file)
+	}
+	for i := len(frames) - 1; i >= 0; i-- {
+		if strings.HasPrefix(frames[i], "panic(") {
+			frames = frames[i+1:]
+			break
+		}
+	}
+	if len(frames) > PANIC_STACK_MAX_FRAMES {
+		frames = frames[:PANIC_STACK_MAX_FRAMES]
+	}
+	return strings.Join(frames, "\n")
+}
+
+// log emergency level, close the logger so queues are drained and adapters are flushed, then os.Exit(1)
+// deferred functions are not run
+func (logger *Logger) Fatal(msg string) {
+	logger.write(2, LOGGER_LEVEL_EMERGENCY, msg, nil)
+	logger.exit()
+}
+
+// log emergency format, close the logger and os.Exit(1)
+func (logger *Logger) Fatalf(format string, a ...interface{}) {
+	logger.write(2, LOGGER_LEVEL_EMERGENCY, fmt.Sprintf(format, a...), nil)
+	logger.exit()
+}
+
+// log critical level, flush queues and adapters, then panic(msg)
+func (logger *Logger) Panic(msg string) {
+	logger.write(2, LOGGER_LEVEL_CRITICAL, msg, nil)
+	logger.Flush()
+	panic(msg)
+}
+
+// log critical format, flush queues and adapters, then panic
+func (logger *Logger) Panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	logger.write(2, LOGGER_LEVEL_CRITICAL, msg, nil)
+	logger.Flush()
+	panic(msg)
+}
+
+// recover a panic of the goroutine, log it at critical level with PanicFields() and flush
+// it must be deferred directly, the caller of the message is the panicking function
+//
+// example:
+//	func worker(jobs chan Job) {
+//		defer logger.RecoverAndLog()
+//		for job := range jobs {
+//			job.Run()
+//		}
+//	}
+func (logger *Logger) RecoverAndLog() {
+	e := recover()
+	if e == nil {
+		return
+	}
+	// frames of runtime.gopanic and the deferred call
+	logger.write(3, LOGGER_LEVEL_CRITICAL, "panic: "+fmt.Sprint(panicValue(e)), PanicFields(e, debug.Stack()))
+	logger.Flush()
+}
+
+// log emergency level with bound fields, close the logger and os.Exit(1)
+func (child *ChildLogger) Fatal(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_EMERGENCY, child.prefix+msg, child.messageFields(nil))
+	child.logger.exit()
+}
+
+// log emergency format with bound fields, close the logger and os.Exit(1)
+func (child *ChildLogger) Fatalf(format string, a ...interface{}) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_EMERGENCY, child.prefix+fmt.Sprintf(format, a...), child.messageFields(nil))
+	child.logger.exit()
+}
+
+// log critical level with bound fields, flush and panic(msg)
+func (child *ChildLogger) Panic(msg string) {
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+msg, child.messageFields(nil))
+	child.logger.Flush()
+	panic(msg)
+}
+
+// log critical format with bound fields, flush and panic
+func (child *ChildLogger) Panicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+msg, child.messageFields(nil))
+	child.logger.Flush()
+	panic(msg)
+}
+
+// recover a panic of the goroutine, log it with bound fields and flush, it must be deferred directly
+func (child *ChildLogger) RecoverAndLog() {
+	e := recover()
+	if e == nil {
+		return
+	}
+	child.logger.write(3+child.callerSkip, LOGGER_LEVEL_CRITICAL, child.prefix+"panic: "+fmt.Sprint(panicValue(e)), child.messageFields(PanicFields(e, debug.Stack())))
+	child.logger.Flush()
+}
+
+// close the logger in FATAL_CLOSE_TIMEOUT and exit
+func (logger *Logger) exit() {
+	ctx, cancel := context.WithTimeout(context.Background(), FATAL_CLOSE_TIMEOUT)
+	err := logger.Close(ctx)
+	cancel()
+	if err != nil && err != ErrLoggerClosed {
+		fmt.Fprintf(os.Stderr, "logger: close before exit failed, error: %v\n", err)
+	}
+	loggerExit(1)
+}
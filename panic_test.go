@@ -0,0 +1,141 @@
+package go_logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type panicTestValue struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+func recoverPanicFields(fn func()) (fields map[string]interface{}) {
+	defer func() {
+		fields = PanicFields(recover(), debug.Stack())
+	}()
+	fn()
+	return nil
+}
+
+func TestPanicFields(t *testing.T) {
+
+	fields := recoverPanicFields(func() { panic(errors.New("nil map")) })
+	if fields[LOGGER_FIELD_PANIC_VALUE] != "nil map" || fields[LOGGER_FIELD_PANIC_TYPE] != "*errors.errorString" {
+		t.Errorf("error panic fields error: %v", fields)
+	}
+
+	fields = recoverPanicFields(func() { panic(panicTestValue{Code: 3, Reason: "quota"}) })
+	value, ok := fields[LOGGER_FIELD_PANIC_VALUE].(map[string]interface{})
+	if !ok || value["reason"] != "quota" || value["code"] != float64(3) {
+		t.Errorf("struct panic value must be json object: %v", fields[LOGGER_FIELD_PANIC_VALUE])
+	}
+
+	stack := fields[LOGGER_FIELD_PANIC_STACK].(string)
+	if !strings.HasPrefix(stack, "github.com/phachon/go-logger.TestPanicFields.func2()") {
+		t.Errorf("panic stack must start from the panicking function: %s", stack)
+	}
+	if strings.Contains(stack, "debug.Stack") || strings.Contains(stack, "+0x") {
+		t.Errorf("panic stack must be trimmed: %s", stack)
+	}
+}
+
+func newTestPanicLogger(buffer *bytes.Buffer) *Logger {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "%level_string% %body% %file%:%line%",
+	})
+	return logger
+}
+
+func TestLogger_Fatal(t *testing.T) {
+
+	exitCode := -1
+	loggerExit = func(code int) {
+		exitCode = code
+	}
+	defer func() {
+		loggerExit = os.Exit
+	}()
+
+	buffer := &bytes.Buffer{}
+	logger := newTestPanicLogger(buffer)
+	logger.SetAsync()
+	logger.Fatalf("config %s missing", "db")
+	if exitCode != 1 {
+		t.Errorf("logger fatal exit code %d, expect 1", exitCode)
+	}
+	if !strings.HasPrefix(buffer.String(), "Emergency config db missing panic_test.go:") {
+		t.Errorf("logger fatal message must be drained before exit: %q", buffer.String())
+	}
+	if logger.Writer(LOGGER_LEVEL_INFO, "closed") != ErrLoggerClosed {
+		t.Errorf("logger fatal must close the logger")
+	}
+}
+
+func TestLogger_Panic(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := newTestPanicLogger(buffer)
+	child := logger.With(map[string]interface{}{"k": "v"})
+
+	for _, fn := range []func(){
+		func() { logger.Panic("boom") },
+		func() { child.Panicf("child %s", "boom") },
+	} {
+		func() {
+			defer func() {
+				if e := recover(); e == nil {
+					t.Errorf("logger panic must panic")
+				}
+			}()
+			fn()
+		}()
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "Critical boom panic_test.go:") || !strings.HasPrefix(lines[1], "Critical child boom panic_test.go:") {
+		t.Errorf("logger panic messages error: %q", buffer.String())
+	}
+}
+
+func testRecoverAndLog(logger *Logger) (line int) {
+	defer logger.RecoverAndLog()
+	_, _, line, _ = runtime.Caller(0)
+	panic(errors.New("nil map"))
+}
+
+func TestLogger_RecoverAndLog(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := newTestPanicLogger(buffer)
+	logger.RecoverAndLog()
+
+	line := testRecoverAndLog(logger)
+	expect := "Critical panic: nil map panic_test.go:" + strconv.Itoa(line+1) + "\n"
+	if buffer.String() != expect {
+		t.Fatalf("logger recover and log error: %q", buffer.String())
+	}
+
+	memory := NewAdapterMemory().(*AdapterMemory)
+	memory.Init(&MemoryConfig{})
+	logger.AttachAdapter("memory", LOGGER_LEVEL_DEBUG, memory)
+	func() {
+		defer logger.With(map[string]interface{}{"k": "v"}).RecoverAndLog()
+		panic("child")
+	}()
+	messages := memory.messages()
+	if len(messages) != 1 || messages[0].Body != "panic: child" || messages[0].Fields["k"] != "v" {
+		t.Fatalf("child recover and log error: %v", messages)
+	}
+	if !strings.HasPrefix(messages[0].Fields[LOGGER_FIELD_PANIC_STACK].(string), "github.com/phachon/go-logger.TestLogger_RecoverAndLog.func1(") {
+		t.Errorf("child recover and log stack error: %v", messages[0].Fields[LOGGER_FIELD_PANIC_STACK])
+	}
+}
@@ -0,0 +1,273 @@
+package go_logger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// kinds of pipeline nodes
+const (
+	PIPELINE_NODE_LOGGER  = "logger"
+	PIPELINE_NODE_STAGE   = "stage"
+	PIPELINE_NODE_ROUTER  = "router"
+	PIPELINE_NODE_QUEUE   = "queue"
+	PIPELINE_NODE_ADAPTER = "adapter"
+)
+
+// pipeline of the logger, messages flow by edges from the logger node through stages and the router to adapters
+type PipelineGraph struct {
+	Nodes []PipelineNode `json:"nodes"`
+	Edges []PipelineEdge `json:"edges"`
+}
+
+// node of the pipeline, eg: id "adapter:file", kind PIPELINE_NODE_ADAPTER, attributes {"level": "Debug", "filter": "exclude_components=[thirdparty]"}
+type PipelineNode struct {
+	Id         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Label      string            `json:"label"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// edge of the pipeline, label "fallback" is an edge to the fallback adapter
+type PipelineEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label,omitempty"`
+}
+
+// graph of the configured pipeline: tee loggers, flight recorder, logger sampler, hooks, field encoders, redactor, field encryptor, budgets
+// and write-ahead log in dispatch order, the router and adapters with their levels, filters, samplers, queues and fallbacks
+// json.Marshal() the graph or render DOT() by graphviz, eg: "dot -Tsvg pipeline.dot"
+//
+// example:
+//	ioutil.WriteFile("pipeline.dot", []byte(logger.PipelineGraph().DOT()), 0644)
+func (logger *Logger) PipelineGraph() *PipelineGraph {
+	logger.lock.Lock()
+	outputs := append([]*outputLogger{}, logger.outputs...)
+	async := !logger.synchronous
+	policy := logger.queuePolicy
+	logger.lock.Unlock()
+
+	graph := &PipelineGraph{}
+	graph.addNode("logger", PIPELINE_NODE_LOGGER, "logger", map[string]string{
+		"level": levelNames()[int(atomic.LoadInt32(&logger.level))],
+		"async": strconv.FormatBool(async),
+	})
+	if tees := logger.teeLoggers(); len(tees) > 0 {
+		graph.Nodes[0].Attributes["tees"] = strconv.Itoa(len(tees))
+	}
+	last := "logger"
+	addStage := func(name string, attributes map[string]string) {
+		graph.addNode("stage:"+name, PIPELINE_NODE_STAGE, name, attributes)
+		graph.addEdge(last, "stage:"+name, "")
+		last = "stage:" + name
+	}
+
+	if recorder := logger.flightRecorder(); recorder != nil {
+		addStage("flight_recorder", map[string]string{
+			"size":    strconv.Itoa(recorder.config.Size),
+			"level":   levelNames()[recorder.config.Level],
+			"trigger": levelNames()[recorder.config.TriggerLevel],
+		})
+	}
+	if sampler, ok := logger.sampler.Load().(**Sampler); ok && *sampler != nil {
+		addStage("sampler", (*sampler).pipelineAttributes())
+	}
+	if hooks, _ := logger.hooks.Load().([]Hook); len(hooks) > 0 {
+		addStage("hooks", map[string]string{"hooks": strconv.Itoa(len(hooks))})
+	}
+	if registry, ok := fieldEncoders.Load().(*fieldEncoderRegistry); ok {
+		types := make([]string, 0, len(registry.types))
+		for valueType := range registry.types {
+			types = append(types, valueType.String())
+		}
+		sort.Strings(types)
+		addStage("field_encoders", map[string]string{"types": strings.Join(types, ", ")})
+	}
+	if redactor, ok := logger.redactor.Load().(**Redactor); ok && *redactor != nil {
+		addStage("redactor", map[string]string{"fields": strings.Join(sortedNames((*redactor).fields), ", ")})
+	}
+	if encryptor, ok := logger.encryptor.Load().(**FieldEncryptor); ok && *encryptor != nil {
+		addStage("field_encryptor", map[string]string{"fields": strings.Join(sortedNames((*encryptor).fields), ", ")})
+	}
+	if budgets, _ := logger.budgets.Load().(*loggerBudgets); budgets != nil {
+		budgets.lock.Lock()
+		categories := make(map[string]bool, len(budgets.categories))
+		for category := range budgets.categories {
+			categories[category] = true
+		}
+		budgets.lock.Unlock()
+		addStage("budgets", map[string]string{"categories": strings.Join(sortedNames(categories), ", ")})
+	}
+	if wal, _ := logger.wal.Load().(*writeAheadLog); wal != nil {
+		addStage("wal", map[string]string{"filename": wal.config.Filename})
+	}
+
+	// messages of routed loggers reach only the targets of routes
+	var targets map[string]bool
+	if router, ok := logger.router.Load().(**Router); ok && *router != nil {
+		graph.addNode("router", PIPELINE_NODE_ROUTER, "router", map[string]string{"routes": strings.TrimSpace((*router).String())})
+		graph.addEdge(last, "router", "")
+		last = "router"
+		targets = (*router).targets()
+	}
+
+	for _, output := range outputs {
+		id := "adapter:" + output.Name
+		graph.addNode(id, PIPELINE_NODE_ADAPTER, output.Name, output.pipelineAttributes())
+		if targets != nil && !targets[output.Name] {
+			continue
+		}
+		from := last
+		if async && output.queue != nil {
+			from = "queue:" + output.Name
+			graph.addNode(from, PIPELINE_NODE_QUEUE, "queue "+output.Name, map[string]string{
+				"capacity": strconv.Itoa(cap(output.queue.msgChan)),
+				"policy":   pipelinePolicyNames[policy],
+			})
+			graph.addEdge(last, from, "")
+		}
+		graph.addEdge(from, id, "")
+	}
+	for _, output := range outputs {
+		if fallback, _ := output.fallback.Load().(*adapterFallback); fallback != nil {
+			graph.addEdge("adapter:"+output.Name, "adapter:"+fallback.config.Adapter, "fallback")
+		}
+	}
+	return graph
+}
+
+var pipelinePolicyNames = map[int]string{
+	ASYNC_POLICY_BLOCK:       "block",
+	ASYNC_POLICY_DROP_OLDEST: "drop_oldest",
+	ASYNC_POLICY_DROP_NEWEST: "drop_newest",
+}
+
+// DOT graph of the pipeline, attributes are lines of node labels
+func (graph *PipelineGraph) DOT() string {
+	shapes := map[string]string{
+		PIPELINE_NODE_LOGGER:  "oval",
+		PIPELINE_NODE_STAGE:   "box",
+		PIPELINE_NODE_ROUTER:  "diamond",
+		PIPELINE_NODE_QUEUE:   "cds",
+		PIPELINE_NODE_ADAPTER: "box3d",
+	}
+	dot := &strings.Builder{}
+	dot.WriteString("digraph logger {\n\trankdir=LR;\n")
+	for _, node := range graph.Nodes {
+		label := node.Label
+		for _, name := range sortedStringKeys(node.Attributes) {
+			label += "\n" + name + "=" + node.Attributes[name]
+		}
+		fmt.Fprintf(dot, "\t%s [shape=%s, label=%s];\n", dotQuote(node.Id), shapes[node.Kind], dotQuote(label))
+	}
+	for _, edge := range graph.Edges {
+		if edge.Label != "" {
+			fmt.Fprintf(dot, "\t%s -> %s [label=%s, style=dashed];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Label))
+			continue
+		}
+		fmt.Fprintf(dot, "\t%s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
+func (graph *PipelineGraph) addNode(id string, kind string, label string, attributes map[string]string) {
+	for name, value := range attributes {
+		if value == "" {
+			delete(attributes, name)
+		}
+	}
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+	graph.Nodes = append(graph.Nodes, PipelineNode{Id: id, Kind: kind, Label: label, Attributes: attributes})
+}
+
+func (graph *PipelineGraph) addEdge(from string, to string, label string) {
+	graph.Edges = append(graph.Edges, PipelineEdge{From: from, To: to, Label: label})
+}
+
+// attributes of the adapter node: levels, filter, sampler, dedup, time format, timeout, tags and dependencies
+func (output *outputLogger) pipelineAttributes() map[string]string {
+	attributes := map[string]string{"level": levelNames()[output.minLevel()]}
+	if levels, ok := output.levels.Load().(*levelRange); ok && levels.max != LOGGER_LEVEL_EMERGENCY {
+		attributes["max_level"] = levelNames()[levels.max]
+	}
+	if rules, ok := output.filter.Load().(**FilterRules); ok && *rules != nil {
+		attributes["filter"] = (*rules).String()
+	}
+	if sampler, ok := output.sampler.Load().(**Sampler); ok && *sampler != nil {
+		attributes["sampler"] = (*sampler).pipelineAttributes()["rules"]
+	}
+	if dedup, ok := output.dedup.Load().(*deduper); ok && dedup != nil {
+		attributes["dedup"] = dedup.config.Window.String()
+	}
+	if timeout, _ := output.timeout.Load().(time.Duration); timeout > 0 {
+		attributes["timeout"] = timeout.String()
+	}
+	if ttl, _ := output.ttl.Load().(time.Duration); ttl > 0 {
+		attributes["ttl"] = ttl.String()
+	}
+	if tags := output.tagsOf(); len(tags) > 0 {
+		pairs := []string{}
+		for _, name := range sortedStringKeys(tags) {
+			pairs = append(pairs, name+"="+tags[name])
+		}
+		attributes["tags"] = strings.Join(pairs, ", ")
+	}
+	if format, ok := output.timeFormat.Load().(**TimeFormat); ok && *format != nil {
+		attributes["time"] = (*format).Location.String() + " " + (*format).MillisecondLayout
+	}
+	if dependencies, _ := output.dependsOn.Load().([]string); len(dependencies) > 0 {
+		attributes["depends_on"] = strings.Join(dependencies, ", ")
+	}
+	if atomic.LoadInt32(&output.standby) == 1 {
+		attributes["standby"] = "true"
+	}
+	return attributes
+}
+
+// levels and rules of the sampler, eg: "tick=1s, Debug=100/10"
+func (sampler *Sampler) pipelineAttributes() map[string]string {
+	levels := make([]int, 0, len(sampler.rules))
+	for level := range sampler.rules {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+	rules := []string{}
+	for _, level := range levels {
+		rule := sampler.rules[level]
+		rules = append(rules, levelNames()[level]+"="+strconv.Itoa(rule.First)+"/"+strconv.Itoa(rule.Thereafter))
+	}
+	return map[string]string{"tick": sampler.tick.String(), "rules": strings.Join(rules, ", ")}
+}
+
+// names of the set, sorted
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// keys of the map, sorted
+func sortedStringKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quoted DOT id
+func dotQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text) + `"`
+}
@@ -0,0 +1,68 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLogger_PipelineGraph(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.AttachAs("errors", "memory", LOGGER_LEVEL_ERROR, &MemoryConfig{})
+	logger.AttachAs("audit", "memory", LOGGER_LEVEL_INFO, &MemoryConfig{})
+	logger.AddHook(func(entry *LogEntry) error { return nil })
+	logger.SetRedactor(NewRedactor(&RedactConfig{Fields: []string{"password"}}))
+	logger.SetAdapterFilter("errors", &FilterRules{ExcludeComponents: []string{"thirdparty"}, ExcludeBodies: []*regexp.Regexp{regexp.MustCompile("^health")}})
+	logger.SetAdapterTags("errors", map[string]string{"team": "core"})
+	logger.SetAdapterFallback("errors", &FallbackConfig{Adapter: "memory"})
+	logger.SetRoutes("route level>=error -> [errors]; route default -> [memory]")
+	logger.SetAsync(10)
+	defer logger.Flush()
+
+	graph := logger.PipelineGraph()
+	nodes := map[string]PipelineNode{}
+	for _, node := range graph.Nodes {
+		nodes[node.Id] = node
+	}
+	edges := []string{}
+	for _, edge := range graph.Edges {
+		edges = append(edges, edge.From+"->"+edge.To+edge.Label)
+	}
+	expected := []string{
+		"logger->stage:hooks", "stage:hooks->stage:redactor", "stage:redactor->router",
+		"router->queue:memory", "queue:memory->adapter:memory", "router->queue:errors", "queue:errors->adapter:errors",
+		"adapter:errors->adapter:memoryfallback",
+	}
+	if _, ok := nodes["stage:field_encoders"]; ok {
+		// field encoders are registered for all loggers by other tests
+		expected[1] = "stage:hooks->stage:field_encoders stage:field_encoders->stage:redactor"
+	}
+	if strings.Join(edges, " ") != strings.Join(expected, " ") {
+		t.Errorf("pipeline edges error: %v", edges)
+	}
+	if nodes["adapter:audit"].Kind != PIPELINE_NODE_ADAPTER || nodes["queue:audit"].Id != "" {
+		t.Errorf("pipeline adapter out of routes error: %v", nodes)
+	}
+	errorsNode := nodes["adapter:errors"]
+	if errorsNode.Attributes["level"] != "Error" || errorsNode.Attributes["tags"] != "team=core" ||
+		errorsNode.Attributes["filter"] != "exclude_bodies=[^health] exclude_components=[thirdparty]" {
+		t.Errorf("pipeline adapter attributes error: %v", errorsNode.Attributes)
+	}
+	if nodes["queue:errors"].Attributes["capacity"] != "10" || nodes["stage:redactor"].Attributes["fields"] != "password" {
+		t.Errorf("pipeline stage attributes error: %v", nodes)
+	}
+
+	dot := graph.DOT()
+	if !strings.HasPrefix(dot, "digraph logger {") || !strings.Contains(dot, `"router" -> "queue:errors";`) ||
+		!strings.Contains(dot, `"adapter:errors" [shape=box3d, label="errors\nfilter=exclude_bodies=[^health] exclude_components=[thirdparty]\nlevel=Error\ntags=team=core"];`) ||
+		!strings.Contains(dot, `"adapter:errors" -> "adapter:memory" [label="fallback", style=dashed];`) {
+		t.Errorf("pipeline dot error: %s", dot)
+	}
+	if _, err := json.Marshal(graph); err != nil {
+		t.Errorf("pipeline json error: %s", err)
+	}
+}
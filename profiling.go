@@ -0,0 +1,73 @@
+package go_logger
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync/atomic"
+)
+
+// pprof label keys of profiled logging
+const (
+	PROFILE_LABEL_PHASE   = "go_logger_phase"
+	PROFILE_LABEL_ADAPTER = "go_logger_adapter"
+)
+
+// loggers with profiling enabled, format regions are traced if any
+var profilingLoggers int32
+
+// label logging with pprof labels and runtime/trace regions, so profiles attribute time of logging
+// phases are "dispatch" (sampling, hooks, redaction and routing) and "write" (adapter Write) labeled by adapter,
+// trace regions are "go-logger.dispatch", "go-logger.write" and "go-logger.format"
+//
+// example, CPU of logging by adapter:
+//	go tool pprof -tagfocus go_logger_phase=write -tags cpu.pprof
+func (logger *Logger) SetProfiling(enabled bool) {
+	value := int32(0)
+	if enabled {
+		value = 1
+	}
+	old := atomic.SwapInt32(&logger.profiling, value)
+	atomic.AddInt32(&profilingLoggers, value-old)
+}
+
+// run fn with labels and trace region of the phase
+func profilePhase(phase string, adapter string, fn func()) {
+	labels := pprof.Labels(PROFILE_LABEL_PHASE, phase)
+	if adapter != "" {
+		labels = pprof.Labels(PROFILE_LABEL_PHASE, phase, PROFILE_LABEL_ADAPTER, adapter)
+	}
+	pprof.Do(context.Background(), labels, func(ctx context.Context) {
+		trace.WithRegion(ctx, "go-logger."+phase, fn)
+	})
+}
+
+// dispatch the message, profiled if enabled
+func (logger *Logger) dispatch(loggerMsg *loggerMessage, adapters []string) {
+	if atomic.LoadInt32(&logger.profiling) == 0 {
+		logger.dispatchMessage(loggerMsg, adapters)
+		return
+	}
+	profilePhase("dispatch", "", func() {
+		logger.dispatchMessage(loggerMsg, adapters)
+	})
+}
+
+// adapter Write, profiled if enabled
+func (output *outputLogger) adapterWrite(loggerMsg *loggerMessage) (err error) {
+	if output.profiling == nil || atomic.LoadInt32(output.profiling) == 0 {
+		return output.Write(loggerMsg)
+	}
+	profilePhase("write", output.Name, func() {
+		err = output.Write(loggerMsg)
+	})
+	return err
+}
+
+// trace region of formatting, returns end of the region
+func formatRegion() func() {
+	if atomic.LoadInt32(&profilingLoggers) == 0 {
+		return func() {}
+	}
+	return trace.StartRegion(context.Background(), "go-logger.format").End
+}
@@ -0,0 +1,48 @@
+package go_logger
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// writer blocking until released
+type blockingWriter struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (bw *blockingWriter) Write(p []byte) (int, error) {
+	bw.entered <- struct{}{}
+	<-bw.release
+	return len(p), nil
+}
+
+func TestLogger_SetProfiling(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	writer := &blockingWriter{entered: make(chan struct{}), release: make(chan struct{})}
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: writer})
+	logger.SetProfiling(true)
+	logger.SetProfiling(true)
+	if atomic.LoadInt32(&profilingLoggers) != 1 {
+		t.Errorf("profiling loggers error: %d", profilingLoggers)
+	}
+
+	go logger.Info("profiled")
+	<-writer.entered
+	profile := &bytes.Buffer{}
+	pprof.Lookup("goroutine").WriteTo(profile, 1)
+	close(writer.release)
+	if !strings.Contains(profile.String(), `"go_logger_adapter":"writer"`) || !strings.Contains(profile.String(), `"go_logger_phase":"write"`) {
+		t.Errorf("profiling labels not found in goroutine profile")
+	}
+
+	logger.SetProfiling(false)
+	if atomic.LoadInt32(&profilingLoggers) != 0 {
+		t.Errorf("profiling loggers error: %d", profilingLoggers)
+	}
+}
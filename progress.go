@@ -0,0 +1,190 @@
+package go_logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const PROGRESS_ADAPTER_NAME = "progress"
+
+const (
+	PROGRESS_DEFAULT_INTERVAL = 200 * time.Millisecond
+	PROGRESS_DEFAULT_WIDTH    = 120
+)
+
+// adapter progress, a summary line of long running batch jobs is updated in place instead of printing messages
+// counts of levels and the last error are shown, attach the file adapter for full logs
+type AdapterProgress struct {
+	lock      sync.Mutex
+	config    *ProgressConfig
+	terminal  bool // the line is redrawn in place, otherwise summaries are printed as lines
+	start     time.Time
+	drawn     time.Time
+	counts    map[int]int64
+	lastError string
+	dirty     bool
+}
+
+// progress config
+type ProgressConfig struct {
+
+	// summary writer, default os.Stderr
+	Writer io.Writer
+
+	// label of the summary, eg: job name
+	Label string
+
+	// min interval of redraws, default 200ms
+	// summaries of non terminal writers are printed every Interval
+	Interval time.Duration
+
+	// max width of the summary line, default 120
+	Width int
+}
+
+func (pc *ProgressConfig) Name() string {
+	return PROGRESS_ADAPTER_NAME
+}
+
+func NewAdapterProgress() LoggerAbstract {
+	return &AdapterProgress{
+		counts: map[int]int64{},
+	}
+}
+
+func (adapterProgress *AdapterProgress) Init(progressConfig Config) error {
+	pc, ok := progressConfig.(*ProgressConfig)
+	if !ok || pc == nil {
+		return configTypeError(PROGRESS_ADAPTER_NAME, "ProgressConfig")
+	}
+	adapterProgress.config = pc
+
+	if pc.Writer == nil {
+		pc.Writer = os.Stderr
+	}
+	if pc.Interval <= 0 {
+		pc.Interval = PROGRESS_DEFAULT_INTERVAL
+	}
+	if pc.Width <= 0 {
+		pc.Width = PROGRESS_DEFAULT_WIDTH
+	}
+	adapterProgress.terminal = isTerminal(pc.Writer)
+	adapterProgress.start = time.Now()
+	return nil
+}
+
+// count the message, the summary is redrawn every Interval
+func (adapterProgress *AdapterProgress) Write(loggerMsg *loggerMessage) error {
+	adapterProgress.lock.Lock()
+	defer adapterProgress.lock.Unlock()
+
+	adapterProgress.counts[loggerMsg.Level]++
+	if loggerMsg.Level <= LOGGER_LEVEL_ERROR {
+		adapterProgress.lastError = loggerMsg.Body
+	}
+	adapterProgress.dirty = true
+	if time.Since(adapterProgress.drawn) < adapterProgress.config.Interval {
+		return nil
+	}
+	return adapterProgress.draw()
+}
+
+// redraw the summary
+func (adapterProgress *AdapterProgress) Flush() {
+	adapterProgress.lock.Lock()
+	defer adapterProgress.lock.Unlock()
+
+	if adapterProgress.dirty {
+		adapterProgress.draw()
+	}
+}
+
+func (adapterProgress *AdapterProgress) Name() string {
+	return PROGRESS_ADAPTER_NAME
+}
+
+// draw the final summary and end the line
+func (adapterProgress *AdapterProgress) Close() error {
+	adapterProgress.lock.Lock()
+	defer adapterProgress.lock.Unlock()
+
+	err := adapterProgress.draw()
+	if err == nil && adapterProgress.terminal {
+		_, err = io.WriteString(adapterProgress.config.Writer, "\n")
+	}
+	return err
+}
+
+// messages of levels
+func (adapterProgress *AdapterProgress) Counters() map[string]int64 {
+	adapterProgress.lock.Lock()
+	defer adapterProgress.lock.Unlock()
+
+	counters := map[string]int64{}
+	for level, count := range adapterProgress.counts {
+		counters[strings.ToLower(levelNames()[level])] = count
+	}
+	return counters
+}
+
+// summary line, eg: "[import] 1m30s error=1 warning=3 info=120 | last error: db is down"
+func (adapterProgress *AdapterProgress) summary() string {
+	summary := &strings.Builder{}
+	if adapterProgress.config.Label != "" {
+		summary.WriteString("[" + adapterProgress.config.Label + "] ")
+	}
+	summary.WriteString(time.Since(adapterProgress.start).Truncate(time.Second).String())
+	for _, level := range registeredLevels() {
+		count := adapterProgress.counts[level]
+		if count == 0 {
+			continue
+		}
+		fmt.Fprintf(summary, " %s=%d", strings.ToLower(levelNames()[level]), count)
+	}
+	if adapterProgress.lastError != "" {
+		summary.WriteString(" | last error: " + strings.Replace(adapterProgress.lastError, "\n", " ", -1))
+	}
+
+	line := []rune(summary.String())
+	if len(line) > adapterProgress.config.Width {
+		line = append(line[:adapterProgress.config.Width-3], []rune("...")...)
+	}
+	return string(line)
+}
+
+// draw the summary, after lock
+func (adapterProgress *AdapterProgress) draw() error {
+	adapterProgress.drawn = time.Now()
+	adapterProgress.dirty = false
+	if adapterProgress.terminal {
+		// return to the start of the line and clear it
+		_, err := io.WriteString(adapterProgress.config.Writer, "\r\x1b[K"+adapterProgress.summary())
+		return err
+	}
+	_, err := io.WriteString(adapterProgress.config.Writer, adapterProgress.summary()+"\n")
+	return err
+}
+
+// the writer is a terminal (character device)
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+func init() {
+	Register(PROGRESS_ADAPTER_NAME, NewAdapterProgress)
+	RegisterConfig(PROGRESS_ADAPTER_NAME, func() Config {
+		return &ProgressConfig{}
+	})
+}
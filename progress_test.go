@@ -0,0 +1,43 @@
+package go_logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdapterProgress_Write(t *testing.T) {
+
+	output := &bytes.Buffer{}
+	progressAdapter := NewAdapterProgress()
+	err := progressAdapter.Init(&ProgressConfig{Writer: output, Label: "import", Interval: time.Hour, Width: 60})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	progressAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "row 1", nil))
+	progressAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "row 2", nil))
+	progressAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "row 3 is\nbroken", nil))
+	if output.String() != "[import] 0s info=1\n" {
+		t.Errorf("progress must draw once every interval: %q", output.String())
+	}
+
+	progressAdapter.Flush()
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if len(lines) != 2 || lines[1] != "[import] 0s error=1 info=2 | last error: row 3 is broken" {
+		t.Errorf("progress summary error: %q", lines)
+	}
+	if counters := progressAdapter.(LoggerCounter).Counters(); counters["info"] != 2 || counters["error"] != 1 {
+		t.Errorf("progress counters error: %v", counters)
+	}
+
+	for i := 0; i < 10; i++ {
+		progressAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_CRITICAL, "the long error message of the row", nil))
+	}
+	progressAdapter.(LoggerCloser).Close()
+	lines = strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	if last := lines[len(lines)-1]; len(last) != 60 || !strings.HasSuffix(last, "...") {
+		t.Errorf("progress summary must be truncated to width: %q", last)
+	}
+}
@@ -0,0 +1,122 @@
+package go_logger
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// start time of TestProviders
+var TEST_PROVIDERS_START_TIME = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// providers of message time, sequence, hostname and pid, nil providers are the defaults
+// message sequence, hostname and pid are formatted by %sequence%, %hostname% and %pid%
+type Providers struct {
+
+	// message time, default time.Now
+	Now func() time.Time
+
+	// message sequence, default increases from 1 for every message of the logger
+	Sequence func() uint64
+
+	// hostname and pid, called once by SetProviders, default os.Hostname and os.Getpid
+	Hostname func() string
+	Pid      func() int
+}
+
+// providers of logger
+type loggerProviders struct {
+	now      func() time.Time
+	nowSet   bool // Now is provided, it replaces time of slog records
+	sequence func() uint64
+	host     *loggerHost
+}
+
+// hostname and pid of messages
+type loggerHost struct {
+	hostname string
+	pid      int
+}
+
+// set providers of message time, sequence, hostname and pid
+func (logger *Logger) SetProviders(providers Providers) {
+	logger.providers.Store(newLoggerProviders(providers))
+}
+
+// deterministic test mode for golden-file comparisons of formatted output, see TestProviders
+func (logger *Logger) SetTestMode() {
+	logger.SetProviders(TestProviders())
+}
+
+// deterministic providers, time starts at TEST_PROVIDERS_START_TIME (UTC) and advances 1ms every message,
+// sequence increases from 1, hostname is "localhost" and pid is 1
+func TestProviders() Providers {
+	sequence := newSequence()
+	return Providers{
+		Now: func() time.Time {
+			return TEST_PROVIDERS_START_TIME.Add(time.Duration(sequence()-1) * time.Millisecond)
+		},
+		Sequence: newSequence(),
+		Hostname: func() string {
+			return "localhost"
+		},
+		Pid: func() int {
+			return 1
+		},
+	}
+}
+
+func newLoggerProviders(providers Providers) *loggerProviders {
+	lp := &loggerProviders{
+		now:      providers.Now,
+		nowSet:   providers.Now != nil,
+		sequence: providers.Sequence,
+		host:     &loggerHost{},
+	}
+	if lp.now == nil {
+		lp.now = time.Now
+	}
+	if lp.sequence == nil {
+		lp.sequence = newSequence()
+	}
+	if providers.Hostname != nil {
+		lp.host.hostname = providers.Hostname()
+	} else {
+		lp.host.hostname, _ = os.Hostname()
+	}
+	if providers.Pid != nil {
+		lp.host.pid = providers.Pid()
+	} else {
+		lp.host.pid = os.Getpid()
+	}
+	return lp
+}
+
+// sequence increases from 1
+func newSequence() func() uint64 {
+	sequence := new(uint64)
+	return func() uint64 {
+		return atomic.AddUint64(sequence, 1)
+	}
+}
+
+// message time of logger
+func (logger *Logger) now() time.Time {
+	return logger.providers.Load().(*loggerProviders).now()
+}
+
+// time of record, replaced by Now provider
+func (logger *Logger) recordTime(t time.Time) time.Time {
+	providers := logger.providers.Load().(*loggerProviders)
+	if t.IsZero() || providers.nowSet {
+		return providers.now()
+	}
+	return t
+}
+
+// set sequence, hostname and pid of message
+func (logger *Logger) stamp(loggerMsg *loggerMessage) {
+	providers := logger.providers.Load().(*loggerProviders)
+	loggerMsg.sequence = providers.sequence()
+	loggerMsg.host = providers.host
+}
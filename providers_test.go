@@ -0,0 +1,53 @@
+package go_logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetTestMode(t *testing.T) {
+
+	output := func() string {
+		buffer := &bytes.Buffer{}
+		logger := NewLogger()
+		logger.Detach("console")
+		logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+			Writer: buffer,
+			Format: "%millisecond_format% #%sequence% %hostname%[%pid%] [%level_string%] %body%",
+		})
+		logger.SetTestMode()
+		logger.Info("first")
+		logger.Error("second")
+		return buffer.String()
+	}
+
+	golden := "2000-01-01 00:00:00 #1 localhost[1] [Info] first\n" +
+		"2000-01-01 00:00:00.001 #2 localhost[1] [Error] second\n"
+	for i := 0; i < 2; i++ {
+		if result := output(); result != golden {
+			t.Errorf("test mode output error: %q", result)
+		}
+	}
+}
+
+func TestLogger_SetProviders(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "%timestamp% %sequence% %hostname%"})
+	logger.SetProviders(Providers{
+		Now: func() time.Time {
+			return time.Unix(1521791201, 0)
+		},
+		Hostname: func() string {
+			return "web-1"
+		},
+	})
+	logger.Info("message")
+	logger.Info("message")
+	if buffer.String() != "1521791201 1 web-1\n1521791201 2 web-1\n" {
+		t.Errorf("providers output error: %q", buffer.String())
+	}
+}
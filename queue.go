@@ -0,0 +1,166 @@
+package go_logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// async queue policy when the queue is full
+const (
+	// block the caller until the queue has space
+	ASYNC_POLICY_BLOCK = iota
+	// drop the oldest queued message
+	ASYNC_POLICY_DROP_OLDEST
+	// drop the message being written
+	ASYNC_POLICY_DROP_NEWEST
+)
+
+// default async queue capacity of each adapter
+const ASYNC_QUEUE_DEFAULT_CAPACITY = 100
+
+// adapter async queue, one goroutine writes the queued messages to the adapter
+type asyncQueue struct {
+	dropped int64 // first, 64-bit aligned for atomic operations on 32-bit platforms
+	pushed  int64 // messages pushed, after lock
+	done    int64 // messages written or dropped, after lock
+	output  *outputLogger
+	msgChan chan *loggerMessage
+	policy  int
+	lock    sync.Mutex
+	drained *sync.Cond // signaled when done is increased
+	stopped bool       // pushes are rejected after stop()
+	quit    chan struct{}
+}
+
+func newAsyncQueue(output *outputLogger, capacity int, policy int) *asyncQueue {
+	queue := &asyncQueue{
+		output:  output,
+		msgChan: make(chan *loggerMessage, capacity),
+		policy:  policy,
+		quit:    make(chan struct{}),
+	}
+	queue.drained = sync.NewCond(&queue.lock)
+	go queue.start()
+	return queue
+}
+
+// read msgChan and write to adapter
+func (queue *asyncQueue) start() {
+	for {
+		select {
+		case loggerMsg := <-queue.msgChan:
+			queue.write(loggerMsg)
+		case <-queue.quit:
+			return
+		}
+	}
+}
+
+// write the message to the adapter, a panic of the adapter is reported as the write error and the queue goes on
+func (queue *asyncQueue) write(loggerMsg *loggerMessage) {
+	var err error
+	defer func() {
+		if e := recover(); e != nil {
+			err = adapterPanicError(queue.output.Name, e)
+		}
+		if err != nil {
+			queue.output.writeError(loggerMsg, err)
+		}
+		loggerMsg.releaseWAL(err == nil)
+		queue.finish()
+	}()
+	err = queue.output.send(loggerMsg)
+}
+
+// error of a recovered adapter panic
+func adapterPanicError(adapterName string, e interface{}) error {
+	return fmt.Errorf("logger: adapter %s panic: %v", adapterName, e)
+}
+
+// push message to queue by policy, the message is dropped if the queue is stopped
+func (queue *asyncQueue) push(loggerMsg *loggerMessage) {
+	queue.lock.Lock()
+	if queue.stopped {
+		queue.lock.Unlock()
+		loggerMsg.releaseWAL(false)
+		atomic.AddInt64(&queue.dropped, 1)
+		queue.output.strict.fail(STRICT_FAILURE_DROP, queue.output.Name, ErrQueueStopped)
+		return
+	}
+	queue.pushed++
+	queue.lock.Unlock()
+
+	switch queue.policy {
+	case ASYNC_POLICY_DROP_NEWEST:
+		select {
+		case queue.msgChan <- loggerMsg:
+		default:
+			loggerMsg.releaseWAL(false)
+			queue.drop()
+		}
+	case ASYNC_POLICY_DROP_OLDEST:
+		for {
+			select {
+			case queue.msgChan <- loggerMsg:
+				return
+			default:
+			}
+			select {
+			case oldest := <-queue.msgChan:
+				oldest.releaseWAL(false)
+				queue.drop()
+			default:
+			}
+		}
+	default:
+		select {
+		case queue.msgChan <- loggerMsg:
+		case <-queue.quit:
+			loggerMsg.releaseWAL(false)
+			queue.finish()
+		}
+	}
+}
+
+func (queue *asyncQueue) drop() {
+	atomic.AddInt64(&queue.dropped, 1)
+	queue.finish()
+	queue.output.strict.fail(STRICT_FAILURE_DROP, queue.output.Name, ErrQueueFull)
+}
+
+// a pushed message is written or dropped
+func (queue *asyncQueue) finish() {
+	queue.lock.Lock()
+	queue.done++
+	queue.drained.Broadcast()
+	queue.lock.Unlock()
+}
+
+// wait until messages pushed before flush are written, messages pushed during flush are not waited for
+func (queue *asyncQueue) flush() {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	pushed := queue.pushed
+	for queue.done < pushed {
+		queue.drained.Wait()
+	}
+}
+
+// reject new messages, flush and stop the queue goroutine, it's safe to call stop more than once
+func (queue *asyncQueue) stop() {
+	queue.lock.Lock()
+	stopped := queue.stopped
+	queue.stopped = true
+	queue.lock.Unlock()
+	if stopped {
+		return
+	}
+	queue.flush()
+	close(queue.quit)
+}
+
+// dropped message count
+func (queue *asyncQueue) droppedCount() int64 {
+	return atomic.LoadInt64(&queue.dropped)
+}
@@ -0,0 +1,184 @@
+package go_logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// adapter blocks every write until config release is closed
+type blockingAdapter struct {
+	config *blockingConfig
+}
+
+type blockingConfig struct {
+	release chan struct{}
+	lock    sync.Mutex
+	bodies  []string
+}
+
+func (bc *blockingConfig) Name() string {
+	return "blocking"
+}
+
+func (ba *blockingAdapter) Name() string {
+	return "blocking"
+}
+
+func (ba *blockingAdapter) Init(config Config) error {
+	ba.config = config.(*blockingConfig)
+	return nil
+}
+
+func (ba *blockingAdapter) Write(loggerMsg *loggerMessage) error {
+	<-ba.config.release
+	ba.config.lock.Lock()
+	ba.config.bodies = append(ba.config.bodies, loggerMsg.Body)
+	ba.config.lock.Unlock()
+	return nil
+}
+
+func (ba *blockingAdapter) Flush() {
+}
+
+func init() {
+	Register("blocking", func() LoggerAbstract {
+		return &blockingAdapter{}
+	})
+}
+
+func TestLogger_SetAsyncPolicy(t *testing.T) {
+
+	for _, policy := range []int{ASYNC_POLICY_DROP_NEWEST, ASYNC_POLICY_DROP_OLDEST} {
+		blockingConfig := &blockingConfig{release: make(chan struct{})}
+
+		logger := NewLogger()
+		logger.Detach("console")
+		logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+		logger.SetAsyncPolicy(policy)
+		logger.SetAsync(2)
+
+		// first message is taken by the queue goroutine, two are queued, the others dropped
+		logger.Info("1")
+		for len(logger.outputs[0].queue.msgChan) != 0 {
+		}
+		logger.Info("2")
+		logger.Info("3")
+		logger.Info("4")
+		logger.Info("5")
+
+		if logger.Dropped() != 2 || logger.AdapterDropped("blocking") != 2 {
+			t.Errorf("policy %d dropped count error: %d", policy, logger.Dropped())
+		}
+
+		close(blockingConfig.release)
+		logger.Flush()
+
+		bodies := blockingConfig.bodies
+		expect := "123"
+		if policy == ASYNC_POLICY_DROP_OLDEST {
+			expect = "145"
+		}
+		if len(bodies) != 3 || bodies[0]+bodies[1]+bodies[2] != expect {
+			t.Errorf("policy %d written messages error: %v", policy, bodies)
+		}
+	}
+}
+
+func TestAsyncQueue_PushAfterStop(t *testing.T) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetAsync(2)
+	queue := logger.outputs[0].queue
+
+	logger.Info("written")
+	queue.stop()
+	queue.stop()
+	queue.push(&loggerMessage{Level: LOGGER_LEVEL_INFO, Body: "rejected"})
+	queue.flush()
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 1 || queue.droppedCount() != 1 || len(queue.msgChan) != 0 {
+		t.Errorf("push after stop must be rejected: %d entries, %d dropped", len(entries), queue.droppedCount())
+	}
+}
+
+func TestAsyncQueue_FlushWhileLogging(t *testing.T) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetAsync(10)
+
+	stop := make(chan struct{})
+	wait := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("steady")
+				}
+			}
+		}()
+	}
+
+	// flush returns while messages are still pushed
+	for i := 0; i < 20; i++ {
+		logger.Flush()
+	}
+	close(stop)
+	wait.Wait()
+	logger.Flush()
+	if logger.Dropped() != 0 {
+		t.Errorf("blocking queue must not drop: %d", logger.Dropped())
+	}
+}
+
+// writer panics on every write
+type panicWriter struct{}
+
+func (pw panicWriter) Write(p []byte) (int, error) {
+	panic("broken writer")
+}
+
+func TestAsyncQueue_AdapterPanic(t *testing.T) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: panicWriter{}})
+	logger.SetAsync(1)
+
+	lock := sync.Mutex{}
+	errs := []error{}
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+		lock.Lock()
+		errs = append(errs, err)
+		lock.Unlock()
+	})
+
+	// the queue goroutine goes on after a panic, blocking pushes and flush return
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			logger.Info("panic")
+		}
+		logger.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue is stopped by the adapter panic")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(errs) != 3 || !strings.Contains(errs[0].Error(), "adapter writer panic: broken writer") {
+		t.Errorf("adapter panic is not reported: %v", errs)
+	}
+}
@@ -0,0 +1,153 @@
+package go_logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+const FLIGHT_RECORDER_DEFAULT_SIZE = 1000
+
+// flight recorder config, see SetFlightRecorder()
+type FlightRecorderConfig struct {
+
+	// max recorded messages, the oldest are dropped, default 1000
+	Size int
+
+	// messages of this level and lower no adapter writes are recorded, default LOGGER_LEVEL_INFO records Info and Debug
+	Level int
+
+	// messages of this level and higher dump the recorded messages before they are written, default LOGGER_LEVEL_ERROR
+	TriggerLevel int
+
+	// dump to these adapters, empty is all adapters
+	Adapters []string
+}
+
+// ring buffer of messages no adapter writes
+type flightRecorder struct {
+	config FlightRecorderConfig
+
+	lock       sync.Mutex
+	loggerMsgs []*loggerMessage
+	next       int
+	full       bool
+}
+
+// set the flight recorder, the last Size messages of Level and lower which no adapter writes are kept in memory
+// and written with their original time when a message of TriggerLevel or higher arrives, nil config disables it
+// adapters write dumped messages regardless of their levels, eg: the file of errors gets the debug context of every error
+//
+// example:
+//	logger.Attach("file", go_logger.LOGGER_LEVEL_WARNING, &go_logger.FileConfig{Filename: "./app.log"})
+//	logger.SetFlightRecorder(&go_logger.FlightRecorderConfig{Size: 500})
+//	logger.Debug("cache miss") // recorded
+//	logger.Error("timeout")    // the file gets "cache miss" and "timeout"
+func (logger *Logger) SetFlightRecorder(config *FlightRecorderConfig) error {
+	if config == nil {
+		logger.recorder.Store((*flightRecorder)(nil))
+		return nil
+	}
+	recorder := &flightRecorder{config: *config}
+	if recorder.config.Size <= 0 {
+		recorder.config.Size = FLIGHT_RECORDER_DEFAULT_SIZE
+	}
+	if recorder.config.Level == 0 {
+		recorder.config.Level = LOGGER_LEVEL_INFO
+	}
+	if recorder.config.TriggerLevel == 0 {
+		recorder.config.TriggerLevel = LOGGER_LEVEL_ERROR
+	}
+	if levelNames()[recorder.config.Level] == "" || levelNames()[recorder.config.TriggerLevel] == "" {
+		return errors.New("logger: flight recorder level is illegal!")
+	}
+	if recorder.config.TriggerLevel >= recorder.config.Level {
+		return errors.New("logger: flight recorder TriggerLevel must be more severe than Level!")
+	}
+	recorder.loggerMsgs = make([]*loggerMessage, recorder.config.Size)
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, adapterName := range recorder.config.Adapters {
+		attached := false
+		for _, output := range logger.outputs {
+			attached = attached || output.Name == adapterName
+		}
+		if !attached {
+			return errors.New("logger: adapter " + adapterName + " is not attached!")
+		}
+	}
+	logger.recorder.Store(recorder)
+	return nil
+}
+
+// write the recorded messages now, eg: before exiting on a fatal signal
+func (logger *Logger) DumpFlightRecorder() {
+	if recorder := logger.flightRecorder(); recorder != nil {
+		logger.dumpRecorded(recorder)
+	}
+}
+
+func (logger *Logger) flightRecorder() *flightRecorder {
+	recorder, _ := logger.recorder.Load().(*flightRecorder)
+	return recorder
+}
+
+// the flight recorder records messages of the level
+func (logger *Logger) recordEnabled(level int) bool {
+	recorder := logger.flightRecorder()
+	return recorder != nil && level >= recorder.config.Level
+}
+
+// record the message if no adapter writes it, return whether it's recorded
+// messages of the trigger level dump recorded messages before they are written
+func (logger *Logger) record(loggerMsg *loggerMessage) bool {
+	recorder := logger.flightRecorder()
+	if recorder == nil || loggerMsg.verbose || loggerMsg.boosted {
+		return false
+	}
+	if loggerMsg.Level >= recorder.config.Level && loggerMsg.Level > int(atomic.LoadInt32(&logger.gate)) {
+		recorder.add(loggerMsg)
+		return true
+	}
+	if loggerMsg.Level <= recorder.config.TriggerLevel {
+		logger.dumpRecorded(recorder)
+	}
+	return false
+}
+
+// write recorded messages oldest first to the adapters of the recorder
+func (logger *Logger) dumpRecorded(recorder *flightRecorder) {
+	for _, loggerMsg := range recorder.take() {
+		loggerMsg.verbose = true
+		logger.process(loggerMsg, recorder.config.Adapters)
+	}
+}
+
+func (recorder *flightRecorder) add(loggerMsg *loggerMessage) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.loggerMsgs[recorder.next] = loggerMsg
+	recorder.next++
+	if recorder.next == len(recorder.loggerMsgs) {
+		recorder.next = 0
+		recorder.full = true
+	}
+}
+
+// recorded messages oldest first, the recorder is emptied
+func (recorder *flightRecorder) take() []*loggerMessage {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	taken := append([]*loggerMessage{}, recorder.loggerMsgs[:recorder.next]...)
+	if recorder.full {
+		taken = append(append([]*loggerMessage{}, recorder.loggerMsgs[recorder.next:]...), taken...)
+	}
+	recorder.loggerMsgs = make([]*loggerMessage, len(recorder.loggerMsgs))
+	recorder.next = 0
+	recorder.full = false
+	return taken
+}
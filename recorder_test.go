@@ -0,0 +1,57 @@
+package go_logger
+
+import (
+	"testing"
+)
+
+func TestLogger_SetFlightRecorder(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_WARNING, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+	err := logger.SetFlightRecorder(&FlightRecorderConfig{Size: 2})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logger.Debug("debug 1")
+	logger.Info("info 2")
+	logger.Debug("debug 3")
+	logger.Warning("warning")
+	logger.Flush()
+	if entries := recorded.Entries(); len(entries) != 1 || entries[0].Body != "warning" {
+		t.Fatalf("recorded messages must not be written before the trigger: %v", entries)
+	}
+
+	logger.Error("failed")
+	logger.Info("after")
+	logger.Flush()
+	bodies := []string{}
+	for _, entry := range recorded.Entries() {
+		bodies = append(bodies, entry.Body)
+	}
+	if len(bodies) != 4 || bodies[1] != "info 2" || bodies[2] != "debug 3" || bodies[3] != "failed" {
+		t.Errorf("dumped messages error: %v", bodies)
+	}
+
+	logger.DumpFlightRecorder()
+	logger.Flush()
+	if last := recorded.LastEntry(); last == nil || last.Body != "after" {
+		t.Errorf("dump of flight recorder error: %v", last)
+	}
+
+	if logger.SetFlightRecorder(&FlightRecorderConfig{Level: LOGGER_LEVEL_ERROR, TriggerLevel: LOGGER_LEVEL_DEBUG}) == nil {
+		t.Error("trigger level less severe than level must return error")
+	}
+	if logger.SetFlightRecorder(&FlightRecorderConfig{Adapters: []string{"file"}}) == nil {
+		t.Error("adapters not attached must return error")
+	}
+	logger.SetFlightRecorder(nil)
+	logger.Debug("not recorded")
+	logger.Error("failed again")
+	logger.Flush()
+	if len(recorded.Match(MatchBody("not recorded"))) != 0 {
+		t.Error("disabled flight recorder must not record")
+	}
+}
@@ -0,0 +1,213 @@
+package go_logger
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// default redaction mask
+const REDACT_DEFAULT_MASK = "******"
+
+// default redacted field names, case insensitive
+var DefaultRedactFields = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token",
+	"authorization", "api_key", "apikey", "card_number",
+}
+
+// bearer token pattern, eg: "Authorization: Bearer eyJhbGciOi..."
+var RedactPatternBearer = regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/=-]+`)
+
+var redactCardRegexp = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+// redact config
+type RedactConfig struct {
+
+	// values of these fields are masked, name match is case insensitive, default DefaultRedactFields
+	// the last segment of dotted keys is matched too, eg: "req.password", nested maps and slices are masked
+	// "name=value" and "name: value" in message body are masked too
+	Fields []string
+
+	// matched text of message body and string field values is masked
+	Patterns []*regexp.Regexp
+
+	// mask card numbers (13 - 19 digits passed the luhn check)
+	CardNumbers bool
+
+	// default "******"
+	Mask string
+}
+
+// redactor masks secrets of messages before adapters write
+type Redactor struct {
+	fields      map[string]bool
+	bodyRegexp  *regexp.Regexp
+	patterns    []*regexp.Regexp
+	cardNumbers bool
+	mask        string
+}
+
+// new redactor
+func NewRedactor(config *RedactConfig) *Redactor {
+	if config == nil {
+		config = &RedactConfig{}
+	}
+	fieldNames := config.Fields
+	if len(fieldNames) == 0 {
+		fieldNames = DefaultRedactFields
+	}
+	redactor := &Redactor{
+		fields:      map[string]bool{},
+		patterns:    config.Patterns,
+		cardNumbers: config.CardNumbers,
+		mask:        config.Mask,
+	}
+	if redactor.mask == "" {
+		redactor.mask = REDACT_DEFAULT_MASK
+	}
+	quoted := make([]string, 0, len(fieldNames))
+	for _, name := range fieldNames {
+		redactor.fields[strings.ToLower(name)] = true
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+	redactor.bodyRegexp = regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)(\s*[=:]\s*)("[^"]*"|(?:bearer|basic)\s+[^\s,;&]+|[^\s,;&]+)`)
+	return redactor
+}
+
+// set redactor, applied after hooks, nil disables redaction
+func (logger *Logger) SetRedactor(redactor *Redactor) {
+	logger.redactor.Store(&redactor)
+}
+
+// redact the message with logger redactor
+func (logger *Logger) redact(loggerMsg *loggerMessage) {
+	redactor, ok := logger.redactor.Load().(**Redactor)
+	if !ok || *redactor == nil {
+		return
+	}
+	(*redactor).redactMessage(loggerMsg)
+}
+
+// redact body and fields, fields are copied
+func (redactor *Redactor) redactMessage(loggerMsg *loggerMessage) {
+	loggerMsg.Body = redactor.Redact(loggerMsg.Body)
+	if len(loggerMsg.Fields) > 0 {
+		loggerMsg.Fields = redactor.redactFields(loggerMsg.Fields)
+	}
+}
+
+// redact text by field names, patterns and card numbers
+func (redactor *Redactor) Redact(text string) string {
+	text = redactor.bodyRegexp.ReplaceAllString(text, "${1}${2}"+strings.Replace(redactor.mask, "$", "$$", -1))
+	for _, pattern := range redactor.patterns {
+		text = pattern.ReplaceAllLiteralString(text, redactor.mask)
+	}
+	if redactor.cardNumbers {
+		text = redactCardRegexp.ReplaceAllStringFunc(text, func(number string) string {
+			if luhnValid(number) {
+				return redactor.mask
+			}
+			return number
+		})
+	}
+	return text
+}
+
+// redact values of the fields, keys are matched by the whole key and by the last segment of dotted keys, eg: "req.password"
+func (redactor *Redactor) redactFields(fields map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if redactor.redactKey(key) {
+			redacted[key] = redactor.mask
+			continue
+		}
+		redacted[key] = redactor.redactValue(value)
+	}
+	return redacted
+}
+
+// the field of the key is masked
+func (redactor *Redactor) redactKey(key string) bool {
+	key = strings.ToLower(key)
+	if redactor.fields[key] {
+		return true
+	}
+	index := strings.LastIndex(key, ".")
+	return index >= 0 && redactor.fields[key[index+1:]]
+}
+
+// redact strings, maps and slices of the value, maps of other types are copied to map[string]interface{} and slices to []interface{}
+func (redactor *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return redactor.Redact(v)
+	case map[string]interface{}:
+		return redactor.redactFields(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactor.redactValue(item)
+		}
+		return redacted
+	case []byte:
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		redacted := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if redactor.redactKey(key) {
+				redacted[key] = redactor.mask
+				continue
+			}
+			redacted[key] = redactor.redactValue(iter.Value().Interface())
+		}
+		return redacted
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return value
+		}
+		redacted := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			redacted[i] = redactor.redactValue(rv.Index(i).Interface())
+		}
+		return redacted
+	case reflect.String:
+		// named string types are kept unless they're masked
+		if redacted := redactor.Redact(rv.String()); redacted != rv.String() {
+			return redacted
+		}
+	}
+	return value
+}
+
+// luhn check of card number, spaces and dashes are ignored
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,74 @@
+package go_logger
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+
+	redactor := NewRedactor(&RedactConfig{
+		Patterns:    []*regexp.Regexp{RedactPatternBearer},
+		CardNumbers: true,
+	})
+	texts := map[string]string{
+		"login password=abc123 user=go":          "login password=****** user=go",
+		`token: "a b c", next`:                   "token: ******, next",
+		"Authorization: Bearer eyJhbGciOi.x-y_z": "Authorization: ******",
+		"card 4111 1111 1111 1111 paid":          "card ****** paid",
+		"order 1234567890123 created":            "order 1234567890123 created",
+	}
+	for text, expected := range texts {
+		if redactor.Redact(text) != expected {
+			t.Errorf("redact %q error: %q", text, redactor.Redact(text))
+		}
+	}
+}
+
+func TestLogger_SetRedactor(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.SetRedactor(NewRedactor(&RedactConfig{Mask: "[redacted]"}))
+
+	fields := map[string]interface{}{
+		"Password": "abc",
+		"user":     map[string]interface{}{"name": "go", "token": "t1"},
+		"query":    "a=1&secret=s1",
+	}
+	logger.WriterFields(LOGGER_LEVEL_ERROR, "login failed password=abc", fields)
+
+	entry := logger.Adapter("memory").(*AdapterMemory).Entries()[0]
+	if entry.Body != "login failed password=[redacted]" {
+		t.Errorf("redact body error: %s", entry.Body)
+	}
+	if entry.Fields["Password"] != "[redacted]" || entry.Fields["query"] != "a=1&secret=[redacted]" ||
+		entry.Fields["user"].(map[string]interface{})["token"] != "[redacted]" {
+		t.Errorf("redact fields error: %v", entry.Fields)
+	}
+	if fields["Password"] != "abc" {
+		t.Error("redact must not modify the fields of the caller")
+	}
+}
+
+func TestRedactor_RedactNestedFields(t *testing.T) {
+
+	redactor := NewRedactor(nil)
+	fields := redactor.redactFields(map[string]interface{}{
+		"req.password": "hunter2",
+		"user.token":   "abc",
+		"headers":      map[string]string{"Authorization": "Basic YWxhZGRpbg==", "accept": "json"},
+		"accounts":     []interface{}{map[string]interface{}{"password": "p1", "name": "go"}},
+		"tokens":       []string{"password=p2"},
+		"id":           7,
+	})
+	headers := fields["headers"].(map[string]interface{})
+	account := fields["accounts"].([]interface{})[0].(map[string]interface{})
+	if fields["req.password"] != REDACT_DEFAULT_MASK || fields["user.token"] != REDACT_DEFAULT_MASK ||
+		headers["Authorization"] != REDACT_DEFAULT_MASK || headers["accept"] != "json" ||
+		account["password"] != REDACT_DEFAULT_MASK || account["name"] != "go" ||
+		fields["tokens"].([]interface{})[0] != "password="+REDACT_DEFAULT_MASK || fields["id"] != 7 {
+		t.Errorf("redact nested fields error: %v", fields)
+	}
+}
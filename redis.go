@@ -0,0 +1,375 @@
+package go_logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const REDIS_ADAPTER_NAME = "redis"
+
+// modes of redis adapter
+const (
+	// LPUSH records to the list Key, eg: logstash redis input data_type "list"
+	REDIS_MODE_LIST = "list"
+
+	// PUBLISH records to the channel Key, eg: logstash redis input data_type "channel"
+	REDIS_MODE_CHANNEL = "channel"
+
+	// XADD records to the stream Key, the record is the entry field "message"
+	REDIS_MODE_STREAM = "stream"
+)
+
+const (
+	REDIS_DEFAULT_ADDRESS        = "127.0.0.1:6379"
+	REDIS_DEFAULT_KEY            = "go-logger"
+	REDIS_DEFAULT_POOL_SIZE      = 2
+	REDIS_DEFAULT_BATCH_SIZE     = 100
+	REDIS_DEFAULT_FLUSH_INTERVAL = time.Second
+	REDIS_DEFAULT_TIMEOUT        = 3 * time.Second
+	REDIS_DEFAULT_MAX_RETRIES    = 2
+)
+
+// field of stream entries
+const REDIS_STREAM_FIELD = "message"
+
+// adapter redis, json records are pushed to a list, published to a channel or added to a stream
+type AdapterRedis struct {
+	encodingErrors int64
+	lock           sync.Mutex
+	config         *RedisConfig
+	pool           chan *redisConn
+	buffer         []*loggerMessage
+	ticker         *time.Ticker
+	quit           chan struct{}
+}
+
+// redis config
+type RedisConfig struct {
+
+	// address, default "127.0.0.1:6379"
+	Address string
+
+	// AUTH of redis 6 acl if Username is not empty, AUTH password otherwise
+	Username string
+	Password string
+	DB       int
+
+	// REDIS_MODE_LIST (default), REDIS_MODE_CHANNEL or REDIS_MODE_STREAM
+	Mode string
+
+	// list, channel or stream, placeholders of Format are replaced, default "go-logger"
+	// example: "logs:%level_string%"
+	Key string
+
+	// approximate max length of the stream (XADD MAXLEN ~), 0 is unlimited
+	StreamMaxLen int64
+
+	// idle connections kept by the pool, default 2
+	PoolSize int
+
+	// buffered messages are sent by one pipeline when BatchSize is reached or every FlushInterval
+	// default 100 and 1s
+	BatchSize     int
+	FlushInterval time.Duration
+
+	// dial, write and read timeout, default 3s
+	Timeout time.Duration
+
+	// send is retried with a new connection, default 2, -1 is no retry
+	MaxRetries int
+
+	// connect by tls if it's not nil
+	TLSConfig *tls.Config
+}
+
+func (rc *RedisConfig) Name() string {
+	return REDIS_ADAPTER_NAME
+}
+
+// pooled connection
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewAdapterRedis() LoggerAbstract {
+	return &AdapterRedis{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterRedis *AdapterRedis) Init(redisConfig Config) error {
+	rc, ok := redisConfig.(*RedisConfig)
+	if !ok || rc == nil {
+		return configTypeError(REDIS_ADAPTER_NAME, "RedisConfig")
+	}
+	adapterRedis.config = rc
+
+	if rc.Address == "" {
+		rc.Address = REDIS_DEFAULT_ADDRESS
+	}
+	if rc.Mode == "" {
+		rc.Mode = REDIS_MODE_LIST
+	}
+	if rc.Mode != REDIS_MODE_LIST && rc.Mode != REDIS_MODE_CHANNEL && rc.Mode != REDIS_MODE_STREAM {
+		return configError(REDIS_ADAPTER_NAME, "Mode", "must be list, channel or stream!")
+	}
+	if rc.Key == "" {
+		rc.Key = REDIS_DEFAULT_KEY
+	}
+	if rc.PoolSize <= 0 {
+		rc.PoolSize = REDIS_DEFAULT_POOL_SIZE
+	}
+	if rc.BatchSize <= 0 {
+		rc.BatchSize = REDIS_DEFAULT_BATCH_SIZE
+	}
+	if rc.FlushInterval <= 0 {
+		rc.FlushInterval = REDIS_DEFAULT_FLUSH_INTERVAL
+	}
+	if rc.Timeout <= 0 {
+		rc.Timeout = REDIS_DEFAULT_TIMEOUT
+	}
+	if rc.MaxRetries == 0 {
+		rc.MaxRetries = REDIS_DEFAULT_MAX_RETRIES
+	}
+	adapterRedis.pool = make(chan *redisConn, rc.PoolSize)
+
+	adapterRedis.ticker = time.NewTicker(rc.FlushInterval)
+	adapterRedis.quit = make(chan struct{})
+	go adapterRedis.startFlush(adapterRedis.ticker, adapterRedis.quit)
+
+	return nil
+}
+
+func (adapterRedis *AdapterRedis) Write(loggerMsg *loggerMessage) error {
+	adapterRedis.lock.Lock()
+	adapterRedis.buffer = append(adapterRedis.buffer, loggerMsg)
+	if len(adapterRedis.buffer) < adapterRedis.config.BatchSize {
+		adapterRedis.lock.Unlock()
+		return nil
+	}
+	buffer := adapterRedis.buffer
+	adapterRedis.buffer = []*loggerMessage{}
+	adapterRedis.lock.Unlock()
+
+	return adapterRedis.send(buffer)
+}
+
+func (adapterRedis *AdapterRedis) Flush() {
+	adapterRedis.lock.Lock()
+	buffer := adapterRedis.buffer
+	adapterRedis.buffer = []*loggerMessage{}
+	adapterRedis.lock.Unlock()
+
+	err := adapterRedis.send(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: redis adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker, send buffered messages and close pooled connections
+func (adapterRedis *AdapterRedis) Close() error {
+	if adapterRedis.ticker != nil {
+		adapterRedis.ticker.Stop()
+		close(adapterRedis.quit)
+		adapterRedis.ticker = nil
+	}
+	adapterRedis.Flush()
+
+	for {
+		select {
+		case rConn := <-adapterRedis.pool:
+			rConn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (adapterRedis *AdapterRedis) Name() string {
+	return REDIS_ADAPTER_NAME
+}
+
+func (adapterRedis *AdapterRedis) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// PING by a pooled connection
+func (adapterRedis *AdapterRedis) HealthCheck() error {
+	return adapterRedis.pipeline(appendRedisCommand(nil, "PING"), 1)
+}
+
+func (adapterRedis *AdapterRedis) Counters() map[string]int64 {
+	return map[string]int64{
+		COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterRedis.encodingErrors),
+	}
+}
+
+// flush buffer every FlushInterval
+func (adapterRedis *AdapterRedis) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterRedis.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// send messages by one pipeline, records of the same list are pushed by one LPUSH
+func (adapterRedis *AdapterRedis) send(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	config := adapterRedis.config
+	keys := []string{}
+	records := map[string][]string{}
+	for _, loggerMsg := range loggerMsgs {
+		key := loggerMessageFormat(config.Key, loggerMsg)
+		if _, ok := records[key]; !ok {
+			keys = append(keys, key)
+		}
+		records[key] = append(records[key], string(marshalLoggerMessage(loggerMsg, &adapterRedis.encodingErrors)))
+	}
+
+	buf := []byte{}
+	commands := 0
+	for _, key := range keys {
+		switch config.Mode {
+		case REDIS_MODE_LIST:
+			buf = appendRedisCommand(buf, append([]string{"LPUSH", key}, records[key]...)...)
+			commands++
+		case REDIS_MODE_CHANNEL:
+			for _, record := range records[key] {
+				buf = appendRedisCommand(buf, "PUBLISH", key, record)
+				commands++
+			}
+		case REDIS_MODE_STREAM:
+			for _, record := range records[key] {
+				if config.StreamMaxLen > 0 {
+					buf = appendRedisCommand(buf, "XADD", key, "MAXLEN", "~", strconv.FormatInt(config.StreamMaxLen, 10), "*", REDIS_STREAM_FIELD, record)
+				} else {
+					buf = appendRedisCommand(buf, "XADD", key, "*", REDIS_STREAM_FIELD, record)
+				}
+				commands++
+			}
+		}
+	}
+
+	err := adapterRedis.pipeline(buf, commands)
+	for retry := 0; err != nil && retry < config.MaxRetries; retry++ {
+		if _, ok := err.(redisError); ok {
+			// error replies, eg: WRONGTYPE, are not retried
+			break
+		}
+		err = adapterRedis.pipeline(buf, commands)
+	}
+	return err
+}
+
+// write the commands by a pooled connection and read their replies, the connection is closed on network errors
+// commands may be written twice if the connection is broken after they're written (at least once)
+func (adapterRedis *AdapterRedis) pipeline(buf []byte, commands int) error {
+	rConn, err := adapterRedis.get()
+	if err != nil {
+		return err
+	}
+	err = rConn.pipeline(buf, commands, adapterRedis.config.Timeout)
+	if _, ok := err.(redisError); err != nil && !ok {
+		rConn.conn.Close()
+		return err
+	}
+	adapterRedis.put(rConn)
+	return err
+}
+
+// idle connection of the pool, or a new connection
+func (adapterRedis *AdapterRedis) get() (*redisConn, error) {
+	select {
+	case rConn := <-adapterRedis.pool:
+		return rConn, nil
+	default:
+	}
+
+	config := adapterRedis.config
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	var conn net.Conn
+	var err error
+	if config.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", config.Address, config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", config.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rConn := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	buf := []byte{}
+	commands := 0
+	if config.Password != "" {
+		if config.Username != "" {
+			buf = appendRedisCommand(buf, "AUTH", config.Username, config.Password)
+		} else {
+			buf = appendRedisCommand(buf, "AUTH", config.Password)
+		}
+		commands++
+	}
+	if config.DB != 0 {
+		buf = appendRedisCommand(buf, "SELECT", strconv.Itoa(config.DB))
+		commands++
+	}
+	if commands > 0 {
+		err = rConn.pipeline(buf, commands, config.Timeout)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rConn, nil
+}
+
+// return the connection to the pool, it's closed if the pool is full
+func (adapterRedis *AdapterRedis) put(rConn *redisConn) {
+	select {
+	case adapterRedis.pool <- rConn:
+	default:
+		rConn.conn.Close()
+	}
+}
+
+// write the commands and read their replies, return the first error reply
+func (rConn *redisConn) pipeline(buf []byte, commands int, timeout time.Duration) error {
+	rConn.conn.SetDeadline(time.Now().Add(timeout))
+	_, err := rConn.conn.Write(buf)
+	if err != nil {
+		return err
+	}
+	var replyErr error
+	for i := 0; i < commands; i++ {
+		_, err = readRedisReply(rConn.reader)
+		if _, ok := err.(redisError); err != nil && !ok {
+			return err
+		}
+		if err != nil && replyErr == nil {
+			replyErr = err
+		}
+	}
+	return replyErr
+}
+
+func init() {
+	Register(REDIS_ADAPTER_NAME, NewAdapterRedis)
+	RegisterConfig(REDIS_ADAPTER_NAME, func() Config {
+		return &RedisConfig{}
+	})
+}
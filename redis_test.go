@@ -0,0 +1,154 @@
+package go_logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// redis server replying +OK, or reply of the command if it's in replies, commands are sent to the channel
+func newTestRedisServer(t *testing.T, replies map[string]string) (string, chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	commands := make(chan []string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					reply, err := readRedisReply(reader)
+					if err != nil {
+						return
+					}
+					args := []string{}
+					for _, arg := range reply.([]interface{}) {
+						args = append(args, arg.(string))
+					}
+					commands <- args
+					if r, ok := replies[args[0]]; ok {
+						conn.Write([]byte(r))
+					} else {
+						conn.Write([]byte("+OK\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String(), commands
+}
+
+func TestAdapterRedis_List(t *testing.T) {
+
+	address, commands := newTestRedisServer(t, map[string]string{"LPUSH": ":2\r\n"})
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("redis", LOGGER_LEVEL_DEBUG, &RedisConfig{
+		Address:   address,
+		Password:  "secret",
+		DB:        3,
+		Key:       "logs:%level_string%",
+		BatchSize: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("one")
+	logger.Error("two")
+	logger.Info("three")
+
+	expects := []string{"AUTH secret", "SELECT 3", "LPUSH logs:Info", "LPUSH logs:Error"}
+	for _, expect := range expects {
+		args := <-commands
+		if strings.Join(args[:len(strings.Fields(expect))], " ") != expect {
+			t.Fatalf("redis command error: %q, expect %q", args, expect)
+		}
+		if expect == "LPUSH logs:Info" {
+			if len(args) != 4 || !strings.Contains(args[2], `"body":"one"`) || !strings.Contains(args[3], `"body":"three"`) {
+				t.Errorf("redis lpush error: %q", args)
+			}
+			record := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(args[2]), &record); err != nil {
+				t.Errorf("redis record error: %s, %q", err, args[2])
+			}
+		}
+	}
+}
+
+func TestAdapterRedis_Stream(t *testing.T) {
+
+	address, commands := newTestRedisServer(t, map[string]string{"XADD": "$3\r\n1-0\r\n", "PUBLISH": ":1\r\n"})
+	adapterRedis := NewAdapterRedis()
+	err := adapterRedis.Init(&RedisConfig{Address: address, Mode: REDIS_MODE_STREAM, StreamMaxLen: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapterRedis.(LoggerCloser).Close()
+
+	adapterRedis.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "stream", nil))
+	adapterRedis.Flush()
+	args := <-commands
+	if strings.Join(args[:7], " ") != "XADD go-logger MAXLEN ~ 1000 * message" || !strings.Contains(args[7], `"body":"stream"`) {
+		t.Errorf("redis xadd error: %q", args)
+	}
+
+	channelRedis := NewAdapterRedis()
+	err = channelRedis.Init(&RedisConfig{Address: address, Mode: REDIS_MODE_CHANNEL, Key: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer channelRedis.(LoggerCloser).Close()
+	channelRedis.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "a", nil))
+	channelRedis.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "b", nil))
+	channelRedis.Flush()
+	for _, body := range []string{"a", "b"} {
+		args := <-commands
+		if len(args) != 3 || args[0] != "PUBLISH" || args[1] != "logs" || !strings.Contains(args[2], `"body":"`+body+`"`) {
+			t.Errorf("redis publish error: %q", args)
+		}
+	}
+}
+
+func TestAdapterRedis_ErrorReply(t *testing.T) {
+
+	address, commands := newTestRedisServer(t, map[string]string{"LPUSH": "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"})
+	adapterRedis := NewAdapterRedis()
+	err := adapterRedis.Init(&RedisConfig{Address: address, BatchSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer adapterRedis.(LoggerCloser).Close()
+
+	err = adapterRedis.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "one", nil))
+	if err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Errorf("redis error reply error: %v", err)
+	}
+	<-commands
+	// error replies are not retried, the connection is reused
+	adapterRedis.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, "two", nil))
+	if args := <-commands; !strings.Contains(args[2], `"body":"two"`) {
+		t.Errorf("redis error reply retried: %q", args)
+	}
+	if n := len(adapterRedis.(*AdapterRedis).pool); n != 1 {
+		t.Errorf("redis pool size error: %d", n)
+	}
+}
+
+func TestAdapterRedis_Init(t *testing.T) {
+
+	err := NewAdapterRedis().Init(&RedisConfig{Mode: "set"})
+	if err == nil {
+		t.Error("redis mode must be error")
+	}
+}
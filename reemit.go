@@ -0,0 +1,89 @@
+package go_logger
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// log entry, a logger message captured or parsed outside of the logger
+type LogEntry struct {
+	Time     time.Time
+	Level    int
+	Body     string
+	File     string
+	Line     int
+	Function string
+	Fields   map[string]interface{}
+}
+
+// reemit options
+type ReemitOptions struct {
+
+	// only write to these adapters, empty is all adapters (routes and adapter levels still apply)
+	Adapters []string
+
+	// max entries per second, 0 is unlimited
+	// keep a large replay from flooding the adapters
+	Rate int
+}
+
+// LogEntry of the logger message
+func (loggerMsg *loggerMessage) Entry() LogEntry {
+	nanosecond := loggerMsg.nanosecond
+	if nanosecond == 0 {
+		nanosecond = loggerMsg.Millisecond * int64(time.Millisecond)
+	}
+	return LogEntry{
+		Time:     time.Unix(0, nanosecond),
+		Level:    loggerMsg.Level,
+		Body:     loggerMsg.Body,
+		File:     loggerMsg.File,
+		Line:     loggerMsg.Line,
+		Function: loggerMsg.Function,
+		Fields:   loggerMsg.Fields,
+	}
+}
+
+// logger message of the entry, original time is preserved
+func (entry LogEntry) loggerMessage() *loggerMessage {
+	loggerMsg := newLoggerMessage(entry.Time, entry.Level, entry.Body, entry.Fields)
+	loggerMsg.File = entry.File
+	loggerMsg.Line = entry.Line
+	loggerMsg.Function = entry.Function
+	return loggerMsg
+}
+
+// push previously captured entries back through the adapters with their original timestamps
+// entries with illegal level are skipped, return the error of the first one
+func (logger *Logger) Reemit(entries []LogEntry, opts *ReemitOptions) error {
+	if opts == nil {
+		opts = &ReemitOptions{}
+	}
+
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Second / time.Duration(opts.Rate)
+	}
+
+	var reemitErr error
+	nextTime := time.Now()
+	for _, entry := range entries {
+		if atomic.LoadInt32(&logger.closed) == 1 {
+			return ErrLoggerClosed
+		}
+		if levelNames()[entry.Level] == "" {
+			if reemitErr == nil {
+				reemitErr = errors.New("logger: reemit entry level " + strconv.Itoa(entry.Level) + " is illegal!")
+			}
+			continue
+		}
+		if interval > 0 {
+			time.Sleep(time.Until(nextTime))
+			nextTime = nextTime.Add(interval)
+		}
+		logger.dispatch(entry.loggerMessage(), opts.Adapters)
+	}
+	return reemitErr
+}
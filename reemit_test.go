@@ -0,0 +1,47 @@
+package go_logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_Reemit(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{
+		Format: "%timestamp_format% [%level_string%] [%file%:%line%] %body% %fields%",
+	})
+
+	entryTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	entries := []LogEntry{
+		{Time: entryTime, Level: LOGGER_LEVEL_ERROR, Body: "spooled", File: "main.go", Line: 10, Fields: map[string]interface{}{"id": 1}},
+		{Time: entryTime, Level: 100, Body: "illegal"},
+		{Time: entryTime, Level: LOGGER_LEVEL_INFO, Body: "imported"},
+	}
+
+	startTime := time.Now()
+	err := logger.Reemit(entries, &ReemitOptions{Rate: 20})
+	if err == nil {
+		t.Error("reemit illegal level should return error")
+	}
+	if time.Since(startTime) < 50*time.Millisecond {
+		t.Error("reemit rate limit error")
+	}
+	logger.Reemit(entries[:1], &ReemitOptions{Adapters: []string{"console"}})
+
+	content := readLog()
+	if !strings.Contains(content, "2020-01-02 03:04:05 [Error] [main.go:10] spooled id=1") {
+		t.Error("reemit entry error: " + content)
+	}
+	if !strings.Contains(content, "2020-01-02 03:04:05 [Info]") || strings.Contains(content, "illegal") {
+		t.Error("reemit entries error: " + content)
+	}
+	if strings.Count(content, "spooled") != 1 {
+		t.Error("reemit adapters option error")
+	}
+
+	loggerMsg := entries[0].loggerMessage()
+	if entry := loggerMsg.Entry(); !entry.Time.Equal(entryTime) || entry.Body != "spooled" || entry.Line != 10 {
+		t.Error("logger message entry error")
+	}
+}
@@ -0,0 +1,45 @@
+package go_logger
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// runs of TestRegister, adapters of every run have new names
+var registerRuns int
+
+func TestRegister(t *testing.T) {
+
+	names := RegisteredAdapters()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("registered adapters must be sorted: %v", names)
+		}
+	}
+	if !IsRegistered(CONSOLE_ADAPTER_NAME) || IsRegistered("unknown") {
+		t.Errorf("registered adapters error: %v", names)
+	}
+
+	// concurrent register and attach
+	registerRuns++
+	prefix := "test-register-" + strconv.Itoa(registerRuns) + "-"
+	wait := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wait.Add(2)
+		go func(i int) {
+			defer wait.Done()
+			Register(prefix+strconv.Itoa(i), NewAdapterWriter)
+		}(i)
+		go func() {
+			defer wait.Done()
+			logger := NewLogger()
+			logger.Detach("console")
+			logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+		}()
+	}
+	wait.Wait()
+	if len(RegisteredAdapters()) != len(names)+10 {
+		t.Errorf("registered adapters error: %v", RegisteredAdapters())
+	}
+}
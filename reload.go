@@ -0,0 +1,90 @@
+package go_logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const CONFIG_WATCH_DEFAULT_INTERVAL = time.Second
+
+// load config file, then reload it when it's changed or the signals are received, default SIGUSR2 (not on windows)
+// the file is checked every interval (modify time and size), 0 is default 1s, negative only reloads on signals
+// reload errors are logged and the last loaded config is kept
+// return func stops watching
+//
+// example:
+//	stop, err := logger.WatchConfig("/etc/app/logger.yaml", 0)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer stop()
+func (logger *Logger) WatchConfig(filename string, interval time.Duration, sigs ...os.Signal) (stop func(), err error) {
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = logger.LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if interval == 0 {
+		interval = CONFIG_WATCH_DEFAULT_INTERVAL
+	}
+	if len(sigs) == 0 {
+		sigs = configReloadSignals
+	}
+	sigChan := make(chan os.Signal, 1)
+	if len(sigs) > 0 {
+		signal.Notify(sigChan, sigs...)
+	}
+	var tickChan <-chan time.Time
+	var ticker *time.Ticker
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		tickChan = ticker.C
+	}
+	quit := make(chan struct{})
+	stopOnce := sync.Once{}
+
+	go func() {
+		modTime, size := fileInfo.ModTime(), fileInfo.Size()
+		for {
+			select {
+			case <-tickChan:
+				fileInfo, err := os.Stat(filename)
+				if err != nil || (fileInfo.ModTime().Equal(modTime) && fileInfo.Size() == size) {
+					continue
+				}
+				modTime, size = fileInfo.ModTime(), fileInfo.Size()
+				logger.reloadConfig(filename)
+			case <-sigChan:
+				logger.reloadConfig(filename)
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(sigChan)
+			if ticker != nil {
+				ticker.Stop()
+			}
+			close(quit)
+		})
+	}, nil
+}
+
+// reload config file, log the result
+func (logger *Logger) reloadConfig(filename string) {
+	err := logger.LoadConfig(filename)
+	if err != nil {
+		logger.write(2, LOGGER_LEVEL_ERROR, "logger: reload config "+filename+" failed, error: "+err.Error(), nil)
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_NOTICE, "logger: config "+filename+" reloaded", nil)
+}
@@ -0,0 +1,12 @@
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package go_logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// default signals of WatchConfig
+var configReloadSignals = []os.Signal{syscall.SIGUSR2}
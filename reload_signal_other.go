@@ -0,0 +1,9 @@
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package go_logger
+
+import "os"
+
+// SIGUSR2 is not supported, WatchConfig only checks the file
+var configReloadSignals = []os.Signal{}
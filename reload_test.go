@@ -0,0 +1,129 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// replace the config file by rename, the watcher never reads a half written file
+func writeTestConfigFile(filename string, data string) {
+	ioutil.WriteFile(filename+".tmp", []byte(data), 0644)
+	os.Rename(filename+".tmp", filename)
+}
+
+func TestLogger_WatchConfig(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "logger.json")
+	ioutil.WriteFile(filename, []byte(`{"level": "info", "adapters": [{"name": "memory"}]}`), 0644)
+
+	logger := NewLogger()
+	stop, err := logger.WatchConfig(filename, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer stop()
+	memoryAdapter := logger.Adapter("memory")
+	if logger.Level() != LOGGER_LEVEL_INFO || memoryAdapter == nil {
+		t.Fatal("watch config must load config")
+	}
+
+	writeTestConfigFile(filename, `{"level": "debug", "adapters": [{"name": "memory", "level": "error"}]}`)
+	time.Sleep(50 * time.Millisecond)
+	if logger.Level() != LOGGER_LEVEL_DEBUG {
+		t.Error("changed config must be reloaded")
+	}
+	if logger.Adapter("memory") != memoryAdapter {
+		t.Error("adapter of unchanged config must be kept")
+	}
+	logger.Warning("warning")
+	logger.Error("error")
+
+	writeTestConfigFile(filename, `{"level": "verbose"}`)
+	time.Sleep(50 * time.Millisecond)
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if len(entries) != 2 || entries[0].Body != "error" || entries[1].Level != LOGGER_LEVEL_ERROR {
+		t.Errorf("reload error must be logged and last config kept: %v", entries)
+	}
+}
+
+func TestLogger_ReloadConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	fileConfig := func(n int) []byte {
+		filename := path.Join(dir, "app"+strconv.Itoa(n%2)+".log")
+		return []byte(`{"adapters": [{"name": "file", "config": {"filename": "` + filename + `", "format": "%body%"}}]}`)
+	}
+
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.LoadConfigBytes(fileConfig(0), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// messages are written while reloads switch the file adapter
+	stop := make(chan struct{})
+	wait := sync.WaitGroup{}
+	written := make([]int, 4)
+	for i := 0; i < 4; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("message")
+					written[i]++
+				}
+			}
+		}(i)
+	}
+	for n := 1; n <= 50; n++ {
+		err := logger.LoadConfigBytes(fileConfig(n), CONFIG_FORMAT_JSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wait.Wait()
+	logger.Detach("file")
+
+	total := 0
+	for _, count := range written {
+		total += count
+	}
+	lines := 0
+	for n := 0; n < 2; n++ {
+		content, _ := ioutil.ReadFile(path.Join(dir, "app"+strconv.Itoa(n)+".log"))
+		lines += strings.Count(string(content), "message\n")
+	}
+	if lines != total {
+		t.Errorf("messages are lost in reloads: %d of %d written", lines, total)
+	}
+}
+
+func TestLogger_WatchConfigStopTwice(t *testing.T) {
+	dir := t.TempDir()
+	filename := path.Join(dir, "logger.json")
+	ioutil.WriteFile(filename, []byte(`{"adapters": [{"name": "memory"}]}`), 0644)
+
+	logger := NewLogger()
+	stop, err := logger.WatchConfig(filename, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	stop()
+}
@@ -0,0 +1,65 @@
+package go_logger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// reopen adapters implement LoggerReopener, return the first error
+func (logger *Logger) Reopen() error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	var reopenErr error
+	for _, output := range logger.outputs {
+		reopener, ok := output.LoggerAbstract.(LoggerReopener)
+		if !ok {
+			continue
+		}
+		err := reopener.Reopen()
+		if err != nil && reopenErr == nil {
+			reopenErr = err
+		}
+	}
+	return reopenErr
+}
+
+// reopen adapters when the signals are received, default SIGHUP
+// return func stops the signal handler, it may be called more than once
+//
+// example, logrotate postrotate script "kill -HUP <pid>":
+//	stop := logger.ReopenOnSignal()
+//	defer stop()
+func (logger *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	sigChan := make(chan os.Signal, 1)
+	quit := make(chan struct{})
+	stopOnce := sync.Once{}
+	signal.Notify(sigChan, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				err := logger.Reopen()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "logger: reopen adapters failed, error: %v\n", err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		stopOnce.Do(func() {
+			signal.Stop(sigChan)
+			close(quit)
+		})
+	}
+}
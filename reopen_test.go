@@ -0,0 +1,82 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAdapterFile_ReopenRotated(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "app.log")
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+		Filename:       filename,
+		Format:         "%body%",
+		ReopenInterval: time.Millisecond,
+	})
+
+	logger.Info("before rotate")
+	err = os.Rename(filename, filename+".1")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	time.Sleep(2 * time.Millisecond)
+	logger.Info("after rotate")
+
+	rotated, _ := ioutil.ReadFile(filename + ".1")
+	content, _ := ioutil.ReadFile(filename)
+	if strings.TrimSpace(string(rotated)) != "before rotate" || strings.TrimSpace(string(content)) != "after rotate" {
+		t.Errorf("file reopen after rotate error: %q %q", rotated, content)
+	}
+}
+
+func TestLogger_ReopenOnSignal(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "app.log")
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+		Filename:       filename,
+		Format:         "%body%",
+		ReopenInterval: -1,
+	})
+	stop := logger.ReopenOnSignal()
+	defer stop()
+
+	os.Rename(filename, filename+".1")
+	process, _ := os.FindProcess(os.Getpid())
+	if process.Signal(syscall.SIGHUP) != nil {
+		t.Skip("SIGHUP is not supported")
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(filename); err == nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	logger.Info("after signal")
+	content, _ := ioutil.ReadFile(filename)
+	if strings.TrimSpace(string(content)) != "after signal" {
+		t.Errorf("file reopen on signal error: %q", content)
+	}
+	stop()
+}
@@ -0,0 +1,173 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rotation of a log file, the file at OldPath is moved to NewPath and OldPath is recreated
+type RotateEvent struct {
+	OldPath string
+	NewPath string
+	Time    time.Time
+}
+
+// channels of NotifyRotate
+var rotateNotify = struct {
+	lock     sync.Mutex
+	channels map[chan<- RotateEvent]bool
+}{channels: map[chan<- RotateEvent]bool{}}
+
+// NotifyRotate relay rotations of all file adapters to ch, like signal.Notify events are not blocked
+// for ch, events are dropped if ch is full, use a buffered channel
+func NotifyRotate(ch chan<- RotateEvent) {
+	rotateNotify.lock.Lock()
+	defer rotateNotify.lock.Unlock()
+
+	rotateNotify.channels[ch] = true
+}
+
+// StopNotifyRotate stop relaying rotations to ch
+func StopNotifyRotate(ch chan<- RotateEvent) {
+	rotateNotify.lock.Lock()
+	defer rotateNotify.lock.Unlock()
+
+	delete(rotateNotify.channels, ch)
+}
+
+// rotations of the file writer since the last write, fired after unlock
+func (fw *FileWriter) rotated(event RotateEvent) {
+	fw.rotateEvents = append(fw.rotateEvents, event)
+}
+
+// fire OnRotate and NotifyRotate of rotations, after unlock of the file writer, OnRotate may write logs
+func fireRotateEvents(config *FileConfig, events []RotateEvent) {
+	for _, event := range events {
+		if config.OnRotate != nil {
+			fireOnRotate(config.OnRotate, event)
+		}
+		rotateNotify.lock.Lock()
+		for ch := range rotateNotify.channels {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+		rotateNotify.lock.Unlock()
+	}
+}
+
+func fireOnRotate(onRotate func(oldPath, newPath string), event RotateEvent) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: OnRotate of %s panic: %v\n", event.OldPath, err)
+		}
+	}()
+	onRotate(event.OldPath, event.NewPath)
+}
+
+// rotate files of the attached adapter now regardless of MaxSize, MaxLine and DateSlice,
+// eg: before collecting a support bundle or after config changes
+// levels are LevelFileName levels and FILE_ACCESS_LEVEL of Filename, empty is all files
+//
+// example:
+//	err := logger.RotateNow("file", go_logger.LOGGER_LEVEL_ERROR)
+func (logger *Logger) RotateNow(adapterName string, levels ...int) error {
+	logger.lock.Lock()
+	var rotator LoggerRotator
+	attached := false
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			rotator, _ = output.LoggerAbstract.(LoggerRotator)
+			attached = true
+			break
+		}
+	}
+	logger.lock.Unlock()
+
+	if !attached {
+		return errors.New("logger: adapter " + adapterName + " is not attached!")
+	}
+	if rotator == nil {
+		return errors.New("logger: adapter " + adapterName + " cannot be rotated!")
+	}
+	return rotator.RotateNow(levels...)
+}
+
+// rotate files of the levels now, empty is all files, return the first error
+func (adapterFile *AdapterFile) RotateNow(levels ...int) error {
+	fileWrites := make([]*FileWriter, 0, len(adapterFile.write))
+	if len(levels) == 0 {
+		for _, fileWrite := range adapterFile.write {
+			fileWrites = append(fileWrites, fileWrite)
+		}
+	}
+	for _, level := range levels {
+		fileWrite, ok := adapterFile.write[level]
+		if !ok {
+			return errors.New("logger: file of level " + strconv.Itoa(level) + " is not configured!")
+		}
+		fileWrites = append(fileWrites, fileWrite)
+	}
+
+	var rotateErr error
+	for _, fileWrite := range fileWrites {
+		err := fileWrite.rotateNow(adapterFile.config)
+		if err != nil && rotateErr == nil {
+			rotateErr = err
+		}
+	}
+	for _, tenantFile := range adapterFile.tenantAdapters() {
+		err := tenantFile.RotateNow(levels...)
+		if err != nil && rotateErr == nil {
+			rotateErr = err
+		}
+	}
+	return rotateErr
+}
+
+// rotate the file now, backups of files sliced only by date are numbered in the date
+// so the backup of the date slice doesn't replace them, eg: "app_20240101.1.log"
+func (fw *FileWriter) rotateNow(config *FileConfig) error {
+	return fw.rotateBefore(config, time.Time{}, false)
+}
+
+// rotate the file if it's started before t, zero t rotates it now
+// the backup of dateSliced is named by the date slice, like the rotation of a write
+func (fw *FileWriter) rotateBefore(config *FileConfig, t time.Time, dateSliced bool) error {
+	fw.lock.Lock()
+	defer func() {
+		events := fw.rotateEvents
+		fw.rotateEvents = nil
+		fw.lock.Unlock()
+		fireRotateEvents(config, events)
+	}()
+
+	if fw.multiProcess {
+		unlock, err := fw.lockProcesses()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		err = fw.syncProcesses()
+		if err != nil {
+			return err
+		}
+	}
+	if !t.IsZero() && fw.startTime >= t.Unix() {
+		return nil
+	}
+	if dateSliced {
+		return fw.rotate(config, fileSliceTimeFormats[config.DateSlice], fw.sliceStart(config))
+	}
+	if config.DateSlice != "" && config.BackupName == "" && config.MaxSize == 0 && config.MaxLine == 0 {
+		numbered := *config
+		numbered.BackupName = FILE_BACKUP_NAME_DATE_SIZE
+		config = &numbered
+	}
+	return fw.rotateBySize(config)
+}
@@ -0,0 +1,83 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileConfig_OnRotate(t *testing.T) {
+
+	rotated := [][2]string{}
+	config := &FileConfig{MaxLine: 3}
+	logger, readLog := newTestFileLogger(t, config)
+	config.OnRotate = func(oldPath, newPath string) {
+		rotated = append(rotated, [2]string{oldPath, newPath})
+		// the file is unlocked
+		logger.Info("rotated")
+	}
+	events := make(chan RotateEvent, 1)
+	NotifyRotate(events)
+	defer StopNotifyRotate(events)
+
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+
+	if len(rotated) != 1 || rotated[0][0] != config.Filename || filepath.Dir(rotated[0][1]) != filepath.Dir(config.Filename) ||
+		!strings.HasPrefix(filepath.Base(rotated[0][1]), "test.") {
+		t.Fatalf("OnRotate error: %v", rotated)
+	}
+	select {
+	case event := <-events:
+		if event.OldPath != rotated[0][0] || event.NewPath != rotated[0][1] {
+			t.Errorf("rotate event error: %+v", event)
+		}
+	default:
+		t.Error("rotate event is not notified")
+	}
+	if content := readLog(); !strings.Contains(content, "three") || !strings.Contains(content, "rotated") {
+		t.Errorf("file after rotation error: %s", content)
+	}
+}
+
+func TestLogger_RotateNow(t *testing.T) {
+
+	config := &FileConfig{DateSlice: FILE_SLICE_DATE_DAY}
+	logger, readLog := newTestFileLogger(t, config)
+	dir := filepath.Dir(config.Filename)
+
+	if err := logger.RotateNow("console"); err == nil {
+		t.Error("logger rotate now of detached adapter must be error")
+	}
+	if err := logger.RotateNow("file", LOGGER_LEVEL_ERROR); err == nil {
+		t.Error("logger rotate now of unconfigured level must be error")
+	}
+
+	logger.Info("one")
+	if err := logger.RotateNow("file"); err != nil {
+		t.Fatalf("logger rotate now error: %s", err)
+	}
+	logger.Info("two")
+	if err := logger.RotateNow("file", FILE_ACCESS_LEVEL); err != nil {
+		t.Fatalf("logger rotate now error: %s", err)
+	}
+	logger.Info("three")
+
+	files, _ := ioutil.ReadDir(dir)
+	backups := []string{}
+	for _, file := range files {
+		if file.Name() != "test.log" {
+			content, _ := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+			backups = append(backups, file.Name()+":"+strings.TrimSpace(string(content)))
+		}
+	}
+	if len(backups) != 2 || !strings.Contains(backups[0], ".1.log:") || !strings.HasSuffix(backups[0], "one") ||
+		!strings.Contains(backups[1], ".2.log:") || !strings.HasSuffix(backups[1], "two") {
+		t.Errorf("logger rotate now backups error: %v", backups)
+	}
+	if content := readLog(); !strings.Contains(content, "three") || strings.Contains(content, "two") {
+		t.Errorf("file after rotate now error: %s", content)
+	}
+}
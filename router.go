@@ -0,0 +1,555 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// router, route messages to adapters by a routing table
+//
+// one route per line (or separated by ";"), the first matched route wins:
+//
+//	route level>=error && category=="payment" -> [email, kafka]
+//	route body=~"timeout" || level==critical -> [file, api]
+//	route default -> [file]
+//
+// identifiers are level, level_string, body, file, line, function, other names are looked up in the message fields.
+// level compares by severity, "level>=error" matches error, critical, alert and emergency.
+// operators: == != > >= < <= =~ (regexp), && || ! and parentheses.
+// messages match no route and no default route are not written to any adapter.
+type Router struct {
+	routes     []*route
+	defaults   []string
+	hasDefault bool
+	routeText  string
+}
+
+type route struct {
+	expr    routeExpr
+	targets []string
+}
+
+// parse routing table
+func ParseRoutes(text string) (*Router, error) {
+	tokens, err := lexRoutes(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &routeParser{tokens: tokens}
+	router := &Router{routeText: text}
+
+	for {
+		p.skipSeparators()
+		if p.peek().kind == routeTokenEOF {
+			break
+		}
+		if !p.acceptIdent("route") {
+			return nil, p.errorf("expect 'route'")
+		}
+
+		var expr routeExpr
+		isDefault := p.acceptIdent("default")
+		if !isDefault {
+			expr, err = p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !p.accept(routeTokenArrow) {
+			return nil, p.errorf("expect '->'")
+		}
+		targets, err := p.parseTargets()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek().kind; t != routeTokenSeparator && t != routeTokenEOF {
+			return nil, p.errorf("unexpected token after route targets")
+		}
+
+		if isDefault {
+			if router.hasDefault {
+				return nil, errors.New("logger: router default route is defined twice")
+			}
+			router.hasDefault = true
+			router.defaults = targets
+			continue
+		}
+		router.routes = append(router.routes, &route{expr: expr, targets: targets})
+	}
+	return router, nil
+}
+
+// match route targets of the message
+func (router *Router) Match(loggerMsg *loggerMessage) []string {
+	for _, r := range router.routes {
+		if r.expr.eval(loggerMsg) {
+			return r.targets
+		}
+	}
+	return router.defaults
+}
+
+// routing table text
+func (router *Router) String() string {
+	return router.routeText
+}
+
+// adapters of all routes and the default route
+func (router *Router) targets() map[string]bool {
+	targets := map[string]bool{}
+	for _, r := range router.routes {
+		for _, target := range r.targets {
+			targets[target] = true
+		}
+	}
+	for _, target := range router.defaults {
+		targets[target] = true
+	}
+	return targets
+}
+
+// set message router, nil removes the router and every adapter receives all messages
+func (logger *Logger) SetRouter(router *Router) {
+	logger.router.Store(&router)
+}
+
+// parse and set routing table
+func (logger *Logger) SetRoutes(text string) error {
+	router, err := ParseRoutes(text)
+	if err != nil {
+		return err
+	}
+	logger.SetRouter(router)
+	return nil
+}
+
+// route targets of the message, routed is false if logger has no router
+func (logger *Logger) routeTargets(loggerMsg *loggerMessage) (targets []string, routed bool) {
+	router, ok := logger.router.Load().(**Router)
+	if !ok || *router == nil {
+		return nil, false
+	}
+	return (*router).Match(loggerMsg), true
+}
+
+// route expression
+
+type routeExpr interface {
+	eval(loggerMsg *loggerMessage) bool
+}
+
+type routeAnd struct {
+	left, right routeExpr
+}
+
+func (e *routeAnd) eval(loggerMsg *loggerMessage) bool {
+	return e.left.eval(loggerMsg) && e.right.eval(loggerMsg)
+}
+
+type routeOr struct {
+	left, right routeExpr
+}
+
+func (e *routeOr) eval(loggerMsg *loggerMessage) bool {
+	return e.left.eval(loggerMsg) || e.right.eval(loggerMsg)
+}
+
+type routeNot struct {
+	expr routeExpr
+}
+
+func (e *routeNot) eval(loggerMsg *loggerMessage) bool {
+	return !e.expr.eval(loggerMsg)
+}
+
+type routeCompare struct {
+	name   string
+	op     string
+	value  string
+	number float64
+	isNum  bool
+	regexp *regexp.Regexp
+}
+
+func (e *routeCompare) eval(loggerMsg *loggerMessage) bool {
+	if e.name == "level" {
+		// compare by severity, bigger is more severe
+		return compareNumber(float64(-loggerMsg.Level), e.op, -e.number)
+	}
+
+	value, ok := routeValue(loggerMsg, e.name)
+	if !ok {
+		return e.op == "!="
+	}
+	if e.regexp != nil {
+		return e.regexp.MatchString(value)
+	}
+	if e.isNum {
+		number, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return compareNumber(number, e.op, e.number)
+		}
+	}
+	switch e.op {
+	case "==":
+		return value == e.value
+	case "!=":
+		return value != e.value
+	case ">":
+		return value > e.value
+	case ">=":
+		return value >= e.value
+	case "<":
+		return value < e.value
+	case "<=":
+		return value <= e.value
+	}
+	return false
+}
+
+func compareNumber(a float64, op string, b float64) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+// message attribute or field value by name
+func routeValue(loggerMsg *loggerMessage, name string) (string, bool) {
+	switch name {
+	case "level_string":
+		return loggerMsg.LevelString, true
+	case "body":
+		return loggerMsg.Body, true
+	case "file":
+		return loggerMsg.File, true
+	case "line":
+		return strconv.Itoa(loggerMsg.Line), true
+	case "function":
+		return loggerMsg.Function, true
+	}
+	value, ok := loggerMsg.Fields[name]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// route parser
+
+type routeParser struct {
+	tokens []routeToken
+	pos    int
+}
+
+func (p *routeParser) peek() routeToken {
+	return p.tokens[p.pos]
+}
+
+func (p *routeParser) next() routeToken {
+	t := p.tokens[p.pos]
+	if t.kind != routeTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *routeParser) accept(kind int) bool {
+	if p.peek().kind == kind {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *routeParser) acceptIdent(name string) bool {
+	t := p.peek()
+	if t.kind == routeTokenIdent && t.text == name {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *routeParser) skipSeparators() {
+	for p.accept(routeTokenSeparator) {
+	}
+}
+
+// error at the next token
+func (p *routeParser) errorf(format string, a ...interface{}) error {
+	return p.errorAt(p.peek(), format, a...)
+}
+
+// error at the token t, eg: a token taken by next()
+func (p *routeParser) errorAt(t routeToken, format string, a ...interface{}) error {
+	return fmt.Errorf("logger: router parse error at line %d near %q: %s", t.line, t.text, fmt.Sprintf(format, a...))
+}
+
+func (p *routeParser) parseOr() (routeExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(routeTokenOr) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &routeOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *routeParser) parseAnd() (routeExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(routeTokenAnd) {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &routeAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *routeParser) parseUnary() (routeExpr, error) {
+	if p.accept(routeTokenNot) {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &routeNot{expr: expr}, nil
+	}
+	if p.accept(routeTokenLParen) {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(routeTokenRParen) {
+			return nil, p.errorf("expect ')'")
+		}
+		return expr, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *routeParser) parseCompare() (routeExpr, error) {
+	name := p.next()
+	if name.kind != routeTokenIdent {
+		return nil, p.errorAt(name, "expect identifier")
+	}
+	op := p.next()
+	if op.kind != routeTokenOperator {
+		return nil, p.errorAt(op, "expect compare operator")
+	}
+	value := p.next()
+	if value.kind != routeTokenIdent && value.kind != routeTokenString && value.kind != routeTokenNumber {
+		return nil, p.errorAt(value, "expect value")
+	}
+
+	expr := &routeCompare{name: name.text, op: op.text, value: value.text}
+	if op.text == "=~" {
+		if name.text == "level" {
+			return nil, p.errorAt(op, "level doesn't support =~")
+		}
+		r, err := regexp.Compile(value.text)
+		if err != nil {
+			return nil, p.errorAt(value, "invalid regexp: %v", err)
+		}
+		expr.regexp = r
+		return expr, nil
+	}
+	if name.text == "level" {
+		level, err := routeLevel(value)
+		if err != nil {
+			return nil, p.errorAt(value, "%v", err)
+		}
+		expr.number = float64(level)
+		return expr, nil
+	}
+	if value.kind == routeTokenNumber {
+		expr.number, _ = strconv.ParseFloat(value.text, 64)
+		expr.isNum = true
+	}
+	return expr, nil
+}
+
+// level value by name or number
+func routeLevel(value routeToken) (int, error) {
+	if value.kind == routeTokenNumber {
+		level, err := strconv.Atoi(value.text)
+		if err != nil || levelNames()[level] == "" {
+			return 0, errors.New("illegal level " + value.text)
+		}
+		return level, nil
+	}
+	for level, levelString := range levelNames() {
+		if strings.EqualFold(levelString, value.text) {
+			return level, nil
+		}
+	}
+	return 0, errors.New("illegal level " + value.text)
+}
+
+func (p *routeParser) parseTargets() ([]string, error) {
+	if !p.accept(routeTokenLBracket) {
+		return nil, p.errorf("expect '['")
+	}
+	targets := []string{}
+	for !p.accept(routeTokenRBracket) {
+		if len(targets) > 0 && !p.accept(routeTokenComma) {
+			return nil, p.errorf("expect ',' or ']'")
+		}
+		target := p.next()
+		if target.kind != routeTokenIdent && target.kind != routeTokenString {
+			return nil, p.errorAt(target, "expect adapter name")
+		}
+		targets = append(targets, target.text)
+	}
+	return targets, nil
+}
+
+// route lexer
+
+const (
+	routeTokenEOF = iota
+	routeTokenSeparator
+	routeTokenIdent
+	routeTokenString
+	routeTokenNumber
+	routeTokenOperator
+	routeTokenArrow
+	routeTokenAnd
+	routeTokenOr
+	routeTokenNot
+	routeTokenLParen
+	routeTokenRParen
+	routeTokenLBracket
+	routeTokenRBracket
+	routeTokenComma
+)
+
+type routeToken struct {
+	kind int
+	text string
+	line int
+}
+
+func lexRoutes(text string) ([]routeToken, error) {
+	tokens := []routeToken{}
+	line := 1
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n' || c == ';':
+			tokens = append(tokens, routeToken{kind: routeTokenSeparator, text: string(c), line: line})
+			if c == '\n' {
+				line++
+			}
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("logger: router parse error at line %d: unterminated string", line)
+			}
+			value, err := strconv.Unquote(string(runes[i : j+1]))
+			if err != nil {
+				return nil, fmt.Errorf("logger: router parse error at line %d: %v", line, err)
+			}
+			tokens = append(tokens, routeToken{kind: routeTokenString, text: value, line: line})
+			i = j + 1
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, routeToken{kind: routeTokenNumber, text: string(runes[i:j]), line: line})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '.' || runes[j] == '-') {
+				if runes[j] == '-' && j+1 < len(runes) && runes[j+1] == '>' {
+					break
+				}
+				j++
+			}
+			tokens = append(tokens, routeToken{kind: routeTokenIdent, text: string(runes[i:j]), line: line})
+			i = j
+		default:
+			kind, op := lexRouteOperator(runes[i:])
+			if op == "" {
+				return nil, fmt.Errorf("logger: router parse error at line %d: unexpected %q", line, c)
+			}
+			tokens = append(tokens, routeToken{kind: kind, text: op, line: line})
+			i += len(op)
+		}
+	}
+	tokens = append(tokens, routeToken{kind: routeTokenEOF, line: line})
+	return tokens, nil
+}
+
+// operator at the start of runes, operators are ascii
+func lexRouteOperator(runes []rune) (int, string) {
+	for _, op := range []string{"->", "&&", "||", "==", "!=", ">=", "<=", "=~"} {
+		if len(runes) >= 2 && runes[0] == rune(op[0]) && runes[1] == rune(op[1]) {
+			switch op {
+			case "->":
+				return routeTokenArrow, op
+			case "&&":
+				return routeTokenAnd, op
+			case "||":
+				return routeTokenOr, op
+			}
+			return routeTokenOperator, op
+		}
+	}
+	switch runes[0] {
+	case '>', '<':
+		return routeTokenOperator, string(runes[0])
+	case '!':
+		return routeTokenNot, "!"
+	case '(':
+		return routeTokenLParen, "("
+	case ')':
+		return routeTokenRParen, ")"
+	case '[':
+		return routeTokenLBracket, "["
+	case ']':
+		return routeTokenRBracket, "]"
+	case ',':
+		return routeTokenComma, ","
+	}
+	return 0, ""
+}
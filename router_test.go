@@ -0,0 +1,89 @@
+package go_logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRoutes(t *testing.T) {
+
+	router, err := ParseRoutes(`
+# payment errors go to email and kafka
+route level>=error && category=="payment" -> [email, kafka]
+route body=~"time(out|d out)" || (level==debug && !(line<100)) -> [api]; route default -> [file]
+`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tests := []struct {
+		loggerMsg *loggerMessage
+		targets   string
+	}{
+		{&loggerMessage{Level: LOGGER_LEVEL_CRITICAL, Fields: map[string]interface{}{"category": "payment"}}, "email,kafka"},
+		{&loggerMessage{Level: LOGGER_LEVEL_WARNING, Fields: map[string]interface{}{"category": "payment"}}, "file"},
+		{&loggerMessage{Level: LOGGER_LEVEL_ERROR}, "file"},
+		{&loggerMessage{Level: LOGGER_LEVEL_INFO, Body: "read timed out"}, "api"},
+		{&loggerMessage{Level: LOGGER_LEVEL_DEBUG, Line: 120}, "api"},
+		{&loggerMessage{Level: LOGGER_LEVEL_DEBUG, Line: 20}, "file"},
+	}
+	for i, test := range tests {
+		targets := strings.Join(router.Match(test.loggerMsg), ",")
+		if targets != test.targets {
+			t.Errorf("route %d match error: %s", i, targets)
+		}
+	}
+
+	for _, text := range []string{
+		`route level>=fatal -> [file]`,
+		`route level>=error [file]`,
+		`route default -> [file]; route default -> [api]`,
+		`route body=~"(" -> [file]`,
+		`level>=error -> [file]`,
+	} {
+		_, err := ParseRoutes(text)
+		if err == nil {
+			t.Errorf("parse routes %s should be failed", text)
+		}
+	}
+
+	// errors are reported at the failed token
+	for text, near := range map[string]string{
+		`route level>=fatal -> [file]`:    `near "fatal"`,
+		`route body=~"(" -> [file]`:       `near "("`,
+		`route body -> [file]`:            `near "->"`,
+		`route level=~"e" -> [file]`:      `near "=~"`,
+		`route level>=error -> [file, >]`: `near ">"`,
+	} {
+		_, err := ParseRoutes(text)
+		if err == nil || !strings.Contains(err.Error(), near) {
+			t.Errorf("parse routes %s error isn't %s: %v", text, near, err)
+		}
+	}
+}
+
+func BenchmarkParseRoutes(b *testing.B) {
+	text := strings.Repeat("route level>=error && body=~\"timeout\" -> [file, api]\n", 1000)
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseRoutes(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestLogger_SetRoutes(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, nil)
+	err := logger.SetRoutes(`route category=="payment" -> [file]`)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logger.WriterFields(LOGGER_LEVEL_INFO, "paid", map[string]interface{}{"category": "payment"})
+	logger.Info("not routed")
+
+	content := readLog()
+	if !strings.Contains(content, "paid") || strings.Contains(content, "not routed") {
+		t.Error("logger routes error: " + content)
+	}
+}
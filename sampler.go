@@ -0,0 +1,124 @@
+package go_logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampling rule of a level
+type SamplingRule struct {
+
+	// the first messages of every tick are written
+	First int
+
+	// then 1 in Thereafter messages is written, 0 drops the rest of the tick
+	Thereafter int
+}
+
+// logger sampler, limit written messages of a level in every tick
+//
+// example, the first 100 debug messages per second, then 1 in 10:
+//	logger.SetSampler(go_logger.NewSampler(time.Second, map[int]go_logger.SamplingRule{
+//		go_logger.LOGGER_LEVEL_DEBUG: {First: 100, Thereafter: 10},
+//	}))
+type Sampler struct {
+	tick  time.Duration
+	rules map[int]SamplingRule
+
+	lock     sync.Mutex
+	counters map[int]*samplerCounter
+	dropped  int64
+}
+
+type samplerCounter struct {
+	start time.Time
+	count int
+}
+
+// new sampler, tick default 1s, levels without rule are not sampled
+func NewSampler(tick time.Duration, rules map[int]SamplingRule) *Sampler {
+	if tick <= 0 {
+		tick = time.Second
+	}
+	return &Sampler{
+		tick:     tick,
+		rules:    rules,
+		counters: map[int]*samplerCounter{},
+	}
+}
+
+// message is sampled to write
+func (sampler *Sampler) sample(loggerMsg *loggerMessage) bool {
+	rule, ok := sampler.rules[loggerMsg.Level]
+	if !ok {
+		return true
+	}
+
+	now := time.Now()
+	sampler.lock.Lock()
+	counter := sampler.counters[loggerMsg.Level]
+	if counter == nil || now.Sub(counter.start) >= sampler.tick {
+		counter = &samplerCounter{start: now}
+		sampler.counters[loggerMsg.Level] = counter
+	}
+	counter.count++
+	count := counter.count
+	sampler.lock.Unlock()
+
+	if count <= rule.First {
+		return true
+	}
+	if rule.Thereafter > 0 && (count-rule.First)%rule.Thereafter == 0 {
+		return true
+	}
+	atomic.AddInt64(&sampler.dropped, 1)
+	return false
+}
+
+// dropped messages count
+func (sampler *Sampler) Dropped() int64 {
+	return atomic.LoadInt64(&sampler.dropped)
+}
+
+// set logger sampler of all adapters, nil disables sampling
+func (logger *Logger) SetSampler(sampler *Sampler) {
+	logger.sampler.Store(&sampler)
+}
+
+// set sampler of the adapter, nil disables sampling
+// adapter sampler applies after logger sampler, router and adapter level
+func (logger *Logger) SetAdapterSampler(adapterName string, sampler *Sampler) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.sampler.Store(&sampler)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// logger sampler samples the message
+func (logger *Logger) sample(loggerMsg *loggerMessage) bool {
+	sampler, ok := logger.sampler.Load().(**Sampler)
+	if !ok || *sampler == nil {
+		return true
+	}
+	return (*sampler).sample(loggerMsg)
+}
+
+// output sampler samples the message
+func (output *outputLogger) sample(loggerMsg *loggerMessage) bool {
+	if loggerMsg.verbose {
+		return true
+	}
+	sampler, ok := output.sampler.Load().(**Sampler)
+	if !ok || *sampler == nil {
+		return true
+	}
+	return (*sampler).sample(loggerMsg)
+}
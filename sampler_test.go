@@ -0,0 +1,61 @@
+package go_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogger_SetSampler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	sampler := NewSampler(time.Hour, map[int]SamplingRule{
+		LOGGER_LEVEL_DEBUG: {First: 3, Thereafter: 5},
+	})
+	logger.SetSampler(sampler)
+	for i := 0; i < 20; i++ {
+		logger.Debug("sampled debug")
+		logger.Info("info")
+	}
+
+	debugCount := 0
+	for _, entry := range memoryAdapter.Entries() {
+		if entry.Level == LOGGER_LEVEL_DEBUG {
+			debugCount++
+		}
+	}
+	// first 3, then the 8th, 13th, 18th
+	if debugCount != 6 || len(memoryAdapter.Entries()) != 26 {
+		t.Errorf("logger sampler count error: debug=%d total=%d", debugCount, len(memoryAdapter.Entries()))
+	}
+	if sampler.Dropped() != 14 {
+		t.Errorf("logger sampler dropped error: %d", sampler.Dropped())
+	}
+}
+
+func TestLogger_SetAdapterSampler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	if logger.SetAdapterSampler("file", NewSampler(0, nil)) == nil {
+		t.Error("logger adapter sampler of detached adapter must error")
+	}
+	logger.SetAdapterSampler("memory", NewSampler(20*time.Millisecond, map[int]SamplingRule{
+		LOGGER_LEVEL_INFO: {First: 1},
+	}))
+	logger.Info("info 1")
+	logger.Info("info 2")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info("info 3")
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 2 || entries[0].Body != "info 1" || entries[1].Body != "info 3" {
+		t.Errorf("logger adapter sampler error: %v", entries)
+	}
+}
@@ -0,0 +1,77 @@
+package go_logger
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ansi escape sequences, CSI "\x1b[31m" and OSC "\x1b]0;title\x07"
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// truncate s to at most max bytes without splitting a rune
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	end := max
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
+
+// truncate b to at most max bytes without splitting a rune
+func truncateBytes(b []byte, max int) []byte {
+	return []byte(truncateString(string(b), max))
+}
+
+// remove the incomplete rune at the end of b
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// strip ansi escapes and control characters except "\t" and "\n", invalid utf-8 is replaced by U+FFFD
+func SanitizeString(s string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	if strings.IndexByte(s, 0x1b) >= 0 {
+		s = ansiEscapeRegexp.ReplaceAllString(s, "")
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' {
+			return r
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// sanitized copy of the message, body and string fields are sanitized
+func sanitizeLoggerMessage(loggerMsg *loggerMessage) *loggerMessage {
+	sanitized := *loggerMsg
+	sanitized.Body = SanitizeString(loggerMsg.Body)
+	if len(loggerMsg.Fields) > 0 {
+		sanitized.Fields = make(map[string]interface{}, len(loggerMsg.Fields))
+		for key, value := range loggerMsg.Fields {
+			if str, ok := value.(string); ok {
+				value = SanitizeString(str)
+			}
+			sanitized.Fields[SanitizeString(key)] = value
+		}
+	}
+	return &sanitized
+}
@@ -0,0 +1,52 @@
+package go_logger
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeString(t *testing.T) {
+
+	texts := map[string]string{
+		"\x1b[31mred\x1b[0m text":         "red text",
+		"\x1b]0;title\x07title":           "title",
+		"line1\nline2\ttab\r\x00\x07bell": "line1\nline2\ttabbell",
+		"bad \xff utf8":                   "bad � utf8",
+		"日志":                              "日志",
+	}
+	for text, expected := range texts {
+		if SanitizeString(text) != expected {
+			t.Errorf("sanitize %q error: %q", text, SanitizeString(text))
+		}
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+
+	for max := 0; max <= 7; max++ {
+		truncated := truncateString("a日志b", max)
+		if !utf8.ValidString(truncated) || len(truncated) > max {
+			t.Errorf("truncate %d error: %q", max, truncated)
+		}
+	}
+	if string(trimPartialRune([]byte("a日志"[:5]))) != "a日" {
+		t.Error("trim partial rune error")
+	}
+	if string(trimPartialRune([]byte("a日志"))) != "a日志" {
+		t.Error("trim partial rune must keep full runes")
+	}
+}
+
+func TestAdapterWriter_StripControl(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "%body% %fields%", StripControl: true})
+
+	logger.WriterFields(LOGGER_LEVEL_INFO, "\x1b[1mbold\x1b[0m\r", map[string]interface{}{"user": "go\x1b[2J"})
+	if buffer.String() != "bold user=go\n" {
+		t.Errorf("writer strip control error: %q", buffer.String())
+	}
+}
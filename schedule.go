@@ -0,0 +1,215 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors of RotateCron
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cron expression "minute hour day month weekday", every field is a bit set of the values
+type cronSchedule struct {
+	minute  uint64
+	hour    uint64
+	day     uint64
+	month   uint64
+	weekday uint64
+
+	// day and weekday are not "*", the time matches either of them
+	dayOrWeekday bool
+}
+
+// parse the cron expression, fields are numbers, "*", ranges "1-5", lists "0,30" and steps "*/15"
+// weekday 0 and 7 are Sunday, descriptors "@daily", "@hourly" etc are supported
+func parseCron(expr string) (*cronSchedule, error) {
+	if descriptor, ok := cronDescriptors[strings.TrimSpace(expr)]; ok {
+		expr = descriptor
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.New("cron expression must be 5 fields \"minute hour day month weekday\"")
+	}
+	schedule := &cronSchedule{}
+	var err error
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := [5]*uint64{&schedule.minute, &schedule.hour, &schedule.day, &schedule.month, &schedule.weekday}
+	for i, field := range fields {
+		*sets[i], err = parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	// 7 is Sunday
+	if schedule.weekday&(1<<7) != 0 {
+		schedule.weekday = schedule.weekday&^(1<<7) | 1
+	}
+	schedule.dayOrWeekday = fields[2] != "*" && fields[4] != "*"
+	return schedule, nil
+}
+
+// bit set of the values of the field
+func parseCronField(field string, min int, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, errors.New("cron step " + part + " is illegal")
+			}
+			step = n
+			part = part[:i]
+		}
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			n, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, errors.New("cron value " + part + " is illegal")
+			}
+			start, end = n, n
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, errors.New("cron value " + part + " is illegal")
+				}
+			} else if step > 1 {
+				// "5/15" is "5-max/15"
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("cron value %s is out of range %d-%d", part, min, max)
+		}
+		for value := start; value <= end; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+	return set, nil
+}
+
+// first time after t the schedule matches, zero time if it never matches, eg: "0 0 31 2 *"
+func (schedule *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if schedule.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !schedule.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if schedule.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (schedule *cronSchedule) matchDay(t time.Time) bool {
+	day := schedule.day&(1<<uint(t.Day())) != 0
+	weekday := schedule.weekday&(1<<uint(t.Weekday())) != 0
+	if schedule.dayOrWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// rotation schedule of the file adapter
+type fileSchedule struct {
+
+	// next rotation after t
+	next func(t time.Time) time.Time
+
+	// backups are named by the date slice, like rotations of writes
+	dateSliced bool
+}
+
+// schedule of ScheduledRotate or RotateCron, nil if files are rotated by writes only
+func newFileSchedule(config *FileConfig) (*fileSchedule, error) {
+	if config.RotateCron != "" {
+		cron, err := parseCron(config.RotateCron)
+		if err != nil {
+			return nil, configErrorCause(FILE_ADAPTER_NAME, "RotateCron", "is illegal", err)
+		}
+		return &fileSchedule{next: cron.next}, nil
+	}
+	if config.ScheduledRotate {
+		dateSlice, weekStartDay := config.DateSlice, config.WeekStart
+		return &fileSchedule{next: func(t time.Time) time.Time {
+			return dateSliceNext(dateSlice, weekStartDay, t)
+		}, dateSliced: true}, nil
+	}
+	return nil, nil
+}
+
+// start of the next date slice after t
+func dateSliceNext(dateSlice string, weekStartDay time.Weekday, t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch dateSlice {
+	case FILE_SLICE_DATE_YEAR:
+		return time.Date(year+1, 1, 1, 0, 0, 0, 0, t.Location())
+	case FILE_SLICE_DATE_MONTH:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, t.Location())
+	case FILE_SLICE_DATE_HOUR:
+		return time.Date(year, month, day, t.Hour()+1, 0, 0, 0, t.Location())
+	case FILE_SLICE_DATE_WEEK:
+		return weekStart(t, weekStartDay).AddDate(0, 0, 7)
+	}
+	return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+}
+
+// rotate the files at every time of the schedule
+func (adapterFile *AdapterFile) startSchedule(schedule *fileSchedule, quit chan struct{}) {
+	for {
+		next := schedule.next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			err := adapterFile.rotateSchedule(schedule, next)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "logger: scheduled rotation failed, error: %v\n", err)
+			}
+		case <-quit:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// rotate the files started before the scheduled time, files rotated by writes after it are not rotated again
+// return the first error
+func (adapterFile *AdapterFile) rotateSchedule(schedule *fileSchedule, scheduled time.Time) error {
+	var rotateErr error
+	for _, fileWrite := range adapterFile.write {
+		err := fileWrite.rotateBefore(adapterFile.config, scheduled, schedule.dateSliced)
+		if err != nil && rotateErr == nil {
+			rotateErr = err
+		}
+	}
+	return rotateErr
+}
@@ -0,0 +1,123 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+
+	base := time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC) // Monday
+	tests := map[string]time.Time{
+		"* * * * *":    time.Date(2024, 1, 1, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *": time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC),
+		"0 */6 * * *":  time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		"30 2 * * 1-5": time.Date(2024, 1, 2, 2, 30, 0, 0, time.UTC),
+		"0 0 * * 7":    time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		"0 0 15 * 0":   time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		"5,10 9 1 2 *": time.Date(2024, 2, 1, 9, 5, 0, 0, time.UTC),
+		"@daily":       time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		"@hourly":      time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":   time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 31 2 *":   {},
+	}
+	for expr, next := range tests {
+		schedule, err := parseCron(expr)
+		if err != nil {
+			t.Errorf("parse cron %s error: %s", expr, err)
+			continue
+		}
+		if !schedule.next(base).Equal(next) {
+			t.Errorf("cron %s next must be %s: %s", expr, next, schedule.next(base))
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parse cron %s must be error", expr)
+		}
+	}
+}
+
+func TestDateSliceNext(t *testing.T) {
+
+	base := time.Date(2024, 1, 3, 10, 7, 0, 0, time.UTC) // Wednesday
+	tests := map[string]time.Time{
+		FILE_SLICE_DATE_YEAR:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		FILE_SLICE_DATE_MONTH: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		FILE_SLICE_DATE_DAY:   time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+		FILE_SLICE_DATE_HOUR:  time.Date(2024, 1, 3, 11, 0, 0, 0, time.UTC),
+		FILE_SLICE_DATE_WEEK:  time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+	}
+	for dateSlice, next := range tests {
+		if !dateSliceNext(dateSlice, time.Monday, base).Equal(next) {
+			t.Errorf("date slice %s next must be %s: %s", dateSlice, next, dateSliceNext(dateSlice, time.Monday, base))
+		}
+	}
+}
+
+func TestAdapterFile_ScheduledRotate(t *testing.T) {
+
+	config := &FileConfig{Format: "%body%", DateSlice: FILE_SLICE_DATE_DAY, ScheduledRotate: true}
+	logger, readLog := newTestFileLogger(t, config)
+	dir := path.Dir(config.Filename)
+	fileAdapter := logger.Adapter("file").(*AdapterFile)
+	if fileAdapter.schedule == nil || !fileAdapter.schedule.dateSliced {
+		t.Fatal("file schedule must be set by ScheduledRotate")
+	}
+
+	logger.Info("yesterday")
+	ch := make(chan RotateEvent, 1)
+	NotifyRotate(ch)
+	defer StopNotifyRotate(ch)
+
+	// a rotation is scheduled in the next second, nothing is written
+	quit := make(chan struct{})
+	scheduled := time.Now().Truncate(time.Second).Add(time.Second)
+	go fileAdapter.startSchedule(&fileSchedule{next: func(t time.Time) time.Time {
+		if t.Before(scheduled) {
+			return scheduled
+		}
+		return t.Add(time.Hour)
+	}, dateSliced: true}, quit)
+
+	select {
+	case event := <-ch:
+		if event.NewPath != path.Join(dir, "test_"+time.Now().Format("20060102")+".log") {
+			t.Errorf("scheduled rotation backup error: %s", event.NewPath)
+		}
+		content, _ := ioutil.ReadFile(event.NewPath)
+		if string(content) != "yesterday\n" || readLog() != "" {
+			t.Errorf("scheduled rotation error: %q", content)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("file is not rotated by the schedule")
+	}
+	close(quit)
+
+	// the file started after the scheduled time is not rotated again
+	if err := fileAdapter.rotateSchedule(fileAdapter.schedule, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-ch:
+		t.Errorf("file started after the scheduled time must not be rotated: %v", event)
+	default:
+	}
+}
+
+func TestAdapterFile_InitSchedule(t *testing.T) {
+
+	configs := map[string]*FileConfig{
+		"config ScheduledRotate must be used with DateSlice and without RotateCron!": {Filename: "test.log", ScheduledRotate: true},
+		"config RotateCron is illegal, error: cron value 24 is out of range 0-23":    {Filename: "test.log", RotateCron: "0 24 * * *"},
+	}
+	for message, config := range configs {
+		err := NewAdapterFile().Init(config)
+		if err == nil || err.Error() != message {
+			t.Errorf("file init %v error: %v", config, err)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// version of AdapterV1, adapters of other versions are registered by their own Register functions
+const ADAPTER_SDK_VERSION = 1
+
+// entries buffered by adapters writing batches, unless BatchSize() is positive
+const ADAPTER_SDK_BATCH_SIZE = 100
+
+// buffered entries of batchers are written every interval, unless FlushInterval() is positive
+const ADAPTER_SDK_FLUSH_INTERVAL = time.Second
+
+// adapter written out of the tree, registered by RegisterAdapter() or attached by AttachAdapter(name, level, WrapAdapter(adapter))
+//
+// lifecycle:
+//	Init is called once before any other method, an error fails Attach
+//	Write, WriteBatch, Flush and Close are never called concurrently, adapters need no locks
+//	Flush is called by Logger.Flush() and before Close, Close is called once by Logger.Close()
+//	write errors are reported to the error handler, fallback and spool of the adapter
+//	entries and their fields are read only, they may be kept after Write returns
+type AdapterV1 interface {
+	Name() string
+	Init(config Config) error
+	Write(entry *LogEntry) error
+	Flush() error
+	Close() error
+}
+
+// adapter writing entries in batches, optional
+// entries are buffered up to BatchSize() and written when it's full, every flush interval and by Flush
+// the error of a batch is reported with every entry of the batch, the batch is not written again
+// entries are delivered to the wal and the spool when their batch is written
+type AdapterV1Batcher interface {
+	WriteBatch(entries []*LogEntry) error
+	BatchSize() int
+}
+
+// flush interval of batchers, optional, default ADAPTER_SDK_FLUSH_INTERVAL
+type AdapterV1FlushInterval interface {
+	FlushInterval() time.Duration
+}
+
+// capabilities of AdapterV1 are reported by the optional LoggerCapabilities, eg: Remote of adapters writing over the network
+// batchers are Batching and NeedsFlush
+//
+// example:
+//	func init() {
+//		go_logger.RegisterAdapter("kafka", func() go_logger.AdapterV1 {
+//			return &KafkaAdapter{}
+//		})
+//	}
+func RegisterAdapter(adapterName string, newAdapter func() AdapterV1) {
+	if newAdapter == nil {
+		panic("logger: logger adapter " + adapterName + " is nil!")
+	}
+	Register(adapterName, func() LoggerAbstract {
+		return WrapAdapter(newAdapter())
+	})
+}
+
+// adapter of the logger writing to the AdapterV1
+func WrapAdapter(adapter AdapterV1) LoggerAbstract {
+	sdk := &sdkAdapter{adapter: adapter}
+	sdk.batcher, _ = adapter.(AdapterV1Batcher)
+	return sdk
+}
+
+// adapter buffering messages, messages are delivered when their buffer is written
+type loggerBuffer interface {
+	// errors of buffered messages are reported by report
+	setErrorReport(report func(loggerMsg *loggerMessage, err error))
+
+	// write buffered messages, the first error of buffers written since the last call
+	writeBuffered() error
+}
+
+// AdapterV1 wrapped as LoggerAbstract, calls are serialized by lock
+type sdkAdapter struct {
+	lock     sync.Mutex
+	adapter  AdapterV1
+	batcher  AdapterV1Batcher
+	batch    []*LogEntry
+	messages []*loggerMessage // messages of the batch, their wal records are retained until it's written
+	err      error            // first batch error since the last writeBuffered
+	report   func(loggerMsg *loggerMessage, err error)
+	ticker   *time.Ticker
+	quit     chan struct{}
+	closed   bool
+}
+
+func (sdk *sdkAdapter) Name() string {
+	return sdk.adapter.Name()
+}
+
+func (sdk *sdkAdapter) Init(config Config) error {
+	sdk.lock.Lock()
+	defer sdk.lock.Unlock()
+	err := sdk.adapter.Init(config)
+	if err != nil || sdk.batcher == nil || sdk.ticker != nil {
+		return err
+	}
+	sdk.ticker = time.NewTicker(sdk.flushInterval())
+	sdk.quit = make(chan struct{})
+	go sdk.startFlush(sdk.ticker, sdk.quit)
+	return nil
+}
+
+// write messages to the adapter, messages of batchers are buffered and nil is returned, errors of their batch are reported later
+func (sdk *sdkAdapter) Write(loggerMsg *loggerMessage) error {
+	entry := loggerMsg.Entry()
+
+	sdk.lock.Lock()
+	if sdk.closed {
+		sdk.lock.Unlock()
+		return errors.New("logger: adapter " + sdk.adapter.Name() + " is closed")
+	}
+	if sdk.batcher == nil {
+		defer sdk.lock.Unlock()
+		return sdk.adapter.Write(&entry)
+	}
+	loggerMsg.retainWAL()
+	sdk.batch = append(sdk.batch, &entry)
+	sdk.messages = append(sdk.messages, loggerMsg)
+	if len(sdk.batch) < sdk.batchSize() {
+		sdk.lock.Unlock()
+		return nil
+	}
+	messages, err := sdk.writeBatch()
+	sdk.lock.Unlock()
+
+	sdk.done(messages, err)
+	return nil
+}
+
+// write buffered entries after lock, messages of the batch are done after unlock
+func (sdk *sdkAdapter) writeBatch() ([]*loggerMessage, error) {
+	if len(sdk.batch) == 0 {
+		return nil, nil
+	}
+	batch, messages := sdk.batch, sdk.messages
+	sdk.batch, sdk.messages = nil, nil
+	err := sdk.batcher.WriteBatch(batch)
+	if err != nil && sdk.err == nil {
+		sdk.err = err
+	}
+	return messages, err
+}
+
+// release wal records of the written batch, the error of the batch is reported with every message
+func (sdk *sdkAdapter) done(messages []*loggerMessage, err error) {
+	bufferDone(sdk.adapter.Name(), sdk.report, messages, err)
+}
+
+// release wal records of the written buffer, the error of the buffer is reported with every message
+// messages are printed to stderr if the adapter isn't attached
+func bufferDone(adapterName string, report func(loggerMsg *loggerMessage, err error), loggerMsgs []*loggerMessage, err error) {
+	for _, loggerMsg := range loggerMsgs {
+		if err != nil {
+			if report != nil {
+				report(loggerMsg, err)
+			} else {
+				fmt.Fprintf(os.Stderr, "logger: unable write loggerMessage to adapter:%v, error: %v\n", adapterName, err)
+			}
+		}
+		loggerMsg.releaseWAL(err == nil)
+	}
+}
+
+func (sdk *sdkAdapter) setErrorReport(report func(loggerMsg *loggerMessage, err error)) {
+	sdk.lock.Lock()
+	defer sdk.lock.Unlock()
+	sdk.report = report
+}
+
+func (sdk *sdkAdapter) writeBuffered() error {
+	sdk.lock.Lock()
+	messages, err := sdk.writeBatch()
+	batchErr := sdk.err
+	sdk.err = nil
+	sdk.lock.Unlock()
+
+	sdk.done(messages, err)
+	return batchErr
+}
+
+func (sdk *sdkAdapter) batchSize() int {
+	if size := sdk.batcher.BatchSize(); size > 0 {
+		return size
+	}
+	return ADAPTER_SDK_BATCH_SIZE
+}
+
+func (sdk *sdkAdapter) flushInterval() time.Duration {
+	if flusher, ok := sdk.adapter.(AdapterV1FlushInterval); ok {
+		if interval := flusher.FlushInterval(); interval > 0 {
+			return interval
+		}
+	}
+	return ADAPTER_SDK_FLUSH_INTERVAL
+}
+
+// flush buffered entries every flush interval
+func (sdk *sdkAdapter) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			sdk.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+func (sdk *sdkAdapter) Flush() {
+	sdk.lock.Lock()
+	if sdk.closed {
+		sdk.lock.Unlock()
+		return
+	}
+	var messages []*loggerMessage
+	var batchErr error
+	if sdk.batcher != nil {
+		messages, batchErr = sdk.writeBatch()
+	}
+	// the adapter isn't flushed after a failed batch
+	var err error
+	if batchErr == nil {
+		err = sdk.adapter.Flush()
+	}
+	sdk.lock.Unlock()
+
+	sdk.done(messages, batchErr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: adapter %s flush failed, error: %v\n", sdk.adapter.Name(), err)
+	}
+}
+
+func (sdk *sdkAdapter) Close() error {
+	sdk.lock.Lock()
+	if sdk.closed {
+		sdk.lock.Unlock()
+		return nil
+	}
+	sdk.closed = true
+	if sdk.ticker != nil {
+		sdk.ticker.Stop()
+		close(sdk.quit)
+		sdk.ticker = nil
+	}
+	// the logger flushes adapters before they're closed, buffered entries are written if Flush isn't called
+	var messages []*loggerMessage
+	var batchErr error
+	if sdk.batcher != nil {
+		messages, batchErr = sdk.writeBatch()
+	}
+	err := sdk.adapter.Close()
+	sdk.lock.Unlock()
+
+	sdk.done(messages, batchErr)
+	return err
+}
+
+func (sdk *sdkAdapter) Capabilities() Capabilities {
+	var capabilities Capabilities
+	if reporter, ok := sdk.adapter.(LoggerCapabilities); ok {
+		capabilities = reporter.Capabilities()
+	}
+	if sdk.batcher != nil {
+		capabilities.Batching = true
+		capabilities.NeedsFlush = true
+	}
+	return capabilities
+}
+
+// text of the entry by the format of placeholders, eg: "%timestamp_format% [%level_string%] %body%"
+// placeholders of the logger, eg: %sequence% and %hostname%, are empty
+func (entry *LogEntry) Text(format string) string {
+	return loggerMessageFormat(format, entry.loggerMessage())
+}
+
+// json record of the entry, the same as records of JsonFormat files
+func (entry *LogEntry) Json() ([]byte, error) {
+	return entry.loggerMessage().MarshalJSON()
+}
@@ -0,0 +1,249 @@
+package go_logger
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const sdkTestAdapterName = "sdk_test"
+
+type sdkTestConfig struct {
+	BatchSize int
+}
+
+func (config *sdkTestConfig) Name() string {
+	return sdkTestAdapterName
+}
+
+// adapter of the SDK recording writes, batches and lifecycle calls
+type sdkTestAdapter struct {
+	batchSize int
+	calls     []string
+	entries   []*LogEntry
+}
+
+func (adapter *sdkTestAdapter) Name() string {
+	return sdkTestAdapterName
+}
+
+func (adapter *sdkTestAdapter) Init(config Config) error {
+	sdkConfig, ok := config.(*sdkTestConfig)
+	if !ok || sdkConfig == nil {
+		return errors.New("config must sdkTestConfig")
+	}
+	adapter.batchSize = sdkConfig.BatchSize
+	adapter.calls = append(adapter.calls, "init")
+	return nil
+}
+
+func (adapter *sdkTestAdapter) Write(entry *LogEntry) error {
+	adapter.calls = append(adapter.calls, "write")
+	adapter.entries = append(adapter.entries, entry)
+	return nil
+}
+
+func (adapter *sdkTestAdapter) Flush() error {
+	adapter.calls = append(adapter.calls, "flush")
+	return nil
+}
+
+func (adapter *sdkTestAdapter) Close() error {
+	adapter.calls = append(adapter.calls, "close")
+	return nil
+}
+
+func (adapter *sdkTestAdapter) Capabilities() Capabilities {
+	return Capabilities{Remote: true}
+}
+
+type sdkTestBatcher struct {
+	sdkTestAdapter
+}
+
+func (adapter *sdkTestBatcher) WriteBatch(entries []*LogEntry) error {
+	adapter.calls = append(adapter.calls, "batch")
+	adapter.entries = append(adapter.entries, entries...)
+	return nil
+}
+
+func (adapter *sdkTestBatcher) BatchSize() int {
+	return adapter.batchSize
+}
+
+func TestRegisterAdapter(t *testing.T) {
+
+	adapter := &sdkTestAdapter{}
+	RegisterAdapter(sdkTestAdapterName, func() AdapterV1 {
+		return adapter
+	})
+	defer func() {
+		registryLock.Lock()
+		delete(adapters, sdkTestAdapterName)
+		registryLock.Unlock()
+	}()
+
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach(sdkTestAdapterName, LOGGER_LEVEL_DEBUG, &sdkTestConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WriterFields(LOGGER_LEVEL_WARNING, "sdk", map[string]interface{}{"id": 1})
+	if len(adapter.entries) != 1 || adapter.entries[0].Body != "sdk" || adapter.entries[0].Level != LOGGER_LEVEL_WARNING || adapter.entries[0].Fields["id"] != 1 {
+		t.Errorf("sdk adapter write error: %v", adapter.entries)
+	}
+	if capabilities := logger.AdapterCapabilities(sdkTestAdapterName); !capabilities.Remote || capabilities.Batching {
+		t.Errorf("sdk adapter capabilities error: %v", capabilities)
+	}
+
+	logger.Close(context.Background())
+	if strings.Join(adapter.calls, ",") != "init,write,flush,close" {
+		t.Errorf("sdk adapter lifecycle error: %v", adapter.calls)
+	}
+	logger = NewLogger()
+	if err := logger.Attach(sdkTestAdapterName, LOGGER_LEVEL_DEBUG, &FileConfig{}); err == nil {
+		t.Error("sdk adapter init error is not returned")
+	}
+}
+
+func TestWrapAdapter_Batch(t *testing.T) {
+
+	batcher := &sdkTestBatcher{}
+	adapter := WrapAdapter(batcher)
+	err := adapter.Init(&sdkTestConfig{BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capabilities := adapter.(LoggerCapabilities).Capabilities(); !capabilities.Batching || !capabilities.NeedsFlush || !capabilities.Remote {
+		t.Errorf("sdk batcher capabilities error: %v", capabilities)
+	}
+
+	for _, body := range []string{"a", "b", "c"} {
+		err = adapter.Write(newLoggerMessage(time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), LOGGER_LEVEL_INFO, body, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(batcher.entries) != 2 {
+		t.Errorf("sdk batch is not written when it's full: %v", batcher.calls)
+	}
+	adapter.(LoggerCloser).Close()
+	if len(batcher.entries) != 3 || strings.Join(batcher.calls, ",") != "init,batch,batch,close" {
+		t.Errorf("sdk batch is not written by close: %v", batcher.calls)
+	}
+	if adapter.Write(newLoggerMessage(time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), LOGGER_LEVEL_INFO, "closed", nil)) == nil {
+		t.Error("write of closed sdk adapter must error")
+	}
+}
+
+// batcher of the SDK failing batches while it's down, written batches are sent to written
+type sdkTestSwitchBatcher struct {
+	sdkTestBatcher
+	lock     sync.Mutex
+	down     bool
+	interval time.Duration
+	written  chan []*LogEntry
+}
+
+func (adapter *sdkTestSwitchBatcher) WriteBatch(entries []*LogEntry) error {
+	adapter.lock.Lock()
+	defer adapter.lock.Unlock()
+	if adapter.down {
+		return errors.New("batch failed")
+	}
+	adapter.written <- entries
+	return nil
+}
+
+func (adapter *sdkTestSwitchBatcher) FlushInterval() time.Duration {
+	return adapter.interval
+}
+
+func (adapter *sdkTestSwitchBatcher) setDown(down bool) {
+	adapter.lock.Lock()
+	defer adapter.lock.Unlock()
+	adapter.down = down
+}
+
+func TestWrapAdapter_BatchDelivery(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger-sdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	batcher := &sdkTestSwitchBatcher{down: true, interval: 20 * time.Millisecond, written: make(chan []*LogEntry, 10)}
+	adapter := WrapAdapter(batcher)
+	err = adapter.Init(&sdkTestConfig{BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lock sync.Mutex
+	failed := []string{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAdapter(sdkTestAdapterName, LOGGER_LEVEL_DEBUG, adapter)
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+		lock.Lock()
+		defer lock.Unlock()
+		failed = append(failed, loggerMsg.Body)
+	})
+	err = logger.SetWAL(&WALConfig{Filename: filepath.Join(dir, "app.wal")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close(context.Background())
+
+	// buffered messages are not delivered, the error of the batch is reported with every message
+	logger.Info("a")
+	if logger.WALPending() != 1 {
+		t.Errorf("buffered sdk message is delivered: %d", logger.WALPending())
+	}
+	logger.Info("b")
+	lock.Lock()
+	if strings.Join(failed, ",") != "a,b" {
+		t.Errorf("sdk batch error is not reported with every message: %v", failed)
+	}
+	lock.Unlock()
+	if logger.WALPending() != 2 {
+		t.Errorf("failed sdk batch is delivered: %d", logger.WALPending())
+	}
+
+	// buffered messages are written every flush interval
+	batcher.setDown(false)
+	logger.Info("c")
+	select {
+	case entries := <-batcher.written:
+		if len(entries) != 1 || entries[0].Body != "c" {
+			t.Errorf("sdk batch of the flush interval error: %v", entries)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("sdk batch is not written every flush interval")
+	}
+	for i := 0; i < 100 && logger.WALPending() != 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.WALPending() != 2 {
+		t.Errorf("written sdk batch is not delivered: %d", logger.WALPending())
+	}
+}
+
+func TestLogEntry_TextJson(t *testing.T) {
+
+	entry := &LogEntry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local), Level: LOGGER_LEVEL_ERROR, Body: "failed", Fields: map[string]interface{}{"id": 1}}
+	if text := entry.Text("[%level_string%] %body% %field(id)%"); text != "[Error] failed 1" {
+		t.Errorf("entry text error: %s", text)
+	}
+	record, err := entry.Json()
+	if err != nil || !strings.Contains(string(record), `"body":"failed"`) || !strings.Contains(string(record), `"id":1`) {
+		t.Errorf("entry json error: %s %v", record, err)
+	}
+}
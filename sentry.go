@@ -0,0 +1,401 @@
+package go_logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SENTRY_ADAPTER_NAME = "sentry"
+
+const (
+	SENTRY_DEFAULT_BATCH_SIZE        = 20
+	SENTRY_DEFAULT_FLUSH_INTERVAL    = 5 * time.Second
+	SENTRY_DEFAULT_TIMEOUT           = 10 * time.Second
+	SENTRY_DEFAULT_MAX_EVENTS_MINUTE = 60
+	SENTRY_CLIENT                    = "go-logger/1.0"
+)
+
+var sentryEventLevels = map[int]string{
+	LOGGER_LEVEL_EMERGENCY: "fatal",
+	LOGGER_LEVEL_ALERT:     "fatal",
+	LOGGER_LEVEL_CRITICAL:  "fatal",
+	LOGGER_LEVEL_ERROR:     "error",
+}
+
+// adapter sentry, error and more severe messages are sent as events by the envelope api
+type AdapterSentry struct {
+	lock      sync.Mutex
+	config    *SentryConfig
+	client    *http.Client
+	url       string
+	auth      string
+	buffer    []*loggerMessage
+	ticker    *time.Ticker
+	quit      chan struct{}
+	window    time.Time // start of the rate limit minute
+	sent      int       // events of the rate limit minute
+	limited   time.Time // events are dropped until it by 429 Retry-After
+	dropped   int64     // events dropped by rate limit
+	sendCount int64     // sent events
+}
+
+// sentry config
+type SentryConfig struct {
+
+	// sentry dsn, eg: "https://public@o1.ingest.sentry.io/42"
+	Dsn string
+
+	// envelope endpoint of a webhook with sentry compatible payload, used if Dsn is empty
+	Url string
+
+	// environment and release of events, eg: "production", "api@1.4.2"
+	Environment string
+	Release     string
+
+	// server name of events, default hostname
+	ServerName string
+
+	// static tags of events
+	Tags map[string]string
+
+	// fields added as tags, others are added as extra
+	FieldTags []string
+
+	// max events of one envelope, default 20
+	BatchSize int
+
+	// buffered events are sent every FlushInterval, default 5s
+	FlushInterval time.Duration
+
+	// max events sent every minute, more are dropped, default 60, -1 is unlimited
+	MaxEventsPerMinute int
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// tls config of https
+	TLSConfig *tls.Config
+}
+
+func (sc *SentryConfig) Name() string {
+	return SENTRY_ADAPTER_NAME
+}
+
+func NewAdapterSentry() LoggerAbstract {
+	return &AdapterSentry{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterSentry *AdapterSentry) Init(sentryConfig Config) error {
+	sc, ok := sentryConfig.(*SentryConfig)
+	if !ok || sc == nil {
+		return configTypeError(SENTRY_ADAPTER_NAME, "SentryConfig")
+	}
+	adapterSentry.config = sc
+
+	if sc.Dsn != "" {
+		endpoint, auth, err := parseSentryDsn(sc.Dsn)
+		if err != nil {
+			return err
+		}
+		adapterSentry.url = endpoint
+		adapterSentry.auth = auth
+	} else if sc.Url != "" {
+		adapterSentry.url = sc.Url
+	} else {
+		return configError(SENTRY_ADAPTER_NAME, "Dsn", "cannot be empty if Url is empty!")
+	}
+	if sc.ServerName == "" {
+		sc.ServerName, _ = os.Hostname()
+	}
+	if sc.BatchSize <= 0 {
+		sc.BatchSize = SENTRY_DEFAULT_BATCH_SIZE
+	}
+	if sc.FlushInterval <= 0 {
+		sc.FlushInterval = SENTRY_DEFAULT_FLUSH_INTERVAL
+	}
+	if sc.MaxEventsPerMinute == 0 {
+		sc.MaxEventsPerMinute = SENTRY_DEFAULT_MAX_EVENTS_MINUTE
+	}
+	if sc.Timeout <= 0 {
+		sc.Timeout = SENTRY_DEFAULT_TIMEOUT
+	}
+
+	adapterSentry.client = &http.Client{
+		Timeout: sc.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: sc.TLSConfig,
+		},
+	}
+
+	adapterSentry.ticker = time.NewTicker(sc.FlushInterval)
+	adapterSentry.quit = make(chan struct{})
+	go adapterSentry.startFlush(adapterSentry.ticker, adapterSentry.quit)
+
+	return nil
+}
+
+// messages less severe than error are ignored, events over rate limit are dropped
+func (adapterSentry *AdapterSentry) Write(loggerMsg *loggerMessage) error {
+	if loggerMsg.Level > LOGGER_LEVEL_ERROR {
+		return nil
+	}
+	adapterSentry.lock.Lock()
+	if !adapterSentry.allow(time.Now()) {
+		adapterSentry.dropped++
+		adapterSentry.lock.Unlock()
+		return nil
+	}
+	adapterSentry.buffer = append(adapterSentry.buffer, loggerMsg)
+	if len(adapterSentry.buffer) < adapterSentry.config.BatchSize {
+		adapterSentry.lock.Unlock()
+		return nil
+	}
+	buffer := adapterSentry.buffer
+	adapterSentry.buffer = []*loggerMessage{}
+	adapterSentry.lock.Unlock()
+
+	return adapterSentry.send(buffer)
+}
+
+func (adapterSentry *AdapterSentry) Flush() {
+	adapterSentry.lock.Lock()
+	buffer := adapterSentry.buffer
+	adapterSentry.buffer = []*loggerMessage{}
+	adapterSentry.lock.Unlock()
+
+	err := adapterSentry.send(buffer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: sentry adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop flush ticker and send buffered events
+func (adapterSentry *AdapterSentry) Close() error {
+	if adapterSentry.ticker != nil {
+		adapterSentry.ticker.Stop()
+		close(adapterSentry.quit)
+		adapterSentry.ticker = nil
+	}
+	adapterSentry.Flush()
+	return nil
+}
+
+func (adapterSentry *AdapterSentry) Name() string {
+	return SENTRY_ADAPTER_NAME
+}
+
+func (adapterSentry *AdapterSentry) Capabilities() Capabilities {
+	return Capabilities{Batching: true, NeedsFlush: true, Remote: true}
+}
+
+// dial the host of the envelope endpoint
+func (adapterSentry *AdapterSentry) HealthCheck() error {
+	return healthCheckDialUrl(adapterSentry.url, adapterSentry.config.Timeout)
+}
+
+// sent events and events dropped by rate limit
+func (adapterSentry *AdapterSentry) Counters() map[string]int64 {
+	adapterSentry.lock.Lock()
+	defer adapterSentry.lock.Unlock()
+
+	return map[string]int64{
+		"events":       adapterSentry.sendCount,
+		"rate_dropped": adapterSentry.dropped,
+	}
+}
+
+// flush buffer every FlushInterval
+func (adapterSentry *AdapterSentry) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterSentry.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// rate limit of events by minute and 429 Retry-After, after lock
+func (adapterSentry *AdapterSentry) allow(now time.Time) bool {
+	if now.Before(adapterSentry.limited) {
+		return false
+	}
+	if adapterSentry.config.MaxEventsPerMinute < 0 {
+		return true
+	}
+	if now.Sub(adapterSentry.window) >= time.Minute {
+		adapterSentry.window = now
+		adapterSentry.sent = 0
+	}
+	if adapterSentry.sent >= adapterSentry.config.MaxEventsPerMinute {
+		return false
+	}
+	adapterSentry.sent++
+	return true
+}
+
+// send events in one envelope
+func (adapterSentry *AdapterSentry) send(loggerMsgs []*loggerMessage) error {
+	if len(loggerMsgs) == 0 {
+		return nil
+	}
+
+	body := &bytes.Buffer{}
+	header, _ := json.Marshal(map[string]string{"sent_at": time.Now().UTC().Format(time.RFC3339Nano)})
+	body.Write(header)
+	body.WriteByte('\n')
+	for _, loggerMsg := range loggerMsgs {
+		event, err := json.Marshal(adapterSentry.event(loggerMsg))
+		if err != nil {
+			return err
+		}
+		itemHeader, _ := json.Marshal(map[string]interface{}{"type": "event", "length": len(event)})
+		body.Write(itemHeader)
+		body.WriteByte('\n')
+		body.Write(event)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest("POST", adapterSentry.url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	if adapterSentry.auth != "" {
+		req.Header.Set("X-Sentry-Auth", adapterSentry.auth)
+	}
+	resp, err := adapterSentry.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if retryAfter <= 0 {
+			retryAfter = 60
+		}
+		adapterSentry.lock.Lock()
+		adapterSentry.limited = time.Now().Add(time.Duration(retryAfter) * time.Second)
+		adapterSentry.lock.Unlock()
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sentry envelope request failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	adapterSentry.lock.Lock()
+	adapterSentry.sendCount += int64(len(loggerMsgs))
+	adapterSentry.lock.Unlock()
+	return nil
+}
+
+// sentry event of the message, the caller is the stack frame of the exception
+func (adapterSentry *AdapterSentry) event(loggerMsg *loggerMessage) map[string]interface{} {
+	config := adapterSentry.config
+	tags := make(map[string]string, len(config.Tags)+len(config.FieldTags))
+	for key, value := range config.Tags {
+		tags[key] = value
+	}
+	extra := make(map[string]interface{}, len(loggerMsg.Fields))
+	for key, value := range loggerMsg.Fields {
+		extra[key] = value
+	}
+	for _, field := range config.FieldTags {
+		if _, ok := loggerMsg.Fields[field]; ok {
+			tags[field] = loggerMessageField(loggerMsg.Fields, field)
+			delete(extra, field)
+		}
+	}
+
+	event := map[string]interface{}{
+		"event_id":    sentryEventId(),
+		"timestamp":   float64(loggerMsg.Millisecond) / 1000,
+		"level":       sentryEventLevels[builtinLevel(loggerMsg.Level)],
+		"logger":      "go-logger",
+		"platform":    "go",
+		"server_name": config.ServerName,
+		"message":     map[string]string{"formatted": loggerMsg.Body},
+		"culprit":     loggerMsg.Function,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":  loggerMsg.LevelString,
+				"value": loggerMsg.Body,
+				"stacktrace": map[string]interface{}{
+					"frames": []map[string]interface{}{{
+						"filename": loggerMsg.File,
+						"function": loggerMsg.Function,
+						"lineno":   loggerMsg.Line,
+						"in_app":   true,
+					}},
+				},
+			}},
+		},
+	}
+	if config.Environment != "" {
+		event["environment"] = config.Environment
+	}
+	if config.Release != "" {
+		event["release"] = config.Release
+	}
+	if len(tags) > 0 {
+		event["tags"] = tags
+	}
+	if len(extra) > 0 {
+		event["extra"] = extra
+	}
+	return event
+}
+
+// envelope endpoint and auth header of dsn
+func parseSentryDsn(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", configErrorCause(SENTRY_ADAPTER_NAME, "Dsn", "is illegal", err)
+	}
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return "", "", configError(SENTRY_ADAPTER_NAME, "Dsn", dsn+" is illegal!")
+	}
+	path := strings.TrimRight(u.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectId := path[slash+1:]
+	if projectId == "" {
+		return "", "", configError(SENTRY_ADAPTER_NAME, "Dsn", dsn+" has no project id!")
+	}
+
+	endpoint := u.Scheme + "://" + u.Host + path[:slash] + "/api/" + projectId + "/envelope/"
+	auth := "Sentry sentry_version=7, sentry_client=" + SENTRY_CLIENT + ", sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return endpoint, auth, nil
+}
+
+// 32 hex characters
+func sentryEventId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func init() {
+	Register(SENTRY_ADAPTER_NAME, NewAdapterSentry)
+	RegisterConfig(SENTRY_ADAPTER_NAME, func() Config {
+		return &SentryConfig{}
+	})
+}
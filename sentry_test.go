@@ -0,0 +1,89 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSentryDsn(t *testing.T) {
+
+	endpoint, auth, err := parseSentryDsn("https://public@o1.ingest.sentry.io/42")
+	if err != nil || endpoint != "https://o1.ingest.sentry.io/api/42/envelope/" || !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("parse sentry dsn error: %s %s %v", endpoint, auth, err)
+	}
+	endpoint, _, _ = parseSentryDsn("http://public@127.0.0.1:9000/sentry/7")
+	if endpoint != "http://127.0.0.1:9000/sentry/api/7/envelope/" {
+		t.Errorf("parse sentry dsn with path error: %s", endpoint)
+	}
+	_, _, err = parseSentryDsn("https://o1.ingest.sentry.io/42")
+	if err == nil {
+		t.Errorf("dsn without key must be illegal")
+	}
+}
+
+func TestAdapterSentry_Write(t *testing.T) {
+
+	envelopes := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/42/envelope/" || !strings.Contains(r.Header.Get("X-Sentry-Auth"), "sentry_key=public") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		envelopes <- string(body)
+	}))
+	defer server.Close()
+
+	sentryAdapter := NewAdapterSentry()
+	err := sentryAdapter.Init(&SentryConfig{
+		Dsn:                strings.Replace(server.URL, "http://", "http://public@", 1) + "/42",
+		Environment:        "production",
+		Release:            "api@1.4.2",
+		FieldTags:          []string{"tenant"},
+		BatchSize:          2,
+		FlushInterval:      time.Hour,
+		MaxEventsPerMinute: 3,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_CRITICAL, "db is down", map[string]interface{}{"tenant": "acme", "retries": 3})
+	loggerMsg.File = "main.go"
+	loggerMsg.Line = 64
+	loggerMsg.Function = "main.main"
+	sentryAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_WARNING, "ignored", nil))
+	sentryAdapter.Write(loggerMsg)
+	sentryAdapter.Write(loggerMsg)
+
+	lines := strings.Split(strings.TrimSpace(<-envelopes), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("sentry envelope error: %v", lines)
+	}
+	event := map[string]interface{}{}
+	json.Unmarshal([]byte(lines[2]), &event)
+	if event["level"] != "fatal" || event["environment"] != "production" || event["release"] != "api@1.4.2" || event["culprit"] != "main.main" {
+		t.Errorf("sentry event error: %v", event)
+	}
+	if event["tags"].(map[string]interface{})["tenant"] != "acme" || event["extra"].(map[string]interface{})["retries"] != float64(3) {
+		t.Errorf("sentry event tags error: %v", event)
+	}
+	if !strings.Contains(lines[2], `"filename":"main.go"`) || !strings.Contains(lines[2], `"lineno":64`) {
+		t.Errorf("sentry event frame error: %s", lines[2])
+	}
+
+	// rate limited
+	for i := 0; i < 5; i++ {
+		sentryAdapter.Write(loggerMsg)
+	}
+	sentryAdapter.(LoggerCloser).Close()
+	counters := sentryAdapter.(LoggerCounter).Counters()
+	if counters["events"] != 3 || counters["rate_dropped"] != 4 {
+		t.Errorf("sentry counters error: %v", counters)
+	}
+}
@@ -0,0 +1,156 @@
+package go_logger
+
+import (
+	"errors"
+	"strconv"
+)
+
+// set adapters the adapter depends on, eg: it writes to them or releases their files, nil removes them
+// Close() drains, flushes and closes the adapter before its dependencies, fallback adapters are dependencies too
+//
+// example, the audit adapter copies messages to the file adapter:
+//	logger.SetAdapterDependsOn("audit", "file")
+func (logger *Logger) SetAdapterDependsOn(adapterName string, dependencies ...string) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	var output *outputLogger
+	attached := map[string]bool{}
+	for _, o := range logger.outputs {
+		attached[o.Name] = true
+		if o.Name == adapterName {
+			output = o
+		}
+	}
+	if output == nil {
+		return errors.New("logger: adapter " + adapterName + " is not attached!")
+	}
+	for _, dependency := range dependencies {
+		if !attached[dependency] {
+			return errors.New("logger: dependency adapter " + dependency + " is not attached!")
+		}
+	}
+
+	graph := closeDependencies(logger.outputs)
+	graph[adapterName] = append(fallbackDependencies(output), dependencies...)
+	if cycle := dependencyCycle(graph, adapterName); cycle != "" {
+		return errors.New("logger: adapter " + adapterName + " dependencies have a cycle " + cycle + "!")
+	}
+	output.dependsOn.Store(append([]string{}, dependencies...))
+	return nil
+}
+
+// adapters closed after the output: its dependencies and fallback adapter
+func (output *outputLogger) closeAfter() []string {
+	dependencies, _ := output.dependsOn.Load().([]string)
+	return append(fallbackDependencies(output), dependencies...)
+}
+
+// fallback adapter of the output, written by the output until it is closed
+func fallbackDependencies(output *outputLogger) []string {
+	if fallback, _ := output.fallback.Load().(*adapterFallback); fallback != nil {
+		return []string{fallback.secondary.Name}
+	}
+	return nil
+}
+
+// dependencies of outputs by name
+func closeDependencies(outputs []*outputLogger) map[string][]string {
+	graph := make(map[string][]string, len(outputs))
+	for _, output := range outputs {
+		graph[output.Name] = output.closeAfter()
+	}
+	return graph
+}
+
+// cycle of dependencies reached from the adapter, eg: "a -> b -> a", empty if there is no cycle
+func dependencyCycle(graph map[string][]string, adapterName string) string {
+	path := []string{}
+	visiting := map[string]bool{}
+	done := map[string]bool{}
+	var visit func(name string) string
+	visit = func(name string) string {
+		if visiting[name] {
+			cycle := name
+			for i := len(path) - 1; i >= 0 && path[i] != name; i-- {
+				cycle = path[i] + " -> " + cycle
+			}
+			return name + " -> " + cycle
+		}
+		if done[name] {
+			return ""
+		}
+		visiting[name] = true
+		path = append(path, name)
+		for _, dependency := range graph[name] {
+			if cycle := visit(dependency); cycle != "" {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		visiting[name] = false
+		done[name] = true
+		return ""
+	}
+	return visit(adapterName)
+}
+
+// outputs in close order, every output before its dependencies, otherwise in attach order
+// outputs of a cycle are closed in attach order
+func closeOrder(outputs []*outputLogger) []*outputLogger {
+	graph := closeDependencies(outputs)
+	// number of outputs depending on the output not closed yet
+	dependents := make(map[string]int, len(outputs))
+	for _, output := range outputs {
+		for _, dependency := range graph[output.Name] {
+			dependents[dependency]++
+		}
+	}
+
+	ordered := make([]*outputLogger, 0, len(outputs))
+	closed := make(map[*outputLogger]bool, len(outputs))
+	for len(ordered) < len(outputs) {
+		next := -1
+		for i, output := range outputs {
+			if !closed[output] && dependents[output.Name] == 0 {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			// cycle, the first output left is closed
+			for i, output := range outputs {
+				if !closed[output] {
+					next = i
+					break
+				}
+			}
+		}
+		output := outputs[next]
+		closed[output] = true
+		ordered = append(ordered, output)
+		for _, dependency := range graph[output.Name] {
+			dependents[dependency]--
+		}
+	}
+	return ordered
+}
+
+// dependencies of config adapters are attached by the config and have no cycle
+func checkConfigDependencies(configAdapters []loggerConfigAdapter) error {
+	graph := make(map[string][]string, len(configAdapters))
+	for _, configAdapter := range configAdapters {
+		graph[configAdapter.outputName()] = configAdapter.DependsOn
+	}
+	for i, configAdapter := range configAdapters {
+		for _, dependency := range configAdapter.DependsOn {
+			if _, ok := graph[dependency]; !ok {
+				return errors.New("logger: config adapters[" + strconv.Itoa(i) + "] dependency adapter " + dependency + " is not attached!")
+			}
+		}
+		if cycle := dependencyCycle(graph, configAdapter.outputName()); cycle != "" {
+			return errors.New("logger: config adapters[" + strconv.Itoa(i) + "] dependencies have a cycle " + cycle + "!")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package go_logger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// adapter recording writes and close into a shared journal, writes fail after close
+type shutdownAdapter struct {
+	name    string
+	fail    bool
+	lock    *sync.Mutex
+	journal *[]string
+	closed  bool
+}
+
+func (sa *shutdownAdapter) Name() string {
+	return sa.name
+}
+
+func (sa *shutdownAdapter) Init(config Config) error {
+	return nil
+}
+
+func (sa *shutdownAdapter) Write(loggerMsg *loggerMessage) error {
+	sa.lock.Lock()
+	defer sa.lock.Unlock()
+	if sa.closed {
+		*sa.journal = append(*sa.journal, "lost:"+sa.name+":"+loggerMsg.Body)
+		return errors.New("closed")
+	}
+	if sa.fail {
+		return errors.New("down")
+	}
+	*sa.journal = append(*sa.journal, "write:"+sa.name+":"+loggerMsg.Body)
+	return nil
+}
+
+func (sa *shutdownAdapter) Flush() {
+}
+
+func (sa *shutdownAdapter) Close() error {
+	sa.lock.Lock()
+	defer sa.lock.Unlock()
+	sa.closed = true
+	*sa.journal = append(*sa.journal, "close:"+sa.name)
+	return nil
+}
+
+func TestLogger_CloseOrder(t *testing.T) {
+
+	lock := &sync.Mutex{}
+	journal := []string{}
+	logger := NewLogger()
+	logger.Detach("console")
+	for _, name := range []string{"file", "api", "audit"} {
+		logger.AttachAdapter(name, LOGGER_LEVEL_DEBUG, &shutdownAdapter{name: name, fail: name == "api", lock: lock, journal: &journal})
+	}
+	logger.SetAsync(10)
+	logger.SetAdapterFallback("api", &FallbackConfig{Adapter: "file", MaxFailures: 1})
+	if err := logger.SetAdapterDependsOn("audit", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.SetAdapterDependsOn("file", "audit"); err == nil || !strings.Contains(err.Error(), "file -> audit -> api -> file") {
+		t.Errorf("dependency cycle error: %v", err)
+	}
+	if err := logger.SetAdapterDependsOn("audit", "database"); err == nil {
+		t.Error("dependency not attached must be error")
+	}
+
+	logger.Info("shutdown")
+	logger.Close(context.Background())
+
+	closes := []string{}
+	for _, entry := range journal {
+		if strings.HasPrefix(entry, "lost:") {
+			t.Errorf("message written after close: %s", entry)
+		}
+		if strings.HasPrefix(entry, "close:") {
+			closes = append(closes, entry)
+		}
+	}
+	if strings.Join(closes, ",") != "close:audit,close:api,close:file" {
+		t.Errorf("close order error: %v", journal)
+	}
+}
+
+func TestCloseOrder_Cycle(t *testing.T) {
+
+	a := &outputLogger{Name: "a"}
+	b := &outputLogger{Name: "b"}
+	c := &outputLogger{Name: "c"}
+	a.dependsOn.Store([]string{"b"})
+	b.dependsOn.Store([]string{"a"})
+	names := []string{}
+	for _, output := range closeOrder([]*outputLogger{a, b, c}) {
+		names = append(names, output.Name)
+	}
+	if strings.Join(names, ",") != "c,a,b" {
+		t.Errorf("close order of cycle error: %v", names)
+	}
+}
+
+func TestLogger_LoadConfigDependsOn(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`{"adapters": [
+		{"name": "memory", "alias": "a", "depends_on": ["b"]},
+		{"name": "memory", "alias": "b", "depends_on": ["a"]}
+	]}`), CONFIG_FORMAT_JSON)
+	if err == nil || !strings.Contains(err.Error(), "cycle a -> b -> a") {
+		t.Errorf("config dependency cycle error: %v", err)
+	}
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [
+		{"name": "memory", "alias": "a", "depends_on": ["console"]}
+	]}`), CONFIG_FORMAT_JSON)
+	if err == nil {
+		t.Error("config dependency not attached must be error")
+	}
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [
+		{"name": "memory", "alias": "b"},
+		{"name": "memory", "alias": "a", "depends_on": ["b"]}
+	]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.lock.Lock()
+	order := closeOrder(logger.outputs)
+	logger.lock.Unlock()
+	if order[0].Name != "a" || order[1].Name != "b" {
+		t.Errorf("config close order error: %s, %s", order[0].Name, order[1].Name)
+	}
+}
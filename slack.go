@@ -0,0 +1,233 @@
+package go_logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const SLACK_ADAPTER_NAME = "slack"
+
+const (
+	SLACK_STYLE_SLACK   = "slack"
+	SLACK_STYLE_DISCORD = "discord"
+)
+
+const (
+	SLACK_DEFAULT_FORMAT              = "*[%level_string%]* %body%"
+	SLACK_DEFAULT_MAX_MESSAGES_MINUTE = 30
+	SLACK_DEFAULT_TIMEOUT             = 10 * time.Second
+)
+
+// adapter slack, messages are posted to incoming webhooks of slack, mattermost or discord
+type AdapterSlack struct {
+	lock      sync.Mutex
+	config    *SlackConfig
+	client    *http.Client
+	window    time.Time // start of the rate limit minute
+	sent      int       // messages of the rate limit minute
+	limited   time.Time // messages are dropped until it by 429 Retry-After
+	throttled int64     // messages dropped since the last post, reported by the next post
+	dropped   int64     // total of dropped messages
+	posts     int64     // posted messages
+}
+
+// slack config, messages at or above the level of Attach are posted
+type SlackConfig struct {
+
+	// incoming webhook url, eg: "https://hooks.slack.com/services/T000/B000/XXXX"
+	Url string
+
+	// payload style, "slack" {"text": ...} (default, also mattermost) or "discord" {"content": ...}
+	Style string
+
+	// message text format with placeholders of Format, default "*[%level_string%]* %body%"
+	Format string
+
+	// override channel, username and icon of the webhook, slack and mattermost only
+	Channel   string
+	Username  string
+	IconEmoji string
+
+	// max messages posted every minute, more are dropped, default 30, -1 is unlimited
+	MaxMessagesPerMinute int
+
+	// request timeout, default 10s
+	Timeout time.Duration
+
+	// tls config of https Url
+	TLSConfig *tls.Config
+}
+
+func (sc *SlackConfig) Name() string {
+	return SLACK_ADAPTER_NAME
+}
+
+func NewAdapterSlack() LoggerAbstract {
+	return &AdapterSlack{}
+}
+
+func (adapterSlack *AdapterSlack) Init(slackConfig Config) error {
+	sc, ok := slackConfig.(*SlackConfig)
+	if !ok || sc == nil {
+		return configTypeError(SLACK_ADAPTER_NAME, "SlackConfig")
+	}
+	adapterSlack.config = sc
+
+	if sc.Url == "" {
+		return configError(SLACK_ADAPTER_NAME, "Url", "cannot be empty!")
+	}
+	if sc.Style == "" {
+		sc.Style = SLACK_STYLE_SLACK
+	}
+	if sc.Style != SLACK_STYLE_SLACK && sc.Style != SLACK_STYLE_DISCORD {
+		return configError(SLACK_ADAPTER_NAME, "Style", "must one of the 'slack', 'discord'!")
+	}
+	if sc.Format == "" {
+		sc.Format = SLACK_DEFAULT_FORMAT
+	}
+	if err := checkFormatPlaceholders(sc.Format, nil); err != nil {
+		return err
+	}
+	if sc.MaxMessagesPerMinute == 0 {
+		sc.MaxMessagesPerMinute = SLACK_DEFAULT_MAX_MESSAGES_MINUTE
+	}
+	if sc.Timeout <= 0 {
+		sc.Timeout = SLACK_DEFAULT_TIMEOUT
+	}
+
+	adapterSlack.client = &http.Client{
+		Timeout: sc.Timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: sc.TLSConfig,
+		},
+	}
+	return nil
+}
+
+// post the message, messages over rate limit are dropped
+func (adapterSlack *AdapterSlack) Write(loggerMsg *loggerMessage) error {
+	adapterSlack.lock.Lock()
+	if !adapterSlack.allow(time.Now()) {
+		adapterSlack.throttled++
+		adapterSlack.dropped++
+		adapterSlack.lock.Unlock()
+		return nil
+	}
+	throttled := adapterSlack.throttled
+	adapterSlack.throttled = 0
+	adapterSlack.lock.Unlock()
+
+	text := loggerMessageFormat(adapterSlack.config.Format, loggerMsg)
+	if throttled > 0 {
+		text += fmt.Sprintf("\n_%d messages are dropped by rate limit since the last message_", throttled)
+	}
+	return adapterSlack.post(text)
+}
+
+func (adapterSlack *AdapterSlack) Flush() {
+
+}
+
+func (adapterSlack *AdapterSlack) Name() string {
+	return SLACK_ADAPTER_NAME
+}
+
+func (adapterSlack *AdapterSlack) Capabilities() Capabilities {
+	return Capabilities{Remote: true}
+}
+
+// dial the host of the webhook, a post would be a message of the channel
+func (adapterSlack *AdapterSlack) HealthCheck() error {
+	return healthCheckDialUrl(adapterSlack.config.Url, adapterSlack.config.Timeout)
+}
+
+// posted messages and messages dropped by rate limit
+func (adapterSlack *AdapterSlack) Counters() map[string]int64 {
+	adapterSlack.lock.Lock()
+	defer adapterSlack.lock.Unlock()
+
+	return map[string]int64{
+		"posts":        adapterSlack.posts,
+		"rate_dropped": adapterSlack.dropped,
+	}
+}
+
+// rate limit of messages by minute and 429 Retry-After, after lock
+func (adapterSlack *AdapterSlack) allow(now time.Time) bool {
+	if now.Before(adapterSlack.limited) {
+		return false
+	}
+	if adapterSlack.config.MaxMessagesPerMinute < 0 {
+		return true
+	}
+	if now.Sub(adapterSlack.window) >= time.Minute {
+		adapterSlack.window = now
+		adapterSlack.sent = 0
+	}
+	if adapterSlack.sent >= adapterSlack.config.MaxMessagesPerMinute {
+		return false
+	}
+	adapterSlack.sent++
+	return true
+}
+
+// post text to the webhook
+func (adapterSlack *AdapterSlack) post(text string) error {
+	config := adapterSlack.config
+	payload := map[string]string{}
+	if config.Style == SLACK_STYLE_DISCORD {
+		payload["content"] = text
+	} else {
+		payload["text"] = text
+		if config.Channel != "" {
+			payload["channel"] = config.Channel
+		}
+		if config.IconEmoji != "" {
+			payload["icon_emoji"] = config.IconEmoji
+		}
+	}
+	if config.Username != "" {
+		payload["username"] = config.Username
+	}
+	body, _ := json.Marshal(payload)
+
+	resp, err := adapterSlack.client.Post(config.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		// seconds, discord may send fractions
+		retryAfter, _ := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+		if retryAfter <= 0 {
+			retryAfter = 60
+		}
+		adapterSlack.lock.Lock()
+		adapterSlack.limited = time.Now().Add(time.Duration(retryAfter * float64(time.Second)))
+		adapterSlack.lock.Unlock()
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("slack webhook request failed, code=%d, body=%s", resp.StatusCode, respBody)
+	}
+
+	adapterSlack.lock.Lock()
+	adapterSlack.posts++
+	adapterSlack.lock.Unlock()
+	return nil
+}
+
+func init() {
+	Register(SLACK_ADAPTER_NAME, NewAdapterSlack)
+	RegisterConfig(SLACK_ADAPTER_NAME, func() Config {
+		return &SlackConfig{}
+	})
+}
@@ -0,0 +1,87 @@
+package go_logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdapterSlack_Write(t *testing.T) {
+
+	payloads := []map[string]string{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads = append(payloads, payload)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0.05")
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	slackAdapter := NewAdapterSlack()
+	err := slackAdapter.Init(&SlackConfig{
+		Url:                  server.URL,
+		Channel:              "#ops",
+		MaxMessagesPerMinute: 2,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	loggerMsg := newLoggerMessage(time.Now(), LOGGER_LEVEL_CRITICAL, "db is down", nil)
+	slackAdapter.Write(loggerMsg)
+	slackAdapter.Write(loggerMsg)
+	slackAdapter.Write(loggerMsg)
+	if len(payloads) != 2 || payloads[0]["text"] != "*[Critical]* db is down" || payloads[0]["channel"] != "#ops" {
+		t.Errorf("slack payloads error: %v", payloads)
+	}
+	if counters := slackAdapter.(LoggerCounter).Counters(); counters["posts"] != 2 || counters["rate_dropped"] != 1 {
+		t.Errorf("slack counters error: %v", counters)
+	}
+
+	// dropped messages are reported by the next message
+	slackAdapter.(*AdapterSlack).window = time.Time{}
+	status = http.StatusTooManyRequests
+	if slackAdapter.Write(loggerMsg) == nil {
+		t.Error("slack 429 must error")
+	}
+	if !strings.HasSuffix(payloads[2]["text"], "_1 messages are dropped by rate limit since the last message_") {
+		t.Errorf("slack dropped report error: %s", payloads[2]["text"])
+	}
+	slackAdapter.Write(loggerMsg)
+	if len(payloads) != 3 {
+		t.Errorf("slack Retry-After is not respected: %d posts", len(payloads))
+	}
+}
+
+func TestAdapterSlack_Discord(t *testing.T) {
+
+	payloads := make(chan map[string]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	slackAdapter := NewAdapterSlack()
+	if slackAdapter.Init(&SlackConfig{Url: server.URL, Style: "teams"}) == nil {
+		t.Error("slack unknown style must error")
+	}
+	slackAdapter.Init(&SlackConfig{Url: server.URL, Style: SLACK_STYLE_DISCORD, Username: "logger", Format: "%level_string%: %body%"})
+	err := slackAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ALERT, "disk is full", nil))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	payload := <-payloads
+	if payload["content"] != "Alert: disk is full" || payload["username"] != "logger" || payload["text"] != "" {
+		t.Errorf("discord payload error: %v", payload)
+	}
+}
@@ -0,0 +1,165 @@
+//go:build go1.21
+// +build go1.21
+
+package go_logger
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// slog handler options
+type SlogHandlerOptions struct {
+
+	// min slog level handled, default slog.LevelDebug
+	Level slog.Leveler
+}
+
+// slog.Handler writes records to the logger adapters
+type SlogHandler struct {
+	logger *Logger
+	opts   SlogHandlerOptions
+	fields map[string]interface{}
+	group  string
+}
+
+// new slog handler, records are converted to logger messages with attrs as fields
+//
+// example:
+//	slog.SetDefault(slog.New(logger.SlogHandler(nil)))
+func (logger *Logger) SlogHandler(opts *SlogHandlerOptions) *SlogHandler {
+	handler := &SlogHandler{
+		logger: logger,
+		fields: map[string]interface{}{},
+	}
+	if opts != nil {
+		handler.opts = *opts
+	}
+	if handler.opts.Level == nil {
+		handler.opts.Level = slog.LevelDebug
+	}
+	return handler
+}
+
+func (sh *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= sh.opts.Level.Level() && sh.logger.enabled(slogLevel(level))
+}
+
+func (sh *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if atomic.LoadInt32(&sh.logger.closed) == 1 {
+		return ErrLoggerClosed
+	}
+
+	fields := make(map[string]interface{}, len(sh.fields)+record.NumAttrs())
+	for key, value := range sh.logger.contextFields(ctx) {
+		fields[key] = value
+	}
+	for key, value := range sh.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(fields, sh.group, attr)
+		return true
+	})
+	if len(fields) == 0 {
+		fields = nil
+	}
+
+	loggerMsg := newLoggerMessage(sh.logger.recordTime(record.Time), slogLevel(record.Level), record.Message, fields)
+	loggerMsg.File = "null"
+	loggerMsg.Function = "null"
+	if record.PC != 0 {
+		frame := framesOf(record.PC)[0]
+		loggerMsg.File = frame.file
+		loggerMsg.Line = frame.line
+		loggerMsg.Function = frame.function
+	}
+
+	sh.logger.dispatch(loggerMsg, nil)
+	return nil
+}
+
+func (sh *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := sh.clone()
+	for _, attr := range attrs {
+		addSlogAttr(handler.fields, handler.group, attr)
+	}
+	return handler
+}
+
+func (sh *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return sh
+	}
+	handler := sh.clone()
+	handler.group = slogKey(sh.group, name)
+	return handler
+}
+
+func (sh *SlogHandler) clone() *SlogHandler {
+	fields := make(map[string]interface{}, len(sh.fields))
+	for key, value := range sh.fields {
+		fields[key] = value
+	}
+	return &SlogHandler{
+		logger: sh.logger,
+		opts:   sh.opts,
+		fields: fields,
+		group:  sh.group,
+	}
+}
+
+// add attr to fields, group attrs are flattened to "group.key"
+func addSlogAttr(fields map[string]interface{}, group string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			group = slogKey(group, attr.Key)
+		}
+		for _, groupAttr := range value.Group() {
+			addSlogAttr(fields, group, groupAttr)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	switch value.Kind() {
+	case slog.KindTime:
+		fields[slogKey(group, attr.Key)] = value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		fields[slogKey(group, attr.Key)] = value.Duration().String()
+	default:
+		fields[slogKey(group, attr.Key)] = value.Any()
+	}
+}
+
+func slogKey(group string, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
+
+// logger level of slog level
+func slogLevel(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return LOGGER_LEVEL_DEBUG
+	case level < slog.LevelInfo+2:
+		return LOGGER_LEVEL_INFO
+	case level < slog.LevelWarn:
+		return LOGGER_LEVEL_NOTICE
+	case level < slog.LevelError:
+		return LOGGER_LEVEL_WARNING
+	case level < slog.LevelError+4:
+		return LOGGER_LEVEL_ERROR
+	case level < slog.LevelError+8:
+		return LOGGER_LEVEL_CRITICAL
+	case level < slog.LevelError+12:
+		return LOGGER_LEVEL_ALERT
+	}
+	return LOGGER_LEVEL_EMERGENCY
+}
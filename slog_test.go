@@ -0,0 +1,37 @@
+//go:build go1.21
+// +build go1.21
+
+package go_logger
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+
+	slogger := slog.New(logger.SlogHandler(&SlogHandlerOptions{Level: slog.LevelInfo}))
+	slogger.Debug("slog debug")
+	slogger.With("service", "api").WithGroup("req").Warn("slog warn", "id", 7, slog.Group("user", "name", "go"))
+	slogger.Error("slog error")
+
+	entries := memoryAdapter.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("slog handler entries error: %v", entries)
+	}
+	entry := entries[0]
+	if entry.Level != LOGGER_LEVEL_WARNING || entry.Body != "slog warn" || entry.File != "slog_test.go" {
+		t.Errorf("slog handler record error: %v", entry)
+	}
+	if entry.Fields["service"] != "api" || entry.Fields["req.id"] != int64(7) || entry.Fields["req.user.name"] != "go" {
+		t.Errorf("slog handler attrs error: %v", entry.Fields)
+	}
+	if entries[1].Level != LOGGER_LEVEL_ERROR {
+		t.Error("slog handler level error")
+	}
+}
@@ -0,0 +1,372 @@
+package go_logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const SMTP_ADAPTER_NAME = "smtp"
+
+const (
+	SMTP_DEFAULT_SUBJECT             = "[%level_string%] %body%"
+	SMTP_DEFAULT_MAX_MAILS           = 10
+	SMTP_DEFAULT_MAX_MAILS_INTERVAL  = time.Hour
+	SMTP_DEFAULT_MAX_DIGEST_MESSAGES = 1000
+	SMTP_DEFAULT_TIMEOUT             = 10 * time.Second
+)
+
+// adapter smtp, messages are sent by email, one email every message or a digest every window
+type AdapterSmtp struct {
+	lock      sync.Mutex
+	config    *SmtpConfig
+	buffer    []*loggerMessage
+	ticker    *time.Ticker
+	quit      chan struct{}
+	window    time.Time // start of the throttle interval
+	sent      int       // mails of the throttle interval
+	throttled int64     // messages dropped by throttle, reported by the next mail
+	dropped   int64     // total of throttled messages
+	mails     int64     // sent mails
+}
+
+// smtp config, messages at or above the level of Attach are sent
+type SmtpConfig struct {
+
+	// smtp server address, eg: "smtp.example.com:587"
+	// STARTTLS is used if the server supports it, Tls is implicit tls (port 465)
+	Host string
+	Tls  bool
+
+	// plain auth, the connection must be tls or localhost
+	Username string
+	Password string
+
+	// sender and recipients
+	From string
+	To   []string
+
+	// subject format with placeholders of Format, default "[%level_string%] %body%"
+	// the subject of a digest is the subject of its most severe message suffixed with " (and N more)"
+	Subject string
+
+	// message format of the mail body, default "%millisecond_format% [%level_string%] %body%"
+	Format string
+
+	// max mails sent every MaxMailsInterval, messages over it are dropped, default 10 every 1h, -1 is unlimited
+	MaxMails         int
+	MaxMailsInterval time.Duration
+
+	// send messages of every Digest window in one mail, 0 is one mail every message
+	Digest time.Duration
+
+	// max messages of one digest, more are dropped, default 1000
+	MaxDigestMessages int
+
+	// dial and send timeout, default 10s
+	Timeout time.Duration
+
+	// tls config of Tls and STARTTLS, default server name of Host
+	TLSConfig *tls.Config
+}
+
+func (sc *SmtpConfig) Name() string {
+	return SMTP_ADAPTER_NAME
+}
+
+func NewAdapterSmtp() LoggerAbstract {
+	return &AdapterSmtp{
+		buffer: []*loggerMessage{},
+	}
+}
+
+func (adapterSmtp *AdapterSmtp) Init(smtpConfig Config) error {
+	sc, ok := smtpConfig.(*SmtpConfig)
+	if !ok || sc == nil {
+		return configTypeError(SMTP_ADAPTER_NAME, "SmtpConfig")
+	}
+	adapterSmtp.config = sc
+
+	if sc.Host == "" {
+		return configError(SMTP_ADAPTER_NAME, "Host", "cannot be empty!")
+	}
+	if sc.From == "" {
+		return configError(SMTP_ADAPTER_NAME, "From", "cannot be empty!")
+	}
+	if len(sc.To) == 0 {
+		return configError(SMTP_ADAPTER_NAME, "To", "cannot be empty!")
+	}
+	if sc.Subject == "" {
+		sc.Subject = SMTP_DEFAULT_SUBJECT
+	}
+	if sc.Format == "" {
+		sc.Format = defaultLoggerMessageFormat
+	}
+	if err := checkFormatPlaceholders(sc.Format, nil); err != nil {
+		return err
+	}
+	if sc.MaxMails == 0 {
+		sc.MaxMails = SMTP_DEFAULT_MAX_MAILS
+	}
+	if sc.MaxMailsInterval <= 0 {
+		sc.MaxMailsInterval = SMTP_DEFAULT_MAX_MAILS_INTERVAL
+	}
+	if sc.MaxDigestMessages <= 0 {
+		sc.MaxDigestMessages = SMTP_DEFAULT_MAX_DIGEST_MESSAGES
+	}
+	if sc.Timeout <= 0 {
+		sc.Timeout = SMTP_DEFAULT_TIMEOUT
+	}
+
+	if sc.Digest > 0 {
+		adapterSmtp.ticker = time.NewTicker(sc.Digest)
+		adapterSmtp.quit = make(chan struct{})
+		go adapterSmtp.startFlush(adapterSmtp.ticker, adapterSmtp.quit)
+	}
+	return nil
+}
+
+// send the message, or add it to the digest
+func (adapterSmtp *AdapterSmtp) Write(loggerMsg *loggerMessage) error {
+	adapterSmtp.lock.Lock()
+	if adapterSmtp.config.Digest > 0 {
+		if len(adapterSmtp.buffer) >= adapterSmtp.config.MaxDigestMessages {
+			adapterSmtp.throttled++
+			adapterSmtp.dropped++
+		} else {
+			adapterSmtp.buffer = append(adapterSmtp.buffer, loggerMsg)
+		}
+		adapterSmtp.lock.Unlock()
+		return nil
+	}
+	if !adapterSmtp.allow(time.Now()) {
+		adapterSmtp.throttled++
+		adapterSmtp.dropped++
+		adapterSmtp.lock.Unlock()
+		return nil
+	}
+	throttled := adapterSmtp.throttled
+	adapterSmtp.throttled = 0
+	adapterSmtp.lock.Unlock()
+
+	return adapterSmtp.send([]*loggerMessage{loggerMsg}, throttled)
+}
+
+// send the digest
+func (adapterSmtp *AdapterSmtp) Flush() {
+	adapterSmtp.lock.Lock()
+	buffer := adapterSmtp.buffer
+	if len(buffer) == 0 {
+		adapterSmtp.lock.Unlock()
+		return
+	}
+	if !adapterSmtp.allow(time.Now()) {
+		adapterSmtp.throttled += int64(len(buffer))
+		adapterSmtp.dropped += int64(len(buffer))
+		adapterSmtp.buffer = []*loggerMessage{}
+		adapterSmtp.lock.Unlock()
+		return
+	}
+	throttled := adapterSmtp.throttled
+	adapterSmtp.throttled = 0
+	adapterSmtp.buffer = []*loggerMessage{}
+	adapterSmtp.lock.Unlock()
+
+	err := adapterSmtp.send(buffer, throttled)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: smtp adapter flush failed, error: %v\n", err)
+	}
+}
+
+// stop digest ticker and send the digest
+func (adapterSmtp *AdapterSmtp) Close() error {
+	if adapterSmtp.ticker != nil {
+		adapterSmtp.ticker.Stop()
+		close(adapterSmtp.quit)
+		adapterSmtp.ticker = nil
+	}
+	adapterSmtp.Flush()
+	return nil
+}
+
+func (adapterSmtp *AdapterSmtp) Name() string {
+	return SMTP_ADAPTER_NAME
+}
+
+func (adapterSmtp *AdapterSmtp) Capabilities() Capabilities {
+	return Capabilities{
+		Batching:   adapterSmtp.config.Digest > 0,
+		NeedsFlush: adapterSmtp.config.Digest > 0,
+		Remote:     true,
+	}
+}
+
+// dial Host
+func (adapterSmtp *AdapterSmtp) HealthCheck() error {
+	return healthCheckDial("tcp", adapterSmtp.config.Host, adapterSmtp.config.Timeout)
+}
+
+// sent mails and messages dropped by throttle
+func (adapterSmtp *AdapterSmtp) Counters() map[string]int64 {
+	adapterSmtp.lock.Lock()
+	defer adapterSmtp.lock.Unlock()
+
+	return map[string]int64{
+		"mails":     adapterSmtp.mails,
+		"throttled": adapterSmtp.dropped,
+	}
+}
+
+// send digest every Digest window
+func (adapterSmtp *AdapterSmtp) startFlush(ticker *time.Ticker, quit chan struct{}) {
+	for {
+		select {
+		case <-ticker.C:
+			adapterSmtp.Flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// throttle of mails by MaxMailsInterval, after lock
+func (adapterSmtp *AdapterSmtp) allow(now time.Time) bool {
+	if adapterSmtp.config.MaxMails < 0 {
+		return true
+	}
+	if now.Sub(adapterSmtp.window) >= adapterSmtp.config.MaxMailsInterval {
+		adapterSmtp.window = now
+		adapterSmtp.sent = 0
+	}
+	if adapterSmtp.sent >= adapterSmtp.config.MaxMails {
+		return false
+	}
+	adapterSmtp.sent++
+	return true
+}
+
+// send messages in one mail
+func (adapterSmtp *AdapterSmtp) send(loggerMsgs []*loggerMessage, throttled int64) error {
+	mail := adapterSmtp.mail(loggerMsgs, throttled)
+	config := adapterSmtp.config
+
+	host, _, err := net.SplitHostPort(config.Host)
+	if err != nil {
+		return configErrorCause(SMTP_ADAPTER_NAME, "Host", config.Host+" is illegal", err)
+	}
+	tlsConfig := config.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	var conn net.Conn
+	if config.Tls {
+		conn, err = tls.DialWithDialer(dialer, "tcp", config.Host, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", config.Host)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(config.Timeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !config.Tls {
+		err = client.StartTLS(tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if config.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", config.Username, config.Password, host))
+		if err != nil {
+			return err
+		}
+	}
+	err = client.Mail(config.From)
+	if err != nil {
+		return err
+	}
+	for _, to := range config.To {
+		err = client.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = data.Write(mail)
+	if err != nil {
+		return err
+	}
+	err = data.Close()
+	if err != nil {
+		return err
+	}
+
+	adapterSmtp.lock.Lock()
+	adapterSmtp.mails++
+	adapterSmtp.lock.Unlock()
+	return client.Quit()
+}
+
+// mail headers and quoted-printable body of messages
+func (adapterSmtp *AdapterSmtp) mail(loggerMsgs []*loggerMessage, throttled int64) []byte {
+	config := adapterSmtp.config
+
+	// subject of the most severe message
+	subjectMsg := loggerMsgs[0]
+	for _, loggerMsg := range loggerMsgs {
+		if loggerMsg.Level < subjectMsg.Level {
+			subjectMsg = loggerMsg
+		}
+	}
+	subject := loggerMessageFormat(config.Subject, subjectMsg)
+	subject = strings.Join(strings.Fields(subject), " ")
+	if len(loggerMsgs) > 1 {
+		subject += fmt.Sprintf(" (and %d more)", len(loggerMsgs)-1)
+	}
+
+	mail := &bytes.Buffer{}
+	mail.WriteString("From: " + config.From + "\r\n")
+	mail.WriteString("To: " + strings.Join(config.To, ", ") + "\r\n")
+	mail.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	mail.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	mail.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	mail.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(mail)
+	for _, loggerMsg := range loggerMsgs {
+		body.Write([]byte(loggerMessageFormat(config.Format, loggerMsg) + "\r\n"))
+	}
+	if throttled > 0 {
+		body.Write([]byte(fmt.Sprintf("\r\n%d messages are dropped by throttling since the last mail\r\n", throttled)))
+	}
+	body.Close()
+	return mail.Bytes()
+}
+
+func init() {
+	Register(SMTP_ADAPTER_NAME, NewAdapterSmtp)
+	RegisterConfig(SMTP_ADAPTER_NAME, func() Config {
+		return &SmtpConfig{}
+	})
+}
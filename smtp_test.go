@@ -0,0 +1,140 @@
+package go_logger
+
+import (
+	"bufio"
+	"io/ioutil"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtp server receiving mails without auth and tls
+func testSmtpServer(t *testing.T) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	t.Cleanup(func() { listener.Close() })
+	mails := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				text := textproto.NewConn(conn)
+				text.PrintfLine("220 localhost ESMTP")
+				for {
+					line, err := text.ReadLine()
+					if err != nil {
+						return
+					}
+					switch strings.ToUpper(strings.SplitN(line, " ", 2)[0]) {
+					case "EHLO", "HELO":
+						text.PrintfLine("250 localhost")
+					case "DATA":
+						text.PrintfLine("354 go ahead")
+						// lines of DATA are read with "\n"
+						data, _ := ioutil.ReadAll(text.DotReader())
+						mails <- string(data)
+						text.PrintfLine("250 ok")
+					case "QUIT":
+						text.PrintfLine("221 bye")
+						return
+					default:
+						text.PrintfLine("250 ok")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), mails
+}
+
+// headers and decoded body of mail
+func testSmtpMail(t *testing.T, mails chan string) (textproto.MIMEHeader, string) {
+	select {
+	case mail := <-mails:
+		reader := textproto.NewReader(bufio.NewReader(strings.NewReader(mail)))
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		body, _ := ioutil.ReadAll(quotedprintable.NewReader(reader.R))
+		return header, string(body)
+	case <-time.After(5 * time.Second):
+		t.Fatal("mail is not received")
+	}
+	return nil, ""
+}
+
+func TestAdapterSmtp_Write(t *testing.T) {
+
+	host, mails := testSmtpServer(t)
+	smtpAdapter := NewAdapterSmtp()
+	err := smtpAdapter.Init(&SmtpConfig{
+		Host:     host,
+		From:     "logger@example.com",
+		To:       []string{"ops@example.com", "dev@example.com"},
+		Format:   "[%level_string%] %body%",
+		MaxMails: 1,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	err = smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "db is down", nil))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	header, body := testSmtpMail(t, mails)
+	if header.Get("Subject") != "[Error] db is down" || header.Get("To") != "ops@example.com, dev@example.com" {
+		t.Errorf("smtp mail header error: %v", header)
+	}
+	if body != "[Error] db is down\n" {
+		t.Errorf("smtp mail body error: %q", body)
+	}
+
+	// throttled
+	smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "db is down", nil))
+	if counters := smtpAdapter.(LoggerCounter).Counters(); counters["mails"] != 1 || counters["throttled"] != 1 {
+		t.Errorf("smtp counters error: %v", counters)
+	}
+}
+
+func TestAdapterSmtp_Digest(t *testing.T) {
+
+	host, mails := testSmtpServer(t)
+	smtpAdapter := NewAdapterSmtp()
+	err := smtpAdapter.Init(&SmtpConfig{
+		Host:              host,
+		From:              "logger@example.com",
+		To:                []string{"ops@example.com"},
+		Format:            "[%level_string%] %body%",
+		Digest:            time.Hour,
+		MaxDigestMessages: 3,
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_WARNING, "disk is full", nil))
+	smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_CRITICAL, "db is down", nil))
+	smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "retry failed", nil))
+	smtpAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_ERROR, "dropped", nil))
+	smtpAdapter.(LoggerCloser).Close()
+
+	header, body := testSmtpMail(t, mails)
+	if header.Get("Subject") != "[Critical] db is down (and 2 more)" {
+		t.Errorf("smtp digest subject error: %s", header.Get("Subject"))
+	}
+	expected := "[Warning] disk is full\n[Critical] db is down\n[Error] retry failed\n\n1 messages are dropped by throttling since the last mail\n"
+	if body != expected {
+		t.Errorf("smtp digest body error: %q", body)
+	}
+}
@@ -0,0 +1,172 @@
+package go_logger
+
+import (
+	"fmt"
+	"github.com/phachon/go-logger/utils"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// field names of the split message parts
+const (
+	LOGGER_FIELD_PART_ID = "part_id"
+	LOGGER_FIELD_PART    = "part"
+	LOGGER_FIELD_PARTS   = "parts"
+)
+
+// split message into parts by body, json record of every part is not bigger than maxSize
+// parts have fields "part_id", "part" (from 1) and "parts", maxSize <= 0 is unlimited
+// the message is not split if it fits or fields alone are too big
+func splitLoggerMessage(loggerMsg *loggerMessage, maxSize int) []*loggerMessage {
+	if maxSize <= 0 {
+		return []*loggerMessage{loggerMsg}
+	}
+	data, err := loggerMsg.MarshalJSON()
+	if err != nil || len(data) <= maxSize {
+		return []*loggerMessage{loggerMsg}
+	}
+
+	partId := utils.NewMisc().RandString(16)
+	// upper bound of part numbers, keeps the measured size of every part
+	maxParts := len(loggerMsg.Body)
+	fits := func(body string) bool {
+		partMsg := loggerMessagePart(loggerMsg, body, partId, maxParts, maxParts)
+		data, _ := partMsg.MarshalJSON()
+		return len(data) <= maxSize
+	}
+
+	bodies := []string{}
+	rest := loggerMsg.Body
+	for len(rest) > 0 {
+		// max body length fits, at rune boundary
+		n := 0
+		lo, hi := 1, len(rest)
+		for lo <= hi {
+			mid := (lo + hi) / 2
+			end := mid
+			for end < len(rest) && end > 0 && !utf8.RuneStart(rest[end]) {
+				end--
+			}
+			if end == 0 {
+				lo = mid + 1
+				continue
+			}
+			if fits(rest[:end]) {
+				n = end
+				lo = mid + 1
+			} else {
+				hi = end - 1
+			}
+		}
+		if n == 0 {
+			return []*loggerMessage{loggerMsg}
+		}
+		bodies = append(bodies, rest[:n])
+		rest = rest[n:]
+	}
+
+	parts := make([]*loggerMessage, 0, len(bodies))
+	for i, body := range bodies {
+		parts = append(parts, loggerMessagePart(loggerMsg, body, partId, i+1, len(bodies)))
+	}
+	return parts
+}
+
+// part of the message
+func loggerMessagePart(loggerMsg *loggerMessage, body string, partId string, part int, parts int) *loggerMessage {
+	partMsg := *loggerMsg
+	partMsg.Body = body
+	partMsg.Fields = make(map[string]interface{}, len(loggerMsg.Fields)+3)
+	for key, value := range loggerMsg.Fields {
+		partMsg.Fields[key] = value
+	}
+	partMsg.Fields[LOGGER_FIELD_PART_ID] = partId
+	partMsg.Fields[LOGGER_FIELD_PART] = part
+	partMsg.Fields[LOGGER_FIELD_PARTS] = parts
+	return &partMsg
+}
+
+// parse json record written by adapters to entry
+func ParseLogEntry(data []byte) (LogEntry, error) {
+	loggerMsg := &loggerMessage{}
+	err := loggerMsg.UnmarshalJSON(data)
+	if err != nil {
+		return LogEntry{}, err
+	}
+	return loggerMsg.Entry(), nil
+}
+
+// reassemble split parts to the original entries, in order of the first part
+// entries are not split or incomplete parts are returned as is
+func ReassembleEntries(entries []LogEntry) []LogEntry {
+	groups := map[string][]LogEntry{}
+	for _, entry := range entries {
+		partId, ok := entry.Fields[LOGGER_FIELD_PART_ID].(string)
+		if ok {
+			groups[partId] = append(groups[partId], entry)
+		}
+	}
+
+	result := make([]LogEntry, 0, len(entries))
+	done := map[string]bool{}
+	for _, entry := range entries {
+		partId, ok := entry.Fields[LOGGER_FIELD_PART_ID].(string)
+		if !ok {
+			result = append(result, entry)
+			continue
+		}
+		if done[partId] {
+			continue
+		}
+		done[partId] = true
+
+		group := groups[partId]
+		sort.SliceStable(group, func(i, j int) bool {
+			return entryPartNumber(group[i], LOGGER_FIELD_PART) < entryPartNumber(group[j], LOGGER_FIELD_PART)
+		})
+		complete := entryPartNumber(group[0], LOGGER_FIELD_PARTS) == len(group)
+		for i, part := range group {
+			if entryPartNumber(part, LOGGER_FIELD_PART) != i+1 {
+				complete = false
+			}
+		}
+		if !complete {
+			result = append(result, group...)
+			continue
+		}
+
+		whole := group[0]
+		whole.Fields = map[string]interface{}{}
+		for key, value := range group[0].Fields {
+			if key != LOGGER_FIELD_PART_ID && key != LOGGER_FIELD_PART && key != LOGGER_FIELD_PARTS {
+				whole.Fields[key] = value
+			}
+		}
+		if len(whole.Fields) == 0 {
+			whole.Fields = nil
+		}
+		body := ""
+		for _, part := range group {
+			body += part.Body
+		}
+		whole.Body = body
+		result = append(result, whole)
+	}
+	return result
+}
+
+// part number field, int or float64 of decoded json
+func entryPartNumber(entry LogEntry, key string) int {
+	switch value := entry.Fields[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case float64:
+		return int(value)
+	default:
+		number, _ := strconv.Atoi(fmt.Sprint(value))
+		return number
+	}
+}
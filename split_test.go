@@ -0,0 +1,57 @@
+package go_logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SplitOversizedRecord(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer:        buffer,
+		JsonFormat:    true,
+		MaxRecordSize: 512,
+	})
+
+	body := strings.Repeat("日志 \"quoted\" <tag> ", 100)
+	logger.WriterFields(LOGGER_LEVEL_INFO, body, map[string]interface{}{"user": "go"})
+	logger.Info("small")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("split record parts error: %d", len(lines))
+	}
+	entries := []LogEntry{}
+	for _, line := range lines {
+		if len(line) > 512 {
+			t.Errorf("split record size %d is bigger than max", len(line))
+		}
+		entry, err := ParseLogEntry([]byte(line))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	entries = ReassembleEntries(entries)
+	if len(entries) != 2 {
+		t.Fatalf("reassemble entries error: %d", len(entries))
+	}
+	if entries[0].Body != body || entries[0].Fields["user"] != "go" || len(entries[0].Fields) != 1 {
+		t.Errorf("reassemble entry error: %v", entries[0].Fields)
+	}
+	if entries[1].Body != "small" {
+		t.Error("reassemble entries order error")
+	}
+
+	incomplete := ReassembleEntries([]LogEntry{{Body: "a", Fields: map[string]interface{}{
+		LOGGER_FIELD_PART_ID: "x", LOGGER_FIELD_PART: 1, LOGGER_FIELD_PARTS: 2,
+	}}})
+	if len(incomplete) != 1 || incomplete[0].Fields[LOGGER_FIELD_PART_ID] != "x" {
+		t.Error("reassemble incomplete parts must return parts")
+	}
+}
@@ -0,0 +1,493 @@
+package go_logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SPOOL_DEFAULT_SEGMENT_SIZE   = 4 * 1024 * 1024
+	SPOOL_DEFAULT_MAX_SIZE       = 256 * 1024 * 1024
+	SPOOL_DEFAULT_RETRY_INTERVAL = 5 * time.Second
+)
+
+// extension of segment files and name of the index file in the spool directory
+const (
+	spoolSegmentExt = ".seg"
+	spoolIndexFile  = "spool.index"
+)
+
+// the index of spools is saved at most every spoolIndexInterval, and when the spool is closed
+const spoolIndexInterval = time.Second
+
+// on-disk spool of an adapter, see Logger.SetAdapterSpool()
+type SpoolConfig struct {
+
+	// directory of segment files and the index, one directory per adapter, eg: "./spool/api"
+	Dir string
+
+	// max size (byte) of a segment file, default 4MB
+	SegmentSize int64
+
+	// max size (byte) of all segments, the oldest segments are removed with their messages, default 256MB
+	MaxSize int64
+
+	// undelivered messages are retried every RetryInterval, default 5s
+	RetryInterval time.Duration
+}
+
+// spool of an output, messages are appended to segments "<sequence>.seg" before they're written to the adapter
+// the index "spool.index" is "<segment> <offset>" of the first undelivered message
+type adapterSpool struct {
+	lock     sync.Mutex
+	config   *SpoolConfig
+	output   *outputLogger
+	segments []uint64 // sequences of segment files, oldest first, messages are appended to the last
+	file     *os.File // the last segment
+	fileSize int64
+	size     int64 // size of all segments
+
+	// position of the first undelivered message
+	readSegment uint64
+	readOffset  int64
+	indexTime   time.Time
+	indexDirty  bool
+
+	pending   int  // undelivered messages
+	full      bool // messages are dropped by MaxSize since the last replay
+	nextRetry time.Time
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+// set the on-disk spool of attached adapter, nil config removes the spool
+// messages are appended and fsynced to the spool before they're written to the adapter, messages of failed writes
+// are kept and retried in order every RetryInterval, after restarts too: undelivered messages of the spool
+// are written when it's set again, delivery is at least once
+// messages are delivered when Write of the adapter returns nil, messages of SDK batchers when their batch is written
+//
+// example, keep error logs of the api while the collector is down:
+//	logger.SetAdapterSpool("api", &go_logger.SpoolConfig{Dir: "./spool/api", MaxSize: 64 * 1024 * 1024})
+func (logger *Logger) SetAdapterSpool(adapterName string, config *SpoolConfig) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name != adapterName {
+			continue
+		}
+		if config == nil {
+			output.closeSpool()
+			return nil
+		}
+		if config.Dir == "" {
+			return configError("", "Dir", "cannot be empty!")
+		}
+		spoolConfig := *config
+		if spoolConfig.SegmentSize <= 0 {
+			spoolConfig.SegmentSize = SPOOL_DEFAULT_SEGMENT_SIZE
+		}
+		if spoolConfig.MaxSize <= 0 {
+			spoolConfig.MaxSize = SPOOL_DEFAULT_MAX_SIZE
+		}
+		if spoolConfig.RetryInterval <= 0 {
+			spoolConfig.RetryInterval = SPOOL_DEFAULT_RETRY_INTERVAL
+		}
+		output.closeSpool()
+		spool, err := openSpool(&spoolConfig, output)
+		if err != nil {
+			return err
+		}
+		output.spool.Store(spool)
+		go spool.startRetry()
+		return nil
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// undelivered messages of the spool of attached adapter
+func (logger *Logger) AdapterSpooled(adapterName string) int {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			if spool, _ := output.spool.Load().(*adapterSpool); spool != nil {
+				spool.lock.Lock()
+				defer spool.lock.Unlock()
+				return spool.pending
+			}
+		}
+	}
+	return 0
+}
+
+// close the spool of the output, undelivered messages are kept on disk
+func (output *outputLogger) closeSpool() {
+	spool, _ := output.spool.Load().(*adapterSpool)
+	if spool == nil {
+		return
+	}
+	output.spool.Store((*adapterSpool)(nil))
+	close(spool.quit)
+	<-spool.done
+
+	spool.lock.Lock()
+	defer spool.lock.Unlock()
+	spool.saveIndex()
+	if spool.file != nil {
+		spool.file.Close()
+		spool.file = nil
+	}
+}
+
+// open the spool directory, a torn last message of a crash is removed
+func openSpool(config *SpoolConfig, output *outputLogger) (*adapterSpool, error) {
+	err := os.MkdirAll(config.Dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	spool := &adapterSpool{config: config, output: output, quit: make(chan struct{}), done: make(chan struct{})}
+
+	names, err := filepath.Glob(filepath.Join(config.Dir, "*"+spoolSegmentExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		sequence, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), spoolSegmentExt), 10, 64)
+		if err == nil {
+			spool.segments = append(spool.segments, sequence)
+		}
+	}
+	sort.Slice(spool.segments, func(i, j int) bool { return spool.segments[i] < spool.segments[j] })
+	if len(spool.segments) == 0 {
+		spool.segments = []uint64{1}
+	}
+
+	// remove the torn last message
+	last := spool.segmentName(spool.segments[len(spool.segments)-1])
+	content, err := ioutil.ReadFile(last)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if end := int64(bytes.LastIndexByte(content, '\n') + 1); end < int64(len(content)) {
+		err = os.Truncate(last, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	spool.file, err = os.OpenFile(last, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	for _, sequence := range spool.segments {
+		if info, err := os.Stat(spool.segmentName(sequence)); err == nil {
+			spool.size += info.Size()
+			spool.fileSize = info.Size()
+		}
+	}
+
+	spool.readSegment = spool.segments[0]
+	index, err := ioutil.ReadFile(filepath.Join(config.Dir, spoolIndexFile))
+	if err == nil {
+		fields := strings.Fields(string(index))
+		if len(fields) == 2 {
+			segment, segmentErr := strconv.ParseUint(fields[0], 10, 64)
+			offset, offsetErr := strconv.ParseInt(fields[1], 10, 64)
+			if segmentErr == nil && offsetErr == nil && segment >= spool.segments[0] {
+				spool.readSegment, spool.readOffset = segment, offset
+			}
+		}
+	}
+	for _, sequence := range spool.segments {
+		if sequence < spool.readSegment {
+			continue
+		}
+		offset := int64(0)
+		if sequence == spool.readSegment {
+			offset = spool.readOffset
+		}
+		spool.pending += spool.countMessages(sequence, offset)
+	}
+	return spool, nil
+}
+
+func (spool *adapterSpool) segmentName(sequence uint64) string {
+	return filepath.Join(spool.config.Dir, fmt.Sprintf("%020d%s", sequence, spoolSegmentExt))
+}
+
+// messages of the segment after offset
+func (spool *adapterSpool) countMessages(sequence uint64, offset int64) int {
+	file, err := os.Open(spool.segmentName(sequence))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0
+	}
+	count := 0
+	reader := bufio.NewReader(file)
+	for {
+		_, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+// append the message to the spool, write it if no message is waiting for a retry
+// messages are written in order, the message is delivered later if older messages are undelivered
+func (spool *adapterSpool) write(loggerMsg *loggerMessage) error {
+	spool.lock.Lock()
+	defer spool.lock.Unlock()
+
+	err := spool.append(loggerMsg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: spool %s write failed, error: %v\n", spool.config.Dir, err)
+		return spool.output.transmit(loggerMsg)
+	}
+	spool.pending++
+	if spool.pending > 1 && time.Now().Before(spool.nextRetry) {
+		return nil
+	}
+	spool.replay()
+	return nil
+}
+
+// append the message to the last segment and fsync, the oldest segments are removed if the spool is full
+func (spool *adapterSpool) append(loggerMsg *loggerMessage) error {
+	jsonByte, err := loggerMsg.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	if spool.file == nil {
+		return ErrLoggerClosed
+	}
+	record := append(jsonByte, '\n')
+	if spool.fileSize > 0 && spool.fileSize+int64(len(record)) > spool.config.SegmentSize {
+		err := spool.nextSegment()
+		if err != nil {
+			return err
+		}
+	}
+	n, err := spool.file.Write(record)
+	spool.fileSize += int64(n)
+	spool.size += int64(n)
+	if err != nil {
+		return err
+	}
+	err = spool.file.Sync()
+	if err != nil {
+		return err
+	}
+	spool.truncate()
+	return nil
+}
+
+// start a new segment
+func (spool *adapterSpool) nextSegment() error {
+	sequence := spool.segments[len(spool.segments)-1] + 1
+	file, err := os.OpenFile(spool.segmentName(sequence), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	spool.file.Close()
+	spool.file = file
+	spool.fileSize = 0
+	spool.segments = append(spool.segments, sequence)
+	return nil
+}
+
+// remove the oldest segments while the spool is bigger than MaxSize, the last segment is kept
+func (spool *adapterSpool) truncate() {
+	dropped := 0
+	for spool.size > spool.config.MaxSize && len(spool.segments) > 1 {
+		sequence := spool.segments[0]
+		if sequence >= spool.readSegment {
+			offset := int64(0)
+			if sequence == spool.readSegment {
+				offset = spool.readOffset
+			}
+			dropped += spool.countMessages(sequence, offset)
+			spool.readSegment, spool.readOffset = spool.segments[1], 0
+			spool.indexDirty = true
+		}
+		spool.removeSegment(sequence)
+	}
+	if dropped > 0 {
+		spool.pending -= dropped
+		if !spool.full {
+			spool.full = true
+			fmt.Fprintf(os.Stderr, "logger: spool %s is full, the oldest messages of adapter %s are dropped\n", spool.config.Dir, spool.output.Name)
+		}
+		spool.saveIndex()
+	}
+}
+
+func (spool *adapterSpool) removeSegment(sequence uint64) {
+	name := spool.segmentName(sequence)
+	if info, err := os.Stat(name); err == nil {
+		spool.size -= info.Size()
+	}
+	os.Remove(name)
+	spool.segments = spool.segments[1:]
+}
+
+// write undelivered messages in order until a write fails, expired messages are skipped
+func (spool *adapterSpool) replay() {
+	failed := !spool.nextRetry.IsZero()
+	for spool.pending > 0 {
+		segment := spool.readSegment
+		file, err := os.Open(spool.segmentName(spool.readSegment))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: spool %s read failed, error: %v\n", spool.config.Dir, err)
+			spool.nextRetry = time.Now().Add(spool.config.RetryInterval)
+			return
+		}
+		ok := spool.replaySegment(file)
+		file.Close()
+		if !ok {
+			return
+		}
+		if spool.readSegment == segment {
+			// the last segment is read, messages are counted from a lost index
+			break
+		}
+	}
+	spool.nextRetry = time.Time{}
+	spool.full = false
+	if failed {
+		fmt.Fprintf(os.Stderr, "logger: adapter %s is recovered, spooled messages are written\n", spool.output.Name)
+	}
+	spool.pending = 0
+	spool.saveIndex()
+}
+
+// write messages of the read segment from the read offset, false if a write failed
+// the segment is removed when all of its messages are delivered, except the last segment
+func (spool *adapterSpool) replaySegment(file *os.File) bool {
+	_, err := file.Seek(spool.readOffset, io.SeekStart)
+	if err != nil {
+		return false
+	}
+	// messages written to buffering adapters are delivered when their buffer is written
+	buffer, _ := spool.output.LoggerAbstract.(loggerBuffer)
+	offset, written := spool.readOffset, 0
+	reader := bufio.NewReader(file)
+	for spool.pending > written {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		loggerMsg := &loggerMessage{}
+		if err := loggerMsg.UnmarshalJSON(line[:len(line)-1]); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: spool %s illegal message is skipped, error: %v\n", spool.config.Dir, err)
+		} else if !spool.output.expired(loggerMsg) {
+			err := spool.output.transmit(loggerMsg)
+			if err != nil {
+				return spool.writeFailed(err)
+			}
+		}
+		offset += int64(len(line))
+		written++
+		if buffer == nil {
+			spool.delivered(offset, written)
+			written = 0
+		}
+	}
+	if written > 0 {
+		err = buffer.writeBuffered()
+		if err != nil {
+			return spool.writeFailed(err)
+		}
+		spool.delivered(offset, written)
+	}
+	if spool.readSegment != spool.segments[len(spool.segments)-1] {
+		spool.removeSegment(spool.readSegment)
+		spool.readSegment, spool.readOffset = spool.segments[0], 0
+		spool.indexDirty = true
+	}
+	spool.saveIndexInterval()
+	return true
+}
+
+// messages before offset are delivered
+func (spool *adapterSpool) delivered(offset int64, count int) {
+	spool.readOffset = offset
+	spool.pending -= count
+	spool.indexDirty = true
+}
+
+// retry undelivered messages after RetryInterval, false
+func (spool *adapterSpool) writeFailed(err error) bool {
+	if spool.nextRetry.IsZero() {
+		fmt.Fprintf(os.Stderr, "logger: adapter %s write failed, error: %v, messages are spooled to %s\n", spool.output.Name, err, spool.config.Dir)
+	}
+	spool.nextRetry = time.Now().Add(spool.config.RetryInterval)
+	spool.saveIndexInterval()
+	return false
+}
+
+// save the index if it's not saved in spoolIndexInterval
+func (spool *adapterSpool) saveIndexInterval() {
+	if time.Since(spool.indexTime) >= spoolIndexInterval {
+		spool.saveIndex()
+	}
+}
+
+// save the read position to the index atomically
+func (spool *adapterSpool) saveIndex() {
+	if !spool.indexDirty {
+		return
+	}
+	name := filepath.Join(spool.config.Dir, spoolIndexFile)
+	index := strconv.FormatUint(spool.readSegment, 10) + " " + strconv.FormatInt(spool.readOffset, 10) + "\n"
+	err := ioutil.WriteFile(name+".tmp", []byte(index), 0644)
+	if err == nil {
+		err = os.Rename(name+".tmp", name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: spool %s index write failed, error: %v\n", spool.config.Dir, err)
+		return
+	}
+	spool.indexTime = time.Now()
+	spool.indexDirty = false
+}
+
+// retry undelivered messages every RetryInterval until the spool is closed
+func (spool *adapterSpool) startRetry() {
+	defer close(spool.done)
+
+	ticker := time.NewTicker(spool.config.RetryInterval)
+	defer ticker.Stop()
+	for {
+		spool.lock.Lock()
+		if spool.pending > 0 && !time.Now().Before(spool.nextRetry) {
+			spool.replay()
+		}
+		spool.saveIndexInterval()
+		spool.lock.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-spool.quit:
+			return
+		}
+	}
+}
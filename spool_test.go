@@ -0,0 +1,163 @@
+package go_logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writer failing while down
+type outageWriter struct {
+	lock sync.Mutex
+	down bool
+	buf  bytes.Buffer
+}
+
+func (ow *outageWriter) Write(p []byte) (int, error) {
+	ow.lock.Lock()
+	defer ow.lock.Unlock()
+	if ow.down {
+		return 0, errors.New("connection refused")
+	}
+	return ow.buf.Write(p)
+}
+
+func (ow *outageWriter) setDown(down bool) {
+	ow.lock.Lock()
+	ow.down = down
+	ow.lock.Unlock()
+}
+
+func (ow *outageWriter) String() string {
+	ow.lock.Lock()
+	defer ow.lock.Unlock()
+	return ow.buf.String()
+}
+
+func newSpoolLogger(t *testing.T, writer *outageWriter, config *SpoolConfig) *Logger {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: writer, Format: "%body%"})
+	err := logger.SetAdapterSpool("writer", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func waitSpooled(logger *Logger, spooled int) bool {
+	for i := 0; i < 200; i++ {
+		if logger.AdapterSpooled("writer") == spooled {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestLogger_SetAdapterSpool(t *testing.T) {
+
+	dir := t.TempDir()
+	writer := &outageWriter{}
+	logger := newSpoolLogger(t, writer, &SpoolConfig{Dir: dir, RetryInterval: 50 * time.Millisecond})
+
+	logger.Info("first")
+	writer.setDown(true)
+	logger.Info("second")
+	logger.Info("third")
+	if logger.AdapterSpooled("writer") != 2 || writer.String() != "first\n" {
+		t.Fatalf("spool outage error: %d %q", logger.AdapterSpooled("writer"), writer.String())
+	}
+
+	// replayed in order after the outage
+	writer.setDown(false)
+	if !waitSpooled(logger, 0) || writer.String() != "first\nsecond\nthird\n" {
+		t.Fatalf("spool replay error: %q", writer.String())
+	}
+
+	// replayed after restart
+	writer.setDown(true)
+	logger.Info("fourth")
+	logger.Close(context.Background())
+	writer.setDown(false)
+	logger = newSpoolLogger(t, writer, &SpoolConfig{Dir: dir, RetryInterval: time.Hour})
+	if !waitSpooled(logger, 0) || writer.String() != "first\nsecond\nthird\nfourth\n" {
+		t.Errorf("spool restart replay error: %q", writer.String())
+	}
+	logger.Info("fifth")
+	logger.Close(context.Background())
+
+	logger = newSpoolLogger(t, writer, &SpoolConfig{Dir: dir})
+	if logger.AdapterSpooled("writer") != 0 || strings.Count(writer.String(), "\n") != 5 {
+		t.Errorf("spool delivered messages are replayed again: %q", writer.String())
+	}
+	logger.SetAdapterSpool("writer", nil)
+}
+
+func TestLogger_SpoolMaxSize(t *testing.T) {
+
+	writer := &outageWriter{down: true}
+	logger := newSpoolLogger(t, writer, &SpoolConfig{Dir: t.TempDir(), SegmentSize: 1024, MaxSize: 4096, RetryInterval: time.Hour})
+	defer logger.Close(context.Background())
+
+	for i := 0; i < 100; i++ {
+		logger.Infof("message %d", i)
+	}
+	spool := logger.outputs[0].spool.Load().(*adapterSpool)
+	spooled := logger.AdapterSpooled("writer")
+	if spooled >= 100 || spooled == 0 || spool.size > 4096 {
+		t.Fatalf("spool max size error: %d messages, %d bytes, %d segments", spooled, spool.size, len(spool.segments))
+	}
+
+	writer.setDown(false)
+	spool.lock.Lock()
+	spool.replay()
+	spool.lock.Unlock()
+	lines := strings.Split(strings.TrimSpace(writer.String()), "\n")
+	if len(lines) != spooled || lines[len(lines)-1] != "message 99" || len(spool.segments) != 1 {
+		t.Errorf("spool replay of the newest messages error: %d lines, %d segments", len(lines), len(spool.segments))
+	}
+}
+
+func TestLogger_SpoolBatcher(t *testing.T) {
+
+	batcher := &sdkTestSwitchBatcher{down: true, interval: time.Hour, written: make(chan []*LogEntry, 10)}
+	adapter := WrapAdapter(batcher)
+	err := adapter.Init(&sdkTestConfig{BatchSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAdapter("writer", LOGGER_LEVEL_DEBUG, adapter)
+	err = logger.SetAdapterSpool("writer", &SpoolConfig{Dir: t.TempDir(), RetryInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logger.Close(context.Background())
+
+	// messages of the failed batch are kept in the spool
+	logger.Info("first")
+	logger.Info("second")
+	if logger.AdapterSpooled("writer") != 2 {
+		t.Errorf("messages of failed sdk batch are not spooled: %d", logger.AdapterSpooled("writer"))
+	}
+
+	batcher.setDown(false)
+	if !waitSpooled(logger, 0) {
+		t.Fatalf("spooled messages are not delivered: %d", logger.AdapterSpooled("writer"))
+	}
+	bodies := []string{}
+	for len(batcher.written) > 0 {
+		for _, entry := range <-batcher.written {
+			bodies = append(bodies, entry.Body)
+		}
+	}
+	if strings.Join(bodies, ",") != "first,second" {
+		t.Errorf("spooled sdk batch error: %v", bodies)
+	}
+}
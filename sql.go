@@ -0,0 +1,364 @@
+package go_logger
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sql logger config
+type SqlConfig struct {
+
+	// log level of queries, default LOGGER_LEVEL_DEBUG, failed queries are logged by LOGGER_LEVEL_ERROR
+	// 0 is unset, queries are not logged by LOGGER_LEVEL_EMERGENCY
+	Level int
+
+	// queries take longer than SlowThreshold are logged by SlowLevel, 0 is disabled
+	SlowThreshold time.Duration
+
+	// log level of slow queries, default LOGGER_LEVEL_WARNING, 0 is unset
+	SlowLevel int
+
+	// is log query args
+	LogArgs bool
+
+	// redact query arg before log, eg: return "***" for password
+	// name is empty and ordinal starts from 1 for positional args
+	ArgRedactor func(name string, ordinal int, value driver.Value) driver.Value
+
+	// message "sql <action>" with fields query, args, duration_ms, tx, error and slow, default they're in the message
+	Fields bool
+}
+
+// wrap a database/sql driver, every query, exec and transaction is logged
+//
+// example:
+//	sql.Register("mysql-logger", logger.WrapSqlDriver(&mysql.MySQLDriver{}, &go_logger.SqlConfig{
+//		SlowThreshold: 200 * time.Millisecond,
+//	}))
+//	db, err := sql.Open("mysql-logger", dsn)
+func (logger *Logger) WrapSqlDriver(d driver.Driver, config *SqlConfig) driver.Driver {
+	return &sqlDriver{
+		driver: d,
+		sqlLog: newSqlLog(logger, config),
+	}
+}
+
+// wrap a database/sql connector, use it with sql.OpenDB()
+func (logger *Logger) WrapSqlConnector(connector driver.Connector, config *SqlConfig) driver.Connector {
+	return &sqlConnector{
+		connector: connector,
+		sqlLog:    newSqlLog(logger, config),
+	}
+}
+
+type sqlLog struct {
+	logger *Logger
+	config *SqlConfig
+}
+
+func newSqlLog(logger *Logger, config *SqlConfig) *sqlLog {
+	// defaults are not written to the config of the caller
+	sqlConfig := SqlConfig{}
+	if config != nil {
+		sqlConfig = *config
+	}
+	if sqlConfig.Level == 0 || levelNames()[sqlConfig.Level] == "" {
+		sqlConfig.Level = LOGGER_LEVEL_DEBUG
+	}
+	if sqlConfig.SlowLevel == 0 || levelNames()[sqlConfig.SlowLevel] == "" {
+		sqlConfig.SlowLevel = LOGGER_LEVEL_WARNING
+	}
+	return &sqlLog{
+		logger: logger,
+		config: &sqlConfig,
+	}
+}
+
+// log a finished driver call
+func (sl *sqlLog) log(action string, txId string, query string, args []driver.NamedValue, startTime time.Time, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	duration := time.Since(startTime)
+	if sl.config.Fields {
+		sl.logFields(action, txId, query, args, duration, err)
+		return
+	}
+
+	fields := []string{"sql " + action}
+	if query != "" {
+		fields = append(fields, fmt.Sprintf("query=%q", query))
+	}
+	if sl.config.LogArgs && len(args) > 0 {
+		fields = append(fields, "args="+sl.formatArgs(args))
+	}
+	fields = append(fields, "duration="+duration.String())
+	if txId != "" {
+		fields = append(fields, "tx="+txId)
+	}
+
+	level := sl.config.Level
+	if err != nil {
+		level = LOGGER_LEVEL_ERROR
+		fields = append(fields, "error="+err.Error())
+	} else if sl.config.SlowThreshold > 0 && duration >= sl.config.SlowThreshold {
+		level = sl.config.SlowLevel
+		fields = append(fields, "slow=true")
+	}
+
+	sl.logger.write(2, level, strings.Join(fields, " "), nil)
+}
+
+// log a finished driver call with fields
+func (sl *sqlLog) logFields(action string, txId string, query string, args []driver.NamedValue, duration time.Duration, err error) {
+	fields := map[string]interface{}{"duration_ms": float64(duration.Microseconds()) / 1000}
+	if query != "" {
+		fields["query"] = query
+	}
+	if sl.config.LogArgs && len(args) > 0 {
+		fields["args"] = sl.formatArgs(args)
+	}
+	if txId != "" {
+		fields["tx"] = txId
+	}
+
+	level := sl.config.Level
+	if err != nil {
+		level = LOGGER_LEVEL_ERROR
+		fields["error"] = err.Error()
+	} else if sl.config.SlowThreshold > 0 && duration >= sl.config.SlowThreshold {
+		level = sl.config.SlowLevel
+		fields["slow"] = true
+	}
+
+	sl.logger.WriterFields(level, "sql "+action, fields)
+}
+
+func (sl *sqlLog) formatArgs(args []driver.NamedValue) string {
+	values := make([]string, 0, len(args))
+	for _, arg := range args {
+		value := arg.Value
+		if sl.config.ArgRedactor != nil {
+			value = sl.config.ArgRedactor(arg.Name, arg.Ordinal, value)
+		}
+		if arg.Name != "" {
+			values = append(values, fmt.Sprintf("%s:%v", arg.Name, value))
+			continue
+		}
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+type sqlDriver struct {
+	driver driver.Driver
+	sqlLog *sqlLog
+}
+
+func (sd *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := sd.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, sqlLog: sd.sqlLog}, nil
+}
+
+type sqlConnector struct {
+	connector driver.Connector
+	sqlLog    *sqlLog
+}
+
+func (sc *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := sc.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, sqlLog: sc.sqlLog}, nil
+}
+
+func (sc *sqlConnector) Driver() driver.Driver {
+	return &sqlDriver{driver: sc.connector.Driver(), sqlLog: sc.sqlLog}
+}
+
+// a driver conn is not used concurrently, so the current tx id needs no lock
+type sqlConn struct {
+	conn   driver.Conn
+	sqlLog *sqlLog
+	txId   string
+}
+
+func (sc *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return sc.PrepareContext(context.Background(), query)
+}
+
+func (sc *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if connPrepare, ok := sc.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = connPrepare.PrepareContext(ctx, query)
+	} else {
+		stmt, err = sc.conn.Prepare(query)
+	}
+	if err != nil {
+		sc.sqlLog.log("prepare", sc.txId, query, nil, time.Now(), err)
+		return nil, err
+	}
+	return &sqlStmt{stmt: stmt, conn: sc, query: query}, nil
+}
+
+func (sc *sqlConn) Close() error {
+	return sc.conn.Close()
+}
+
+func (sc *sqlConn) Begin() (driver.Tx, error) {
+	return sc.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (sc *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	startTime := time.Now()
+	var tx driver.Tx
+	var err error
+	if connBegin, ok := sc.conn.(driver.ConnBeginTx); ok {
+		tx, err = connBegin.BeginTx(ctx, opts)
+	} else {
+		tx, err = sc.conn.Begin()
+	}
+	txId := sc.sqlLog.logger.NewID()
+	sc.sqlLog.log("begin", txId, "", nil, startTime, err)
+	if err != nil {
+		return nil, err
+	}
+	sc.txId = txId
+	return &sqlTx{tx: tx, conn: sc}, nil
+}
+
+func (sc *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := sc.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	startTime := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	sc.sqlLog.log("exec", sc.txId, query, args, startTime, err)
+	return result, err
+}
+
+func (sc *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := sc.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	startTime := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	sc.sqlLog.log("query", sc.txId, query, args, startTime, err)
+	return rows, err
+}
+
+func (sc *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := sc.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (sc *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := sc.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (sc *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := sc.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type sqlTx struct {
+	tx   driver.Tx
+	conn *sqlConn
+}
+
+func (st *sqlTx) Commit() error {
+	startTime := time.Now()
+	err := st.tx.Commit()
+	st.conn.sqlLog.log("commit", st.conn.txId, "", nil, startTime, err)
+	st.conn.txId = ""
+	return err
+}
+
+func (st *sqlTx) Rollback() error {
+	startTime := time.Now()
+	err := st.tx.Rollback()
+	st.conn.sqlLog.log("rollback", st.conn.txId, "", nil, startTime, err)
+	st.conn.txId = ""
+	return err
+}
+
+type sqlStmt struct {
+	stmt  driver.Stmt
+	conn  *sqlConn
+	query string
+}
+
+func (ss *sqlStmt) Close() error {
+	return ss.stmt.Close()
+}
+
+func (ss *sqlStmt) NumInput() int {
+	return ss.stmt.NumInput()
+}
+
+func (ss *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return ss.ExecContext(context.Background(), namedValues(args))
+}
+
+func (ss *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return ss.QueryContext(context.Background(), namedValues(args))
+}
+
+func (ss *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	startTime := time.Now()
+	var result driver.Result
+	var err error
+	if stmtExec, ok := ss.stmt.(driver.StmtExecContext); ok {
+		result, err = stmtExec.ExecContext(ctx, args)
+	} else {
+		result, err = ss.stmt.Exec(driverValues(args))
+	}
+	ss.conn.sqlLog.log("exec", ss.conn.txId, ss.query, args, startTime, err)
+	return result, err
+}
+
+func (ss *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	startTime := time.Now()
+	var rows driver.Rows
+	var err error
+	if stmtQuery, ok := ss.stmt.(driver.StmtQueryContext); ok {
+		rows, err = stmtQuery.QueryContext(ctx, args)
+	} else {
+		rows, err = ss.stmt.Query(driverValues(args))
+	}
+	ss.conn.sqlLog.log("query", ss.conn.txId, ss.query, args, startTime, err)
+	return rows, err
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	values := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		values[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return values
+}
+
+func driverValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
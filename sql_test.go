@@ -0,0 +1,206 @@
+package go_logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fake driver, it only supports prepared statements
+type fakeSqlDriver struct{}
+
+type fakeSqlConn struct{}
+
+type fakeSqlStmt struct {
+	query string
+}
+
+type fakeSqlRows struct{}
+
+// driver opening connections of the driver set by the running test, drivers are registered once by name
+type fakeDriverSwitch struct {
+	driver driver.Driver
+}
+
+func (fd *fakeDriverSwitch) Open(name string) (driver.Conn, error) {
+	return fd.driver.Open(name)
+}
+
+var fakeLoggerDriver = &fakeDriverSwitch{}
+
+func init() {
+	sql.Register("fake-logger", fakeLoggerDriver)
+}
+
+func (fd *fakeSqlDriver) Open(name string) (driver.Conn, error) {
+	return &fakeSqlConn{}, nil
+}
+
+func (fc *fakeSqlConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSqlStmt{query: query}, nil
+}
+
+func (fc *fakeSqlConn) Close() error {
+	return nil
+}
+
+func (fc *fakeSqlConn) Begin() (driver.Tx, error) {
+	return fc, nil
+}
+
+func (fc *fakeSqlConn) Commit() error {
+	return nil
+}
+
+func (fc *fakeSqlConn) Rollback() error {
+	return nil
+}
+
+func (fs *fakeSqlStmt) Close() error {
+	return nil
+}
+
+func (fs *fakeSqlStmt) NumInput() int {
+	return -1
+}
+
+func (fs *fakeSqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(fs.query, "BAD") {
+		return nil, errors.New("syntax error")
+	}
+	if strings.HasPrefix(fs.query, "SLOW") {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (fs *fakeSqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeSqlRows{}, nil
+}
+
+func (fr *fakeSqlRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (fr *fakeSqlRows) Close() error {
+	return nil
+}
+
+func (fr *fakeSqlRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+func TestLogger_WrapSqlDriver(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, nil)
+	fakeLoggerDriver.driver = logger.WrapSqlDriver(&fakeSqlDriver{}, &SqlConfig{
+		Level:         LOGGER_LEVEL_INFO,
+		SlowThreshold: time.Millisecond,
+		LogArgs:       true,
+		ArgRedactor: func(name string, ordinal int, value driver.Value) driver.Value {
+			if ordinal == 2 {
+				return "***"
+			}
+			return value
+		},
+	})
+	db, err := sql.Open("fake-logger", "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer db.Close()
+
+	db.Exec("INSERT INTO user (name, password) VALUES (?, ?)", "go-logger", "123456")
+	db.Exec("BAD QUERY")
+	db.Exec("SLOW QUERY")
+	rows, _ := db.Query("SELECT id FROM user")
+	rows.Close()
+
+	tx, _ := db.Begin()
+	tx.Exec("UPDATE user SET name = ?", "logger")
+	tx.Commit()
+
+	lines := strings.Split(strings.TrimSpace(readLog()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("sql log lines error: %v", lines)
+	}
+	expects := []string{
+		`[Info] sql exec query="INSERT INTO user (name, password) VALUES (?, ?)" args=[go-logger, ***]`,
+		`[Error] sql exec query="BAD QUERY"`,
+		`[Warning] sql exec query="SLOW QUERY"`,
+		`[Info] sql query query="SELECT id FROM user"`,
+		`[Info] sql begin`,
+		`[Info] sql exec query="UPDATE user SET name = ?" args=[logger]`,
+		`[Info] sql commit`,
+	}
+	for i, expect := range expects {
+		if !strings.Contains(lines[i], expect) {
+			t.Errorf("sql log line %d error: %s", i, lines[i])
+		}
+	}
+	txId := strings.TrimSpace(lines[4][strings.Index(lines[4], "tx="):])
+	if !strings.Contains(lines[5], txId) || !strings.Contains(lines[6], txId) {
+		t.Error("sql log tx id error")
+	}
+}
+
+func TestLogger_WrapSqlDriverFields(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+	db := sql.OpenDB(logger.WrapSqlConnector(&fakeSqlConnector{}, &SqlConfig{Level: LOGGER_LEVEL_INFO, LogArgs: true, Fields: true}))
+	defer db.Close()
+
+	db.Exec("INSERT INTO user (name) VALUES (?)", "go-logger")
+	db.Exec("BAD QUERY")
+
+	entries := recorded.Entries()
+	if len(entries) != 2 || entries[0].Body != "sql exec" || entries[0].Fields["query"] != "INSERT INTO user (name) VALUES (?)" || entries[0].Fields["args"] != "[go-logger]" {
+		t.Fatalf("sql log fields error: %v", entries)
+	}
+	if _, ok := entries[0].Fields["duration_ms"].(float64); !ok {
+		t.Errorf("sql log duration error: %v", entries[0].Fields)
+	}
+	if entries[1].Level != LOGGER_LEVEL_ERROR || entries[1].Fields["error"] != "syntax error" {
+		t.Errorf("sql log error field error: %v", entries[1])
+	}
+}
+
+func TestLogger_WrapSqlDriverDefaultLevels(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+	config := &SqlConfig{SlowThreshold: time.Millisecond}
+	db := sql.OpenDB(logger.WrapSqlConnector(&fakeSqlConnector{}, config))
+	defer db.Close()
+
+	db.Exec("INSERT INTO user (name) VALUES (?)", "go-logger")
+	db.Exec("SLOW QUERY")
+
+	entries := recorded.Entries()
+	if len(entries) != 2 || entries[0].Level != LOGGER_LEVEL_DEBUG || entries[1].Level != LOGGER_LEVEL_WARNING {
+		t.Fatalf("sql log default levels error: %v", entries)
+	}
+	if config.Level != 0 || config.SlowLevel != 0 {
+		t.Errorf("defaults must not be written to the config: %+v", config)
+	}
+}
+
+type fakeSqlConnector struct{}
+
+func (fc *fakeSqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeSqlConn{}, nil
+}
+
+func (fc *fakeSqlConnector) Driver() driver.Driver {
+	return &fakeSqlDriver{}
+}
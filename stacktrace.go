@@ -0,0 +1,159 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// field of the stack trace, format placeholder "%stacktrace%"
+const LOGGER_FIELD_STACKTRACE = "stacktrace"
+
+// fields of ErrorWithStack
+const (
+	LOGGER_FIELD_ERROR       = "error"
+	LOGGER_FIELD_ERROR_TYPE  = "error.type"
+	LOGGER_FIELD_ERROR_CHAIN = "error.chain"
+)
+
+// stack traces are not captured, see SetStacktraceLevel()
+const STACKTRACE_LEVEL_NONE = math.MinInt32
+
+// max frames of stack traces
+const STACKTRACE_MAX_FRAMES = 32
+
+// capture the stack trace of the caller for messages at or above the level, eg: LOGGER_LEVEL_ERROR
+// the stack trace is field "stacktrace", default STACKTRACE_LEVEL_NONE
+func (logger *Logger) SetStacktraceLevel(level int) {
+	atomic.StoreInt32(&logger.stackLevel, int32(level))
+}
+
+// message fields with the stack trace at callDepth of the caller of withStacktrace if the level captures it
+func (logger *Logger) withStacktrace(callDepth int, level int, fields map[string]interface{}) map[string]interface{} {
+	if level > int(atomic.LoadInt32(&logger.stackLevel)) {
+		return fields
+	}
+	if _, ok := fields[LOGGER_FIELD_STACKTRACE]; ok {
+		return fields
+	}
+	return stacktraceFields(fields, stackOf(callDepth+1))
+}
+
+// copy of the fields with the stack trace
+func stacktraceFields(fields map[string]interface{}, stack string) map[string]interface{} {
+	stackFields := make(map[string]interface{}, len(fields)+1)
+	for key, value := range fields {
+		stackFields[key] = value
+	}
+	stackFields[LOGGER_FIELD_STACKTRACE] = stack
+	return stackFields
+}
+
+// stack trace from the caller at callDepth of the caller of stackOf, helpers on the top are skipped
+// frames are "function\n\tfile:line" lines
+func stackOf(callDepth int) string {
+	pcs := make([]uintptr, STACKTRACE_MAX_FRAMES+CALLER_MAX_DEPTH)
+	// frames of runtime.Callers and stackOf
+	n := runtime.Callers(callDepth+2, pcs)
+	runtimeFrames := runtime.CallersFrames(pcs[:n])
+
+	frames := []string{}
+	top := true
+	for len(frames) < STACKTRACE_MAX_FRAMES {
+		frame, more := runtimeFrames.Next()
+		if top && atomic.LoadInt32(&helpers) > 0 {
+			if _, ok := helperFunctions.Load(frame.Function); ok && more {
+				continue
+			}
+		}
+		top = false
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+		frames = append(frames, frame.Function+"\n\t"+frame.File+":"+strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return strings.Join(frames, "\n")
+}
+
+// log the error at error level with its chain, the body is the error message
+// fields are "error", "error.type" (go type of the innermost error) and "error.chain" (messages of wrapped errors, outermost first)
+//
+// example:
+//	if err := db.Ping(); err != nil {
+//		logger.Errore(err)
+//	}
+func (logger *Logger) Errore(err error) {
+	if err == nil || !logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	logger.write(2, LOGGER_LEVEL_ERROR, err.Error(), ErrorFields(err))
+}
+
+// log the error at error level with its chain and bound fields
+func (child *ChildLogger) Errore(err error) {
+	if err == nil || !child.logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ERROR, child.prefix+err.Error(), child.messageFields(ErrorFields(err)))
+}
+
+// log the error at error level with its chain and the stack trace of the caller
+// fields are the ones of Errore() and "stacktrace"
+//
+// example:
+//	if err != nil {
+//		logger.ErrorWithStack(fmt.Errorf("load config: %w", err))
+//	}
+func (logger *Logger) ErrorWithStack(err error) {
+	if err == nil || !logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	stack := stackOf(1 + int(atomic.LoadInt32(&logger.callerSkip)))
+	logger.write(2, LOGGER_LEVEL_ERROR, err.Error(), stacktraceFields(ErrorFields(err), stack))
+}
+
+// log the error at error level with its chain, the stack trace and bound fields
+func (child *ChildLogger) ErrorWithStack(err error) {
+	if err == nil || !child.logger.enabled(LOGGER_LEVEL_ERROR) {
+		return
+	}
+	stack := stackOf(1 + child.callerSkip + int(atomic.LoadInt32(&child.logger.callerSkip)))
+	child.logger.write(2+child.callerSkip, LOGGER_LEVEL_ERROR, child.prefix+err.Error(), child.messageFields(stacktraceFields(ErrorFields(err), stack)))
+}
+
+// fields of the error chain: "error", "error.type" and "error.chain"
+// errors are unwrapped by Unwrap() error, the first error of Unwrap() []error
+func ErrorFields(err error) map[string]interface{} {
+	chain := []string{}
+	innermost := err
+	for e := err; e != nil; e = unwrapError(e) {
+		chain = append(chain, e.Error())
+		innermost = e
+		if len(chain) == STACKTRACE_MAX_FRAMES {
+			break
+		}
+	}
+	return map[string]interface{}{
+		LOGGER_FIELD_ERROR:       err.Error(),
+		LOGGER_FIELD_ERROR_TYPE:  fmt.Sprintf("%T", innermost),
+		LOGGER_FIELD_ERROR_CHAIN: chain,
+	}
+}
+
+func unwrapError(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		if len(errs) == 0 {
+			return nil
+		}
+		return errs[0]
+	}
+	return errors.Unwrap(err)
+}
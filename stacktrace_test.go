@@ -0,0 +1,170 @@
+package go_logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SetStacktraceLevel(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer: buffer,
+		Format: "%body%|%stacktrace%",
+	})
+
+	logger.Error("no stack")
+	logger.SetStacktraceLevel(LOGGER_LEVEL_ERROR)
+	logger.Info("info")
+	logger.Error("error")
+	testLogHelper(logger, "helper")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "|")
+	if len(lines) < 4 {
+		t.Fatalf("logger stacktrace lines error: %q", buffer.String())
+	}
+	if !strings.HasSuffix(lines[0], "no stack") || !strings.HasPrefix(lines[1], "\ninfo") {
+		t.Errorf("logger stacktrace of levels below error: %q", buffer.String())
+	}
+	if !strings.HasPrefix(lines[3], "github.com/phachon/go-logger.TestLogger_SetStacktraceLevel\n\t") ||
+		!strings.Contains(lines[3], "stacktrace_test.go:") {
+		t.Errorf("logger stacktrace error: %q", lines[3])
+	}
+	if strings.Contains(lines[3], "runtime.goexit") || strings.Contains(lines[3], "go-logger.(*Logger)") {
+		t.Errorf("logger stacktrace frames error: %q", lines[3])
+	}
+
+	logger.SetStacktraceLevel(STACKTRACE_LEVEL_NONE)
+	buffer.Reset()
+	logger.Emergency("none")
+	if buffer.String() != "none|\n" {
+		t.Errorf("logger stacktrace none error: %q", buffer.String())
+	}
+}
+
+func TestLogger_StacktraceJson(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer:     buffer,
+		JsonFormat: true,
+	})
+	logger.SetStacktraceLevel(LOGGER_LEVEL_WARNING)
+	logger.Warning("warning")
+
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(buffer.Bytes(), &record); err != nil {
+		t.Fatalf("logger json error: %s, %q", err, buffer.String())
+	}
+	fields, _ := record["fields"].(map[string]interface{})
+	stack, _ := fields[LOGGER_FIELD_STACKTRACE].(string)
+	if !strings.HasPrefix(stack, "github.com/phachon/go-logger.TestLogger_StacktraceJson\n\t") {
+		t.Errorf("logger json stacktrace error: %q", buffer.String())
+	}
+}
+
+type testStackError struct{}
+
+func (err *testStackError) Error() string {
+	return "not found"
+}
+
+func TestLogger_ErrorWithStack(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer:     buffer,
+		JsonFormat: true,
+	})
+
+	logger.ErrorWithStack(nil)
+	logger.ErrorWithStack(fmt.Errorf("load config: %w", fmt.Errorf("open: %w", &testStackError{})))
+	logger.With(map[string]interface{}{"k": "v"}).ErrorWithStack(errors.New("child"))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logger error with stack lines error: %q", buffer.String())
+	}
+	record := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("logger json error: %s, %q", err, lines[0])
+	}
+	fields, _ := record["fields"].(map[string]interface{})
+	if record["body"] != "load config: open: not found" || fields[LOGGER_FIELD_ERROR_TYPE] != "*go_logger.testStackError" {
+		t.Errorf("logger error with stack fields error: %q", lines[0])
+	}
+	chain := fmt.Sprint(fields[LOGGER_FIELD_ERROR_CHAIN])
+	if chain != "[load config: open: not found open: not found not found]" {
+		t.Errorf("logger error with stack chain error: %s", chain)
+	}
+	if stack, _ := fields[LOGGER_FIELD_STACKTRACE].(string); !strings.HasPrefix(stack, "github.com/phachon/go-logger.TestLogger_ErrorWithStack\n\t") {
+		t.Errorf("logger error with stack stacktrace error: %q", stack)
+	}
+
+	record = map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("logger json error: %s, %q", err, lines[1])
+	}
+	fields, _ = record["fields"].(map[string]interface{})
+	if fields["k"] != "v" || fields[LOGGER_FIELD_ERROR_TYPE] != "*errors.errorString" {
+		t.Errorf("logger child error with stack fields error: %q", lines[1])
+	}
+	if stack, _ := fields[LOGGER_FIELD_STACKTRACE].(string); !strings.HasPrefix(stack, "github.com/phachon/go-logger.TestLogger_ErrorWithStack\n\t") {
+		t.Errorf("logger child error with stack stacktrace error: %q", stack)
+	}
+}
+
+func TestLogger_Errore(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{
+		Writer:     buffer,
+		JsonFormat: true,
+	})
+
+	logger.Errore(nil)
+	logger.Errore(fmt.Errorf("connect: %w", errors.New("refused")))
+	logger.With(map[string]interface{}{"k": "v"}).Errore(errors.New("child"))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logger errore lines error: %q", buffer.String())
+	}
+	expects := []string{
+		`"body":"connect: refused","file":"stacktrace_test.go"`,
+		`"fields":{"error":"connect: refused","error.chain":["connect: refused","refused"],"error.type":"*errors.errorString"}`,
+		`"body":"child"`,
+		`"fields":{"error":"child","error.chain":["child"],"error.type":"*errors.errorString","k":"v"}`,
+	}
+	for i, expect := range expects {
+		if !strings.Contains(lines[i/2], expect) {
+			t.Errorf("logger errore error: %q, expect %q", lines[i/2], expect)
+		}
+	}
+}
+
+func TestErrorFields(t *testing.T) {
+
+	err := fmt.Errorf("read: %w", os.ErrNotExist)
+	fields := ErrorFields(err)
+	if fields[LOGGER_FIELD_ERROR] != "read: file does not exist" {
+		t.Errorf("error fields error: %v", fields)
+	}
+	chain := fields[LOGGER_FIELD_ERROR_CHAIN].([]string)
+	if len(chain) != 2 || chain[1] != "file does not exist" {
+		t.Errorf("error fields chain error: %v", chain)
+	}
+}
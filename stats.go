@@ -0,0 +1,201 @@
+package go_logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// logger stats
+type LoggerStats struct {
+
+	// messages dispatched to adapters by level string, after sampling and hooks
+	Levels map[string]int64
+
+	// messages dropped by the logger sampler
+	Sampled int64
+
+	// stats of attached adapters by name
+	Adapters map[string]AdapterStats
+
+	// json types of fields, empty if SetFieldTypeTracking() is disabled
+	FieldTypes FieldTypeReport
+
+	// messages suppressed by budgets by category
+	Budgets map[string]BudgetStats
+}
+
+// adapter stats
+type AdapterStats struct {
+
+	// write latency, writes, errors and timeouts
+	Latency LatencyStats
+
+	// messages dropped by the full async queue and messages in the queue
+	Dropped    int64
+	QueueDepth int
+
+	// messages older than the ttl of SetAdapterTTL, not written
+	Expired int64
+
+	// counters of adapters implement LoggerCounter, eg: "rotations" of file adapter
+	Counters map[string]int64
+
+	// tags of SetAdapterTags(), nil if there are no tags
+	Tags map[string]string
+}
+
+// counters of the logger
+type loggerStats struct {
+	levels [LOGGER_LEVEL_DEBUG + 1]int64
+	custom customLevelCounters // custom levels of RegisterLevel
+}
+
+// count the dispatched message
+func (stats *loggerStats) count(loggerMsg *loggerMessage) {
+	if loggerMsg.Level >= 0 && loggerMsg.Level < len(stats.levels) {
+		atomic.AddInt64(&stats.levels[loggerMsg.Level], 1)
+		return
+	}
+	stats.custom.add(loggerMsg.Level)
+}
+
+// stats of logger and attached adapters
+func (logger *Logger) Stats() LoggerStats {
+	stats := LoggerStats{
+		Levels:     map[string]int64{},
+		Adapters:   map[string]AdapterStats{},
+		FieldTypes: logger.FieldTypeReport(),
+		Budgets:    logger.BudgetStats(),
+	}
+	for level := range logger.stats.levels {
+		stats.Levels[levelNames()[level]] = atomic.LoadInt64(&logger.stats.levels[level])
+	}
+	logger.stats.custom.each(func(level int, count int64) {
+		stats.Levels[levelNames()[level]] = count
+	})
+	if sampler, ok := logger.sampler.Load().(**Sampler); ok && *sampler != nil {
+		stats.Sampled = (*sampler).Dropped()
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		adapterStats := AdapterStats{
+			Latency:  output.latency.stats(),
+			Counters: map[string]int64{},
+			Tags:     output.tagsOf(),
+			Expired:  atomic.LoadInt64(&output.expiredCount),
+		}
+		if output.queue != nil {
+			adapterStats.Dropped = output.queue.droppedCount()
+			adapterStats.QueueDepth = len(output.queue.msgChan)
+		}
+		if counter, ok := output.LoggerAbstract.(LoggerCounter); ok {
+			adapterStats.Counters = counter.Counters()
+		}
+		stats.Adapters[output.Name] = adapterStats
+	}
+	return stats
+}
+
+// http handler of stats in prometheus text format
+//
+// example:
+//	http.Handle("/metrics/logger", logger.MetricsHandler())
+func (logger *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(logger.Stats().prometheusText())
+	})
+}
+
+// stats in prometheus text format, metrics are prefixed with "go_logger_"
+func (stats LoggerStats) prometheusText() []byte {
+	buf := &bytes.Buffer{}
+
+	metric := func(name string, kind string, help string) {
+		fmt.Fprintf(buf, "# HELP go_logger_%s %s\n# TYPE go_logger_%s %s\n", name, help, name, kind)
+	}
+	sample := func(name string, labels string, value interface{}) {
+		fmt.Fprintf(buf, "go_logger_%s{%s} %v\n", name, labels, value)
+	}
+
+	metric("messages_total", "counter", "Messages dispatched to adapters by level.")
+	for _, level := range registeredLevels() {
+		name := levelNames()[level]
+		sample("messages_total", `level="`+strings.ToLower(name)+`"`, stats.Levels[name])
+	}
+	metric("sampled_total", "counter", "Messages dropped by the logger sampler.")
+	fmt.Fprintf(buf, "go_logger_sampled_total %d\n", stats.Sampled)
+	metric("field_type_conflicts", "gauge", "Fields logged with more than one json type.")
+	fmt.Fprintf(buf, "go_logger_field_type_conflicts %d\n", len(stats.FieldTypes.Conflicts))
+	if len(stats.Budgets) > 0 {
+		categories := make([]string, 0, len(stats.Budgets))
+		for category := range stats.Budgets {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		metric("budget_suppressed_total", "counter", "Messages suppressed by the budget of the category.")
+		for _, category := range categories {
+			sample("budget_suppressed_total", fmt.Sprintf("category=%q", category), stats.Budgets[category].Suppressed)
+		}
+	}
+
+	names := make([]string, 0, len(stats.Adapters))
+	for name := range stats.Adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	labels := make(map[string]string, len(names))
+	for _, name := range names {
+		labels[name] = stats.Adapters[name].labels(name)
+	}
+	adapterMetrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(stats AdapterStats) interface{}
+	}{
+		{"adapter_writes_total", "counter", "Messages written to the adapter.", func(s AdapterStats) interface{} { return s.Latency.Writes }},
+		{"adapter_errors_total", "counter", "Failed writes of the adapter.", func(s AdapterStats) interface{} { return s.Latency.Errors }},
+		{"adapter_timeouts_total", "counter", "Timed out writes of the adapter.", func(s AdapterStats) interface{} { return s.Latency.Timeouts }},
+		{"adapter_dropped_total", "counter", "Messages dropped by the full async queue.", func(s AdapterStats) interface{} { return s.Dropped }},
+		{"adapter_expired_total", "counter", "Messages dropped by the ttl of the adapter.", func(s AdapterStats) interface{} { return s.Expired }},
+		{"adapter_queue_depth", "gauge", "Messages in the async queue.", func(s AdapterStats) interface{} { return s.QueueDepth }},
+		{"adapter_write_p99_seconds", "gauge", "P99 write latency of the latest writes.", func(s AdapterStats) interface{} { return s.Latency.P99.Seconds() }},
+	}
+	for _, adapterMetric := range adapterMetrics {
+		metric(adapterMetric.name, adapterMetric.kind, adapterMetric.help)
+		for _, name := range names {
+			sample(adapterMetric.name, labels[name], adapterMetric.value(stats.Adapters[name]))
+		}
+	}
+
+	// adapter counters, eg: go_logger_adapter_rotations_total{adapter="file"}
+	counterSet := map[string]bool{}
+	for _, name := range names {
+		for counter := range stats.Adapters[name].Counters {
+			counterSet[counter] = true
+		}
+	}
+	counterNames := make([]string, 0, len(counterSet))
+	for counter := range counterSet {
+		counterNames = append(counterNames, counter)
+	}
+	sort.Strings(counterNames)
+	for _, counter := range counterNames {
+		metricName := "adapter_" + counter + "_total"
+		metric(metricName, "counter", "Adapter counter "+counter+".")
+		for _, name := range names {
+			if value, ok := stats.Adapters[name].Counters[counter]; ok {
+				sample(metricName, labels[name], value)
+			}
+		}
+	}
+	return buf.Bytes()
+}
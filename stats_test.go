@@ -0,0 +1,73 @@
+package go_logger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Stats(t *testing.T) {
+
+	logger, readLog := newTestFileLogger(t, &FileConfig{Format: "%body%", MaxLine: 2})
+	defer readLog()
+	logger.Info("1")
+	logger.Info("2")
+	logger.Error("3")
+
+	stats := logger.Stats()
+	if stats.Levels["Info"] != 2 || stats.Levels["Error"] != 1 {
+		t.Errorf("level stats error: %v", stats.Levels)
+	}
+	fileStats := stats.Adapters["file"]
+	if fileStats.Latency.Writes != 3 || fileStats.Latency.Errors != 0 || fileStats.Counters["rotations"] == 0 {
+		t.Errorf("adapter stats error: %+v", fileStats)
+	}
+
+	w := httptest.NewRecorder()
+	logger.MetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, expected := range []string{
+		`go_logger_messages_total{level="info"} 2`,
+		`go_logger_adapter_writes_total{adapter="file"} 3`,
+		`go_logger_adapter_rotations_total{adapter="file"} `,
+		"# TYPE go_logger_adapter_queue_depth gauge",
+	} {
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("metrics must contain %s", expected)
+		}
+	}
+}
+
+func TestLogger_SetAdapterTags(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.AttachAs("errors", "memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	if err := logger.SetAdapterTags("errors", map[string]string{"team-name": "core"}); err == nil {
+		t.Error("adapter tag name must be a label name")
+	}
+	if err := logger.SetAdapterTags("file", map[string]string{"team": "core"}); err == nil {
+		t.Error("adapter tags of detached adapter must be error")
+	}
+	tags := map[string]string{"team": "payments", "environment": "prod", ADAPTER_TAG_NAME: "memory_errors"}
+	if err := logger.SetAdapterTags("errors", tags); err != nil {
+		t.Fatal(err)
+	}
+	tags["team"] = "changed"
+	logger.Info("1")
+
+	stats := logger.Stats()
+	if stats.Adapters["errors"].Tags["team"] != "payments" {
+		t.Errorf("adapter stats tags error: %v", stats.Adapters["errors"].Tags)
+	}
+	w := httptest.NewRecorder()
+	logger.MetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	expected := `go_logger_adapter_writes_total{adapter="memory_errors",environment="prod",team="payments"} 1`
+	if !strings.Contains(w.Body.String(), expected) {
+		t.Errorf("metrics must contain %s: %s", expected, w.Body.String())
+	}
+
+	logger.SetAdapterTags("errors", nil)
+	if tags := logger.Stats().Adapters["errors"].Tags; tags != nil {
+		t.Errorf("adapter tags must be removed: %v", tags)
+	}
+}
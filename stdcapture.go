@@ -0,0 +1,217 @@
+package go_logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// field of captured lines, "stdout", "stderr" or "log"
+const LOGGER_FIELD_SOURCE = "source"
+
+// sources of captured lines
+const (
+	STD_CAPTURE_SOURCE_STDOUT = "stdout"
+	STD_CAPTURE_SOURCE_STDERR = "stderr"
+	STD_CAPTURE_SOURCE_LOG    = "log"
+)
+
+// max bytes of a captured line, longer lines are split
+const STD_CAPTURE_MAX_LINE = 64 * 1024
+
+// capture config of os.Stdout, os.Stderr and the standard log package
+type StdCaptureConfig struct {
+
+	// capture os.Stdout, lines are info messages
+	Stdout bool
+
+	// capture os.Stderr, lines are error messages
+	// lines of the logger itself ("logger: ...") are written to the original stderr
+	Stderr bool
+
+	// capture the standard log package (log.SetOutput), lines are info messages
+	// flags of log are cleared while captured, messages have their own time
+	StdLog bool
+}
+
+// captured line writer, lines are written to the logger
+type stdCaptureWriter struct {
+	logger *Logger
+	level  int
+	source string   // field LOGGER_FIELD_SOURCE of lines, no field if it's empty
+	stderr *os.File // original stderr of lines of the logger itself, nil if it's not stderr
+	lock   sync.Mutex
+	buffer []byte
+}
+
+// capture of os.Stdout, os.Stderr and log
+type stdCapture struct {
+	stdout   *os.File
+	stderr   *os.File
+	pipes    []*os.File // write ends of the pipes
+	readers  sync.WaitGroup
+	logOut   io.Writer
+	logFlags int
+	stdLog   bool
+	restored int32
+}
+
+// capture os.Stdout, os.Stderr and the standard log package so stray prints of dependencies are written to adapters
+// lines have field "source" ("stdout", "stderr" or "log"), the returned func restores them, it's safe to call it twice
+// attach the console adapter before capture, it keeps writing to the original stdout
+// writes to the file descriptors by cgo or syscall are not captured
+//
+// example:
+//	restore, err := logger.CaptureStd(&go_logger.StdCaptureConfig{Stdout: true, Stderr: true, StdLog: true})
+//	if err != nil {
+//		return err
+//	}
+//	defer restore()
+func (logger *Logger) CaptureStd(config *StdCaptureConfig) (func(), error) {
+	if config == nil || !config.Stdout && !config.Stderr && !config.StdLog {
+		return nil, configError("", "Stdout", "must be true if Stderr and StdLog are false!")
+	}
+	capture := &stdCapture{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	if config.Stdout {
+		err := capture.pipe(&os.Stdout, &stdCaptureWriter{
+			logger: logger,
+			level:  LOGGER_LEVEL_INFO,
+			source: STD_CAPTURE_SOURCE_STDOUT,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.Stderr {
+		err := capture.pipe(&os.Stderr, &stdCaptureWriter{
+			logger: logger,
+			level:  LOGGER_LEVEL_ERROR,
+			source: STD_CAPTURE_SOURCE_STDERR,
+			stderr: capture.stderr,
+		})
+		if err != nil {
+			capture.restore()
+			return nil, err
+		}
+	}
+	if config.StdLog {
+		capture.stdLog = true
+		capture.logOut = log.Writer()
+		capture.logFlags = log.Flags()
+		log.SetFlags(0)
+		log.SetOutput(&stdCaptureWriter{
+			logger: logger,
+			level:  LOGGER_LEVEL_INFO,
+			source: STD_CAPTURE_SOURCE_LOG,
+		})
+	}
+	return capture.restore, nil
+}
+
+// replace the file by the write end of a pipe, lines of the read end are written to the writer
+func (capture *stdCapture) pipe(file **os.File, writer *stdCaptureWriter) error {
+	reader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	*file = pipeWriter
+	capture.pipes = append(capture.pipes, pipeWriter)
+
+	capture.readers.Add(1)
+	go func() {
+		defer capture.readers.Done()
+		defer reader.Close()
+		io.Copy(writer, reader)
+		writer.flush()
+	}()
+	return nil
+}
+
+// restore os.Stdout, os.Stderr and log, captured lines are written before it returns
+func (capture *stdCapture) restore() {
+	if !atomic.CompareAndSwapInt32(&capture.restored, 0, 1) {
+		return
+	}
+	os.Stdout = capture.stdout
+	os.Stderr = capture.stderr
+	for _, pipeWriter := range capture.pipes {
+		pipeWriter.Close()
+	}
+	capture.readers.Wait()
+	if capture.stdLog {
+		log.SetOutput(capture.logOut)
+		log.SetFlags(capture.logFlags)
+	}
+}
+
+// write complete lines, the rest is buffered until the next newline or STD_CAPTURE_MAX_LINE
+func (writer *stdCaptureWriter) Write(p []byte) (int, error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.buffer = append(writer.buffer, p...)
+	for {
+		i := bytes.IndexByte(writer.buffer, '\n')
+		if i < 0 {
+			if len(writer.buffer) < STD_CAPTURE_MAX_LINE {
+				break
+			}
+			i = STD_CAPTURE_MAX_LINE
+		}
+		line := string(writer.buffer[:i])
+		if i < len(writer.buffer) && writer.buffer[i] == '\n' {
+			i++
+		}
+		writer.buffer = writer.buffer[i:]
+		writer.writeLine(line)
+	}
+	if len(writer.buffer) == 0 {
+		writer.buffer = nil
+	}
+	return len(p), nil
+}
+
+// write the incomplete last line
+func (writer *stdCaptureWriter) flush() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if len(writer.buffer) > 0 {
+		writer.writeLine(string(writer.buffer))
+		writer.buffer = nil
+	}
+}
+
+// write the line as a message, call it after lock
+func (writer *stdCaptureWriter) writeLine(line string) {
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return
+	}
+	// errors of the logger are not written to itself, it may fail again
+	if writer.stderr != nil && strings.HasPrefix(line, "logger: ") {
+		writer.stderr.WriteString(line + "\n")
+		return
+	}
+	logger := writer.logger
+	if atomic.LoadInt32(&logger.closed) == 1 || !logger.enabled(writer.level) {
+		return
+	}
+	var fields map[string]interface{}
+	if writer.source != "" {
+		fields = map[string]interface{}{LOGGER_FIELD_SOURCE: writer.source}
+	}
+	// the caller is unknown, lines are written by the reader of a pipe or by log
+	loggerMsg := newLoggerMessage(logger.now(), writer.level, line, fields)
+	loggerMsg.File = unknownCallerFrame.file
+	loggerMsg.Function = unknownCallerFrame.function
+	logger.dispatch(loggerMsg, nil)
+}
@@ -0,0 +1,76 @@
+package go_logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger_CaptureStd(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	memory := NewAdapterMemory().(*AdapterMemory)
+	memory.Init(&MemoryConfig{})
+	logger.AttachAdapter("memory", LOGGER_LEVEL_DEBUG, memory)
+
+	if _, err := logger.CaptureStd(&StdCaptureConfig{}); err == nil {
+		t.Errorf("capture std without sources must be error")
+	}
+
+	stdout, stderr, logFlags := os.Stdout, os.Stderr, log.Flags()
+	restore, err := logger.CaptureStd(&StdCaptureConfig{Stdout: true, Stderr: true, StdLog: true})
+	if err != nil {
+		t.Fatalf("capture std error: %s", err)
+	}
+	fmt.Println("hello stdout")
+	fmt.Fprint(os.Stdout, "partial ")
+	fmt.Fprint(os.Stdout, "line\r\n\nlast")
+	fmt.Fprintln(os.Stderr, "hello stderr")
+	log.Printf("hello %s", "log")
+	restore()
+	restore()
+
+	if os.Stdout != stdout || os.Stderr != stderr || log.Flags() != logFlags {
+		t.Fatalf("capture std is not restored")
+	}
+
+	got := map[string]string{}
+	for _, loggerMsg := range memory.messages() {
+		got[loggerMsg.Body] = loggerMsg.LevelString + " " + loggerMsg.Fields[LOGGER_FIELD_SOURCE].(string)
+	}
+	expects := map[string]string{
+		"hello stdout": "Info stdout",
+		"partial line": "Info stdout",
+		"last":         "Info stdout",
+		"hello stderr": "Error stderr",
+		"hello log":    "Info log",
+	}
+	if len(got) != len(expects) {
+		t.Errorf("capture std messages error: %v", got)
+	}
+	for body, expect := range expects {
+		if got[body] != expect {
+			t.Errorf("capture std message %q: %q, expect %q", body, got[body], expect)
+		}
+	}
+}
+
+func TestStdCaptureWriter_MaxLine(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	memory := NewAdapterMemory().(*AdapterMemory)
+	memory.Init(&MemoryConfig{})
+	logger.AttachAdapter("memory", LOGGER_LEVEL_DEBUG, memory)
+
+	writer := &stdCaptureWriter{logger: logger, level: LOGGER_LEVEL_INFO, source: STD_CAPTURE_SOURCE_STDOUT}
+	writer.Write([]byte(strings.Repeat("x", STD_CAPTURE_MAX_LINE+10)))
+	writer.flush()
+	messages := memory.messages()
+	if len(messages) != 2 || len(messages[0].Body) != STD_CAPTURE_MAX_LINE || len(messages[1].Body) != 10 {
+		t.Errorf("capture std long line must be split: %d", len(messages))
+	}
+}
@@ -0,0 +1,131 @@
+package go_logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// strict modes of internal failures, SetStrict()
+const (
+	// failures are degraded silently: printed to stderr, counted by stats or written by the json fallback
+	STRICT_MODE_OFF = 0
+
+	// the first failure is also returned by the next Writer(), WriterFields() or StrictErr()
+	STRICT_MODE_ERROR = 1
+
+	// failures panic in the goroutine of the failure (the async queue goroutine for async write errors)
+	STRICT_MODE_PANIC = 2
+)
+
+var (
+	ErrQueueFull       = errors.New("logger: async queue is full")
+	ErrQueueStopped    = errors.New("logger: async queue is stopped")
+	ErrEarlyBufferFull = errors.New("logger: early buffer is full")
+)
+
+// kinds of strict failures
+const (
+	STRICT_FAILURE_WRITE    = "write"
+	STRICT_FAILURE_DROP     = "drop"
+	STRICT_FAILURE_ENCODING = "encoding"
+)
+
+// internal failure of strict mode
+type StrictError struct {
+
+	// STRICT_FAILURE_WRITE, STRICT_FAILURE_DROP or STRICT_FAILURE_ENCODING
+	Kind string
+
+	// attached name of the adapter, empty if the failure is not of an adapter
+	Adapter string
+
+	Err error
+}
+
+func (err *StrictError) Error() string {
+	if err.Adapter == "" {
+		return "logger: strict " + err.Kind + " failure, error: " + err.Err.Error()
+	}
+	return "logger: strict " + err.Kind + " failure of adapter " + err.Adapter + ", error: " + err.Err.Error()
+}
+
+func (err *StrictError) Unwrap() error {
+	return err.Err
+}
+
+// strict mode and the first failure not returned yet
+type loggerStrict struct {
+	mode    int32
+	lock    sync.Mutex
+	failure *StrictError
+}
+
+// set strict mode for development and CI, STRICT_MODE_OFF (default), STRICT_MODE_ERROR or STRICT_MODE_PANIC
+// failures are adapter write errors, messages dropped by full async queues or the early buffer
+// and messages which cannot be marshaled to json (checked before they're written)
+//
+// example:
+//	if os.Getenv("CI") != "" {
+//		logger.SetStrict(go_logger.STRICT_MODE_PANIC)
+//	}
+func (logger *Logger) SetStrict(mode int) {
+	logger.strict.lock.Lock()
+	defer logger.strict.lock.Unlock()
+
+	atomic.StoreInt32(&logger.strict.mode, int32(mode))
+	logger.strict.failure = nil
+}
+
+func (strict *loggerStrict) enabled() bool {
+	return atomic.LoadInt32(&strict.mode) != STRICT_MODE_OFF
+}
+
+// report the failure by the mode
+func (strict *loggerStrict) fail(kind string, adapter string, err error) {
+	if strict == nil {
+		return
+	}
+	switch atomic.LoadInt32(&strict.mode) {
+	case STRICT_MODE_ERROR:
+		strict.lock.Lock()
+		if strict.failure == nil {
+			strict.failure = &StrictError{Kind: kind, Adapter: adapter, Err: err}
+		}
+		strict.lock.Unlock()
+	case STRICT_MODE_PANIC:
+		panic(&StrictError{Kind: kind, Adapter: adapter, Err: err})
+	}
+}
+
+// the first failure since the last call, nil error if there's none
+func (strict *loggerStrict) err() error {
+	if atomic.LoadInt32(&strict.mode) != STRICT_MODE_ERROR {
+		return nil
+	}
+	strict.lock.Lock()
+	defer strict.lock.Unlock()
+
+	failure := strict.failure
+	strict.failure = nil
+	if failure == nil {
+		// nil *StrictError is not a nil error
+		return nil
+	}
+	return failure
+}
+
+// the first failure of STRICT_MODE_ERROR since it's returned, eg: write errors of async queues after Flush()
+func (logger *Logger) StrictErr() error {
+	return logger.strict.err()
+}
+
+// message can be marshaled to json in strict mode
+func (logger *Logger) strictEncoding(loggerMsg *loggerMessage) {
+	if !logger.strict.enabled() {
+		return
+	}
+	if _, err := loggerMsg.MarshalJSON(); err != nil {
+		logger.strict.fail(STRICT_FAILURE_ENCODING, "", err)
+	}
+}
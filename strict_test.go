@@ -0,0 +1,91 @@
+package go_logger
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestLogger_SetStrict(t *testing.T) {
+
+	diskFull := errors.New("disk full")
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: &failingWriter{err: diskFull}})
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {})
+
+	if err := logger.Writer(LOGGER_LEVEL_INFO, "off"); err != nil {
+		t.Errorf("strict off must not return write errors: %v", err)
+	}
+
+	logger.SetStrict(STRICT_MODE_ERROR)
+	err := logger.Writer(LOGGER_LEVEL_INFO, "error")
+	strictErr, ok := err.(*StrictError)
+	if !ok || strictErr.Kind != STRICT_FAILURE_WRITE || strictErr.Adapter != "writer" || !errors.Is(err, diskFull) {
+		t.Fatalf("strict write error: %v", err)
+	}
+	if logger.StrictErr() != nil {
+		t.Errorf("strict error must be returned once")
+	}
+
+	logger.SetStrict(STRICT_MODE_PANIC)
+	func() {
+		defer func() {
+			e := recover()
+			if strictErr, ok := e.(*StrictError); !ok || strictErr.Kind != STRICT_FAILURE_WRITE {
+				t.Errorf("strict panic error: %v", e)
+			}
+		}()
+		logger.Info("panic")
+	}()
+}
+
+func TestLogger_SetStrictEncoding(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Format: "%body%"})
+	logger.SetStrict(STRICT_MODE_ERROR)
+
+	err := logger.WriterFields(LOGGER_LEVEL_INFO, "nan", map[string]interface{}{"ratio": math.NaN()})
+	strictErr, ok := err.(*StrictError)
+	if !ok || strictErr.Kind != STRICT_FAILURE_ENCODING || strictErr.Adapter != "" {
+		t.Fatalf("strict encoding error: %v", err)
+	}
+	if buffer.String() != "nan\n" {
+		t.Errorf("strict error mode must still write the message: %q", buffer.String())
+	}
+}
+
+func TestLogger_SetStrictDrop(t *testing.T) {
+
+	blockingConfig := &blockingConfig{release: make(chan struct{})}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+	logger.SetAsyncPolicy(ASYNC_POLICY_DROP_NEWEST)
+	logger.SetAsync(1)
+	logger.SetStrict(STRICT_MODE_ERROR)
+
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = logger.Writer(LOGGER_LEVEL_INFO, "queued")
+	}
+	close(blockingConfig.release)
+	logger.Flush()
+	strictErr, ok := err.(*StrictError)
+	if !ok || strictErr.Kind != STRICT_FAILURE_DROP || !errors.Is(err, ErrQueueFull) {
+		t.Errorf("strict drop error: %v", err)
+	}
+
+	early := NewLogger()
+	early.Detach("console")
+	early.SetEarlyBuffer(1)
+	early.SetStrict(STRICT_MODE_ERROR)
+	early.Info("first")
+	if err := early.Writer(LOGGER_LEVEL_INFO, "second"); !errors.Is(err, ErrEarlyBufferFull) {
+		t.Errorf("strict early buffer drop error: %v", err)
+	}
+}
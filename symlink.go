@@ -0,0 +1,123 @@
+package go_logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// suffix of the link created and renamed to Filename
+const FILE_SYMLINK_TMP_SUFFIX = ".link"
+
+// point the Filename symlink to the file of the date slice of t, the file is created by initFile
+// resume is true if the file of the date slice linked before restart is written again
+func (fw *FileWriter) linkLatest(config *FileConfig, t time.Time, resume bool) error {
+	naming, err := fw.backupNaming(config, fileSliceTimeFormats[config.DateSlice])
+	if err != nil {
+		return err
+	}
+	if config.DateSlice == FILE_SLICE_DATE_WEEK {
+		t = weekStart(t, config.WeekStart)
+	}
+
+	target := ""
+	if resume {
+		target = fw.linkedLatest(naming, t)
+	}
+	if target == "" {
+		err = fw.createDir(naming.dir)
+		if err != nil {
+			return err
+		}
+		target = naming.path(t)
+	}
+
+	err = fw.createDir(filepath.Dir(fw.filename))
+	if err != nil {
+		return err
+	}
+	fileInfo, err := fw.fs.Lstat(fw.filename)
+	if err == nil && fileInfo.Mode()&os.ModeSymlink == 0 {
+		// the file written before SymlinkLatest is the file of the date slice
+		if _, err := fw.fs.Stat(target); err == nil {
+			return errors.New("logger: file " + fw.filename + " cannot be moved to " + target + ", it exists")
+		}
+		err = fw.fs.Rename(fw.filename, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	// replace the link by rename, Filename always exists for tail -F
+	link, err := filepath.Rel(filepath.Dir(fw.filename), target)
+	if err != nil {
+		link = target
+	}
+	tmpLink := fw.filename + FILE_SYMLINK_TMP_SUFFIX
+	fw.fs.Remove(tmpLink)
+	err = fw.fs.Symlink(link, tmpLink)
+	if err != nil {
+		return err
+	}
+	err = fw.fs.Rename(tmpLink, fw.filename)
+	if err != nil {
+		fw.fs.Remove(tmpLink)
+		return err
+	}
+	fw.latest = target
+	return nil
+}
+
+// file of the date slice of t linked by Filename, "" if the link is missing or of another date slice
+func (fw *FileWriter) linkedLatest(naming *backupNaming, t time.Time) string {
+	link, err := fw.fs.Readlink(fw.filename)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(filepath.Dir(fw.filename), link)
+	}
+	if filepath.Clean(filepath.Dir(link)) != filepath.Clean(naming.dir) {
+		return ""
+	}
+	r, err := naming.pattern()
+	if err != nil {
+		return ""
+	}
+	linkTime, _, ok := naming.parse(r, filepath.Base(link))
+	if !ok || linkTime.Format(naming.timeFormat) != t.Format(naming.timeFormat) {
+		return ""
+	}
+	return link
+}
+
+// slice by pointing Filename to a new file, the linked file is the bak file
+func (fw *FileWriter) rotateLatest(config *FileConfig) error {
+
+	// check bak num, age and total size
+	if config.MaxBak > 0 || config.MaxAge > 0 || config.MaxTotalSize > 0 {
+		err := fw.cleanUpBackupFiles(config, fileSliceTimeFormats[config.DateSlice])
+		if err != nil {
+			return err
+		}
+	}
+
+	//close file handle
+	fw.closeFile()
+	bakFilename := fw.latest
+	err := fw.linkLatest(config, fw.clock.Now(), false)
+	if err != nil {
+		return err
+	}
+	fw.checkpointRotated(bakFilename)
+	fw.uploadBackup(config, bakFilename)
+	err = fw.initFile()
+	if err != nil {
+		return err
+	}
+	fw.rotations++
+	fw.rotated(RotateEvent{OldPath: fw.filename, NewPath: bakFilename, Time: fw.clock.Now()})
+
+	return nil
+}
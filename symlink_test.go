@@ -0,0 +1,72 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileConfig_SymlinkLatest(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	today := time.Now().Format("20060102")
+	yesterday := filepath.Join(dir, "test_"+time.Now().AddDate(0, 0, -1).Format("20060102")+".1.log")
+	ioutil.WriteFile(yesterday, []byte("old\n"), 0644)
+	os.Symlink(filepath.Base(yesterday), filename)
+
+	fileAdapter := NewAdapterFile()
+	if fileAdapter.Init(&FileConfig{Filename: filename, SymlinkLatest: true}) == nil {
+		t.Error("SymlinkLatest without DateSlice must error")
+	}
+
+	// the link of yesterday is not resumed
+	config := &FileConfig{Filename: filename, DateSlice: FILE_SLICE_DATE_DAY, SymlinkLatest: true, MaxLine: 3, Format: "%body%"}
+	fileAdapter = NewAdapterFile()
+	err = fileAdapter.Init(config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if link, _ := os.Readlink(filename); link != "test_"+today+".1.log" {
+		t.Fatalf("latest link error: %s", link)
+	}
+
+	// the link is switched by the slice, files are not renamed
+	for _, body := range []string{"one", "two", "three"} {
+		err = fileAdapter.Write(newLoggerMessage(time.Now(), LOGGER_LEVEL_INFO, body, nil))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	fileAdapter.(LoggerCloser).Close()
+	if link, _ := os.Readlink(filename); link != "test_"+today+".2.log" {
+		t.Fatalf("latest link is not switched: %s", link)
+	}
+	if content, _ := ioutil.ReadFile(filepath.Join(dir, "test_"+today+".1.log")); string(content) != "one\ntwo\n" {
+		t.Errorf("bak file error: %q", content)
+	}
+	if content, _ := ioutil.ReadFile(filename); string(content) != "three\n" {
+		t.Errorf("latest file error: %q", content)
+	}
+	if content, _ := ioutil.ReadFile(yesterday); string(content) != "old\n" {
+		t.Errorf("file of yesterday error: %q", content)
+	}
+
+	// the link of today is resumed by restart
+	fileAdapter = NewAdapterFile()
+	err = fileAdapter.Init(config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer fileAdapter.(LoggerCloser).Close()
+	if link, _ := os.Readlink(filename); link != "test_"+today+".2.log" {
+		t.Errorf("latest link is not resumed: %s", link)
+	}
+}
@@ -0,0 +1,90 @@
+package go_logger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tag of adapter metrics overriding the label "adapter", eg: the destination instead of the attached alias
+const ADAPTER_TAG_NAME = "adapter"
+
+var adapterTagRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// set tags of attached adapter, eg: {"team": "payments", "sink": "s3"}
+// tags are labels of MetricsHandler() samples and Tags of Stats().Adapters, nil removes them
+// tag "adapter" overrides the adapter label, names must be prometheus label names
+//
+// example:
+//	logger.SetAdapterTags("file", map[string]string{"team": "payments", "environment": "prod"})
+func (logger *Logger) SetAdapterTags(adapterName string, tags map[string]string) error {
+	err := checkAdapterTags(tags)
+	if err != nil {
+		return errors.New("logger: " + err.Error())
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.setTags(tags)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// tags are prometheus label names, "__" names are reserved
+func checkAdapterTags(tags map[string]string) error {
+	for name := range tags {
+		if !adapterTagRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return errors.New("adapter tag " + name + " is not a label name!")
+		}
+	}
+	return nil
+}
+
+// store a copy of tags
+func (output *outputLogger) setTags(tags map[string]string) {
+	copied := make(map[string]string, len(tags))
+	for name, value := range tags {
+		copied[name] = value
+	}
+	output.tags.Store(copied)
+}
+
+// copy of tags, nil if there are no tags
+func (output *outputLogger) tagsOf() map[string]string {
+	tags, ok := output.tags.Load().(map[string]string)
+	if !ok || len(tags) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(tags))
+	for name, value := range tags {
+		copied[name] = value
+	}
+	return copied
+}
+
+// prometheus labels of the adapter, eg: `adapter="file",team="payments"`
+func (stats AdapterStats) labels(name string) string {
+	if value, ok := stats.Tags[ADAPTER_TAG_NAME]; ok {
+		name = value
+	}
+	labels := fmt.Sprintf("adapter=%q", name)
+
+	tagNames := make([]string, 0, len(stats.Tags))
+	for tagName := range stats.Tags {
+		if tagName != ADAPTER_TAG_NAME {
+			tagNames = append(tagNames, tagName)
+		}
+	}
+	sort.Strings(tagNames)
+	for _, tagName := range tagNames {
+		labels += fmt.Sprintf(",%s=%q", tagName, stats.Tags[tagName])
+	}
+	return labels
+}
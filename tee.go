@@ -0,0 +1,80 @@
+package go_logger
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// set loggers every message of the logger is also written to, by their own levels, samplers, hooks and adapters
+// eg: a json logger of all levels for shipping and a text logger of errors for humans written by one call,
+// Tee() without loggers removes them, adapters of one pipeline are composed by Tee adapters
+//
+// example:
+//	logger.Tee(shipping, console)
+//	logger.Info("started") // written by logger, shipping and console
+func (logger *Logger) Tee(loggers ...*Logger) error {
+	for _, tee := range loggers {
+		if tee == nil {
+			return errors.New("logger: tee logger cannot be nil!")
+		}
+		if tee == logger || tee.reaches(logger, map[*Logger]bool{}) {
+			return errors.New("logger: tee loggers have a cycle!")
+		}
+	}
+	logger.tees.Store(append([]*Logger{}, loggers...))
+	return nil
+}
+
+// target is reached by tees of the logger
+func (logger *Logger) reaches(target *Logger, visited map[*Logger]bool) bool {
+	if visited[logger] {
+		return false
+	}
+	visited[logger] = true
+	for _, next := range logger.teeLoggers() {
+		if next == target || next.reaches(target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func (logger *Logger) teeLoggers() []*Logger {
+	tees, _ := logger.tees.Load().([]*Logger)
+	return tees
+}
+
+// a tee logger writes messages of the level
+func (logger *Logger) teeEnabled(level int) bool {
+	for _, tee := range logger.teeLoggers() {
+		if atomic.LoadInt32(&tee.closed) == 0 && tee.enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// write a copy of the message to tee loggers before the pipeline of the logger changes it
+// return whether the logger writes the message itself, the level is checked again if tees let it pass
+func (logger *Logger) tee(loggerMsg *loggerMessage) bool {
+	tees := logger.teeLoggers()
+	if len(tees) == 0 {
+		return true
+	}
+	for _, tee := range tees {
+		if atomic.LoadInt32(&tee.closed) == 1 || (!loggerMsg.verbose && !tee.enabled(loggerMsg.Level)) {
+			continue
+		}
+		teeMsg := *loggerMsg
+		teeMsg.wal = nil
+		teeMsg.batched = nil
+		if loggerMsg.Fields != nil {
+			teeMsg.Fields = make(map[string]interface{}, len(loggerMsg.Fields))
+			for key, value := range loggerMsg.Fields {
+				teeMsg.Fields[key] = value
+			}
+		}
+		tee.dispatch(&teeMsg, nil)
+	}
+	return loggerMsg.verbose || loggerMsg.boosted || loggerMsg.Level <= int(atomic.LoadInt32(&logger.gate))
+}
@@ -0,0 +1,56 @@
+package go_logger
+
+import (
+	"testing"
+)
+
+func newTestTeeLogger(level int) (*Logger, *AdapterTest) {
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", level, &TestConfig{})
+	return logger, logger.Adapter("test").(*AdapterTest)
+}
+
+func TestLogger_Tee(t *testing.T) {
+
+	logger, recorded := newTestTeeLogger(LOGGER_LEVEL_ERROR)
+	shipping, shipped := newTestTeeLogger(LOGGER_LEVEL_DEBUG)
+	shipping.SetAsync()
+	alerts, alerted := newTestTeeLogger(LOGGER_LEVEL_DEBUG)
+	alerts.SetLevel(LOGGER_LEVEL_ERROR)
+	alerts.AddHook(func(entry *LogEntry) error {
+		entry.Fields["hooked"] = true
+		return nil
+	})
+
+	err := logger.Tee(shipping, alerts)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	logger.Debug("debug")
+	logger.With(map[string]interface{}{"order": 1001}).Error("failed")
+	logger.Flush()
+	shipping.Flush()
+
+	if entries := recorded.Entries(); len(entries) != 1 || entries[0].Body != "failed" {
+		t.Errorf("logger must write by its own level: %v", entries)
+	}
+	if entries := shipped.Entries(); len(entries) != 2 || entries[0].Body != "debug" || entries[1].Fields["hooked"] != nil {
+		t.Errorf("tee logger must write by its own level: %v", entries)
+	}
+	if entries := alerted.Match(MatchField("hooked", true), MatchField("order", 1001)); len(entries) != 1 {
+		t.Errorf("tee logger must run its own hooks: %v", alerted.Entries())
+	}
+	if recorded.Entries()[0].Fields["hooked"] != nil {
+		t.Error("hooks of tee loggers must not change messages of the logger")
+	}
+
+	if alerts.Tee(logger) == nil || logger.Tee(logger) == nil {
+		t.Error("tee cycle must return error")
+	}
+	logger.Tee()
+	logger.Debug("untee")
+	if len(shipped.Match(MatchBody("untee"))) != 0 {
+		t.Error("removed tee logger must not write")
+	}
+}
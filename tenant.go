@@ -0,0 +1,65 @@
+package go_logger
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// field name of the tenant
+const LOGGER_FIELD_TENANT = "tenant"
+
+// default max tenants of a sharded adapter
+const TENANT_DEFAULT_MAX_SHARDS = 100
+
+var tenantNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// child logger of the tenant, messages get field "tenant"
+// adapters with TenantShard write tenants to separate files or indices
+func (logger *Logger) Tenant(tenant string) *ChildLogger {
+	return logger.With(map[string]interface{}{LOGGER_FIELD_TENANT: tenant})
+}
+
+// tenant of the message, safe for file and index names, empty if none
+func messageTenant(loggerMsg *loggerMessage) string {
+	tenant, ok := loggerMsg.Fields[LOGGER_FIELD_TENANT]
+	if !ok {
+		return ""
+	}
+	return tenantNameRegexp.ReplaceAllString(fmt.Sprint(tenant), "_")
+}
+
+// limit tenant cardinality of a sharded adapter
+type tenantShards struct {
+	lock    sync.Mutex
+	max     int
+	tenants map[string]struct{}
+}
+
+func newTenantShards(max int) *tenantShards {
+	if max <= 0 {
+		max = TENANT_DEFAULT_MAX_SHARDS
+	}
+	return &tenantShards{
+		max:     max,
+		tenants: map[string]struct{}{},
+	}
+}
+
+// tenant has a shard, new tenants over max share the default output
+func (shards *tenantShards) allow(tenant string) bool {
+	if tenant == "" {
+		return false
+	}
+	shards.lock.Lock()
+	defer shards.lock.Unlock()
+
+	if _, ok := shards.tenants[tenant]; ok {
+		return true
+	}
+	if len(shards.tenants) >= shards.max {
+		return false
+	}
+	shards.tenants[tenant] = struct{}{}
+	return true
+}
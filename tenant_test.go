@@ -0,0 +1,57 @@
+package go_logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLogger_Tenant(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	err = logger.Attach("file", LOGGER_LEVEL_DEBUG, &FileConfig{
+		Filename:    path.Join(dir, "app.log"),
+		TenantShard: true,
+		MaxTenants:  2,
+		Format:      "%body%",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logger.Tenant("acme").Info("acme info")
+	logger.Tenant("globex").With(map[string]interface{}{"user": "go"}).Infof("globex %s", "info")
+	logger.Tenant("initech").Info("initech info")
+	logger.Tenant("../evil").Info("evil info")
+	logger.Info("default info")
+	logger.Flush()
+
+	readFile := func(filename string) string {
+		content, _ := ioutil.ReadFile(path.Join(dir, filename))
+		return strings.TrimSpace(string(content))
+	}
+	if readFile("app.acme.log") != "acme info" || readFile("app.globex.log") != "globex info" {
+		t.Error("tenant file shard error")
+	}
+	if readFile("app.log") != "initech info\nevil info\ndefault info" {
+		t.Errorf("tenant over max shards must write default file: %q", readFile("app.log"))
+	}
+
+	entries := logger.Adapter("memory").(*AdapterMemory).Entries()
+	if entries[1].Fields[LOGGER_FIELD_TENANT] != "globex" || entries[1].Fields["user"] != "go" {
+		t.Errorf("tenant fields error: %v", entries[1].Fields)
+	}
+	if entries[0].File != "tenant_test.go" {
+		t.Errorf("tenant message file error: %s", entries[0].File)
+	}
+}
@@ -0,0 +1,174 @@
+package go_logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const TEST_ADAPTER_NAME = "test"
+
+// adapter test, record every message for assertions of unit tests, see loggertest.Record()
+//
+// example:
+//	logger.Attach("test", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.TestConfig{})
+//	doWork(logger)
+//	logger.Flush()
+//	recorded := logger.Adapter("test").(*go_logger.AdapterTest)
+//	if len(recorded.Match(go_logger.MatchLevel(go_logger.LOGGER_LEVEL_WARNING), go_logger.MatchField("order", 1001))) == 0 {
+//		t.Error("order warning is not logged")
+//	}
+type AdapterTest struct {
+	lock       sync.RWMutex
+	loggerMsgs []*loggerMessage
+}
+
+// test config
+type TestConfig struct {
+}
+
+func (tc *TestConfig) Name() string {
+	return TEST_ADAPTER_NAME
+}
+
+func NewAdapterTest() LoggerAbstract {
+	return &AdapterTest{}
+}
+
+func (adapterTest *AdapterTest) Init(testConfig Config) error {
+	if _, ok := testConfig.(*TestConfig); !ok {
+		return configTypeError(TEST_ADAPTER_NAME, "TestConfig")
+	}
+	adapterTest.Reset()
+	return nil
+}
+
+func (adapterTest *AdapterTest) Write(loggerMsg *loggerMessage) error {
+	adapterTest.lock.Lock()
+	defer adapterTest.lock.Unlock()
+
+	adapterTest.loggerMsgs = append(adapterTest.loggerMsgs, loggerMsg)
+	return nil
+}
+
+func (adapterTest *AdapterTest) Flush() {
+
+}
+
+func (adapterTest *AdapterTest) Name() string {
+	return TEST_ADAPTER_NAME
+}
+
+// recorded entries, oldest first
+func (adapterTest *AdapterTest) Entries() []LogEntry {
+	adapterTest.lock.RLock()
+	defer adapterTest.lock.RUnlock()
+
+	entries := make([]LogEntry, len(adapterTest.loggerMsgs))
+	for i, loggerMsg := range adapterTest.loggerMsgs {
+		entries[i] = loggerMsg.Entry()
+	}
+	return entries
+}
+
+// last recorded entry, nil if nothing is recorded
+func (adapterTest *AdapterTest) LastEntry() *LogEntry {
+	adapterTest.lock.RLock()
+	defer adapterTest.lock.RUnlock()
+
+	if len(adapterTest.loggerMsgs) == 0 {
+		return nil
+	}
+	entry := adapterTest.loggerMsgs[len(adapterTest.loggerMsgs)-1].Entry()
+	return &entry
+}
+
+// remove all recorded messages
+func (adapterTest *AdapterTest) Reset() {
+	adapterTest.lock.Lock()
+	defer adapterTest.lock.Unlock()
+
+	adapterTest.loggerMsgs = nil
+}
+
+// recorded entries matched by all matchers, oldest first
+func (adapterTest *AdapterTest) Match(matchers ...EntryMatcher) []LogEntry {
+	matched := []LogEntry{}
+	for _, entry := range adapterTest.Entries() {
+		if matchEntry(&entry, matchers) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// matcher of recorded entries, see MatchLevel(), MatchBody() and MatchField()
+type EntryMatcher struct {
+	description string
+	match       func(entry *LogEntry) bool
+}
+
+// new matcher described by description, eg: "level Warning"
+func NewEntryMatcher(description string, match func(entry *LogEntry) bool) EntryMatcher {
+	return EntryMatcher{description: description, match: match}
+}
+
+// description of the matcher for test failures
+func (matcher EntryMatcher) String() string {
+	return matcher.description
+}
+
+// whether the entry is matched
+func (matcher EntryMatcher) Matches(entry *LogEntry) bool {
+	return matcher.match(entry)
+}
+
+// entries of the level
+func MatchLevel(level int) EntryMatcher {
+	return NewEntryMatcher("level "+levelNames()[level], func(entry *LogEntry) bool {
+		return entry.Level == level
+	})
+}
+
+// entries of body containing text
+func MatchBody(text string) EntryMatcher {
+	return NewEntryMatcher(fmt.Sprintf("body containing %q", text), func(entry *LogEntry) bool {
+		return strings.Contains(entry.Body, text)
+	})
+}
+
+// entries with the field of value, values of different types are equal by fmt.Sprint, eg: int 1 and int64 1
+// nil value matches any value of the field
+func MatchField(name string, value interface{}) EntryMatcher {
+	description := "field " + name
+	if value != nil {
+		description += fmt.Sprintf("=%v", value)
+	}
+	return NewEntryMatcher(description, func(entry *LogEntry) bool {
+		fieldValue, ok := entry.Fields[name]
+		if !ok {
+			return false
+		}
+		if value == nil || reflect.DeepEqual(fieldValue, value) {
+			return true
+		}
+		return fmt.Sprint(fieldValue) == fmt.Sprint(value)
+	})
+}
+
+func matchEntry(entry *LogEntry, matchers []EntryMatcher) bool {
+	for _, matcher := range matchers {
+		if !matcher.Matches(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func init() {
+	Register(TEST_ADAPTER_NAME, NewAdapterTest)
+	RegisterConfig(TEST_ADAPTER_NAME, func() Config {
+		return &TestConfig{}
+	})
+}
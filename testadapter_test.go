@@ -0,0 +1,44 @@
+package go_logger
+
+import (
+	"testing"
+)
+
+func TestAdapterTest_Match(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_DEBUG, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+	if recorded.LastEntry() != nil {
+		t.Error("last entry of empty test adapter must be nil")
+	}
+
+	logger.Info("started")
+	logger.With(map[string]interface{}{"order": int64(1001)}).Warning("payment retried")
+	logger.Flush()
+
+	if entries := recorded.Entries(); len(entries) != 2 || entries[0].Body != "started" {
+		t.Fatalf("recorded entries error: %v", entries)
+	}
+	if last := recorded.LastEntry(); last == nil || last.Body != "payment retried" {
+		t.Errorf("last entry error: %v", last)
+	}
+	if len(recorded.Match(MatchLevel(LOGGER_LEVEL_WARNING), MatchBody("retried"), MatchField("order", 1001))) != 1 {
+		t.Error("warning with field order must be matched")
+	}
+	if len(recorded.Match(MatchField("order", nil))) != 1 || len(recorded.Match(MatchField("order", 1002))) != 0 {
+		t.Error("field matcher error")
+	}
+	if len(recorded.Match(MatchLevel(LOGGER_LEVEL_ERROR))) != 0 {
+		t.Error("error level must not be matched")
+	}
+	if MatchField("order", 1001).String() != "field order=1001" {
+		t.Errorf("matcher description error: %s", MatchField("order", 1001))
+	}
+
+	recorded.Reset()
+	if len(recorded.Entries()) != 0 {
+		t.Error("reset must remove recorded entries")
+	}
+}
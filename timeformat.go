@@ -0,0 +1,115 @@
+package go_logger
+
+import (
+	"errors"
+	"time"
+)
+
+// default layouts of %timestamp_format% and %millisecond_format%
+const (
+	TIME_DEFAULT_TIMESTAMP_LAYOUT   = "2006-01-02 15:04:05"
+	TIME_DEFAULT_MILLISECOND_LAYOUT = "2006-01-02 15:04:05.999"
+)
+
+// layouts by name, eg: layout "RFC3339Nano" of config files
+var timeLayoutNames = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"Stamp":       time.StampMilli,
+}
+
+// time format of an adapter, timestamp_format and millisecond_format of text and json messages
+type TimeFormat struct {
+
+	// location of formatted times, eg: time.UTC or time.LoadLocation("Europe/Berlin"), default time.Local
+	// config files set the name of the zone, eg: "UTC", "Local", "Asia/Shanghai"
+	Location *time.Location
+
+	// layout of TimestampFormat, default "2006-01-02 15:04:05"
+	// a time layout or a name: "RFC3339", "RFC3339Nano", "RFC1123", "RFC1123Z", "Kitchen", "DateTime" and "Stamp"
+	TimestampLayout string
+
+	// layout of MillisecondFormat, default "2006-01-02 15:04:05.999", eg: time.RFC3339Nano
+	MillisecondLayout string
+}
+
+// set the time format of the adapter, nil writes local times of the default layouts
+// messages keep the time they are written, only their formatted times are in the location and layouts
+//
+// example, utc timestamps for compliance:
+//	logger.SetAdapterTimeFormat("file", &go_logger.TimeFormat{Location: time.UTC, MillisecondLayout: time.RFC3339Nano})
+func (logger *Logger) SetAdapterTimeFormat(adapterName string, format *TimeFormat) error {
+	format, err := format.resolve()
+	if err != nil {
+		return errors.New("logger: " + err.Error())
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.timeFormat.Store(&format)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// copy of the format with defaults and named layouts resolved, nil if format is nil
+func (format *TimeFormat) resolve() (*TimeFormat, error) {
+	if format == nil {
+		return nil, nil
+	}
+	resolved := *format
+	if resolved.Location == nil {
+		resolved.Location = time.Local
+	}
+	if resolved.TimestampLayout == "" {
+		resolved.TimestampLayout = TIME_DEFAULT_TIMESTAMP_LAYOUT
+	}
+	if resolved.MillisecondLayout == "" {
+		resolved.MillisecondLayout = TIME_DEFAULT_MILLISECOND_LAYOUT
+	}
+	if layout, ok := timeLayoutNames[resolved.TimestampLayout]; ok {
+		resolved.TimestampLayout = layout
+	}
+	if layout, ok := timeLayoutNames[resolved.MillisecondLayout]; ok {
+		resolved.MillisecondLayout = layout
+	}
+	// a layout without reference fields formats every time the same
+	for _, layout := range []string{resolved.TimestampLayout, resolved.MillisecondLayout} {
+		if time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(layout) == time.Date(2007, 2, 3, 16, 5, 6, 7e8, time.UTC).Format(layout) {
+			return nil, errors.New("time layout " + layout + " is illegal!")
+		}
+	}
+	return &resolved, nil
+}
+
+// message with times formatted by the output time format, the message itself if no format is set
+func (output *outputLogger) formatTime(loggerMsg *loggerMessage) *loggerMessage {
+	format, ok := output.timeFormat.Load().(**TimeFormat)
+	if !ok || *format == nil {
+		return loggerMsg
+	}
+	t := loggerMsg.time().In((*format).Location)
+	formatted := *loggerMsg
+	formatted.TimestampFormat = t.Format((*format).TimestampLayout)
+	formatted.MillisecondFormat = t.Format((*format).MillisecondLayout)
+	return &formatted
+}
+
+// time of the message, in milliseconds if the message is decoded
+func (loggerMsg *loggerMessage) time() time.Time {
+	if loggerMsg.nanosecond != 0 {
+		return time.Unix(0, loggerMsg.nanosecond)
+	}
+	if loggerMsg.Millisecond != 0 {
+		return time.Unix(0, loggerMsg.Millisecond*int64(time.Millisecond))
+	}
+	return time.Unix(loggerMsg.Timestamp, 0)
+}
@@ -0,0 +1,81 @@
+package go_logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetAdapterTimeFormat(t *testing.T) {
+
+	local, utc := &bytes.Buffer{}, &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: local, Format: "%timestamp_format%|%millisecond_format%"})
+	logger.AttachAs("utc", "writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: utc, Format: "%timestamp_format%|%millisecond_format%"})
+	err := logger.SetAdapterTimeFormat("utc", &TimeFormat{Location: time.UTC, TimestampLayout: "RFC3339", MillisecondLayout: time.RFC3339Nano})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.SetAdapterTimeFormat("utc", &TimeFormat{TimestampLayout: "date"}); err == nil {
+		t.Error("time layout without reference fields must be error")
+	}
+
+	now := time.Now()
+	loggerMsg := newLoggerMessage(now, LOGGER_LEVEL_INFO, "timed", nil)
+	logger.dispatch(loggerMsg, nil)
+
+	expected := now.UTC().Format(time.RFC3339) + "|" + now.UTC().Format(time.RFC3339Nano)
+	if strings.TrimSpace(utc.String()) != expected {
+		t.Errorf("utc time format error: %q, expected %q", utc.String(), expected)
+	}
+	expected = now.Format(TIME_DEFAULT_TIMESTAMP_LAYOUT) + "|" + now.Format(TIME_DEFAULT_MILLISECOND_LAYOUT)
+	if strings.TrimSpace(local.String()) != expected {
+		t.Errorf("default time format error: %q, expected %q", local.String(), expected)
+	}
+	if loggerMsg.MillisecondFormat != now.Format(TIME_DEFAULT_MILLISECOND_LAYOUT) {
+		t.Errorf("message of other adapters is changed: %s", loggerMsg.MillisecondFormat)
+	}
+
+	utc.Reset()
+	logger.SetAdapterTimeFormat("utc", nil)
+	logger.Info("local")
+	if strings.Contains(utc.String(), "Z") {
+		t.Errorf("removed time format error: %q", utc.String())
+	}
+}
+
+func TestLogger_LoadConfigTimeFormat(t *testing.T) {
+
+	logger := NewLogger()
+	err := logger.LoadConfigBytes([]byte(`{"adapters": [
+		{"name": "memory", "time": {"location": "Asia/Shanghai", "millisecond_layout": "RFC3339Nano"}}
+	]}`), CONFIG_FORMAT_JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format, _ := logger.outputs[0].timeFormat.Load().(**TimeFormat)
+	if format == nil || (*format).Location.String() != "Asia/Shanghai" || (*format).MillisecondLayout != time.RFC3339Nano {
+		t.Errorf("config time format error: %+v", format)
+	}
+
+	err = logger.LoadConfigBytes([]byte(`{"adapters": [{"name": "memory", "time": {"location": "Mars/Olympus"}}]}`), CONFIG_FORMAT_JSON)
+	if err == nil {
+		t.Error("unknown time zone must be error")
+	}
+}
+
+func TestLogger_SetAdapterTimeFormatFormatter(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: buffer, Formatter: &TextFormatter{Pattern: "%millisecond_format%"}})
+	logger.SetAdapterTimeFormat("writer", &TimeFormat{Location: time.UTC, MillisecondLayout: time.RFC3339Nano})
+	logger.Info("formatter")
+
+	if !strings.HasSuffix(strings.TrimSpace(buffer.String()), "Z") {
+		t.Errorf("time format of formatter error: %q", buffer.String())
+	}
+}
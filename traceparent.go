@@ -0,0 +1,69 @@
+package go_logger
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// W3C trace context header
+const TRACEPARENT_HEADER = "traceparent"
+
+type loggerVerboseKey struct{}
+
+// context with verbose logging, XxxCtx messages of the context are written regardless of logger level and sampler
+// adapters keep their own level and sampling, eg: Debug is written by adapters of level Debug
+func ContextWithVerbose(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loggerVerboseKey{}, true)
+}
+
+func verboseFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	verbose, _ := ctx.Value(loggerVerboseKey{}).(bool)
+	return verbose
+}
+
+// parse W3C traceparent header "version-traceid-parentid-flags"
+// example: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func ParseTraceparent(header string) (traceId string, spanId string, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", "", false, false
+	}
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", "", false, false
+	}
+	for _, part := range parts[:4] {
+		if _, err := hex.DecodeString(part); err != nil || strings.ToLower(part) != part {
+			return "", "", false, false
+		}
+	}
+	if parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return "", "", false, false
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return parts[1], parts[2], flags[0]&0x01 == 0x01, true
+}
+
+// http handler, the trace of traceparent header is added to the request context
+// and requests of sampled traces are logged verbose (Debug is written by adapters of level Debug)
+//
+// example:
+//	http.ListenAndServe(":8080", logger.TraceparentHandler(mux))
+func (logger *Logger) TraceparentHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceId, spanId, sampled, ok := ParseTraceparent(r.Header.Get(TRACEPARENT_HEADER))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := ContextWithTrace(r.Context(), traceId, spanId)
+		if sampled {
+			ctx = ContextWithVerbose(ctx)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
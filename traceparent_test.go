@@ -0,0 +1,64 @@
+package go_logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+
+	traceId, spanId, sampled, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok || traceId != "4bf92f3577b34da6a3ce929d0e0e4736" || spanId != "00f067aa0ba902b7" || !sampled {
+		t.Errorf("parse traceparent error: %s %s %v %v", traceId, spanId, sampled, ok)
+	}
+	_, _, sampled, ok = ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if !ok || sampled {
+		t.Error("parse traceparent unsampled error")
+	}
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, _, _, ok := ParseTraceparent(header); ok {
+			t.Errorf("parse traceparent %q must fail", header)
+		}
+	}
+}
+
+func TestLogger_TraceparentHandler(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.SetLevel(LOGGER_LEVEL_INFO)
+	logger.Attach("memory", LOGGER_LEVEL_DEBUG, &MemoryConfig{})
+	logger.AttachAs("alert", "memory", LOGGER_LEVEL_ERROR, &MemoryConfig{})
+	memoryAdapter := logger.Adapter("memory").(*AdapterMemory)
+	alertAdapter := logger.Adapter("alert").(*AdapterMemory)
+
+	handler := logger.TraceparentHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.DebugCtx(r.Context(), "debug")
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if len(memoryAdapter.Entries()) != 0 {
+		t.Error("unsampled trace debug must not be written")
+	}
+
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	entries := memoryAdapter.Entries()
+	if len(entries) != 1 || entries[0].Fields[LOGGER_FIELD_TRACE_ID] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("sampled trace debug error: %v", entries)
+	}
+	// adapters keep their level
+	if len(alertAdapter.Entries()) != 0 {
+		t.Errorf("sampled trace debug is written by adapter of level error: %v", alertAdapter.Entries())
+	}
+}
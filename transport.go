@@ -0,0 +1,162 @@
+package go_logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// default max logged body size (byte)
+const HTTP_TRANSPORT_DEFAULT_MAX_BODY_SIZE = 1024
+
+// http transport config
+type HttpTransportConfig struct {
+
+	// log level of request messages, failed requests are logged by LOGGER_LEVEL_ERROR
+	Level int
+
+	// is log request and response body
+	LogBody bool
+
+	// max logged body size (byte), default 1024
+	MaxBodySize int
+
+	// sanitize body before log, eg: mask password
+	BodySanitizer func(body []byte) []byte
+
+	// is log the query of the url, it may have tokens, the logger redactor masks them if it's set
+	LogQuery bool
+}
+
+// http transport, log outbound requests and responses
+//
+// example:
+//	client := &http.Client{
+//		Transport: logger.NewHttpTransport(nil, &go_logger.HttpTransportConfig{Level: go_logger.LOGGER_LEVEL_INFO}),
+//	}
+type HttpTransport struct {
+	logger  *Logger
+	next    http.RoundTripper
+	config  *HttpTransportConfig
+	enabled int32
+}
+
+// new http transport, next is http.DefaultTransport if nil
+func (logger *Logger) NewHttpTransport(next http.RoundTripper, config *HttpTransportConfig) *HttpTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config == nil {
+		config = &HttpTransportConfig{Level: LOGGER_LEVEL_INFO}
+	}
+	// defaults are not written to the config of the caller
+	transportConfig := *config
+	config = &transportConfig
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = HTTP_TRANSPORT_DEFAULT_MAX_BODY_SIZE
+	}
+	return &HttpTransport{
+		logger:  logger,
+		next:    next,
+		config:  config,
+		enabled: 1,
+	}
+}
+
+// enable or disable logging of this transport
+func (ht *HttpTransport) SetEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&ht.enabled, 1)
+		return
+	}
+	atomic.StoreInt32(&ht.enabled, 0)
+}
+
+// transport logging is enabled
+func (ht *HttpTransport) Enabled() bool {
+	return atomic.LoadInt32(&ht.enabled) == 1
+}
+
+// implement http.RoundTripper
+func (ht *HttpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !ht.Enabled() {
+		return ht.next.RoundTrip(req)
+	}
+
+	// the request of the caller is not modified, the body is peeked by a clone
+	requestBody := []byte{}
+	if ht.config.LogBody && req.Body != nil {
+		peeked := req.Clone(req.Context())
+		requestBody, peeked.Body = ht.peekBody(req.Body)
+		req = peeked
+	}
+
+	startTime := time.Now()
+	resp, err := ht.next.RoundTrip(req)
+	latency := time.Since(startTime)
+
+	fields := []string{
+		"method=" + req.Method,
+		"url=" + ht.loggedUrl(req.URL),
+	}
+	if err != nil {
+		fields = append(fields, "latency="+latency.String(), "error="+err.Error())
+		ht.logger.write(2, LOGGER_LEVEL_ERROR, "http client "+strings.Join(fields, " "), nil)
+		return resp, err
+	}
+
+	fields = append(fields, fmt.Sprintf("status=%d", resp.StatusCode), "latency="+latency.String())
+	if ht.config.LogBody {
+		responseBody := []byte{}
+		if resp.Body != nil {
+			responseBody, resp.Body = ht.peekBody(resp.Body)
+		}
+		fields = append(fields, "request_body="+ht.sanitize(requestBody), "response_body="+ht.sanitize(responseBody))
+	}
+	ht.logger.write(2, ht.config.Level, "http client "+strings.Join(fields, " "), nil)
+
+	return resp, nil
+}
+
+// url without user info, the query is logged if LogQuery is true
+func (ht *HttpTransport) loggedUrl(requestUrl *url.URL) string {
+	logged := *requestUrl
+	logged.User = nil
+	logged.Fragment = ""
+	if !ht.config.LogQuery {
+		logged.RawQuery = ""
+		logged.ForceQuery = false
+	}
+	return logged.String()
+}
+
+// read at most MaxBodySize bytes from body, return them and a body which still reads everything
+func (ht *HttpTransport) peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	buf, _ := ioutil.ReadAll(io.LimitReader(body, int64(ht.config.MaxBodySize)))
+	return buf, &peekedBody{
+		Reader: io.MultiReader(bytes.NewReader(buf), body),
+		Closer: body,
+	}
+}
+
+func (ht *HttpTransport) sanitize(body []byte) string {
+	// body is cut at MaxBodySize, don't log half of the last rune
+	if len(body) >= ht.config.MaxBodySize {
+		body = trimPartialRune(body)
+	}
+	if ht.config.BodySanitizer != nil {
+		body = ht.config.BodySanitizer(body)
+	}
+	return fmt.Sprintf("%q", truncateBytes(body, ht.config.MaxBodySize))
+}
+
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,77 @@
+package go_logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// default max age of bak files in TrashDir
+const FILE_TRASH_DEFAULT_MAX_AGE = 7 * 24 * time.Hour
+
+// trash directory of the file
+func (fw *FileWriter) trashDir(config *FileConfig) string {
+	return joinFileDir(filepath.Dir(fw.filename), config.TrashDir)
+}
+
+// move bak files to TrashDir and remove expired files of TrashDir
+func (fw *FileWriter) trashBackupFiles(config *FileConfig, bakFiles []backupFile) error {
+	trashDir := fw.trashDir(config)
+	if len(bakFiles) > 0 {
+		dirMode := config.DirMode
+		if dirMode == 0 {
+			dirMode = FILE_DEFAULT_DIR_MODE
+		}
+		err := fw.fs.MkdirAll(trashDir, dirMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	now := fw.clock.Now()
+	for _, bakFile := range bakFiles {
+		trashPath := filepath.Join(trashDir, filepath.Base(bakFile.path))
+		err := fw.fs.Rename(bakFile.path, trashPath)
+		if err != nil {
+			return err
+		}
+		// TrashMaxAge starts when it's moved to trash
+		fw.fs.Chtimes(trashPath, now, now)
+		if config.TrashCompress && !strings.HasSuffix(trashPath, ".gz") {
+			err = compressFile(fw.fs, trashPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return fw.purgeTrash(config, trashDir, now)
+}
+
+// remove files of the file in TrashDir older than TrashMaxAge, the trash directory can be shared by files
+func (fw *FileWriter) purgeTrash(config *FileConfig, trashDir string, now time.Time) error {
+	dir, err := fw.fs.ReadDir(trashDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	prefix, _ := splitFilenameExt(filepath.Base(fw.filename))
+	maxAge := config.TrashMaxAge
+	if maxAge <= 0 {
+		maxAge = FILE_TRASH_DEFAULT_MAX_AGE
+	}
+	expireTime := now.Add(-maxAge)
+	for _, fi := range dir {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), prefix+".") && !strings.HasPrefix(fi.Name(), prefix+"_") || fi.ModTime().After(expireTime) {
+			continue
+		}
+		err = fw.fs.Remove(filepath.Join(trashDir, fi.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
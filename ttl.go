@@ -0,0 +1,46 @@
+package go_logger
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// error of messages older than the ttl of the adapter, reported to the error handler
+var ErrMessageExpired = errors.New("logger: message expired")
+
+// set ttl of messages of attached adapter, messages older than it when they're written are dropped with ErrMessageExpired
+// eg: messages queued behind a hung adapter, replayed by its fallback, 0 is no ttl
+//
+// example:
+//	logger.SetAdapterTimeout("api", 2*time.Second)
+//	logger.SetAdapterTTL("api", time.Minute)
+func (logger *Logger) SetAdapterTTL(adapterName string, ttl time.Duration) error {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	for _, output := range logger.outputs {
+		if output.Name == adapterName {
+			output.ttl.Store(ttl)
+			return nil
+		}
+	}
+	return errors.New("logger: adapter " + adapterName + " is not attached!")
+}
+
+// message is older than the ttl, it's counted as expired
+func (output *outputLogger) expired(loggerMsg *loggerMessage) bool {
+	ttl, _ := output.ttl.Load().(time.Duration)
+	if ttl <= 0 {
+		return false
+	}
+	created := time.Unix(loggerMsg.Timestamp, 0)
+	if loggerMsg.nanosecond != 0 {
+		created = time.Unix(0, loggerMsg.nanosecond)
+	}
+	if time.Since(created) <= ttl {
+		return false
+	}
+	atomic.AddInt64(&output.expiredCount, 1)
+	return true
+}
@@ -0,0 +1,51 @@
+package go_logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogger_SetAdapterTTL(t *testing.T) {
+
+	blockingConfig := &blockingConfig{release: make(chan struct{})}
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("blocking", LOGGER_LEVEL_DEBUG, blockingConfig)
+	logger.SetAsync(10)
+	if logger.SetAdapterTTL("blocking", 100*time.Millisecond) != nil {
+		t.Fatal("set adapter ttl error")
+	}
+	lock := sync.Mutex{}
+	expired := []string{}
+	logger.SetErrorHandler(func(adapter string, err error, loggerMsg *loggerMessage) {
+		lock.Lock()
+		defer lock.Unlock()
+		if err == ErrMessageExpired {
+			expired = append(expired, loggerMsg.Body)
+		}
+	})
+
+	// the first message is taken by the queue goroutine, the others are queued behind it
+	logger.Info("1")
+	for len(logger.outputs[0].queue.msgChan) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	logger.Info("2")
+	logger.Info("3")
+	time.Sleep(150 * time.Millisecond)
+	close(blockingConfig.release)
+	logger.Flush()
+
+	if strings.Join(blockingConfig.bodies, "") != "1" || strings.Join(expired, "") != "23" {
+		t.Errorf("adapter ttl error: written %v, expired %v", blockingConfig.bodies, expired)
+	}
+	if logger.Stats().Adapters["blocking"].Expired != 2 {
+		t.Errorf("adapter expired stats error: %+v", logger.Stats().Adapters["blocking"])
+	}
+	if logger.SetAdapterTTL("file", time.Second) == nil {
+		t.Error("not attached adapter must be error")
+	}
+}
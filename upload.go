@@ -0,0 +1,221 @@
+package go_logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// manifest of uploaded bak files is Filename + ".manifest"
+const FILE_MANIFEST_SUFFIX = ".manifest"
+
+// uploader of rotated bak files, eg: object storage
+type BackupUploader interface {
+
+	// upload the bak file, return location of the archive, eg: "s3://bucket/app_20240102.log"
+	// the bak file is removed by MaxBak, MaxAge or MaxTotalSize only after Upload returns nil
+	Upload(path string) (location string, err error)
+}
+
+// uploaded bak file of manifest
+type BackupManifestEntry struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Size     int64  `json:"size"`
+	Uploaded int64  `json:"uploaded"`
+}
+
+// ReadBackupManifest return uploaded bak files of the log file, oldest upload first
+// bak files can be restored from Location after they are removed
+func ReadBackupManifest(filename string) ([]BackupManifestEntry, error) {
+	return readBackupManifest(OsFileSystem, filename)
+}
+
+// uploaded bak files of the log file of the file system
+func readBackupManifest(fs FileSystem, filename string) ([]BackupManifestEntry, error) {
+	file, err := fs.OpenFile(filename+FILE_MANIFEST_SUFFIX, os.O_RDONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BackupManifestEntry{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []BackupManifestEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := BackupManifestEntry{}
+		// skip the torn last line of a crash
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil || entry.Name == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// uploads of bak files of a file writer
+type backupUploads struct {
+	uploader BackupUploader
+	filename string
+	fs       FileSystem
+	clock    Clock
+
+	lock     sync.Mutex
+	uploaded map[string]bool // names of manifest
+	queued   map[string]bool
+	pending  []string
+	running  bool
+	wait     sync.WaitGroup
+}
+
+func newBackupUploads(uploader BackupUploader, filename string, fs FileSystem, clock Clock) *backupUploads {
+	uploads := &backupUploads{
+		uploader: uploader,
+		filename: filename,
+		fs:       fs,
+		clock:    clock,
+		uploaded: map[string]bool{},
+		queued:   map[string]bool{},
+	}
+	entries, err := readBackupManifest(fs, filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: unable read backup manifest of %s, error: %v\n", filename, err)
+	}
+	for _, entry := range entries {
+		uploads.uploaded[entry.Name] = true
+	}
+	return uploads
+}
+
+// backup uploads of the file writer, created by first use
+func (fw *FileWriter) backupUploads(config *FileConfig) *backupUploads {
+	if fw.uploads == nil {
+		fw.uploads = newBackupUploads(config.Uploader, fw.filename, fw.fs, fw.clock)
+	}
+	return fw.uploads
+}
+
+// upload the rotated bak file in background
+func (fw *FileWriter) uploadBackup(config *FileConfig, path string) {
+	if config.Uploader == nil {
+		return
+	}
+	fw.backupUploads(config).enqueue(path)
+}
+
+// wait for background uploads
+func (fw *FileWriter) waitUploads() {
+	if fw.uploads != nil {
+		fw.uploads.wait.Wait()
+	}
+}
+
+// return uploaded files of the expired bak files, bak files not uploaded yet (failures, crash) are uploaded again
+func (uploads *backupUploads) removable(bakFiles []backupFile, removeNum int) []backupFile {
+	uploads.lock.Lock()
+	removeFiles := []backupFile{}
+	for _, bakFile := range bakFiles[:removeNum] {
+		if uploads.uploaded[filepath.Base(bakFile.path)] {
+			removeFiles = append(removeFiles, bakFile)
+		}
+	}
+	uploads.lock.Unlock()
+
+	for _, bakFile := range bakFiles {
+		uploads.enqueue(bakFile.path)
+	}
+	return removeFiles
+}
+
+// the bak file of name is in the manifest
+func (uploads *backupUploads) isUploaded(name string) bool {
+	uploads.lock.Lock()
+	defer uploads.lock.Unlock()
+	return uploads.uploaded[name]
+}
+
+func (uploads *backupUploads) enqueue(path string) {
+	uploads.lock.Lock()
+	defer uploads.lock.Unlock()
+	if uploads.uploaded[filepath.Base(path)] || uploads.queued[path] {
+		return
+	}
+	uploads.queued[path] = true
+	uploads.pending = append(uploads.pending, path)
+	if !uploads.running {
+		uploads.running = true
+		uploads.wait.Add(1)
+		go uploads.run()
+	}
+}
+
+// upload pending files one by one, failed files are retried by the next clean up
+func (uploads *backupUploads) run() {
+	defer uploads.wait.Done()
+	for {
+		uploads.lock.Lock()
+		if len(uploads.pending) == 0 {
+			uploads.running = false
+			uploads.lock.Unlock()
+			return
+		}
+		path := uploads.pending[0]
+		uploads.pending = uploads.pending[1:]
+		uploads.lock.Unlock()
+
+		err := uploads.upload(path)
+
+		uploads.lock.Lock()
+		delete(uploads.queued, path)
+		uploads.lock.Unlock()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: unable upload backup file %s, error: %v\n", path, err)
+		}
+	}
+}
+
+// upload the file and append it to the manifest
+func (uploads *backupUploads) upload(path string) error {
+	fi, err := uploads.fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	location, err := uploads.uploader.Upload(path)
+	if err != nil {
+		return err
+	}
+
+	entry := BackupManifestEntry{
+		Name:     fi.Name(),
+		Location: location,
+		Size:     fi.Size(),
+		Uploaded: uploads.clock.Now().Unix(),
+	}
+	line, _ := json.Marshal(entry)
+	file, err := uploads.fs.OpenFile(uploads.filename+FILE_MANIFEST_SUFFIX, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err == nil {
+		// the bak file can be removed only after the manifest is durable
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	uploads.lock.Lock()
+	uploads.uploaded[entry.Name] = true
+	uploads.lock.Unlock()
+	return nil
+}
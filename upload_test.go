@@ -0,0 +1,93 @@
+package go_logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testUploader struct {
+	lock  sync.Mutex
+	fail  string
+	names []string
+}
+
+func (uploader *testUploader) Upload(path string) (string, error) {
+	uploader.lock.Lock()
+	defer uploader.lock.Unlock()
+	name := filepath.Base(path)
+	if name == uploader.fail {
+		return "", errors.New("upload failed")
+	}
+	uploader.names = append(uploader.names, name)
+	return "mem://" + name, nil
+}
+
+func TestFileWriter_UploadBackupFiles(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	filename := path.Join(dir, "test.log")
+	ioutil.WriteFile(filename, []byte{}, 0766)
+
+	timeFormat := "20060102"
+	now := time.Now()
+	bakNames := []string{}
+	for i := 3; i >= 1; i-- {
+		day := now.AddDate(0, 0, -i*10)
+		bakNames = append(bakNames, "test_"+day.Format(timeFormat)+".log")
+		ioutil.WriteFile(path.Join(dir, bakNames[len(bakNames)-1]), make([]byte, 2048), 0766)
+	}
+
+	uploader := &testUploader{fail: bakNames[0]}
+	config := &FileConfig{MaxBak: 2, Uploader: uploader}
+	fw := NewFileWrite(filename)
+
+	// nothing is uploaded yet
+	err = fw.cleanUpBackupFiles(config, timeFormat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	fw.waitUploads()
+	for _, name := range bakNames {
+		if _, err := os.Stat(path.Join(dir, name)); err != nil {
+			t.Errorf("bak file %s removed before upload", name)
+		}
+	}
+
+	err = fw.cleanUpBackupFiles(config, timeFormat)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	fw.waitUploads()
+	if _, err := os.Stat(path.Join(dir, bakNames[0])); err != nil {
+		t.Error("failed upload bak file is removed")
+	}
+	if _, err := os.Stat(path.Join(dir, bakNames[1])); !os.IsNotExist(err) {
+		t.Error("uploaded bak file is not removed")
+	}
+
+	entries, err := ReadBackupManifest(filename)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(entries) != 2 || entries[0].Name != bakNames[1] || entries[0].Location != "mem://"+bakNames[1] || entries[0].Size != 2048 {
+		t.Errorf("manifest error: %+v", entries)
+	}
+
+	// manifest is loaded by new writer
+	fw = NewFileWrite(filename)
+	fw.backupUploads(config)
+	if !fw.uploads.uploaded[bakNames[2]] || fw.uploads.uploaded[bakNames[0]] {
+		t.Errorf("manifest load error: %v", fw.uploads.uploaded)
+	}
+}
@@ -1,7 +1,7 @@
 package utils
 
 import (
-	"bufio"
+	"bytes"
 	"io"
 	"os"
 )
@@ -34,7 +34,7 @@ func (f *File) PathExists(path string) (bool, error) {
 	return false, err
 }
 
-//get file lines
+//get file lines, the file is read by chunks and lines are not loaded
 //params : filename
 //return : fileLine, error
 func (f *File) GetFileLines(filename string) (fileLine int64, err error) {
@@ -43,15 +43,24 @@ func (f *File) GetFileLines(filename string) (fileLine int64, err error) {
 		return fileLine, err
 	}
 	defer file.Close()
+	return f.GetReaderLines(file)
+}
 
+//get lines of the reader, it's read by chunks until EOF
+//params : reader
+//return : fileLine, error
+func (f *File) GetReaderLines(reader io.Reader) (fileLine int64, err error) {
 	fileLine = 1
-	r := bufio.NewReader(file)
+	chunk := make([]byte, 32*1024)
 	for {
-		_, err := r.ReadString('\n')
-		if err != nil || err == io.EOF {
+		n, err := reader.Read(chunk)
+		fileLine += int64(bytes.Count(chunk[:n], []byte{'\n'}))
+		if err == io.EOF {
 			break
 		}
-		fileLine += 1
+		if err != nil {
+			return fileLine, err
+		}
 	}
 	return fileLine, nil
 }
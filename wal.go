@@ -0,0 +1,318 @@
+package go_logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// default size of the wal (byte) compacted after delivery
+const WAL_DEFAULT_COMPACT_SIZE = 1024 * 1024
+
+// write-ahead log config, see Logger.SetWAL()
+type WALConfig struct {
+
+	// wal filename, eg: "./app.wal"
+	Filename string
+
+	// the wal is compacted when it's bigger (byte) and delivered records are the most of it, default 1MB
+	CompactSize int64
+}
+
+// write-ahead log, every accepted message is written and fsynced before it's dispatched to adapters
+// records are "<sequence> <json message>" lines, records of delivered messages are removed by compaction
+type writeAheadLog struct {
+	lock     sync.Mutex
+	config   *WALConfig
+	file     *os.File
+	size     int64             // size of the file
+	live     int64             // size of pending records
+	sequence uint64            // last record sequence
+	pending  map[uint64][]byte // lines of undelivered records by sequence
+}
+
+// wal record of a message, released by the dispatcher and every adapter write
+// the record is delivered when all are released and no adapter failed
+type walRecord struct {
+	wal      *writeAheadLog
+	sequence uint64
+	refs     int32
+	failed   int32
+}
+
+// set the write-ahead log, nil disables it
+// accepted messages are written and fsynced to the wal before they're dispatched, so no message is lost on crash or power failure
+// undelivered messages of the wal are replayed to adapters, attach adapters before SetWAL
+// messages of failed or dropped writes are kept in the wal and replayed on next SetWAL, delivery is at least once
+//
+// example:
+//	logger.Attach("file", go_logger.LOGGER_LEVEL_DEBUG, &go_logger.FileConfig{Filename: "./app.log"})
+//	logger.SetWAL(&go_logger.WALConfig{Filename: "./app.wal"})
+func (logger *Logger) SetWAL(config *WALConfig) error {
+	if config == nil {
+		old, _ := logger.wal.Load().(*writeAheadLog)
+		logger.wal.Store((*writeAheadLog)(nil))
+		if old != nil {
+			return old.close()
+		}
+		return nil
+	}
+	if config.Filename == "" {
+		return configError("", "Filename", "cannot be empty!")
+	}
+	walConfig := *config
+	if walConfig.CompactSize <= 0 {
+		walConfig.CompactSize = WAL_DEFAULT_COMPACT_SIZE
+	}
+
+	wal, loggerMsgs, err := openWAL(&walConfig)
+	if err != nil {
+		return err
+	}
+	old, _ := logger.wal.Load().(*writeAheadLog)
+	logger.wal.Store(wal)
+	if old != nil {
+		old.close()
+	}
+
+	for _, loggerMsg := range loggerMsgs {
+		logger.stamp(loggerMsg)
+		logger.deliver(loggerMsg, nil)
+	}
+	return nil
+}
+
+// undelivered messages of the wal
+func (logger *Logger) WALPending() int {
+	wal, _ := logger.wal.Load().(*writeAheadLog)
+	if wal == nil {
+		return 0
+	}
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	return len(wal.pending)
+}
+
+// write the message to the wal if it's set
+func (logger *Logger) writeAhead(loggerMsg *loggerMessage) {
+	wal, _ := logger.wal.Load().(*writeAheadLog)
+	if wal == nil {
+		return
+	}
+	err := wal.append(loggerMsg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: wal %s write failed, error: %v\n", wal.config.Filename, err)
+	}
+}
+
+func (logger *Logger) closeWAL() {
+	wal, _ := logger.wal.Load().(*writeAheadLog)
+	if wal != nil {
+		wal.close()
+	}
+}
+
+// open the wal, return messages of the undelivered records
+// a torn last record of a crash is skipped, the wal is rewritten by its records
+func openWAL(config *WALConfig) (*writeAheadLog, []*loggerMessage, error) {
+	err := os.MkdirAll(filepath.Dir(config.Filename), 0755)
+	if err != nil {
+		return nil, nil, err
+	}
+	wal := &writeAheadLog{config: config, pending: map[uint64][]byte{}}
+
+	content, err := ioutil.ReadFile(config.Filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+	loggerMsgs := []*loggerMessage{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		index := bytes.IndexByte(line, ' ')
+		if index <= 0 {
+			fmt.Fprintf(os.Stderr, "logger: wal %s illegal record is skipped\n", config.Filename)
+			continue
+		}
+		sequence, err := strconv.ParseUint(string(line[:index]), 10, 64)
+		loggerMsg := &loggerMessage{}
+		if err == nil {
+			err = loggerMsg.UnmarshalJSON(line[index+1:])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: wal %s illegal record is skipped, error: %v\n", config.Filename, err)
+			continue
+		}
+		record := append(append([]byte{}, line...), '\n')
+		wal.pending[sequence] = record
+		wal.live += int64(len(record))
+		if sequence > wal.sequence {
+			wal.sequence = sequence
+		}
+		loggerMsg.wal = &walRecord{wal: wal, sequence: sequence, refs: 1}
+		loggerMsgs = append(loggerMsgs, loggerMsg)
+	}
+
+	err = wal.rewrite()
+	if err != nil {
+		return nil, nil, err
+	}
+	return wal, loggerMsgs, nil
+}
+
+// append the record of the message and fsync
+func (wal *writeAheadLog) append(loggerMsg *loggerMessage) error {
+	jsonByte, err := loggerMsg.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	if wal.file == nil {
+		return ErrLoggerClosed
+	}
+	sequence := wal.sequence + 1
+	record := make([]byte, 0, len(jsonByte)+22)
+	record = strconv.AppendUint(record, sequence, 10)
+	record = append(record, ' ')
+	record = append(record, jsonByte...)
+	record = append(record, '\n')
+	n, err := wal.file.Write(record)
+	wal.size += int64(n)
+	if err != nil {
+		return err
+	}
+	err = wal.file.Sync()
+	if err != nil {
+		return err
+	}
+	wal.sequence = sequence
+	wal.pending[sequence] = record
+	wal.live += int64(len(record))
+	loggerMsg.wal = &walRecord{wal: wal, sequence: sequence, refs: 1}
+	return nil
+}
+
+// remove the delivered record, compact the wal if it's big and most records are delivered
+func (wal *writeAheadLog) delivered(sequence uint64) {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	record, ok := wal.pending[sequence]
+	if !ok {
+		return
+	}
+	delete(wal.pending, sequence)
+	wal.live -= int64(len(record))
+	if wal.file == nil || wal.size < wal.config.CompactSize || wal.size < 2*wal.live {
+		return
+	}
+	err := wal.compact()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: wal %s compact failed, error: %v\n", wal.config.Filename, err)
+	}
+}
+
+// truncate the wal if all records are delivered, otherwise rewrite it by pending records
+func (wal *writeAheadLog) compact() error {
+	if len(wal.pending) > 0 {
+		return wal.rewrite()
+	}
+	err := wal.file.Truncate(0)
+	if err != nil {
+		return err
+	}
+	wal.size = 0
+	return wal.file.Sync()
+}
+
+// replace the wal by pending records atomically
+func (wal *writeAheadLog) rewrite() error {
+	tmpFilename := wal.config.Filename + ".tmp"
+	file, err := os.OpenFile(tmpFilename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	sequences := make([]uint64, 0, len(wal.pending))
+	for sequence := range wal.pending {
+		sequences = append(sequences, sequence)
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	writer := bufio.NewWriter(file)
+	for _, sequence := range sequences {
+		writer.Write(wal.pending[sequence])
+	}
+	err = writer.Flush()
+	if err == nil {
+		err = file.Sync()
+	}
+	file.Close()
+	if err == nil {
+		err = os.Rename(tmpFilename, wal.config.Filename)
+	}
+	if err != nil {
+		os.Remove(tmpFilename)
+		return err
+	}
+
+	walFile, err := os.OpenFile(wal.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if wal.file != nil {
+		wal.file.Close()
+	}
+	wal.file = walFile
+	wal.size = wal.live
+	return nil
+}
+
+// compact and close the wal, records of undelivered messages are kept
+func (wal *writeAheadLog) close() error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+
+	if wal.file == nil {
+		return nil
+	}
+	if wal.size > wal.live {
+		err := wal.compact()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger: wal %s compact failed, error: %v\n", wal.config.Filename, err)
+		}
+	}
+	err := wal.file.Close()
+	wal.file = nil
+	return err
+}
+
+// an adapter write of the message begins
+func (loggerMsg *loggerMessage) retainWAL() {
+	if loggerMsg.wal != nil {
+		atomic.AddInt32(&loggerMsg.wal.refs, 1)
+	}
+}
+
+// an adapter write of the message ends, the record is delivered if every write is delivered
+func (loggerMsg *loggerMessage) releaseWAL(delivered bool) {
+	record := loggerMsg.wal
+	if record == nil {
+		return
+	}
+	if !delivered {
+		atomic.StoreInt32(&record.failed, 1)
+	}
+	if atomic.AddInt32(&record.refs, -1) == 0 && atomic.LoadInt32(&record.failed) == 0 {
+		record.wal.delivered(record.sequence)
+	}
+}
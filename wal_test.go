@@ -0,0 +1,88 @@
+package go_logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SetWAL(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "go-logger-wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.wal")
+
+	for _, async := range []bool{false, true} {
+		os.Remove(filename)
+
+		// messages of the failed adapter are kept in the wal
+		writer := &switchWriter{down: true}
+		logger := NewLogger()
+		logger.Detach("console")
+		logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: writer, Format: "%body%"})
+		if async {
+			logger.SetAsync()
+		}
+		err = logger.SetWAL(&WALConfig{Filename: filename, CompactSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Info("lost 1")
+		logger.Info("lost 2")
+		logger.Flush()
+		if logger.WALPending() != 2 {
+			t.Errorf("async=%v, wal pending error: %d", async, logger.WALPending())
+		}
+
+		// delivered messages are compacted
+		writer.setDown(false)
+		logger.Info("delivered")
+		logger.Flush()
+		if logger.WALPending() != 2 {
+			t.Errorf("async=%v, wal pending of delivered error: %d", async, logger.WALPending())
+		}
+		logger.Close(context.Background())
+		content, _ := ioutil.ReadFile(filename)
+		if strings.Count(string(content), "\n") != 2 || strings.Contains(string(content), "delivered") {
+			t.Errorf("async=%v, wal compact error: %q", async, content)
+		}
+
+		// a torn record of a crash is skipped, undelivered records are replayed
+		file, _ := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+		file.WriteString("3 {\"body\":\"tor")
+		file.Close()
+		replayed := &switchWriter{}
+		logger = NewLogger()
+		logger.Detach("console")
+		logger.Attach("writer", LOGGER_LEVEL_DEBUG, &WriterConfig{Writer: replayed, Format: "%body%"})
+		if async {
+			logger.SetAsync()
+		}
+		err = logger.SetWAL(&WALConfig{Filename: filename, CompactSize: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logger.Flush()
+		if strings.TrimSpace(replayed.String()) != "lost 1\nlost 2" {
+			t.Errorf("async=%v, wal replay error: %q", async, replayed.String())
+		}
+		if logger.WALPending() != 0 {
+			t.Errorf("async=%v, wal pending of replay error: %d", async, logger.WALPending())
+		}
+		logger.Close(context.Background())
+		content, _ = ioutil.ReadFile(filename)
+		if len(content) != 0 {
+			t.Errorf("async=%v, wal truncate error: %q", async, content)
+		}
+	}
+
+	if NewLogger().SetWAL(&WALConfig{}) == nil {
+		t.Error("wal empty filename error")
+	}
+}
@@ -0,0 +1,125 @@
+package go_logger
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const WRITER_ADAPTER_NAME = "writer"
+
+// adapter writer, write messages to any io.Writer
+type AdapterWriter struct {
+	encodingErrors int64 // json fallbacks of marshal errors
+
+	lock   sync.Mutex
+	config *WriterConfig
+}
+
+// writer config
+type WriterConfig struct {
+
+	// messages are written to it, eg: bytes.Buffer, net.Conn
+	Writer io.Writer
+
+	// formatter of messages, JsonFormat and Format are ignored if it's set
+	// example: &go_logger.LogfmtFormatter{}
+	Formatter Formatter
+
+	// is json format
+	JsonFormat bool
+
+	// max json record size (byte), bigger records are split into parts by body, 0 is unlimited
+	MaxRecordSize int
+
+	// strip ansi escapes and control characters (except "\t" and "\n") from body and fields
+	StripControl bool
+
+	// jsonFormat is false, please input format string
+	// if format is empty, default format "%millisecond_format% [%level_string%] %body%"
+	Format string
+}
+
+func (wc *WriterConfig) Name() string {
+	return WRITER_ADAPTER_NAME
+}
+
+func NewAdapterWriter() LoggerAbstract {
+	return &AdapterWriter{}
+}
+
+func (adapterWriter *AdapterWriter) Init(writerConfig Config) error {
+	wc, ok := writerConfig.(*WriterConfig)
+	if !ok || wc == nil {
+		return configTypeError(WRITER_ADAPTER_NAME, "WriterConfig")
+	}
+	adapterWriter.config = wc
+
+	if wc.Writer == nil {
+		return configError(WRITER_ADAPTER_NAME, "Writer", "cannot be nil!")
+	}
+	if wc.JsonFormat == false && wc.Format == "" {
+		wc.Format = defaultLoggerMessageFormat
+	}
+	if err := checkFormatPlaceholders(wc.Format, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (adapterWriter *AdapterWriter) Write(loggerMsg *loggerMessage) error {
+	if adapterWriter.config.StripControl {
+		loggerMsg = sanitizeLoggerMessage(loggerMsg)
+	}
+	msg := ""
+	if adapterWriter.config.Formatter != nil {
+		msg = formatterFormat(adapterWriter.config.Formatter, loggerMsg) + "\n"
+	} else if adapterWriter.config.JsonFormat == true {
+		for _, partMsg := range splitLoggerMessage(loggerMsg, adapterWriter.config.MaxRecordSize) {
+			jsonByte := marshalLoggerMessage(partMsg, &adapterWriter.encodingErrors)
+			msg += string(jsonByte) + "\n"
+		}
+	} else {
+		msg = loggerMessageFormat(adapterWriter.config.Format, loggerMsg) + "\n"
+	}
+
+	adapterWriter.lock.Lock()
+	defer adapterWriter.lock.Unlock()
+	_, err := adapterWriter.config.Writer.Write([]byte(msg))
+	return err
+}
+
+func (adapterWriter *AdapterWriter) Flush() {
+	if flusher, ok := adapterWriter.config.Writer.(interface{ Flush() error }); ok {
+		adapterWriter.lock.Lock()
+		flusher.Flush()
+		adapterWriter.lock.Unlock()
+	}
+}
+
+func (adapterWriter *AdapterWriter) Name() string {
+	return WRITER_ADAPTER_NAME
+}
+
+// Counters of json fallbacks
+func (adapterWriter *AdapterWriter) Counters() map[string]int64 {
+	return map[string]int64{COUNTER_ENCODING_ERRORS: atomic.LoadInt64(&adapterWriter.encodingErrors)}
+}
+
+// io.Writer logs every written line at level, an incomplete line is buffered until its newline
+// lines are written like captured lines, messages have no caller ("null")
+//
+// example:
+//	server := &http.Server{
+//		ErrorLog: log.New(logger.LevelWriter(go_logger.LOGGER_LEVEL_ERROR), "", 0),
+//	}
+func (logger *Logger) LevelWriter(level int) io.Writer {
+	return &stdCaptureWriter{logger: logger, level: level}
+}
+
+func init() {
+	Register(WRITER_ADAPTER_NAME, NewAdapterWriter)
+	RegisterConfig(WRITER_ADAPTER_NAME, func() Config {
+		return &WriterConfig{}
+	})
+}
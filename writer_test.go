@@ -0,0 +1,71 @@
+package go_logger
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestAdapterWriter_Write(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewLogger()
+	logger.Detach("console")
+	err := logger.Attach("writer", LOGGER_LEVEL_INFO, &WriterConfig{
+		Writer: buffer,
+		Format: "[%level_string%] [%file%] %body%",
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	logger.Info("writer info")
+	logger.Debug("writer debug")
+	if buffer.String() != "[Info] [writer_test.go] writer info\n" {
+		t.Error("writer adapter write error: " + buffer.String())
+	}
+
+	buffer.Reset()
+	stdLogger := log.New(logger.LevelWriter(LOGGER_LEVEL_ERROR), "", 0)
+	stdLogger.Print("http: TLS handshake error\nsecond line")
+	if buffer.String() != "[Error] [null] http: TLS handshake error\n[Error] [null] second line\n" {
+		t.Error("level writer error: " + buffer.String())
+	}
+
+	// a line written in chunks is one message
+	buffer.Reset()
+	levelWriter := logger.LevelWriter(LOGGER_LEVEL_ERROR)
+	levelWriter.Write([]byte("chunked "))
+	fmt.Fprintln(levelWriter, "line")
+	if buffer.String() != "[Error] [null] chunked line\n" {
+		t.Error("level writer chunks error: " + buffer.String())
+	}
+
+	adapter := NewAdapterWriter()
+	if adapter.Init(&WriterConfig{}) == nil || !strings.Contains(adapter.Init(&ConsoleConfig{}).Error(), "WriterConfig") {
+		t.Error("writer adapter init should be failed")
+	}
+}
+
+func TestLogger_LevelWriter(t *testing.T) {
+
+	logger := NewLogger()
+	logger.Detach("console")
+	logger.Attach("test", LOGGER_LEVEL_INFO, &TestConfig{})
+	recorded := logger.Adapter("test").(*AdapterTest)
+
+	stdLogger := log.New(logger.LevelWriter(LOGGER_LEVEL_ERROR), "", 0)
+	stdLogger.Printf("http: TLS handshake error from %s", "10.0.0.1:5000")
+	stdLogger.Print("first line\nsecond line")
+	log.New(logger.LevelWriter(LOGGER_LEVEL_DEBUG), "", 0).Print("dropped by adapter level")
+
+	entries := recorded.Entries()
+	if len(entries) != 3 || entries[0].Body != "http: TLS handshake error from 10.0.0.1:5000" || entries[0].Level != LOGGER_LEVEL_ERROR {
+		t.Fatalf("level writer log error: %v", entries)
+	}
+	if entries[0].File != "null" || entries[2].Body != "second line" {
+		t.Errorf("level writer log lines error: %v", entries)
+	}
+}